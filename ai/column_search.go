@@ -0,0 +1,62 @@
+// column_search.go implements semantic column search: given a cached
+// schema index and a natural-language description, the AI returns
+// candidate table/column pairs with a confidence score.
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// ColumnMatch is one candidate column returned by SearchColumns.
+type ColumnMatch struct {
+	Table      string  `json:"table"`
+	Column     string  `json:"column"`
+	Confidence float64 `json:"confidence"`
+	Reason     string  `json:"reason"`
+}
+
+// SearchColumns asks the provider which columns in schemaIndex match description.
+// Results are sorted by confidence (highest first).
+func SearchColumns(ctx context.Context, p Provider, schemaIndex string, description string) ([]ColumnMatch, error) {
+	messages := []Message{
+		{Role: "system", Content: systemPromptColumnSearch},
+		{Role: "user", Content: fmt.Sprintf("Schema index:\n%s\n\nLooking for: %s", schemaIndex, description)},
+	}
+
+	LogAIRequest("ColumnSearch", p.Name(), map[string]string{
+		"Description":  description,
+		"Schema Index": schemaIndex,
+	})
+	resp, err := p.Chat(ctx, messages)
+	LogAIResponse("ColumnSearch", resp, err)
+	if err != nil {
+		return nil, err
+	}
+	return ParseColumnMatches(resp)
+}
+
+// ParseColumnMatches extracts the {"matches": [...]} object from an AI response.
+func ParseColumnMatches(response string) ([]ColumnMatch, error) {
+	jsonStr := extractJSON(response)
+	if jsonStr == "" {
+		return nil, fmt.Errorf("no JSON found in AI response")
+	}
+
+	var out struct {
+		Matches []ColumnMatch `json:"matches"`
+	}
+	if err := json.Unmarshal([]byte(jsonStr), &out); err != nil {
+		return nil, fmt.Errorf("failed to parse column search JSON: %w", err)
+	}
+
+	for i := range out.Matches {
+		out.Matches[i].Reason = SanitizeResponse(out.Matches[i].Reason)
+	}
+	sort.SliceStable(out.Matches, func(i, j int) bool {
+		return out.Matches[i].Confidence > out.Matches[j].Confidence
+	})
+	return out.Matches, nil
+}
@@ -0,0 +1,90 @@
+// date_range.go resolves relative date phrases ("last week", "past 30
+// days") in query plans into SQL filters.
+//
+// The AI only names the phrase; the bounds are computed here, so the same
+// question always produces the same SQL and the model never does date
+// arithmetic. Bounds are date_trunc expressions over the time the plan
+// was read, written as a timestamptz literal: PostgreSQL evaluates them
+// in the session's TimeZone, so "today" means today where the session
+// is, and as every page of a plan runs the same filter, a paginated
+// result doesn't shift between pages.
+package ai
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// QueryPlanDateRange restricts a column to a relative date range.
+type QueryPlanDateRange struct {
+	Column string `json:"column"` // e.g. "orders.created_at"
+	Range  string `json:"range"`  // e.g. "last week", "past 30 days", "today"
+}
+
+// calendarUnits maps period names to their date_trunc field and length.
+var calendarUnits = map[string]struct{ trunc, length string }{
+	"day":     {"day", "1 day"},
+	"week":    {"week", "1 week"},
+	"month":   {"month", "1 month"},
+	"quarter": {"quarter", "3 months"},
+	"year":    {"year", "1 year"},
+}
+
+var (
+	calendarRangeRe = regexp.MustCompile(`^(this|current|last|previous|next) (day|week|month|quarter|year)$`)
+	rollingRangeRe  = regexp.MustCompile(`^(?:last|past) (\d+) (minute|hour|day|week|month|year)s?$`)
+	toDateRangeRe   = regexp.MustCompile(`^(week|month|quarter|year) to date$`)
+)
+
+// ResolveDateRange turns a relative date phrase into a filter on column,
+// relative to now. Supported phrases:
+//
+//	today, yesterday, tomorrow
+//	this|last|next day|week|month|quarter|year   (calendar periods)
+//	last|past N minutes|hours|days|weeks|months|years   (rolling, up to now)
+//	week|month|quarter|year to date, wtd, mtd, qtd, ytd
+func ResolveDateRange(column, phrase string, now time.Time) (string, error) {
+	at := "'" + now.Format("2006-01-02 15:04:05.999999Z07:00") + "'::timestamptz"
+	p := strings.Join(strings.Fields(strings.ToLower(phrase)), " ")
+	switch p {
+	case "today":
+		p = "this day"
+	case "yesterday":
+		p = "last day"
+	case "tomorrow":
+		p = "next day"
+	case "wtd", "mtd", "qtd", "ytd":
+		p = map[string]string{"wtd": "week", "mtd": "month", "qtd": "quarter", "ytd": "year"}[p] + " to date"
+	}
+
+	if m := calendarRangeRe.FindStringSubmatch(p); m != nil {
+		unit := calendarUnits[m[2]]
+		start := fmt.Sprintf("date_trunc('%s', %s)", unit.trunc, at)
+		switch m[1] {
+		case "last", "previous":
+			return fmt.Sprintf("%s >= %s - interval '%s' AND %s < %s", column, start, unit.length, column, start), nil
+		case "next":
+			return fmt.Sprintf("%s >= %s + interval '%s' AND %s < %s + interval '%s' * 2",
+				column, start, unit.length, column, start, unit.length), nil
+		}
+		return fmt.Sprintf("%s >= %s AND %s < %s + interval '%s'", column, start, column, start, unit.length), nil
+	}
+
+	if m := rollingRangeRe.FindStringSubmatch(p); m != nil {
+		n, err := strconv.Atoi(m[1])
+		if err != nil || n <= 0 {
+			return "", fmt.Errorf("invalid date range %q", phrase)
+		}
+		return fmt.Sprintf("%s >= %s - interval '%d %ss' AND %s <= %s", column, at, n, m[2], column, at), nil
+	}
+
+	if m := toDateRangeRe.FindStringSubmatch(p); m != nil {
+		unit := calendarUnits[m[1]]
+		return fmt.Sprintf("%s >= date_trunc('%s', %s) AND %s <= %s", column, unit.trunc, at, column, at), nil
+	}
+
+	return "", fmt.Errorf("unrecognized date range %q", phrase)
+}
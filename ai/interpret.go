@@ -0,0 +1,55 @@
+// interpret.go turns an executed query result back into a natural-language
+// answer, closing the question → plan → SQL → answer loop.
+//
+// Only the first rows of the result are sent to the provider, formatted as
+// a compact pipe-separated table to keep the prompt small.
+package ai
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// DefaultInterpretRows is the number of result rows sent for interpretation
+// when the config does not specify a value.
+const DefaultInterpretRows = 10
+
+// InterpretResult asks the provider for a one-paragraph answer to question
+// based on the first maxRows rows of a result set. totalRows is the full
+// result size so the model knows whether it is looking at a sample.
+func InterpretResult(ctx context.Context, p Provider, question string, columns []string, rows [][]string, totalRows int64, maxRows int) (string, error) {
+	if maxRows <= 0 {
+		maxRows = DefaultInterpretRows
+	}
+	if len(rows) > maxRows {
+		rows = rows[:maxRows]
+	}
+
+	var sb strings.Builder
+	sb.WriteString(strings.Join(columns, " | "))
+	sb.WriteString("\n")
+	for _, row := range rows {
+		sb.WriteString(strings.Join(row, " | "))
+		sb.WriteString("\n")
+	}
+
+	userContent := fmt.Sprintf("Question: %s\n\nResult (%d of %d rows):\n%s",
+		question, len(rows), totalRows, dataBlock(sb.String()))
+
+	messages := []Message{
+		{Role: "system", Content: systemPromptInterpret},
+		{Role: "user", Content: userContent},
+	}
+
+	LogAIRequest("Interpret", p.Name(), map[string]string{
+		"Question": question,
+		"Result":   sb.String(),
+	})
+	resp, err := p.Chat(ctx, messages)
+	LogAIResponse("Interpret", resp, err)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(SanitizeResponse(resp)), nil
+}
@@ -0,0 +1,79 @@
+// migration_review.go asks the provider to review a migration before it
+// runs: locking, table rewrites, missing indexes for new foreign keys and
+// irreversible steps, judged against the current size and shape of the
+// tables it touches.
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// MigrationRisk is one issue found in a migration.
+type MigrationRisk struct {
+	Severity   string `json:"severity"`  // "high", "medium" or "low"
+	Statement  string `json:"statement"` // start of the statement concerned
+	Issue      string `json:"issue"`
+	Suggestion string `json:"suggestion"`
+}
+
+// MigrationReview is the provider's verdict on a migration.
+type MigrationReview struct {
+	Summary string          `json:"summary"`
+	Risks   []MigrationRisk `json:"risks"`
+}
+
+var severityRank = map[string]int{"high": 0, "medium": 1, "low": 2}
+
+// ReviewMigration reviews sql given schemaContext, the state of the
+// tables it touches. Risks are sorted by severity, highest first.
+func ReviewMigration(ctx context.Context, p Provider, schemaContext, sql string) (*MigrationReview, error) {
+	messages := []Message{
+		{Role: "system", Content: systemPromptMigrationReview},
+		{Role: "user", Content: fmt.Sprintf("Current schema:\n%s\n\nMigration:\n%s", schemaContext, sql)},
+	}
+
+	LogAIRequest("MigrationReview", p.Name(), map[string]string{
+		"Schema":    schemaContext,
+		"Migration": sql,
+	})
+	resp, err := p.Chat(ctx, messages)
+	LogAIResponse("MigrationReview", resp, err)
+	if err != nil {
+		return nil, err
+	}
+	return ParseMigrationReview(resp)
+}
+
+// ParseMigrationReview extracts the review object from an AI response.
+func ParseMigrationReview(response string) (*MigrationReview, error) {
+	jsonStr := extractJSON(response)
+	if jsonStr == "" {
+		return nil, fmt.Errorf("no JSON found in AI response")
+	}
+
+	var review MigrationReview
+	if err := json.Unmarshal([]byte(jsonStr), &review); err != nil {
+		return nil, fmt.Errorf("failed to parse migration review JSON: %w", err)
+	}
+
+	review.Summary = SanitizeResponse(review.Summary)
+	for i := range review.Risks {
+		r := &review.Risks[i]
+		r.Statement, r.Issue, r.Suggestion = SanitizeResponse(r.Statement), SanitizeResponse(r.Issue), SanitizeResponse(r.Suggestion)
+	}
+	sort.SliceStable(review.Risks, func(i, j int) bool {
+		ri, ok := severityRank[review.Risks[i].Severity]
+		if !ok {
+			ri = len(severityRank)
+		}
+		rj, ok := severityRank[review.Risks[j].Severity]
+		if !ok {
+			rj = len(severityRank)
+		}
+		return ri < rj
+	})
+	return &review, nil
+}
@@ -7,15 +7,22 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
+	"time"
 )
 
 // Ollama implements the Provider interface for local Ollama instances.
 type Ollama struct {
-	host  string
-	model string
+	host       string
+	model      string
+	embedModel string
 }
 
-var _ Provider = (*Ollama)(nil)
+var (
+	_ Provider          = (*Ollama)(nil)
+	_ StreamingProvider = (*Ollama)(nil)
+	_ Embedder          = (*Ollama)(nil)
+)
 
 // NewOllama creates an Ollama provider.
 func NewOllama(host, model string) *Ollama {
@@ -25,7 +32,7 @@ func NewOllama(host, model string) *Ollama {
 	if model == "" {
 		model = "llama3.2"
 	}
-	return &Ollama{host: host, model: model}
+	return &Ollama{host: host, model: model, embedModel: "nomic-embed-text"}
 }
 
 func (o *Ollama) Name() string {
@@ -54,6 +61,79 @@ func (o *Ollama) GenerateQueryPlan(ctx context.Context, schemaContext string, us
 }
 
 func (o *Ollama) call(ctx context.Context, messages []Message) (string, error) {
+	resp, err := o.chatRequest(ctx, messages, false)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	var result struct {
+		Message struct {
+			Content string `json:"content"`
+		} `json:"message"`
+	}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return "", fmt.Errorf("ollama parse error: %w", err)
+	}
+
+	if result.Message.Content == "" {
+		return "", fmt.Errorf("ollama returned empty response")
+	}
+
+	return result.Message.Content, nil
+}
+
+// ChatStream implements StreamingProvider. Ollama streams the reply as
+// newline-delimited JSON objects, the last of which has "done": true.
+func (o *Ollama) ChatStream(ctx context.Context, messages []Message, onDelta func(string)) (string, error) {
+	resp, err := o.chatRequest(ctx, messages, true)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var reply strings.Builder
+	dec := json.NewDecoder(resp.Body)
+	for {
+		var chunk struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+			Done  bool   `json:"done"`
+			Error string `json:"error"`
+		}
+		if err := dec.Decode(&chunk); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return reply.String(), fmt.Errorf("ollama stream error: %w", err)
+		}
+		if chunk.Error != "" {
+			return reply.String(), fmt.Errorf("ollama error: %s", chunk.Error)
+		}
+		if chunk.Message.Content != "" {
+			reply.WriteString(chunk.Message.Content)
+			onDelta(chunk.Message.Content)
+		}
+		if chunk.Done {
+			break
+		}
+	}
+
+	if reply.Len() == 0 {
+		return "", fmt.Errorf("ollama returned empty response")
+	}
+	return reply.String(), nil
+}
+
+// chatRequest posts messages to /api/chat. The caller closes the body of
+// the returned response, which has status 200.
+func (o *Ollama) chatRequest(ctx context.Context, messages []Message, stream bool) (*http.Response, error) {
 	type chatMsg struct {
 		Role    string `json:"role"`
 		Content string `json:"content"`
@@ -74,48 +154,145 @@ func (o *Ollama) call(ctx context.Context, messages []Message) (string, error) {
 	body := map[string]interface{}{
 		"model":    o.model,
 		"messages": apiMsgs,
-		"stream":   false,
+		"stream":   stream,
 	}
+	return o.post(ctx, "/api/chat", body)
+}
 
+// post sends a JSON request to the Ollama API and checks the status.
+func (o *Ollama) post(ctx context.Context, path string, body any) (*http.Response, error) {
 	payload, err := json.Marshal(body)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	url := o.host + "/api/chat"
-	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(payload))
+	req, err := http.NewRequestWithContext(ctx, "POST", o.host+path, bytes.NewReader(payload))
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("ollama request failed (is Ollama running at %s?): %w", o.host, err)
+		return nil, fmt.Errorf("ollama request failed (is Ollama running at %s?): %w", o.host, err)
 	}
-	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		respBody, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode == http.StatusNotFound && path == "/api/chat" {
+			return nil, fmt.Errorf("ollama model %q is not installed (/pull in the AI view downloads it): %s", o.model, string(respBody))
+		}
+		if resp.StatusCode == http.StatusNotFound && path == "/api/embed" {
+			return nil, fmt.Errorf("ollama embedding model %q is not installed (/pull %s in the AI view downloads it): %s", o.embedModel, o.embedModel, string(respBody))
+		}
+		return nil, fmt.Errorf("ollama API error (%d): %s", resp.StatusCode, string(respBody))
+	}
+	return resp, nil
+}
 
-	respBody, err := io.ReadAll(resp.Body)
+// Model returns the configured model name.
+func (o *Ollama) Model() string { return o.model }
+
+// OllamaModel is a model installed in the local Ollama.
+type OllamaModel struct {
+	Name       string    `json:"name"`
+	Size       int64     `json:"size"`
+	ModifiedAt time.Time `json:"modified_at"`
+}
+
+// Models lists the installed models (GET /api/tags).
+func (o *Ollama) Models(ctx context.Context) ([]OllamaModel, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", o.host+"/api/tags", nil)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("ollama request failed (is Ollama running at %s?): %w", o.host, err)
+	}
+	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("ollama API error (%d): %s", resp.StatusCode, string(respBody))
+		respBody, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("ollama API error (%d): %s", resp.StatusCode, string(respBody))
 	}
 
 	var result struct {
-		Message struct {
-			Content string `json:"content"`
-		} `json:"message"`
+		Models []OllamaModel `json:"models"`
 	}
-	if err := json.Unmarshal(respBody, &result); err != nil {
-		return "", fmt.Errorf("ollama parse error: %w", err)
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("ollama parse error: %w", err)
 	}
+	return result.Models, nil
+}
 
-	if result.Message.Content == "" {
-		return "", fmt.Errorf("ollama returned empty response")
+// HasModel reports whether name is among models. A name without a tag
+// matches its ":latest" variant, as in Ollama itself.
+func HasModel(models []OllamaModel, name string) bool {
+	if !strings.Contains(name, ":") {
+		name += ":latest"
+	}
+	for _, m := range models {
+		if m.Name == name {
+			return true
+		}
 	}
+	return false
+}
 
-	return result.Message.Content, nil
+// PullProgress is one status update of a model download.
+type PullProgress struct {
+	Status    string `json:"status"` // e.g. "pulling manifest", "pulling 6a0746a1ec1a", "success"
+	Total     int64  `json:"total"`  // bytes of the layer being downloaded
+	Completed int64  `json:"completed"`
+}
+
+// Pull downloads model (POST /api/pull), calling onProgress with each
+// status update until the download finishes.
+func (o *Ollama) Pull(ctx context.Context, model string, onProgress func(PullProgress)) error {
+	resp, err := o.post(ctx, "/api/pull", map[string]any{"model": model, "stream": true})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	dec := json.NewDecoder(resp.Body)
+	for {
+		var p struct {
+			PullProgress
+			Error string `json:"error"`
+		}
+		if err := dec.Decode(&p); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return fmt.Errorf("ollama pull stream error: %w", err)
+		}
+		if p.Error != "" {
+			return fmt.Errorf("ollama pull %s: %s", model, p.Error)
+		}
+		onProgress(p.PullProgress)
+	}
+}
+
+// EmbedModel implements Embedder.
+func (o *Ollama) EmbedModel() string { return "ollama-" + o.embedModel }
+
+// Embed implements Embedder (POST /api/embed).
+func (o *Ollama) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	resp, err := o.post(ctx, "/api/embed", map[string]any{"model": o.embedModel, "input": texts})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Embeddings [][]float32 `json:"embeddings"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("ollama parse error: %w", err)
+	}
+	if len(result.Embeddings) != len(texts) {
+		return nil, fmt.Errorf("ollama returned %d embeddings for %d texts", len(result.Embeddings), len(texts))
+	}
+	return result.Embeddings, nil
 }
@@ -11,18 +11,22 @@ import (
 
 // OpenAI implements the Provider interface for OpenAI's Chat API.
 type OpenAI struct {
-	apiKey string
-	model  string
+	apiKey     string
+	model      string
+	embedModel string
 }
 
-var _ Provider = (*OpenAI)(nil)
+var (
+	_ Provider = (*OpenAI)(nil)
+	_ Embedder = (*OpenAI)(nil)
+)
 
 // NewOpenAI creates an OpenAI provider.
 func NewOpenAI(apiKey, model string) *OpenAI {
 	if model == "" {
 		model = "gpt-4o"
 	}
-	return &OpenAI{apiKey: apiKey, model: model}
+	return &OpenAI{apiKey: apiKey, model: model, embedModel: "text-embedding-3-small"}
 }
 
 func (o *OpenAI) Name() string {
@@ -118,3 +122,57 @@ func (o *OpenAI) call(ctx context.Context, messages []Message) (string, error) {
 
 	return result.Choices[0].Message.Content, nil
 }
+
+// EmbedModel implements Embedder.
+func (o *OpenAI) EmbedModel() string { return "openai-" + o.embedModel }
+
+// Embed implements Embedder using the embeddings API.
+func (o *OpenAI) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	payload, err := json.Marshal(map[string]any{"model": o.embedModel, "input": texts})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", "https://api.openai.com/v1/embeddings", bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+o.apiKey)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("openai request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("openai API error (%d): %s", resp.StatusCode, string(respBody))
+	}
+
+	var result struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, fmt.Errorf("openai parse error: %w", err)
+	}
+	vectors := make([][]float32, len(texts))
+	for _, d := range result.Data {
+		if d.Index >= 0 && d.Index < len(vectors) {
+			vectors[d.Index] = d.Embedding
+		}
+	}
+	for i, v := range vectors {
+		if v == nil {
+			return nil, fmt.Errorf("openai returned no embedding for input %d", i)
+		}
+	}
+	return vectors, nil
+}
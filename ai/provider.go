@@ -33,3 +33,21 @@ type Provider interface {
 	// Name returns the provider name for display.
 	Name() string
 }
+
+// StreamingProvider is implemented by providers that can stream chat
+// replies. ChatStream calls onDelta with each piece of the reply as it
+// arrives and returns the whole reply, like Chat.
+type StreamingProvider interface {
+	ChatStream(ctx context.Context, messages []Message, onDelta func(string)) (string, error)
+}
+
+// Embedder is implemented by providers with an embeddings API, used to
+// pick the tables relevant to a question (see schema_retrieval.go).
+type Embedder interface {
+	// Embed returns one vector per text.
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+
+	// EmbedModel names the embedding model; vectors from different
+	// models are not comparable.
+	EmbedModel() string
+}
@@ -12,6 +12,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 )
 
 // QueryPlan represents a structured query plan returned by the AI.
@@ -26,10 +27,16 @@ type QueryPlan struct {
 	// Filters lists WHERE conditions (e.g. "country.name = 'China'").
 	Filters []string `json:"filters"`
 
+	// DateRanges lists relative date conditions ("last week"); they are
+	// resolved into Filters by ParseQueryPlan.
+	DateRanges []QueryPlanDateRange `json:"date_ranges,omitempty"`
+
 	// Select lists the columns to return (e.g. "company.id", "company.name").
 	Select []string `json:"select"`
 
-	// Limit is the number of rows per page.
+	// Limit is the number of rows per page. ToSQL leaves out LIMIT and
+	// OFFSET when it is 0 (a whole-result export); ParseQueryPlan never
+	// returns 0.
 	Limit int `json:"limit"`
 
 	// Page is the current page number (1-based).
@@ -38,6 +45,10 @@ type QueryPlan struct {
 	// Sort specifies the ordering.
 	Sort *QueryPlanSort `json:"sort,omitempty"`
 
+	// PerGroup keeps only the first rows of each group, for questions like
+	// "latest order per customer" or "top 3 products per category".
+	PerGroup *QueryPlanPerGroup `json:"per_group,omitempty"`
+
 	// NeedOtherTables is true when the request cannot be satisfied
 	// with the current table and its FK-related tables.
 	NeedOtherTables bool `json:"need_other_tables,omitempty"`
@@ -63,6 +74,15 @@ type QueryPlanSort struct {
 	Order  string `json:"order"` // "asc" or "desc"
 }
 
+// QueryPlanPerGroup describes a top-N-per-group window: rows are numbered
+// within each partition and only the first Limit rows are kept.
+type QueryPlanPerGroup struct {
+	PartitionBy []string      `json:"partition_by"`       // e.g. ["orders.customer_id"]
+	OrderBy     QueryPlanSort `json:"order_by"`           // ranking order within a group
+	Limit       int           `json:"limit"`              // rows per group (default 1)
+	Function    string        `json:"function,omitempty"` // "row_number" (default), "rank" or "dense_rank"
+}
+
 // ParseQueryPlan extracts a QueryPlan from the AI's response text.
 // The response may contain markdown fencing or surrounding text,
 // so we search for the JSON object within it.
@@ -78,6 +98,8 @@ func ParseQueryPlan(response string) (*QueryPlan, error) {
 		return nil, fmt.Errorf("failed to parse query plan JSON: %w\nRaw: %s", err, jsonStr)
 	}
 
+	plan.Description = SanitizeResponse(plan.Description)
+
 	// Default action to "select"
 	if plan.Action == "" {
 		plan.Action = "select"
@@ -93,6 +115,23 @@ func ParseQueryPlan(response string) (*QueryPlan, error) {
 		plan.Page = 1
 	}
 
+	now := time.Now()
+	for _, dr := range plan.DateRanges {
+		filter, err := ResolveDateRange(dr.Column, dr.Range, now)
+		if err != nil {
+			return nil, err
+		}
+		plan.Filters = append(plan.Filters, filter)
+	}
+
+	if g := plan.PerGroup; g != nil {
+		if len(g.PartitionBy) == 0 {
+			plan.PerGroup = nil // nothing to group by; plain select
+		} else if g.Limit <= 0 {
+			g.Limit = 1
+		}
+	}
+
 	return &plan, nil
 }
 
@@ -160,6 +199,10 @@ func (p *QueryPlan) toSelectSQL() (string, error) {
 		return "", fmt.Errorf("query plan has no tables")
 	}
 
+	if p.PerGroup != nil {
+		return p.toPerGroupSQL()
+	}
+
 	// SELECT columns
 	selectCols := "*"
 	if len(p.Select) > 0 {
@@ -188,10 +231,12 @@ func (p *QueryPlan) toSelectSQL() (string, error) {
 	}
 
 	// LIMIT & OFFSET
-	sql += fmt.Sprintf("\nLIMIT %d", p.Limit)
-	if p.Page > 1 {
-		offset := (p.Page - 1) * p.Limit
-		sql += fmt.Sprintf(" OFFSET %d", offset)
+	if p.Limit > 0 {
+		sql += fmt.Sprintf("\nLIMIT %d", p.Limit)
+		if p.Page > 1 {
+			offset := (p.Page - 1) * p.Limit
+			sql += fmt.Sprintf(" OFFSET %d", offset)
+		}
 	}
 
 	return sql, nil
@@ -204,6 +249,10 @@ func (p *QueryPlan) ToCountSQL() string {
 		return ""
 	}
 
+	if p.PerGroup != nil {
+		return "SELECT count(*) FROM " + p.rankedSubquery()
+	}
+
 	sql := fmt.Sprintf("SELECT count(*) FROM %s", p.buildFromClause())
 
 	if len(p.Filters) > 0 {
@@ -213,6 +262,76 @@ func (p *QueryPlan) ToCountSQL() string {
 	return sql
 }
 
+// rankedSubquery numbers the rows of each group with a window function
+// and keeps the first PerGroup.Limit of them. Window functions can't be
+// used in WHERE, hence the subquery; the rank is returned as group_rank.
+func (p *QueryPlan) rankedSubquery() string {
+	g := p.PerGroup
+
+	selectCols := "*"
+	if len(p.Select) > 0 {
+		selectCols = strings.Join(p.Select, ", ")
+	}
+
+	fn := strings.ToLower(g.Function)
+	if fn != "rank" && fn != "dense_rank" {
+		fn = "row_number"
+	}
+	window := "PARTITION BY " + strings.Join(g.PartitionBy, ", ")
+	if g.OrderBy.Column != "" {
+		window += " ORDER BY " + g.OrderBy.Column + " " + sortDirection(g.OrderBy.Order)
+	}
+
+	inner := fmt.Sprintf("SELECT %s, %s() OVER (%s) AS group_rank\n  FROM %s",
+		selectCols, fn, window, strings.ReplaceAll(p.buildFromClause(), "\n", "\n  "))
+	if len(p.Filters) > 0 {
+		inner += "\n  WHERE " + strings.Join(p.Filters, "\n    AND ")
+	}
+	return fmt.Sprintf("(\n  %s\n) ranked\nWHERE group_rank <= %d", inner, g.Limit)
+}
+
+// toPerGroupSQL wraps rankedSubquery in the paginated outer SELECT.
+// Outside the subquery, table qualifiers no longer resolve, so the
+// ordering uses bare column names.
+func (p *QueryPlan) toPerGroupSQL() (string, error) {
+	sql := "SELECT *\nFROM " + p.rankedSubquery()
+
+	var order []string
+	if p.Sort != nil && p.Sort.Column != "" {
+		order = append(order, unqualified(p.Sort.Column)+" "+sortDirection(p.Sort.Order))
+	} else {
+		for _, col := range p.PerGroup.PartitionBy {
+			order = append(order, unqualified(col))
+		}
+	}
+	order = append(order, "group_rank")
+	sql += "\nORDER BY " + strings.Join(order, ", ")
+
+	if p.Limit > 0 {
+		sql += fmt.Sprintf("\nLIMIT %d", p.Limit)
+		if p.Page > 1 {
+			sql += fmt.Sprintf(" OFFSET %d", (p.Page-1)*p.Limit)
+		}
+	}
+	return sql, nil
+}
+
+// sortDirection normalizes a plan's sort order to ASC or DESC.
+func sortDirection(order string) string {
+	if strings.EqualFold(order, "desc") {
+		return "DESC"
+	}
+	return "ASC"
+}
+
+// unqualified strips the table from a "table.column" reference.
+func unqualified(col string) string {
+	if i := strings.LastIndex(col, "."); i >= 0 {
+		return col[i+1:]
+	}
+	return col
+}
+
 // buildFromClause builds the FROM + JOIN clause shared by SELECT and COUNT queries.
 func (p *QueryPlan) buildFromClause() string {
 	fromClause := p.Tables[0]
@@ -315,6 +434,13 @@ func (p *QueryPlan) Summary() string {
 	if len(p.Filters) > 0 {
 		summary += " where " + strings.Join(p.Filters, " and ")
 	}
+	if g := p.PerGroup; g != nil {
+		summary += fmt.Sprintf(" (top %d per %s", g.Limit, strings.Join(g.PartitionBy, ", "))
+		if g.OrderBy.Column != "" {
+			summary += fmt.Sprintf(" by %s %s", g.OrderBy.Column, g.OrderBy.Order)
+		}
+		summary += ")"
+	}
 	if p.Sort != nil {
 		summary += fmt.Sprintf(" order by %s %s", p.Sort.Column, p.Sort.Order)
 	}
@@ -17,7 +17,11 @@ func NewProvider(cfg config.AIConfig) (Provider, error) {
 		if cfg.OpenAI.APIKey == "" {
 			return nil, fmt.Errorf("OpenAI API key not set. Set OPENAI_API_KEY env var or add it to ~/.paisql/config.json")
 		}
-		return NewOpenAI(cfg.OpenAI.APIKey, cfg.OpenAI.Model), nil
+		p := NewOpenAI(cfg.OpenAI.APIKey, cfg.OpenAI.Model)
+		if cfg.OpenAI.EmbedModel != "" {
+			p.embedModel = cfg.OpenAI.EmbedModel
+		}
+		return p, nil
 
 	case "anthropic":
 		if cfg.Anthropic.APIKey == "" {
@@ -38,7 +42,11 @@ func NewProvider(cfg config.AIConfig) (Provider, error) {
 		return NewGroq(cfg.Groq.APIKey, cfg.Groq.Model), nil
 
 	case "ollama":
-		return NewOllama(cfg.Ollama.Host, cfg.Ollama.Model), nil
+		p := NewOllama(cfg.Ollama.Host, cfg.Ollama.Model)
+		if cfg.Ollama.EmbedModel != "" {
+			p.embedModel = cfg.Ollama.EmbedModel
+		}
+		return p, nil
 
 	case "antigravity":
 		return NewAntigravity(cfg.Antigravity.Model), nil
@@ -0,0 +1,35 @@
+// sanitize.go cleans AI responses before they are shown. A response can
+// echo text from the schema or from data values sent in the prompt, so
+// terminal escape sequences and other control characters are removed
+// before they reach the screen.
+package ai
+
+import (
+	"regexp"
+	"strings"
+)
+
+// ansiEscape matches CSI and OSC escape sequences (colors, cursor moves,
+// window titles, hyperlinks).
+var ansiEscape = regexp.MustCompile(`\x1b(\[[0-?]*[ -/]*[@-~]|\][^\x07\x1b]*(\x07|\x1b\\)?|.)`)
+
+// SanitizeResponse strips escape sequences and control characters other
+// than newline and tab from an AI response.
+func SanitizeResponse(s string) string {
+	s = ansiEscape.ReplaceAllString(s, "")
+	return strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\t' {
+			return r
+		}
+		if r < 0x20 || r == 0x7f || (r >= 0x80 && r < 0xa0) {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// dataBlock labels values from the database sent in a prompt, so the
+// model treats them as data and not as instructions.
+func dataBlock(text string) string {
+	return "<data>\n" + strings.ReplaceAll(text, "</data>", "<\\/data>") + "</data>"
+}
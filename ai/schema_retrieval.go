@@ -0,0 +1,96 @@
+// schema_retrieval.go picks the tables relevant to a question by
+// embedding similarity. Each table is embedded as its one-line schema
+// index entry (name, columns, types and comments); embeddings are cached
+// on disk per model, so only new or changed tables are embedded again.
+package ai
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/DachengChen/paiSQL/config"
+)
+
+// DefaultRetrieveTables is the number of tables retrieved per question
+// when the config doesn't set one.
+const DefaultRetrieveTables = 5
+
+// embedBatch is the number of texts sent per embeddings request.
+const embedBatch = 64
+
+// RetrieveTables returns the names of the k docs (table name → text)
+// most similar to question, best first.
+func RetrieveTables(ctx context.Context, e Embedder, docs map[string]string, question string, k int) ([]string, error) {
+	if k <= 0 {
+		k = DefaultRetrieveTables
+	}
+	cache, err := config.LoadEmbeddingCache(e.EmbedModel())
+	if err != nil {
+		return nil, fmt.Errorf("embedding cache: %w", err)
+	}
+
+	var missing []string
+	for _, text := range docs {
+		if _, ok := cache.Get(text); !ok {
+			missing = append(missing, text)
+		}
+	}
+	for start := 0; start < len(missing); start += embedBatch {
+		batch := missing[start:min(start+embedBatch, len(missing))]
+		vectors, err := e.Embed(ctx, batch)
+		if err != nil {
+			_ = cache.Save() // keep the batches that succeeded
+			return nil, fmt.Errorf("embedding schema: %w", err)
+		}
+		for i, text := range batch {
+			cache.Put(text, vectors[i])
+		}
+	}
+	_ = cache.Save() // if this fails, the tables are embedded again next time
+
+	q, err := e.Embed(ctx, []string{question})
+	if err != nil {
+		return nil, fmt.Errorf("embedding question: %w", err)
+	}
+
+	type scored struct {
+		table string
+		score float64
+	}
+	ranked := make([]scored, 0, len(docs))
+	for table, text := range docs {
+		v, _ := cache.Get(text)
+		ranked = append(ranked, scored{table, cosine(q[0], v)})
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].score != ranked[j].score {
+			return ranked[i].score > ranked[j].score
+		}
+		return ranked[i].table < ranked[j].table
+	})
+
+	names := make([]string, 0, k)
+	for _, r := range ranked[:min(k, len(ranked))] {
+		names = append(names, r.table)
+	}
+	return names, nil
+}
+
+// cosine is the cosine similarity of a and b, or 0 if their lengths differ.
+func cosine(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
@@ -0,0 +1,60 @@
+// seed.go asks the AI provider for realistic sample rows for a table.
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// MaxSeedRows caps how many rows are requested from the provider in one
+// call; larger seeds fill the remainder with rule-based generators.
+const MaxSeedRows = 50
+
+// GenerateSeedRows asks the provider for n rows matching tableDescription,
+// returned as column → value maps.
+func GenerateSeedRows(ctx context.Context, p Provider, tableDescription string, n int) ([]map[string]any, error) {
+	if n > MaxSeedRows {
+		n = MaxSeedRows
+	}
+	messages := []Message{
+		{Role: "system", Content: systemPromptSeed},
+		{Role: "user", Content: fmt.Sprintf("%s\nGenerate %d rows.", tableDescription, n)},
+	}
+
+	LogAIRequest("Seed", p.Name(), map[string]string{
+		"Table": tableDescription,
+		"Rows":  fmt.Sprint(n),
+	})
+	resp, err := p.Chat(ctx, messages)
+	LogAIResponse("Seed", resp, err)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonStr := extractJSON(resp)
+	if jsonStr == "" {
+		return nil, fmt.Errorf("no JSON found in AI response")
+	}
+	var out struct {
+		Rows []map[string]any `json:"rows"`
+	}
+	dec := json.NewDecoder(strings.NewReader(jsonStr))
+	dec.UseNumber() // keep numbers as written, e.g. no 1e+06
+	if err := dec.Decode(&out); err != nil {
+		return nil, fmt.Errorf("failed to parse seed rows JSON: %w", err)
+	}
+
+	// Nested JSON values are meant for json/jsonb columns: keep them as text
+	for _, row := range out.Rows {
+		for k, v := range row {
+			switch v.(type) {
+			case map[string]any, []any:
+				b, _ := json.Marshal(v)
+				row[k] = string(b)
+			}
+		}
+	}
+	return out.Rows, nil
+}
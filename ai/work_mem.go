@@ -0,0 +1,26 @@
+// work_mem.go asks the provider how to tune work_mem, given the temporary
+// files the server writes and the statements writing them.
+package ai
+
+import (
+	"context"
+	"strings"
+)
+
+// SuggestWorkMem returns the provider's advice on work_mem for report, a
+// plain-text summary of the settings, the temp file usage and the
+// spilling statements.
+func SuggestWorkMem(ctx context.Context, p Provider, report string) (string, error) {
+	messages := []Message{
+		{Role: "system", Content: systemPromptWorkMem},
+		{Role: "user", Content: "Temporary file usage:\n" + dataBlock(report)},
+	}
+
+	LogAIRequest("WorkMem", p.Name(), map[string]string{"Report": report})
+	resp, err := p.Chat(ctx, messages)
+	LogAIResponse("WorkMem", resp, err)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(SanitizeResponse(resp)), nil
+}
@@ -9,12 +9,14 @@ import (
 	"os"
 	"path/filepath"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 var (
 	once    sync.Once
 	logFile *os.File
+	debug   atomic.Bool
 )
 
 func init() {
@@ -56,6 +58,26 @@ func Error(format string, args ...interface{}) {
 	write(fmt.Sprintf("[%s] ERROR %s\n", ts, msg))
 }
 
+// SetDebug turns Debug logging on or off (paisql --debug).
+func SetDebug(on bool) {
+	debug.Store(on)
+}
+
+// DebugEnabled reports whether Debug messages are written.
+func DebugEnabled() bool {
+	return debug.Load()
+}
+
+// Debug logs a debug message when enabled with SetDebug.
+func Debug(format string, args ...interface{}) {
+	if !debug.Load() {
+		return
+	}
+	ts := time.Now().Format("2006-01-02 15:04:05.000")
+	msg := fmt.Sprintf(format, args...)
+	write(fmt.Sprintf("[%s] DEBUG %s\n", ts, msg))
+}
+
 // Event logs a structured event with a category.
 func Event(category string, format string, args ...interface{}) {
 	ts := time.Now().Format("2006-01-02 15:04:05")
@@ -0,0 +1,161 @@
+// connections.go implements `paisql connections encrypt|decrypt`, which
+// turn encryption of the saved connections file on and off, the terminal
+// prompt for its passphrase, and `paisql connections share`, which copies a
+// saved connection into the project workspace.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/DachengChen/paiSQL/config"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var connectionsCmd = &cobra.Command{
+	Use:   "connections",
+	Short: "Encrypt, decrypt or share the saved connections",
+	Long: `Saved connections, passwords included, are kept in
+~/.paisql/connections.json. 'encrypt' seals the file with a passphrase,
+which paisql then asks for once on start ($PAISQL_PASSPHRASE skips the
+prompt); 'decrypt' stores it in plaintext again.
+
+'share' copies a saved connection, without its passwords, to the
+connections.json of the project workspace: the .paisql directory found
+from the working directory, created here if there is none. Commit it and
+everyone working on the project gets the connection.`,
+}
+
+var connectionsShareCmd = &cobra.Command{
+	Use:   "share <name>",
+	Short: "Copy a saved connection, without its passwords, into the workspace",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		store, err := config.NewConnectionStore()
+		if err != nil {
+			return err
+		}
+		conn, ok := store.Get(args[0])
+		if !ok {
+			return fmt.Errorf("no saved connection named %q", args[0])
+		}
+		if conn.Shared {
+			return fmt.Errorf("%q is already shared in %s", conn.Name, store.Workspace)
+		}
+		path, err := config.ShareConnection(conn)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Shared %s in %s.\n", conn.Name, path)
+		return nil
+	},
+}
+
+var connectionsEncryptCmd = &cobra.Command{
+	Use:   "encrypt",
+	Short: "Encrypt the saved connections with a passphrase, or change it",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := config.NewConnectionStore()
+		if err != nil {
+			return err
+		}
+		passphrase, err := readPassphrase("New passphrase: ")
+		if err != nil {
+			return err
+		}
+		if passphrase == "" {
+			return fmt.Errorf("the passphrase cannot be empty (decrypt stores the file in plaintext)")
+		}
+		again, err := readPassphrase("Repeat it: ")
+		if err != nil {
+			return err
+		}
+		if again != passphrase {
+			return fmt.Errorf("the passphrases don't match")
+		}
+		changed := store.Encrypted()
+		if err := store.SetPassphrase(passphrase); err != nil {
+			return err
+		}
+		if err := store.Save(); err != nil {
+			return err
+		}
+		if changed {
+			fmt.Fprintln(cmd.OutOrStdout(), "Passphrase changed.")
+		} else {
+			fmt.Fprintf(cmd.OutOrStdout(), "Encrypted %d saved connections.\n", ownConnections(store))
+		}
+		return nil
+	},
+}
+
+var connectionsDecryptCmd = &cobra.Command{
+	Use:   "decrypt",
+	Short: "Store the saved connections in plaintext again",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := config.NewConnectionStore()
+		if err != nil {
+			return err
+		}
+		if !store.Encrypted() {
+			fmt.Fprintln(cmd.OutOrStdout(), "The saved connections aren't encrypted.")
+			return nil
+		}
+		if err := store.SetPassphrase(""); err != nil {
+			return err
+		}
+		if err := store.Save(); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Decrypted %d saved connections.\n", ownConnections(store))
+		return nil
+	},
+}
+
+func init() {
+	config.PassphrasePrompt = func() (string, error) {
+		passphrase, err := readPassphrase("Passphrase for the saved connections: ")
+		if errors.Is(err, errNoTerminal) {
+			return "", config.ErrConnectionsLocked
+		}
+		return passphrase, err
+	}
+	connectionsCmd.AddCommand(connectionsEncryptCmd, connectionsDecryptCmd, connectionsShareCmd)
+	rootCmd.AddCommand(connectionsCmd)
+}
+
+// ownConnections counts the connections saved in ~/.paisql, leaving out
+// the workspace's.
+func ownConnections(store *config.ConnectionStore) int {
+	n := 0
+	for _, c := range store.Connections {
+		if !c.Shared {
+			n++
+		}
+	}
+	return n
+}
+
+var errNoTerminal = errors.New("no terminal to read the passphrase from")
+
+// readPassphrase asks for a passphrase on the terminal without echoing
+// it. Without a terminal there is no one to ask.
+func readPassphrase(prompt string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return "", errNoTerminal
+	}
+	fmt.Fprint(os.Stderr, prompt)
+	b, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
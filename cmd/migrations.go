@@ -0,0 +1,104 @@
+// migrations.go implements `paisql migrations`, a status/apply command for
+// migration tools that keep their history in the database.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/DachengChen/paiSQL/config"
+	"github.com/DachengChen/paiSQL/db"
+	"github.com/spf13/cobra"
+)
+
+var (
+	migrationsDir   string
+	migrationsApply bool
+)
+
+var migrationsCmd = &cobra.Command{
+	Use:   "migrations <connection>",
+	Short: "Show and apply schema migrations for a saved connection",
+	Long: `Detects the migration table used by golang-migrate (schema_migrations),
+Rails (schema_migrations), Flyway (flyway_schema_history) or goose
+(goose_db_version), lists applied versions and the pending files in the
+migrations directory, and with --apply runs the pending files in version
+order — each file and its bookkeeping row in one transaction.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runMigrations,
+}
+
+func init() {
+	migrationsCmd.Flags().StringVarP(&migrationsDir, "dir", "d", "", `migrations directory (default from config, else "migrations")`)
+	migrationsCmd.Flags().BoolVar(&migrationsApply, "apply", false, "apply pending migrations in order")
+	rootCmd.AddCommand(migrationsCmd)
+}
+
+func runMigrations(cmd *cobra.Command, args []string) error {
+	dir := migrationsDir
+	if dir == "" {
+		if appCfg, err := config.LoadAppConfig(); err == nil {
+			dir = appCfg.MigrationsDir
+		}
+	}
+	if dir == "" {
+		dir = "migrations"
+	}
+
+	ctx := context.Background()
+	database, err := connectSaved(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	status, err := database.DetectMigrations(ctx)
+	if err != nil {
+		return err
+	}
+	if status.Tool == "" {
+		return fmt.Errorf("no migration table found (schema_migrations, flyway_schema_history, goose_db_version)")
+	}
+
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "Migration tool: %s (%s)\n\n", status.Tool, status.Table)
+	fmt.Fprintf(out, "Applied (%d):\n", len(status.Applied))
+	for _, m := range status.Applied {
+		mark := "✓"
+		if !m.Success {
+			mark = "✗"
+		}
+		fmt.Fprintf(out, "  %s %-16s %-32s %s\n", mark, m.Version, m.Description, m.AppliedAt)
+	}
+	if status.Dirty {
+		fmt.Fprintln(out, "\n⚠️  Database is dirty: the last migration failed part way.")
+	}
+
+	if status.Tool == db.MigrationToolRails {
+		fmt.Fprintln(out, "\nRails migrations are Ruby files; use rails db:migrate to apply them.")
+		return nil
+	}
+	files, err := db.ScanMigrationFiles(dir, status.Tool)
+	if err != nil {
+		return fmt.Errorf("scan %s: %w", dir, err)
+	}
+	status.SetPending(files)
+
+	fmt.Fprintf(out, "\nPending in %s (%d):\n", dir, len(status.Pending))
+	for _, f := range status.Pending {
+		fmt.Fprintf(out, "  • %-16s %s\n", f.Version, f.Name)
+	}
+	if !migrationsApply || len(status.Pending) == 0 {
+		return nil
+	}
+
+	fmt.Fprintln(out)
+	for _, f := range status.Pending {
+		if err := database.ApplyMigration(ctx, status, f); err != nil {
+			return fmt.Errorf("migration %s failed and was rolled back: %w", f.Version, err)
+		}
+		fmt.Fprintf(out, "  applied %s %s\n", f.Version, f.Name)
+	}
+	return nil
+}
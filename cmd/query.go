@@ -0,0 +1,359 @@
+// query.go implements `paisql query`, which runs one statement without the
+// TUI and prints its result for scripts and pipelines.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/DachengChen/paiSQL/config"
+	"github.com/DachengChen/paiSQL/db"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+)
+
+var (
+	queryFormat  string
+	queryConnect string
+	queryConn    config.Connection // --host etc., over the saved connection or the defaults
+)
+
+var queryCmd = &cobra.Command{
+	Use:   "query <sql>",
+	Short: "Run a statement and print its result",
+	Long: `Runs one statement and prints its result to stdout as an aligned table,
+CSV or JSON, for use from scripts. Pass - as the statement to read it
+from stdin.
+
+Connects to a saved connection (--connect) or with --host, --port, --user,
+--dbname and --sslmode, which also override a saved connection's settings.
+The password comes from --password or PGPASSWORD.
+
+A failed connection or statement prints the error to stderr and exits
+with status 1.`,
+	Example: `  paisql query -c prod "SELECT count(*) FROM orders"
+  paisql query --host db.local -d shop -f csv "SELECT * FROM users" > users.csv
+  echo "SELECT now()" | paisql query -c dev -f json -`,
+	Args: cobra.ExactArgs(1),
+	RunE: runQuery,
+}
+
+func init() {
+	defaults := config.DefaultConnection()
+	f := queryCmd.Flags()
+	f.StringVarP(&queryFormat, "format", "f", "table", "output format: table, csv or json")
+	f.StringVarP(&queryConnect, "connect", "c", "", "saved connection to use")
+	f.StringVar(&queryConn.Host, "host", defaults.Host, "database host")
+	f.StringVarP(&queryConn.Port, "port", "p", defaults.Port, "database port")
+	f.StringVarP(&queryConn.User, "user", "U", defaults.User, "database user")
+	f.StringVar(&queryConn.Password, "password", "", "database password (default $PGPASSWORD)")
+	f.StringVarP(&queryConn.Database, "dbname", "d", defaults.Database, "database name")
+	f.StringVar(&queryConn.SSLMode, "sslmode", defaults.SSLMode, "SSL mode")
+	rootCmd.AddCommand(queryCmd)
+}
+
+func runQuery(cmd *cobra.Command, args []string) error {
+	switch queryFormat {
+	case "table", "csv", "json":
+	default:
+		return fmt.Errorf("unknown format %q (want table, csv or json)", queryFormat)
+	}
+	sql := args[0]
+	if sql == "-" {
+		b, err := io.ReadAll(cmd.InOrStdin())
+		if err != nil {
+			return fmt.Errorf("reading the statement: %w", err)
+		}
+		sql = string(b)
+	}
+	if strings.TrimSpace(sql) == "" {
+		return fmt.Errorf("no statement to run")
+	}
+	// Arguments are fine from here on: don't follow a database error with
+	// the usage text.
+	cmd.SilenceUsage = true
+
+	conn, err := queryConnection(cmd)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	database, err := db.Connect(ctx, config.FromConnection(conn))
+	if err != nil {
+		if queryConnect != "" {
+			return fmt.Errorf("%s: %w", queryConnect, err)
+		}
+		return err
+	}
+	defer database.Close()
+
+	result, err := database.QueryValues(ctx, sql)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	switch queryFormat {
+	case "csv":
+		err = writeQueryCSV(out, result)
+	case "json":
+		err = writeQueryJSON(out, result)
+	default:
+		err = writeQueryTable(out, result)
+	}
+	if err != nil {
+		return err
+	}
+	// A statement without rows has only its command tag to show; keep it
+	// off stdout where that is data.
+	if len(result.Columns) == 0 && queryFormat != "table" && result.Status != "" {
+		fmt.Fprintln(cmd.ErrOrStderr(), result.Status)
+	}
+	return nil
+}
+
+// queryConnection returns the connection settings: the saved connection
+// or the defaults, with the flags given on the command line over them.
+func queryConnection(cmd *cobra.Command) (config.Connection, error) {
+	conn := queryConn
+	if queryConnect != "" {
+		store, err := config.NewConnectionStore()
+		if err != nil {
+			return conn, err
+		}
+		saved, ok := store.Get(queryConnect)
+		if !ok {
+			return conn, fmt.Errorf("no saved connection named %q", queryConnect)
+		}
+		flags := cmd.Flags()
+		for name, value := range map[string]*string{
+			"host": &saved.Host, "port": &saved.Port, "user": &saved.User,
+			"password": &saved.Password, "dbname": &saved.Database, "sslmode": &saved.SSLMode,
+		} {
+			if flags.Changed(name) {
+				*value = flags.Lookup(name).Value.String()
+			}
+		}
+		conn = saved
+	}
+	if conn.Password == "" {
+		conn.Password = os.Getenv("PGPASSWORD")
+	}
+	return conn, nil
+}
+
+// writeQueryTable prints the result aligned like psql, with NULL as an
+// empty cell and the row count or command tag at the end.
+func writeQueryTable(w io.Writer, r *db.ValueResult) error {
+	if len(r.Columns) == 0 {
+		_, err := fmt.Fprintln(w, r.Status)
+		return err
+	}
+	rows := queryTextRows(r)
+	widths := make([]int, len(r.Columns))
+	for i, c := range r.Columns {
+		widths[i] = lipgloss.Width(c)
+	}
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], lipgloss.Width(cell))
+		}
+	}
+
+	var b strings.Builder
+	line := func(cells []string) {
+		for i, w := range widths {
+			cell := ""
+			if i < len(cells) {
+				cell = cells[i]
+			}
+			if i > 0 {
+				b.WriteString(" | ")
+			}
+			if i == len(widths)-1 {
+				b.WriteString(cell)
+			} else {
+				b.WriteString(cell + strings.Repeat(" ", w-lipgloss.Width(cell)))
+			}
+		}
+		b.WriteString("\n")
+	}
+	line(r.Columns)
+	for i, w := range widths {
+		if i > 0 {
+			b.WriteString("-+-")
+		}
+		b.WriteString(strings.Repeat("-", w))
+	}
+	b.WriteString("\n")
+	for _, row := range rows {
+		line(row)
+	}
+	fmt.Fprintf(&b, "%s\n", r.Status)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeQueryCSV prints the result as RFC 4180 CSV with a header row and
+// NULL as an empty field.
+func writeQueryCSV(w io.Writer, r *db.ValueResult) error {
+	if len(r.Columns) == 0 {
+		return nil
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.Write(r.Columns); err != nil {
+		return err
+	}
+	for _, record := range queryTextRows(r) {
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// queryTextRows renders the result's rows as text, one cell per column,
+// with NULL as an empty cell.
+func queryTextRows(r *db.ValueResult) [][]string {
+	rows := make([][]string, len(r.Rows))
+	for n, values := range r.Rows {
+		row := make([]string, len(r.Columns))
+		for i := range row {
+			if i < len(values) {
+				row[i] = queryText(values[i])
+			}
+		}
+		rows[n] = row
+	}
+	return rows
+}
+
+// queryText renders a value as text: times in RFC 3339, bytea in
+// PostgreSQL's hex format, json and arrays as JSON, and the rest like the
+// TUI shows them.
+func queryText(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case time.Time:
+		return val.Format(time.RFC3339Nano)
+	case []byte:
+		return `\x` + hex.EncodeToString(val)
+	case map[string]any, []any:
+		b, err := marshalQueryJSON(queryJSONValue(val))
+		if err == nil {
+			return string(b)
+		}
+	}
+	return db.FormatValue(v)
+}
+
+// writeQueryJSON prints the result as a JSON array with an object per
+// row, keyed by column in result order. NULL is null; numbers, booleans,
+// json and arrays keep their JSON type, times are RFC 3339 strings and
+// everything else is a string.
+func writeQueryJSON(w io.Writer, r *db.ValueResult) error {
+	if len(r.Columns) == 0 {
+		return nil
+	}
+	keys := make([][]byte, len(r.Columns))
+	for i, c := range r.Columns {
+		keys[i], _ = marshalQueryJSON(c)
+	}
+
+	var b bytes.Buffer
+	b.WriteString("[")
+	for n, row := range r.Rows {
+		if n > 0 {
+			b.WriteString(",")
+		}
+		b.WriteString("\n  {")
+		for i := range r.Columns {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			b.Write(keys[i])
+			b.WriteString(": ")
+			var value any
+			if i < len(row) {
+				value = row[i]
+			}
+			cell, err := marshalQueryJSON(queryJSONValue(value))
+			if err != nil {
+				return fmt.Errorf("column %s: %w", r.Columns[i], err)
+			}
+			b.Write(cell)
+		}
+		b.WriteString("}")
+	}
+	if len(r.Rows) > 0 {
+		b.WriteString("\n")
+	}
+	b.WriteString("]\n")
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// marshalQueryJSON encodes v as JSON, leaving <, > and & as they are:
+// the output is data, not HTML.
+func marshalQueryJSON(v any) ([]byte, error) {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(b.Bytes(), []byte("\n")), nil
+}
+
+// queryJSONValue turns a value, and the elements of json and arrays,
+// into one json.Marshal encodes as its JSON counterpart. What has none,
+// such as NaN, Infinity or a uuid, becomes its text.
+func queryJSONValue(v any) any {
+	switch val := v.(type) {
+	case nil, bool, string,
+		int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return val
+	case float32:
+		return queryJSONValue(float64(val))
+	case float64:
+		if math.IsNaN(val) || math.IsInf(val, 0) {
+			return db.FormatValue(val)
+		}
+		return val
+	case time.Time, []byte:
+		return queryText(val)
+	case []any:
+		elems := make([]any, len(val))
+		for i, e := range val {
+			elems[i] = queryJSONValue(e)
+		}
+		return elems
+	case map[string]any:
+		fields := make(map[string]any, len(val))
+		for k, e := range val {
+			fields[k] = queryJSONValue(e)
+		}
+		return fields
+	case json.Marshaler:
+		// pgtype values such as numeric: kept when they encode as JSON,
+		// which numeric's Infinity doesn't.
+		if b, err := json.Marshal(val); err == nil {
+			return json.RawMessage(b)
+		}
+	}
+	return db.FormatValue(v)
+}
@@ -7,12 +7,18 @@
 package cmd
 
 import (
+	"context"
+	"fmt"
+
+	"github.com/DachengChen/paiSQL/applog"
+	"github.com/DachengChen/paiSQL/config"
+	"github.com/DachengChen/paiSQL/db"
 	"github.com/DachengChen/paiSQL/tui"
 	"github.com/spf13/cobra"
 )
 
 var rootCmd = &cobra.Command{
-	Use:   "paisql",
+	Use:   "paisql [connection]",
 	Short: "PostgreSQL CLI with TUI and AI assistant",
 	Long: `paiSQL is a PostgreSQL CLI tool featuring:
   • Multi-view TUI (SQL, Explain, Stats, Logs, AI)
@@ -20,14 +26,94 @@ var rootCmd = &cobra.Command{
   • Optional SSH tunnel for remote servers
   • Keyboard-driven navigation
 
-Run 'paisql' to start the TUI with a connection setup screen.`,
+Run 'paisql' to start the TUI with a connection setup screen, or
+'paisql <connection>' (or --conn) to open a saved connection directly.`,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		applog.SetDebug(debugLog)
+	},
+	Args: cobra.MaximumNArgs(1),
+	// For connectArg's hints; cobra only defaults this for its own.
+	SuggestionsMinimumDistance: 2,
 	// Running with no subcommand launches the TUI.
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return tui.Start()
+		if connFlag != "" {
+			if connectName != "" && connectName != connFlag {
+				return fmt.Errorf("--connect %q and --conn %q name different connections", connectName, connFlag)
+			}
+			connectName = connFlag
+		}
+		if len(args) == 1 {
+			if err := connectArg(cmd, args[0]); err != nil {
+				return err
+			}
+		}
+		return tui.Start(tui.Options{Connect: connectName, View: startView, Table: startTable})
 	},
 }
 
+// connectName is the saved connection to open on startup (--connect,
+// --conn or the argument).
+var connectName string
+
+// connFlag is --conn, kept apart from --connect so that the two naming
+// different connections is an error rather than one silently winning.
+var connFlag string
+
+// startView and startTable are the view to show and the table to browse
+// once connected (--view, --table), e.g. for a tmux pane that always
+// shows the Stats view.
+var startView, startTable string
+
+// debugLog logs every SQL statement to ~/.paisql/logs/app.log (--debug).
+var debugLog bool
+
+func init() {
+	rootCmd.Flags().StringVarP(&connectName, "connect", "c", "", "connect to a saved connection, skipping the connection screen")
+	rootCmd.Flags().StringVar(&connFlag, "conn", "", "same as --connect")
+	rootCmd.Flags().StringVar(&startView, "view", "", "view to open once connected: main, explain, index, stats, log, ai, integrity, listen or activity")
+	rootCmd.Flags().StringVar(&startTable, "table", "", "table to browse once connected")
+	rootCmd.PersistentFlags().BoolVar(&debugLog, "debug", false, "log every SQL statement with its duration to ~/.paisql/logs/app.log")
+}
+
 // Execute runs the root command.
 func Execute() error {
 	return rootCmd.Execute()
 }
+
+// connectArg takes the saved connection named by the argument. A name
+// that looks like a mistyped subcommand and isn't saved gets a hint
+// instead of a failed connection.
+func connectArg(cmd *cobra.Command, name string) error {
+	if connectName != "" && connectName != name {
+		return fmt.Errorf("two connections given: %q and %q", connectName, name)
+	}
+	connectName = name
+	if suggestions := cmd.SuggestionsFor(name); len(suggestions) > 0 {
+		store, err := config.NewConnectionStore()
+		if err != nil {
+			return err
+		}
+		if _, ok := store.Get(name); !ok {
+			return fmt.Errorf("no saved connection named %q; did you mean the %s command?", name, suggestions[0])
+		}
+	}
+	return nil
+}
+
+// connectSaved opens the saved connection with the given name, for
+// subcommands that work without the TUI.
+func connectSaved(ctx context.Context, name string) (*db.DB, error) {
+	store, err := config.NewConnectionStore()
+	if err != nil {
+		return nil, err
+	}
+	conn, ok := store.Get(name)
+	if !ok {
+		return nil, fmt.Errorf("no saved connection named %q", name)
+	}
+	database, err := db.Connect(ctx, config.FromConnection(conn))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return database, nil
+}
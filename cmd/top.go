@@ -0,0 +1,30 @@
+// top.go implements `paisql top`, a monitor in the spirit of pg_top: the
+// TUI with only its monitoring views (sessions, lock waits, stats and
+// database activity) and no SQL editor, for a terminal left open on a
+// server.
+
+package cmd
+
+import (
+	"github.com/DachengChen/paiSQL/tui"
+	"github.com/spf13/cobra"
+)
+
+var topView string
+
+var topCmd = &cobra.Command{
+	Use:   "top <connection>",
+	Short: "Monitor a saved connection's sessions, locks and activity",
+	Long: `Opens a saved connection in a monitor-only TUI: the Log view's live
+sessions, the lock waits as blocking trees, the Stats view and the
+per-database activity. Tab cycles through the screens and q quits.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return tui.Start(tui.Options{Connect: args[0], View: topView, Monitor: true})
+	},
+}
+
+func init() {
+	topCmd.Flags().StringVar(&topView, "view", "", "screen to start on: log, locks, stats or activity")
+	rootCmd.AddCommand(topCmd)
+}
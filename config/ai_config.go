@@ -20,12 +20,33 @@ type AIConfig struct {
 	Ollama      OllamaConfig      `json:"ollama"`
 	Groq        GroqConfig        `json:"groq"`
 	Antigravity AntigravityConfig `json:"antigravity"`
+
+	// InterpretResults sends the first rows of an executed AI plan back to
+	// the provider for a one-paragraph natural-language answer.
+	InterpretResults bool `json:"interpret_results,omitempty"`
+	InterpretRows    int  `json:"interpret_rows,omitempty"` // rows sent for interpretation (default 10)
+
+	// SchemaRetrieval picks the tables sent as context for a question by
+	// embedding similarity (OpenAI or Ollama only), so questions about a
+	// large database aren't limited to the selected table.
+	SchemaRetrieval bool `json:"schema_retrieval,omitempty"`
+	RetrieveTables  int  `json:"retrieve_tables,omitempty"` // tables retrieved per question (default 5)
+
+	// CompactSchema sends table schemas to the AI one line per column
+	// with abbreviated types, about half the size on wide tables.
+	CompactSchema bool `json:"compact_schema,omitempty"`
+
+	// DisablePlanGuard turns off the check that refuses AI query plans
+	// reading system catalogs, other schemas' tables or more than one
+	// statement. It is on by default.
+	DisablePlanGuard bool `json:"disable_plan_guard,omitempty"`
 }
 
 // OpenAIConfig holds OpenAI-specific settings.
 type OpenAIConfig struct {
-	APIKey string `json:"api_key,omitempty"`
-	Model  string `json:"model"`
+	APIKey     string `json:"api_key,omitempty"`
+	Model      string `json:"model"`
+	EmbedModel string `json:"embed_model,omitempty"` // for schema retrieval
 }
 
 // AnthropicConfig holds Anthropic-specific settings.
@@ -42,8 +63,9 @@ type GeminiConfig struct {
 
 // OllamaConfig holds Ollama-specific settings.
 type OllamaConfig struct {
-	Host  string `json:"host"`
-	Model string `json:"model"`
+	Host       string `json:"host"`
+	Model      string `json:"model"`
+	EmbedModel string `json:"embed_model,omitempty"` // for schema retrieval
 }
 
 // AntigravityConfig holds Google Antigravity OAuth settings.
@@ -60,7 +82,22 @@ type GroqConfig struct {
 
 // AppConfig is the top-level config file structure (~/.paisql/config.json).
 type AppConfig struct {
-	AI AIConfig `json:"ai"`
+	AI      AIConfig      `json:"ai"`
+	Display DisplayConfig `json:"display,omitempty"`
+
+	// SQLStyle sets keyword case and identifier quoting of generated SQL.
+	SQLStyle SQLStyleConfig `json:"sql_style,omitempty"`
+
+	// Notify announces long operations on the desktop or to a webhook.
+	Notify NotifyConfig `json:"notify,omitempty"`
+
+	// MigrationsDir is where `paisql migrations` looks for migration files
+	// (default "migrations", relative to the working directory).
+	MigrationsDir string `json:"migrations_dir,omitempty"`
+
+	// AutoconnectLast skips the connection screen and connects to the most
+	// recently used saved connection on startup.
+	AutoconnectLast bool `json:"autoconnect_last,omitempty"`
 }
 
 // DefaultAIConfig returns sensible defaults.
@@ -68,7 +105,8 @@ func DefaultAIConfig() AIConfig {
 	return AIConfig{
 		Provider: "placeholder",
 		OpenAI: OpenAIConfig{
-			Model: "gpt-4o",
+			Model:      "gpt-4o",
+			EmbedModel: "text-embedding-3-small",
 		},
 		Anthropic: AnthropicConfig{
 			Model: "claude-sonnet-4-20250514",
@@ -77,8 +115,9 @@ func DefaultAIConfig() AIConfig {
 			Model: "gemini-2.0-flash",
 		},
 		Ollama: OllamaConfig{
-			Host:  "http://localhost:11434",
-			Model: "llama3.2",
+			Host:       "http://localhost:11434",
+			Model:      "llama3.2",
+			EmbedModel: "nomic-embed-text",
 		},
 		Antigravity: AntigravityConfig{
 			Model: "gemini-2.0-flash",
@@ -89,6 +128,43 @@ func DefaultAIConfig() AIConfig {
 	}
 }
 
+// ForConnection returns the AI settings for a saved connection: its
+// provider and model override, if it has one, over c. Credentials and the
+// Ollama host still come from c.
+func (c AIConfig) ForConnection(conn Connection) AIConfig {
+	if conn.AIProvider == "" {
+		return c
+	}
+	c.Provider = conn.AIProvider
+	defaults := DefaultAIConfig()
+	model := func(m *string, def string) {
+		switch {
+		case conn.AIModel != "":
+			*m = conn.AIModel
+		case *m == "":
+			*m = def
+		}
+	}
+	switch c.Provider {
+	case "openai":
+		model(&c.OpenAI.Model, defaults.OpenAI.Model)
+	case "anthropic":
+		model(&c.Anthropic.Model, defaults.Anthropic.Model)
+	case "gemini":
+		model(&c.Gemini.Model, defaults.Gemini.Model)
+	case "groq":
+		model(&c.Groq.Model, defaults.Groq.Model)
+	case "ollama":
+		model(&c.Ollama.Model, defaults.Ollama.Model)
+		if c.Ollama.Host == "" {
+			c.Ollama.Host = defaults.Ollama.Host
+		}
+	case "antigravity":
+		model(&c.Antigravity.Model, defaults.Antigravity.Model)
+	}
+	return c
+}
+
 // LoadAppConfig reads ~/.paisql/config.json; returns defaults if not found.
 func LoadAppConfig() (*AppConfig, error) {
 	homeDir, err := os.UserHomeDir()
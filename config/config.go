@@ -4,7 +4,10 @@
 // depend on config without importing Cobra.
 package config
 
-import "strconv"
+import (
+	"strconv"
+	"strings"
+)
 
 // Config holds all application settings for an active connection.
 type Config struct {
@@ -15,6 +18,12 @@ type Config struct {
 	Database string
 	SSLMode  string
 
+	// SearchPath is sent as the search_path startup parameter when set.
+	SearchPath string
+
+	// StatementCache selects pgx's query exec mode; see StatementCacheModes.
+	StatementCache string
+
 	SSH SSHConfig
 }
 
@@ -24,18 +33,63 @@ type SSHConfig struct {
 	Host          string
 	Port          int
 	User          string
+	Auth          string // SSHAuthKey, SSHAuthAgent or SSHAuthPassword
 	KeyPath       string
 	KeyPassphrase string
+	Password      string
+
+	// InsecureHostKey skips checking the host key against known_hosts.
+	InsecureHostKey bool
+}
+
+// SSH authentication methods for SSHConfig.Auth and SSHEntry.Auth.
+const (
+	SSHAuthKey      = ""         // the key file KeyPath
+	SSHAuthAgent    = "agent"    // the keys of the ssh-agent at $SSH_AUTH_SOCK
+	SSHAuthPassword = "password" // Password, also for keyboard-interactive
+)
+
+// Statement cache modes for Connection.StatementCache. The default
+// prepares each statement once per connection and caches it, which breaks
+// behind pgbouncer in transaction mode and can go stale after schema
+// changes.
+const (
+	StatementCacheDescribe = "describe" // cache only result descriptions
+	StatementCacheExec     = "exec"     // no cache: describe every statement, run it unnamed
+	StatementCacheDisabled = "disabled" // no prepared statements at all (simple protocol)
+)
+
+// StatementCacheModes lists the modes in cycling order; "" is the default.
+var StatementCacheModes = []string{"", StatementCacheDescribe, StatementCacheExec, StatementCacheDisabled}
+
+// queryExecModes maps statement cache modes to pgx's default_query_exec_mode.
+var queryExecModes = map[string]string{
+	StatementCacheDescribe: "cache_describe",
+	StatementCacheExec:     "describe_exec",
+	StatementCacheDisabled: "simple_protocol",
 }
 
-// DSN builds a pgx-compatible connection string.
+// DSN builds a pgx-compatible connection string. Values are quoted, so
+// an empty password or one with spaces doesn't swallow the next key.
 func (c Config) DSN() string {
-	return "host=" + c.Host +
+	quote := func(s string) string {
+		return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+	}
+	dsn := "host=" + quote(c.Host) +
 		" port=" + strconv.Itoa(c.Port) +
-		" user=" + c.User +
-		" password=" + c.Password +
-		" dbname=" + c.Database +
-		" sslmode=" + c.SSLMode
+		" user=" + quote(c.User) +
+		" password=" + quote(c.Password) +
+		" dbname=" + quote(c.Database) +
+		" sslmode=" + quote(c.SSLMode)
+	if c.SearchPath != "" {
+		// Unknown keys become startup parameters, so every pooled
+		// connection starts with this search_path.
+		dsn += " search_path=" + quote(c.SearchPath)
+	}
+	if mode, ok := queryExecModes[c.StatementCache]; ok {
+		dsn += " default_query_exec_mode=" + mode
+	}
+	return dsn
 }
 
 // FromConnection converts a saved Connection profile into a Config.
@@ -56,13 +110,21 @@ func FromConnection(conn Connection) Config {
 		Password: conn.Password,
 		Database: conn.Database,
 		SSLMode:  conn.SSLMode,
+
+		SearchPath:     strings.TrimSpace(conn.SearchPath),
+		StatementCache: conn.StatementCache,
+
 		SSH: SSHConfig{
 			Enabled:       conn.SSH.Enabled,
 			Host:          conn.SSH.Host,
 			Port:          sshPort,
 			User:          conn.SSH.User,
+			Auth:          conn.SSH.Auth,
 			KeyPath:       conn.SSH.KeyPath,
 			KeyPassphrase: conn.SSH.KeyPassphrase,
+			Password:      conn.SSH.Password,
+
+			InsecureHostKey: conn.SSH.InsecureHostKey,
 		},
 	}
 }
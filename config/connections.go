@@ -5,10 +5,12 @@
 package config
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
+	"time"
 )
 
 // Connection is a named, saveable database connection profile.
@@ -21,6 +23,31 @@ type Connection struct {
 	Database string   `json:"database"`
 	SSLMode  string   `json:"ssl_mode"`
 	SSH      SSHEntry `json:"ssh,omitempty"`
+
+	// SearchPath overrides the server's search_path for this connection,
+	// e.g. "app, public". Empty keeps the server/role default.
+	SearchPath string `json:"search_path,omitempty"`
+
+	// StatementCache is how statements are prepared: "" caches prepared
+	// statements, or one of the StatementCache* modes.
+	StatementCache string `json:"statement_cache,omitempty"`
+
+	// Color tints the frame and header while connected, e.g. "red" for
+	// production: a color name the connection form offers, or an ANSI
+	// color number or #rrggbb. Empty keeps the default frame.
+	Color string `json:"color,omitempty"`
+
+	// AIProvider and AIModel override the AI provider for this connection,
+	// e.g. a local Ollama for a database with personal data. Empty uses
+	// the global AI settings; an empty model is the provider's.
+	AIProvider string `json:"ai_provider,omitempty"`
+	AIModel    string `json:"ai_model,omitempty"`
+
+	LastUsed time.Time `json:"last_used,omitempty"` // set on each successful connect
+
+	// Shared is set on connections from the workspace's connections file
+	// (see workspace.go), which Save leaves out.
+	Shared bool `json:"-"`
 }
 
 // SSHEntry holds SSH tunnel settings for a saved connection.
@@ -29,17 +56,31 @@ type SSHEntry struct {
 	Host          string `json:"host,omitempty"`
 	Port          string `json:"port,omitempty"`
 	User          string `json:"user,omitempty"`
+	Auth          string `json:"auth,omitempty"` // SSHAuthKey, SSHAuthAgent or SSHAuthPassword
 	KeyPath       string `json:"key_path,omitempty"`
 	KeyPassphrase string `json:"key_passphrase,omitempty"`
+	Password      string `json:"password,omitempty"`
+
+	// InsecureHostKey accepts any host key instead of checking it against
+	// ~/.ssh/known_hosts. Only for hosts whose key can't be pinned.
+	InsecureHostKey bool `json:"insecure_host_key,omitempty"`
 }
 
 // ConnectionStore manages saved connections on disk.
 type ConnectionStore struct {
 	path        string
 	Connections []Connection `json:"connections"`
+	key         *sealKey     // encrypts the file; nil saves it in plaintext
+
+	// Workspace is the workspace directory the shared connections come
+	// from, "" outside a workspace. StrippedSecrets names the shared
+	// connections whose passwords were ignored.
+	Workspace       string
+	StrippedSecrets []string
 }
 
-// NewConnectionStore creates a store, loading from ~/.paisql/connections.json.
+// NewConnectionStore creates a store, loading from ~/.paisql/connections.json
+// and adding the shared connections of the workspace.
 func NewConnectionStore() (*ConnectionStore, error) {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
@@ -57,23 +98,51 @@ func NewConnectionStore() (*ConnectionStore, error) {
 
 	// Load existing connections
 	data, err := os.ReadFile(store.path)
+	if err == nil {
+		store.Connections, store.key, err = decodeConnections(data)
+	} else if os.IsNotExist(err) {
+		err = nil
+	}
 	if err != nil {
-		if os.IsNotExist(err) {
-			return store, nil
-		}
 		return nil, err
 	}
-
-	if err := json.Unmarshal(data, store); err != nil {
-		return nil, fmt.Errorf("parse connections: %w", err)
+	if err := store.addShared(WorkspaceDir()); err != nil {
+		return nil, err
 	}
+	store.sortByRecent()
 
 	return store, nil
 }
 
-// Save writes all connections to disk.
+// addShared adds the shared connections of the workspace in dir that have
+// no saved connection of the same name.
+func (s *ConnectionStore) addShared(dir string) error {
+	if dir == "" {
+		return nil
+	}
+	shared, stripped, err := readWorkspaceConnections(dir)
+	if err != nil {
+		return err
+	}
+	s.Workspace, s.StrippedSecrets = dir, stripped
+	for _, c := range shared {
+		if _, exists := s.Get(c.Name); !exists {
+			s.Connections = append(s.Connections, c)
+		}
+	}
+	return nil
+}
+
+// Save writes the saved connections to disk, encrypted if the store is.
+// Shared connections stay in the workspace; when one was used isn't kept.
 func (s *ConnectionStore) Save() error {
-	data, err := json.MarshalIndent(s, "", "  ")
+	var own []Connection
+	for _, c := range s.Connections {
+		if !c.Shared {
+			own = append(own, c)
+		}
+	}
+	data, err := encodeConnections(own, s.key)
 	if err != nil {
 		return err
 	}
@@ -84,6 +153,9 @@ func (s *ConnectionStore) Save() error {
 func (s *ConnectionStore) Add(conn Connection) {
 	for i, c := range s.Connections {
 		if c.Name == conn.Name {
+			if conn.LastUsed.IsZero() {
+				conn.LastUsed = c.LastUsed
+			}
 			s.Connections[i] = conn
 			return
 		}
@@ -91,6 +163,35 @@ func (s *ConnectionStore) Add(conn Connection) {
 	s.Connections = append(s.Connections, conn)
 }
 
+// Touch records that the named connection was just used, moves it to
+// the front of the list, and saves the store.
+func (s *ConnectionStore) Touch(name string) error {
+	for i := range s.Connections {
+		if s.Connections[i].Name == name {
+			s.Connections[i].LastUsed = time.Now()
+			s.sortByRecent()
+			return s.Save()
+		}
+	}
+	return nil
+}
+
+// MostRecent returns the most recently used connection, if any has been used.
+func (s *ConnectionStore) MostRecent() (Connection, bool) {
+	if len(s.Connections) == 0 || s.Connections[0].LastUsed.IsZero() {
+		return Connection{}, false
+	}
+	return s.Connections[0], true
+}
+
+// sortByRecent orders connections by last use, most recent first;
+// never-used connections keep their relative order at the end.
+func (s *ConnectionStore) sortByRecent() {
+	sort.SliceStable(s.Connections, func(i, j int) bool {
+		return s.Connections[i].LastUsed.After(s.Connections[j].LastUsed)
+	})
+}
+
 // Delete removes a connection by name.
 func (s *ConnectionStore) Delete(name string) {
 	for i, c := range s.Connections {
@@ -101,6 +202,78 @@ func (s *ConnectionStore) Delete(name string) {
 	}
 }
 
+// Insert puts conn back at index i of the list, e.g. to undo a Delete.
+func (s *ConnectionStore) Insert(i int, conn Connection) {
+	i = min(max(i, 0), len(s.Connections))
+	s.Connections = slices.Insert(s.Connections, i, conn)
+}
+
+// Rename renames a connection and moves its per-connection files (query
+// history, scratchpad and table preferences) along with it. Nothing is
+// renamed when a file cannot be moved. It does not save the store.
+func (s *ConnectionStore) Rename(oldName, newName string) error {
+	if newName == "" {
+		return fmt.Errorf("connection name cannot be empty")
+	}
+	if _, exists := s.Get(newName); exists && newName != oldName {
+		return fmt.Errorf("a connection named '%s' already exists", newName)
+	}
+	i := slices.IndexFunc(s.Connections, func(c Connection) bool { return c.Name == oldName })
+	if i < 0 {
+		return fmt.Errorf("connection '%s' not found", oldName)
+	}
+	if s.Connections[i].Shared {
+		return fmt.Errorf("'%s' is shared in the workspace %s: rename it there", oldName, s.Workspace)
+	}
+	if err := renameConnectionFiles(oldName, newName); err != nil {
+		return err
+	}
+	s.Connections[i].Name = newName
+	return nil
+}
+
+// connectionFiles are the directories of ~/.paisql holding a file per
+// connection, with the files' extension.
+var connectionFiles = []struct{ dir, ext string }{
+	{"history", ".jsonl"},
+	{"scratch", ".json"},
+	{"tables", ".json"},
+}
+
+// renameConnectionFiles moves the files of connection oldName to the names
+// of newName. A missing file is skipped, and an existing file of newName
+// is left alone rather than overwritten. When a file cannot be moved, the
+// ones already moved are moved back.
+func renameConnectionFiles(oldName, newName string) error {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return err
+	}
+	var moved [][2]string // from, to
+	for _, f := range connectionFiles {
+		dir := filepath.Join(homeDir, ".paisql", f.dir)
+		from := filepath.Join(dir, unsafeFileChars.ReplaceAllString(oldName, "_")+f.ext)
+		to := filepath.Join(dir, unsafeFileChars.ReplaceAllString(newName, "_")+f.ext)
+		if from == to {
+			continue
+		}
+		if _, err := os.Stat(to); err == nil {
+			continue
+		}
+		if err := os.Rename(from, to); err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			for i := len(moved) - 1; i >= 0; i-- {
+				_ = os.Rename(moved[i][1], moved[i][0])
+			}
+			return err
+		}
+		moved = append(moved, [2]string{from, to})
+	}
+	return nil
+}
+
 // Get retrieves a connection by name.
 func (s *ConnectionStore) Get(name string) (Connection, bool) {
 	for _, c := range s.Connections {
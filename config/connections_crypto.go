@@ -0,0 +1,267 @@
+// connections_crypto.go encrypts connections.json at rest. With a
+// passphrase set, the connections are sealed with AES-256-GCM under a key
+// derived from the passphrase with scrypt; the file stays JSON, with a
+// version number so plaintext files from older releases still load and
+// are upgraded on the next save.
+//
+// The passphrase is asked for once per process (PassphrasePrompt, or
+// $PAISQL_PASSPHRASE for scripts) and only the derived key is kept.
+package config
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// connectionsVersion is the connections file format written. Version 1
+// (no version field) is a plain list; version 2 is a plain list or an
+// encrypted one.
+const connectionsVersion = 2
+
+// PassphraseEnv is the environment variable read for the passphrase of
+// an encrypted connections file before prompting.
+const PassphraseEnv = "PAISQL_PASSPHRASE"
+
+var (
+	// ErrConnectionsLocked is returned when the connections file is
+	// encrypted and there is no way to ask for its passphrase.
+	ErrConnectionsLocked = fmt.Errorf("the saved connections are encrypted: set %s or run paisql in a terminal", PassphraseEnv)
+
+	// ErrWrongPassphrase is returned when a passphrase doesn't open the
+	// connections file.
+	ErrWrongPassphrase = errors.New("wrong passphrase for the saved connections")
+)
+
+// PassphrasePrompt asks for the passphrase of an encrypted connections
+// file; the command line sets it to read from the terminal. It is asked
+// again after a wrong passphrase, up to three times.
+var PassphrasePrompt func() (string, error)
+
+// unlockedKey is the key that opened the connections file, so the stores
+// created later in the process don't ask again. unlockMu guards it and
+// keeps two stores loading at once from asking for the passphrase twice.
+var (
+	unlockMu    sync.Mutex
+	unlockedKey *sealKey
+)
+
+// Bounds on the scrypt parameters read from a connections file, so an
+// edited file can't make deriving the key take unbounded memory or time.
+// The parameters written are well inside them.
+const (
+	maxScryptMemory = 256 << 20 // 128·N·r bytes
+	maxScryptP      = 4
+)
+
+// connectionsFile is the on-disk form of the connection store.
+type connectionsFile struct {
+	Version     int          `json:"version,omitempty"`
+	Connections []Connection `json:"connections,omitempty"`
+
+	// Set instead of Connections when encrypted: Data is the sealed
+	// {"connections": [...]} document.
+	Encryption *encryptionParams `json:"encryption,omitempty"`
+	Data       []byte            `json:"data,omitempty"`
+}
+
+// encryptionParams are what is needed besides the passphrase to open an
+// encrypted connections file.
+type encryptionParams struct {
+	Cipher string `json:"cipher"` // "aes-256-gcm"
+	KDF    string `json:"kdf"`    // "scrypt"
+	N      int    `json:"n"`
+	R      int    `json:"r"`
+	P      int    `json:"p"`
+	Salt   []byte `json:"salt"`
+	Nonce  []byte `json:"nonce"`
+}
+
+// sealKey is a key derived from the passphrase, with the parameters that
+// derived it.
+type sealKey struct {
+	params encryptionParams // without the nonce, which is new on every save
+	key    []byte
+}
+
+// newSealKey derives a key from passphrase with a fresh salt.
+func newSealKey(passphrase string) (*sealKey, error) {
+	params := encryptionParams{Cipher: "aes-256-gcm", KDF: "scrypt", N: 1 << 15, R: 8, P: 1, Salt: make([]byte, 16)}
+	if _, err := rand.Read(params.Salt); err != nil {
+		return nil, err
+	}
+	return deriveKey(passphrase, params)
+}
+
+// deriveKey derives the key of passphrase with params.
+func deriveKey(passphrase string, params encryptionParams) (*sealKey, error) {
+	if params.Cipher != "aes-256-gcm" || params.KDF != "scrypt" {
+		return nil, fmt.Errorf("unsupported connections encryption %s/%s", params.Cipher, params.KDF)
+	}
+	if params.N < 2 || params.N&(params.N-1) != 0 || params.R < 1 || params.P < 1 || params.P > maxScryptP ||
+		params.N > maxScryptMemory/128/params.R || len(params.Salt) < 16 {
+		return nil, fmt.Errorf("bad connections encryption parameters (scrypt N=%d r=%d p=%d)", params.N, params.R, params.P)
+	}
+	key, err := scrypt.Key([]byte(passphrase), params.Salt, params.N, params.R, params.P, 32)
+	if err != nil {
+		return nil, err
+	}
+	params.Nonce = nil
+	return &sealKey{params: params, key: key}, nil
+}
+
+func (k *sealKey) aead() (cipher.AEAD, error) {
+	block, err := aes.NewCipher(k.key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts plaintext into an encrypted connections file.
+func (k *sealKey) seal(plaintext []byte) (connectionsFile, error) {
+	aead, err := k.aead()
+	if err != nil {
+		return connectionsFile{}, err
+	}
+	params := k.params
+	params.Nonce = make([]byte, aead.NonceSize())
+	if _, err := rand.Read(params.Nonce); err != nil {
+		return connectionsFile{}, err
+	}
+	return connectionsFile{
+		Version:    connectionsVersion,
+		Encryption: &params,
+		Data:       aead.Seal(nil, params.Nonce, plaintext, nil),
+	}, nil
+}
+
+// open decrypts the data of an encrypted connections file.
+func (k *sealKey) open(file connectionsFile) ([]byte, error) {
+	aead, err := k.aead()
+	if err != nil {
+		return nil, err
+	}
+	if len(file.Encryption.Nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("bad connections encryption nonce: %d bytes, want %d", len(file.Encryption.Nonce), aead.NonceSize())
+	}
+	plaintext, err := aead.Open(nil, file.Encryption.Nonce, file.Data, nil)
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	return plaintext, nil
+}
+
+// decodeConnections reads a connections file, decrypting it if needed,
+// and returns its connections and the key that opened it.
+func decodeConnections(data []byte) ([]Connection, *sealKey, error) {
+	var file connectionsFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, nil, fmt.Errorf("parse connections: %w", err)
+	}
+	if file.Version > connectionsVersion {
+		return nil, nil, fmt.Errorf("connections file version %d is newer than this paiSQL reads (%d)", file.Version, connectionsVersion)
+	}
+	if file.Encryption == nil {
+		return file.Connections, nil, nil
+	}
+
+	plaintext, key, err := unlock(file)
+	if err != nil {
+		return nil, nil, err
+	}
+	var inner connectionsFile
+	if err := json.Unmarshal(plaintext, &inner); err != nil {
+		return nil, nil, fmt.Errorf("parse connections: %w", err)
+	}
+	return inner.Connections, key, nil
+}
+
+// unlock opens an encrypted connections file with the key already used in
+// this process, $PAISQL_PASSPHRASE or the passphrase prompt, in that
+// order.
+func unlock(file connectionsFile) ([]byte, *sealKey, error) {
+	unlockMu.Lock()
+	defer unlockMu.Unlock()
+
+	try := func(passphrase string) ([]byte, *sealKey, error) {
+		key, err := deriveKey(passphrase, *file.Encryption)
+		if err != nil {
+			return nil, nil, err
+		}
+		plaintext, err := key.open(file)
+		if err != nil {
+			return nil, nil, err
+		}
+		unlockedKey = key
+		return plaintext, key, nil
+	}
+
+	if k := unlockedKey; k != nil && bytes.Equal(k.params.Salt, file.Encryption.Salt) {
+		if plaintext, err := k.open(file); err == nil {
+			return plaintext, k, nil
+		}
+	}
+	if passphrase, ok := os.LookupEnv(PassphraseEnv); ok {
+		return try(passphrase)
+	}
+	if PassphrasePrompt == nil {
+		return nil, nil, ErrConnectionsLocked
+	}
+	for attempt := 1; ; attempt++ {
+		passphrase, err := PassphrasePrompt()
+		if err != nil {
+			return nil, nil, err
+		}
+		plaintext, key, err := try(passphrase)
+		if !errors.Is(err, ErrWrongPassphrase) || attempt == 3 {
+			return plaintext, key, err
+		}
+	}
+}
+
+// encodeConnections returns the file contents of conns, encrypted with key
+// unless it is nil.
+func encodeConnections(conns []Connection, key *sealKey) ([]byte, error) {
+	file := connectionsFile{Version: connectionsVersion, Connections: conns}
+	if key != nil {
+		plaintext, err := json.Marshal(connectionsFile{Connections: conns})
+		if err != nil {
+			return nil, err
+		}
+		if file, err = key.seal(plaintext); err != nil {
+			return nil, err
+		}
+	}
+	return json.MarshalIndent(file, "", "  ")
+}
+
+// Encrypted reports whether the store is saved encrypted.
+func (s *ConnectionStore) Encrypted() bool {
+	return s.key != nil
+}
+
+// SetPassphrase encrypts the store with passphrase from the next Save on,
+// or stores it in plaintext again when passphrase is empty. It does not
+// save the store.
+func (s *ConnectionStore) SetPassphrase(passphrase string) error {
+	var key *sealKey
+	if passphrase != "" {
+		var err error
+		if key, err = newSealKey(passphrase); err != nil {
+			return err
+		}
+	}
+	unlockMu.Lock()
+	defer unlockMu.Unlock()
+	s.key, unlockedKey = key, key
+	return nil
+}
@@ -0,0 +1,20 @@
+// display.go defines how result values are rendered in the grid.
+//
+// These are the global defaults from ~/.paisql/config.json; the SQL view
+// copies them at startup and \pset changes the copy for the session.
+package config
+
+// DisplayConfig holds result formatting options.
+type DisplayConfig struct {
+	// NumericLocale adds thousand separators to numeric columns, using
+	// the separators of the LC_ALL / LC_NUMERIC / LANG locale.
+	NumericLocale bool `json:"numeric_locale,omitempty"`
+
+	// Decimals, if set, renders non-integer numeric columns with exactly
+	// this many decimal places.
+	Decimals *int `json:"decimals,omitempty"`
+
+	// LocalTime renders timestamptz values in the local time zone instead
+	// of UTC.
+	LocalTime bool `json:"local_time,omitempty"`
+}
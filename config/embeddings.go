@@ -0,0 +1,90 @@
+// embeddings.go caches the embeddings used for schema retrieval, so a
+// large schema is embedded once and only changed tables are sent again.
+//
+// Each embedding model gets its own file in ~/.paisql/embeddings/,
+// keyed by a hash of the embedded text.
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// EmbeddingCache maps text hashes to their embeddings for one model.
+type EmbeddingCache struct {
+	path    string
+	Vectors map[string][]float32 `json:"vectors"`
+	dirty   bool
+}
+
+// LoadEmbeddingCache reads the cache for model. A missing file is not
+// an error; it yields an empty cache.
+func LoadEmbeddingCache(model string) (*EmbeddingCache, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	c := &EmbeddingCache{
+		path:    filepath.Join(homeDir, ".paisql", "embeddings", unsafeFileChars.ReplaceAllString(model, "_")+".json"),
+		Vectors: map[string][]float32{},
+	}
+
+	data, err := os.ReadFile(c.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return c, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, err
+	}
+	if c.Vectors == nil {
+		c.Vectors = map[string][]float32{}
+	}
+	return c, nil
+}
+
+func embeddingKey(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
+
+// Get returns the cached embedding of text.
+func (c *EmbeddingCache) Get(text string) ([]float32, bool) {
+	v, ok := c.Vectors[embeddingKey(text)]
+	return v, ok
+}
+
+// Put caches the embedding of text.
+func (c *EmbeddingCache) Put(text string, vector []float32) {
+	c.Vectors[embeddingKey(text)] = vector
+	c.dirty = true
+}
+
+// Save writes the cache to disk if anything was added. The file is
+// replaced atomically, like the scratchpad.
+func (c *EmbeddingCache) Save() error {
+	if !c.dirty {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0700); err != nil {
+		return err
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, c.path); err != nil {
+		return err
+	}
+	c.dirty = false
+	return nil
+}
@@ -0,0 +1,112 @@
+// history.go persists the statements run from the SQL input so the ↑/↓
+// history and Ctrl+R search survive a restart.
+//
+// Each saved connection gets its own file in ~/.paisql/history/, one JSON
+// object per line, oldest first. Lines are appended as statements finish;
+// the file is cut back to the newest historyLimit entries on load.
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// historyLimit is the number of statements kept per connection.
+const historyLimit = 1000
+
+// HistoryEntry is one statement run from the SQL input.
+type HistoryEntry struct {
+	SQL      string    `json:"sql"`
+	At       time.Time `json:"at"`
+	Status   string    `json:"status"` // "ok", "error" or "cancelled"
+	Duration int64     `json:"duration_ms,omitempty"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// History is the statement history of one connection.
+type History struct {
+	path    string
+	Entries []HistoryEntry // oldest first
+}
+
+// LoadHistory reads the history of a connection. An unnamed connection
+// shares the "default" history. A missing file is not an error; it
+// yields an empty history, and lines that do not parse are skipped.
+func LoadHistory(connName string) (*History, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	if connName == "" {
+		connName = "default"
+	}
+	h := &History{
+		path: filepath.Join(homeDir, ".paisql", "history", unsafeFileChars.ReplaceAllString(connName, "_")+".jsonl"),
+	}
+
+	data, err := os.ReadFile(h.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return h, nil
+		}
+		return nil, err
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, 16<<20)
+	for scanner.Scan() {
+		var e HistoryEntry
+		if json.Unmarshal(scanner.Bytes(), &e) == nil && e.SQL != "" {
+			h.Entries = append(h.Entries, e)
+		}
+	}
+	if len(h.Entries) > historyLimit {
+		h.Entries = h.Entries[len(h.Entries)-historyLimit:]
+		if err := h.rewrite(); err != nil {
+			return nil, err
+		}
+	}
+	return h, nil
+}
+
+// Append adds an entry and writes it to the end of the file.
+func (h *History) Append(e HistoryEntry) error {
+	h.Entries = append(h.Entries, e)
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(h.path), 0700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(h.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// rewrite replaces the file with the entries in memory, atomically like
+// Scratchpad.Save.
+func (h *History) rewrite() error {
+	var buf bytes.Buffer
+	for _, e := range h.Entries {
+		line, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		buf.Write(append(line, '\n'))
+	}
+	tmp := h.path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, h.path)
+}
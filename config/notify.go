@@ -0,0 +1,36 @@
+// notify.go defines the notification hooks fired when a long operation
+// (a query, an export, a VACUUM) finishes, so the terminal can be left
+// during long maintenance.
+package config
+
+import "time"
+
+// DefaultNotifyAfter is the threshold used when after_seconds is unset.
+const DefaultNotifyAfter = 30 * time.Second
+
+// NotifyConfig holds the notification hooks. Both are off by default.
+type NotifyConfig struct {
+	// AfterSeconds is how long an operation must run before its end is
+	// announced (default 30).
+	AfterSeconds int `json:"after_seconds,omitempty"`
+
+	// Desktop shows a desktop notification (osascript on macOS,
+	// notify-send elsewhere).
+	Desktop bool `json:"desktop,omitempty"`
+
+	// Webhook receives a POST with a Slack-compatible {"text": "..."} body.
+	Webhook string `json:"webhook,omitempty"`
+}
+
+// Enabled reports whether any hook is configured.
+func (n NotifyConfig) Enabled() bool {
+	return n.Desktop || n.Webhook != ""
+}
+
+// Threshold returns the minimum duration of an announced operation.
+func (n NotifyConfig) Threshold() time.Duration {
+	if n.AfterSeconds <= 0 {
+		return DefaultNotifyAfter
+	}
+	return time.Duration(n.AfterSeconds) * time.Second
+}
@@ -0,0 +1,199 @@
+// recipes.go loads recipes: named, multi-step workflows for routine
+// operational procedures, run with \recipe <name> in the SQL tab.
+//
+// A recipe is a JSON file in ~/.paisql/recipes/, the workspace's recipes/
+// (see workspace.go) or any path with a directory, holding a list of steps; each step runs SQL, exports the
+// last result, waits, or asks for confirmation:
+//
+//	{
+//	  "description": "Archive last month's orders",
+//	  "steps": [
+//	    {"sql": "SELECT count(*) FROM orders WHERE created_at < date_trunc('month', now())"},
+//	    {"confirm": "Archive these orders?"},
+//	    {"sql": "INSERT INTO orders_archive SELECT * FROM orders WHERE created_at < date_trunc('month', now())"},
+//	    {"wait": "5s"},
+//	    {"sql": "SELECT * FROM orders_archive ORDER BY created_at DESC LIMIT 100"},
+//	    {"export": "~/archive.xlsx"}
+//	  ]
+//	}
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Recipe is a sequence of steps run in order.
+type Recipe struct {
+	Name        string       `json:"-"` // file name without .json
+	Description string       `json:"description,omitempty"`
+	Steps       []RecipeStep `json:"steps"`
+}
+
+// RecipeStep is one step of a recipe. Exactly one of SQL, Export, Wait
+// and Confirm is set.
+type RecipeStep struct {
+	Name    string `json:"name,omitempty"`    // shown instead of the step itself
+	SQL     string `json:"sql,omitempty"`     // a single statement
+	Export  string `json:"export,omitempty"`  // file for the last SQL result: .csv or .xlsx
+	Wait    string `json:"wait,omitempty"`    // a duration such as "30s" or "2m"
+	Confirm string `json:"confirm,omitempty"` // question answered with y to continue
+}
+
+// Step kinds, as returned by RecipeStep.Kind.
+const (
+	StepSQL     = "sql"
+	StepExport  = "export"
+	StepWait    = "wait"
+	StepConfirm = "confirm"
+)
+
+// Kind returns which of the step's fields is set, or "" if not exactly one.
+func (s RecipeStep) Kind() string {
+	kind := ""
+	for _, k := range []struct {
+		name string
+		set  bool
+	}{
+		{StepSQL, s.SQL != ""},
+		{StepExport, s.Export != ""},
+		{StepWait, s.Wait != ""},
+		{StepConfirm, s.Confirm != ""},
+	} {
+		if !k.set {
+			continue
+		}
+		if kind != "" {
+			return ""
+		}
+		kind = k.name
+	}
+	return kind
+}
+
+// Duration returns the wait of a wait step.
+func (s RecipeStep) Duration() time.Duration {
+	d, _ := time.ParseDuration(s.Wait)
+	return d
+}
+
+// validate checks the step's kind and arguments.
+func (s RecipeStep) validate() error {
+	switch s.Kind() {
+	case "":
+		return fmt.Errorf("needs exactly one of sql, export, wait and confirm")
+	case StepWait:
+		if d, err := time.ParseDuration(s.Wait); err != nil || d <= 0 {
+			return fmt.Errorf("wait %q is not a duration such as 30s or 2m", s.Wait)
+		}
+	case StepExport:
+		if ext := strings.ToLower(filepath.Ext(s.Export)); ext != ".csv" && ext != ".xlsx" {
+			return fmt.Errorf("export %q must be a .csv or .xlsx file", s.Export)
+		}
+	}
+	return nil
+}
+
+// RecipesDir returns ~/.paisql/recipes.
+func RecipesDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".paisql", "recipes"), nil
+}
+
+// RecipeDirs returns the directories recipes are looked for in, in order:
+// RecipesDir, then the workspace's recipes directory if there is one.
+func RecipeDirs() ([]string, error) {
+	dir, err := RecipesDir()
+	if err != nil {
+		return nil, err
+	}
+	dirs := []string{dir}
+	if ws := WorkspaceDir(); ws != "" {
+		dirs = append(dirs, filepath.Join(ws, "recipes"))
+	}
+	return dirs, nil
+}
+
+// RecipePath resolves name to a recipe file like PlanPath does for plans;
+// a name not in RecipesDir is looked for in the workspace.
+func RecipePath(name string) (string, error) {
+	if rest, ok := strings.CutPrefix(name, "~/"); ok {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(homeDir, rest), nil
+	}
+	if strings.ContainsRune(name, filepath.Separator) {
+		return name, nil
+	}
+	if filepath.Ext(name) == "" {
+		name += ".json"
+	}
+	dirs, err := RecipeDirs()
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dirs[0], name)
+	if _, err := os.Stat(path); os.IsNotExist(err) && len(dirs) > 1 {
+		shared := filepath.Join(dirs[1], name)
+		if _, err := os.Stat(shared); err == nil {
+			return shared, nil
+		}
+	}
+	return path, nil
+}
+
+// LoadRecipe reads and validates a recipe.
+func LoadRecipe(name string) (*Recipe, error) {
+	path, err := RecipePath(name)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var r Recipe
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(r.Steps) == 0 {
+		return nil, fmt.Errorf("%s has no steps", path)
+	}
+	for i, s := range r.Steps {
+		if err := s.validate(); err != nil {
+			return nil, fmt.Errorf("%s: step %d %w", path, i+1, err)
+		}
+	}
+	r.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	return &r, nil
+}
+
+// ListRecipes returns the names of the recipes in dir, one of RecipeDirs,
+// sorted.
+func ListRecipes(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() && filepath.Ext(e.Name()) == ".json" {
+			names = append(names, strings.TrimSuffix(e.Name(), ".json"))
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
@@ -0,0 +1,132 @@
+// saved_plans.go stores EXPLAIN results as files, so a plan can be
+// attached to a ticket and loaded back into the Explain view later.
+//
+// Plans saved by name go to ~/.paisql/plans/; a path with a directory
+// is used as given.
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SavedPlan is an EXPLAIN result with the query and options it came from.
+type SavedPlan struct {
+	Query   string          `json:"query"`
+	Options string          `json:"options"` // e.g. "(ANALYZE, BUFFERS, FORMAT JSON)"
+	SavedAt time.Time       `json:"saved_at"`
+	Plan    json.RawMessage `json:"plan,omitempty"` // FORMAT JSON output
+	Text    string          `json:"text,omitempty"` // FORMAT TEXT output
+}
+
+// PlansDir returns ~/.paisql/plans.
+func PlansDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".paisql", "plans"), nil
+}
+
+// PlanPath resolves name to a plan file: a bare name is a file in
+// PlansDir (".json" is added if it has no extension), anything with a
+// directory is a path, and a leading ~/ is the home directory. An empty
+// name gets a timestamped one.
+func PlanPath(name string) (string, error) {
+	if name == "" {
+		name = "plan-" + time.Now().Format("20060102-150405")
+	}
+	if rest, ok := strings.CutPrefix(name, "~/"); ok {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(homeDir, rest), nil
+	}
+	if strings.ContainsRune(name, filepath.Separator) {
+		return name, nil
+	}
+	if filepath.Ext(name) == "" {
+		name += ".json"
+	}
+	dir, err := PlansDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name), nil
+}
+
+// SavePlan writes p to the file PlanPath(name) resolves to and returns
+// that path.
+func SavePlan(name string, p *SavedPlan) (string, error) {
+	path, err := PlanPath(name)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return "", err
+	}
+	p.SavedAt = time.Now()
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return path, os.WriteFile(path, data, 0600)
+}
+
+// LoadPlan reads a plan saved with SavePlan.
+func LoadPlan(name string) (*SavedPlan, error) {
+	path, err := PlanPath(name)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var p SavedPlan
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// ListPlans returns the plan files in PlansDir, newest first.
+func ListPlans() ([]string, error) {
+	dir, err := PlansDir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	type plan struct {
+		name string
+		mod  time.Time
+	}
+	var plans []plan
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".json" {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		plans = append(plans, plan{e.Name(), info.ModTime()})
+	}
+	sort.Slice(plans, func(i, j int) bool { return plans[i].mod.After(plans[j].mod) })
+	names := make([]string, len(plans))
+	for i, p := range plans {
+		names[i] = p.name
+	}
+	return names, nil
+}
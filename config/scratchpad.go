@@ -0,0 +1,74 @@
+// scratchpad.go persists unsent input buffers so a crash, dropped SSH
+// session or accidental Ctrl+C never loses a query being written.
+//
+// Each saved connection gets its own file in ~/.paisql/scratch/.
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+)
+
+// Scratchpad holds the text buffers of one connection's views, keyed by
+// a stable buffer name (e.g. "sql", "chat", "explain").
+type Scratchpad struct {
+	path    string
+	Buffers map[string]string `json:"buffers"`
+	Saved   time.Time         `json:"saved"`
+}
+
+// unsafeFileChars matches characters not allowed in scratchpad file names.
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+// LoadScratchpad reads the scratchpad for a connection. An unnamed
+// connection shares the "default" scratchpad. A missing file is not an
+// error; it yields an empty scratchpad.
+func LoadScratchpad(connName string) (*Scratchpad, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	if connName == "" {
+		connName = "default"
+	}
+	pad := &Scratchpad{
+		path:    filepath.Join(homeDir, ".paisql", "scratch", unsafeFileChars.ReplaceAllString(connName, "_")+".json"),
+		Buffers: map[string]string{},
+	}
+
+	data, err := os.ReadFile(pad.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return pad, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, pad); err != nil {
+		return nil, err
+	}
+	if pad.Buffers == nil {
+		pad.Buffers = map[string]string{}
+	}
+	return pad, nil
+}
+
+// Save writes the scratchpad to disk. The file is replaced atomically so
+// a crash mid-write leaves the previous copy intact.
+func (p *Scratchpad) Save() error {
+	if err := os.MkdirAll(filepath.Dir(p.path), 0700); err != nil {
+		return err
+	}
+	p.Saved = time.Now()
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := p.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, p.path)
+}
@@ -0,0 +1,26 @@
+// sql_style.go defines how paiSQL writes the SQL it generates: query
+// plans, pagination queries, DDL previews and the statements of the table
+// actions, column wizard and create index form.
+package config
+
+// Keyword case values for SQLStyleConfig.KeywordCase.
+const (
+	KeywordUpper = "upper" // SELECT * FROM users (default)
+	KeywordLower = "lower" // select * from users
+)
+
+// Identifier quoting values for SQLStyleConfig.QuoteIdentifiers.
+const (
+	QuoteMinimal = "minimal" // quote only names that need it (default)
+	QuoteAlways  = "always"  // quote table names and qualified references
+	QuoteKeep    = "keep"    // leave quoting as each generator wrote it
+)
+
+// SQLStyleConfig holds the SQL style options.
+type SQLStyleConfig struct {
+	// KeywordCase is "upper" or "lower".
+	KeywordCase string `json:"keyword_case,omitempty"`
+
+	// QuoteIdentifiers is "minimal", "always" or "keep".
+	QuoteIdentifiers string `json:"quote_identifiers,omitempty"`
+}
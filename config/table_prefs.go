@@ -0,0 +1,99 @@
+// table_prefs.go persists how each table is browsed: the columns shown
+// and their order, and the sort order, so a table opens the way it was
+// left.
+//
+// Each saved connection gets its own file in ~/.paisql/tables/, keyed
+// by the table list name.
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// TablePref is the browsing preference of one table.
+type TablePref struct {
+	// Columns lists the columns shown, in order; empty shows them all.
+	Columns []string `json:"columns,omitempty"`
+
+	// Sort is the ORDER BY of the rows, e.g. "created_at desc, id"; empty
+	// leaves them in physical order.
+	Sort string `json:"sort,omitempty"`
+}
+
+// empty reports whether the preference changes nothing.
+func (p TablePref) empty() bool {
+	return len(p.Columns) == 0 && p.Sort == ""
+}
+
+// TablePrefs holds the table preferences of one connection.
+type TablePrefs struct {
+	path   string
+	Tables map[string]TablePref `json:"tables"`
+}
+
+// LoadTablePrefs reads the table preferences of a connection. An unnamed
+// connection shares the "default" file. A missing file is not an error;
+// it yields no preferences.
+func LoadTablePrefs(connName string) (*TablePrefs, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	if connName == "" {
+		connName = "default"
+	}
+	prefs := &TablePrefs{
+		path:   filepath.Join(homeDir, ".paisql", "tables", unsafeFileChars.ReplaceAllString(connName, "_")+".json"),
+		Tables: map[string]TablePref{},
+	}
+
+	data, err := os.ReadFile(prefs.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return prefs, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, prefs); err != nil {
+		return nil, err
+	}
+	if prefs.Tables == nil {
+		prefs.Tables = map[string]TablePref{}
+	}
+	return prefs, nil
+}
+
+// Get returns the preference of table.
+func (p *TablePrefs) Get(table string) TablePref {
+	return p.Tables[table]
+}
+
+// Set stores the preference of table, dropping it when it changes nothing,
+// and saves the file.
+func (p *TablePrefs) Set(table string, pref TablePref) error {
+	if pref.empty() {
+		delete(p.Tables, table)
+	} else {
+		p.Tables[table] = pref
+	}
+	return p.save()
+}
+
+// save writes the preferences to disk, replacing the file atomically like
+// the scratchpad.
+func (p *TablePrefs) save() error {
+	if err := os.MkdirAll(filepath.Dir(p.path), 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := p.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, p.path)
+}
@@ -0,0 +1,150 @@
+// workspace.go finds the project workspace: a .paisql directory in the
+// working directory or one of its parents, committed with an application's
+// code so everyone working on it gets the same connections and recipes:
+//
+//	.paisql/
+//	  connections.json   shared connections, in the connections file format
+//	  recipes/           recipes, as in ~/.paisql/recipes
+//
+// A workspace holds only what is safe to commit, and nothing a cloned
+// repository could use to weaken a connection: passwords, SSH key paths
+// and insecure_host_key in its connections are ignored, and are only
+// taken from ~/.paisql, which is never a workspace itself. What is in
+// ~/.paisql wins over the workspace, so saving a shared connection with a
+// password keeps a personal copy of it.
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// workspaceDirName is the name of the workspace directory in a project.
+const workspaceDirName = ".paisql"
+
+var workspaceDir = sync.OnceValue(findWorkspace)
+
+// WorkspaceDir returns the workspace directory found from the working
+// directory, or "" outside a workspace. It is looked for once per process.
+func WorkspaceDir() string {
+	return workspaceDir()
+}
+
+// findWorkspace walks up from the working directory to the first
+// directory with a .paisql directory in it, stopping at the home
+// directory, whose .paisql is the user's own.
+func findWorkspace() string {
+	dir, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	homeDir, _ := os.UserHomeDir()
+	for dir != homeDir {
+		path := filepath.Join(dir, workspaceDirName)
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			return path
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+	return ""
+}
+
+// workspaceConnectionsPath returns the shared connections file of the
+// workspace in dir.
+func workspaceConnectionsPath(dir string) string {
+	return filepath.Join(dir, "connections.json")
+}
+
+// readWorkspaceConnections reads the shared connections of the workspace
+// in dir, without their secrets and local-only settings. stripped names
+// the connections that had any, which were dropped.
+func readWorkspaceConnections(dir string) (conns []Connection, stripped []string, err error) {
+	path := workspaceConnectionsPath(dir)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, nil
+		}
+		return nil, nil, err
+	}
+	var file connectionsFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if file.Encryption != nil {
+		return nil, nil, fmt.Errorf("%s is encrypted: a workspace is for settings that can be shared as they are", path)
+	}
+	for _, c := range file.Connections {
+		if c != shareable(c) {
+			stripped = append(stripped, c.Name)
+		}
+		c = shareable(c)
+		c.LastUsed = time.Time{}
+		c.Shared = true
+		conns = append(conns, c)
+	}
+	return conns, stripped, nil
+}
+
+// shareable returns conn as it is shared in a workspace: without its
+// passwords, its SSH key (a path on this machine) or turning off host key
+// checks, which only the user's own settings may do.
+func shareable(conn Connection) Connection {
+	conn.Password = ""
+	conn.SSH.Password = ""
+	conn.SSH.KeyPassphrase = ""
+	conn.SSH.KeyPath = ""
+	conn.SSH.InsecureHostKey = false
+	return conn
+}
+
+// ShareConnection adds conn, without its secrets, to the shared
+// connections of the workspace, replacing one of the same name. Outside a
+// workspace it starts one in the working directory. It returns the file
+// written.
+func ShareConnection(conn Connection) (string, error) {
+	dir := WorkspaceDir()
+	if dir == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(wd, workspaceDirName)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	conns, _, err := readWorkspaceConnections(dir)
+	if err != nil {
+		return "", err
+	}
+	conn = shareable(conn)
+	conn.LastUsed = time.Time{}
+	conn.Shared = false
+	replaced := false
+	for i, c := range conns {
+		conns[i].Shared = false
+		if c.Name == conn.Name {
+			conns[i], replaced = conn, true
+		}
+	}
+	if !replaced {
+		conns = append(conns, conn)
+	}
+
+	data, err := json.MarshalIndent(connectionsFile{Version: connectionsVersion, Connections: conns}, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	path := workspaceConnectionsPath(dir)
+	return path, os.WriteFile(path, append(data, '\n'), 0644)
+}
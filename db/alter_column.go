@@ -0,0 +1,167 @@
+// alter_column.go builds single-column ALTER TABLE statements and
+// previews their effect before they run: the DDL, whether the table
+// will be rewritten, which locks are taken, and for type changes how
+// sample values convert.
+package db
+
+import (
+	"context"
+	"fmt"
+
+	pgx "github.com/jackc/pgx/v5"
+)
+
+// AlterColumnOp is a kind of column change.
+type AlterColumnOp string
+
+const (
+	AlterRename      AlterColumnOp = "Rename column"
+	AlterType        AlterColumnOp = "Change type"
+	AlterSetNotNull  AlterColumnOp = "Set NOT NULL"
+	AlterDropNotNull AlterColumnOp = "Drop NOT NULL"
+	AlterSetDefault  AlterColumnOp = "Set default"
+	AlterDropDefault AlterColumnOp = "Drop default"
+)
+
+// alterPreviewRows is how many sample values a type change converts.
+const alterPreviewRows = 5
+
+// AlterColumn describes one change to one column.
+type AlterColumn struct {
+	Schema string // empty resolves Table through the search path
+	Table  string
+	Column string
+	Op     AlterColumnOp
+	Value  string // new name, new type or default expression
+	Using  string // USING expression for AlterType; empty casts the column
+}
+
+// table returns the quoted, optionally schema-qualified table name.
+func (a AlterColumn) table() string {
+	if a.Schema != "" {
+		return pgx.Identifier{a.Schema, a.Table}.Sanitize()
+	}
+	return pgx.Identifier{a.Table}.Sanitize()
+}
+
+// DDL returns the ALTER TABLE statement for the change.
+func (a AlterColumn) DDL() string {
+	col := pgx.Identifier{a.Column}.Sanitize()
+	prefix := "ALTER TABLE " + a.table()
+	switch a.Op {
+	case AlterRename:
+		return fmt.Sprintf("%s RENAME COLUMN %s TO %s;", prefix, col, pgx.Identifier{a.Value}.Sanitize())
+	case AlterType:
+		ddl := fmt.Sprintf("%s ALTER COLUMN %s TYPE %s", prefix, col, a.Value)
+		if a.Using != "" {
+			ddl += " USING " + a.Using
+		}
+		return ddl + ";"
+	case AlterSetNotNull:
+		return fmt.Sprintf("%s ALTER COLUMN %s SET NOT NULL;", prefix, col)
+	case AlterDropNotNull:
+		return fmt.Sprintf("%s ALTER COLUMN %s DROP NOT NULL;", prefix, col)
+	case AlterSetDefault:
+		return fmt.Sprintf("%s ALTER COLUMN %s SET DEFAULT %s;", prefix, col, a.Value)
+	case AlterDropDefault:
+		return fmt.Sprintf("%s ALTER COLUMN %s DROP DEFAULT;", prefix, col)
+	}
+	return ""
+}
+
+// AlterColumnPreview is what a column change would do.
+type AlterColumnPreview struct {
+	DDL      string
+	Warnings []string
+	Samples  [][2]string // (current value, converted value) for type changes
+}
+
+// PreviewAlterColumn checks a column change without running it. Errors
+// are reserved for failures to inspect the table; problems the change
+// would hit (NULLs present, values that don't convert) become warnings.
+func (d *DB) PreviewAlterColumn(ctx context.Context, a AlterColumn) (*AlterColumnPreview, error) {
+	p := &AlterColumnPreview{DDL: a.DDL()}
+	col := pgx.Identifier{a.Column}.Sanitize()
+
+	switch a.Op {
+	case AlterRename:
+		p.Warnings = append(p.Warnings,
+			"Catalog-only change: no rewrite, the lock is held only briefly.",
+			"Views follow the rename; function bodies and application queries that use the old name break.")
+
+	case AlterType:
+		var sameType, binaryCoercible bool
+		err := d.Pool.QueryRow(ctx, `
+			SELECT a.atttypid = $3::text::regtype,
+			       EXISTS (SELECT 1 FROM pg_cast
+			               WHERE castsource = a.atttypid AND casttarget = $3::text::regtype
+			                 AND castmethod = 'b')
+			FROM pg_attribute a
+			WHERE a.attrelid = to_regclass($1) AND a.attname = $2 AND NOT a.attisdropped`,
+			a.table(), a.Column, a.Value).Scan(&sameType, &binaryCoercible)
+		if err != nil {
+			return nil, fmt.Errorf("check type %s: %w", a.Value, err)
+		}
+		switch {
+		case a.Using != "":
+			p.Warnings = append(p.Warnings, "Rewrites the table and rebuilds its indexes: a USING expression always does.")
+		case sameType:
+			p.Warnings = append(p.Warnings, "Same base type: no rewrite if only a length limit is raised or removed; otherwise the table and its indexes are rewritten.")
+		case binaryCoercible:
+			p.Warnings = append(p.Warnings, "Binary-compatible types: no table rewrite (indexes on the column may still be rebuilt).")
+		default:
+			p.Warnings = append(p.Warnings, "Rewrites the table and rebuilds its indexes; expect it to take about as long as copying the table.")
+		}
+
+		expr := a.Using
+		if expr == "" {
+			expr = fmt.Sprintf("%s::%s", col, a.Value)
+		}
+		query := fmt.Sprintf("SELECT %s::text, (%s)::text FROM %s WHERE %s IS NOT NULL LIMIT %d",
+			col, expr, a.table(), col, alterPreviewRows)
+		rows, err := d.Pool.Query(ctx, query)
+		if err == nil {
+			for rows.Next() {
+				var before, after *string
+				if err = rows.Scan(&before, &after); err != nil {
+					break
+				}
+				p.Samples = append(p.Samples, [2]string{deref(before), deref(after)})
+			}
+			if err == nil {
+				err = rows.Err()
+			}
+			rows.Close()
+		}
+		if err != nil {
+			p.Warnings = append(p.Warnings, "Sample values fail to convert: "+err.Error())
+		}
+
+	case AlterSetNotNull:
+		var nulls int64
+		query := fmt.Sprintf("SELECT count(*) FROM %s WHERE %s IS NULL", a.table(), col)
+		if err := d.Pool.QueryRow(ctx, query).Scan(&nulls); err != nil {
+			return nil, err
+		}
+		p.Warnings = append(p.Warnings, "Scans the whole table to verify no NULLs remain (no rewrite).")
+		if nulls > 0 {
+			p.Warnings = append(p.Warnings, fmt.Sprintf("NULL in %d row%s: the statement fails until they are filled.", nulls, plural(int(nulls))))
+		}
+
+	case AlterDropNotNull, AlterDropDefault:
+		p.Warnings = append(p.Warnings, "Catalog-only change: no rewrite, the lock is held only briefly.")
+
+	case AlterSetDefault:
+		p.Warnings = append(p.Warnings, "Catalog-only change: applies to rows inserted from now on; existing rows keep their values.")
+	}
+
+	p.Warnings = append(p.Warnings, "Takes an ACCESS EXCLUSIVE lock: it waits for running queries on the table and blocks new ones, reads included, until it finishes.")
+	return p, nil
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return "NULL"
+	}
+	return *s
+}
@@ -6,21 +6,47 @@
 //     rest of the application unaware of connection details.
 //   - SSH tunnel integration is handled transparently: if SSH is enabled,
 //     we first establish the tunnel, then connect pgx to the local endpoint.
+//   - Every statement goes through a tracer (tracer.go) that logs it when
+//     debug logging is on.
 package db
 
 import (
 	"context"
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/DachengChen/paiSQL/config"
 	"github.com/DachengChen/paiSQL/ssh"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgconn/ctxwatch"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// cancelDeadline is how long a cancelled statement's connection waits for
+// the server to act on the cancel request before it is closed.
+const cancelDeadline = 5 * time.Second
+
 // DB wraps a pgx connection pool and optional SSH tunnel.
 type DB struct {
 	Pool   *pgxpool.Pool
 	Tunnel *ssh.Tunnel
+
+	// typeNames caches pg_type names by OID so result columns from
+	// extension types (vector, geometry, ...) can be identified.
+	typeMu    sync.Mutex
+	typeNames map[uint32]string
+
+	// SearchPath is the session's effective schema list, read on connect.
+	SearchPath []string
+
+	// notices holds the NOTICE/WARNING messages of each pooled connection.
+	notices noticeLog
+
+	// listeners are the LISTEN connections taken out of the pool.
+	listenMu  sync.Mutex
+	listeners []*Listener
 }
 
 // Connect establishes a PostgreSQL connection, optionally through an SSH tunnel.
@@ -44,7 +70,20 @@ func Connect(ctx context.Context, cfg config.Config) (*DB, error) {
 		cfg.Port = localAddr.Port
 	}
 
-	pool, err := pgxpool.New(ctx, cfg.DSN())
+	poolCfg, err := pgxpool.ParseConfig(cfg.DSN())
+	if err != nil {
+		return nil, fmt.Errorf("pgx connect: %w", err)
+	}
+	poolCfg.ConnConfig.Tracer = queryTracer{}
+	poolCfg.ConnConfig.OnNotice = d.notices.add
+	poolCfg.BeforeClose = func(c *pgx.Conn) { d.notices.take(c.PgConn()) }
+	// A cancelled context sends the server a cancel request, like
+	// pg_cancel_backend, so the statement stops there too and the
+	// connection stays usable; the deadline only breaks a hung server.
+	poolCfg.ConnConfig.BuildContextWatcherHandler = func(c *pgconn.PgConn) ctxwatch.Handler {
+		return &pgconn.CancelRequestContextWatcherHandler{Conn: c, DeadlineDelay: cancelDeadline}
+	}
+	pool, err := pgxpool.NewWithConfig(ctx, poolCfg)
 	if err != nil {
 		return nil, fmt.Errorf("pgx connect: %w", err)
 	}
@@ -56,11 +95,99 @@ func Connect(ctx context.Context, cfg config.Config) (*DB, error) {
 	}
 
 	d.Pool = pool
+	if err := d.loadSearchPath(ctx); err != nil {
+		d.Close()
+		return nil, fmt.Errorf("read search_path: %w", err)
+	}
 	return d, nil
 }
 
-// Close shuts down the pool and SSH tunnel.
+// ConnectionStep is the outcome of one step of TestConnection.
+type ConnectionStep struct {
+	Name    string
+	Err     error
+	Elapsed time.Duration
+}
+
+// TestConnection checks that cfg works without keeping anything open:
+// it starts the SSH tunnel (if enabled), opens a single connection, and
+// runs SELECT 1. It stops at the first failing step; ctx bounds the
+// whole test.
+func TestConnection(ctx context.Context, cfg config.Config) []ConnectionStep {
+	var steps []ConnectionStep
+	step := func(name string, fn func() error) bool {
+		start := time.Now()
+		err := fn()
+		steps = append(steps, ConnectionStep{Name: name, Err: err, Elapsed: time.Since(start)})
+		return err == nil
+	}
+
+	if cfg.SSH.Enabled {
+		var tunnel *ssh.Tunnel
+		ok := step("SSH tunnel", func() error {
+			var err error
+			tunnel, err = ssh.NewTunnel(cfg.SSH, cfg.Host, cfg.Port)
+			if err != nil {
+				return err
+			}
+			addr, err := tunnel.Start(ctx)
+			if err != nil {
+				return err
+			}
+			cfg.Host = addr.Host
+			cfg.Port = addr.Port
+			return nil
+		})
+		if !ok {
+			return steps
+		}
+		defer tunnel.Stop()
+	}
+
+	var conn *pgx.Conn
+	if !step("Connect", func() error {
+		var err error
+		conn, err = pgx.Connect(ctx, cfg.DSN())
+		return err
+	}) {
+		return steps
+	}
+	defer conn.Close(context.Background())
+
+	step("SELECT 1", func() error {
+		var one int
+		return conn.QueryRow(ctx, "SELECT 1").Scan(&one)
+	})
+	return steps
+}
+
+// DeallocateAll drops the prepared statements of every idle connection,
+// on the server and in pgx's statement cache, so plans cached before a
+// schema change are prepared again. It returns the number of connections
+// reset; connections busy with a query keep their statements.
+func (d *DB) DeallocateAll(ctx context.Context) (int, error) {
+	conns := d.Pool.AcquireAllIdle(ctx)
+	defer func() {
+		for _, c := range conns {
+			c.Release()
+		}
+	}()
+	for _, c := range conns {
+		if err := c.Conn().DeallocateAll(ctx); err != nil {
+			return 0, err
+		}
+	}
+	return len(conns), nil
+}
+
+// Close shuts down the listeners, the pool and the SSH tunnel.
 func (d *DB) Close() {
+	d.listenMu.Lock()
+	for _, l := range d.listeners {
+		l.Close()
+	}
+	d.listeners = nil
+	d.listenMu.Unlock()
 	if d.Pool != nil {
 		d.Pool.Close()
 	}
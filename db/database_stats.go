@@ -0,0 +1,44 @@
+// database_stats.go reads the cumulative activity counters of every
+// database from pg_stat_database: transactions, deadlocks, block reads
+// and hits, and tuples read. Rates come from comparing two readings.
+package db
+
+import "context"
+
+// DatabaseStats is a reading of a database's counters, cumulative since
+// the statistics were last reset.
+type DatabaseStats struct {
+	Name        string
+	Backends    int64 // connections right now
+	Commits     int64
+	Rollbacks   int64
+	Deadlocks   int64
+	BlksRead    int64 // blocks read from disk (or the OS cache)
+	BlksHit     int64 // blocks found in shared buffers
+	TupReturned int64 // rows read by scans
+	TupFetched  int64 // rows fetched by index scans
+}
+
+// FetchDatabaseStats reads the counters of every database, by name.
+func (d *DB) FetchDatabaseStats(ctx context.Context) ([]DatabaseStats, error) {
+	rows, err := d.Pool.Query(ctx, `
+		SELECT datname, numbackends, xact_commit, xact_rollback, deadlocks,
+		       blks_read, blks_hit, tup_returned, tup_fetched
+		FROM pg_stat_database
+		WHERE datname IS NOT NULL
+		ORDER BY datname`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var stats []DatabaseStats
+	for rows.Next() {
+		var s DatabaseStats
+		if err := rows.Scan(&s.Name, &s.Backends, &s.Commits, &s.Rollbacks, &s.Deadlocks,
+			&s.BlksRead, &s.BlksHit, &s.TupReturned, &s.TupFetched); err != nil {
+			return nil, err
+		}
+		stats = append(stats, s)
+	}
+	return stats, rows.Err()
+}
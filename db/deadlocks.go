@@ -0,0 +1,129 @@
+// deadlocks.go collects the deadlock reports from the server log. When
+// PostgreSQL breaks a deadlock it logs the cycle it found:
+//
+//	2024-05-01 12:03:04.120 UTC [4211] ERROR:  deadlock detected
+//	2024-05-01 12:03:04.120 UTC [4211] DETAIL:  Process 4211 waits for ShareLock on transaction 981; blocked by process 4198.
+//		Process 4198 waits for ShareLock on transaction 980; blocked by process 4211.
+//		Process 4211: UPDATE accounts SET balance = balance - 10 WHERE id = 2
+//		Process 4198: UPDATE accounts SET balance = balance + 10 WHERE id = 1
+//
+// The log is read with pg_read_file, which needs superuser or the
+// pg_read_server_files role, and only the stderr log (logging_collector
+// on, log_destination including stderr) is parsed. Only the tail of the
+// current log file is read, so the history covers recent deadlocks.
+package db
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// deadlockLogTail is how much of the end of the log file is read.
+const deadlockLogTail = 4 << 20
+
+var (
+	deadlockWait  = regexp.MustCompile(`Process (\d+) waits for (.+?); blocked by process (\d+)\.`)
+	deadlockQuery = regexp.MustCompile(`^Process (\d+): (.*)$`)
+	logTimestamp  = regexp.MustCompile(`\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2}(\.\d+)?( [A-Za-z]+| [+-]\d{2}(:?\d{2})?)?`)
+)
+
+// Deadlock is a deadlock found in the server log.
+type Deadlock struct {
+	At        string // timestamp from the log line prefix, "" if it has none
+	Processes []DeadlockProcess
+}
+
+// DeadlockProcess is one process of a deadlock cycle. Each waits for the
+// next, and the last for the first; the first is the one whose
+// transaction was cancelled to break the cycle.
+type DeadlockProcess struct {
+	PID       int
+	WaitsFor  string // e.g. "ShareLock on transaction 981"
+	BlockedBy int
+	Query     string
+}
+
+// ReadDeadlocks reads the deadlocks reported in the tail of the current
+// server log, oldest first.
+func (d *DB) ReadDeadlocks(ctx context.Context) ([]Deadlock, error) {
+	var file *string
+	if err := d.Pool.QueryRow(ctx, "SELECT pg_current_logfile('stderr')").Scan(&file); err != nil {
+		return nil, err
+	}
+	if file == nil {
+		return nil, errors.New("the server writes no stderr log file (logging_collector off, or log_destination without stderr)")
+	}
+	var log string
+	if err := d.Pool.QueryRow(ctx, `
+		SELECT pg_read_file($1, greatest((pg_stat_file($1)).size - $2, 0), $2)`,
+		*file, deadlockLogTail).Scan(&log); err != nil {
+		return nil, err
+	}
+	return ParseDeadlocks(log), nil
+}
+
+// ParseDeadlocks extracts the deadlock reports of a stderr log, whatever
+// its log_line_prefix.
+func ParseDeadlocks(log string) []Deadlock {
+	var deadlocks []Deadlock
+	var cur *Deadlock
+	var last *DeadlockProcess // the process whose query a continuation line extends
+	inDetail := false
+	sc := bufio.NewScanner(strings.NewReader(log))
+	sc.Buffer(make([]byte, 64*1024), deadlockLogTail)
+	for sc.Scan() {
+		line := sc.Text()
+		if i := strings.Index(line, "ERROR:  deadlock detected"); i >= 0 {
+			deadlocks = append(deadlocks, Deadlock{At: logTimestamp.FindString(line[:i])})
+			cur, last, inDetail = &deadlocks[len(deadlocks)-1], nil, false
+			continue
+		}
+		if cur == nil {
+			continue
+		}
+		text := line
+		switch {
+		case !inDetail && strings.Contains(line, "DETAIL:  "):
+			inDetail = true
+			text = line[strings.Index(line, "DETAIL:  ")+len("DETAIL:  "):]
+		case inDetail && strings.HasPrefix(line, "\t"):
+			text = line[1:]
+		default:
+			// The report ended (HINT, STATEMENT, or another message)
+			if inDetail {
+				cur = nil
+			}
+			continue
+		}
+
+		if m := deadlockWait.FindStringSubmatch(text); m != nil {
+			pid, _ := strconv.Atoi(m[1])
+			blocker, _ := strconv.Atoi(m[3])
+			cur.Processes = append(cur.Processes, DeadlockProcess{PID: pid, WaitsFor: m[2], BlockedBy: blocker})
+			last = nil
+			continue
+		}
+		if m := deadlockQuery.FindStringSubmatch(text); m != nil {
+			pid, _ := strconv.Atoi(m[1])
+			last = nil
+			for i := range cur.Processes {
+				if cur.Processes[i].PID == pid {
+					last = &cur.Processes[i]
+					last.Query = m[2]
+				}
+			}
+			continue
+		}
+		if last != nil {
+			last.Query += " " + strings.TrimSpace(text) // a multi-line query
+		}
+	}
+	// A report whose DETAIL was cut off by the end of the tail has no
+	// processes
+	return slices.DeleteFunc(deadlocks, func(dl Deadlock) bool { return len(dl.Processes) == 0 })
+}
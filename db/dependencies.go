@@ -0,0 +1,192 @@
+// dependencies.go finds the views and materialized views built on a
+// relation, so the blast radius of ALTER or DROP is visible up front.
+//
+// Views depend on relations through their rewrite rule: pg_depend links
+// the rule (pg_rewrite) to each relation it reads, and the rule belongs
+// to the view (pg_rewrite.ev_class). Following that edge repeatedly
+// yields the views of views.
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	pgx "github.com/jackc/pgx/v5"
+)
+
+// maxDependencyDepth bounds the view-of-view walk.
+const maxDependencyDepth = 32
+
+// DependentView is a view or materialized view that reads a relation,
+// directly (Depth 1) or through other views.
+type DependentView struct {
+	Schema string
+	Name   string
+	Kind   string // "view" or "materialized view"
+	Depth  int
+	Via    string // qualified name of the relation it reads, at Depth
+}
+
+// ReferencingKey is a foreign key on another table that references the
+// relation; DROP … CASCADE drops the constraint, not the table.
+type ReferencingKey struct {
+	Schema     string
+	Table      string
+	Constraint string
+}
+
+// DependencyReport lists what depends on a relation.
+type DependencyReport struct {
+	Schema      string
+	Name        string
+	Kind        string // "table", "view", "materialized view", ...
+	Views       []DependentView
+	ForeignKeys []ReferencingKey
+}
+
+// Dependencies reports the views, materialized views and foreign keys
+// that depend on schema.name. An empty schema resolves name through the
+// search path.
+func (d *DB) Dependencies(ctx context.Context, schema, name string) (*DependencyReport, error) {
+	rel := pgx.Identifier{name}.Sanitize()
+	if schema != "" {
+		rel = pgx.Identifier{schema, name}.Sanitize()
+	}
+
+	report := &DependencyReport{}
+	err := d.Pool.QueryRow(ctx, `
+		SELECT n.nspname, c.relname,
+		       CASE c.relkind WHEN 'r' THEN 'table' WHEN 'p' THEN 'partitioned table'
+		                      WHEN 'v' THEN 'view' WHEN 'm' THEN 'materialized view'
+		                      WHEN 'f' THEN 'foreign table' ELSE 'relation' END
+		FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE c.oid = to_regclass($1)`, rel).Scan(&report.Schema, &report.Name, &report.Kind)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("relation %s not found", rel)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		WITH RECURSIVE deps(oid, depth, via) AS (
+		  SELECT r.ev_class, 1, to_regclass($1)::oid
+		  FROM pg_depend d
+		  JOIN pg_rewrite r ON r.oid = d.objid
+		  WHERE d.classid = 'pg_rewrite'::regclass
+		    AND d.refclassid = 'pg_class'::regclass
+		    AND d.refobjid = to_regclass($1)
+		    AND r.ev_class <> d.refobjid
+		  UNION
+		  SELECT r.ev_class, deps.depth + 1, deps.oid
+		  FROM deps
+		  JOIN pg_depend d ON d.refobjid = deps.oid
+		    AND d.classid = 'pg_rewrite'::regclass
+		    AND d.refclassid = 'pg_class'::regclass
+		  JOIN pg_rewrite r ON r.oid = d.objid
+		  WHERE r.ev_class <> deps.oid AND deps.depth < $2
+		)
+		SELECT n.nspname, c.relname,
+		       CASE c.relkind WHEN 'm' THEN 'materialized view' ELSE 'view' END,
+		       nearest.depth, vn.nspname || '.' || v.relname
+		FROM (
+		  SELECT DISTINCT ON (oid) oid, depth, via
+		  FROM deps
+		  ORDER BY oid, depth
+		) nearest
+		JOIN pg_class c ON c.oid = nearest.oid
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		JOIN pg_class v ON v.oid = nearest.via
+		JOIN pg_namespace vn ON vn.oid = v.relnamespace
+		ORDER BY nearest.depth, n.nspname, c.relname`
+	rows, err := d.Pool.Query(ctx, query, rel, maxDependencyDepth)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var v DependentView
+		if err := rows.Scan(&v.Schema, &v.Name, &v.Kind, &v.Depth, &v.Via); err != nil {
+			return nil, err
+		}
+		report.Views = append(report.Views, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = d.Pool.Query(ctx, `
+		SELECT n.nspname, c.relname, con.conname
+		FROM pg_constraint con
+		JOIN pg_class c ON c.oid = con.conrelid
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE con.contype = 'f'
+		  AND con.confrelid = to_regclass($1)
+		  AND con.conrelid <> con.confrelid
+		ORDER BY n.nspname, c.relname, con.conname`, rel)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var k ReferencingKey
+		if err := rows.Scan(&k.Schema, &k.Table, &k.Constraint); err != nil {
+			return nil, err
+		}
+		report.ForeignKeys = append(report.ForeignKeys, k)
+	}
+	return report, rows.Err()
+}
+
+// QualifiedName returns the relation's schema-qualified, quoted name.
+func (r *DependencyReport) QualifiedName() string {
+	return pgx.Identifier{r.Schema, r.Name}.Sanitize()
+}
+
+// DropCascadeSQL returns the DROP … CASCADE statement for the relation.
+// It is a preview only; nothing here executes it.
+func (r *DependencyReport) DropCascadeSQL() string {
+	kind := "TABLE"
+	switch r.Kind {
+	case "view":
+		kind = "VIEW"
+	case "materialized view":
+		kind = "MATERIALIZED VIEW"
+	case "foreign table":
+		kind = "FOREIGN TABLE"
+	}
+	return fmt.Sprintf("DROP %s %s CASCADE;", kind, r.QualifiedName())
+}
+
+// DropCascadeEffects lists, one line each, everything DropCascadeSQL
+// would drop besides the relation itself, deepest views last.
+func (r *DependencyReport) DropCascadeEffects() []string {
+	var effects []string
+	for _, v := range r.Views {
+		effects = append(effects, fmt.Sprintf("%s %s", v.Kind, pgx.Identifier{v.Schema, v.Name}.Sanitize()))
+	}
+	for _, k := range r.ForeignKeys {
+		effects = append(effects, fmt.Sprintf("constraint %s on table %s",
+			pgx.Identifier{k.Constraint}.Sanitize(), pgx.Identifier{k.Schema, k.Table}.Sanitize()))
+	}
+	return effects
+}
+
+// Summary describes the dependents in one line, e.g. "3 dependent views,
+// 1 referencing foreign key".
+func (r *DependencyReport) Summary() string {
+	var parts []string
+	if n := len(r.Views); n > 0 {
+		parts = append(parts, fmt.Sprintf("%d dependent view%s", n, plural(n)))
+	}
+	if n := len(r.ForeignKeys); n > 0 {
+		parts = append(parts, fmt.Sprintf("%d referencing foreign key%s", n, plural(n)))
+	}
+	if len(parts) == 0 {
+		return "nothing depends on it"
+	}
+	return strings.Join(parts, ", ")
+}
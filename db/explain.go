@@ -0,0 +1,101 @@
+// explain.go reads EXPLAIN output for UPDATE/DELETE statements so the
+// TUI can show how many rows a modification would touch before the user
+// runs it. Plain EXPLAIN only plans the statement; nothing is modified.
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ModificationEstimate summarizes the plan of an UPDATE or DELETE.
+type ModificationEstimate struct {
+	Operation string   // "Update", "Delete", "Insert" or "Merge"
+	Table     string   // target table
+	Rows      int64    // planner's estimate of affected rows
+	Indexes   []string // indexes used to find the rows
+	SeqScans  []string // tables read with a sequential scan
+}
+
+// planNode is the subset of EXPLAIN (FORMAT JSON) node fields we read.
+type planNode struct {
+	NodeType     string     `json:"Node Type"`
+	Operation    string     `json:"Operation"`
+	RelationName string     `json:"Relation Name"`
+	IndexName    string     `json:"Index Name"`
+	PlanRows     float64    `json:"Plan Rows"`
+	Plans        []planNode `json:"Plans"`
+}
+
+// EstimateModification plans sql with EXPLAIN (without ANALYZE) and
+// reports the estimated affected rows and how they would be found. sql
+// must be one statement: EXPLAIN plans only the first, and the simple
+// query protocol would run the rest for real.
+func (d *DB) EstimateModification(ctx context.Context, sql string) (*ModificationEstimate, error) {
+	sql = strings.TrimRight(strings.TrimSpace(sql), ";")
+	if !singleStatement(sql) {
+		return nil, fmt.Errorf("more than one statement: only a single statement can be estimated")
+	}
+	plan, err := d.Explain(ctx, sql, false)
+	if err != nil {
+		return nil, err
+	}
+	return parseModificationEstimate(plan.JSON)
+}
+
+// singleStatement reports whether sql holds one statement: nothing but
+// semicolons and comments follows its first semicolon.
+func singleStatement(sql string) bool {
+	ended := false
+	for _, tok := range tokenizeSQL(sql) {
+		switch {
+		case tok.kind == tokComment || tok.kind == tokLineComment:
+		case tok.kind == tokPunct && tok.text == ";":
+			ended = true
+		case ended:
+			return false
+		}
+	}
+	return true
+}
+
+func parseModificationEstimate(jsonPlan string) (*ModificationEstimate, error) {
+	var plans []struct {
+		Plan planNode `json:"Plan"`
+	}
+	if err := json.Unmarshal([]byte(jsonPlan), &plans); err != nil {
+		return nil, fmt.Errorf("parse plan: %w", err)
+	}
+	if len(plans) == 0 {
+		return nil, fmt.Errorf("empty plan")
+	}
+
+	root := plans[0].Plan
+	if root.NodeType != "ModifyTable" {
+		return nil, fmt.Errorf("not a modification plan (%s)", root.NodeType)
+	}
+	est := &ModificationEstimate{Operation: root.Operation, Table: root.RelationName}
+
+	// ModifyTable itself estimates 0 rows without RETURNING; the rows it
+	// would modify are those produced by its input.
+	for _, child := range root.Plans {
+		est.Rows += int64(child.PlanRows)
+	}
+
+	var walk func(n planNode)
+	walk = func(n planNode) {
+		switch {
+		case n.IndexName != "":
+			est.Indexes = append(est.Indexes, n.IndexName)
+		case n.NodeType == "Seq Scan":
+			est.SeqScans = append(est.SeqScans, n.RelationName)
+		}
+		for _, c := range n.Plans {
+			walk(c)
+		}
+	}
+	walk(root)
+	return est, nil
+}
@@ -0,0 +1,70 @@
+package db
+
+import (
+	"context"
+	"io"
+	"strings"
+	"sync/atomic"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ExportProgress counts what an export has written so far. It is updated
+// by the export while it runs and can be read from another goroutine.
+type ExportProgress struct {
+	bytes  atomic.Int64
+	writes atomic.Int64
+}
+
+// Bytes is the number of bytes written.
+func (p *ExportProgress) Bytes() int64 { return p.bytes.Load() }
+
+// Rows is the number of rows written, not counting the header.
+func (p *ExportProgress) Rows() int64 { return max(p.writes.Load()-1, 0) }
+
+// progressWriter counts the bytes and writes passing through to w.
+type progressWriter struct {
+	w io.Writer
+	p *ExportProgress
+}
+
+func (pw progressWriter) Write(b []byte) (int, error) {
+	n, err := pw.w.Write(b)
+	pw.p.bytes.Add(int64(n))
+	pw.p.writes.Add(1)
+	return n, err
+}
+
+// ExportCSV streams the rows of the query sql to w as CSV with a header
+// row, using COPY ... TO STDOUT, so the result is never held in memory.
+// It runs in a read-only transaction: a statement that writes fails
+// instead of running again. p, if not nil, follows the progress. It
+// returns the number of rows written.
+//
+// Each Write to w is one whole row, the first one the header: the server
+// sends a COPY row per message. So w can split the output between rows.
+func (d *DB) ExportCSV(ctx context.Context, w io.Writer, sql string, p *ExportProgress) (int64, error) {
+	sql = strings.TrimRight(strings.TrimSpace(sql), "; \t\n")
+	if p == nil {
+		p = &ExportProgress{}
+	}
+
+	conn, err := d.Pool.Acquire(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Release()
+	tx, err := conn.BeginTx(ctx, pgx.TxOptions{AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(context.Background())
+
+	// The line breaks keep a trailing -- comment from swallowing the ")".
+	copySQL := "COPY (\n" + sql + "\n) TO STDOUT WITH (FORMAT csv, HEADER)"
+	tag, err := tx.Conn().PgConn().CopyTo(ctx, progressWriter{w: w, p: p}, copySQL)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
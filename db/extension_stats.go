@@ -0,0 +1,189 @@
+// extension_stats.go reads the statistics of the Citus and TimescaleDB
+// extensions, whose tables don't size like ordinary ones: a distributed
+// table's rows live in shards on the workers and a hypertable's in chunks
+// in _timescaledb_internal, so the parent relation itself is (nearly)
+// empty and the chunks clutter every table listing.
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	pgx "github.com/jackc/pgx/v5"
+)
+
+// Extension is an installed extension.
+type Extension struct {
+	Schema  string
+	Version string
+}
+
+// Hypertable is a TimescaleDB hypertable.
+type Hypertable struct {
+	Schema           string
+	Name             string
+	Chunks           int64
+	CompressedChunks int64
+	Size             int64 // bytes, all chunks and their indexes
+	BeforeCompress   int64 // bytes of the compressed chunks before compression
+	AfterCompress    int64 // bytes of the compressed chunks after compression
+}
+
+// CompressionRatio returns how many times smaller the compressed chunks
+// got, or 0 when nothing is compressed.
+func (h Hypertable) CompressionRatio() float64 {
+	if h.AfterCompress == 0 {
+		return 0
+	}
+	return float64(h.BeforeCompress) / float64(h.AfterCompress)
+}
+
+// DistributedTable is a Citus distributed or reference table.
+type DistributedTable struct {
+	Name   string // as regclass prints it
+	Type   string // "distributed", "reference", ...
+	Column string // distribution column, "" for reference tables
+	Shards int64
+	Size   int64 // bytes, across all shards
+}
+
+// ExtensionStats holds what the installed Citus and TimescaleDB
+// extensions report; the slices are empty when they aren't installed.
+type ExtensionStats struct {
+	Extensions  map[string]Extension
+	Hypertables []Hypertable
+	Workers     int // active Citus worker nodes
+	Distributed []DistributedTable
+}
+
+// Has reports whether the extension is installed.
+func (s *ExtensionStats) Has(name string) bool {
+	_, ok := s.Extensions[name]
+	return ok
+}
+
+// FetchExtensionStats reads the installed extensions and, for Citus and
+// TimescaleDB, their tables' statistics.
+func (d *DB) FetchExtensionStats(ctx context.Context) (*ExtensionStats, error) {
+	s := &ExtensionStats{Extensions: map[string]Extension{}}
+	rows, err := d.Pool.Query(ctx, `
+		SELECT e.extname, n.nspname, e.extversion
+		FROM pg_extension e
+		JOIN pg_namespace n ON n.oid = e.extnamespace`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		var ext Extension
+		if err := rows.Scan(&name, &ext.Schema, &ext.Version); err != nil {
+			return nil, err
+		}
+		s.Extensions[name] = ext
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if ext, ok := s.Extensions["timescaledb"]; ok {
+		if s.Hypertables, err = d.hypertables(ctx, ext.Schema); err != nil {
+			return nil, fmt.Errorf("timescaledb: %w", err)
+		}
+	}
+	if s.Has("citus") {
+		if err := d.citusStats(ctx, s); err != nil {
+			return nil, fmt.Errorf("citus: %w", err)
+		}
+	}
+	return s, nil
+}
+
+// hypertables lists the hypertables, largest first. The size functions
+// live in the extension's schema.
+func (d *DB) hypertables(ctx context.Context, extSchema string) ([]Hypertable, error) {
+	fn := func(name string) string { return pgx.Identifier{extSchema, name}.Sanitize() }
+	query := fmt.Sprintf(`
+		SELECT h.hypertable_schema, h.hypertable_name, h.num_chunks,
+		       COALESCE(%s(t.rel), 0),
+		       COALESCE(c.compressed, 0), COALESCE(c.before, 0), COALESCE(c.after, 0)
+		FROM timescaledb_information.hypertables h
+		CROSS JOIN LATERAL (
+		  SELECT format('%%I.%%I', h.hypertable_schema, h.hypertable_name)::regclass AS rel
+		) t
+		LEFT JOIN LATERAL (
+		  SELECT sum(number_compressed_chunks)::bigint AS compressed,
+		         sum(before_compression_total_bytes)::bigint AS before,
+		         sum(after_compression_total_bytes)::bigint AS after
+		  FROM %s(t.rel)
+		  WHERE h.compression_enabled
+		) c ON true
+		ORDER BY 4 DESC, 1, 2`, fn("hypertable_size"), fn("hypertable_compression_stats"))
+	rows, err := d.Pool.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var tables []Hypertable
+	for rows.Next() {
+		var h Hypertable
+		if err := rows.Scan(&h.Schema, &h.Name, &h.Chunks, &h.Size,
+			&h.CompressedChunks, &h.BeforeCompress, &h.AfterCompress); err != nil {
+			return nil, err
+		}
+		tables = append(tables, h)
+	}
+	return tables, rows.Err()
+}
+
+// citusStats reads the worker count and the distributed tables, largest
+// first.
+func (d *DB) citusStats(ctx context.Context, s *ExtensionStats) error {
+	if err := d.Pool.QueryRow(ctx,
+		"SELECT count(*) FROM citus_get_active_worker_nodes()").Scan(&s.Workers); err != nil {
+		return err
+	}
+	rows, err := d.Pool.Query(ctx, `
+		SELECT table_name::text, citus_table_type, distribution_column, shard_count,
+		       citus_total_relation_size(table_name)
+		FROM citus_tables
+		ORDER BY 5 DESC, 1`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var t DistributedTable
+		if err := rows.Scan(&t.Name, &t.Type, &t.Column, &t.Shards, &t.Size); err != nil {
+			return err
+		}
+		if t.Column == "<none>" {
+			t.Column = ""
+		}
+		s.Distributed = append(s.Distributed, t)
+	}
+	return rows.Err()
+}
+
+// OrdinaryTables returns a condition on a pg_stat_user_tables row (its
+// relid and schemaname) that leaves out the extensions' internal tables
+// and the tables listed with their own statistics: TimescaleDB chunks and
+// hypertables, and Citus distributed tables. It returns "true" when
+// neither extension is installed.
+func (s *ExtensionStats) OrdinaryTables() string {
+	var conds []string
+	if s.Has("timescaledb") {
+		conds = append(conds,
+			`schemaname NOT LIKE '\_timescaledb\_%'`,
+			`relid NOT IN (SELECT format('%I.%I', hypertable_schema, hypertable_name)::regclass
+			               FROM timescaledb_information.hypertables)`)
+	}
+	if s.Has("citus") {
+		conds = append(conds, "relid NOT IN (SELECT logicalrelid FROM pg_dist_partition)")
+	}
+	if len(conds) == 0 {
+		return "true"
+	}
+	return strings.Join(conds, " AND ")
+}
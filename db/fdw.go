@@ -0,0 +1,128 @@
+// fdw.go builds and applies postgres_fdw setup so the current database
+// can query tables living in another (saved) connection's database.
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/DachengChen/paiSQL/config"
+	"github.com/jackc/pgx/v5"
+)
+
+// FDWPlan is the reviewed list of statements that links a remote database
+// into the current one via postgres_fdw.
+type FDWPlan struct {
+	Server       string   // foreign server name
+	RemoteSchema string   // schema imported from the remote database
+	LocalSchema  string   // local schema that receives the foreign tables
+	Statements   []string // DDL, in execution order
+	Warnings     []string // things the user should double-check
+}
+
+// NewFDWPlan generates the statements that create a foreign server for
+// conn, map the current user to conn's credentials, and import
+// remoteSchema into localSchema. Empty schemas default to "public" and
+// the sanitized connection name.
+func NewFDWPlan(conn config.Connection, remoteSchema, localSchema string) *FDWPlan {
+	name := fdwName(conn.Name)
+	if remoteSchema == "" {
+		remoteSchema = "public"
+	}
+	if localSchema == "" {
+		localSchema = name
+	}
+	server := "fdw_" + name
+
+	p := &FDWPlan{Server: server, RemoteSchema: remoteSchema, LocalSchema: localSchema}
+
+	opts := []string{
+		"host " + quoteLiteral(conn.Host),
+		"port " + quoteLiteral(conn.Port),
+		"dbname " + quoteLiteral(conn.Database),
+	}
+	if conn.SSLMode != "" {
+		opts = append(opts, "sslmode "+quoteLiteral(conn.SSLMode))
+	}
+
+	p.Statements = []string{
+		"CREATE EXTENSION IF NOT EXISTS postgres_fdw",
+		fmt.Sprintf("CREATE SERVER %s FOREIGN DATA WRAPPER postgres_fdw OPTIONS (%s)",
+			ident(server), strings.Join(opts, ", ")),
+		fmt.Sprintf("CREATE USER MAPPING FOR CURRENT_USER SERVER %s OPTIONS (user %s, password %s)",
+			ident(server), quoteLiteral(conn.User), quoteLiteral(conn.Password)),
+		fmt.Sprintf("CREATE SCHEMA IF NOT EXISTS %s", ident(localSchema)),
+		fmt.Sprintf("IMPORT FOREIGN SCHEMA %s FROM SERVER %s INTO %s",
+			ident(remoteSchema), ident(server), ident(localSchema)),
+	}
+
+	// The remote host is resolved by the database server, not by paiSQL.
+	if conn.SSH.Enabled {
+		p.Warnings = append(p.Warnings, fmt.Sprintf(
+			"%q uses an SSH tunnel; the database server must reach %s:%s directly.", conn.Name, conn.Host, conn.Port))
+	}
+	if conn.Host == "localhost" || conn.Host == "127.0.0.1" {
+		p.Warnings = append(p.Warnings,
+			"host is localhost — from the database server this means the server itself.")
+	}
+	if conn.Password == "" {
+		p.Warnings = append(p.Warnings,
+			"no password saved; postgres_fdw requires password authentication for non-superusers.")
+	}
+	return p
+}
+
+// DisplayStatements returns the statements with the password masked, for
+// showing on screen.
+func (p *FDWPlan) DisplayStatements(password string) []string {
+	out := make([]string, len(p.Statements))
+	for i, s := range p.Statements {
+		if password != "" {
+			s = strings.Replace(s, "password "+quoteLiteral(password), "password '********'", 1)
+		}
+		out[i] = s + ";"
+	}
+	return out
+}
+
+// ApplyFDWPlan runs the plan's statements in a single transaction, so a
+// failure part way leaves no half-configured server behind.
+func (d *DB) ApplyFDWPlan(ctx context.Context, p *FDWPlan) error {
+	tx, err := d.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	for i, stmt := range p.Statements {
+		if _, err := tx.Exec(ctx, stmt); err != nil {
+			return fmt.Errorf("statement %d: %w", i+1, err)
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// fdwName turns a connection name into a safe lowercase identifier.
+func fdwName(name string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '_' {
+			sb.WriteRune(r)
+		} else {
+			sb.WriteRune('_')
+		}
+	}
+	if sb.Len() == 0 {
+		return "remote"
+	}
+	return sb.String()
+}
+
+func ident(name string) string {
+	return pgx.Identifier{name}.Sanitize()
+}
+
+func quoteLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,67 @@
+// format.go converts values returned by pgx into display strings, and
+// back into SQL literals where that is reliable.
+package db
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// FormatValue renders a pgx value as text. Most values use %v, but some
+// pgtype values (numeric, uuid) have no useful %v representation.
+func FormatValue(v any) string {
+	switch val := v.(type) {
+	case [16]byte:
+		return fmt.Sprintf("%x-%x-%x-%x-%x", val[0:4], val[4:6], val[6:8], val[8:10], val[10:16])
+	case driver.Valuer:
+		dv, err := val.Value()
+		if err != nil {
+			return fmt.Sprintf("%v", v)
+		}
+		return fmt.Sprintf("%v", dv)
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// ValueLiteral turns a value rendered by FormatValue back into a quoted
+// SQL literal of a column of type colType (a pg_type name), for filters
+// built from a result. It fails for types whose rendering PostgreSQL
+// can't read back: JSON, arrays, bytea, intervals, pgvector, PostGIS and
+// unknown types.
+func ValueLiteral(colType, cell string) (string, error) {
+	unsupported := fmt.Errorf("can't filter on a column of type %s", typeLabel(colType))
+	if colType == "" || strings.HasPrefix(colType, "_") || IsVectorType(colType) || IsGeometryType(colType) {
+		return "", unsupported
+	}
+	switch colType {
+	case "json", "jsonb", "bytea", "interval", "time", "timetz", "xml", "tsvector", "tsquery":
+		return "", unsupported
+	case "timestamptz", "timestamp", "date":
+		t, err := time.Parse(valueTimeLayout, cell)
+		if err != nil {
+			return "", fmt.Errorf("can't read %q as a %s", cell, colType)
+		}
+		switch colType {
+		case "timestamptz":
+			cell = t.Format("2006-01-02 15:04:05.999999999Z07:00")
+		case "timestamp":
+			cell = t.Format("2006-01-02 15:04:05.999999999")
+		default:
+			cell = t.Format("2006-01-02")
+		}
+	}
+	return quoteLiteral(cell), nil
+}
+
+// typeLabel names a column type in messages.
+func typeLabel(colType string) string {
+	switch {
+	case colType == "":
+		return "unknown"
+	case strings.HasPrefix(colType, "_"):
+		return colType[1:] + "[]"
+	}
+	return colType
+}
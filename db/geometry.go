@@ -0,0 +1,439 @@
+// geometry.go adds PostGIS helpers: a minimal EWKB reader used to render
+// geometry/geography values as readable summaries in the grid, and to
+// write result sets as GeoJSON.
+//
+// PostGIS returns geometries as hex-encoded EWKB in text mode. Rather than
+// round-tripping through ST_AsText for every cell, we decode the header and
+// walk the coordinates locally to get the type, SRID, and bounding box.
+package db
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+// IsGeometryType reports whether a pg_type name is a PostGIS spatial type.
+func IsGeometryType(typeName string) bool {
+	return typeName == "geometry" || typeName == "geography" ||
+		strings.HasPrefix(typeName, "geometry(") || strings.HasPrefix(typeName, "geography(")
+}
+
+var wkbTypeNames = map[uint32]string{
+	1: "POINT",
+	2: "LINESTRING",
+	3: "POLYGON",
+	4: "MULTIPOINT",
+	5: "MULTILINESTRING",
+	6: "MULTIPOLYGON",
+	7: "GEOMETRYCOLLECTION",
+}
+
+// SummarizeGeometry renders a hex EWKB value as e.g.
+//
+//	POINT SRID=4326 (13.4 52.5)
+//	POLYGON SRID=4326 bbox(13.1 52.3, 13.7 52.6) 5 pts
+//
+// Values that can't be decoded are returned unchanged.
+func SummarizeGeometry(value string) string {
+	raw, err := hex.DecodeString(value)
+	if err != nil || len(raw) < 5 {
+		return value
+	}
+	r := &wkbReader{buf: raw}
+	g, err := r.readGeometry()
+	if err != nil {
+		return value
+	}
+
+	var sb strings.Builder
+	sb.WriteString(g.typeName)
+	if g.srid != 0 {
+		sb.WriteString(fmt.Sprintf(" SRID=%d", g.srid))
+	}
+	switch {
+	case g.points == 0:
+		sb.WriteString(" EMPTY")
+	case g.points == 1:
+		sb.WriteString(fmt.Sprintf(" (%s %s)", fmtCoord(g.minX), fmtCoord(g.minY)))
+	default:
+		sb.WriteString(fmt.Sprintf(" bbox(%s %s, %s %s) %d pts",
+			fmtCoord(g.minX), fmtCoord(g.minY), fmtCoord(g.maxX), fmtCoord(g.maxY), g.points))
+	}
+	return sb.String()
+}
+
+func fmtCoord(f float64) string {
+	return strings.TrimRight(strings.TrimRight(fmt.Sprintf("%.6f", f), "0"), ".")
+}
+
+// wkbGeom accumulates what we learn while walking an EWKB value.
+type wkbGeom struct {
+	typeName               string
+	srid                   uint32
+	points                 int
+	minX, minY, maxX, maxY float64
+}
+
+type wkbReader struct {
+	buf   []byte
+	pos   int
+	order binary.ByteOrder
+	dims  int
+	geom  wkbGeom
+}
+
+func (r *wkbReader) uint32() (uint32, error) {
+	if r.pos+4 > len(r.buf) {
+		return 0, fmt.Errorf("wkb: unexpected end of data")
+	}
+	v := r.order.Uint32(r.buf[r.pos:])
+	r.pos += 4
+	return v, nil
+}
+
+func (r *wkbReader) float64() (float64, error) {
+	if r.pos+8 > len(r.buf) {
+		return 0, fmt.Errorf("wkb: unexpected end of data")
+	}
+	v := math.Float64frombits(r.order.Uint64(r.buf[r.pos:]))
+	r.pos += 8
+	return v, nil
+}
+
+// readHeader reads a geometry's byte order and type: the base type (1 for
+// a point to 7 for a collection), whether it has Z and M coordinates, and
+// its SRID, 0 if it has none. It sets the byte order and dimensions the
+// coordinates that follow are read with.
+func (r *wkbReader) readHeader() (typ uint32, hasZ, hasM bool, srid uint32, err error) {
+	if r.pos >= len(r.buf) {
+		return 0, false, false, 0, fmt.Errorf("wkb: unexpected end of data")
+	}
+	if r.buf[r.pos] == 0 {
+		r.order = binary.BigEndian
+	} else {
+		r.order = binary.LittleEndian
+	}
+	r.pos++
+
+	if typ, err = r.uint32(); err != nil {
+		return 0, false, false, 0, err
+	}
+
+	// EWKB flags, then ISO WKB Z/M offsets (1000, 2000, 3000)
+	hasZ, hasM = typ&0x80000000 != 0, typ&0x40000000 != 0
+	if typ&0x20000000 != 0 {
+		if srid, err = r.uint32(); err != nil {
+			return 0, false, false, 0, err
+		}
+	}
+	typ &= 0x0FFFFFFF
+	switch typ / 1000 {
+	case 1:
+		hasZ = true
+	case 2:
+		hasM = true
+	case 3:
+		hasZ, hasM = true, true
+	}
+	typ %= 1000
+	r.dims = 2
+	if hasZ {
+		r.dims++
+	}
+	if hasM {
+		r.dims++
+	}
+	return typ, hasZ, hasM, srid, nil
+}
+
+// readGeometry reads one (possibly nested) geometry and returns the
+// accumulated summary. The outermost header determines the type and SRID.
+func (r *wkbReader) readGeometry() (*wkbGeom, error) {
+	typ, _, _, srid, err := r.readHeader()
+	if err != nil {
+		return nil, err
+	}
+
+	name, ok := wkbTypeNames[typ]
+	if !ok {
+		return nil, fmt.Errorf("wkb: unknown geometry type %d", typ)
+	}
+	if r.geom.typeName == "" {
+		r.geom.typeName = name
+		r.geom.srid = srid
+	}
+
+	switch typ {
+	case 1:
+		err = r.readPoints(1)
+	case 2:
+		err = r.readCountedPoints()
+	case 3:
+		err = r.readRings()
+	default: // multi* and collections contain full geometries
+		var n uint32
+		if n, err = r.uint32(); err != nil {
+			return nil, err
+		}
+		for i := uint32(0); i < n && err == nil; i++ {
+			_, err = r.readGeometry()
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &r.geom, nil
+}
+
+func (r *wkbReader) readRings() error {
+	n, err := r.uint32()
+	if err != nil {
+		return err
+	}
+	for i := uint32(0); i < n; i++ {
+		if err := r.readCountedPoints(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *wkbReader) readCountedPoints() error {
+	n, err := r.uint32()
+	if err != nil {
+		return err
+	}
+	return r.readPoints(int(n))
+}
+
+func (r *wkbReader) readPoints(n int) error {
+	for i := 0; i < n; i++ {
+		x, err := r.float64()
+		if err != nil {
+			return err
+		}
+		y, err := r.float64()
+		if err != nil {
+			return err
+		}
+		for d := 2; d < r.dims; d++ {
+			if _, err := r.float64(); err != nil {
+				return err
+			}
+		}
+		// An empty point is encoded as NaN coordinates
+		if math.IsNaN(x) || math.IsNaN(y) {
+			continue
+		}
+		g := &r.geom
+		if g.points == 0 {
+			g.minX, g.maxX, g.minY, g.maxY = x, x, y, y
+		} else {
+			g.minX = math.Min(g.minX, x)
+			g.maxX = math.Max(g.maxX, x)
+			g.minY = math.Min(g.minY, y)
+			g.maxY = math.Max(g.maxY, y)
+		}
+		g.points++
+	}
+	return nil
+}
+
+// geoJSONTypes names the WKB geometry types in GeoJSON.
+var geoJSONTypes = map[uint32]string{
+	1: "Point",
+	2: "LineString",
+	3: "Polygon",
+	4: "MultiPoint",
+	5: "MultiLineString",
+	6: "MultiPolygon",
+	7: "GeometryCollection",
+}
+
+// geoJSONFeature is a GeoJSON Feature, with its members in the usual order.
+type geoJSONFeature struct {
+	Type       string         `json:"type"`
+	Geometry   any            `json:"geometry"`
+	Properties map[string]any `json:"properties"`
+}
+
+// WriteGeoJSON writes the rows of r to w as a GeoJSON FeatureCollection:
+// its first geometry or geography column is each feature's geometry and
+// the other columns are its properties, like PostGIS's
+// ST_AsGeoJSON(record). It returns the number of features.
+func WriteGeoJSON(w io.Writer, r *QueryResult) (int, error) {
+	geomCol := -1
+	for i, t := range r.ColumnTypes {
+		if IsGeometryType(t) {
+			geomCol = i
+			break
+		}
+	}
+	if geomCol < 0 {
+		return 0, fmt.Errorf("the result has no geometry or geography column")
+	}
+
+	features := make([]geoJSONFeature, 0, len(r.Rows))
+	for n, row := range r.Rows {
+		f := geoJSONFeature{Type: "Feature", Properties: map[string]any{}}
+		for i, cell := range row {
+			if i >= len(r.Columns) {
+				break
+			}
+			if i != geomCol {
+				colType := ""
+				if i < len(r.ColumnTypes) {
+					colType = r.ColumnTypes[i]
+				}
+				f.Properties[r.Columns[i]] = geoJSONProperty(colType, cell)
+				continue
+			}
+			if cell == nullValue {
+				continue
+			}
+			raw, err := hex.DecodeString(cell)
+			if err != nil {
+				return 0, fmt.Errorf("row %d: %s is not a WKB geometry", n+1, r.Columns[i])
+			}
+			if f.Geometry, err = (&wkbReader{buf: raw}).readGeoJSON(); err != nil {
+				return 0, fmt.Errorf("row %d: %w", n+1, err)
+			}
+		}
+		features = append(features, f)
+	}
+
+	doc, err := json.Marshal(struct {
+		Type     string           `json:"type"`
+		Features []geoJSONFeature `json:"features"`
+	}{"FeatureCollection", features})
+	if err != nil {
+		return 0, err
+	}
+	if _, err := w.Write(doc); err != nil {
+		return 0, err
+	}
+	return len(features), nil
+}
+
+// geoJSONProperty is a cell of a column of type colType as a property
+// value: numbers and booleans keep their type, NULL is null and the rest
+// is text.
+func geoJSONProperty(colType, cell string) any {
+	if cell == nullValue {
+		return nil
+	}
+	switch colType {
+	case "int2", "int4", "int8", "oid", "float4", "float8", "numeric", "bool":
+		// NaN and Infinity aren't JSON; they stay strings.
+		if json.Valid([]byte(cell)) {
+			return json.RawMessage(cell)
+		}
+	}
+	return cell
+}
+
+// readGeoJSON reads one (possibly nested) geometry as a GeoJSON geometry
+// object. M coordinates are dropped, as GeoJSON has none.
+func (r *wkbReader) readGeoJSON() (map[string]any, error) {
+	typ, hasZ, _, _, err := r.readHeader()
+	if err != nil {
+		return nil, err
+	}
+	name, ok := geoJSONTypes[typ]
+	if !ok {
+		return nil, fmt.Errorf("wkb: unknown geometry type %d", typ)
+	}
+	geom := map[string]any{"type": name}
+
+	switch typ {
+	case 1:
+		coords, err := r.readCoords(1, hasZ)
+		if err != nil {
+			return nil, err
+		}
+		// An empty point is encoded as NaN coordinates
+		if math.IsNaN(coords[0][0]) || math.IsNaN(coords[0][1]) {
+			geom["coordinates"] = []float64{}
+		} else {
+			geom["coordinates"] = coords[0]
+		}
+	case 2:
+		n, err := r.uint32()
+		if err != nil {
+			return nil, err
+		}
+		if geom["coordinates"], err = r.readCoords(int(n), hasZ); err != nil {
+			return nil, err
+		}
+	case 3:
+		n, err := r.uint32()
+		if err != nil {
+			return nil, err
+		}
+		rings := make([][][]float64, 0, min(n, 1024))
+		for i := uint32(0); i < n; i++ {
+			points, err := r.uint32()
+			if err != nil {
+				return nil, err
+			}
+			ring, err := r.readCoords(int(points), hasZ)
+			if err != nil {
+				return nil, err
+			}
+			rings = append(rings, ring)
+		}
+		geom["coordinates"] = rings
+	default: // multi* and collections contain full geometries
+		n, err := r.uint32()
+		if err != nil {
+			return nil, err
+		}
+		parts := make([]any, 0, min(n, 1024))
+		for i := uint32(0); i < n; i++ {
+			part, err := r.readGeoJSON()
+			if err != nil {
+				return nil, err
+			}
+			if typ == 7 {
+				parts = append(parts, part)
+			} else {
+				parts = append(parts, part["coordinates"])
+			}
+		}
+		if typ == 7 {
+			geom["geometries"] = parts
+		} else {
+			geom["coordinates"] = parts
+		}
+	}
+	return geom, nil
+}
+
+// readCoords reads n positions as [x, y] or, with hasZ, [x, y, z].
+func (r *wkbReader) readCoords(n int, hasZ bool) ([][]float64, error) {
+	if n < 0 || n > (len(r.buf)-r.pos)/(8*r.dims) {
+		return nil, fmt.Errorf("wkb: unexpected end of data")
+	}
+	coords := make([][]float64, n)
+	for i := range coords {
+		values := make([]float64, r.dims)
+		for d := range values {
+			v, err := r.float64()
+			if err != nil {
+				return nil, err
+			}
+			values[d] = v
+		}
+		if hasZ {
+			coords[i] = values[:3]
+		} else {
+			coords[i] = values[:2]
+		}
+	}
+	return coords, nil
+}
@@ -0,0 +1,126 @@
+// hypertable.go adds TimescaleDB hypertables to the table list and
+// describe: a hypertable's rows live in chunks, so its own reltuples and
+// size are (nearly) zero, and its partitioning, chunks and background
+// policies are what matter when looking at it.
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	pgx "github.com/jackc/pgx/v5"
+)
+
+// HypertableDetails describes a hypertable, for describe.
+type HypertableDetails struct {
+	Dimensions *QueryResult // partitioning columns and chunk intervals
+	Chunks     *QueryResult // one row: chunk count, compressed chunks, time range, size
+	Policies   *QueryResult // retention, compression, ... jobs
+}
+
+// timescaleSchema returns the schema TimescaleDB is installed in, or ""
+// when it isn't installed.
+func (d *DB) timescaleSchema(ctx context.Context) (string, error) {
+	var schema string
+	err := d.Pool.QueryRow(ctx, `
+		SELECT n.nspname
+		FROM pg_extension e
+		JOIN pg_namespace n ON n.oid = e.extnamespace
+		WHERE e.extname = 'timescaledb'`).Scan(&schema)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
+	}
+	return schema, err
+}
+
+// markHypertables sets the type of the hypertables among tables to
+// "hypertable" and their row count to TimescaleDB's estimate across the
+// chunks. It does nothing without TimescaleDB.
+func (d *DB) markHypertables(ctx context.Context, tables []TableInfo) error {
+	ext, err := d.timescaleSchema(ctx)
+	if err != nil || ext == "" {
+		return err
+	}
+	rows, err := d.Pool.Query(ctx, fmt.Sprintf(`
+		SELECT hypertable_schema, hypertable_name,
+		       GREATEST(%s(format('%%I.%%I', hypertable_schema, hypertable_name)::regclass), 0)
+		FROM timescaledb_information.hypertables`,
+		pgx.Identifier{ext, "approximate_row_count"}.Sanitize()))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	counts := map[[2]string]int64{}
+	for rows.Next() {
+		var schema, name string
+		var count int64
+		if err := rows.Scan(&schema, &name, &count); err != nil {
+			return err
+		}
+		counts[[2]string{schema, name}] = count
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for i, t := range tables {
+		if count, ok := counts[[2]string{t.Schema, t.Name}]; ok {
+			tables[i].Type, tables[i].RowCount = "hypertable", count
+		}
+	}
+	return nil
+}
+
+// DescribeHypertable returns the details of schema.table, or nil when it
+// is not a hypertable (or TimescaleDB isn't installed).
+func (d *DB) DescribeHypertable(ctx context.Context, schema, table string) (*HypertableDetails, error) {
+	if schema == "" {
+		schema = d.defaultSchema()
+	}
+	ext, err := d.timescaleSchema(ctx)
+	if err != nil || ext == "" {
+		return nil, err
+	}
+	var exists bool
+	if err := d.Pool.QueryRow(ctx, `
+		SELECT EXISTS (
+		  SELECT 1 FROM timescaledb_information.hypertables
+		  WHERE hypertable_schema = $1 AND hypertable_name = $2
+		)`, schema, table).Scan(&exists); err != nil || !exists {
+		return nil, err
+	}
+
+	h := &HypertableDetails{}
+	h.Dimensions, err = d.executeQuery(ctx, `
+		SELECT column_name, dimension_type,
+		       COALESCE(time_interval::text, integer_interval::text,
+		                num_partitions::text || ' partitions') AS chunk_interval
+		FROM timescaledb_information.dimensions
+		WHERE hypertable_schema = $1 AND hypertable_name = $2
+		ORDER BY dimension_number`, schema, table)
+	if err != nil {
+		return nil, err
+	}
+	h.Chunks, err = d.executeQuery(ctx, fmt.Sprintf(`
+		SELECT count(*) AS chunks,
+		       count(*) FILTER (WHERE is_compressed) AS compressed,
+		       COALESCE(min(range_start)::text, min(range_start_integer)::text) AS oldest,
+		       COALESCE(max(range_end)::text, max(range_end_integer)::text) AS newest,
+		       pg_size_pretty(%s(format('%%I.%%I', $1::text, $2::text)::regclass)) AS size
+		FROM timescaledb_information.chunks
+		WHERE hypertable_schema = $1 AND hypertable_name = $2`,
+		pgx.Identifier{ext, "hypertable_size"}.Sanitize()), schema, table)
+	if err != nil {
+		return nil, err
+	}
+	h.Policies, err = d.executeQuery(ctx, `
+		SELECT job_id, proc_name AS policy, schedule_interval::text AS every,
+		       config::text, scheduled, next_start::text
+		FROM timescaledb_information.jobs
+		WHERE hypertable_schema = $1 AND hypertable_name = $2
+		ORDER BY job_id`, schema, table)
+	if err != nil {
+		return nil, err
+	}
+	return h, nil
+}
@@ -0,0 +1,227 @@
+// index_create.go builds CREATE INDEX statements from an IndexSpec and
+// estimates the size of the index before it is built.
+//
+// The statement runs as a maintenance plan (see maintenance.go), so it
+// reports progress from pg_stat_progress_create_index like REINDEX.
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	pgx "github.com/jackc/pgx/v5"
+)
+
+// IndexMethods are the access methods offered for new indexes.
+var IndexMethods = []string{"btree", "hash", "gin", "gist", "brin"}
+
+// maxIdentifierLength is PostgreSQL's NAMEDATALEN - 1.
+const maxIdentifierLength = 63
+
+// IndexColumn is one key column of an index.
+type IndexColumn struct {
+	Name    string
+	Desc    bool
+	Opclass string // e.g. "text_pattern_ops"; empty for the default
+}
+
+// IndexSpec describes an index to create.
+type IndexSpec struct {
+	Schema       string // empty resolves Table through the search path
+	Table        string
+	Name         string
+	Method       string // one of IndexMethods; empty means btree
+	Columns      []IndexColumn
+	Include      []string // non-key columns stored in the index (btree, gist)
+	Where        string   // partial index predicate
+	Unique       bool
+	Concurrently bool
+}
+
+func (s IndexSpec) table() string {
+	if s.Schema != "" {
+		return pgx.Identifier{s.Schema, s.Table}.Sanitize()
+	}
+	return pgx.Identifier{s.Table}.Sanitize()
+}
+
+// DefaultIndexName names an index after its table and key columns the
+// way PostgreSQL does: orders_customer_id_created_at_idx.
+func DefaultIndexName(table string, columns []IndexColumn) string {
+	parts := []string{table}
+	for _, c := range columns {
+		parts = append(parts, c.Name)
+	}
+	suffix := "_idx"
+	if len(columns) == 0 {
+		suffix = "idx"
+	}
+	name := strings.Join(parts, "_")
+	if len(name)+len(suffix) > maxIdentifierLength {
+		name = name[:maxIdentifierLength-len(suffix)]
+	}
+	return name + suffix
+}
+
+// SQL returns the CREATE INDEX statement.
+func (s IndexSpec) SQL() string {
+	var sb strings.Builder
+	sb.WriteString("CREATE ")
+	if s.Unique {
+		sb.WriteString("UNIQUE ")
+	}
+	sb.WriteString("INDEX ")
+	if s.Concurrently {
+		sb.WriteString("CONCURRENTLY ")
+	}
+	if s.Name != "" {
+		sb.WriteString(pgx.Identifier{s.Name}.Sanitize() + " ")
+	}
+	sb.WriteString("ON " + s.table())
+	if s.Method != "" && s.Method != "btree" {
+		sb.WriteString(" USING " + s.Method)
+	}
+
+	var keys []string
+	for _, c := range s.Columns {
+		key := pgx.Identifier{c.Name}.Sanitize()
+		if c.Opclass != "" {
+			key += " " + c.Opclass
+		}
+		if c.Desc {
+			key += " DESC"
+		}
+		keys = append(keys, key)
+	}
+	sb.WriteString(" (" + strings.Join(keys, ", ") + ")")
+
+	if len(s.Include) > 0 {
+		var cols []string
+		for _, c := range s.Include {
+			cols = append(cols, pgx.Identifier{c}.Sanitize())
+		}
+		sb.WriteString(" INCLUDE (" + strings.Join(cols, ", ") + ")")
+	}
+	if s.Where != "" {
+		sb.WriteString(" WHERE " + s.Where)
+	}
+	return sb.String()
+}
+
+// Warnings describes the locking and failure behaviour of the statement.
+func (s IndexSpec) Warnings() []string {
+	var w []string
+	if s.Concurrently {
+		w = append(w,
+			"CONCURRENTLY: writes continue, but the build scans the table twice and waits for running transactions.",
+			"If it fails, an INVALID index is left behind; drop it before retrying.")
+	} else {
+		w = append(w, "Blocks INSERT, UPDATE and DELETE on the table until the build finishes (reads continue).")
+	}
+	if s.Unique {
+		w = append(w, "UNIQUE: fails if existing rows contain duplicates.")
+	}
+	if len(s.Include) > 0 && s.Method != "" && s.Method != "btree" && s.Method != "gist" {
+		w = append(w, "INCLUDE is only supported by btree and gist indexes.")
+	}
+	return w
+}
+
+// Plan wraps the statement as a maintenance plan so it runs with
+// progress reporting. est may be nil.
+func (s IndexSpec) Plan(est *IndexEstimate) *MaintenancePlan {
+	plan := &MaintenancePlan{Action: createIndexAction, Table: s.table(), SQL: s.SQL()}
+	if est != nil {
+		plan.Size, plan.Rows = est.Size, est.Rows
+	}
+	return plan
+}
+
+var createIndexAction = MaintenanceAction{
+	Name:          "CREATE INDEX",
+	Description:   "Build a new index",
+	progressQuery: `SELECT phase, blocks_done, blocks_total FROM pg_stat_progress_create_index WHERE pid = $1`,
+}
+
+// IndexEstimate predicts the size of an index from planner statistics.
+type IndexEstimate struct {
+	Rows         int64    // rows the index will cover
+	Size         string   // estimated size, pretty-printed
+	MissingStats []string // columns without pg_stats (never analyzed)
+}
+
+// EstimateIndex estimates a btree built from spec: covered rows (the
+// planner's estimate for the WHERE predicate, if any) times the average
+// width of the indexed columns plus per-tuple overhead, at the default
+// 90% fill factor. Other access methods are usually smaller.
+func (d *DB) EstimateIndex(ctx context.Context, spec IndexSpec) (*IndexEstimate, error) {
+	est := &IndexEstimate{}
+
+	if spec.Where != "" {
+		plan, err := d.Explain(ctx, fmt.Sprintf("SELECT 1 FROM %s WHERE %s", spec.table(), spec.Where), false)
+		if err != nil {
+			return nil, fmt.Errorf("WHERE predicate: %w", err)
+		}
+		var root []struct {
+			Plan planNode `json:"Plan"`
+		}
+		if err := json.Unmarshal([]byte(plan.JSON), &root); err != nil {
+			return nil, err
+		}
+		if len(root) > 0 {
+			est.Rows = int64(root[0].Plan.PlanRows)
+		}
+	} else if err := d.Pool.QueryRow(ctx,
+		"SELECT GREATEST(reltuples, 0)::bigint FROM pg_class WHERE oid = to_regclass($1)",
+		spec.table()).Scan(&est.Rows); err != nil {
+		return nil, err
+	}
+
+	cols := make([]string, 0, len(spec.Columns)+len(spec.Include))
+	for _, c := range spec.Columns {
+		cols = append(cols, c.Name)
+	}
+	cols = append(cols, spec.Include...)
+
+	rows, err := d.Pool.Query(ctx, `
+		SELECT a.attname,
+		       (SELECT s.avg_width FROM pg_stats s
+		        WHERE s.schemaname = n.nspname AND s.tablename = c.relname AND s.attname = a.attname
+		        ORDER BY s.inherited LIMIT 1)
+		FROM pg_attribute a
+		JOIN pg_class c ON c.oid = a.attrelid
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE a.attrelid = to_regclass($1) AND a.attname = ANY($2::text[])`, spec.table(), cols)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	width := int64(0)
+	for rows.Next() {
+		var name string
+		var avg *int32
+		if err := rows.Scan(&name, &avg); err != nil {
+			return nil, err
+		}
+		if avg == nil {
+			est.MissingStats = append(est.MissingStats, name)
+			width += 8 // guess: a typical fixed-width column
+			continue
+		}
+		width += int64(*avg)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Index tuple header (8) plus line pointer (4), MAXALIGNed to 8 bytes.
+	tuple := (width + 12 + 7) / 8 * 8
+	bytes := est.Rows * tuple * 10 / 9
+	if err := d.Pool.QueryRow(ctx, "SELECT pg_size_pretty($1::bigint)", bytes).Scan(&est.Size); err != nil {
+		return nil, err
+	}
+	return est, nil
+}
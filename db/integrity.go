@@ -0,0 +1,180 @@
+// integrity.go runs data-quality checks that constraints don't enforce:
+// orphaned rows behind implicit (*_id) foreign keys, duplicates in columns
+// that look unique, and stray NULLs in columns that are almost always set.
+package db
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Integrity issue kinds.
+const (
+	IssueOrphans    = "orphans"
+	IssueDuplicates = "duplicates"
+	IssueNulls      = "nulls"
+)
+
+// requiredNullRatio is the largest share of NULLs for which a nullable
+// column is still considered effectively required.
+const requiredNullRatio = 0.05
+
+// uniqueishColumn matches column names that usually hold unique values.
+var uniqueishColumn = regexp.MustCompile(`(^|_)(email|username|login|slug|sku|uuid|guid|handle|token|external_id)$`)
+
+// IntegrityIssue is one finding for a table column.
+type IntegrityIssue struct {
+	Kind   string // IssueOrphans, IssueDuplicates or IssueNulls
+	Column string
+	Count  int64  // orphaned rows, duplicated values, or NULL rows
+	Detail string // human-readable explanation
+}
+
+// TableIntegrity is the integrity report for one table.
+type TableIntegrity struct {
+	Table  string
+	Rows   int64
+	Issues []IntegrityIssue
+	Err    error // set if the table could not be checked
+}
+
+// CheckIntegrity checks every table in schema. Checks that fail (e.g. an
+// implicit FK whose column types don't match) are skipped silently; a
+// table that can't be read at all is reported with Err.
+func (d *DB) CheckIntegrity(ctx context.Context, schema string) ([]TableIntegrity, error) {
+	if schema == "" {
+		schema = d.defaultSchema()
+	}
+	tables, err := d.ListTables(ctx, schema)
+	if err != nil {
+		return nil, err
+	}
+
+	var report []TableIntegrity
+	for _, t := range tables {
+		report = append(report, d.checkTable(ctx, schema, t.Name))
+	}
+	return report, nil
+}
+
+func (d *DB) checkTable(ctx context.Context, schema, table string) TableIntegrity {
+	ti := TableIntegrity{Table: table}
+	ts, err := d.FetchTableSchema(ctx, schema, table)
+	if err != nil {
+		ti.Err = err
+		return ti
+	}
+	qtable := pgx.Identifier{schema, table}.Sanitize()
+
+	// One scan counts rows and non-NULL values of every nullable column
+	var nullable []ColumnInfo
+	exprs := []string{"count(*)"}
+	for _, col := range ts.Columns {
+		if col.IsNullable {
+			nullable = append(nullable, col)
+			exprs = append(exprs, fmt.Sprintf("count(%s)", ident(col.Name)))
+		}
+	}
+	counts := make([]int64, len(exprs))
+	dest := make([]any, len(exprs))
+	for i := range counts {
+		dest[i] = &counts[i]
+	}
+	if err := d.Pool.QueryRow(ctx, fmt.Sprintf("SELECT %s FROM %s", strings.Join(exprs, ", "), qtable)).Scan(dest...); err != nil {
+		ti.Err = err
+		return ti
+	}
+	ti.Rows = counts[0]
+
+	// Orphans: implicit FK values with no parent row
+	formal := make(map[string]bool)
+	for _, fk := range ts.ForeignKeys {
+		if fk.ConstraintName != "(implicit)" {
+			formal[fk.Column] = true
+		}
+	}
+	for _, fk := range d.detectImplicitFKs(ctx, schema, ts) {
+		if formal[fk.Column] {
+			continue
+		}
+		var n int64
+		sql := fmt.Sprintf(`SELECT count(*) FROM %s c WHERE c.%s IS NOT NULL
+			AND NOT EXISTS (SELECT 1 FROM %s p WHERE p.%s = c.%s)`,
+			qtable, ident(fk.Column), pgx.Identifier{schema, fk.ForeignTable}.Sanitize(),
+			ident(fk.ForeignColumn), ident(fk.Column))
+		if err := d.Pool.QueryRow(ctx, sql).Scan(&n); err != nil || n == 0 {
+			continue
+		}
+		ti.Issues = append(ti.Issues, IntegrityIssue{
+			Kind:   IssueOrphans,
+			Column: fk.Column,
+			Count:  n,
+			Detail: fmt.Sprintf("%d rows point to a missing %s.%s", n, fk.ForeignTable, fk.ForeignColumn),
+		})
+	}
+
+	// Duplicates in unique-looking columns that have no unique index
+	unique := d.uniqueColumns(ctx, qtable)
+	for _, col := range ts.Columns {
+		if col.IsPK || unique[col.Name] {
+			continue
+		}
+		if !uniqueishColumn.MatchString(strings.ToLower(col.Name)) && col.DataType != "uuid" {
+			continue
+		}
+		var n int64
+		sql := fmt.Sprintf(`SELECT count(*) FROM (
+			SELECT 1 FROM %s WHERE %s IS NOT NULL GROUP BY %s HAVING count(*) > 1) dup`,
+			qtable, ident(col.Name), ident(col.Name))
+		if err := d.Pool.QueryRow(ctx, sql).Scan(&n); err != nil || n == 0 {
+			continue
+		}
+		ti.Issues = append(ti.Issues, IntegrityIssue{
+			Kind:   IssueDuplicates,
+			Column: col.Name,
+			Count:  n,
+			Detail: fmt.Sprintf("%d values appear more than once (no unique constraint)", n),
+		})
+	}
+
+	// NULLs in columns that are set in (almost) every row
+	for i, col := range nullable {
+		nulls := ti.Rows - counts[i+1]
+		if nulls == 0 || float64(nulls) > float64(ti.Rows)*requiredNullRatio {
+			continue
+		}
+		ti.Issues = append(ti.Issues, IntegrityIssue{
+			Kind:   IssueNulls,
+			Column: col.Name,
+			Count:  nulls,
+			Detail: fmt.Sprintf("%d NULLs in %d rows (%.2f%%) — effectively required?",
+				nulls, ti.Rows, float64(nulls)*100/float64(ti.Rows)),
+		})
+	}
+	return ti
+}
+
+// uniqueColumns returns columns covered by a single-column unique index.
+func (d *DB) uniqueColumns(ctx context.Context, qtable string) map[string]bool {
+	out := make(map[string]bool)
+	rows, err := d.Pool.Query(ctx, `
+		SELECT a.attname
+		FROM pg_index i
+		JOIN pg_attribute a ON a.attrelid = i.indrelid AND a.attnum = i.indkey[0]
+		WHERE i.indrelid = $1::regclass AND i.indisunique AND i.indnatts = 1`, qtable)
+	if err != nil {
+		return out
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		if rows.Scan(&name) == nil {
+			out[name] = true
+		}
+	}
+	return out
+}
@@ -0,0 +1,127 @@
+// listen.go implements LISTEN/NOTIFY for the Listen view.
+//
+// LISTEN is per session, so a Listener takes a connection out of the pool
+// for itself. Channel names are used as typed, so "Orders" and "orders"
+// are different channels, as they are for pg_notify.
+package db
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Notification is a NOTIFY received by a Listener.
+type Notification struct {
+	Channel string
+	Payload string
+	PID     uint32 // backend that sent it
+	At      time.Time
+}
+
+// Listener is a connection that LISTENs on channels. Wait holds the
+// connection until a notification arrives, so statements run by Listen and
+// Unlisten wait for it: cancel the context of the Wait first.
+type Listener struct {
+	mu       sync.Mutex // held while the connection is in use
+	conn     *pgx.Conn
+	channels []string
+
+	ctx    context.Context // ended by Close, stopping a Wait
+	cancel context.CancelFunc
+}
+
+// NewListener takes a connection out of the pool for LISTEN. It is closed
+// by Close or when the DB is closed.
+func (d *DB) NewListener(ctx context.Context) (*Listener, error) {
+	c, err := d.Pool.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	l := &Listener{conn: c.Hijack()}
+	l.ctx, l.cancel = context.WithCancel(context.Background())
+
+	d.listenMu.Lock()
+	d.listeners = append(d.listeners, l)
+	d.listenMu.Unlock()
+	return l, nil
+}
+
+// Listen starts listening on channel.
+func (l *Listener) Listen(ctx context.Context, channel string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, err := l.conn.Exec(ctx, "LISTEN "+ident(channel)); err != nil {
+		return err
+	}
+	if !slices.Contains(l.channels, channel) {
+		l.channels = append(l.channels, channel)
+	}
+	return nil
+}
+
+// Unlisten stops listening on channel, or on every channel for "*".
+func (l *Listener) Unlisten(ctx context.Context, channel string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if channel == "*" {
+		if _, err := l.conn.Exec(ctx, "UNLISTEN *"); err != nil {
+			return err
+		}
+		l.channels = nil
+		return nil
+	}
+	i := slices.Index(l.channels, channel)
+	if i < 0 {
+		return fmt.Errorf("not listening on %s", channel)
+	}
+	if _, err := l.conn.Exec(ctx, "UNLISTEN "+ident(channel)); err != nil {
+		return err
+	}
+	l.channels = slices.Delete(l.channels, i, i+1)
+	return nil
+}
+
+// Wait blocks until a notification arrives, ctx ends or the listener is
+// closed. Notifications sent while nobody waits are queued and returned
+// by the next Wait.
+func (l *Listener) Wait(ctx context.Context) (*Notification, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.ctx.Err() != nil {
+		return nil, fmt.Errorf("listener closed")
+	}
+	ctx, stop := context.WithCancel(ctx)
+	defer stop()
+	go func() {
+		select {
+		case <-l.ctx.Done():
+			stop()
+		case <-ctx.Done():
+		}
+	}()
+
+	n, err := l.conn.WaitForNotification(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &Notification{Channel: n.Channel, Payload: n.Payload, PID: n.PID, At: time.Now()}, nil
+}
+
+// Close ends a Wait and closes the connection.
+func (l *Listener) Close() {
+	l.cancel()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.conn.Close(context.Background())
+}
+
+// Notify sends payload on channel with pg_notify.
+func (d *DB) Notify(ctx context.Context, channel, payload string) error {
+	_, err := d.Pool.Exec(ctx, "SELECT pg_notify($1, $2)", channel, payload)
+	return err
+}
@@ -0,0 +1,67 @@
+// locks.go reads who is blocking whom: the sessions waiting for a lock,
+// the lock they wait for, and the sessions holding them up
+// (pg_blocking_pids), so the blocking chains can be drawn as trees.
+package db
+
+import (
+	"context"
+	"time"
+)
+
+// LockSession is a session that waits for a lock or blocks one that does.
+type LockSession struct {
+	PID       int
+	User      string
+	App       string
+	State     string
+	XactAge   time.Duration // time since the transaction started, 0 outside one
+	QueryAge  time.Duration // time since the current or last query started
+	WaitsFor  string        // e.g. "RowExclusiveLock on relation orders", "" if not waiting
+	BlockedBy []int32
+	Query     string // whitespace collapsed, shortened
+}
+
+// FetchLockSessions returns the sessions waiting for a lock and those
+// blocking them, longest waiting first.
+func (d *DB) FetchLockSessions(ctx context.Context) ([]LockSession, error) {
+	rows, err := d.Pool.Query(ctx, `
+		WITH s AS (
+		  SELECT a.*, pg_blocking_pids(a.pid) AS blocked_by
+		  FROM pg_stat_activity a
+		  WHERE a.pid <> pg_backend_pid()
+		)
+		SELECT s.pid, COALESCE(s.usename, ''), COALESCE(s.application_name, ''), COALESCE(s.state, ''),
+		       COALESCE(extract(epoch FROM now() - s.xact_start), 0)::float8,
+		       COALESCE(extract(epoch FROM now() - s.query_start), 0)::float8,
+		       COALESCE(w.mode || ' on ' || w.locktype ||
+		                COALESCE(' ' || w.relation::regclass::text, ''), ''),
+		       s.blocked_by,
+		       left(regexp_replace(COALESCE(s.query, ''), '\s+', ' ', 'g'), 200)
+		FROM s
+		LEFT JOIN LATERAL (
+		  SELECT l.mode, l.locktype, l.relation
+		  FROM pg_locks l
+		  WHERE l.pid = s.pid AND NOT l.granted
+		  LIMIT 1
+		) w ON true
+		WHERE cardinality(s.blocked_by) > 0
+		   OR s.pid IN (SELECT unnest(blocked_by) FROM s)
+		ORDER BY s.query_start NULLS LAST`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var sessions []LockSession
+	for rows.Next() {
+		var s LockSession
+		var xactAge, queryAge float64
+		if err := rows.Scan(&s.PID, &s.User, &s.App, &s.State, &xactAge, &queryAge,
+			&s.WaitsFor, &s.BlockedBy, &s.Query); err != nil {
+			return nil, err
+		}
+		s.XactAge = time.Duration(xactAge * float64(time.Second))
+		s.QueryAge = time.Duration(queryAge * float64(time.Second))
+		sessions = append(sessions, s)
+	}
+	return sessions, rows.Err()
+}
@@ -0,0 +1,190 @@
+// maintenance.go plans and runs table maintenance commands (ANALYZE,
+// VACUUM, REINDEX, CLUSTER, TRUNCATE, DROP) and reads their progress.
+//
+// Each command runs on a dedicated pooled connection so its backend PID
+// is known; progress is then polled from the matching pg_stat_progress_*
+// view on another connection.
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	pgx "github.com/jackc/pgx/v5"
+)
+
+// MaintenanceAction is one entry of the table actions menu.
+type MaintenanceAction struct {
+	Name        string // e.g. "VACUUM"
+	Description string
+	Lock        string // what concurrent sessions can still do
+	Destructive bool   // loses data; requires typing the table name
+
+	// progressQuery selects (phase, done, total) for a backend PID from
+	// the action's pg_stat_progress_* view; empty if there is none.
+	progressQuery string
+}
+
+// MaintenanceActions lists the table actions, least disruptive first.
+var MaintenanceActions = []MaintenanceAction{
+	{
+		Name:          "ANALYZE",
+		Description:   "Refresh planner statistics",
+		Lock:          "SHARE UPDATE EXCLUSIVE — reads and writes continue",
+		progressQuery: `SELECT phase, sample_blks_scanned, sample_blks_total FROM pg_stat_progress_analyze WHERE pid = $1`,
+	},
+	{
+		Name:          "VACUUM",
+		Description:   "Reclaim dead rows and update the visibility map",
+		Lock:          "SHARE UPDATE EXCLUSIVE — reads and writes continue",
+		progressQuery: `SELECT phase, heap_blks_scanned, heap_blks_total FROM pg_stat_progress_vacuum WHERE pid = $1`,
+	},
+	{
+		Name:          "REINDEX CONCURRENTLY",
+		Description:   "Rebuild every index of the table without blocking writes",
+		Lock:          "SHARE UPDATE EXCLUSIVE — slower, needs room for a second copy of each index",
+		progressQuery: `SELECT phase, blocks_done, blocks_total FROM pg_stat_progress_create_index WHERE pid = $1`,
+	},
+	{
+		Name:          "CLUSTER",
+		Description:   "Rewrite the table in index order",
+		Lock:          "ACCESS EXCLUSIVE — blocks all reads and writes until done",
+		progressQuery: `SELECT phase, heap_blks_scanned, heap_blks_total FROM pg_stat_progress_cluster WHERE pid = $1`,
+	},
+	{
+		Name:        "TRUNCATE",
+		Description: "Delete every row",
+		Lock:        "ACCESS EXCLUSIVE — brief, but the rows are gone for good",
+		Destructive: true,
+	},
+	{
+		Name:        "DROP",
+		Description: "Drop the table with its indexes, constraints and triggers",
+		Lock:        "ACCESS EXCLUSIVE — fails if views or foreign keys depend on it",
+		Destructive: true,
+	},
+}
+
+// MaintenancePlan is a maintenance action resolved against one table.
+type MaintenancePlan struct {
+	Action MaintenanceAction
+	Table  string // schema-qualified, quoted
+	SQL    string
+	Size   string // total size, indexes included
+	Rows   int64  // estimated rows
+}
+
+// PlanMaintenance builds the statement for action on schema.table and
+// gathers the table's size. An empty schema resolves table through the
+// search path.
+func (d *DB) PlanMaintenance(ctx context.Context, action MaintenanceAction, schema, table string) (*MaintenancePlan, error) {
+	rel := pgx.Identifier{table}.Sanitize()
+	if schema != "" {
+		rel = pgx.Identifier{schema, table}.Sanitize()
+	}
+
+	plan := &MaintenancePlan{Action: action}
+	var nsp, name string
+	err := d.Pool.QueryRow(ctx, `
+		SELECT n.nspname, c.relname, pg_size_pretty(pg_total_relation_size(c.oid)),
+		       GREATEST(c.reltuples, 0)::bigint
+		FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE c.oid = to_regclass($1)`, rel).Scan(&nsp, &name, &plan.Size, &plan.Rows)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("table %s not found", rel)
+	}
+	if err != nil {
+		return nil, err
+	}
+	plan.Table = pgx.Identifier{nsp, name}.Sanitize()
+
+	switch action.Name {
+	case "REINDEX CONCURRENTLY":
+		plan.SQL = "REINDEX TABLE CONCURRENTLY " + plan.Table
+	case "CLUSTER":
+		// Use the index the table was last clustered on, else its primary key.
+		var index string
+		err := d.Pool.QueryRow(ctx, `
+			SELECT i.relname
+			FROM pg_index x
+			JOIN pg_class i ON i.oid = x.indexrelid
+			WHERE x.indrelid = to_regclass($1) AND (x.indisclustered OR x.indisprimary)
+			ORDER BY x.indisclustered DESC
+			LIMIT 1`, plan.Table).Scan(&index)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%s has no primary key or clustered index to CLUSTER on", plan.Table)
+		}
+		if err != nil {
+			return nil, err
+		}
+		plan.SQL = fmt.Sprintf("CLUSTER %s USING %s", plan.Table, pgx.Identifier{index}.Sanitize())
+	case "DROP":
+		plan.SQL = "DROP TABLE " + plan.Table
+	default:
+		plan.SQL = action.Name + " " + plan.Table
+	}
+	return plan, nil
+}
+
+// MaintenanceRun reports the backend running a maintenance statement.
+type MaintenanceRun struct {
+	pid atomic.Uint32
+}
+
+// PID returns the backend PID, or 0 before the statement has started.
+func (r *MaintenanceRun) PID() uint32 { return r.pid.Load() }
+
+// RunMaintenance executes plan on a dedicated connection, publishing its
+// backend PID through run. Cancelling ctx cancels the statement.
+func (d *DB) RunMaintenance(ctx context.Context, plan *MaintenancePlan, run *MaintenanceRun) (time.Duration, error) {
+	conn, err := d.Pool.Acquire(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Release()
+
+	run.pid.Store(conn.Conn().PgConn().PID())
+	start := time.Now()
+	_, err = conn.Exec(ctx, plan.SQL)
+	return time.Since(start), err
+}
+
+// MaintenanceProgress describes how far the statement on backend pid
+// has got, e.g. "scanning heap — 42% (1.2k/2.9k blocks)". Without a
+// progress row it reports what the backend is waiting on, if anything.
+func (d *DB) MaintenanceProgress(ctx context.Context, plan *MaintenancePlan, pid uint32) (string, error) {
+	if q := plan.Action.progressQuery; q != "" {
+		var phase string
+		var done, total int64
+		err := d.Pool.QueryRow(ctx, q, int64(pid)).Scan(&phase, &done, &total)
+		if err == nil {
+			if total > 0 {
+				return fmt.Sprintf("%s — %d%% (%s/%s blocks)",
+					phase, done*100/total, FormatRowCount(done), FormatRowCount(total)), nil
+			}
+			return phase, nil
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return "", err
+		}
+	}
+
+	var waitType, wait string
+	err := d.Pool.QueryRow(ctx, `
+		SELECT COALESCE(wait_event_type, ''), COALESCE(wait_event, '')
+		FROM pg_stat_activity WHERE pid = $1`, int64(pid)).Scan(&waitType, &wait)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "starting", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if waitType == "Lock" {
+		return "waiting for a " + wait + " lock held by another session", nil
+	}
+	return "running", nil
+}
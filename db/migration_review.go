@@ -0,0 +1,259 @@
+// migration_review.go gathers what a migration touches — the tables it
+// names, with their size, columns, indexes and foreign keys — as context
+// for the AI migration review (\review), and runs migration scripts (\i).
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	pgx "github.com/jackc/pgx/v5"
+)
+
+// migrationKeywords are followed by a table name in DDL and DML.
+var migrationKeywords = map[string]bool{
+	"FROM": true, "JOIN": true, "UPDATE": true, "INTO": true, "TABLE": true,
+	"ONLY": true, "ON": true, "REFERENCES": true, "TRUNCATE": true,
+}
+
+// MigrationTables returns the table names sql refers to, in order of
+// first appearance. Names are returned as written (possibly qualified);
+// words that aren't tables, e.g. after a join's ON, are filtered out by
+// MigrationContext.
+func MigrationTables(sql string) []string {
+	return tableRefs(tokenizeSQL(sql), migrationKeywords)
+}
+
+// listKeywords start a comma-separated list of relations, e.g. FROM a, b.
+var listKeywords = map[string]bool{"FROM": true, "USING": true, "TRUNCATE": true}
+
+// listEnders end a list of relations started by one of listKeywords at
+// the same parenthesis depth.
+var listEnders = map[string]bool{
+	"WHERE": true, "GROUP": true, "HAVING": true, "ORDER": true, "LIMIT": true,
+	"OFFSET": true, "FETCH": true, "WINDOW": true, "FOR": true, "UNION": true,
+	"INTERSECT": true, "EXCEPT": true, "RETURNING": true, "SET": true,
+	"VALUES": true, "SELECT": true, "RESTART": true, "CONTINUE": true,
+}
+
+// tableRefs returns the names following any of keywords, skipping IF
+// [NOT] EXISTS, ONLY and LATERAL, in order of first appearance. After
+// those of keywords that start a list (FROM a, b JOIN c ON ..., d), every
+// item of the list counts.
+func tableRefs(toks []sqlToken, keywords map[string]bool) []string {
+	var names []string
+	seen := map[string]bool{}
+	inList := []bool{false} // per parenthesis depth
+	for i := 0; i < len(toks); i++ {
+		tok := toks[i]
+		depth := len(inList) - 1
+		switch {
+		case tok.text == "(" && tok.kind == tokPunct:
+			inList = append(inList, false)
+			continue
+		case tok.text == ")" && tok.kind == tokPunct:
+			if depth > 0 {
+				inList = inList[:depth]
+			}
+			continue
+		case tok.text == ";" && tok.kind == tokPunct:
+			inList = []bool{false}
+			continue
+		case tok.text == "," && tok.kind == tokPunct:
+			if !inList[depth] {
+				continue
+			}
+		case tok.kind == tokWord:
+			upper := strings.ToUpper(tok.text)
+			if listEnders[upper] {
+				inList[depth] = false
+			}
+			if !keywords[upper] {
+				continue
+			}
+			if listKeywords[upper] {
+				inList[depth] = true
+			}
+			if upper == "ON" && !createsIndex(toks, i) {
+				continue // a join condition, ON CONFLICT, ...
+			}
+		default:
+			continue
+		}
+
+		if name, ok := relationAt(toks, i+1); ok && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// relationAt returns the possibly qualified name starting at toks[j],
+// after IF [NOT] EXISTS, ONLY or LATERAL, as written.
+func relationAt(toks []sqlToken, j int) (string, bool) {
+	for j < len(toks) && toks[j].kind == tokWord &&
+		(strings.EqualFold(toks[j].text, "IF") || strings.EqualFold(toks[j].text, "NOT") ||
+			strings.EqualFold(toks[j].text, "EXISTS") || strings.EqualFold(toks[j].text, "ONLY") ||
+			strings.EqualFold(toks[j].text, "LATERAL")) {
+		j++
+	}
+	var parts []string
+	for j < len(toks) && (toks[j].kind == tokWord || toks[j].kind == tokQuoted) {
+		parts = append(parts, toks[j].text)
+		if j+2 < len(toks) && toks[j+1].text == "." {
+			j += 2
+			continue
+		}
+		break
+	}
+	if len(parts) == 0 || keywordAt(toks, j) {
+		return "", false
+	}
+	return strings.Join(parts, "."), true
+}
+
+// createsIndex reports whether the statement holding toks[i] is a
+// CREATE INDEX, where ON is followed by the table.
+func createsIndex(toks []sqlToken, i int) bool {
+	for j := i - 1; j >= 0 && toks[j].text != ";"; j-- {
+		if toks[j].kind == tokWord && strings.EqualFold(toks[j].text, "INDEX") {
+			return true
+		}
+	}
+	return false
+}
+
+// MigrationContext describes the existing tables sql refers to, for the
+// AI review: estimated rows and size (how long a rewrite or scan takes),
+// columns, indexes and foreign keys. Tables not in the database yet are
+// listed as new.
+func (d *DB) MigrationContext(ctx context.Context, sql string) (string, error) {
+	var sb strings.Builder
+	var version string
+	if err := d.Pool.QueryRow(ctx, "SHOW server_version").Scan(&version); err != nil {
+		return "", err
+	}
+	sb.WriteString("PostgreSQL " + version + "\n")
+
+	var missing []string
+	for _, name := range MigrationTables(sql) {
+		var table string
+		var rows int64
+		var size string
+		err := d.Pool.QueryRow(ctx, `
+			SELECT c.oid::regclass::text, GREATEST(c.reltuples, 0)::bigint,
+			       pg_size_pretty(pg_total_relation_size(c.oid))
+			FROM pg_class c
+			WHERE c.oid = to_regclass($1) AND c.relkind IN ('r', 'p', 'm')`, name).Scan(&table, &rows, &size)
+		if err != nil {
+			missing = append(missing, name)
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("\n## %s (~%s rows, %s)\n", table, FormatRowCount(rows), size))
+
+		cols, err := d.migrationLines(ctx, `
+			SELECT a.attname || ' ' || format_type(a.atttypid, a.atttypmod)
+			       || CASE WHEN a.attnotnull THEN ' NOT NULL' ELSE '' END
+			       || COALESCE(' DEFAULT ' || pg_get_expr(ad.adbin, ad.adrelid), '')
+			FROM pg_attribute a
+			LEFT JOIN pg_attrdef ad ON ad.adrelid = a.attrelid AND ad.adnum = a.attnum
+			WHERE a.attrelid = to_regclass($1) AND a.attnum > 0 AND NOT a.attisdropped
+			ORDER BY a.attnum`, table)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString("Columns: " + strings.Join(cols, ", ") + "\n")
+
+		indexes, err := d.migrationLines(ctx, `
+			SELECT pg_get_indexdef(indexrelid) || CASE WHEN indisvalid THEN '' ELSE ' -- INVALID' END
+			FROM pg_index WHERE indrelid = to_regclass($1) ORDER BY indexrelid`, table)
+		if err != nil {
+			return "", err
+		}
+		writeMigrationList(&sb, "Indexes", indexes)
+
+		fks, err := d.migrationLines(ctx, `
+			SELECT conname || ': ' || pg_get_constraintdef(oid)
+			FROM pg_constraint WHERE conrelid = to_regclass($1) AND contype = 'f' ORDER BY conname`, table)
+		if err != nil {
+			return "", err
+		}
+		writeMigrationList(&sb, "Foreign keys", fks)
+	}
+	if len(missing) > 0 {
+		sb.WriteString("\nNot in the database (new, or not a table): " + strings.Join(missing, ", ") + "\n")
+	}
+	return sb.String(), nil
+}
+
+// migrationLines returns the single text column of query's rows.
+func (d *DB) migrationLines(ctx context.Context, query string, args ...any) ([]string, error) {
+	rows, err := d.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var lines []string
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return nil, err
+		}
+		lines = append(lines, line)
+	}
+	return lines, rows.Err()
+}
+
+func writeMigrationList(sb *strings.Builder, title string, items []string) {
+	if len(items) == 0 {
+		sb.WriteString(title + ": none\n")
+		return
+	}
+	sb.WriteString(title + ":\n")
+	for _, item := range items {
+		sb.WriteString("- " + item + "\n")
+	}
+}
+
+// ExecScriptTx runs sql, which may hold several statements, inside tx
+// with the simple query protocol. The status is the last command's tag.
+// A script with its own BEGIN, COMMIT or ROLLBACK is refused: it would end
+// tx behind its back.
+func (d *DB) ExecScriptTx(ctx context.Context, tx pgx.Tx, sql string) (*QueryResult, error) {
+	sql = strings.TrimSpace(sql)
+	if sql == "" {
+		return nil, fmt.Errorf("empty script")
+	}
+	if stmt := txControl(sql); stmt != "" {
+		return nil, fmt.Errorf("the script has its own %s: remove its transaction control, the open transaction holds it", stmt)
+	}
+	tag, err := tx.Exec(ctx, sql)
+	if err != nil {
+		return nil, err
+	}
+	return &QueryResult{Status: tag.String()}, nil
+}
+
+// txControl returns the first transaction control statement of sql, such
+// as COMMIT, or "" if there is none.
+func txControl(sql string) string {
+	start := true
+	for _, tok := range tokenizeSQL(sql) {
+		switch {
+		case tok.kind == tokComment || tok.kind == tokLineComment:
+			continue
+		case tok.kind == tokPunct && tok.text == ";":
+			start = true
+			continue
+		case start && tok.kind == tokWord:
+			switch word := strings.ToUpper(tok.text); word {
+			case "BEGIN", "START", "COMMIT", "END", "ROLLBACK", "ABORT":
+				return word
+			}
+		}
+		start = false
+	}
+	return ""
+}
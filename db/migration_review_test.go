@@ -0,0 +1,24 @@
+package db
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestMigrationTables(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want []string
+	}{
+		{`ALTER TABLE orders ADD COLUMN note text`, []string{"orders"}},
+		{`CREATE INDEX CONCURRENTLY idx ON public.orders (created_at)`, []string{"public.orders"}},
+		{`TRUNCATE orders, customers`, []string{"orders", "customers"}},
+		{`UPDATE orders SET a = 1, b = 2 FROM customers, regions WHERE true`, []string{"orders", "customers", "regions"}},
+		{`INSERT INTO archive SELECT a, b FROM orders`, []string{"archive", "orders"}},
+	}
+	for _, tt := range tests {
+		if got := MigrationTables(tt.sql); !slices.Equal(got, tt.want) {
+			t.Errorf("MigrationTables(%q) = %q, want %q", tt.sql, got, tt.want)
+		}
+	}
+}
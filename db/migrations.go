@@ -0,0 +1,309 @@
+// migrations.go reads the bookkeeping tables of common migration tools
+// (golang-migrate, Rails, Flyway, goose), compares them with migration
+// files on disk, and applies pending files one transaction at a time.
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Supported migration tools.
+const (
+	MigrationToolGolangMigrate = "golang-migrate"
+	MigrationToolRails         = "rails"
+	MigrationToolFlyway        = "flyway"
+	MigrationToolGoose         = "goose"
+)
+
+// AppliedMigration is one row of a migration tool's history table.
+type AppliedMigration struct {
+	Version     string
+	Description string
+	AppliedAt   string
+	Success     bool
+}
+
+// MigrationFile is a migration script found on disk.
+type MigrationFile struct {
+	Version string
+	Name    string
+	Path    string
+}
+
+// MigrationStatus describes the migration state of the database.
+type MigrationStatus struct {
+	Tool    string // one of the MigrationTool constants, "" if none found
+	Table   string // bookkeeping table name
+	Applied []AppliedMigration
+	Pending []MigrationFile
+	Dirty   bool // golang-migrate: last migration failed part way
+}
+
+// DetectMigrations finds the first known migration table on the search
+// path and loads its applied versions.
+func (d *DB) DetectMigrations(ctx context.Context) (*MigrationStatus, error) {
+	exists := func(table string) bool {
+		var ok bool
+		_ = d.Pool.QueryRow(ctx, "SELECT to_regclass($1) IS NOT NULL", table).Scan(&ok)
+		return ok
+	}
+
+	status := &MigrationStatus{}
+	switch {
+	case exists("schema_migrations"):
+		status.Table = "schema_migrations"
+		var hasDirty bool
+		_ = d.Pool.QueryRow(ctx, `SELECT EXISTS (
+			SELECT 1 FROM information_schema.columns
+			WHERE table_name = 'schema_migrations' AND column_name = 'dirty')`).Scan(&hasDirty)
+		if hasDirty {
+			status.Tool = MigrationToolGolangMigrate
+		} else {
+			status.Tool = MigrationToolRails
+		}
+	case exists("flyway_schema_history"):
+		status.Tool, status.Table = MigrationToolFlyway, "flyway_schema_history"
+	case exists("goose_db_version"):
+		status.Tool, status.Table = MigrationToolGoose, "goose_db_version"
+	default:
+		return status, nil
+	}
+
+	var err error
+	switch status.Tool {
+	case MigrationToolGolangMigrate:
+		// golang-migrate keeps a single row with the current version
+		var version int64
+		err = d.Pool.QueryRow(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &status.Dirty)
+		if err == nil {
+			status.Applied = []AppliedMigration{{
+				Version:     strconv.FormatInt(version, 10),
+				Description: "current version",
+				Success:     !status.Dirty,
+			}}
+		} else if errors.Is(err, pgx.ErrNoRows) {
+			err = nil
+		}
+	case MigrationToolRails:
+		status.Applied, err = d.appliedMigrations(ctx,
+			"SELECT version::text, '', '', true FROM schema_migrations ORDER BY version")
+	case MigrationToolFlyway:
+		status.Applied, err = d.appliedMigrations(ctx, `SELECT version, description, installed_on::text, success
+			FROM flyway_schema_history WHERE version IS NOT NULL ORDER BY installed_rank`)
+	case MigrationToolGoose:
+		status.Applied, err = d.appliedMigrations(ctx, `SELECT version_id::text, '', tstamp::text, true FROM (
+				SELECT DISTINCT ON (version_id) version_id, is_applied, tstamp
+				FROM goose_db_version WHERE version_id > 0
+				ORDER BY version_id, id DESC) v
+			WHERE is_applied ORDER BY version_id`)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", status.Table, err)
+	}
+	return status, nil
+}
+
+func (d *DB) appliedMigrations(ctx context.Context, sql string) ([]AppliedMigration, error) {
+	rows, err := d.Pool.Query(ctx, sql)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []AppliedMigration
+	for rows.Next() {
+		var m AppliedMigration
+		if err := rows.Scan(&m.Version, &m.Description, &m.AppliedAt, &m.Success); err != nil {
+			return nil, err
+		}
+		out = append(out, m)
+	}
+	return out, rows.Err()
+}
+
+var migrationFilePatterns = map[string]*regexp.Regexp{
+	MigrationToolGolangMigrate: regexp.MustCompile(`^(\d+)_(.+)\.up\.sql$`),
+	MigrationToolFlyway:        regexp.MustCompile(`^V([\d._]+)__(.+)\.sql$`),
+	MigrationToolGoose:         regexp.MustCompile(`^(\d+)_(.+)\.sql$`),
+}
+
+// ScanMigrationFiles lists the SQL migrations for tool in dir, sorted by
+// version. Rails migrations are Ruby and are not supported.
+func ScanMigrationFiles(dir, tool string) ([]MigrationFile, error) {
+	re, ok := migrationFilePatterns[tool]
+	if !ok {
+		return nil, fmt.Errorf("%s migrations are not SQL files", tool)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []MigrationFile
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		m := re.FindStringSubmatch(e.Name())
+		if m == nil {
+			continue
+		}
+		version := m[1]
+		if tool == MigrationToolFlyway {
+			version = strings.ReplaceAll(version, "_", ".")
+		}
+		files = append(files, MigrationFile{Version: version, Name: m[2], Path: filepath.Join(dir, e.Name())})
+	}
+	sort.Slice(files, func(i, j int) bool { return compareVersions(files[i].Version, files[j].Version) < 0 })
+	return files, nil
+}
+
+// SetPending fills s.Pending with the files that have not been applied.
+func (s *MigrationStatus) SetPending(files []MigrationFile) {
+	s.Pending = nil
+	if s.Tool == MigrationToolGolangMigrate {
+		current := ""
+		if len(s.Applied) > 0 {
+			current = s.Applied[0].Version
+		}
+		for _, f := range files {
+			if current == "" || compareVersions(f.Version, current) > 0 {
+				s.Pending = append(s.Pending, f)
+			}
+		}
+		return
+	}
+
+	applied := make(map[string]bool, len(s.Applied))
+	for _, m := range s.Applied {
+		if m.Success {
+			applied[normalizeVersion(m.Version)] = true
+		}
+	}
+	for _, f := range files {
+		if !applied[normalizeVersion(f.Version)] {
+			s.Pending = append(s.Pending, f)
+		}
+	}
+}
+
+// ApplyMigration runs one migration file and records it in the tool's
+// table, in a single transaction.
+func (d *DB) ApplyMigration(ctx context.Context, s *MigrationStatus, f MigrationFile) error {
+	if s.Tool == MigrationToolRails || s.Tool == "" {
+		return fmt.Errorf("applying %s migrations is not supported", s.Tool)
+	}
+	if s.Dirty {
+		return fmt.Errorf("database is dirty at version %s; fix it with golang-migrate first", s.Applied[0].Version)
+	}
+	data, err := os.ReadFile(f.Path)
+	if err != nil {
+		return err
+	}
+	sql := string(data)
+	if s.Tool == MigrationToolGoose {
+		sql = gooseUpSection(sql)
+	}
+
+	tx, err := d.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	start := time.Now()
+	if _, err := tx.Exec(ctx, sql); err != nil {
+		return fmt.Errorf("%s: %w", filepath.Base(f.Path), err)
+	}
+	elapsed := time.Since(start)
+
+	switch s.Tool {
+	case MigrationToolGolangMigrate:
+		version, _ := strconv.ParseInt(f.Version, 10, 64)
+		if _, err := tx.Exec(ctx, "DELETE FROM schema_migrations"); err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx, "INSERT INTO schema_migrations (version, dirty) VALUES ($1, false)", version)
+	case MigrationToolFlyway:
+		_, err = tx.Exec(ctx, `INSERT INTO flyway_schema_history
+			(installed_rank, version, description, type, script, checksum, installed_by, execution_time, success)
+			VALUES ((SELECT COALESCE(max(installed_rank), 0) + 1 FROM flyway_schema_history),
+			        $1, $2, 'SQL', $3, $4, current_user, $5, true)`,
+			f.Version, strings.ReplaceAll(f.Name, "_", " "), filepath.Base(f.Path),
+			flywayChecksum(sql), int(elapsed.Milliseconds()))
+	case MigrationToolGoose:
+		version, _ := strconv.ParseInt(f.Version, 10, 64)
+		_, err = tx.Exec(ctx, "INSERT INTO goose_db_version (version_id, is_applied) VALUES ($1, true)", version)
+	}
+	if err != nil {
+		return fmt.Errorf("record %s in %s: %w", f.Version, s.Table, err)
+	}
+	return tx.Commit(ctx)
+}
+
+// gooseUpSection returns the SQL between "-- +goose Up" and "-- +goose Down".
+func gooseUpSection(sql string) string {
+	if i := strings.Index(sql, "-- +goose Up"); i >= 0 {
+		sql = sql[i+len("-- +goose Up"):]
+	}
+	if i := strings.Index(sql, "-- +goose Down"); i >= 0 {
+		sql = sql[:i]
+	}
+	return sql
+}
+
+// flywayChecksum computes Flyway's CRC32 checksum: line contents without
+// line terminators, as a signed 32-bit integer.
+func flywayChecksum(sql string) int32 {
+	crc := crc32.NewIEEE()
+	sql = strings.TrimPrefix(sql, "\ufeff")
+	for _, line := range strings.Split(sql, "\n") {
+		crc.Write([]byte(strings.TrimSuffix(line, "\r")))
+	}
+	return int32(crc.Sum32())
+}
+
+// compareVersions compares dotted numeric versions ("1.10" > "1.9").
+func compareVersions(a, b string) int {
+	pa := strings.Split(normalizeVersion(a), ".")
+	pb := strings.Split(normalizeVersion(b), ".")
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		var x, y int64
+		if i < len(pa) {
+			x, _ = strconv.ParseInt(pa[i], 10, 64)
+		}
+		if i < len(pb) {
+			y, _ = strconv.ParseInt(pb[i], 10, 64)
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// normalizeVersion strips leading zeros from each version segment.
+func normalizeVersion(v string) string {
+	parts := strings.Split(strings.ReplaceAll(v, "_", "."), ".")
+	for i, p := range parts {
+		if n, err := strconv.ParseInt(p, 10, 64); err == nil {
+			parts[i] = strconv.FormatInt(n, 10)
+		}
+	}
+	return strings.Join(parts, ".")
+}
@@ -0,0 +1,52 @@
+package db
+
+import (
+	"sync"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// maxNotices caps the notice lines kept per connection between statements.
+const maxNotices = 100
+
+// noticeLog collects NOTICE and WARNING messages (RAISE NOTICE in a DO
+// block, "truncated to 63 characters", ...) per connection, so Execute
+// can attach the ones its statement raised to the result.
+type noticeLog struct {
+	mu      sync.Mutex
+	notices map[*pgconn.PgConn][]string
+}
+
+// add is the pool's OnNotice handler.
+func (l *noticeLog) add(conn *pgconn.PgConn, n *pgconn.Notice) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.notices == nil {
+		l.notices = make(map[*pgconn.PgConn][]string)
+	}
+	if len(l.notices[conn]) >= maxNotices {
+		return
+	}
+	l.notices[conn] = append(l.notices[conn], formatNotice(n)...)
+}
+
+// take returns and clears the notices of conn.
+func (l *noticeLog) take(conn *pgconn.PgConn) []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	notices := l.notices[conn]
+	delete(l.notices, conn)
+	return notices
+}
+
+// formatNotice renders a notice the way psql prints it.
+func formatNotice(n *pgconn.Notice) []string {
+	lines := []string{n.Severity + ":  " + n.Message}
+	if n.Detail != "" {
+		lines = append(lines, "DETAIL:  "+n.Detail)
+	}
+	if n.Hint != "" {
+		lines = append(lines, "HINT:  "+n.Hint)
+	}
+	return lines
+}
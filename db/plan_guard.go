@@ -0,0 +1,94 @@
+// plan_guard.go checks the SQL built from an AI query plan before it
+// runs. Plan fields such as filters are SQL fragments written by the
+// model, so a prompt injected through the question, a table comment or
+// a data value could smuggle in a second statement, a subquery on the
+// system catalogs or a table in another schema. PlanGuard refuses those.
+package db
+
+import (
+	"fmt"
+	"strings"
+)
+
+// planRelationKeywords are followed by a table name in generated plans.
+var planRelationKeywords = map[string]bool{
+	"FROM": true, "JOIN": true, "UPDATE": true, "INTO": true, "ONLY": true, "TABLE": true,
+	"USING": true,
+}
+
+// deniedFunctionPrefixes are server functions a plan never needs: file
+// and large object access, admin functions, dblink and settings.
+var deniedFunctionPrefixes = []string{"pg_", "lo_", "dblink", "set_config", "query_to_xml", "current_setting"}
+
+// PlanGuard holds what a plan may touch.
+type PlanGuard struct {
+	Tables  []string // table list names the plan was given as context
+	Schemas []string // schemas unqualified names resolve to
+}
+
+// Check returns an error describing the first thing in sql the guard
+// refuses, or nil.
+func (g PlanGuard) Check(sql string) error {
+	toks := tokenizeSQL(sql)
+	for i, tok := range toks {
+		switch tok.kind {
+		case tokComment, tokLineComment:
+			return fmt.Errorf("comments are not allowed in generated SQL")
+		case tokPunct:
+			if tok.text == ";" {
+				return fmt.Errorf("more than one statement")
+			}
+		case tokWord, tokQuoted:
+			name := strings.ToLower(unquoteIdent(tok.text))
+			if name == "pg_catalog" || name == "information_schema" || name == "pg_toast" {
+				return fmt.Errorf("system catalog %s is not allowed", name)
+			}
+			// A quoted name calls the same function when it is in lower
+			// case, and name is unquoted already.
+			if i+1 < len(toks) && toks[i+1].text == "(" {
+				for _, prefix := range deniedFunctionPrefixes {
+					if strings.HasPrefix(name, prefix) {
+						return fmt.Errorf("function %s is not allowed", name)
+					}
+				}
+			}
+		}
+	}
+
+	for _, ref := range tableRefs(toks, planRelationKeywords) {
+		if !g.allows(ref) {
+			return fmt.Errorf("table %s is not among the tables in the AI context", ref)
+		}
+	}
+	return nil
+}
+
+// allows reports whether ref, as written in SQL, names one of g.Tables.
+func (g PlanGuard) allows(ref string) bool {
+	parts := strings.Split(ref, ".")
+	for i := range parts {
+		parts[i] = unquoteIdent(parts[i])
+	}
+	name := strings.Join(parts, ".")
+	for _, t := range g.Tables {
+		if strings.EqualFold(t, name) {
+			return true
+		}
+		if len(parts) == 2 && strings.EqualFold(t, parts[1]) {
+			for _, s := range g.Schemas {
+				if strings.EqualFold(s, parts[0]) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// unquoteIdent strips the double quotes of a quoted identifier.
+func unquoteIdent(s string) string {
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		return strings.ReplaceAll(s[1:len(s)-1], `""`, `"`)
+	}
+	return s
+}
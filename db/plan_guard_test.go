@@ -0,0 +1,45 @@
+package db
+
+import "testing"
+
+func TestPlanGuardCheck(t *testing.T) {
+	g := PlanGuard{Tables: []string{"orders", "customers", "billing.invoice"}, Schemas: []string{"public"}}
+	tests := []struct {
+		sql     string
+		allowed bool
+	}{
+		{`SELECT * FROM orders WHERE total > 10`, true},
+		{`SELECT * FROM orders o JOIN customers c ON c.id = o.customer_id`, true},
+		{`SELECT * FROM public.orders, customers`, true},
+		{`SELECT * FROM billing.invoice`, true},
+		{`SELECT a, b FROM orders WHERE id IN (1, 2, 3) ORDER BY a, b`, true},
+
+		// Tables other than the first of a FROM list.
+		{`SELECT * FROM orders, pg_authid`, false},
+		{`SELECT * FROM orders, secrets`, false},
+		{`SELECT * FROM orders o, customers c, secrets s`, false},
+		{`SELECT * FROM orders o JOIN customers c ON c.id = o.customer_id, secrets`, false},
+		{`SELECT * FROM orders, LATERAL secrets`, false},
+		{`SELECT * FROM (SELECT * FROM orders) o, secrets`, false},
+		{`SELECT (SELECT count(*) FROM orders, secrets) FROM customers`, false},
+		{`DELETE FROM orders USING customers, secrets WHERE true`, false},
+
+		// Denied functions, also when quoted.
+		{`SELECT pg_read_file('/etc/passwd')`, false},
+		{`SELECT "pg_read_file"('/etc/passwd') FROM orders`, false},
+		{`SELECT "lo_import"('/etc/passwd')`, false},
+		{`SELECT * FROM orders WHERE "current_setting"('x') = ''`, false},
+
+		{`SELECT * FROM pg_catalog.pg_class`, false},
+		{`SELECT 1; DROP TABLE orders`, false},
+	}
+	for _, tt := range tests {
+		err := g.Check(tt.sql)
+		if tt.allowed && err != nil {
+			t.Errorf("Check(%q) = %v, want allowed", tt.sql, err)
+		}
+		if !tt.allowed && err == nil {
+			t.Errorf("Check(%q) allowed it", tt.sql)
+		}
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	pgx "github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 // TableInfo represents a database object (table, index, view).
@@ -24,23 +25,83 @@ type TableInfo struct {
 
 // QueryResult holds the output of an arbitrary SQL query.
 type QueryResult struct {
-	Columns  []string
-	Rows     [][]string
-	RowCount int
-	Status   string // e.g. "SELECT 5", "INSERT 0 1"
+	Columns     []string
+	ColumnTypes []string // pg_type names, parallel to Columns (e.g. "int4", "vector")
+	Rows        [][]string
+	RowCount    int
+	Status      string // e.g. "SELECT 5", "INSERT 0 1"
+
+	// Notices are the NOTICE and WARNING messages the statement raised,
+	// formatted like psql prints them. Only Execute collects them.
+	Notices []string
+}
+
+// Footprint estimates the memory held by the result in bytes: the cell
+// text plus Go's string and slice headers.
+func (r *QueryResult) Footprint() int {
+	const stringHeader, sliceHeader = 16, 24
+	n := sliceHeader * (len(r.Rows) + 2)
+	for _, col := range r.Columns {
+		n += stringHeader + len(col)
+	}
+	for _, row := range r.Rows {
+		for _, cell := range row {
+			n += stringHeader + len(cell)
+		}
+	}
+	return n
 }
 
 // ExplainResult holds a JSON explain plan.
 type ExplainResult struct {
-	JSON string
+	JSON string // FORMAT JSON output
+	Text string // FORMAT TEXT output; JSON is empty then
+}
+
+// ExplainOptions are the EXPLAIN options the Explain view can toggle.
+type ExplainOptions struct {
+	Analyze  bool // run the statement and report actual times and rows
+	Buffers  bool // shared/local/temp block usage
+	Settings bool // planner settings changed from their defaults
+	WAL      bool // WAL records generated; only with ANALYZE
+	Verbose  bool // output columns, schema-qualified names
+	Text     bool // FORMAT TEXT instead of FORMAT JSON
+}
+
+// String returns the parenthesised option list, e.g.
+// "(ANALYZE, BUFFERS, FORMAT JSON)". WAL is left out without ANALYZE,
+// which PostgreSQL requires for it.
+func (o ExplainOptions) String() string {
+	var opts []string
+	if o.Analyze {
+		opts = append(opts, "ANALYZE")
+	}
+	if o.Buffers {
+		opts = append(opts, "BUFFERS")
+	}
+	if o.Settings {
+		opts = append(opts, "SETTINGS")
+	}
+	if o.WAL && o.Analyze {
+		opts = append(opts, "WAL")
+	}
+	if o.Verbose {
+		opts = append(opts, "VERBOSE")
+	}
+	if o.Text {
+		opts = append(opts, "FORMAT TEXT")
+	} else {
+		opts = append(opts, "FORMAT JSON")
+	}
+	return "(" + strings.Join(opts, ", ") + ")"
 }
 
 // ListTables implements \dt — list tables in the current database.
-// Includes estimated row counts from pg_stat_user_tables.
+// Includes estimated row counts from pg_stat_user_tables. An empty schema
+// lists every schema on the search path, in search path order, leaving
+// out TimescaleDB's internal schemas (its chunks) should they be on it.
+// TimescaleDB hypertables are typed "hypertable".
 func (d *DB) ListTables(ctx context.Context, schema string) ([]TableInfo, error) {
-	if schema == "" {
-		schema = "public"
-	}
 	query := `
 		SELECT t.table_schema, t.table_name, 'table'::text AS type, '',
 		       GREATEST(COALESCE(c.reltuples, 0), 0)::bigint
@@ -48,9 +109,10 @@ func (d *DB) ListTables(ctx context.Context, schema string) ([]TableInfo, error)
 		LEFT JOIN pg_class c
 		  ON c.relname = t.table_name
 		  AND c.relnamespace = (SELECT oid FROM pg_namespace WHERE nspname = t.table_schema)
-		WHERE t.table_schema = $1 AND t.table_type = 'BASE TABLE'
-		ORDER BY t.table_name`
-	rows, err := d.Pool.Query(ctx, query, schema)
+		WHERE t.table_schema::text = ANY($1::text[]) AND t.table_type = 'BASE TABLE'
+		  AND ($2 OR t.table_schema::text NOT LIKE '\_timescaledb\_%')
+		ORDER BY array_position($1::text[], t.table_schema::text), t.table_name`
+	rows, err := d.Pool.Query(ctx, query, d.searchSchemas(schema), schema != "")
 	if err != nil {
 		return nil, err
 	}
@@ -64,7 +126,14 @@ func (d *DB) ListTables(ctx context.Context, schema string) ([]TableInfo, error)
 		}
 		results = append(results, t)
 	}
-	return results, rows.Err()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+	if err := d.markHypertables(ctx, results); err != nil {
+		return nil, fmt.Errorf("hypertables: %w", err)
+	}
+	return results, nil
 }
 
 // ListIndexes implements \di — list indexes.
@@ -123,14 +192,17 @@ func (d *DB) listObjects(ctx context.Context, schema, tableType, label string) (
 // DescribeTable implements \d <table> — show columns and constraints.
 func (d *DB) DescribeTable(ctx context.Context, schema, table string) (*QueryResult, error) {
 	if schema == "" {
-		schema = "public"
+		schema = d.defaultSchema()
 	}
 	query := `
 		SELECT c.column_name,
-		       c.data_type ||
-		         CASE WHEN c.character_maximum_length IS NOT NULL
-		              THEN '(' || c.character_maximum_length || ')'
-		              ELSE '' END AS data_type,
+		       CASE WHEN c.data_type = 'USER-DEFINED'
+		            THEN format_type(a.atttypid, a.atttypmod)
+		            ELSE c.data_type ||
+		              CASE WHEN c.character_maximum_length IS NOT NULL
+		                   THEN '(' || c.character_maximum_length || ')'
+		                   ELSE '' END
+		       END AS data_type,
 		       c.is_nullable,
 		       COALESCE(c.column_default, ''),
 		       CASE WHEN pk.column_name IS NOT NULL THEN 'PK' ELSE '' END AS key
@@ -145,6 +217,9 @@ func (d *DB) DescribeTable(ctx context.Context, schema, table string) (*QueryRes
 		    AND tc.table_name = $2
 		    AND tc.constraint_type = 'PRIMARY KEY'
 		) pk ON pk.column_name = c.column_name
+		LEFT JOIN pg_attribute a
+		  ON a.attrelid = format('%I.%I', c.table_schema, c.table_name)::regclass
+		  AND a.attname = c.column_name
 		WHERE c.table_schema = $1 AND c.table_name = $2
 		ORDER BY c.ordinal_position`
 	return d.executeQuery(ctx, query, schema, table)
@@ -153,7 +228,7 @@ func (d *DB) DescribeTable(ctx context.Context, schema, table string) (*QueryRes
 // TableIndexes returns indexes for a table.
 func (d *DB) TableIndexes(ctx context.Context, schema, table string) (*QueryResult, error) {
 	if schema == "" {
-		schema = "public"
+		schema = d.defaultSchema()
 	}
 	query := `
 		SELECT indexname, indexdef
@@ -163,51 +238,127 @@ func (d *DB) TableIndexes(ctx context.Context, schema, table string) (*QueryResu
 	return d.executeQuery(ctx, query, schema, table)
 }
 
-// TableForeignKeys returns FK constraints where this table references other tables.
+// TableForeignKeys returns FK constraints where this table references other
+// tables, one row per column pair like TableReferencedBy.
 func (d *DB) TableForeignKeys(ctx context.Context, schema, table string) (*QueryResult, error) {
 	if schema == "" {
-		schema = "public"
+		schema = d.defaultSchema()
 	}
 	query := `
-		SELECT tc.constraint_name,
+		SELECT kcu.constraint_name,
 		       kcu.column_name,
-		       ccu.table_name AS foreign_table,
-		       ccu.column_name AS foreign_column
-		FROM information_schema.table_constraints tc
+		       ukcu.table_name AS foreign_table,
+		       ukcu.column_name AS foreign_column,
+		       ukcu.table_schema AS foreign_schema
+		FROM information_schema.referential_constraints rc
 		JOIN information_schema.key_column_usage kcu
-		  ON tc.constraint_name = kcu.constraint_name
-		  AND tc.table_schema = kcu.table_schema
-		JOIN information_schema.constraint_column_usage ccu
-		  ON tc.constraint_name = ccu.constraint_name
-		  AND tc.table_schema = ccu.table_schema
-		WHERE tc.table_schema = $1
-		  AND tc.table_name = $2
-		  AND tc.constraint_type = 'FOREIGN KEY'
-		ORDER BY tc.constraint_name`
+		  ON kcu.constraint_schema = rc.constraint_schema
+		  AND kcu.constraint_name = rc.constraint_name
+		JOIN information_schema.key_column_usage ukcu
+		  ON ukcu.constraint_schema = rc.unique_constraint_schema
+		  AND ukcu.constraint_name = rc.unique_constraint_name
+		  AND ukcu.ordinal_position = kcu.position_in_unique_constraint
+		WHERE kcu.table_schema = $1
+		  AND kcu.table_name = $2
+		ORDER BY kcu.constraint_name, kcu.ordinal_position`
 	return d.executeQuery(ctx, query, schema, table)
 }
 
-// TableReferencedBy returns FK constraints from other tables referencing this table.
+// TableReferencedBy returns FK constraints from other tables (in the same
+// schema) referencing this table, one row per column pair: a composite key
+// lists its columns in key order, each with the column it references.
 func (d *DB) TableReferencedBy(ctx context.Context, schema, table string) (*QueryResult, error) {
 	if schema == "" {
-		schema = "public"
+		schema = d.defaultSchema()
 	}
 	query := `
-		SELECT tc.table_name AS referencing_table,
+		SELECT kcu.table_name AS referencing_table,
 		       kcu.column_name AS referencing_column,
-		       tc.constraint_name,
-		       ccu.column_name AS referenced_column
-		FROM information_schema.table_constraints tc
+		       kcu.constraint_name,
+		       ukcu.column_name AS referenced_column
+		FROM information_schema.referential_constraints rc
 		JOIN information_schema.key_column_usage kcu
-		  ON tc.constraint_name = kcu.constraint_name
-		  AND tc.table_schema = kcu.table_schema
-		JOIN information_schema.constraint_column_usage ccu
-		  ON tc.constraint_name = ccu.constraint_name
-		  AND tc.table_schema = ccu.table_schema
-		WHERE tc.table_schema = $1
-		  AND ccu.table_name = $2
-		  AND tc.constraint_type = 'FOREIGN KEY'
-		ORDER BY tc.table_name`
+		  ON kcu.constraint_schema = rc.constraint_schema
+		  AND kcu.constraint_name = rc.constraint_name
+		JOIN information_schema.key_column_usage ukcu
+		  ON ukcu.constraint_schema = rc.unique_constraint_schema
+		  AND ukcu.constraint_name = rc.unique_constraint_name
+		  AND ukcu.ordinal_position = kcu.position_in_unique_constraint
+		WHERE kcu.table_schema = $1
+		  AND ukcu.table_schema = $1
+		  AND ukcu.table_name = $2
+		ORDER BY kcu.table_name, kcu.constraint_name, kcu.ordinal_position`
+	return d.executeQuery(ctx, query, schema, table)
+}
+
+// TableConstraints returns CHECK, UNIQUE and EXCLUDE constraints on a table.
+// Primary and foreign keys are covered by DescribeTable and TableForeignKeys.
+func (d *DB) TableConstraints(ctx context.Context, schema, table string) (*QueryResult, error) {
+	if schema == "" {
+		schema = d.defaultSchema()
+	}
+	query := `
+		SELECT con.conname,
+		       CASE con.contype WHEN 'c' THEN 'CHECK'
+		                        WHEN 'u' THEN 'UNIQUE'
+		                        WHEN 'x' THEN 'EXCLUDE' END AS type,
+		       pg_get_constraintdef(con.oid) AS definition
+		FROM pg_constraint con
+		WHERE con.conrelid = format('%I.%I', $1::text, $2::text)::regclass
+		  AND con.contype IN ('c', 'u', 'x')
+		ORDER BY con.contype, con.conname`
+	return d.executeQuery(ctx, query, schema, table)
+}
+
+// TableComments returns the table comment (as "(table)") followed by
+// column comments, skipping columns without one.
+func (d *DB) TableComments(ctx context.Context, schema, table string) (*QueryResult, error) {
+	if schema == "" {
+		schema = d.defaultSchema()
+	}
+	query := `
+		WITH t AS (SELECT format('%I.%I', $1::text, $2::text)::regclass AS oid)
+		SELECT '(table)' AS object, obj_description(t.oid, 'pg_class') AS comment
+		FROM t
+		WHERE obj_description(t.oid, 'pg_class') IS NOT NULL
+		UNION ALL
+		SELECT a.attname::text, col_description(t.oid, a.attnum)
+		FROM t
+		JOIN pg_attribute a ON a.attrelid = t.oid
+		WHERE a.attnum > 0 AND NOT a.attisdropped
+		  AND col_description(t.oid, a.attnum) IS NOT NULL`
+	return d.executeQuery(ctx, query, schema, table)
+}
+
+// TableStatistics returns operational statistics for a table as
+// (statistic, value) rows: live/dead tuples, scan counts, vacuum and
+// analyze times, TOAST size, and fill factor.
+func (d *DB) TableStatistics(ctx context.Context, schema, table string) (*QueryResult, error) {
+	if schema == "" {
+		schema = d.defaultSchema()
+	}
+	query := `
+		SELECT v.statistic, v.value
+		FROM pg_class c
+		LEFT JOIN pg_stat_user_tables s ON s.relid = c.oid
+		CROSS JOIN LATERAL (VALUES
+		  ('live tuples', COALESCE(s.n_live_tup::text, 'n/a')),
+		  ('dead tuples', COALESCE(s.n_dead_tup::text ||
+		      CASE WHEN s.n_live_tup + s.n_dead_tup > 0
+		           THEN ' (' || round(100.0 * s.n_dead_tup / (s.n_live_tup + s.n_dead_tup), 1) || '%)'
+		           ELSE '' END, 'n/a')),
+		  ('sequential scans', COALESCE(s.seq_scan || ' (' || s.seq_tup_read || ' rows read)', 'n/a')),
+		  ('index scans', COALESCE(s.idx_scan || ' (' || s.idx_tup_fetch || ' rows fetched)', 'n/a')),
+		  ('last vacuum', COALESCE(to_char(s.last_vacuum, 'YYYY-MM-DD HH24:MI:SS'), 'never')),
+		  ('last autovacuum', COALESCE(to_char(s.last_autovacuum, 'YYYY-MM-DD HH24:MI:SS'), 'never')),
+		  ('last analyze', COALESCE(to_char(s.last_analyze, 'YYYY-MM-DD HH24:MI:SS'), 'never')),
+		  ('last autoanalyze', COALESCE(to_char(s.last_autoanalyze, 'YYYY-MM-DD HH24:MI:SS'), 'never')),
+		  ('TOAST size', CASE WHEN c.reltoastrelid = 0 THEN 'none'
+		                      ELSE pg_size_pretty(pg_total_relation_size(c.reltoastrelid)) END),
+		  ('fill factor', COALESCE((SELECT option_value FROM pg_options_to_table(c.reloptions)
+		                            WHERE option_name = 'fillfactor'), '100 (default)'))
+		) v(statistic, value)
+		WHERE c.oid = format('%I.%I', $1::text, $2::text)::regclass`
 	return d.executeQuery(ctx, query, schema, table)
 }
 
@@ -217,16 +368,64 @@ func (d *DB) Execute(ctx context.Context, sql string) (*QueryResult, error) {
 	if sql == "" {
 		return nil, fmt.Errorf("empty query")
 	}
-	return d.executeQuery(ctx, sql)
+	conn, err := d.Pool.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Release()
+	pg := conn.Conn().PgConn()
+	d.notices.take(pg) // raised by earlier statements on this connection
+	result, err := d.queryResult(ctx, conn, sql)
+	if result != nil {
+		result.Notices = d.notices.take(pg)
+	}
+	return result, err
+}
+
+// ExecuteTx runs sql like Execute, inside the open transaction tx.
+func (d *DB) ExecuteTx(ctx context.Context, tx pgx.Tx, sql string) (*QueryResult, error) {
+	sql = strings.TrimSpace(sql)
+	if sql == "" {
+		return nil, fmt.Errorf("empty query")
+	}
+	pg := tx.Conn().PgConn()
+	d.notices.take(pg)
+	result, err := d.queryResult(ctx, tx, sql)
+	if result != nil {
+		result.Notices = d.notices.take(pg)
+	}
+	return result, err
 }
 
 // Explain runs EXPLAIN (ANALYZE, FORMAT JSON) on a query.
 func (d *DB) Explain(ctx context.Context, sql string, analyze bool) (*ExplainResult, error) {
-	prefix := "EXPLAIN (FORMAT JSON)"
-	if analyze {
-		prefix = "EXPLAIN (ANALYZE, FORMAT JSON)"
+	return d.ExplainWith(ctx, sql, ExplainOptions{Analyze: analyze})
+}
+
+// ExplainWith runs EXPLAIN with opts on a query.
+func (d *DB) ExplainWith(ctx context.Context, sql string, opts ExplainOptions) (*ExplainResult, error) {
+	explainSQL := "EXPLAIN " + opts.String() + " " + sql
+
+	if opts.Text {
+		// FORMAT TEXT returns one row per line of the plan.
+		rows, err := d.Pool.Query(ctx, explainSQL)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+		var lines []string
+		for rows.Next() {
+			var line string
+			if err := rows.Scan(&line); err != nil {
+				return nil, err
+			}
+			lines = append(lines, line)
+		}
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+		return &ExplainResult{Text: strings.Join(lines, "\n")}, nil
 	}
-	explainSQL := prefix + " " + sql
 
 	var jsonPlan string
 	err := d.Pool.QueryRow(ctx, explainSQL).Scan(&jsonPlan)
@@ -236,48 +435,163 @@ func (d *DB) Explain(ctx context.Context, sql string, analyze bool) (*ExplainRes
 	return &ExplainResult{JSON: jsonPlan}, nil
 }
 
+// querier runs a query on the pool or on one of its connections.
+type querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
 // executeQuery is the internal workhorse for running SQL and collecting results.
 func (d *DB) executeQuery(ctx context.Context, sql string, args ...any) (*QueryResult, error) {
-	rows, err := d.Pool.Query(ctx, sql, args...)
+	return d.queryResult(ctx, d.Pool, sql, args...)
+}
+
+// queryResult runs sql on q and collects its result.
+func (d *DB) queryResult(ctx context.Context, q querier, sql string, args ...any) (*QueryResult, error) {
+	result := &QueryResult{}
+	columns, columnTypes, cmdTag, err := d.queryRows(ctx, q, sql, args, func(values []any) {
+		row := make([]string, len(values))
+		for i, v := range values {
+			row[i] = FormatValue(v)
+		}
+		result.Rows = append(result.Rows, row)
+		result.RowCount++
+	})
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
+	result.Columns, result.ColumnTypes = columns, columnTypes
 
-	result := &QueryResult{}
+	// Use the command tag for non-SELECT queries (e.g., "DELETE 1", "UPDATE 3", "BEGIN")
+	if len(result.Columns) == 0 && cmdTag != "" {
+		result.Status = cmdTag
+	} else {
+		result.Status = fmt.Sprintf("(%d row%s)", result.RowCount, plural(result.RowCount))
+	}
+	return result, nil
+}
+
+// ValueResult is a statement's result with the values as pgx decodes
+// them: nil for NULL, time.Time, numbers, maps and slices for json and
+// arrays, and so on.
+type ValueResult struct {
+	Columns     []string
+	ColumnTypes []string // pg_type names, parallel to Columns
+	Rows        [][]any
+	Status      string // as in QueryResult
+}
+
+// QueryValues runs sql like Execute but keeps the decoded values, for
+// output that keeps their types, such as JSON.
+func (d *DB) QueryValues(ctx context.Context, sql string) (*ValueResult, error) {
+	sql = strings.TrimSpace(sql)
+	if sql == "" {
+		return nil, fmt.Errorf("empty query")
+	}
+	result := &ValueResult{}
+	columns, columnTypes, cmdTag, err := d.queryRows(ctx, d.Pool, sql, nil, func(values []any) {
+		result.Rows = append(result.Rows, values)
+	})
+	if err != nil {
+		return nil, err
+	}
+	result.Columns, result.ColumnTypes = columns, columnTypes
+	if len(result.Columns) == 0 && cmdTag != "" {
+		result.Status = cmdTag
+	} else {
+		result.Status = fmt.Sprintf("(%d row%s)", len(result.Rows), plural(len(result.Rows)))
+	}
+	return result, nil
+}
+
+// queryRows runs sql on q, hands each row's values to row, and returns
+// the column names and types and the command tag.
+func (d *DB) queryRows(ctx context.Context, q querier, sql string, args []any, row func(values []any)) (columns, columnTypes []string, cmdTag string, err error) {
+	rows, err := q.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	defer rows.Close()
 
 	// Extract column names
+	var oids []uint32
 	for _, fd := range rows.FieldDescriptions() {
-		result.Columns = append(result.Columns, fd.Name)
+		columns = append(columns, fd.Name)
+		oids = append(oids, fd.DataTypeOID)
 	}
 
 	// Collect rows
 	for rows.Next() {
 		values, err := rows.Values()
 		if err != nil {
-			return nil, err
+			return nil, nil, "", err
 		}
-		row := make([]string, len(values))
-		for i, v := range values {
-			row[i] = fmt.Sprintf("%v", v)
-		}
-		result.Rows = append(result.Rows, row)
-		result.RowCount++
+		row(values)
 	}
 	if err := rows.Err(); err != nil {
-		return nil, err
+		return nil, nil, "", err
 	}
 
-	// Use the command tag for non-SELECT queries (e.g., "DELETE 1", "UPDATE 3", "BEGIN")
-	cmdTag := rows.CommandTag().String()
-	if len(result.Columns) == 0 && cmdTag != "" {
-		result.Status = cmdTag
-	} else {
-		result.Status = fmt.Sprintf("(%d row%s)", result.RowCount, plural(result.RowCount))
+	cmdTag = rows.CommandTag().String()
+	rows.Close()
+	return columns, d.resolveTypeNames(ctx, oids), cmdTag, nil
+}
+
+// resolveTypeNames maps column type OIDs to pg_type names. Built-in types
+// come from pgx's type map; unknown OIDs (extension types) are looked up
+// in pg_type once and cached. Unresolvable OIDs map to "".
+func (d *DB) resolveTypeNames(ctx context.Context, oids []uint32) []string {
+	names := make([]string, len(oids))
+	if len(oids) == 0 {
+		return names
 	}
-	return result, nil
+
+	d.typeMu.Lock()
+	if d.typeNames == nil {
+		d.typeNames = make(map[uint32]string)
+	}
+	var missing []uint32
+	for i, oid := range oids {
+		if name, ok := d.typeNames[oid]; ok {
+			names[i] = name
+		} else if t, ok := pgTypeMap.TypeForOID(oid); ok {
+			d.typeNames[oid] = t.Name
+			names[i] = t.Name
+		} else {
+			missing = append(missing, oid)
+		}
+	}
+	d.typeMu.Unlock()
+
+	if len(missing) == 0 || d.Pool == nil {
+		return names
+	}
+
+	rows, err := d.Pool.Query(ctx, "SELECT oid::int8, typname FROM pg_type WHERE oid = ANY($1)", missing)
+	if err != nil {
+		return names
+	}
+	defer rows.Close()
+
+	d.typeMu.Lock()
+	defer d.typeMu.Unlock()
+	for rows.Next() {
+		var oid int64
+		var name string
+		if rows.Scan(&oid, &name) == nil {
+			d.typeNames[uint32(oid)] = name
+		}
+	}
+	for i, oid := range oids {
+		if names[i] == "" {
+			names[i] = d.typeNames[oid]
+		}
+	}
+	return names
 }
 
+// pgTypeMap resolves built-in type OIDs without a round trip.
+var pgTypeMap = pgtype.NewMap()
+
 // Transaction helpers — thin wrappers so the TUI can manage transactions.
 
 func (d *DB) Begin(ctx context.Context) (pgx.Tx, error) {
@@ -291,6 +605,49 @@ func plural(n int) string {
 	return "s"
 }
 
+// SelectList renders columns as the quoted select list of a SELECT, or
+// "*" when there are none.
+func SelectList(columns []string) string {
+	if len(columns) == 0 {
+		return "*"
+	}
+	quoted := make([]string, len(columns))
+	for i, c := range columns {
+		quoted[i] = pgx.Identifier{c}.Sanitize()
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// OrderByList renders a sort order such as "created_at desc, id" as the
+// list of an ORDER BY, with quoted column names. Each item is a column
+// name, as it is stored, optionally followed by ASC or DESC and NULLS
+// FIRST or NULLS LAST.
+func OrderByList(sort string) (string, error) {
+	var items []string
+	for _, item := range strings.Split(sort, ",") {
+		fields := strings.Fields(item)
+		if len(fields) == 0 {
+			return "", fmt.Errorf("empty item in sort order %q", sort)
+		}
+		out := pgx.Identifier{fields[0]}.Sanitize()
+		rest := fields[1:]
+		if len(rest) > 0 && (strings.EqualFold(rest[0], "asc") || strings.EqualFold(rest[0], "desc")) {
+			out += " " + strings.ToUpper(rest[0])
+			rest = rest[1:]
+		}
+		if len(rest) == 2 && strings.EqualFold(rest[0], "nulls") &&
+			(strings.EqualFold(rest[1], "first") || strings.EqualFold(rest[1], "last")) {
+			out += " NULLS " + strings.ToUpper(rest[1])
+			rest = nil
+		}
+		if len(rest) > 0 {
+			return "", fmt.Errorf("invalid sort item %q (use: column [asc|desc] [nulls first|last])", strings.TrimSpace(item))
+		}
+		items = append(items, out)
+	}
+	return strings.Join(items, ", "), nil
+}
+
 // FormatRowCount formats a row count for compact display:
 //   - under 1000: exact number (e.g. "42", "999")
 //   - 1000..999499: Xk (e.g. "1k", "999k")
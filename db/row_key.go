@@ -0,0 +1,199 @@
+// row_key.go finds how to address a single row of a table, for features
+// that update or delete the rows shown in the results, and builds their
+// statements (and the INSERT of a new row).
+//
+// The primary key is used when there is one, with all of its columns.
+// Otherwise a unique index whose key columns are all NOT NULL serves the
+// same purpose. A table with neither falls back to ctid, the row's
+// physical location: it is unique, but it changes whenever the row is
+// updated and when VACUUM FULL or CLUSTER rewrites the table, so a ctid
+// read earlier may no longer point at the row that was shown.
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	pgx "github.com/jackc/pgx/v5"
+)
+
+// Row key kinds.
+const (
+	RowKeyPrimary = "primary key"
+	RowKeyUnique  = "unique index"
+	RowKeyCtid    = "ctid"
+)
+
+// RowKey is how rows of a table are identified.
+type RowKey struct {
+	Table   string   // schema-qualified, quoted
+	Kind    string   // RowKeyPrimary, RowKeyUnique or RowKeyCtid
+	Index   string   // the primary key or unique index, "" for ctid
+	Columns []string // key columns in key order; nil for ctid
+}
+
+// TableRowKey returns the row key of schema.table. An empty schema
+// resolves table through the search path. Partitioned tables without a
+// usable key are an error: ctid is only unique within one partition.
+func (d *DB) TableRowKey(ctx context.Context, schema, table string) (*RowKey, error) {
+	rel := pgx.Identifier{table}.Sanitize()
+	if schema != "" {
+		rel = pgx.Identifier{schema, table}.Sanitize()
+	}
+
+	var nsp, name, kind string
+	err := d.Pool.QueryRow(ctx, `
+		SELECT n.nspname, c.relname, c.relkind
+		FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE c.oid = to_regclass($1)`, rel).Scan(&nsp, &name, &kind)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("table %s not found", rel)
+	}
+	if err != nil {
+		return nil, err
+	}
+	key := &RowKey{Table: pgx.Identifier{nsp, name}.Sanitize()}
+	if kind != "r" && kind != "p" {
+		return nil, fmt.Errorf("%s is not a table", key.Table)
+	}
+
+	// The primary key, else the unique index with the fewest columns.
+	// Partial and expression indexes don't identify every row.
+	var primary bool
+	err = d.Pool.QueryRow(ctx, `
+		SELECT i.indisprimary, ic.relname, array_agg(a.attname::text ORDER BY k.n)
+		FROM pg_index i
+		JOIN pg_class ic ON ic.oid = i.indexrelid
+		CROSS JOIN LATERAL unnest(i.indkey) WITH ORDINALITY AS k(attnum, n)
+		JOIN pg_attribute a ON a.attrelid = i.indrelid AND a.attnum = k.attnum
+		WHERE i.indrelid = $1::regclass
+		  AND i.indisunique AND i.indisvalid
+		  AND i.indpred IS NULL AND i.indexprs IS NULL
+		  AND k.n <= i.indnkeyatts
+		GROUP BY i.indexrelid, i.indisprimary, ic.relname
+		HAVING bool_and(a.attnotnull)
+		ORDER BY i.indisprimary DESC, count(*), ic.relname
+		LIMIT 1`, key.Table).Scan(&primary, &key.Index, &key.Columns)
+	switch {
+	case err == nil:
+		key.Kind = RowKeyUnique
+		if primary {
+			key.Kind = RowKeyPrimary
+		}
+		return key, nil
+	case !errors.Is(err, pgx.ErrNoRows):
+		return nil, err
+	case kind == "p":
+		return nil, fmt.Errorf("%s has no primary key or NOT NULL unique index, and ctid is not unique across partitions", key.Table)
+	}
+	key.Kind = RowKeyCtid
+	return key, nil
+}
+
+// PrimaryKey returns the primary key columns of a table (a quoted,
+// optionally qualified name) in key order, or nil when it has none.
+func (d *DB) PrimaryKey(ctx context.Context, table string) ([]string, error) {
+	rows, err := d.Pool.Query(ctx, `
+		SELECT a.attname
+		FROM pg_index i
+		CROSS JOIN LATERAL unnest(i.indkey) WITH ORDINALITY AS k(attnum, n)
+		JOIN pg_attribute a ON a.attrelid = i.indrelid AND a.attnum = k.attnum
+		WHERE i.indrelid = to_regclass($1) AND i.indisprimary
+		  AND k.n <= i.indnkeyatts
+		ORDER BY k.n`, table)
+	if err != nil {
+		return nil, err
+	}
+	return scanNames(rows)
+}
+
+// KeyColumns returns what a SELECT must fetch to identify its rows: the
+// key columns, or ctid.
+func (k *RowKey) KeyColumns() []string {
+	if k.Kind == RowKeyCtid {
+		return []string{"ctid"}
+	}
+	return k.Columns
+}
+
+// Warning explains the risk of the key, or returns "" when there is none.
+func (k *RowKey) Warning() string {
+	if k.Kind != RowKeyCtid {
+		return ""
+	}
+	return k.Table + " has no primary key or NOT NULL unique index: rows are matched by ctid, " +
+		"which changes when a row is updated or the table is rewritten; reload the rows before editing them again."
+}
+
+// Where returns the condition matching one row, from the SQL literals of
+// its KeyColumns values (see ValueLiteral) in the same order. Every key
+// column is used, so a composite key matches exactly one row.
+func (k *RowKey) Where(literals []string) (string, error) {
+	cols := k.KeyColumns()
+	if len(literals) != len(cols) {
+		return "", fmt.Errorf("%s: %d key values for %d key columns", k.Table, len(literals), len(cols))
+	}
+	if k.Kind == RowKeyCtid {
+		if literals[0] == "" {
+			return "", fmt.Errorf("%s: the row has no ctid", k.Table)
+		}
+		return "ctid = " + literals[0] + "::tid", nil
+	}
+	conds := make([]string, len(cols))
+	for i, col := range cols {
+		if literals[i] == "" {
+			return "", fmt.Errorf("%s: key column %s is NULL", k.Table, col)
+		}
+		conds[i] = pgx.Identifier{col}.Sanitize() + " = " + literals[i]
+	}
+	return strings.Join(conds, " AND "), nil
+}
+
+// UpdateCell returns the UPDATE setting column to value in the row whose
+// KeyColumns values are literals (see Where). A nil value sets NULL; any
+// other is sent as an untyped literal, so the column's type reads it the
+// way it reads typed input.
+func (k *RowKey) UpdateCell(column string, value *string, literals []string) (string, error) {
+	where, err := k.Where(literals)
+	if err != nil {
+		return "", err
+	}
+	set := "NULL"
+	if value != nil {
+		set = quoteLiteral(*value)
+	}
+	return fmt.Sprintf("UPDATE %s SET %s = %s WHERE %s", k.Table, pgx.Identifier{column}.Sanitize(), set, where), nil
+}
+
+// DeleteRow returns the DELETE of the row whose KeyColumns values are
+// literals (see Where).
+func (k *RowKey) DeleteRow(literals []string) (string, error) {
+	where, err := k.Where(literals)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("DELETE FROM %s WHERE %s", k.Table, where), nil
+}
+
+// InsertRow returns the INSERT of one row into table (schema-qualified,
+// quoted) setting columns to values. Like UpdateCell, a nil value is NULL
+// and any other an untyped literal; columns left out get their defaults.
+// Without columns the row is all defaults.
+func InsertRow(table string, columns []string, values []*string) string {
+	if len(columns) == 0 {
+		return "INSERT INTO " + table + " DEFAULT VALUES"
+	}
+	names := make([]string, len(columns))
+	literals := make([]string, len(columns))
+	for i, column := range columns {
+		names[i] = pgx.Identifier{column}.Sanitize()
+		literals[i] = "NULL"
+		if values[i] != nil {
+			literals[i] = quoteLiteral(*values[i])
+		}
+	}
+	return fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", table, strings.Join(names, ", "), strings.Join(literals, ", "))
+}
@@ -0,0 +1,206 @@
+// sanity.go looks for red flags right after connecting: settings that
+// risk data loss or starve memory, sessions holding transactions open, and
+// standbys falling behind. Each finding has a one-line title for the
+// connection banner and a detail explaining it.
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	pgx "github.com/jackc/pgx/v5"
+)
+
+// Thresholds of the sanity checks.
+const (
+	sanityManyConnections = 500             // max_connections considered huge...
+	sanityLowWorkMemKB    = 4 * 1024        // ...with work_mem at or below this
+	sanityIdleInTx        = 5 * time.Minute // idle in transaction for longer
+	sanityReplicationLag  = 60 * time.Second
+	sanityReplicationWAL  = 1 << 30 // bytes of WAL not yet replayed by a standby
+)
+
+// SanityWarning is a red flag found by SanityCheck.
+type SanityWarning struct {
+	Title  string // one line
+	Detail string // what it means and what to look at, may span lines
+}
+
+// SanityCheck runs the checks and returns their warnings. A check that
+// fails (e.g. for lack of privileges) is skipped and its error joined
+// into the returned error, alongside the warnings of the others.
+func (d *DB) SanityCheck(ctx context.Context) ([]SanityWarning, error) {
+	var warnings []SanityWarning
+	var errs []error
+	for _, check := range []func(context.Context) ([]SanityWarning, error){
+		d.checkDurability, d.checkConnectionMemory, d.checkIdleInTransaction, d.checkReplicationLag,
+	} {
+		w, err := check(ctx)
+		if err != nil {
+			if errors.Is(err, context.Canceled) {
+				return nil, err
+			}
+			errs = append(errs, err)
+		}
+		warnings = append(warnings, w...)
+	}
+	return warnings, errors.Join(errs...)
+}
+
+// checkDurability flags fsync and full_page_writes being off.
+func (d *DB) checkDurability(ctx context.Context) ([]SanityWarning, error) {
+	var fsync, fullPageWrites string
+	if err := d.Pool.QueryRow(ctx,
+		"SELECT current_setting('fsync'), current_setting('full_page_writes')").Scan(&fsync, &fullPageWrites); err != nil {
+		return nil, fmt.Errorf("durability settings: %w", err)
+	}
+	var warnings []SanityWarning
+	if fsync == "off" {
+		warnings = append(warnings, SanityWarning{
+			Title: "fsync is off: a crash can corrupt the database",
+			Detail: "With fsync off PostgreSQL doesn't wait for writes to reach disk. An OS crash or\n" +
+				"power loss can leave the data files inconsistent, beyond what WAL replay repairs.\n" +
+				"Only acceptable for throwaway data (e.g. a test instance); restore from backup otherwise.",
+		})
+	}
+	if fullPageWrites == "off" {
+		warnings = append(warnings, SanityWarning{
+			Title: "full_page_writes is off: torn pages after a crash",
+			Detail: "Without full page images in WAL, a page partially written during a crash can't be\n" +
+				"repaired by recovery. Only safe on storage that guarantees atomic page writes.",
+		})
+	}
+	return warnings, nil
+}
+
+// checkConnectionMemory flags a huge max_connections with a low work_mem,
+// which usually means memory was split thin to make room for connections.
+func (d *DB) checkConnectionMemory(ctx context.Context) ([]SanityWarning, error) {
+	var maxConns, workMemKB int64
+	if err := d.Pool.QueryRow(ctx, `
+		SELECT current_setting('max_connections')::bigint,
+		       (SELECT setting::bigint FROM pg_settings WHERE name = 'work_mem')`).Scan(&maxConns, &workMemKB); err != nil {
+		return nil, fmt.Errorf("connection settings: %w", err)
+	}
+	if maxConns < sanityManyConnections || workMemKB > sanityLowWorkMemKB {
+		return nil, nil
+	}
+	return []SanityWarning{{
+		Title: fmt.Sprintf("max_connections is %d with work_mem at %d kB", maxConns, workMemKB),
+		Detail: "Each connection is a process with its own memory, so a high max_connections forces a\n" +
+			"low work_mem: sorts and hashes then spill to temporary files. A connection pooler\n" +
+			"(e.g. PgBouncer) in front of a few dozen connections usually serves the same load with\n" +
+			"more work_mem per query.",
+	}}, nil
+}
+
+// checkIdleInTransaction flags sessions idle inside a transaction for a
+// while: they hold locks and keep VACUUM from removing dead rows.
+func (d *DB) checkIdleInTransaction(ctx context.Context) ([]SanityWarning, error) {
+	rows, err := d.Pool.Query(ctx, `
+		SELECT pid, COALESCE(usename, ''), COALESCE(application_name, ''),
+		       date_trunc('second', now() - state_change)::text,
+		       left(regexp_replace(query, '\s+', ' ', 'g'), 80)
+		FROM pg_stat_activity
+		WHERE state LIKE 'idle in transaction%'
+		  AND now() - state_change > make_interval(secs => $1)
+		ORDER BY state_change`, sanityIdleInTx.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("idle transactions: %w", err)
+	}
+	defer rows.Close()
+	var sessions []string
+	for rows.Next() {
+		var pid int
+		var user, app, idle, query string
+		if err := rows.Scan(&pid, &user, &app, &idle, &query); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, fmt.Sprintf("  pid %d  %s  %s  idle %s  last: %s", pid, user, app, idle, query))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	n := len(sessions)
+	if n == 0 {
+		return nil, nil
+	}
+	if n > 5 {
+		sessions = append(sessions[:5], fmt.Sprintf("  … and %d more", n-5))
+	}
+	return []SanityWarning{{
+		Title: fmt.Sprintf("%d sessions idle in transaction for over %.0f minutes", n, sanityIdleInTx.Minutes()),
+		Detail: "They hold their locks and keep VACUUM from removing dead rows until they end.\n" +
+			"pg_terminate_backend(pid) ends one; idle_in_transaction_session_timeout prevents them.\n" +
+			strings.Join(sessions, "\n"),
+	}}, nil
+}
+
+// checkReplicationLag flags standbys behind this primary, or this standby
+// behind its primary.
+func (d *DB) checkReplicationLag(ctx context.Context) ([]SanityWarning, error) {
+	var inRecovery bool
+	if err := d.Pool.QueryRow(ctx, "SELECT pg_is_in_recovery()").Scan(&inRecovery); err != nil {
+		return nil, fmt.Errorf("replication: %w", err)
+	}
+	if inRecovery {
+		// Only lagging if there is WAL received but not replayed: an idle
+		// primary sends nothing to replay.
+		var lag *float64
+		if err := d.Pool.QueryRow(ctx, `
+			SELECT extract(epoch FROM now() - pg_last_xact_replay_timestamp())
+			WHERE pg_last_wal_receive_lsn() IS DISTINCT FROM pg_last_wal_replay_lsn()`).Scan(&lag); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("replication: %w", err)
+		}
+		if lag == nil || *lag < sanityReplicationLag.Seconds() {
+			return nil, nil
+		}
+		return []SanityWarning{{
+			Title: fmt.Sprintf("this standby is %s behind its primary", time.Duration(*lag*float64(time.Second)).Round(time.Second)),
+			Detail: "Reads here return data as of the last replayed transaction. Replay can stall on\n" +
+				"conflicts with long queries on the standby (see max_standby_streaming_delay) or\n" +
+				"fall behind on I/O.",
+		}}, nil
+	}
+
+	rows, err := d.Pool.Query(ctx, `
+		SELECT COALESCE(application_name, ''), COALESCE(client_addr::text, 'local'),
+		       COALESCE(extract(epoch FROM replay_lag), 0),
+		       COALESCE(pg_wal_lsn_diff(pg_current_wal_lsn(), replay_lsn), 0)::bigint
+		FROM pg_stat_replication
+		ORDER BY 4 DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("replication: %w", err)
+	}
+	defer rows.Close()
+	var lagging []string
+	for rows.Next() {
+		var name, addr string
+		var lag float64
+		var behind int64
+		if err := rows.Scan(&name, &addr, &lag, &behind); err != nil {
+			return nil, err
+		}
+		if lag >= sanityReplicationLag.Seconds() || behind >= sanityReplicationWAL {
+			lagging = append(lagging, fmt.Sprintf("  %s (%s)  replay lag %s, %d MB of WAL behind",
+				name, addr, time.Duration(lag*float64(time.Second)).Round(time.Second), behind>>20))
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(lagging) == 0 {
+		return nil, nil
+	}
+	return []SanityWarning{{
+		Title: fmt.Sprintf("%d standbys are lagging", len(lagging)),
+		Detail: "Reads on them are stale, and WAL they still need is retained here, growing pg_wal.\n" +
+			strings.Join(lagging, "\n"),
+	}}, nil
+}
@@ -23,12 +23,14 @@ type ColumnInfo struct {
 	IsNullable bool
 	Default    string
 	IsPK       bool
+	Comment    string
 }
 
 // ForeignKeyInfo describes a foreign key constraint.
 type ForeignKeyInfo struct {
 	ConstraintName string
 	Column         string
+	ForeignSchema  string // schema of ForeignTable
 	ForeignTable   string
 	ForeignColumn  string
 }
@@ -43,30 +45,17 @@ type TableSchema struct {
 // FetchTableSchema retrieves columns and foreign keys for a table.
 func (d *DB) FetchTableSchema(ctx context.Context, schema, table string) (*TableSchema, error) {
 	if schema == "" {
-		schema = "public"
+		schema = d.defaultSchema()
 	}
 
 	ts := &TableSchema{Name: table}
 
 	// Fetch columns
-	colResult, err := d.DescribeTable(ctx, schema, table)
+	columns, err := d.TableColumns(ctx, schema, table)
 	if err != nil {
 		return nil, fmt.Errorf("describe %s: %w", table, err)
 	}
-
-	for _, row := range colResult.Rows {
-		if len(row) < 5 {
-			continue
-		}
-		col := ColumnInfo{
-			Name:       row[0],
-			DataType:   row[1],
-			IsNullable: row[2] == "YES",
-			Default:    row[3],
-			IsPK:       row[4] == "PK",
-		}
-		ts.Columns = append(ts.Columns, col)
-	}
+	ts.Columns = columns
 
 	// Fetch formal foreign keys
 	fkResult, err := d.TableForeignKeys(ctx, schema, table)
@@ -75,7 +64,7 @@ func (d *DB) FetchTableSchema(ctx context.Context, schema, table string) (*Table
 	}
 
 	for _, row := range fkResult.Rows {
-		if len(row) < 4 {
+		if len(row) < 5 {
 			continue
 		}
 		fk := ForeignKeyInfo{
@@ -83,6 +72,7 @@ func (d *DB) FetchTableSchema(ctx context.Context, schema, table string) (*Table
 			Column:         row[1],
 			ForeignTable:   row[2],
 			ForeignColumn:  row[3],
+			ForeignSchema:  row[4],
 		}
 		ts.ForeignKeys = append(ts.ForeignKeys, fk)
 	}
@@ -97,6 +87,29 @@ func (d *DB) FetchTableSchema(ctx context.Context, schema, table string) (*Table
 	return ts, nil
 }
 
+// TableColumns returns the columns of a table as DescribeTable lists
+// them, in column order.
+func (d *DB) TableColumns(ctx context.Context, schema, table string) ([]ColumnInfo, error) {
+	result, err := d.DescribeTable(ctx, schema, table)
+	if err != nil {
+		return nil, err
+	}
+	var columns []ColumnInfo
+	for _, row := range result.Rows {
+		if len(row) < 5 {
+			continue
+		}
+		columns = append(columns, ColumnInfo{
+			Name:       row[0],
+			DataType:   row[1],
+			IsNullable: row[2] == "YES",
+			Default:    row[3],
+			IsPK:       row[4] == "PK",
+		})
+	}
+	return columns, nil
+}
+
 // detectImplicitFKs scans columns ending in "_id" and checks if a matching
 // table exists. This handles the common convention where FK relationships
 // are implied by naming but not enforced by constraints.
@@ -128,6 +141,7 @@ func (d *DB) detectImplicitFKs(ctx context.Context, schema string, ts *TableSche
 		fks = append(fks, ForeignKeyInfo{
 			ConstraintName: "(implicit)",
 			Column:         col.Name,
+			ForeignSchema:  schema,
 			ForeignTable:   refTable,
 			ForeignColumn:  "id",
 		})
@@ -140,7 +154,7 @@ func (d *DB) detectImplicitFKs(ctx context.Context, schema string, ts *TableSche
 // the given table's foreign keys.
 func (d *DB) FetchRelatedSchemas(ctx context.Context, schema string, mainSchema *TableSchema) (map[string]*TableSchema, error) {
 	if schema == "" {
-		schema = "public"
+		schema = d.defaultSchema()
 	}
 
 	related := make(map[string]*TableSchema)
@@ -186,7 +200,7 @@ func FormatSchemaContext(current *TableSchema, related map[string]*TableSchema)
 		if col.Default != "" {
 			def = " DEFAULT " + col.Default
 		}
-		sb.WriteString(fmt.Sprintf("- %s %s %s%s%s\n", col.Name, col.DataType, nullable, pk, def))
+		sb.WriteString(fmt.Sprintf("- %s %s %s%s%s%s\n", col.Name, col.DataType, nullable, pk, def, columnNote(col)))
 	}
 
 	// Foreign Keys
@@ -213,10 +227,19 @@ func FormatSchemaContext(current *TableSchema, related map[string]*TableSchema)
 				if col.IsPK {
 					pk = " [PK]"
 				}
-				sb.WriteString(fmt.Sprintf("- %s %s %s%s\n", col.Name, col.DataType, nullable, pk))
+				sb.WriteString(fmt.Sprintf("- %s %s %s%s%s\n", col.Name, col.DataType, nullable, pk, columnNote(col)))
 			}
 		}
 	}
 
 	return sb.String()
 }
+
+// columnNote returns extra context for special column types so the AI
+// knows how to query them.
+func columnNote(col ColumnInfo) string {
+	if IsVectorType(col.DataType) {
+		return " [pgvector embedding; similarity via <-> (L2), <=> (cosine), <#> (inner product)]"
+	}
+	return ""
+}
@@ -0,0 +1,130 @@
+// schema_compact.go renders the AI schema context in a compact form:
+// one line per column, abbreviated type names and short markers instead
+// of words. On wide schemas it is about half the size of
+// FormatSchemaContext and carries the same facts.
+package db
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// CompactSchemaLegend explains the compact notation to the model; it
+// heads every compact schema context.
+const CompactSchemaLegend = `Schema (compact): one column per line as "name type", then flags:
+pk = primary key, ! = NOT NULL, =expr = default, >table.column = foreign key, [vec] = pgvector (<-> L2, <=> cosine, <#> inner product).
+A multi-column foreign key follows the columns as "(a, b) >table.(x, y)".`
+
+// typeAbbreviations shortens the long spellings of common types to their
+// PostgreSQL aliases.
+var typeAbbreviations = []struct{ long, short string }{
+	{"timestamp without time zone", "timestamp"},
+	{"timestamp with time zone", "timestamptz"},
+	{"time without time zone", "time"},
+	{"time with time zone", "timetz"},
+	{"character varying", "varchar"},
+	{"double precision", "float8"},
+	{"character", "char"},
+	{"integer", "int4"},
+	{"smallint", "int2"},
+	{"bigint", "int8"},
+	{"boolean", "bool"},
+	{"real", "float4"},
+}
+
+// abbreviateType returns the short alias of a type name, keeping any
+// length or precision and array brackets.
+func abbreviateType(t string) string {
+	for _, a := range typeAbbreviations {
+		if rest, ok := strings.CutPrefix(t, a.long); ok && (rest == "" || rest[0] == '(' || rest[0] == '[') {
+			return a.short + rest
+		}
+	}
+	return t
+}
+
+// FormatSchemaContextCompact is FormatSchemaContext in the compact
+// notation described by CompactSchemaLegend.
+func FormatSchemaContextCompact(current *TableSchema, related map[string]*TableSchema) string {
+	var sb strings.Builder
+	sb.WriteString(CompactSchemaLegend + "\n\n")
+	sb.WriteString(fmt.Sprintf("## Current Table: %s\n", current.Name))
+	writeCompactColumns(&sb, current)
+
+	if len(related) > 0 {
+		names := make([]string, 0, len(related))
+		for name := range related {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		sb.WriteString("\n## Related Tables (via Foreign Keys)\n")
+		for _, name := range names {
+			sb.WriteString(fmt.Sprintf("### %s\n", name))
+			writeCompactColumns(&sb, related[name])
+		}
+	}
+	return sb.String()
+}
+
+func writeCompactColumns(sb *strings.Builder, ts *TableSchema) {
+	// Single-column foreign keys go on their column, which may have
+	// several; multi-column ones on lines of their own.
+	refs := map[string][]string{}
+	var composite []string
+	for _, fk := range groupForeignKeys(ts.ForeignKeys) {
+		if len(fk) == 1 {
+			refs[fk[0].Column] = append(refs[fk[0].Column], fk[0].ForeignTable+"."+fk[0].ForeignColumn)
+			continue
+		}
+		cols := make([]string, len(fk))
+		foreign := make([]string, len(fk))
+		for i, pair := range fk {
+			cols[i], foreign[i] = pair.Column, pair.ForeignColumn
+		}
+		composite = append(composite, fmt.Sprintf("(%s) >%s.(%s)",
+			strings.Join(cols, ", "), fk[0].ForeignTable, strings.Join(foreign, ", ")))
+	}
+
+	for _, col := range ts.Columns {
+		line := col.Name + " " + abbreviateType(col.DataType)
+		flags := ""
+		if col.IsPK {
+			flags += "pk"
+		}
+		if !col.IsNullable {
+			flags += "!"
+		}
+		if flags != "" {
+			line += " " + flags
+		}
+		if col.Default != "" {
+			line += " =" + col.Default
+		}
+		for _, ref := range refs[col.Name] {
+			line += " >" + ref
+		}
+		if IsVectorType(col.DataType) {
+			line += " [vec]"
+		}
+		sb.WriteString(line + "\n")
+	}
+	for _, line := range composite {
+		sb.WriteString(line + "\n")
+	}
+}
+
+// groupForeignKeys splits fks, one entry per column pair in key order,
+// into the foreign keys they make up. Implicit foreign keys share a
+// constraint name but each references a table of its own.
+func groupForeignKeys(fks []ForeignKeyInfo) [][]ForeignKeyInfo {
+	var groups [][]ForeignKeyInfo
+	for i, fk := range fks {
+		if i > 0 && fk.ConstraintName == fks[i-1].ConstraintName && fk.ForeignTable == fks[i-1].ForeignTable {
+			groups[len(groups)-1] = append(groups[len(groups)-1], fk)
+			continue
+		}
+		groups = append(groups, []ForeignKeyInfo{fk})
+	}
+	return groups
+}
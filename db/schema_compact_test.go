@@ -0,0 +1,103 @@
+package db
+
+import "testing"
+
+func TestAbbreviateType(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"timestamp with time zone", "timestamptz"},
+		{"timestamp without time zone", "timestamp"},
+		{"character varying(255)", "varchar(255)"},
+		{"character(2)", "char(2)"},
+		{"integer[]", "int4[]"},
+		{"bigint", "int8"},
+		{"double precision", "float8"},
+		{"integerish", "integerish"},
+		{"vector(3)", "vector(3)"},
+		{"text", "text"},
+	}
+	for _, tt := range tests {
+		if got := abbreviateType(tt.in); got != tt.want {
+			t.Errorf("abbreviateType(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFormatSchemaContextCompact(t *testing.T) {
+	current := &TableSchema{
+		Name: "order_lines",
+		Columns: []ColumnInfo{
+			{Name: "id", DataType: "bigint", IsPK: true, Default: "nextval('order_lines_id_seq'::regclass)"},
+			{Name: "order_id", DataType: "integer"},
+			{Name: "product_id", DataType: "integer", IsNullable: true},
+			{Name: "warehouse_id", DataType: "integer", IsNullable: true},
+			{Name: "region", DataType: "character varying(8)", IsNullable: true},
+			{Name: "embedding", DataType: "vector(3)", IsNullable: true},
+		},
+		ForeignKeys: []ForeignKeyInfo{
+			{ConstraintName: "order_lines_order_fk", Column: "order_id", ForeignTable: "orders", ForeignColumn: "id"},
+			{ConstraintName: "order_lines_order_archive_fk", Column: "order_id", ForeignTable: "orders_archive", ForeignColumn: "id"},
+			{ConstraintName: "order_lines_product_fk", Column: "product_id", ForeignTable: "products", ForeignColumn: "id"},
+			{ConstraintName: "order_lines_stock_fk", Column: "warehouse_id", ForeignTable: "stock", ForeignColumn: "warehouse_id"},
+			{ConstraintName: "order_lines_stock_fk", Column: "product_id", ForeignTable: "stock", ForeignColumn: "product_id"},
+		},
+	}
+	related := map[string]*TableSchema{
+		"products": {
+			Name:    "products",
+			Columns: []ColumnInfo{{Name: "id", DataType: "integer", IsPK: true}, {Name: "name", DataType: "text"}},
+		},
+		"orders": {
+			Name: "orders",
+			Columns: []ColumnInfo{
+				{Name: "id", DataType: "integer", IsPK: true},
+				{Name: "customer_id", DataType: "integer", IsNullable: true},
+				{Name: "placed_at", DataType: "timestamp with time zone", Default: "now()"},
+			},
+			ForeignKeys: []ForeignKeyInfo{
+				{ConstraintName: "(implicit)", Column: "customer_id", ForeignTable: "customer", ForeignColumn: "id"},
+			},
+		},
+	}
+
+	want := CompactSchemaLegend + `
+
+## Current Table: order_lines
+id int8 pk! =nextval('order_lines_id_seq'::regclass)
+order_id int4 ! >orders.id >orders_archive.id
+product_id int4 >products.id
+warehouse_id int4
+region varchar(8)
+embedding vector(3) [vec]
+(warehouse_id, product_id) >stock.(warehouse_id, product_id)
+
+## Related Tables (via Foreign Keys)
+### orders
+id int4 pk!
+customer_id int4 >customer.id
+placed_at timestamptz ! =now()
+### products
+id int4 pk!
+name text !
+`
+	if got := FormatSchemaContextCompact(current, related); got != want {
+		t.Errorf("FormatSchemaContextCompact:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestGroupForeignKeys(t *testing.T) {
+	fks := []ForeignKeyInfo{
+		{ConstraintName: "(implicit)", Column: "country_id", ForeignTable: "country", ForeignColumn: "id"},
+		{ConstraintName: "(implicit)", Column: "city_id", ForeignTable: "city", ForeignColumn: "id"},
+		{ConstraintName: "fk_ab", Column: "a", ForeignTable: "t", ForeignColumn: "x"},
+		{ConstraintName: "fk_ab", Column: "b", ForeignTable: "t", ForeignColumn: "y"},
+	}
+	groups := groupForeignKeys(fks)
+	if len(groups) != 3 {
+		t.Fatalf("groupForeignKeys: %d groups, want 3: %v", len(groups), groups)
+	}
+	for i, n := range []int{1, 1, 2} {
+		if len(groups[i]) != n {
+			t.Errorf("group %d has %d columns, want %d", i, len(groups[i]), n)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+// schema_index.go builds a whole-database column index used by
+// semantic column search ("which table has customer emails?").
+//
+// The index is fetched once per schema with a single catalog query
+// and cached by the TUI; it is small enough to send to the AI as a
+// compact "table: column type, ..." listing.
+package db
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SchemaIndex holds every column of every table in a set of schemas.
+// Tables are keyed by their DisplayNames name.
+type SchemaIndex struct {
+	Schemas  []string
+	Tables   map[string][]ColumnInfo
+	Comments map[string]string // table comments, if any
+}
+
+// FetchSchemaIndex loads all table columns (with comments) for a schema,
+// or for every schema on the search path if schema is empty.
+func (d *DB) FetchSchemaIndex(ctx context.Context, schema string) (*SchemaIndex, error) {
+	schemas := d.searchSchemas(schema)
+	query := `
+		SELECT c.table_schema, c.table_name, c.column_name, c.data_type, c.is_nullable = 'YES',
+		       COALESCE(col_description(format('%I.%I', c.table_schema, c.table_name)::regclass,
+		                                c.ordinal_position), ''),
+		       COALESCE(obj_description(format('%I.%I', c.table_schema, c.table_name)::regclass, 'pg_class'), '')
+		FROM information_schema.columns c
+		JOIN information_schema.tables t
+		  ON t.table_schema = c.table_schema AND t.table_name = c.table_name
+		WHERE c.table_schema::text = ANY($1::text[]) AND t.table_type = 'BASE TABLE'
+		ORDER BY c.table_schema, c.table_name, c.ordinal_position`
+	rows, err := d.Pool.Query(ctx, query, schemas)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tables []TableInfo
+	columns := map[TableInfo][]ColumnInfo{}
+	comments := map[TableInfo]string{}
+	for rows.Next() {
+		var t TableInfo
+		var col ColumnInfo
+		var comment string
+		if err := rows.Scan(&t.Schema, &t.Name, &col.Name, &col.DataType, &col.IsNullable, &col.Comment, &comment); err != nil {
+			return nil, err
+		}
+		if _, seen := columns[t]; !seen {
+			tables = append(tables, t)
+			comments[t] = comment
+		}
+		columns[t] = append(columns[t], col)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	idx := &SchemaIndex{Schemas: schemas, Tables: make(map[string][]ColumnInfo), Comments: make(map[string]string)}
+	for i, name := range DisplayNames(tables) {
+		idx.Tables[name] = columns[tables[i]]
+		if c := comments[tables[i]]; c != "" {
+			idx.Comments[name] = c
+		}
+	}
+	return idx, nil
+}
+
+// TableNames returns the indexed table names in sorted order.
+func (s *SchemaIndex) TableNames() []string {
+	names := make([]string, 0, len(s.Tables))
+	for name := range s.Tables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Column looks up a column by table and name.
+func (s *SchemaIndex) Column(table, column string) (ColumnInfo, bool) {
+	for _, col := range s.Tables[table] {
+		if col.Name == column {
+			return col, true
+		}
+	}
+	return ColumnInfo{}, false
+}
+
+// Format renders the index as one line per table, suitable for an AI prompt:
+//
+//	customer: id integer, email character varying -- "login address", ...
+func (s *SchemaIndex) Format() string {
+	return s.FormatTables(s.TableNames())
+}
+
+// FormatTables renders the given tables like Format.
+func (s *SchemaIndex) FormatTables(tables []string) string {
+	var sb strings.Builder
+	for _, table := range tables {
+		sb.WriteString(s.tableLine(table) + "\n")
+	}
+	return sb.String()
+}
+
+// Docs returns each table's Format line, keyed by table name; these are
+// the texts embedded for schema retrieval.
+func (s *SchemaIndex) Docs() map[string]string {
+	docs := make(map[string]string, len(s.Tables))
+	for table := range s.Tables {
+		docs[table] = s.tableLine(table)
+	}
+	return docs
+}
+
+func (s *SchemaIndex) tableLine(table string) string {
+	var cols []string
+	for _, col := range s.Tables[table] {
+		entry := col.Name + " " + col.DataType
+		if col.Comment != "" {
+			entry += fmt.Sprintf(" -- %q", col.Comment)
+		}
+		cols = append(cols, entry)
+	}
+	line := table
+	if c := s.Comments[table]; c != "" {
+		line += fmt.Sprintf(" -- %q", c)
+	}
+	return line + ": " + strings.Join(cols, ", ")
+}
@@ -0,0 +1,68 @@
+// search_path.go tracks the session's effective schema list so catalog
+// lookups follow search_path instead of assuming "public".
+//
+// The list comes from current_schemas(false): the schemas of search_path
+// that exist, in order, without the implicit pg_catalog.
+package db
+
+import (
+	"context"
+	"strings"
+)
+
+// loadSearchPath reads the effective schema list of a pooled session.
+func (d *DB) loadSearchPath(ctx context.Context) error {
+	var schemas []string
+	if err := d.Pool.QueryRow(ctx, "SELECT current_schemas(false)::text[]").Scan(&schemas); err != nil {
+		return err
+	}
+	d.SearchPath = schemas
+	return nil
+}
+
+// defaultSchema is the schema unqualified names are created in: the
+// first schema of the search path, or "public" if none exists.
+func (d *DB) defaultSchema() string {
+	if len(d.SearchPath) > 0 {
+		return d.SearchPath[0]
+	}
+	return "public"
+}
+
+// searchSchemas returns the schemas a lookup covers: schema alone if
+// given, otherwise the whole search path.
+func (d *DB) searchSchemas(schema string) []string {
+	if schema != "" {
+		return []string{schema}
+	}
+	if len(d.SearchPath) > 0 {
+		return d.SearchPath
+	}
+	return []string{"public"}
+}
+
+// DisplayNames returns the tables' names for display, qualified with the
+// schema where the same name exists in more than one schema.
+func DisplayNames(tables []TableInfo) []string {
+	count := map[string]int{}
+	for _, t := range tables {
+		count[t.Name]++
+	}
+	names := make([]string, len(tables))
+	for i, t := range tables {
+		names[i] = t.Name
+		if count[t.Name] > 1 {
+			names[i] = t.Schema + "." + t.Name
+		}
+	}
+	return names
+}
+
+// SplitTableName splits "schema.table" into its parts. An unqualified
+// name returns an empty schema.
+func SplitTableName(name string) (schema, table string) {
+	if i := strings.IndexByte(name, '.'); i > 0 {
+		return name[:i], name[i+1:]
+	}
+	return "", name
+}
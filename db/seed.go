@@ -0,0 +1,377 @@
+// seed.go generates fake rows for a table and bulk inserts them, for
+// filling development and test databases.
+//
+// Values come from simple rule-based generators keyed on column name and
+// type (emails, names, dates, ...). Foreign key columns are always filled
+// with values sampled from the referenced table, so generated rows satisfy
+// both formal and implicit (*_id) relationships.
+package db
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// SeedData is a batch of generated rows waiting to be inserted.
+// A nil value is NULL; all other values are strings in PostgreSQL text format.
+type SeedData struct {
+	Schema  string // empty means the search path
+	Table   string
+	Columns []string
+	Rows    [][]any
+}
+
+// Seeder generates rows for one table.
+type Seeder struct {
+	Schema  *TableSchema
+	Columns []ColumnInfo // columns that get values (no serial/identity/defaulted PKs)
+
+	schema  string              // schema the table lives in
+	parents map[string][]string // FK column → sampled parent values
+	nextInt map[string]int64    // integer PK column → next free value
+	offset  int64               // existing row count, keeps unique values unique across runs
+	rng     *rand.Rand
+}
+
+// NewSeeder loads the table schema and samples parent keys for its
+// foreign keys. It fails if a NOT NULL foreign key has no parent rows.
+func (d *DB) NewSeeder(ctx context.Context, schema, table string) (*Seeder, error) {
+	if schema == "" {
+		schema = d.defaultSchema()
+	}
+	ts, err := d.FetchTableSchema(ctx, schema, table)
+	if err != nil {
+		return nil, err
+	}
+	if len(ts.Columns) == 0 {
+		return nil, fmt.Errorf("table %s not found", table)
+	}
+
+	s := &Seeder{
+		Schema:  ts,
+		schema:  schema,
+		parents: make(map[string][]string),
+		nextInt: make(map[string]int64),
+	}
+	rel := qualifiedTable(schema, table)
+	_ = d.Pool.QueryRow(ctx, fmt.Sprintf("SELECT count(*) FROM %s", rel)).Scan(&s.offset)
+
+	h := fnv.New64a()
+	h.Write([]byte(table))
+	s.rng = rand.New(rand.NewSource(int64(h.Sum64()) + s.offset))
+
+	fkByCol := make(map[string]ForeignKeyInfo)
+	for _, fk := range ts.ForeignKeys {
+		fkByCol[fk.Column] = fk
+	}
+
+	for _, col := range ts.Columns {
+		// Let the database fill serial, identity and defaulted key columns
+		if col.IsPK && col.Default != "" {
+			continue
+		}
+		if strings.Contains(col.Default, "nextval(") {
+			continue
+		}
+		if fk, ok := fkByCol[col.Name]; ok {
+			sql := fmt.Sprintf("SELECT DISTINCT %s::text FROM %s WHERE %s IS NOT NULL LIMIT 1000",
+				ident(fk.ForeignColumn), qualifiedTable(fk.ForeignSchema, fk.ForeignTable), ident(fk.ForeignColumn))
+			res, err := d.executeQuery(ctx, sql)
+			if err != nil {
+				return nil, fmt.Errorf("sample %s.%s: %w", fk.ForeignTable, fk.ForeignColumn, err)
+			}
+			for _, row := range res.Rows {
+				s.parents[col.Name] = append(s.parents[col.Name], row[0])
+			}
+			if len(s.parents[col.Name]) == 0 && !col.IsNullable {
+				return nil, fmt.Errorf("%s.%s references %s, which has no rows — seed it first",
+					table, col.Name, fk.ForeignTable)
+			}
+		} else if col.IsPK && isIntegerType(col.DataType) {
+			var maxID int64
+			_ = d.Pool.QueryRow(ctx, fmt.Sprintf("SELECT COALESCE(max(%s), 0) FROM %s",
+				ident(col.Name), rel)).Scan(&maxID)
+			s.nextInt[col.Name] = maxID + 1
+		}
+		s.Columns = append(s.Columns, col)
+	}
+	if len(s.Columns) == 0 {
+		return nil, fmt.Errorf("table %s has no columns to fill", table)
+	}
+	return s, nil
+}
+
+// IsForeignKey reports whether the seeder fills col from a parent table.
+func (s *Seeder) IsForeignKey(col string) bool {
+	_, ok := s.parents[col]
+	return ok
+}
+
+// Describe lists the columns a caller should supply values for (foreign
+// keys and integer keys are filled by the seeder), one per line.
+func (s *Seeder) Describe() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Table: %s\n", s.Schema.Name)
+	for _, col := range s.Columns {
+		if _, managed := s.nextInt[col.Name]; managed || s.IsForeignKey(col.Name) {
+			continue
+		}
+		nullable := "NULL"
+		if !col.IsNullable {
+			nullable = "NOT NULL"
+		}
+		fmt.Fprintf(&sb, "- %s %s %s", col.Name, col.DataType, nullable)
+		if col.Comment != "" {
+			fmt.Fprintf(&sb, " -- %q", col.Comment)
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// Generate creates n rows with the rule-based generators.
+func (s *Seeder) Generate(n int) *SeedData {
+	return s.FromRecords(nil, n)
+}
+
+// FromRecords creates n rows, taking values from records (e.g. AI output,
+// keyed by column name) where present and generating the rest. Foreign
+// keys and integer primary keys are always generated.
+func (s *Seeder) FromRecords(records []map[string]any, n int) *SeedData {
+	data := &SeedData{Schema: s.schema, Table: s.Schema.Name}
+	for _, col := range s.Columns {
+		data.Columns = append(data.Columns, col.Name)
+	}
+
+	for i := 0; i < n; i++ {
+		var rec map[string]any
+		if i < len(records) {
+			rec = records[i]
+		}
+		row := make([]any, len(s.Columns))
+		for j, col := range s.Columns {
+			_, managed := s.nextInt[col.Name]
+			if v, ok := rec[col.Name]; ok && !managed && !s.IsForeignKey(col.Name) {
+				if v == nil {
+					if col.IsNullable {
+						row[j] = nil
+						continue
+					}
+				} else {
+					row[j] = fitLength(col.DataType, fmt.Sprint(v))
+					continue
+				}
+			}
+			row[j] = s.value(col, i)
+		}
+		data.Rows = append(data.Rows, row)
+	}
+	return data
+}
+
+// value generates one value for col in row i.
+func (s *Seeder) value(col ColumnInfo, i int) any {
+	if parents, ok := s.parents[col.Name]; ok {
+		if len(parents) == 0 {
+			return nil
+		}
+		return parents[s.rng.Intn(len(parents))]
+	}
+	if next, ok := s.nextInt[col.Name]; ok {
+		s.nextInt[col.Name] = next + 1
+		return strconv.FormatInt(next, 10)
+	}
+
+	// Leave some optional columns empty, like real data
+	if col.IsNullable && s.rng.Intn(10) == 0 {
+		return nil
+	}
+
+	seq := s.offset + int64(i) + 1
+	name := strings.ToLower(col.Name)
+	first := seedFirstNames[s.rng.Intn(len(seedFirstNames))]
+	last := seedLastNames[s.rng.Intn(len(seedLastNames))]
+	t := strings.ToLower(col.DataType)
+
+	var v string
+	switch {
+	case t == "boolean":
+		v = strconv.FormatBool(s.rng.Intn(2) == 0)
+	case t == "uuid":
+		b := make([]byte, 16)
+		s.rng.Read(b)
+		b[6] = b[6]&0x0f | 0x40
+		b[8] = b[8]&0x3f | 0x80
+		v = fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+	case t == "date":
+		v = s.randomTime().Format("2006-01-02")
+	case strings.HasPrefix(t, "timestamp"):
+		v = s.randomTime().Format("2006-01-02 15:04:05")
+	case strings.HasPrefix(t, "time"):
+		v = fmt.Sprintf("%02d:%02d:00", s.rng.Intn(24), s.rng.Intn(60))
+	case t == "json" || t == "jsonb":
+		v = "{}"
+	case t == "array":
+		v = "{}"
+	case isIntegerType(t):
+		switch {
+		case strings.Contains(name, "age"):
+			v = strconv.Itoa(18 + s.rng.Intn(60))
+		case strings.Contains(name, "year"):
+			v = strconv.Itoa(1990 + s.rng.Intn(36))
+		case strings.Contains(name, "qty") || strings.Contains(name, "quantity") || strings.Contains(name, "count"):
+			v = strconv.Itoa(1 + s.rng.Intn(20))
+		default:
+			v = strconv.Itoa(1 + s.rng.Intn(1000))
+		}
+	case strings.HasPrefix(t, "numeric") || t == "real" || t == "double precision" || t == "money":
+		v = strconv.FormatFloat(float64(s.rng.Intn(100000))/100, 'f', 2, 64)
+	case !isTextType(t) && col.IsNullable:
+		// Enums, ranges, network types, ...: no generator, leave empty
+		return nil
+	case strings.Contains(name, "email"):
+		v = fmt.Sprintf("%s.%s%d@example.com", strings.ToLower(first), strings.ToLower(last), seq)
+	case strings.Contains(name, "first") && strings.Contains(name, "name"):
+		v = first
+	case strings.Contains(name, "last") && strings.Contains(name, "name"), name == "surname":
+		v = last
+	case name == "username" || name == "login" || name == "handle":
+		v = fmt.Sprintf("%s%s%d", strings.ToLower(first[:1]), strings.ToLower(last), seq)
+	case strings.Contains(name, "name"):
+		v = first + " " + last
+	case strings.Contains(name, "phone"):
+		v = fmt.Sprintf("+1-555-%03d-%04d", s.rng.Intn(1000), s.rng.Intn(10000))
+	case strings.Contains(name, "city"):
+		v = seedCities[s.rng.Intn(len(seedCities))]
+	case strings.Contains(name, "country"):
+		v = seedCountries[s.rng.Intn(len(seedCountries))]
+	case strings.Contains(name, "address") || strings.Contains(name, "street"):
+		v = fmt.Sprintf("%d %s St", 1+s.rng.Intn(9999), last)
+	case strings.Contains(name, "url") || strings.Contains(name, "website"):
+		v = fmt.Sprintf("https://example.com/%s/%d", strings.ToLower(last), seq)
+	case strings.Contains(name, "status"):
+		v = seedStatuses[s.rng.Intn(len(seedStatuses))]
+	case strings.Contains(name, "code") || strings.Contains(name, "sku"):
+		v = fmt.Sprintf("%s-%05d", strings.ToUpper(name[:1]), seq)
+	default:
+		v = s.words(3 + s.rng.Intn(6))
+	}
+	return fitLength(col.DataType, v)
+}
+
+func (s *Seeder) randomTime() time.Time {
+	days := s.rng.Intn(2 * 365)
+	return time.Now().AddDate(0, 0, -days).Add(-time.Duration(s.rng.Intn(86400)) * time.Second)
+}
+
+func (s *Seeder) words(n int) string {
+	w := make([]string, n)
+	for i := range w {
+		w[i] = seedWords[s.rng.Intn(len(seedWords))]
+	}
+	w[0] = strings.ToUpper(w[0][:1]) + w[0][1:]
+	return strings.Join(w, " ")
+}
+
+// InsertSeed bulk inserts data in a single transaction and returns the
+// number of rows inserted.
+func (d *DB) InsertSeed(ctx context.Context, data *SeedData) (int, error) {
+	// A statement takes at most 65535 bind parameters.
+	const maxBatchRows, maxParams = 500, 65535
+
+	cols := make([]string, len(data.Columns))
+	for i, c := range data.Columns {
+		cols[i] = ident(c)
+	}
+	batchRows := maxBatchRows
+	if len(cols) > 0 {
+		batchRows = min(maxBatchRows, maxParams/len(cols))
+	}
+	prefix := fmt.Sprintf("INSERT INTO %s (%s) VALUES ", qualifiedTable(data.Schema, data.Table), strings.Join(cols, ", "))
+
+	tx, err := d.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	inserted := 0
+	for start := 0; start < len(data.Rows); start += batchRows {
+		end := min(start+batchRows, len(data.Rows))
+		var sb strings.Builder
+		sb.WriteString(prefix)
+		args := make([]any, 0, (end-start)*len(cols))
+		for r := start; r < end; r++ {
+			if r > start {
+				sb.WriteString(", ")
+			}
+			sb.WriteString("(")
+			for c := range cols {
+				if c > 0 {
+					sb.WriteString(", ")
+				}
+				args = append(args, data.Rows[r][c])
+				fmt.Fprintf(&sb, "$%d", len(args))
+			}
+			sb.WriteString(")")
+		}
+		tag, err := tx.Exec(ctx, sb.String(), args...)
+		if err != nil {
+			return 0, fmt.Errorf("insert rows %d-%d: %w", start+1, end, err)
+		}
+		inserted += int(tag.RowsAffected())
+	}
+	return inserted, tx.Commit(ctx)
+}
+
+// qualifiedTable quotes table, qualified with schema unless it is empty.
+func qualifiedTable(schema, table string) string {
+	if schema != "" {
+		return pgx.Identifier{schema, table}.Sanitize()
+	}
+	return pgx.Identifier{table}.Sanitize()
+}
+
+func isIntegerType(t string) bool {
+	switch strings.ToLower(t) {
+	case "integer", "bigint", "smallint", "int", "int2", "int4", "int8":
+		return true
+	}
+	return false
+}
+
+func isTextType(t string) bool {
+	return t == "text" || t == "citext" || t == "name" || strings.HasPrefix(t, "character")
+}
+
+var varcharLen = regexp.MustCompile(`^character(?: varying)?\((\d+)\)$`)
+
+// fitLength truncates v to the length limit of a character(n) type.
+func fitLength(dataType, v string) string {
+	if m := varcharLen.FindStringSubmatch(dataType); m != nil {
+		if n, _ := strconv.Atoi(m[1]); n > 0 && len([]rune(v)) > n {
+			return string([]rune(v)[:n])
+		}
+	}
+	return v
+}
+
+var (
+	seedFirstNames = []string{"Alice", "Bob", "Carol", "David", "Emma", "Frank", "Grace", "Henry", "Iris", "Jack",
+		"Karen", "Liam", "Maya", "Noah", "Olivia", "Paul", "Quinn", "Rosa", "Sam", "Tara", "Uma", "Victor", "Wei", "Yuki", "Zoe"}
+	seedLastNames = []string{"Smith", "Johnson", "Garcia", "Chen", "Mueller", "Silva", "Kim", "Patel", "Nguyen", "Brown",
+		"Rossi", "Novak", "Tanaka", "Okafor", "Dubois", "Larsen", "Cohen", "Walker", "Lopez", "Singh"}
+	seedCities    = []string{"London", "Berlin", "Tokyo", "Toronto", "Sydney", "São Paulo", "Paris", "Seoul", "Austin", "Lagos"}
+	seedCountries = []string{"United States", "Germany", "Japan", "Canada", "Australia", "Brazil", "France", "India", "Kenya", "Spain"}
+	seedStatuses  = []string{"active", "pending", "inactive", "archived"}
+	seedWords     = []string{"alpha", "bright", "cloud", "delta", "ember", "forest", "granite", "harbor", "island", "jade",
+		"kernel", "lunar", "meadow", "nova", "orbit", "prism", "quartz", "river", "summit", "timber", "union", "vector", "willow"}
+)
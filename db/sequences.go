@@ -0,0 +1,144 @@
+// sequences.go inspects the sequences of a schema: how far each has
+// counted, the column it feeds, and whether it is about to run out of
+// values or has fallen behind rows inserted with explicit ids (typically
+// after a bulk load or a restore), in which case the next insert fails
+// with a duplicate key.
+package db
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	pgx "github.com/jackc/pgx/v5"
+)
+
+// sequenceOverflowRatio is the share of its range a sequence may use
+// before it is flagged as near overflow.
+const sequenceOverflowRatio = 0.75
+
+// SequenceInfo describes a sequence and the column that owns it.
+type SequenceInfo struct {
+	Schema    string
+	Name      string
+	LastValue *int64 // nil until nextval is first called (or without privilege)
+	Start     int64
+	Increment int64
+	MaxValue  int64
+	Cycle     bool
+
+	// The owning column (serial or identity); Table is "" for a free
+	// standing sequence.
+	Table      string
+	Column     string
+	ColumnType string
+	ColumnMax  *int64 // max of the column, nil for an empty table
+	Err        error  // set if the column max could not be read
+}
+
+// Next returns the value nextval will return.
+func (s *SequenceInfo) Next() int64 {
+	if s.LastValue == nil {
+		return s.Start
+	}
+	return *s.LastValue + s.Increment
+}
+
+// Limit returns the largest value the sequence can hand out: its
+// MAXVALUE, or less if the owning column's type is narrower.
+func (s *SequenceInfo) Limit() int64 {
+	limit := s.MaxValue
+	switch s.ColumnType {
+	case "smallint":
+		limit = min(limit, math.MaxInt16)
+	case "integer":
+		limit = min(limit, math.MaxInt32)
+	}
+	return limit
+}
+
+// Used returns the share of the range the sequence has used, 0 to 1.
+func (s *SequenceInfo) Used() float64 {
+	if s.LastValue == nil || s.Increment < 0 || s.Limit() <= s.Start {
+		return 0
+	}
+	return float64(*s.LastValue-s.Start) / float64(s.Limit()-s.Start)
+}
+
+// NearOverflow reports whether an ascending, non-cycling sequence has
+// used most of its range.
+func (s *SequenceInfo) NearOverflow() bool {
+	return !s.Cycle && s.Used() >= sequenceOverflowRatio
+}
+
+// Behind reports whether the owning column already holds the value the
+// sequence hands out next, so the next insert collides.
+func (s *SequenceInfo) Behind() bool {
+	return s.Increment > 0 && s.ColumnMax != nil && *s.ColumnMax >= s.Next()
+}
+
+// FixSQL returns the statement moving a sequence that is Behind past the
+// owning column's max, or "" if it isn't behind. The max is read when the
+// statement runs, so rows inserted meanwhile are accounted for.
+func (s *SequenceInfo) FixSQL() string {
+	if !s.Behind() {
+		return ""
+	}
+	return fmt.Sprintf("SELECT setval(%s, (SELECT max(%s) FROM %s))",
+		quoteLiteral(pgx.Identifier{s.Schema, s.Name}.Sanitize()),
+		ident(s.Column), pgx.Identifier{s.Schema, s.Table}.Sanitize())
+}
+
+// ListSequences returns the sequences of schema (the default schema if
+// empty) with the max of their owning columns. A column that can't be
+// read sets the sequence's Err.
+func (d *DB) ListSequences(ctx context.Context, schema string) ([]SequenceInfo, error) {
+	if schema == "" {
+		schema = d.defaultSchema()
+	}
+	rows, err := d.Pool.Query(ctx, `
+		SELECT s.schemaname, s.sequencename, s.last_value, s.start_value, s.increment_by,
+		       s.max_value, s.cycle,
+		       COALESCE(t.relname, ''), COALESCE(a.attname, ''),
+		       COALESCE(format_type(a.atttypid, a.atttypmod), '')
+		FROM pg_sequences s
+		JOIN pg_namespace n ON n.nspname = s.schemaname
+		JOIN pg_class c ON c.relnamespace = n.oid AND c.relname = s.sequencename
+		LEFT JOIN pg_depend dep ON dep.classid = 'pg_class'::regclass AND dep.objid = c.oid
+		     AND dep.refclassid = 'pg_class'::regclass AND dep.refobjsubid > 0
+		     AND dep.deptype IN ('a', 'i')
+		LEFT JOIN pg_class t ON t.oid = dep.refobjid
+		LEFT JOIN pg_attribute a ON a.attrelid = dep.refobjid AND a.attnum = dep.refobjsubid
+		WHERE s.schemaname = $1
+		ORDER BY s.sequencename`, schema)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var seqs []SequenceInfo
+	for rows.Next() {
+		var s SequenceInfo
+		if err := rows.Scan(&s.Schema, &s.Name, &s.LastValue, &s.Start, &s.Increment,
+			&s.MaxValue, &s.Cycle, &s.Table, &s.Column, &s.ColumnType); err != nil {
+			return nil, err
+		}
+		seqs = append(seqs, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range seqs {
+		s := &seqs[i]
+		if s.Table == "" {
+			continue
+		}
+		// The max is only comparable for integer columns, which is what
+		// serial and identity columns are.
+		sql := fmt.Sprintf("SELECT max(%s)::bigint FROM %s", ident(s.Column), pgx.Identifier{s.Schema, s.Table}.Sanitize())
+		if err := d.Pool.QueryRow(ctx, sql).Scan(&s.ColumnMax); err != nil {
+			s.Err = err
+		}
+	}
+	return seqs, nil
+}
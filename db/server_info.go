@@ -0,0 +1,41 @@
+// server_info.go reads who and where a connection is, for the banner shown
+// after connecting: the server version, the role, whether the server is a
+// read-only standby, and the team's message of the day.
+//
+// The message of the day is the paisql.motd setting, so it lives on the
+// server and everyone connecting sees the same one:
+//
+//	ALTER DATABASE app SET paisql.motd = 'Production: ask in #db before writing';
+package db
+
+import "context"
+
+// MOTDSetting is the server setting holding the message of the day.
+const MOTDSetting = "paisql.motd"
+
+// ServerInfo describes the server and session of a connection.
+type ServerInfo struct {
+	Version    string // server_version, e.g. "16.2"
+	Database   string
+	User       string // current_user
+	Superuser  bool
+	InRecovery bool // a standby: read-only
+	ReadOnly   bool // default_transaction_read_only
+	MOTD       string
+}
+
+// FetchServerInfo reads the server and session details of the connection.
+func (d *DB) FetchServerInfo(ctx context.Context) (*ServerInfo, error) {
+	s := &ServerInfo{}
+	err := d.Pool.QueryRow(ctx, `
+		SELECT current_setting('server_version'), current_database(), current_user,
+		       COALESCE((SELECT rolsuper FROM pg_roles WHERE rolname = current_user), false),
+		       pg_is_in_recovery(),
+		       current_setting('default_transaction_read_only') = 'on',
+		       COALESCE(current_setting($1, true), '')`, MOTDSetting).
+		Scan(&s.Version, &s.Database, &s.User, &s.Superuser, &s.InRecovery, &s.ReadOnly, &s.MOTD)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
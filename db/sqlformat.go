@@ -0,0 +1,469 @@
+// sqlformat.go reformats SQL text for reading: keywords in upper case,
+// each clause on its own line, select lists and AND/OR conditions one per
+// line, and subqueries indented. It works on tokens rather than a parse
+// tree, so it accepts anything, including SQL it doesn't fully understand;
+// string literals, quoted identifiers and comments are kept verbatim.
+package db
+
+import (
+	"strings"
+	"unicode"
+)
+
+const sqlIndent = "    "
+
+type sqlTokenKind int
+
+const (
+	tokWord        sqlTokenKind = iota // keyword, identifier, $1 or :var
+	tokQuoted                          // "identifier"
+	tokString                          // 'literal', E'…', $$…$$
+	tokNumber                          // 42, 3.14, 1e6
+	tokOp                              // =, <>, ||, ::, ->>, …
+	tokPunct                           // ( ) [ ] , ; .
+	tokComment                         // /* … */
+	tokLineComment                     // -- …
+)
+
+type sqlToken struct {
+	kind       sqlTokenKind
+	text       string
+	start, end int // rune offsets in the source
+}
+
+// sqlKeywords are upper-cased by FormatSQL. Words that are common column
+// names (name, type, key, first, …) are left out so identifiers keep the
+// case they were typed in.
+var sqlKeywords = map[string]bool{}
+
+func init() {
+	for _, kw := range strings.Fields(`
+		SELECT FROM WHERE AND OR NOT IN IS NULL AS ON USING
+		JOIN LEFT RIGHT FULL INNER OUTER CROSS NATURAL LATERAL
+		GROUP BY ORDER HAVING LIMIT OFFSET FETCH WINDOW OVER PARTITION FILTER WITHIN
+		UNION ALL INTERSECT EXCEPT DISTINCT
+		INSERT INTO VALUES UPDATE SET DELETE RETURNING WITH RECURSIVE CONFLICT DO NOTHING
+		CASE WHEN THEN ELSE END BETWEEN LIKE ILIKE SIMILAR EXISTS ANY SOME
+		TRUE FALSE ASC DESC NULLS CAST INTERVAL FOR
+		CREATE TABLE INDEX VIEW DROP ALTER ADD COLUMN CONSTRAINT PRIMARY FOREIGN
+		REFERENCES UNIQUE CHECK DEFAULT TRUNCATE CASCADE IF
+		RENAME TO ONLY MATERIALIZED SCHEMA VACUUM REINDEX CLUSTER CONCURRENTLY VERBOSE
+		CURRENT_DATE CURRENT_TIME CURRENT_TIMESTAMP CURRENT_USER LOCALTIME LOCALTIMESTAMP
+		BEGIN COMMIT ROLLBACK EXPLAIN ANALYZE`) {
+		sqlKeywords[kw] = true
+	}
+}
+
+// keywordAt reports whether the word at toks[i] is a keyword. Besides
+// sqlKeywords, that covers words that are keywords only in context: the
+// FIRST/LAST of NULLS FIRST and the TYPE of ALTER COLUMN c TYPE.
+func keywordAt(toks []sqlToken, i int) bool {
+	upper := strings.ToUpper(toks[i].text)
+	switch {
+	case sqlKeywords[upper]:
+		return true
+	case upper == "FIRST" || upper == "LAST":
+		return i > 0 && strings.EqualFold(toks[i-1].text, "NULLS")
+	case upper == "TYPE":
+		return i > 1 && strings.EqualFold(toks[i-2].text, "COLUMN")
+	}
+	return false
+}
+
+// sqlClauses start a new line when they appear at query level (not
+// inside a function call or IN list). Longer phrases come first so they
+// win over their prefixes.
+var sqlClauses = [][]string{
+	{"LEFT", "OUTER", "JOIN"}, {"RIGHT", "OUTER", "JOIN"}, {"FULL", "OUTER", "JOIN"},
+	{"LEFT", "JOIN"}, {"RIGHT", "JOIN"}, {"FULL", "JOIN"},
+	{"INNER", "JOIN"}, {"CROSS", "JOIN"}, {"NATURAL", "JOIN"}, {"JOIN"},
+	{"GROUP", "BY"}, {"ORDER", "BY"}, {"UNION", "ALL"}, {"INSERT", "INTO"}, {"DELETE", "FROM"},
+	{"ON", "CONFLICT"}, {"DO", "UPDATE"}, {"DO", "NOTHING"}, {"FOR", "UPDATE"},
+	{"SELECT"}, {"FROM"}, {"WHERE"}, {"HAVING"}, {"LIMIT"}, {"OFFSET"}, {"FETCH"}, {"WINDOW"},
+	{"UNION"}, {"INTERSECT"}, {"EXCEPT"}, {"VALUES"}, {"UPDATE"}, {"SET"}, {"RETURNING"}, {"WITH"},
+}
+
+// listClauses put each comma-separated item on its own line.
+var listClauses = map[string]bool{
+	"SELECT": true, "GROUP BY": true, "ORDER BY": true, "SET": true, "VALUES": true, "RETURNING": true,
+}
+
+// conditionClauses put each top-level AND/OR on its own line.
+var conditionClauses = map[string]bool{"WHERE": true, "HAVING": true, "JOIN": true}
+
+// FormatSQL returns sql laid out one clause per line with upper-case
+// keywords. Statements separated by semicolons are separated by a blank
+// line.
+func FormatSQL(sql string) string {
+	f := &sqlFormatter{toks: tokenizeSQL(sql), atLineStart: true}
+	f.reset()
+	f.run()
+	return strings.TrimSpace(f.sb.String())
+}
+
+// sqlFrame is a parenthesised level: the whole statement, a subquery, or
+// any other parentheses (function call, IN list, column list).
+type sqlFrame struct {
+	query     bool   // statement or subquery; clauses break lines
+	level     int    // indent of the frame's clause lines
+	openLevel int    // indent of the line holding "(", for the closing ")"
+	clause    string // current clause, e.g. "SELECT" or "JOIN"
+	between   bool   // inside BETWEEN x AND y; the next AND doesn't break
+	started   bool
+}
+
+type sqlFormatter struct {
+	toks        []sqlToken
+	sb          strings.Builder
+	frames      []*sqlFrame
+	lineLevel   int
+	atLineStart bool
+	prev        *sqlToken
+	unary       bool // prev was a sign; no space after it
+}
+
+func (f *sqlFormatter) reset() {
+	f.frames = []*sqlFrame{{query: true}}
+	f.prev = nil
+}
+
+func (f *sqlFormatter) top() *sqlFrame { return f.frames[len(f.frames)-1] }
+
+func (f *sqlFormatter) newline(level int) {
+	s := strings.TrimRight(f.sb.String(), " ")
+	f.sb.Reset()
+	f.sb.WriteString(s + "\n" + strings.Repeat(sqlIndent, level))
+	f.lineLevel = level
+	f.atLineStart = true
+}
+
+func (f *sqlFormatter) emit(text string, space bool) {
+	if space && !f.atLineStart {
+		f.sb.WriteByte(' ')
+	}
+	f.sb.WriteString(text)
+	f.atLineStart = false
+	f.top().started = true
+}
+
+// spaceBefore reports whether tok is separated from the previous token.
+func (f *sqlFormatter) spaceBefore(tok sqlToken) bool {
+	if f.prev == nil || f.unary {
+		return false
+	}
+	switch tok.text {
+	case ",", ";", ")", "]", ".", "::", "[":
+		return false
+	case "(":
+		// count(, varchar( and "fn"( take no space; IN (, AS ( and = ( do,
+		// as does the column list of INSERT INTO t (…).
+		p := f.prev
+		if f.top().clause == "INSERT INTO" {
+			return true
+		}
+		return p.kind == tokOp || p.text == "," || (p.kind == tokWord && sqlKeywords[strings.ToUpper(p.text)])
+	}
+	switch f.prev.text {
+	case "(", "[", ".", "::":
+		return false
+	}
+	return true
+}
+
+// clauseAt matches a clause phrase starting at token i.
+func (f *sqlFormatter) clauseAt(i int) []string {
+	frame := f.top()
+	for _, phrase := range sqlClauses {
+		if i+len(phrase) > len(f.toks) {
+			continue
+		}
+		match := true
+		for j, word := range phrase {
+			t := f.toks[i+j]
+			if t.kind != tokWord || !strings.EqualFold(t.text, word) {
+				match = false
+				break
+			}
+		}
+		if !match {
+			continue
+		}
+		switch phrase[0] {
+		case "WITH":
+			// WITH starts a statement; elsewhere it's WITH ORDINALITY,
+			// WITH TIME ZONE and the like.
+			if frame.started {
+				continue
+			}
+		case "FROM":
+			if f.prev != nil && strings.EqualFold(f.prev.text, "DISTINCT") {
+				continue // IS DISTINCT FROM
+			}
+		}
+		return phrase
+	}
+	return nil
+}
+
+// opensSubquery reports whether the "(" at token i starts a query.
+func (f *sqlFormatter) opensSubquery(i int) bool {
+	for _, t := range f.toks[i+1:] {
+		if t.kind == tokComment || t.kind == tokLineComment {
+			continue
+		}
+		return t.kind == tokWord && (strings.EqualFold(t.text, "SELECT") || strings.EqualFold(t.text, "WITH"))
+	}
+	return false
+}
+
+func (f *sqlFormatter) run() {
+	for i := 0; i < len(f.toks); i++ {
+		tok := f.toks[i]
+		frame := f.top()
+		space := f.spaceBefore(tok)
+		unary := false
+
+		switch {
+		case tok.kind == tokLineComment:
+			f.emit(tok.text, space)
+			f.newline(f.lineLevel)
+
+		case tok.text == ";":
+			f.emit(";", false)
+			f.reset()
+			if i < len(f.toks)-1 {
+				f.newline(0)
+				f.newline(0)
+			}
+			continue
+
+		case tok.text == "(":
+			f.emit("(", space)
+			sub := f.opensSubquery(i)
+			next := &sqlFrame{query: sub, level: f.lineLevel + 1, openLevel: f.lineLevel}
+			f.frames = append(f.frames, next)
+
+		case tok.text == ")":
+			if len(f.frames) > 1 {
+				f.frames = f.frames[:len(f.frames)-1]
+				if frame.query {
+					f.newline(frame.openLevel)
+				}
+			}
+			f.emit(")", false)
+
+		case tok.text == ",":
+			f.emit(",", false)
+			if frame.query && listClauses[frame.clause] {
+				f.newline(frame.level + 1)
+			} else if frame.query && frame.clause == "WITH" {
+				f.newline(frame.level)
+			}
+
+		case tok.kind == tokWord:
+			upper := strings.ToUpper(tok.text)
+			if frame.query {
+				if phrase := f.clauseAt(i); phrase != nil {
+					if !f.atLineStart {
+						f.newline(frame.level)
+					}
+					f.emit(strings.Join(phrase, " "), space)
+					frame.clause = phrase[len(phrase)-1]
+					if frame.clause != "JOIN" {
+						frame.clause = strings.Join(phrase, " ")
+					}
+					frame.between = false
+					i += len(phrase) - 1
+					f.prev = &f.toks[i]
+					f.unary = false
+					continue
+				}
+				if (upper == "AND" || upper == "OR") && conditionClauses[frame.clause] {
+					if upper == "AND" && frame.between {
+						frame.between = false
+					} else {
+						f.newline(frame.level + 1)
+					}
+				}
+				if upper == "BETWEEN" {
+					frame.between = true
+				}
+			}
+			text := tok.text
+			if keywordAt(f.toks, i) {
+				text = upper
+			}
+			f.emit(text, space)
+
+		case tok.kind == tokOp:
+			if tok.text == "::" {
+				f.emit(tok.text, false)
+				break
+			}
+			if (tok.text == "-" || tok.text == "+") && f.precedesOperand() {
+				unary = true
+			}
+			f.emit(tok.text, space)
+
+		default:
+			f.emit(tok.text, space)
+		}
+
+		f.prev = &f.toks[i]
+		f.unary = unary
+	}
+}
+
+// precedesOperand reports whether the next token starts an operand, so a
+// sign there is unary: after an operator, "(", ",", a keyword or nothing.
+func (f *sqlFormatter) precedesOperand() bool {
+	p := f.prev
+	if p == nil {
+		return true
+	}
+	switch {
+	case p.kind == tokOp, p.text == "(", p.text == ",", p.text == "[":
+		return true
+	case p.kind == tokWord:
+		return sqlKeywords[strings.ToUpper(p.text)]
+	}
+	return false
+}
+
+// tokenizeSQL splits sql into tokens, dropping whitespace.
+func tokenizeSQL(sql string) []sqlToken {
+	var toks []sqlToken
+	rs := []rune(sql)
+	n := len(rs)
+	for i := 0; i < n; {
+		c := rs[i]
+		start := i
+		switch {
+		case unicode.IsSpace(c):
+			i++
+			continue
+
+		case c == '-' && i+1 < n && rs[i+1] == '-':
+			for i < n && rs[i] != '\n' {
+				i++
+			}
+			toks = append(toks, sqlToken{tokLineComment, strings.TrimRight(string(rs[start:i]), " \t\r"), start, i})
+			continue
+
+		case c == '/' && i+1 < n && rs[i+1] == '*':
+			i = scanUntil(rs, i+2, []rune("*/"))
+			toks = append(toks, sqlToken{tokComment, string(rs[start:i]), start, i})
+			continue
+
+		case c == '\'':
+			i = scanQuoted(rs, i, '\'', false)
+			toks = append(toks, sqlToken{tokString, string(rs[start:i]), start, i})
+			continue
+
+		case c == '"':
+			i = scanQuoted(rs, i, '"', false)
+			toks = append(toks, sqlToken{tokQuoted, string(rs[start:i]), start, i})
+			continue
+
+		case c == '$':
+			// $1 is a parameter; $tag$ … $tag$ is a dollar-quoted string.
+			j := i + 1
+			for j < n && (unicode.IsLetter(rs[j]) || unicode.IsDigit(rs[j]) || rs[j] == '_') {
+				j++
+			}
+			if j < n && rs[j] == '$' {
+				i = scanUntil(rs, j+1, rs[i:j+1])
+				toks = append(toks, sqlToken{tokString, string(rs[start:i]), start, i})
+				continue
+			}
+			i = j
+			toks = append(toks, sqlToken{tokWord, string(rs[start:i]), start, i})
+			continue
+
+		case unicode.IsDigit(c) || (c == '.' && i+1 < n && unicode.IsDigit(rs[i+1])):
+			for i < n && (unicode.IsDigit(rs[i]) || rs[i] == '.' || rs[i] == 'e' || rs[i] == 'E' ||
+				((rs[i] == '-' || rs[i] == '+') && (rs[i-1] == 'e' || rs[i-1] == 'E'))) {
+				i++
+			}
+			toks = append(toks, sqlToken{tokNumber, string(rs[start:i]), start, i})
+			continue
+
+		case unicode.IsLetter(c) || c == '_':
+			for i < n && (unicode.IsLetter(rs[i]) || unicode.IsDigit(rs[i]) || rs[i] == '_' || rs[i] == '$') {
+				i++
+			}
+			// E'…', B'…', X'…' and N'…' are prefixed string literals.
+			if i-start == 1 && i < n && rs[i] == '\'' && strings.ContainsRune("EeBbXxNn", c) {
+				i = scanQuoted(rs, i, '\'', c == 'E' || c == 'e')
+				toks = append(toks, sqlToken{tokString, string(rs[start:i]), start, i})
+				continue
+			}
+			toks = append(toks, sqlToken{tokWord, string(rs[start:i]), start, i})
+			continue
+
+		case c == ':' && i+1 < n && rs[i+1] == ':':
+			i += 2
+			toks = append(toks, sqlToken{tokOp, "::", start, i})
+			continue
+
+		case c == ':' && i+1 < n && (unicode.IsLetter(rs[i+1]) || rs[i+1] == '_'):
+			// psql-style :variable, expanded before the query runs
+			i++
+			for i < n && (unicode.IsLetter(rs[i]) || unicode.IsDigit(rs[i]) || rs[i] == '_') {
+				i++
+			}
+			toks = append(toks, sqlToken{tokWord, string(rs[start:i]), start, i})
+			continue
+
+		case strings.ContainsRune("(),;[].", c):
+			i++
+			toks = append(toks, sqlToken{tokPunct, string(c), start, i})
+			continue
+		}
+
+		// Operators: a run of operator characters, stopping before a
+		// comment that follows without a space.
+		for i < n && strings.ContainsRune("+-*/<>=~!@#%^&|`?:", rs[i]) {
+			if i > start && ((rs[i] == '-' && i+1 < n && rs[i+1] == '-') || (rs[i] == '/' && i+1 < n && rs[i+1] == '*')) {
+				break
+			}
+			i++
+		}
+		if i == start {
+			i++ // anything else passes through as a one-character operator
+		}
+		toks = append(toks, sqlToken{tokOp, string(rs[start:i]), start, i})
+	}
+	return toks
+}
+
+// scanQuoted returns the index after the literal opening at rs[i], where
+// a doubled quote character is an escaped quote, and in E'…' strings
+// (backslash) so is a backslash-escaped one.
+func scanQuoted(rs []rune, i int, quote rune, backslash bool) int {
+	for i++; i < len(rs); i++ {
+		if backslash && rs[i] == '\\' {
+			i++
+			continue
+		}
+		if rs[i] == quote {
+			if i+1 < len(rs) && rs[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return len(rs)
+}
+
+// scanUntil returns the index after the first end found at or after
+// rs[i], or len(rs) if the literal or comment is unterminated.
+func scanUntil(rs []rune, i int, end []rune) int {
+	for ; i+len(end) <= len(rs); i++ {
+		if string(rs[i:i+len(end)]) == string(end) {
+			return i + len(end)
+		}
+	}
+	return len(rs)
+}
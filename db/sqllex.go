@@ -0,0 +1,60 @@
+// sqllex.go exposes the FormatSQL tokenizer for syntax highlighting: the
+// text is split into classified spans that together reproduce it exactly,
+// whitespace and all.
+package db
+
+// SQLClass classifies a span of SQL text.
+type SQLClass int
+
+const (
+	SQLPlain   SQLClass = iota // identifiers, operators, punctuation, whitespace
+	SQLKeyword                 // a word of sqlKeywords (see keywordAt)
+	SQLString                  // 'literal', E'…', $$…$$
+	SQLNumber                  // 42, 3.14, 1e6
+	SQLComment                 // -- … and /* … */
+)
+
+// SQLSpan is a run of SQL text of one class.
+type SQLSpan struct {
+	Class SQLClass
+	Text  string
+}
+
+// LexSQL splits sql into spans for highlighting. Joining the span texts
+// gives sql back unchanged; adjacent spans of the same class are merged.
+func LexSQL(sql string) []SQLSpan {
+	rs := []rune(sql)
+	toks := tokenizeSQL(sql)
+	var spans []SQLSpan
+	add := func(class SQLClass, text string) {
+		if text == "" {
+			return
+		}
+		if n := len(spans); n > 0 && spans[n-1].Class == class {
+			spans[n-1].Text += text
+			return
+		}
+		spans = append(spans, SQLSpan{class, text})
+	}
+	pos := 0
+	for i, t := range toks {
+		add(SQLPlain, string(rs[pos:t.start]))
+		class := SQLPlain
+		switch t.kind {
+		case tokWord:
+			if keywordAt(toks, i) {
+				class = SQLKeyword
+			}
+		case tokString:
+			class = SQLString
+		case tokNumber:
+			class = SQLNumber
+		case tokComment, tokLineComment:
+			class = SQLComment
+		}
+		add(class, string(rs[t.start:t.end]))
+		pos = t.end
+	}
+	add(SQLPlain, string(rs[pos:]))
+	return spans
+}
@@ -0,0 +1,108 @@
+// sqlstyle.go rewrites generated SQL in the configured SQL style, so
+// query plans, pagination queries and DDL read the same whichever code
+// produced them. Only keywords and identifiers change; spacing, line
+// breaks, literals and comments are kept.
+package db
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/DachengChen/paiSQL/config"
+)
+
+// plainIdentifier is a name PostgreSQL reads the same quoted or not.
+var plainIdentifier = regexp.MustCompile(`^[a-z_][a-z0-9_$]*$`)
+
+// reservedWords can't be used as bare column or table names (PostgreSQL's
+// reserved and column-name keywords), so their quotes are kept.
+var reservedWords = map[string]bool{}
+
+func init() {
+	for _, w := range strings.Fields(`
+		ALL ANALYSE ANALYZE AND ANY ARRAY AS ASC ASYMMETRIC AUTHORIZATION BETWEEN
+		BIGINT BINARY BIT BOOLEAN BOTH CASE CAST CHAR CHARACTER CHECK COALESCE
+		COLLATE COLLATION COLUMN CONCURRENTLY CONSTRAINT CREATE CROSS
+		CURRENT_CATALOG CURRENT_DATE CURRENT_ROLE CURRENT_SCHEMA CURRENT_TIME
+		CURRENT_TIMESTAMP CURRENT_USER DEC DECIMAL DEFAULT DEFERRABLE DESC
+		DISTINCT DO ELSE END EXCEPT EXISTS EXTRACT FALSE FETCH FLOAT FOR FOREIGN
+		FREEZE FROM FULL GRANT GREATEST GROUP GROUPING HAVING ILIKE IN INITIALLY
+		INNER INOUT INT INTEGER INTERSECT INTERVAL INTO IS ISNULL JOIN LATERAL
+		LEADING LEAST LEFT LIKE LIMIT LOCALTIME LOCALTIMESTAMP NATIONAL NATURAL
+		NCHAR NONE NORMALIZE NOT NOTNULL NULL NULLIF NUMERIC OFFSET ON ONLY OR
+		ORDER OUT OUTER OVERLAPS OVERLAY PLACING POSITION PRECISION PRIMARY REAL
+		REFERENCES RETURNING RIGHT ROW SELECT SESSION_USER SETOF SIMILAR SMALLINT
+		SOME SUBSTRING SYMMETRIC SYSTEM_USER TABLE TABLESAMPLE THEN TIME TIMESTAMP
+		TO TRAILING TREAT TRIM TRUE UNION UNIQUE USER USING VALUES VARCHAR
+		VARIADIC VERBOSE WHEN WHERE WINDOW WITH`) {
+		reservedWords[w] = true
+	}
+}
+
+// relationKeywords are followed by a table name, which QuoteAlways quotes
+// even when it isn't schema-qualified.
+var relationKeywords = map[string]bool{
+	"FROM": true, "JOIN": true, "UPDATE": true, "INTO": true, "TABLE": true, "ONLY": true,
+	"TRUNCATE": true, "ANALYZE": true, "VACUUM": true, "CLUSTER": true, "CONCURRENTLY": true,
+}
+
+// ApplySQLStyle rewrites sql in style. Keywords take the configured case.
+// With QuoteMinimal, quotes are dropped from names that don't need them;
+// with QuoteAlways, table names and schema- or table-qualified references
+// are quoted (expressions are left as written, since a quoted type or
+// function name would mean something else); QuoteKeep changes no names.
+func ApplySQLStyle(sql string, style config.SQLStyleConfig) string {
+	rs := []rune(sql)
+	toks := tokenizeSQL(sql)
+	var sb strings.Builder
+	last := 0
+	for i, tok := range toks {
+		sb.WriteString(string(rs[last:tok.start]))
+		last = tok.end
+		text := string(rs[tok.start:tok.end])
+
+		switch tok.kind {
+		case tokWord:
+			switch {
+			case keywordAt(toks, i):
+				if style.KeywordCase == config.KeywordLower {
+					text = strings.ToLower(text)
+				} else {
+					text = strings.ToUpper(text)
+				}
+			case style.QuoteIdentifiers == config.QuoteAlways && isRelationName(toks, i):
+				text = `"` + strings.ToLower(text) + `"`
+			}
+
+		case tokQuoted:
+			if style.QuoteIdentifiers == "" || style.QuoteIdentifiers == config.QuoteMinimal {
+				inner := text[1 : len(text)-1]
+				if plainIdentifier.MatchString(inner) && !reservedWords[strings.ToUpper(inner)] && !sqlKeywords[strings.ToUpper(inner)] {
+					text = inner
+				}
+			}
+		}
+		sb.WriteString(text)
+	}
+	sb.WriteString(string(rs[last:]))
+	return sb.String()
+}
+
+// isRelationName reports whether the bare word at toks[i] names a table
+// or is part of a qualified name such as schema.table or t.column.
+func isRelationName(toks []sqlToken, i int) bool {
+	w := toks[i].text
+	if w[0] == '$' || w[0] == ':' {
+		return false // parameter or psql variable
+	}
+	if i+1 < len(toks) && toks[i+1].text == "(" {
+		return false // function call
+	}
+	if i > 0 && toks[i-1].text == "::" {
+		return false // type name
+	}
+	if (i > 0 && toks[i-1].text == ".") || (i+1 < len(toks) && toks[i+1].text == ".") {
+		return true
+	}
+	return i > 0 && toks[i-1].kind == tokWord && relationKeywords[strings.ToUpper(toks[i-1].text)]
+}
@@ -0,0 +1,109 @@
+// temp_spill.go reads how much sorts, hashes and other operations spill to
+// temporary files for lack of work_mem: the temp files written per
+// database, and with pg_stat_statements the statements writing them.
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	pgx "github.com/jackc/pgx/v5"
+)
+
+// spillStatementLimit is the number of spilling statements read.
+const spillStatementLimit = 10
+
+// TempUsage is the temporary file usage of a database since its
+// statistics were reset.
+type TempUsage struct {
+	Database   string
+	TempFiles  int64
+	TempBytes  int64
+	StatsReset *time.Time // nil if never reset
+}
+
+// SpillingStatement is a statement that wrote temporary files.
+type SpillingStatement struct {
+	Query      string // normalized, whitespace collapsed, shortened
+	Calls      int64
+	TempBytes  int64   // written across all calls
+	MeanTimeMS float64 // mean execution time
+}
+
+// SpillReport is the temporary file usage of the server.
+type SpillReport struct {
+	WorkMem        string // current work_mem, e.g. "4MB"
+	MaxConnections string
+	LogTempFiles   string // log_temp_files, "-1" when off
+	Databases      []TempUsage
+
+	// Statements of the current database, largest spill first; nil
+	// without pg_stat_statements.
+	Statements    []SpillingStatement
+	HasStatements bool
+}
+
+// FetchSpillReport reads the temporary file usage per database and, when
+// ext has pg_stat_statements, the statements spilling most in the current
+// database. If only the statements can't be read (pg_stat_statements
+// installed but not in shared_preload_libraries, say), the report comes
+// back along with the error.
+func (d *DB) FetchSpillReport(ctx context.Context, ext *ExtensionStats) (*SpillReport, error) {
+	r := &SpillReport{}
+	if err := d.Pool.QueryRow(ctx,
+		"SELECT current_setting('work_mem'), current_setting('max_connections'), current_setting('log_temp_files')").
+		Scan(&r.WorkMem, &r.MaxConnections, &r.LogTempFiles); err != nil {
+		return nil, err
+	}
+
+	rows, err := d.Pool.Query(ctx, `
+		SELECT datname, temp_files, temp_bytes, stats_reset
+		FROM pg_stat_database
+		WHERE datname IS NOT NULL AND temp_files > 0
+		ORDER BY temp_bytes DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var u TempUsage
+		if err := rows.Scan(&u.Database, &u.TempFiles, &u.TempBytes, &u.StatsReset); err != nil {
+			return nil, err
+		}
+		r.Databases = append(r.Databases, u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	pss, ok := ext.Extensions["pg_stat_statements"]
+	if !ok {
+		return r, nil
+	}
+	r.HasStatements = true
+	rows, err = d.Pool.Query(ctx, fmt.Sprintf(`
+		SELECT left(regexp_replace(query, '\s+', ' ', 'g'), 200), calls,
+		       temp_blks_written * current_setting('block_size')::bigint,
+		       mean_exec_time
+		FROM %s
+		WHERE temp_blks_written > 0
+		  AND dbid = (SELECT oid FROM pg_database WHERE datname = current_database())
+		ORDER BY temp_blks_written DESC
+		LIMIT %d`, pgx.Identifier{pss.Schema, "pg_stat_statements"}.Sanitize(), spillStatementLimit))
+	if err != nil {
+		return r, fmt.Errorf("pg_stat_statements: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var s SpillingStatement
+		if err := rows.Scan(&s.Query, &s.Calls, &s.TempBytes, &s.MeanTimeMS); err != nil {
+			return r, fmt.Errorf("pg_stat_statements: %w", err)
+		}
+		r.Statements = append(r.Statements, s)
+	}
+	if err := rows.Err(); err != nil {
+		return r, fmt.Errorf("pg_stat_statements: %w", err)
+	}
+	return r, nil
+}
@@ -0,0 +1,52 @@
+package db
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/DachengChen/paiSQL/applog"
+	"github.com/jackc/pgx/v5"
+)
+
+// tracedSQLLength is how much of a statement's text is logged.
+const tracedSQLLength = 300
+
+// queryTracer logs every statement run on the pool, with its duration and
+// outcome, to the app log at debug level (paisql --debug). It shows what
+// the TUI really sends, e.g. how many queries one describe triggers.
+// Arguments are counted but not logged: they may hold secrets.
+type queryTracer struct{}
+
+type traceStartKey struct{}
+
+type traceStart struct {
+	at   time.Time
+	sql  string
+	args int
+}
+
+func (queryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	if !applog.DebugEnabled() {
+		return ctx
+	}
+	return context.WithValue(ctx, traceStartKey{}, traceStart{at: time.Now(), sql: data.SQL, args: len(data.Args)})
+}
+
+func (queryTracer) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryEndData) {
+	start, ok := ctx.Value(traceStartKey{}).(traceStart)
+	if !ok {
+		return
+	}
+	elapsed := time.Since(start.at).Round(time.Microsecond)
+	sql := []rune(strings.Join(strings.Fields(start.sql), " "))
+	if len(sql) > tracedSQLLength {
+		sql = append(sql[:tracedSQLLength-1], '…')
+	}
+	pid := conn.PgConn().PID()
+	if data.Err != nil {
+		applog.Debug("SQL pid=%d %s args=%d error=%q: %s", pid, elapsed, start.args, data.Err.Error(), string(sql))
+		return
+	}
+	applog.Debug("SQL pid=%d %s args=%d %q: %s", pid, elapsed, start.args, data.CommandTag.String(), string(sql))
+}
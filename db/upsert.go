@@ -0,0 +1,204 @@
+// upsert.go turns a result into INSERT ... ON CONFLICT statements for a
+// table in another database, to copy rows between environments.
+//
+// The source query is run again, in a read-only transaction so a data
+// change is never repeated, and with the simple protocol, so every value
+// arrives in PostgreSQL's text output format and is written back as a
+// quoted literal that the target casts to the column's type.
+package db
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// upsertBatchRows is the number of rows per INSERT statement.
+const upsertBatchRows = 100
+
+// UpsertPlan is the reviewed script that copies rows into Table.
+type UpsertPlan struct {
+	Connection string   // saved connection the script runs on
+	Table      string   // target table, quoted
+	Columns    []string // columns written, from the source query
+	Conflict   []string // ON CONFLICT columns; empty inserts without it
+	Statements []string // one per batch of rows, in execution order
+	Rows       int
+	Warnings   []string
+}
+
+// NewUpsertPlan runs sql on source and builds statements that write its
+// rows into schema.table on target. conflict names the ON CONFLICT
+// columns; when empty, the target table's primary key is used.
+func NewUpsertPlan(ctx context.Context, source, target *DB, connection, sql, schema, table string, conflict []string) (*UpsertPlan, error) {
+	qualified := pgx.Identifier{table}.Sanitize()
+	if schema != "" {
+		qualified = pgx.Identifier{schema, table}.Sanitize()
+	}
+	targetCols, pk, err := target.upsertTarget(ctx, qualified)
+	if err != nil {
+		return nil, fmt.Errorf("%s on %s: %w", qualified, connection, err)
+	}
+
+	tx, err := source.Pool.BeginTx(ctx, pgx.TxOptions{AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+	rows, err := tx.Query(ctx, sql, pgx.QueryExecModeSimpleProtocol)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	p := &UpsertPlan{Connection: connection, Table: qualified}
+	for _, fd := range rows.FieldDescriptions() {
+		p.Columns = append(p.Columns, fd.Name)
+	}
+	var missing []string
+	for _, col := range p.Columns {
+		if !slices.Contains(targetCols, col) {
+			missing = append(missing, col)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%s on %s has no column %s", qualified, connection, strings.Join(missing, ", "))
+	}
+
+	p.Conflict = conflict
+	if len(p.Conflict) == 0 {
+		p.Conflict = pk
+	}
+	for _, col := range p.Conflict {
+		if !slices.Contains(p.Columns, col) {
+			return nil, fmt.Errorf("conflict column %s is not in the result", col)
+		}
+	}
+	if len(p.Conflict) == 0 {
+		p.Warnings = append(p.Warnings,
+			"the target table has no primary key: rows are inserted without ON CONFLICT and may be duplicated.")
+	}
+
+	var values []string
+	for rows.Next() {
+		raw := rows.RawValues()
+		cells := make([]string, len(raw))
+		for i, b := range raw {
+			if b == nil {
+				cells[i] = "NULL"
+			} else {
+				cells[i] = quoteLiteral(string(b))
+			}
+		}
+		values = append(values, "("+strings.Join(cells, ", ")+")")
+		p.Rows++
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for batch := range slices.Chunk(values, upsertBatchRows) {
+		p.Statements = append(p.Statements, p.insert(batch))
+	}
+	return p, nil
+}
+
+// insert returns the statement for one batch of VALUES rows.
+func (p *UpsertPlan) insert(values []string) string {
+	cols := make([]string, len(p.Columns))
+	for i, c := range p.Columns {
+		cols[i] = ident(c)
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "INSERT INTO %s (%s) VALUES\n  %s", p.Table, strings.Join(cols, ", "), strings.Join(values, ",\n  "))
+	if len(p.Conflict) == 0 {
+		return sb.String()
+	}
+
+	keys := make([]string, len(p.Conflict))
+	for i, c := range p.Conflict {
+		keys[i] = ident(c)
+	}
+	var set []string
+	for _, c := range p.Columns {
+		if !slices.Contains(p.Conflict, c) {
+			set = append(set, fmt.Sprintf("%s = EXCLUDED.%s", ident(c), ident(c)))
+		}
+	}
+	fmt.Fprintf(&sb, "\nON CONFLICT (%s) ", strings.Join(keys, ", "))
+	if len(set) == 0 {
+		sb.WriteString("DO NOTHING")
+	} else {
+		sb.WriteString("DO UPDATE SET " + strings.Join(set, ", "))
+	}
+	return sb.String()
+}
+
+// Script returns the statements as one SQL script.
+func (p *UpsertPlan) Script() string {
+	return strings.Join(p.Statements, ";\n\n") + ";\n"
+}
+
+// upsertTarget returns the columns and primary key of a table.
+func (d *DB) upsertTarget(ctx context.Context, table string) (columns, pk []string, err error) {
+	var exists bool
+	if err := d.Pool.QueryRow(ctx, "SELECT to_regclass($1) IS NOT NULL", table).Scan(&exists); err != nil {
+		return nil, nil, err
+	}
+	if !exists {
+		return nil, nil, fmt.Errorf("table not found")
+	}
+
+	rows, err := d.Pool.Query(ctx, `
+		SELECT attname FROM pg_attribute
+		WHERE attrelid = to_regclass($1) AND attnum > 0 AND NOT attisdropped
+		ORDER BY attnum`, table)
+	if err != nil {
+		return nil, nil, err
+	}
+	if columns, err = scanNames(rows); err != nil {
+		return nil, nil, err
+	}
+
+	pk, err = d.PrimaryKey(ctx, table)
+	return columns, pk, err
+}
+
+// scanNames reads a single text column from every row.
+func scanNames(rows pgx.Rows) ([]string, error) {
+	defer rows.Close()
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+// ApplyUpsertPlan runs the plan's statements in a single transaction and
+// returns the number of rows inserted or updated.
+func (d *DB) ApplyUpsertPlan(ctx context.Context, p *UpsertPlan) (int64, error) {
+	tx, err := d.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	var n int64
+	for i, stmt := range p.Statements {
+		tag, err := tx.Exec(ctx, stmt)
+		if err != nil {
+			return 0, fmt.Errorf("statement %d: %w", i+1, err)
+		}
+		n += tag.RowsAffected()
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
@@ -0,0 +1,68 @@
+// vector.go adds pgvector helpers: type detection, compact value
+// summaries, and a nearest-neighbor query builder.
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// IsVectorType reports whether a pg_type name or formatted data type
+// (e.g. "vector", "vector(1536)", "halfvec(768)") is a pgvector type.
+func IsVectorType(typeName string) bool {
+	for _, prefix := range []string{"vector", "halfvec", "sparsevec"} {
+		if typeName == prefix || strings.HasPrefix(typeName, prefix+"(") {
+			return true
+		}
+	}
+	return false
+}
+
+// SummarizeVector renders a pgvector text value ("[0.1,0.2,...]") as
+// "vec(1536)[0.1, 0.2, 0.3, …]" showing at most n leading elements.
+// Values that don't look like a dense vector are returned unchanged.
+func SummarizeVector(value string, n int) string {
+	if !strings.HasPrefix(value, "[") || !strings.HasSuffix(value, "]") {
+		return value
+	}
+	elems := strings.Split(value[1:len(value)-1], ",")
+	head := elems
+	if len(head) > n {
+		head = head[:n]
+	}
+	summary := fmt.Sprintf("vec(%d)[%s", len(elems), strings.Join(head, ", "))
+	if len(elems) > n {
+		summary += ", …"
+	}
+	return summary + "]"
+}
+
+// NearestNeighbors returns the limit rows of schema.table closest to vec
+// by L2 distance on column (ORDER BY column <-> $1), including the
+// distance. An empty schema resolves through the search path.
+func (d *DB) NearestNeighbors(ctx context.Context, schema, table, column, vec string, limit int) (*QueryResult, error) {
+	return d.executeQuery(ctx, nearestNeighborsSQL(schema, table, column, "$1", limit), vec)
+}
+
+// NearestNeighborsSQL returns the SQL text NearestNeighbors runs, with the
+// vector inlined, for display and copying.
+func NearestNeighborsSQL(schema, table, column, vec string, limit int) string {
+	return nearestNeighborsSQL(schema, table, column, quoteLiteral(vec), limit) + ";"
+}
+
+// nearestNeighborsSQL builds the nearest-neighbor query with vec, a bind
+// parameter or a quoted literal, as the query vector.
+func nearestNeighborsSQL(schema, table, column, vec string, limit int) string {
+	if limit <= 0 {
+		limit = 10
+	}
+	rel := pgx.Identifier{table}.Sanitize()
+	if schema != "" {
+		rel = pgx.Identifier{schema, table}.Sanitize()
+	}
+	return fmt.Sprintf("SELECT *, %s <-> %s::vector AS distance FROM %s ORDER BY %s <-> %s::vector LIMIT %d",
+		ident(column), vec, rel, ident(column), vec, limit)
+}
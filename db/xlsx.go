@@ -0,0 +1,223 @@
+// xlsx.go writes a query result as an Excel workbook. The file is a
+// minimal SpreadsheetML package built with archive/zip: one sheet with a
+// bold, frozen header row, columns sized to their contents, and typed
+// cells — numbers, booleans and dates are written as such so they sort
+// and sum in Excel; everything else is text.
+package db
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Excel's limits.
+const (
+	xlsxMaxRows     = 1 << 20
+	xlsxMaxCellText = 32767
+	xlsxMaxDigits   = 15 // integers longer than this lose precision as numbers
+)
+
+// valueTimeLayout is how FormatValue renders time.Time values.
+const valueTimeLayout = "2006-01-02 15:04:05.999999999 -0700 MST"
+
+// nullValue is how FormatValue renders NULL.
+const nullValue = "<nil>"
+
+// Cell styles, indexes into cellXfs in xlsxStyles.
+const (
+	xlsxStyleDate     = 1
+	xlsxStyleDateTime = 2
+	xlsxStyleHeader   = 3
+)
+
+// WriteXLSX writes r to w as an .xlsx workbook with a single sheet.
+func WriteXLSX(w io.Writer, r *QueryResult) error {
+	if len(r.Columns) == 0 {
+		return fmt.Errorf("the result has no columns")
+	}
+	if len(r.Rows)+1 > xlsxMaxRows {
+		return fmt.Errorf("%d rows exceed Excel's limit of %d", len(r.Rows), xlsxMaxRows-1)
+	}
+
+	z := zip.NewWriter(w)
+	files := []struct{ name, body string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRels},
+		{"xl/workbook.xml", xlsxWorkbook},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/styles.xml", xlsxStyles},
+		{"xl/worksheets/sheet1.xml", xlsxSheet(r)},
+	}
+	for _, f := range files {
+		fw, err := z.Create(f.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(fw, f.body); err != nil {
+			return err
+		}
+	}
+	return z.Close()
+}
+
+// xlsxSheet renders the worksheet XML.
+func xlsxSheet(r *QueryResult) string {
+	types := make([]string, len(r.Columns))
+	copy(types, r.ColumnTypes)
+
+	widths := make([]int, len(r.Columns))
+	for i, col := range r.Columns {
+		widths[i] = utf8.RuneCountInString(col)
+	}
+	for _, row := range r.Rows {
+		for i, cell := range row {
+			if i >= len(widths) || cell == nullValue {
+				continue
+			}
+			n := utf8.RuneCountInString(cell)
+			switch types[i] {
+			case "date":
+				n = 10
+			case "timestamp", "timestamptz":
+				n = 19
+			}
+			widths[i] = max(widths[i], n)
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(xml.Header)
+	sb.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	sb.WriteString(`<sheetViews><sheetView workbookViewId="0">` +
+		`<pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/>` +
+		`</sheetView></sheetViews>`)
+	sb.WriteString("<cols>")
+	for i, w := range widths {
+		fmt.Fprintf(&sb, `<col min="%d" max="%d" width="%d" customWidth="1"/>`, i+1, i+1, min(max(w, 6), 60)+2)
+	}
+	sb.WriteString("</cols><sheetData>")
+
+	sb.WriteString(`<row r="1">`)
+	for i, col := range r.Columns {
+		writeXLSXText(&sb, xlsxCellRef(i, 1), col, xlsxStyleHeader)
+	}
+	sb.WriteString("</row>")
+	for n, row := range r.Rows {
+		fmt.Fprintf(&sb, `<row r="%d">`, n+2)
+		for i, cell := range row {
+			if i < len(types) && cell != nullValue {
+				writeXLSXCell(&sb, xlsxCellRef(i, n+2), types[i], cell)
+			}
+		}
+		sb.WriteString("</row>")
+	}
+	sb.WriteString("</sheetData></worksheet>")
+	return sb.String()
+}
+
+// writeXLSXCell writes one value, typed by its pg_type name.
+func writeXLSXCell(sb *strings.Builder, ref, colType, cell string) {
+	switch colType {
+	case "int2", "int4", "int8", "float4", "float8", "numeric":
+		digits := strings.TrimLeft(cell, "-")
+		f, err := strconv.ParseFloat(cell, 64)
+		if err == nil && !math.IsInf(f, 0) && !math.IsNaN(f) &&
+			(colType != "int8" && colType != "numeric" || len(digits) <= xlsxMaxDigits) {
+			fmt.Fprintf(sb, `<c r="%s"><v>%s</v></c>`, ref, cell)
+			return
+		}
+	case "bool":
+		if b, err := strconv.ParseBool(cell); err == nil {
+			v := 0
+			if b {
+				v = 1
+			}
+			fmt.Fprintf(sb, `<c r="%s" t="b"><v>%d</v></c>`, ref, v)
+			return
+		}
+	case "date", "timestamp", "timestamptz":
+		if t, err := time.Parse(valueTimeLayout, cell); err == nil {
+			style := xlsxStyleDateTime
+			if colType == "date" {
+				style = xlsxStyleDate
+			}
+			fmt.Fprintf(sb, `<c r="%s" s="%d"><v>%s</v></c>`, ref, style, xlsxSerial(t))
+			return
+		}
+	}
+	writeXLSXText(sb, ref, cell, 0)
+}
+
+// writeXLSXText writes an inline string cell, truncated to Excel's limit.
+func writeXLSXText(sb *strings.Builder, ref, text string, style int) {
+	if utf8.RuneCountInString(text) > xlsxMaxCellText {
+		text = string([]rune(text)[:xlsxMaxCellText])
+	}
+	fmt.Fprintf(sb, `<c r="%s" t="inlineStr"`, ref)
+	if style != 0 {
+		fmt.Fprintf(sb, ` s="%d"`, style)
+	}
+	sb.WriteString(`><is><t xml:space="preserve">`)
+	_ = xml.EscapeText(sb, []byte(text))
+	sb.WriteString("</t></is></c>")
+}
+
+// xlsxSerial converts t's wall clock time to an Excel date serial: days
+// since 1899-12-30, with the time of day as the fraction.
+func xlsxSerial(t time.Time) string {
+	wall := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+	days := wall.Sub(time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)).Hours() / 24
+	return strconv.FormatFloat(days, 'f', -1, 64)
+}
+
+// xlsxCellRef returns the A1 reference of a 0-based column and 1-based row.
+func xlsxCellRef(col, row int) string {
+	name := ""
+	for col++; col > 0; col = (col - 1) / 26 {
+		name = string(rune('A'+(col-1)%26)) + name
+	}
+	return name + strconv.Itoa(row)
+}
+
+const xlsxContentTypes = xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+	`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+	`<Default Extension="xml" ContentType="application/xml"/>` +
+	`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+	`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+	`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+	`</Types>`
+
+const xlsxRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+const xlsxWorkbook = xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+	`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+	`<sheets><sheet name="Result" sheetId="1" r:id="rId1"/></sheets></workbook>`
+
+const xlsxWorkbookRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+	`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+	`</Relationships>`
+
+// xlsxStyles defines the default style, a date, a date-time and a bold
+// header style, in that order (see xlsxStyleDate and friends).
+const xlsxStyles = xml.Header + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<numFmts count="1"><numFmt numFmtId="164" formatCode="yyyy-mm-dd hh:mm:ss"/></numFmts>` +
+	`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="4">` +
+	`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="14" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>` +
+	`</cellXfs></styleSheet>`
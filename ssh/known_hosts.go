@@ -0,0 +1,144 @@
+// known_hosts.go verifies the SSH server's host key against
+// ~/.ssh/known_hosts, the file OpenSSH uses, so hosts already trusted in
+// a terminal are trusted here too.
+//
+// An unknown host fails with UnknownHostError, which carries the offered
+// key for the caller to show and, once the user accepts it, pass to
+// AddKnownHost. A key that differs from the recorded one fails with
+// HostKeyChangedError and is never accepted from here.
+package ssh
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// UnknownHostError is returned when the SSH host has no key in
+// known_hosts yet.
+type UnknownHostError struct {
+	Host string // as dialed, host:port
+	Key  ssh.PublicKey
+}
+
+func (e *UnknownHostError) Error() string {
+	return fmt.Sprintf("ssh host %s is not in ~/.ssh/known_hosts (%s key %s)", e.Host, e.Key.Type(), e.Fingerprint())
+}
+
+// Fingerprint returns the key's SHA256 fingerprint as OpenSSH shows it.
+func (e *UnknownHostError) Fingerprint() string {
+	return ssh.FingerprintSHA256(e.Key)
+}
+
+// HostKeyChangedError is returned when the SSH host offers a key other
+// than the one in known_hosts.
+type HostKeyChangedError struct {
+	Host string
+	Key  ssh.PublicKey
+	File string // where the recorded key is
+	Line int
+}
+
+func (e *HostKeyChangedError) Error() string {
+	return fmt.Sprintf("ssh host key of %s changed: %s key %s doesn't match %s:%d — the connection may be intercepted, or the host was reinstalled (remove the old key with ssh-keygen -R)",
+		e.Host, e.Key.Type(), ssh.FingerprintSHA256(e.Key), e.File, e.Line)
+}
+
+// knownHostsPath returns the path of the user's known_hosts file.
+func knownHostsPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".ssh", "known_hosts"), nil
+}
+
+// verifyHostKey checks the host key against known_hosts. The file is read
+// on each connect, so a key accepted meanwhile counts.
+func verifyHostKey(hostname string, remote net.Addr, key ssh.PublicKey) error {
+	path, err := knownHostsPath()
+	if err != nil {
+		return err
+	}
+	check, err := knownhosts.New(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &UnknownHostError{Host: hostname, Key: key}
+	}
+	if err != nil {
+		return fmt.Errorf("read %s: %w", path, err)
+	}
+
+	err = check(hostname, remote, key)
+	var keyErr *knownhosts.KeyError
+	if errors.As(err, &keyErr) {
+		if len(keyErr.Want) == 0 {
+			return &UnknownHostError{Host: hostname, Key: key}
+		}
+		want := keyErr.Want[0]
+		return &HostKeyChangedError{Host: hostname, Key: key, File: want.Filename, Line: want.Line}
+	}
+	return err
+}
+
+// knownHostAlgorithms returns the host key algorithms of the keys recorded
+// for addr, so the server is asked for a key that can be checked rather
+// than one known_hosts has no entry of that type for. It is empty for
+// unknown hosts.
+func knownHostAlgorithms(addr string) []string {
+	path, err := knownHostsPath()
+	if err != nil {
+		return nil
+	}
+	check, err := knownhosts.New(path)
+	if err != nil {
+		return nil
+	}
+	// Checking a key no host has lists the recorded ones.
+	probe, err := ssh.NewPublicKey(ed25519.PublicKey(make([]byte, ed25519.PublicKeySize)))
+	if err != nil {
+		return nil
+	}
+	var keyErr *knownhosts.KeyError
+	if !errors.As(check(addr, &net.TCPAddr{IP: net.IPv4zero}, probe), &keyErr) {
+		return nil
+	}
+	var algos []string
+	for _, k := range keyErr.Want {
+		switch t := k.Key.Type(); t {
+		case ssh.KeyAlgoRSA:
+			algos = append(algos, ssh.KeyAlgoRSASHA512, ssh.KeyAlgoRSASHA256, ssh.KeyAlgoRSA)
+		default:
+			algos = append(algos, t)
+		}
+	}
+	return algos
+}
+
+// AddKnownHost records the key of an unknown host in known_hosts, as
+// OpenSSH does when a new host is accepted.
+func AddKnownHost(e *UnknownHostError) error {
+	path, err := knownHostsPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	line := knownhosts.Line([]string{knownhosts.Normalize(e.Host)}, e.Key)
+	if _, err := fmt.Fprintln(f, line); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
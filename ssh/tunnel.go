@@ -6,20 +6,27 @@
 //   - Allocates a random local port (":0") to avoid conflicts.
 //   - The tunnel runs in a background goroutine and is stopped
 //     via the Stop method (which closes the listener).
-//   - Only key-based authentication is supported (with optional passphrase).
+//   - Verifies the host key against ~/.ssh/known_hosts (known_hosts.go)
+//     unless the connection opts out with InsecureHostKey.
+//   - Authenticates with a key file (with optional passphrase), the keys of
+//     a running ssh-agent (SSH_AUTH_SOCK), or a password, which also
+//     answers keyboard-interactive prompts.
 package ssh
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net"
 	"os"
 	"strconv"
 	"sync"
+	"time"
 
 	"github.com/DachengChen/paiSQL/config"
 	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
 )
 
 // Addr represents host:port of the local tunnel endpoint.
@@ -31,8 +38,9 @@ type Addr struct {
 // Tunnel manages an SSH local port forward.
 type Tunnel struct {
 	sshConfig  *ssh.ClientConfig
-	sshAddr    string // e.g. "bastion:22"
-	remoteAddr string // e.g. "db-host:5432"
+	sshAddr    string   // e.g. "bastion:22"
+	remoteAddr string   // e.g. "db-host:5432"
+	agentConn  net.Conn // ssh-agent socket, open until the handshake is done
 
 	client   *ssh.Client
 	listener net.Listener
@@ -42,21 +50,28 @@ type Tunnel struct {
 
 // NewTunnel creates a tunnel configuration (does not connect yet).
 func NewTunnel(cfg config.SSHConfig, pgHost string, pgPort int) (*Tunnel, error) {
-	authMethods, err := buildAuthMethods(cfg)
+	authMethods, agentConn, err := buildAuthMethods(cfg)
 	if err != nil {
 		return nil, err
 	}
 
+	sshAddr := net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port))
 	sshConfig := &ssh.ClientConfig{
-		User:            cfg.User,
-		Auth:            authMethods,
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(), // TODO: proper host key verification
+		User:              cfg.User,
+		Auth:              authMethods,
+		HostKeyCallback:   verifyHostKey,
+		HostKeyAlgorithms: knownHostAlgorithms(sshAddr),
+	}
+	if cfg.InsecureHostKey {
+		sshConfig.HostKeyCallback = ssh.InsecureIgnoreHostKey()
+		sshConfig.HostKeyAlgorithms = nil
 	}
 
 	return &Tunnel{
 		sshConfig:  sshConfig,
-		sshAddr:    net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port)),
+		sshAddr:    sshAddr,
 		remoteAddr: net.JoinHostPort(pgHost, strconv.Itoa(pgPort)),
+		agentConn:  agentConn,
 		done:       make(chan struct{}),
 	}, nil
 }
@@ -65,7 +80,15 @@ func NewTunnel(cfg config.SSHConfig, pgHost string, pgPort int) (*Tunnel, error)
 // Returns the local address to connect pgx to.
 func (t *Tunnel) Start(ctx context.Context) (*Addr, error) {
 	var err error
+	if deadline, ok := ctx.Deadline(); ok {
+		t.sshConfig.Timeout = time.Until(deadline)
+	}
 	t.client, err = ssh.Dial("tcp", t.sshAddr, t.sshConfig)
+	if t.agentConn != nil {
+		// The agent is only needed to sign during authentication
+		t.agentConn.Close()
+		t.agentConn = nil
+	}
 	if err != nil {
 		return nil, fmt.Errorf("ssh dial %s: %w", t.sshAddr, err)
 	}
@@ -142,31 +165,61 @@ func (t *Tunnel) forward(localConn net.Conn) {
 	<-done
 }
 
-// buildAuthMethods creates SSH auth methods from config.
-func buildAuthMethods(cfg config.SSHConfig) ([]ssh.AuthMethod, error) {
-	var methods []ssh.AuthMethod
-
-	if cfg.KeyPath != "" {
-		keyBytes, err := os.ReadFile(cfg.KeyPath)
+// buildAuthMethods creates SSH auth methods from config. With ssh-agent
+// it also returns the agent connection, for the caller to close once
+// connected.
+func buildAuthMethods(cfg config.SSHConfig) ([]ssh.AuthMethod, net.Conn, error) {
+	switch cfg.Auth {
+	case config.SSHAuthAgent:
+		sock := os.Getenv("SSH_AUTH_SOCK")
+		if sock == "" {
+			return nil, nil, fmt.Errorf("ssh-agent: SSH_AUTH_SOCK is not set (is the agent running?)")
+		}
+		conn, err := net.Dial("unix", sock)
 		if err != nil {
-			return nil, fmt.Errorf("read ssh key %s: %w", cfg.KeyPath, err)
+			return nil, nil, fmt.Errorf("ssh-agent: %w", err)
 		}
+		return []ssh.AuthMethod{ssh.PublicKeysCallback(agent.NewClient(conn).Signers)}, conn, nil
 
-		var signer ssh.Signer
-		if cfg.KeyPassphrase != "" {
-			signer, err = ssh.ParsePrivateKeyWithPassphrase(keyBytes, []byte(cfg.KeyPassphrase))
-		} else {
-			signer, err = ssh.ParsePrivateKey(keyBytes)
+	case config.SSHAuthPassword:
+		if cfg.Password == "" {
+			return nil, nil, fmt.Errorf("no SSH password set")
 		}
-		if err != nil {
-			return nil, fmt.Errorf("parse ssh key: %w", err)
+		// Servers that disable plain password auth usually still ask for
+		// the password through keyboard-interactive.
+		interactive := func(user, instruction string, questions []string, echos []bool) ([]string, error) {
+			answers := make([]string, len(questions))
+			for i := range answers {
+				answers[i] = cfg.Password
+			}
+			return answers, nil
 		}
-		methods = append(methods, ssh.PublicKeys(signer))
+		return []ssh.AuthMethod{
+			ssh.Password(cfg.Password),
+			ssh.KeyboardInteractive(interactive),
+		}, nil, nil
 	}
 
-	if len(methods) == 0 {
-		return nil, fmt.Errorf("no SSH authentication methods configured (provide --ssh-key)")
+	if cfg.KeyPath == "" {
+		return nil, nil, fmt.Errorf("no SSH key file set")
+	}
+	keyBytes, err := os.ReadFile(cfg.KeyPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read ssh key %s: %w", cfg.KeyPath, err)
 	}
 
-	return methods, nil
+	var signer ssh.Signer
+	if cfg.KeyPassphrase != "" {
+		signer, err = ssh.ParsePrivateKeyWithPassphrase(keyBytes, []byte(cfg.KeyPassphrase))
+	} else {
+		signer, err = ssh.ParsePrivateKey(keyBytes)
+	}
+	var missing *ssh.PassphraseMissingError
+	if errors.As(err, &missing) {
+		return nil, nil, fmt.Errorf("ssh key %s is encrypted: set its passphrase, or use ssh-agent", cfg.KeyPath)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("parse ssh key: %w", err)
+	}
+	return []ssh.AuthMethod{ssh.PublicKeys(signer)}, nil, nil
 }
@@ -0,0 +1,164 @@
+// ai_ollama.go adds Ollama model management to the AI view: /models
+// lists the installed models and /pull downloads one, with progress, so
+// a configured model that isn't installed yet can be fetched in place.
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/DachengChen/paiSQL/ai"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ollamaCommand runs /models or /pull [model]. It reports false for
+// other input, which is sent to the AI as usual.
+func (v *AIView) ollamaCommand(text string) (tea.Cmd, bool) {
+	fields := strings.Fields(text)
+	if len(fields) == 0 || (fields[0] != "/models" && fields[0] != "/pull") {
+		return nil, false
+	}
+	v.input.Reset()
+	o, ok := v.provider.(*ai.Ollama)
+	if !ok {
+		v.info = []string{StyleError.Render(fields[0] + " is only available with the Ollama provider")}
+		v.refresh()
+		return nil, true
+	}
+
+	if fields[0] == "/models" {
+		v.info = []string{StyleDimmed.Render("Listing installed models…")}
+		v.refresh()
+		return listOllamaModels(o, false), true
+	}
+
+	if v.pulling != "" {
+		v.info = append(v.info, StyleWarning.Render("Already pulling "+v.pulling))
+		v.refresh()
+		return nil, true
+	}
+	model := o.Model()
+	if len(fields) > 1 {
+		model = fields[1]
+	}
+	v.pulling, v.pullStatus = model, ai.PullProgress{Status: "starting"}
+	v.info = nil
+	v.refresh()
+	return pullOllamaModel(o, model), true
+}
+
+// listOllamaModels fetches the installed models. A quiet listing only
+// reports a missing configured model.
+func listOllamaModels(o *ai.Ollama, quiet bool) tea.Cmd {
+	return func() tea.Msg {
+		models, err := o.Models(context.Background())
+		return OllamaModelsMsg{Models: models, Quiet: quiet, Err: err}
+	}
+}
+
+// pullOllamaModel starts downloading model and returns its first
+// progress message.
+func pullOllamaModel(o *ai.Ollama, model string) tea.Cmd {
+	return func() tea.Msg {
+		s := &pullStream{model: model, updates: make(chan ai.PullProgress, 64)}
+		go func() {
+			defer close(s.updates)
+			s.err = o.Pull(context.Background(), model, func(p ai.PullProgress) {
+				s.updates <- p
+			})
+		}()
+		return s.next()
+	}
+}
+
+// pullStream is a model download in progress. err is set before
+// updates is closed.
+type pullStream struct {
+	model   string
+	updates chan ai.PullProgress
+	err     error
+}
+
+// next waits for the latest progress update or the end of the download.
+func (s *pullStream) next() tea.Msg {
+	p, ok := <-s.updates
+	if !ok {
+		return OllamaPullDoneMsg{Model: s.model, Err: s.err}
+	}
+	for len(s.updates) > 0 {
+		p = <-s.updates
+	}
+	return OllamaPullMsg{Model: s.model, Progress: p, next: s.next}
+}
+
+// updateOllama handles the model management messages.
+func (v *AIView) updateOllama(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case OllamaModelsMsg:
+		v.info = v.renderModels(msg)
+	case OllamaPullMsg:
+		v.pullStatus = msg.Progress
+		v.refresh()
+		return msg.next
+	case OllamaPullDoneMsg:
+		v.pulling = ""
+		if msg.Err != nil {
+			v.info = []string{StyleError.Render("Pull failed: " + msg.Err.Error())}
+		} else {
+			v.info = []string{StyleSuccess.Render("✔ Pulled " + msg.Model)}
+		}
+	}
+	v.refresh()
+	return nil
+}
+
+// renderModels lists the installed models, marking the configured one.
+func (v *AIView) renderModels(msg OllamaModelsMsg) []string {
+	o, ok := v.provider.(*ai.Ollama)
+	if !ok {
+		return nil
+	}
+	if msg.Err != nil {
+		if msg.Quiet {
+			return nil
+		}
+		return []string{StyleError.Render("Listing models failed: " + msg.Err.Error())}
+	}
+
+	installed := ai.HasModel(msg.Models, o.Model())
+	if msg.Quiet {
+		if installed {
+			return nil
+		}
+		return []string{StyleWarning.Render(fmt.Sprintf("Model %q is not installed; /pull downloads it.", o.Model()))}
+	}
+
+	lines := []string{StyleBold.Render("Installed models")}
+	if len(msg.Models) == 0 {
+		lines = append(lines, StyleDimmed.Render("  (none)"))
+	}
+	for _, m := range msg.Models {
+		mark := "  "
+		if ai.HasModel([]ai.OllamaModel{m}, o.Model()) {
+			mark = "▸ "
+		}
+		lines = append(lines, fmt.Sprintf("%s%-32s %10s  %s", mark, m.Name,
+			formatByteSize(int(m.Size)), StyleDimmed.Render(m.ModifiedAt.Format("2006-01-02"))))
+	}
+	if !installed {
+		lines = append(lines, "", StyleWarning.Render(fmt.Sprintf("The configured model %q is not installed; /pull downloads it.", o.Model())))
+	}
+	return append(lines, "", StyleDimmed.Render("/pull <model> downloads another model."))
+}
+
+// renderPull is the progress line of the running download.
+func (v *AIView) renderPull() string {
+	p := v.pullStatus
+	line := "⬇ Pulling " + v.pulling + ": " + p.Status
+	if p.Total > 0 {
+		line += fmt.Sprintf(" %d%% (%s / %s)", p.Completed*100/p.Total,
+			formatByteSize(int(p.Completed)), formatByteSize(int(p.Total)))
+	}
+	return StyleDimmed.Render(line)
+}
@@ -0,0 +1,74 @@
+// ai_stream.go sends chat requests for the AI view and the SQL tab's
+// chat. Providers that stream (ai.StreamingProvider) deliver the reply
+// piece by piece as AIChunkMsg, each carrying the command that waits
+// for the next one, and finish with the usual AIResponseMsg.
+package tui
+
+import (
+	"context"
+	"strings"
+
+	"github.com/DachengChen/paiSQL/ai"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// chatCmd sends msgs to provider as chat request id.
+func chatCmd(provider ai.Provider, id int, msgs []ai.Message) tea.Cmd {
+	providerName := provider.Name()
+	logRequest := func() {
+		var inputSummary string
+		for _, m := range msgs {
+			inputSummary += m.Role + ": " + m.Content + "\n"
+		}
+		ai.LogAIRequest("Chat", providerName, map[string]string{
+			"Messages": inputSummary,
+		})
+	}
+
+	sp, ok := provider.(ai.StreamingProvider)
+	if !ok {
+		return func() tea.Msg {
+			logRequest()
+			resp, err := provider.Chat(context.Background(), msgs)
+			ai.LogAIResponse("Chat", resp, err)
+			return AIResponseMsg{ID: id, Response: ai.SanitizeResponse(resp), Err: err}
+		}
+	}
+
+	return func() tea.Msg {
+		logRequest()
+		s := &chatStream{id: id, deltas: make(chan string, 64)}
+		go func() {
+			defer close(s.deltas)
+			s.resp, s.err = sp.ChatStream(context.Background(), msgs, func(delta string) {
+				s.deltas <- delta
+			})
+		}()
+		return s.next()
+	}
+}
+
+// chatStream is a reply being streamed. resp and err are set before
+// deltas is closed.
+type chatStream struct {
+	id     int
+	deltas chan string
+	resp   string
+	err    error
+}
+
+// next waits for more of the reply, joining pieces that arrived while
+// the UI was busy, or for the end of the stream.
+func (s *chatStream) next() tea.Msg {
+	delta, ok := <-s.deltas
+	if !ok {
+		ai.LogAIResponse("Chat", s.resp, s.err)
+		return AIResponseMsg{ID: s.id, Response: ai.SanitizeResponse(s.resp), Err: s.err}
+	}
+	var sb strings.Builder
+	sb.WriteString(delta)
+	for len(s.deltas) > 0 {
+		sb.WriteString(<-s.deltas)
+	}
+	return AIChunkMsg{ID: s.id, Delta: ai.SanitizeResponse(sb.String()), next: s.next}
+}
@@ -0,0 +1,348 @@
+// alter_column.go implements the column wizard (A in the table list): pick
+// a column and a change, fill in the new name, type or default, then
+// review the generated ALTER TABLE with its rewrite and lock warnings
+// before running it.
+package tui
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/DachengChen/paiSQL/applog"
+	"github.com/DachengChen/paiSQL/db"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type alterStep int
+
+const (
+	alterLoading    alterStep = iota // fetching the table's columns
+	alterPickColumn                  // choosing the column
+	alterPickOp                      // choosing the change
+	alterInput                       // typing the new name, type, USING or default
+	alterPreviewing                  // waiting for the preview
+	alterReview                      // DDL and warnings; y runs it
+	alterRunning                     // waiting for the DDL to finish
+)
+
+// alterWizard is the column wizard shown over the results pane.
+type alterWizard struct {
+	table   string // table list name
+	step    alterStep
+	columns []db.ColumnInfo
+	colIdx  int
+	ops     []db.AlterColumnOp // changes offered for the chosen column
+	opIdx   int
+	inputs  []string  // prompts answered so far, see alterPrompts
+	input   textInput // answer being typed
+	preview *db.AlterColumnPreview
+	err     error
+}
+
+// alterPrompts lists what each change asks for, in order.
+var alterPrompts = map[db.AlterColumnOp][]string{
+	db.AlterRename:     {"New name"},
+	db.AlterType:       {"New type", "USING expression (empty for a plain cast)"},
+	db.AlterSetDefault: {"Default expression"},
+}
+
+func (w *alterWizard) column() db.ColumnInfo { return w.columns[w.colIdx] }
+func (w *alterWizard) op() db.AlterColumnOp  { return w.ops[w.opIdx] }
+
+// change builds the AlterColumn from the wizard's answers.
+func (w *alterWizard) change(schema, table string) db.AlterColumn {
+	a := db.AlterColumn{Schema: schema, Table: table, Column: w.column().Name, Op: w.op()}
+	if len(w.inputs) > 0 {
+		a.Value = w.inputs[0]
+	}
+	if len(w.inputs) > 1 {
+		a.Using = w.inputs[1]
+	}
+	return a
+}
+
+// opsFor lists the changes that make sense for col.
+func opsFor(col db.ColumnInfo) []db.AlterColumnOp {
+	ops := []db.AlterColumnOp{db.AlterRename, db.AlterType}
+	if col.IsNullable {
+		ops = append(ops, db.AlterSetNotNull)
+	} else if !col.IsPK {
+		ops = append(ops, db.AlterDropNotNull)
+	}
+	ops = append(ops, db.AlterSetDefault)
+	if col.Default != "" {
+		ops = append(ops, db.AlterDropDefault)
+	}
+	return ops
+}
+
+// openAlterWizard starts the column wizard for table.
+func (v *MainView) openAlterWizard(table string) tea.Cmd {
+	v.alter = &alterWizard{table: table}
+	schema, name := v.tableRef(table)
+	database := v.db
+	return func() tea.Msg {
+		ts, err := database.FetchTableSchema(context.Background(), schema, name)
+		if err != nil {
+			return AlterColumnsMsg{Table: table, Err: err}
+		}
+		return AlterColumnsMsg{Table: table, Columns: ts.Columns}
+	}
+}
+
+func (v *MainView) handleAlterKey(msg tea.KeyMsg) (View, tea.Cmd) {
+	w := v.alter
+	key := msg.String()
+	if key == "esc" {
+		switch w.step {
+		case alterPickOp:
+			w.step = alterPickColumn
+		case alterInput, alterReview:
+			w.step, w.inputs, w.err = alterPickOp, nil, nil
+			w.input.Reset()
+		case alterRunning:
+			// The DDL can't be abandoned halfway; wait for it.
+		default:
+			v.alter = nil
+		}
+		return v, nil
+	}
+
+	switch w.step {
+	case alterPickColumn:
+		switch key {
+		case "up", "k":
+			if w.colIdx > 0 {
+				w.colIdx--
+			}
+		case "down", "j":
+			if w.colIdx < len(w.columns)-1 {
+				w.colIdx++
+			}
+		case "enter":
+			w.ops, w.opIdx, w.step = opsFor(w.column()), 0, alterPickOp
+		}
+
+	case alterPickOp:
+		switch key {
+		case "up", "k":
+			if w.opIdx > 0 {
+				w.opIdx--
+			}
+		case "down", "j":
+			if w.opIdx < len(w.ops)-1 {
+				w.opIdx++
+			}
+		case "enter":
+			w.inputs, w.err = nil, nil
+			w.input.Reset()
+			if len(alterPrompts[w.op()]) == 0 {
+				return v, v.previewAlter()
+			}
+			w.step = alterInput
+		}
+
+	case alterInput:
+		switch key {
+		case "enter":
+			prompts := alterPrompts[w.op()]
+			if w.input.Value() == "" && len(w.inputs) == 0 {
+				return v, nil // the first answer is required
+			}
+			w.inputs = append(w.inputs, w.input.Value())
+			w.input.Reset()
+			if len(w.inputs) == len(prompts) {
+				return v, v.previewAlter()
+			}
+		case "ctrl+u":
+			w.input.Reset()
+		default:
+			w.input.Update(msg)
+		}
+
+	case alterReview:
+		if key == "y" {
+			return v, v.runAlter()
+		}
+	}
+	return v, nil
+}
+
+// previewAlter asks the database what the change would do.
+func (v *MainView) previewAlter() tea.Cmd {
+	w := v.alter
+	w.step = alterPreviewing
+	schema, name := v.tableRef(w.table)
+	change := w.change(schema, name)
+	database := v.db
+	return func() tea.Msg {
+		preview, err := database.PreviewAlterColumn(context.Background(), change)
+		return AlterPreviewMsg{Table: w.table, Preview: preview, Err: err}
+	}
+}
+
+// runAlter executes the reviewed DDL.
+func (v *MainView) runAlter() tea.Cmd {
+	w := v.alter
+	w.step = alterRunning
+	ddl := w.preview.DDL
+	applog.Event("DDL", "Confirmed: %s", ddl)
+	database := v.db
+	return func() tea.Msg {
+		_, err := database.Execute(context.Background(), ddl)
+		return AlterDoneMsg{Table: w.table, DDL: ddl, Err: err}
+	}
+}
+
+// updateAlter handles the column wizard's messages.
+func (v *MainView) updateAlter(msg tea.Msg) tea.Cmd {
+	w := v.alter
+	switch msg := msg.(type) {
+	case AlterColumnsMsg:
+		if w == nil || w.step != alterLoading || w.table != msg.Table {
+			return nil
+		}
+		if msg.Err != nil {
+			v.alter = nil
+			return func() tea.Msg { return StatusMsg("Column wizard: " + msg.Err.Error()) }
+		}
+		if len(msg.Columns) == 0 {
+			v.alter = nil
+			return func() tea.Msg { return StatusMsg("Column wizard: " + msg.Table + " has no columns") }
+		}
+		w.columns, w.step = msg.Columns, alterPickColumn
+
+	case AlterPreviewMsg:
+		if w == nil || w.step != alterPreviewing || w.table != msg.Table {
+			return nil
+		}
+		if msg.Err != nil {
+			w.err = msg.Err
+			w.step, w.inputs = alterPickOp, nil
+			if len(alterPrompts[w.op()]) > 0 {
+				w.step = alterInput
+			}
+			return nil
+		}
+		msg.Preview.DDL = v.styleSQL(msg.Preview.DDL)
+		w.preview, w.step = msg.Preview, alterReview
+
+	case AlterDoneMsg:
+		if msg.Err != nil {
+			applog.Event("DDL", "Failed: %s: %v", msg.DDL, msg.Err)
+			if w != nil && w.step == alterRunning && w.table == msg.Table {
+				w.err, w.step = msg.Err, alterReview
+			}
+			return nil
+		}
+		applog.Event("DDL", "Finished: %s", msg.DDL)
+		if w != nil && w.table == msg.Table {
+			v.alter = nil
+		}
+		v.pagTable, v.pagPlan = "", false
+		return tea.Batch(
+			func() tea.Msg { return StatusMsg("Done: " + msg.DDL) },
+			v.fetchDescribe(msg.Table),
+		)
+	}
+	return nil
+}
+
+// renderAlterWizard renders the column wizard in place of the results.
+func (v *MainView) renderAlterWizard() []string {
+	w := v.alter
+	lines := []string{StyleBold.Render("✏️  Alter column: " + w.table), ""}
+
+	switch w.step {
+	case alterLoading:
+		lines = append(lines, StyleDimmed.Render("Loading columns…"))
+		return lines
+
+	case alterPickColumn:
+		for i, col := range w.columns {
+			line := fmt.Sprintf("%-24s %s", col.Name, StyleDimmed.Render(columnSummary(col)))
+			lines = append(lines, pickLine(line, i == w.colIdx))
+		}
+		lines = append(lines, "", StyleDimmed.Render("↑/↓ select · Enter choose · Esc close"))
+		return lines
+	}
+
+	col := w.column()
+	lines = append(lines, fmt.Sprintf("Column %s  %s", StyleBold.Render(col.Name), StyleDimmed.Render(columnSummary(col))), "")
+
+	switch w.step {
+	case alterPickOp:
+		for i, op := range w.ops {
+			lines = append(lines, pickLine(string(op), i == w.opIdx))
+		}
+		lines = append(lines, "")
+		if w.err != nil {
+			lines = append(lines, StyleError.Render("Error: "+w.err.Error()), "")
+		}
+		lines = append(lines, StyleDimmed.Render("↑/↓ select · Enter choose · Esc back"))
+
+	case alterInput:
+		prompts := alterPrompts[w.op()]
+		for i, answer := range w.inputs {
+			lines = append(lines, fmt.Sprintf("%s: %s", prompts[i], answer))
+		}
+		lines = append(lines, StylePrompt.Render(prompts[len(w.inputs)]+": ")+w.input.View(), "")
+		if w.op() == db.AlterType && len(w.inputs) == 1 {
+			lines = append(lines, StyleDimmed.Render(fmt.Sprintf("e.g. %s::%s, or NULLIF(%s, '')::%s", col.Name, w.inputs[0], col.Name, w.inputs[0])), "")
+		}
+		if w.err != nil {
+			lines = append(lines, StyleError.Render("Error: "+w.err.Error()), "")
+		}
+		lines = append(lines, StyleDimmed.Render("Enter next · Esc back"))
+
+	case alterPreviewing:
+		lines = append(lines, StyleDimmed.Render("Checking "+string(w.op())+"…"))
+
+	case alterReview, alterRunning:
+		p := w.preview
+		lines = append(lines, "  "+highlightSQL(p.DDL), "")
+		if len(p.Samples) > 0 {
+			lines = append(lines, "── Sample conversion ──")
+			for _, s := range p.Samples {
+				lines = append(lines, fmt.Sprintf("  %s → %s", s[0], s[1]))
+			}
+			lines = append(lines, "")
+		}
+		for _, warning := range p.Warnings {
+			lines = append(lines, StyleWarning.Render("⚠ "+warning))
+		}
+		lines = append(lines, "")
+		if w.err != nil {
+			lines = append(lines, StyleError.Render("Error: "+w.err.Error()), "")
+		}
+		if w.step == alterRunning {
+			lines = append(lines, StyleDimmed.Render("Running…"))
+		} else {
+			lines = append(lines, StyleDimmed.Render("y run · Esc back"))
+		}
+	}
+	return lines
+}
+
+// columnSummary describes a column's type, nullability and default.
+func columnSummary(col db.ColumnInfo) string {
+	s := col.DataType
+	if !col.IsNullable {
+		s += " NOT NULL"
+	}
+	if col.Default != "" {
+		s += " DEFAULT " + col.Default
+	}
+	if col.IsPK {
+		s += " (PK)"
+	}
+	return s
+}
+
+// pickLine renders a menu entry, marked when selected.
+func pickLine(text string, selected bool) string {
+	if selected {
+		return StylePrompt.Render(" ▸ ") + text
+	}
+	return "   " + text
+}
@@ -15,9 +15,11 @@ package tui
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/DachengChen/paiSQL/ai"
+	"github.com/DachengChen/paiSQL/applog"
 	"github.com/DachengChen/paiSQL/config"
 	"github.com/DachengChen/paiSQL/db"
 	tea "github.com/charmbracelet/bubbletea"
@@ -26,6 +28,12 @@ import (
 
 const appVersion = "0.1.0"
 
+// The smallest terminal the layout works in; below it only a notice is drawn.
+const (
+	minTermWidth  = 40
+	minTermHeight = 10
+)
+
 // Tab indices for connected mode.
 const (
 	TabSQL = iota
@@ -34,6 +42,10 @@ const (
 	TabStats
 	TabLog
 	TabAI
+	TabIntegrity
+	TabListen
+	TabActivity
+	TabLocks
 )
 
 // AppPhase tracks whether we're connecting or already connected.
@@ -51,6 +63,7 @@ const (
 	ModeNormal InputMode = iota
 	ModeCommand
 	ModeJump
+	ModeSearch
 )
 
 // App is the root Bubble Tea model.
@@ -68,14 +81,33 @@ type App struct {
 	appConfig  *config.AppConfig
 	cfg        config.Config
 	connName   string // name of active connection
+	connColor  string // frame color of the active connection, "" for none
+
+	autoconnect string // saved connection to connect to on startup
+
+	// Monitor mode (paisql top): only the monitoring views, no SQL editor
+	monitor bool
+
+	// View to show and table to browse once connected (--view, --table);
+	// cleared once applied so a reconnect starts in the SQL view
+	startView  string
+	startTable string
+
+	// Autosaved input buffers of the current connection
+	scratch    *config.Scratchpad
+	scratchGen int
 
 	// UI state
-	width     int
-	height    int
-	mode      InputMode
-	cmdInput  string
-	showHelp  bool
-	statusMsg string
+	width      int
+	height     int
+	mode       InputMode
+	cmdInput   textInput
+	showHelp   bool
+	helpFilter textInput // search text typed while the help overlay is open
+	search     SearchOptions
+	searchHint string // match count of the /pattern being typed
+	statusMsg  string
+	notices    []notice // background completions in inactive views
 }
 
 // NewApp creates the application starting with the connection screen.
@@ -91,6 +123,9 @@ func NewApp(store *config.ConnectionStore, provider ai.Provider, appCfg *config.
 
 // Init implements tea.Model.
 func (a *App) Init() tea.Cmd {
+	if a.autoconnect != "" {
+		return a.connectView.connectTo(a.autoconnect)
+	}
 	return a.connectView.Init()
 }
 
@@ -100,19 +135,7 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.WindowSizeMsg:
 		a.width = msg.Width
 		a.height = msg.Height
-		// header(1) + border(2) + helpbar(1) = 4 lines of chrome
-		contentH := a.height - 4
-		contentW := a.width - 2 // border left+right
-		if a.phase == PhaseConnect {
-			a.connectView.SetSize(contentW, contentH)
-		} else {
-			// tab bar removed, status bar moved outside
-			// Header(1) + Status(1) + Slack(1) + Borders(2) = 5 lines chrome
-			viewH := contentH - 1
-			for _, v := range a.views {
-				v.SetSize(contentW, viewH)
-			}
-		}
+		a.resizeViews()
 		return a, nil
 
 	case ConnectedMsg:
@@ -120,19 +143,48 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		a.db = msg.DB
 		a.cfg = msg.Cfg
 		a.connName = msg.Conn.Name
+		a.connColor = msg.Conn.Color
 		a.phase = PhaseMain
-		// Recreate AI provider from (potentially updated) config
-		if p, err := ai.NewProvider(a.appConfig.AI); err == nil {
+		if a.connName != "" {
+			if err := a.store.Touch(a.connName); err != nil {
+				applog.Error("Failed to record last use of '%s': %v", a.connName, err)
+			}
+			a.connectView.selectSaved(a.connName)
+		}
+		// Recreate AI provider from (potentially updated) config, with the
+		// connection's override
+		if p, err := ai.NewProvider(a.appConfig.AI.ForConnection(msg.Conn)); err == nil {
 			a.aiProvider = p
+		} else if msg.Conn.AIProvider != "" {
+			// Never fall back to the global provider: the override may be
+			// there to keep this database's data away from it.
+			applog.Event("AI", "Provider override %s for '%s' failed: %v, using placeholder", msg.Conn.AIProvider, a.connName, err)
+			a.aiProvider = ai.NewPlaceholder()
+			a.statusMsg = "AI provider " + msg.Conn.AIProvider + ": " + err.Error()
 		}
 		a.initViews()
-		// Trigger resize for views
-		contentW := a.width - 2
-		viewH := a.height - 5 // chrome (header1 + status1 + slack1 + borders2)
-		for _, v := range a.views {
-			v.SetSize(contentW, viewH)
+		a.resizeViews()
+		var cmds []tea.Cmd
+		if !a.monitor {
+			// Monitor mode has no input to keep, and saving would wipe
+			// the connection's scratchpad
+			cmds = append(cmds, a.startScratchpad())
+		}
+		if a.startTable != "" {
+			if mv, ok := a.views[TabSQL].(*MainView); ok {
+				mv.startTable = a.startTable
+			}
+			a.startTable = ""
+		}
+		if a.startView != "" {
+			a.jumpToView(a.startView)
+			a.startView = ""
+			if a.activeTab != TabSQL && !a.monitor {
+				// The SQL view still loads the tables (and --table) behind it
+				cmds = append(cmds, a.initView(TabSQL))
+			}
 		}
-		return a, a.views[a.activeTab].Init()
+		return a, tea.Batch(append(cmds, a.initView(a.activeTab))...)
 
 	case ConnectErrorMsg:
 		// Stay on connect screen, forward error
@@ -163,45 +215,116 @@ func (a *App) updateConnect(msg tea.Msg) (tea.Model, tea.Cmd) {
 func (a *App) updateMain(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		// Any key dismisses a transient status message
+		a.statusMsg = ""
 		return a.handleKey(msg)
+
+	case StatusMsg:
+		a.statusMsg = string(msg)
+		return a, nil
+
+	case scratchTickMsg:
+		if msg.gen != a.scratchGen {
+			return a, nil
+		}
+		a.saveScratch()
+		return a, a.scratchTick()
+
+	case routedMsg:
+		hook := a.announce(msg)
+		if status, ok := msg.msg.(StatusMsg); ok {
+			a.statusMsg = string(status)
+			return a, hook
+		}
+		// Deliver async results to the view that asked for them; drop
+		// them if that view is gone (e.g. after a reconnect).
+		for i, v := range a.views {
+			if v == msg.view {
+				a.notifyCompletion(i, msg.msg)
+				return a, tea.Batch(hook, a.updateView(i, msg.msg))
+			}
+		}
+		return a, hook
 	}
 
 	// Forward other messages to active view
 	if a.activeTab < len(a.views) {
-		updatedView, cmd := a.views[a.activeTab].Update(msg)
-		a.views[a.activeTab] = updatedView
-		return a, cmd
+		return a, a.updateView(a.activeTab, msg)
 	}
 
 	return a, nil
 }
 
+// updateView forwards msg to view i, routing the messages of any command
+// it returns back to the same view.
+func (a *App) updateView(i int, msg tea.Msg) tea.Cmd {
+	updated, cmd := a.views[i].Update(msg)
+	a.views[i] = updated
+	return routeTo(updated, cmd)
+}
+
+// initView initializes view i with its messages routed back to it.
+func (a *App) initView(i int) tea.Cmd {
+	return routeTo(a.views[i], a.views[i].Init())
+}
+
+// leaveTab tells the active view that the user is switching to tab idx.
+func (a *App) leaveTab(idx int) {
+	if idx != a.activeTab && a.activeTab < len(a.views) {
+		a.views[a.activeTab].Leave()
+	}
+}
+
 // initViews creates all main views after connection is established.
 func (a *App) initViews() {
+	a.activeTab = 0
+	if a.monitor {
+		a.views = []View{
+			NewLogView(a.db),
+			NewLocksView(a.db),
+			NewStatsView(a.db, a.aiProvider),
+			NewActivityView(a.db),
+		}
+		return
+	}
 	a.views = []View{
-		NewMainView(a.db, a.aiProvider),
+		NewMainView(a.db, a.aiProvider, a.appConfig, a.connName),
 		NewExplainView(a.db),
 		NewIndexView(a.db, a.aiProvider),
-		NewStatsView(a.db),
+		NewStatsView(a.db, a.aiProvider),
 		NewLogView(a.db),
 		NewAIView(a.aiProvider),
+		NewIntegrityView(a.db),
+		NewListenView(a.db),
+		NewActivityView(a.db),
+		NewLocksView(a.db),
 	}
-	a.activeTab = TabSQL
 }
 
 // handleKey processes keyboard input in main phase.
 func (a *App) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if a.showHelp {
+		return a.handleHelpKey(msg)
+	}
 	switch a.mode {
 	case ModeCommand:
 		return a.handleCommandMode(msg)
 	case ModeJump:
 		return a.handleJumpMode(msg)
+	case ModeSearch:
+		return a.handleSearchMode(msg)
 	default:
 		return a.handleNormalMode(msg)
 	}
 }
 
 func (a *App) handleNormalMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if vp := a.searchViewport(); vp != nil {
+		if cmd, ok := a.handleSearchKey(vp, msg); ok {
+			return a, cmd
+		}
+	}
+
 	// When the active view is accepting text input (chat, SQL editor),
 	// only intercept non-text keys. Let everything else pass through.
 	textMode := a.activeTab < len(a.views) && a.views[a.activeTab].WantsTextInput()
@@ -210,9 +333,14 @@ func (a *App) handleNormalMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		// In text mode, only allow escape-key combos and function keys
 		switch msg.String() {
 		case "ctrl+c":
-			return a, tea.Quit
+			return a, a.quit()
 		case "f1":
-			return a.switchTab(0)
+			if a.monitor {
+				return a, nil // there is no SQL view
+			}
+			return a.switchTab(TabSQL)
+		case jumpToNoticeKey:
+			return a.jumpToNotice()
 		case "f2":
 			// Let the view handle F2 (e.g. toggle SQL/Chat)
 		case "?":
@@ -223,9 +351,7 @@ func (a *App) handleNormalMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 		// Forward to active view
 		if a.activeTab < len(a.views) {
-			updatedView, cmd := a.views[a.activeTab].Update(msg)
-			a.views[a.activeTab] = updatedView
-			return a, cmd
+			return a, a.updateView(a.activeTab, msg)
 		}
 		return a, nil
 	}
@@ -233,23 +359,41 @@ func (a *App) handleNormalMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	// Normal mode: handle global shortcuts
 	switch msg.String() {
 	case "ctrl+c":
-		return a, tea.Quit
+		return a, a.quit()
 
 	case "/":
 		a.mode = ModeJump
-		a.cmdInput = ""
+		a.cmdInput.Reset()
 		return a, nil
 
 	case "?":
-		a.showHelp = !a.showHelp
+		a.showHelp = true
+		a.helpFilter.Reset()
 		return a, nil
+
+	case "f1":
+		if a.monitor {
+			return a, nil // there is no SQL view
+		}
+		return a.switchTab(TabSQL)
+
+	case jumpToNoticeKey:
+		return a.jumpToNotice()
+	}
+	if a.monitor {
+		switch msg.String() {
+		case "tab":
+			return a.switchTab((a.activeTab + 1) % len(a.views))
+		case "shift+tab":
+			return a.switchTab((a.activeTab + len(a.views) - 1) % len(a.views))
+		case "q":
+			return a, a.quit()
+		}
 	}
 
 	// Forward to active view
 	if a.activeTab < len(a.views) {
-		updatedView, cmd := a.views[a.activeTab].Update(msg)
-		a.views[a.activeTab] = updatedView
-		return a, cmd
+		return a, a.updateView(a.activeTab, msg)
 	}
 
 	return a, nil
@@ -258,26 +402,18 @@ func (a *App) handleNormalMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 func (a *App) handleCommandMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "enter":
-		cmd := a.executeCommand(a.cmdInput)
+		cmd := a.executeCommand(a.cmdInput.Value())
 		a.mode = ModeNormal
-		a.cmdInput = ""
+		a.cmdInput.Reset()
 		return a, cmd
 
 	case "escape":
 		a.mode = ModeNormal
-		a.cmdInput = ""
-		return a, nil
-
-	case "backspace":
-		if len(a.cmdInput) > 0 {
-			a.cmdInput = a.cmdInput[:len(a.cmdInput)-1]
-		}
+		a.cmdInput.Reset()
 		return a, nil
 
 	default:
-		if len(msg.String()) == 1 {
-			a.cmdInput += msg.String()
-		}
+		a.cmdInput.Update(msg)
 		return a, nil
 	}
 }
@@ -285,74 +421,228 @@ func (a *App) handleCommandMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 func (a *App) handleJumpMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "enter":
-		a.jumpToView(a.cmdInput)
+		a.jumpToView(a.cmdInput.Value())
 		a.mode = ModeNormal
-		a.cmdInput = ""
-		return a, a.views[a.activeTab].Init()
+		a.cmdInput.Reset()
+		return a, a.initView(a.activeTab)
 
 	case "escape":
 		a.mode = ModeNormal
-		a.cmdInput = ""
+		a.cmdInput.Reset()
 		return a, nil
 
-	case "backspace":
-		if len(a.cmdInput) > 0 {
-			a.cmdInput = a.cmdInput[:len(a.cmdInput)-1]
-		}
+	default:
+		a.cmdInput.Update(msg)
 		return a, nil
+	}
+}
 
-	default:
-		if len(msg.String()) == 1 {
-			a.cmdInput += msg.String()
+// searchViewport is the viewport / searches in the active view, or nil
+// when / jumps to a view instead.
+func (a *App) searchViewport() *Viewport {
+	if a.activeTab >= len(a.views) || a.showHelp {
+		return nil
+	}
+	if s, ok := a.views[a.activeTab].(Searcher); ok {
+		return s.SearchViewport()
+	}
+	return nil
+}
+
+// handleSearchKey handles / and, while a search is active, n/N and Esc.
+func (a *App) handleSearchKey(vp *Viewport, msg tea.KeyMsg) (tea.Cmd, bool) {
+	switch msg.String() {
+	case "/":
+		a.mode = ModeSearch
+		a.cmdInput.Reset()
+		a.searchHint = ""
+		return nil, true
+	case "n", "N":
+		if !vp.Searching() {
+			return nil, false
 		}
+		dir := 1
+		if msg.String() == "N" {
+			dir = -1
+		}
+		n, total := vp.NextMatch(dir)
+		if total == 0 {
+			a.statusMsg = "No matches for /" + vp.searchText
+		} else {
+			a.statusMsg = fmt.Sprintf("/%s: match %d of %d", vp.searchText, n, total)
+		}
+		return nil, true
+	case "esc":
+		if !vp.Searching() {
+			return nil, false
+		}
+		vp.ClearSearch()
+		return nil, true
+	}
+	return nil, false
+}
+
+// handleSearchMode edits the /pattern; Enter searches, an empty pattern
+// ends the search. Ctrl+R and Ctrl+T toggle regex and case-sensitive
+// matching, which stay on for later searches.
+func (a *App) handleSearchMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		a.mode = ModeNormal
+		pattern := a.cmdInput.Value()
+		a.cmdInput.Reset()
+		a.searchHint = ""
+		vp := a.searchViewport()
+		if vp == nil {
+			return a, nil
+		}
+		if pattern == "" {
+			vp.ClearSearch()
+			return a, nil
+		}
+		n, err := vp.Search(pattern, a.search)
+		switch {
+		case err != nil:
+			a.statusMsg = StyleError.Render("Bad pattern /" + pattern + ": " + err.Error())
+		case n == 0:
+			a.statusMsg = "No matches for /" + pattern
+		default:
+			a.statusMsg = fmt.Sprintf("/%s: %d matches (n/N next/previous, Esc clears)", pattern, n)
+		}
+		return a, nil
+
+	case "esc":
+		a.mode = ModeNormal
+		a.cmdInput.Reset()
+		a.searchHint = ""
 		return a, nil
+
+	case "ctrl+r":
+		a.search.Regex = !a.search.Regex
+
+	case "ctrl+t":
+		a.search.CaseSensitive = !a.search.CaseSensitive
+
+	default:
+		a.cmdInput.Update(msg)
+	}
+	a.countSearchMatches()
+	return a, nil
+}
+
+// countSearchMatches updates the match count shown after the /pattern
+// while it is typed, or says why the pattern is not valid.
+func (a *App) countSearchMatches() {
+	a.searchHint = ""
+	vp := a.searchViewport()
+	if vp == nil || a.cmdInput.Value() == "" {
+		return
+	}
+	n, err := vp.CountMatches(a.cmdInput.Value(), a.search)
+	switch {
+	case err != nil:
+		a.searchHint = StyleError.Render(err.Error())
+	case n == 1:
+		a.searchHint = StyleDimmed.Render("1 match")
+	default:
+		a.searchHint = StyleDimmed.Render(fmt.Sprintf("%d matches", n))
 	}
 }
 
 func (a *App) switchTab(idx int) (tea.Model, tea.Cmd) {
 	if idx >= 0 && idx < len(a.views) {
+		a.leaveTab(idx)
 		a.activeTab = idx
-		return a, a.views[a.activeTab].Init()
+		a.clearNotices()
+		return a, a.initView(a.activeTab)
 	}
 	return a, nil
 }
 
+// jumpToView switches to the view named name, or else the first whose
+// name contains it ("ai" is the AI view, not Explain).
 func (a *App) jumpToView(name string) {
 	name = strings.ToLower(strings.TrimSpace(name))
-	for i, v := range a.views {
-		if strings.Contains(strings.ToLower(v.Name()), name) {
-			a.activeTab = i
-			return
-		}
+	i := slices.IndexFunc(a.views, func(v View) bool { return strings.ToLower(v.Name()) == name })
+	if i < 0 {
+		i = slices.IndexFunc(a.views, func(v View) bool { return strings.Contains(strings.ToLower(v.Name()), name) })
+	}
+	if i < 0 {
+		a.statusMsg = "view not found: " + name
+		return
 	}
-	a.statusMsg = "view not found: " + name
+	a.leaveTab(i)
+	a.activeTab = i
+	a.clearNotices()
 }
 
 func (a *App) executeCommand(input string) tea.Cmd {
 	input = strings.TrimSpace(input)
 	switch {
 	case input == "q" || input == "quit":
-		return tea.Quit
+		return a.quit()
 	case input == "disconnect":
 		a.disconnect()
 		return nil
-	case strings.HasPrefix(input, "dt"):
+	case strings.HasPrefix(input, "dt") && !a.monitor:
+		a.leaveTab(TabSQL)
 		a.activeTab = TabSQL
+		a.clearNotices()
 		a.statusMsg = "listing tables..."
-		return a.views[TabSQL].Init()
+		return a.initView(TabSQL)
 	default:
 		a.statusMsg = "unknown command: " + input
 		return nil
 	}
 }
 
+// resizeViews passes the space inside the frame on to the views. Below
+// the minimum terminal size View shows a notice instead, so the views
+// are laid out for the minimum and never get negative sizes.
+func (a *App) resizeViews() {
+	contentW := max(a.width, minTermWidth) - 2 // border left+right
+	height := max(a.height, minTermHeight)
+	if a.phase == PhaseConnect {
+		// header(1) + border(2) + helpbar(1) = 4 lines of chrome
+		a.connectView.SetSize(contentW, height-4)
+		return
+	}
+	// Header(1) + Status(1) + Slack(1) + Borders(2) = 5 lines chrome
+	for _, v := range a.views {
+		v.SetSize(contentW, height-5)
+	}
+}
+
+// filterSignals saves the scratchpad when the program is stopped by a
+// signal (SIGINT from outside the terminal, SIGTERM), which Bubble Tea
+// handles without going through Update.
+func (a *App) filterSignals(_ tea.Model, msg tea.Msg) tea.Msg {
+	switch msg.(type) {
+	case tea.InterruptMsg, tea.QuitMsg:
+		a.saveScratch()
+	}
+	return msg
+}
+
+// quit saves unsent input before exiting.
+func (a *App) quit() tea.Cmd {
+	a.saveScratch()
+	return tea.Quit
+}
+
 func (a *App) disconnect() {
+	a.stopScratchpad()
+	a.abandonTx()
+	for _, v := range a.views {
+		v.Leave()
+	}
 	if a.db != nil {
 		a.db.Close()
 		a.db = nil
 	}
 	a.phase = PhaseConnect
 	a.views = nil
+	a.notices = nil
 	a.activeTab = 0
 	a.statusMsg = ""
 }
@@ -363,6 +653,9 @@ func (a *App) View() string {
 	if a.width == 0 {
 		return "loading..."
 	}
+	if a.width < minTermWidth || a.height < minTermHeight {
+		return a.renderTooSmall()
+	}
 
 	// ── Header bar ──
 	header := a.renderHeader()
@@ -412,7 +705,11 @@ func (a *App) View() string {
 		frameHeight = 0
 	}
 
-	frame := StyleBorder.
+	frameStyle := StyleBorder
+	if color, ok := connectionColor(a.connColor); ok {
+		frameStyle = frameStyle.BorderForeground(color)
+	}
+	frame := frameStyle.
 		Width(a.width - 2).
 		Height(frameHeight).
 		Render(innerContent)
@@ -423,6 +720,16 @@ func (a *App) View() string {
 	return header + "\n" + frame + "\n" + statusBar
 }
 
+// renderTooSmall replaces the whole screen while the terminal is below
+// the minimum size; the layout comes back once it is resized.
+func (a *App) renderTooSmall() string {
+	lines := []string{
+		StyleWarning.Render(truncateRunes("Terminal too small", a.width)),
+		truncateRunes(fmt.Sprintf("%dx%d, need %dx%d", a.width, a.height, minTermWidth, minTermHeight), a.width),
+	}
+	return strings.Join(lines[:min(a.height, len(lines))], "\n")
+}
+
 // renderHeader draws a simple text bar: logo + version + connection info.
 func (a *App) renderHeader() string {
 	logo := StyleBold.Render("🐘 paiSQL")
@@ -438,10 +745,26 @@ func (a *App) renderHeader() string {
 		if label == "" {
 			label = "Direct"
 		}
-		connInfo = StyleSuccess.Render(fmt.Sprintf("  ⚡ %s (%s)", label, details))
+		style := StyleSuccess
+		if color, ok := connectionColor(a.connColor); ok {
+			style = lipgloss.NewStyle().Bold(true).Foreground(color)
+		}
+		connInfo = style.Render(fmt.Sprintf("  ⚡ %s (%s)", label, details))
 	}
 
-	content := left + connInfo
+	content := left + connInfo + a.renderTxBadge()
+	if a.phase == PhaseMain && a.monitor {
+		// The screens, as Tab cycles through them
+		var names []string
+		for i, v := range a.views {
+			if i == a.activeTab {
+				names = append(names, StyleBold.Render(v.Name()))
+			} else {
+				names = append(names, StyleDimmed.Render(v.Name()))
+			}
+		}
+		content += "  " + strings.Join(names, StyleDimmed.Render(" · "))
+	}
 
 	// Fill gap to right align dimensions
 	right := StyleDimmed.Render(fmt.Sprintf("%d×%d", a.width, a.height))
@@ -456,6 +779,37 @@ func (a *App) renderHeader() string {
 		Render(content + filler + right)
 }
 
+// renderTxBadge is the header's TX OPEN badge while the SQL view has a
+// transaction open, red once a statement in it failed.
+func (a *App) renderTxBadge() string {
+	if a.phase != PhaseMain || len(a.views) <= TabSQL {
+		return ""
+	}
+	mv, ok := a.views[TabSQL].(*MainView)
+	if !ok {
+		return ""
+	}
+	open, since, aborted := mv.TxStatus()
+	if !open {
+		return ""
+	}
+	text, bg := " TX OPEN since "+since.Format("15:04:05")+" ", ColorWarning
+	if aborted {
+		text, bg = " TX FAILED — \\rollback ", ColorError
+	}
+	return "  " + lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("0")).Background(bg).Render(text)
+}
+
+// abandonTx rolls back the SQL view's open transaction before the
+// connection is closed.
+func (a *App) abandonTx() {
+	if len(a.views) > TabSQL {
+		if mv, ok := a.views[TabSQL].(*MainView); ok {
+			mv.abandonTx()
+		}
+	}
+}
+
 func (a *App) renderConnectHelpBar() string {
 	help := a.connectView.ShortHelp()
 	var parts []string
@@ -478,15 +832,29 @@ func (a *App) renderStatusBar() string {
 
 	switch a.mode {
 	case ModeCommand:
-		content = StylePrompt.Render(":") + a.cmdInput + "█"
+		content = StylePrompt.Render(":") + a.cmdInput.View()
 	case ModeJump:
-		content = StylePrompt.Render("/") + a.cmdInput + "█"
+		content = StylePrompt.Render("/") + a.cmdInput.View()
+	case ModeSearch:
+		content = StylePrompt.Render("search /") + a.cmdInput.View()
+		if flags := a.search.flags(); flags != "" {
+			content += "  " + StyleHelpKey.Render(flags)
+		}
+		if a.searchHint != "" {
+			content += "  " + a.searchHint
+		}
+		content += "  " + StyleDimmed.Render("Ctrl+R regex · Ctrl+T case")
 	default:
-		if a.statusMsg != "" {
+		if a.showHelp {
+			content = StylePrompt.Render("search help: ") + a.helpFilter.View()
+		} else if a.statusMsg != "" {
 			content = a.statusMsg
 		} else {
 			helpItems := a.getHelpItems()
 			var parts []string
+			if len(a.notices) > 0 {
+				parts = append(parts, a.renderNoticeBadge())
+			}
 			for _, h := range helpItems {
 				parts = append(parts,
 					StyleHelpKey.Render(h.Key)+" "+StyleHelpDesc.Render(h.Desc))
@@ -503,45 +871,15 @@ func (a *App) getHelpItems() []KeyBinding {
 		{Key: "?", Desc: "help"},
 		{Key: "Ctrl+C", Desc: "quit"},
 	}
+	if a.monitor {
+		global = []KeyBinding{
+			{Key: "Tab", Desc: "next screen"},
+			{Key: "?", Desc: "help"},
+			{Key: "q", Desc: "quit"},
+		}
+	}
 	if a.activeTab < len(a.views) {
 		return append(a.views[a.activeTab].ShortHelp(), global...)
 	}
 	return global
 }
-
-func (a *App) renderHelp() string {
-	help := []string{
-		StyleTitle.Render("⌨ paiSQL Keyboard Shortcuts"),
-		"",
-		StyleHelpKey.Render("Tab / Shift+Tab") + "  Switch between views",
-		StyleHelpKey.Render("F2") + "               Toggle between SQL and Chat input",
-		StyleHelpKey.Render("/") + "                Jump to view by name",
-		StyleHelpKey.Render("?") + "                Toggle this help",
-		StyleHelpKey.Render("Ctrl+C") + "          Quit",
-		"",
-		StyleTitle.Render("View-specific"),
-		"",
-		StyleHelpKey.Render("↑/↓ j/k") + "         Vertical scroll",
-		StyleHelpKey.Render("←/→ h/l") + "         Horizontal scroll",
-		StyleHelpKey.Render("PgUp/PgDn") + "        Page up/down",
-		StyleHelpKey.Render("Enter") + "            Execute query (SQL view)",
-		StyleHelpKey.Render("Ctrl+E") + "           Explain query",
-		"",
-		StyleTitle.Render("Commands"),
-		"",
-		StyleHelpKey.Render(":disconnect") + "      Return to connection screen",
-		StyleHelpKey.Render(":dt") + "              List tables",
-		StyleHelpKey.Render(":quit") + "            Quit",
-		"",
-		StyleDimmed.Render("Press ? to close"),
-	}
-
-	contentHeight := a.height - 3
-	box := lipgloss.NewStyle().
-		Width(a.width-4).
-		Height(contentHeight).
-		Padding(1, 2).
-		Render(strings.Join(help, "\n"))
-
-	return box
-}
@@ -0,0 +1,145 @@
+// banner.go shows the connection banner in the results pane after
+// connecting: the server version, the role and whether the server takes
+// writes, followed by the team's message of the day (see db.MOTDSetting),
+// so it is clear at a glance which database the session is in.
+//
+// The banner ends with one line per red flag db.SanityCheck finds;
+// \warnings re-runs the checks and shows every warning in full, and
+// \warnings <n> expands one of them.
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/DachengChen/paiSQL/applog"
+	"github.com/DachengChen/paiSQL/db"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// fetchBanner reads the server details for the banner.
+func (v *MainView) fetchBanner() tea.Cmd {
+	database := v.db
+	return func() tea.Msg {
+		ctx := context.Background()
+		info, err := database.FetchServerInfo(ctx)
+		if err != nil {
+			return ServerInfoMsg{Err: err}
+		}
+		warnings, err := database.SanityCheck(ctx)
+		if err != nil {
+			applog.Error("Some sanity checks failed: %v", err)
+		}
+		return ServerInfoMsg{Info: info, Warnings: warnings}
+	}
+}
+
+// updateBanner shows the banner unless something already took the
+// results pane.
+func (v *MainView) updateBanner(msg ServerInfoMsg) {
+	if msg.Err != nil {
+		applog.Error("Failed to read the server details: %v", msg.Err)
+		return
+	}
+	v.warnings = msg.Warnings
+	for _, w := range msg.Warnings {
+		applog.Event("SANITY", "%s", w.Title)
+	}
+	if v.result != nil || v.loading || v.lastSQL != "" {
+		return
+	}
+	v.viewport.SetContentLines(bannerLines(v.connName, msg.Info, msg.Warnings))
+}
+
+// bannerLines renders the connection banner.
+func bannerLines(connName string, s *db.ServerInfo, warnings []db.SanityWarning) []string {
+	title := "🔌 " + s.Database + " as " + s.User
+	if connName != "" {
+		title += "  (" + connName + ")"
+	}
+	lines := []string{StyleTitle.Render(title), "", "  PostgreSQL " + s.Version}
+
+	switch {
+	case s.InRecovery:
+		lines = append(lines, StyleWarning.Render("  Standby (in recovery): read-only"))
+	case s.ReadOnly:
+		lines = append(lines, StyleWarning.Render("  Primary, but default_transaction_read_only is on"))
+	default:
+		lines = append(lines, StyleSuccess.Render("  Primary: read-write"))
+	}
+	if s.Superuser {
+		lines = append(lines, StyleWarning.Render("  "+s.User+" is a superuser"))
+	}
+
+	if motd := strings.TrimSpace(s.MOTD); motd != "" {
+		lines = append(lines, "", StyleBold.Render("📌 Message of the day"))
+		for _, line := range strings.Split(motd, "\n") {
+			lines = append(lines, "  "+line)
+		}
+	}
+	if len(warnings) > 0 {
+		lines = append(lines, "", StyleWarning.Render(fmt.Sprintf("⚠ %d warnings", len(warnings))))
+		for i, w := range warnings {
+			lines = append(lines, StyleWarning.Render(fmt.Sprintf("  %d. %s", i+1, w.Title)))
+		}
+		lines = append(lines, StyleDimmed.Render("  \\warnings <n> explains one, \\warnings re-checks and explains them all"))
+	}
+	lines = append(lines, "", StyleDimmed.Render("  Enter on a table browses it; type SQL below and press Enter to run it."))
+	return lines
+}
+
+// warningsCommand implements \warnings.
+func (v *MainView) warningsCommand(args []string) tea.Cmd {
+	v.input.Reset()
+	v.pagTable, v.pagPlan = "", false
+	if len(args) == 0 {
+		v.loading = true
+		id := v.newResultRequest()
+		database := v.db
+		return func() tea.Msg {
+			warnings, err := database.SanityCheck(context.Background())
+			return SanityMsg{ID: id, Warnings: warnings, Err: err}
+		}
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n < 1 || n > len(v.warnings) {
+		v.viewport.SetContent(StyleError.Render(fmt.Sprintf("\\warnings: there are %d warnings", len(v.warnings))))
+		return nil
+	}
+	v.viewport.SetContentLines(warningLines(v.warnings[n-1 : n]))
+	return nil
+}
+
+// updateSanity shows the re-run checks.
+func (v *MainView) updateSanity(msg SanityMsg) {
+	if msg.ID != v.resultReq {
+		return
+	}
+	v.loading = false
+	v.warnings = msg.Warnings
+	lines := warningLines(msg.Warnings)
+	if len(msg.Warnings) == 0 {
+		lines = []string{StyleSuccess.Render("✓ No warnings")}
+	}
+	if msg.Err != nil {
+		lines = append(lines, "", StyleError.Render("Some checks failed: "+msg.Err.Error()))
+	}
+	v.viewport.SetContentLines(lines)
+}
+
+// warningLines renders warnings in full.
+func warningLines(warnings []db.SanityWarning) []string {
+	var lines []string
+	for i, w := range warnings {
+		if i > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, StyleWarning.Render("⚠ "+w.Title))
+		for _, line := range strings.Split(w.Detail, "\n") {
+			lines = append(lines, "  "+line)
+		}
+	}
+	return lines
+}
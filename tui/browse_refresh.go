@@ -0,0 +1,131 @@
+// browse_refresh.go implements auto-refresh of a browsed table, for
+// watching a job queue drain or a backfill progress:
+//
+//	R                    in the results: toggle refreshing every 5s
+//	\refresh 10s         refresh the browsed table every 10s
+//	\refresh off         stop
+//
+// The page header shows the interval and the time of the last refresh.
+// The focused row stays in place across refreshes, and a refresh is
+// skipped while another statement is running. Browsing another table or
+// leaving the view stops it.
+package tui
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// defaultRefreshInterval is the interval R starts refreshing at.
+const defaultRefreshInterval = 5 * time.Second
+
+// browseRefresh is the auto-refresh of a browsed table.
+type browseRefresh struct {
+	table    string
+	interval time.Duration
+	gen      int
+}
+
+// browseRefreshTickMsg is the next refresh of the generation gen.
+type browseRefreshTickMsg struct{ gen int }
+
+// toggleRefresh implements R.
+func (v *MainView) toggleRefresh() tea.Cmd {
+	if v.refresh != nil {
+		return v.stopRefresh()
+	}
+	return v.startRefresh(defaultRefreshInterval)
+}
+
+// refreshCommand implements \refresh.
+func (v *MainView) refreshCommand(args []string) tea.Cmd {
+	v.input.Reset()
+	status := func(text string) tea.Cmd { return func() tea.Msg { return StatusMsg(text) } }
+	switch {
+	case len(args) == 0:
+		if v.refresh == nil {
+			return status("Auto-refresh is off; \\refresh <interval> starts it, e.g. \\refresh 10s")
+		}
+		return status(fmt.Sprintf("Refreshing %s every %s; \\refresh off stops", v.refresh.table, v.refresh.interval))
+	case len(args) == 1 && args[0] == "off":
+		if v.refresh == nil {
+			return nil
+		}
+		return v.stopRefresh()
+	case len(args) == 1:
+		interval, err := parseWatchInterval(args[0])
+		if err != nil {
+			return status("\\refresh: " + err.Error())
+		}
+		return v.startRefresh(interval)
+	}
+	return status("Usage: \\refresh <interval> | \\refresh off")
+}
+
+// startRefresh refreshes the browsed table every interval, starting now.
+func (v *MainView) startRefresh(interval time.Duration) tea.Cmd {
+	if v.pagTable == "" {
+		return func() tea.Msg {
+			return StatusMsg("Auto-refresh works while browsing a table (Enter in the table list)")
+		}
+	}
+	v.refreshGen++
+	v.refresh = &browseRefresh{table: v.pagTable, interval: interval, gen: v.refreshGen}
+	return v.refreshPage()
+}
+
+// stopRefresh stops the auto-refresh; a pending tick is ignored.
+func (v *MainView) stopRefresh() tea.Cmd {
+	table := v.refresh.table
+	v.refresh = nil
+	return func() tea.Msg { return StatusMsg("Stopped refreshing " + table) }
+}
+
+// updateRefresh handles a refresh tick.
+func (v *MainView) updateRefresh(msg browseRefreshTickMsg) tea.Cmd {
+	r := v.refresh
+	if r == nil || r.gen != msg.gen {
+		return nil
+	}
+	if v.pagTable != r.table || v.pagPlan {
+		return v.stopRefresh()
+	}
+	if v.loading {
+		return v.refreshTick() // try again next time
+	}
+	return v.refreshPage()
+}
+
+// refreshPage fetches the browsed page again, keeping the focused row,
+// and schedules the next refresh.
+func (v *MainView) refreshPage() tea.Cmd {
+	if row := v.focusedRow(); row >= 0 {
+		v.gotoPending = v.rowOffset() + row + 1
+	}
+	fetch := v.fetchPage()
+	refresh := func() tea.Msg {
+		msg := fetch()
+		if m, ok := msg.(QueryResultMsg); ok {
+			m.Refresh = true
+			return m
+		}
+		return msg
+	}
+	return tea.Batch(refresh, v.refreshTick())
+}
+
+// refreshTick schedules the next refresh.
+func (v *MainView) refreshTick() tea.Cmd {
+	gen := v.refresh.gen
+	return tea.Tick(v.refresh.interval, func(time.Time) tea.Msg { return browseRefreshTickMsg{gen: gen} })
+}
+
+// refreshInterval returns the auto-refresh interval of table, or 0.
+func (v *MainView) refreshInterval(table string) time.Duration {
+	if v.refresh == nil || v.refresh.table != table {
+		return 0
+	}
+	return v.refresh.interval
+}
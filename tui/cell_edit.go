@@ -0,0 +1,313 @@
+// cell_edit.go implements editing a cell of a browsed table (e in the
+// results): the focused row's key is looked up (primary key, else a NOT
+// NULL unique index, else the ctid the page was read with), the new value
+// is typed over the old one, and the
+// generated UPDATE is shown for confirmation before it runs — inside the
+// open transaction, if there is one. The page is reloaded afterwards.
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/DachengChen/paiSQL/applog"
+	"github.com/DachengChen/paiSQL/db"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type cellEditStep int
+
+const (
+	cellEditLoading cellEditStep = iota // looking up the row key
+	cellEditInput                       // typing the new value
+	cellEditReview                      // the UPDATE; y runs it
+	cellEditRunning                     // waiting for the UPDATE
+)
+
+// cellEdit is the cell editor shown over the results pane.
+type cellEdit struct {
+	table  string // table list name
+	step   cellEditStep
+	column string
+	old    string // the value shown in the grid
+	input  textInput
+	null   bool // set NULL instead of input
+	key    *db.RowKey
+	sql    string
+	err    error
+
+	// The focused row when e was pressed, and its ctid if the page has them
+	columns []string
+	types   []string
+	row     []string
+	ctid    string
+}
+
+// openCellEdit starts editing the focused cell of the browsed table.
+func (v *MainView) openCellEdit() tea.Cmd {
+	status := func(text string) tea.Cmd { return func() tea.Msg { return StatusMsg(text) } }
+	if v.pagTable == "" {
+		return status("Editing cells works while browsing a table (Enter in the table list)")
+	}
+	col, cell, ok := v.focusedCell()
+	if !ok {
+		return status("Editing cells needs the result grid (x leaves expanded display, w wrapping)")
+	}
+	row := v.focusedRow()
+	e := &cellEdit{
+		table:   v.pagTable,
+		column:  v.result.Columns[col],
+		old:     cell,
+		columns: slices.Clone(v.result.Columns),
+		types:   slices.Clone(v.result.ColumnTypes),
+		row:     slices.Clone(v.result.Rows[row]),
+		ctid:    v.focusedCtid(row),
+	}
+	if cell == nullCell {
+		e.null = true
+	} else {
+		e.input.SetValue(cell)
+	}
+	v.cellEdit = e
+	schema, name := v.tableRef(e.table)
+	database := v.db
+	return func() tea.Msg {
+		key, err := database.TableRowKey(context.Background(), schema, name)
+		return CellEditKeyMsg{Table: e.table, Key: key, Err: err}
+	}
+}
+
+// buildUpdate generates the UPDATE of the edited cell from the row key
+// values in the grid.
+func (e *cellEdit) buildUpdate() (string, error) {
+	literals, err := rowKeyLiterals(e.key, e.columns, e.types, e.row, e.ctid)
+	if err != nil {
+		return "", err
+	}
+	var value *string
+	if !e.null {
+		input := e.input.Value()
+		value = &input
+	}
+	return e.key.UpdateCell(e.column, value, literals)
+}
+
+// rowKeyLiterals returns the literals of key's columns in a grid row, for
+// RowKey.Where. A ctid key uses the row's ctid from when the page was
+// read, which RowKey.Warning cautions about.
+func rowKeyLiterals(key *db.RowKey, columns, types, row []string, ctid string) ([]string, error) {
+	if key.Kind == db.RowKeyCtid {
+		if ctid == "" {
+			// The key was looked up for an earlier page, or changed since.
+			return nil, fmt.Errorf("%s is matched by ctid, which this page wasn't read with: reload it and try again", key.Table)
+		}
+		literal, err := db.ValueLiteral("tid", ctid)
+		if err != nil {
+			return nil, err
+		}
+		return []string{literal}, nil
+	}
+	literals := make([]string, len(key.Columns))
+	for i, name := range key.Columns {
+		col := slices.Index(columns, name)
+		if col < 0 || col >= len(row) {
+			return nil, fmt.Errorf("key column %s isn't shown (C picks the columns)", name)
+		}
+		colType := ""
+		if col < len(types) {
+			colType = types[col]
+		}
+		if row[col] == nullCell {
+			return nil, fmt.Errorf("key column %s is NULL", name)
+		}
+		literal, err := db.ValueLiteral(colType, row[col])
+		if err != nil {
+			return nil, fmt.Errorf("key column %s: %w", name, err)
+		}
+		literals[i] = literal
+	}
+	return literals, nil
+}
+
+func (v *MainView) handleCellEditKey(msg tea.KeyMsg) (View, tea.Cmd) {
+	e := v.cellEdit
+	key := msg.String()
+	switch e.step {
+	case cellEditLoading:
+		if key == "esc" {
+			v.cellEdit = nil
+		}
+
+	case cellEditInput:
+		switch key {
+		case "esc":
+			v.cellEdit = nil
+		case "enter":
+			sql, err := e.buildUpdate()
+			if err != nil {
+				e.err = err
+				break
+			}
+			e.sql, e.err, e.step = v.styleSQL(sql), nil, cellEditReview
+		case "ctrl+n":
+			e.null = !e.null
+		case "ctrl+u":
+			e.input.Reset()
+			e.null = false
+		default:
+			// Editing the text, or backspace on NULL, takes the typed value.
+			before := e.input.Value()
+			if e.input.Update(msg) && (e.input.Value() != before || key == "backspace") {
+				e.null = false
+			}
+		}
+
+	case cellEditReview:
+		switch key {
+		case "y":
+			return v, v.runCellEdit()
+		case "esc", "n":
+			e.step, e.err = cellEditInput, nil
+		}
+	}
+	return v, nil
+}
+
+// runCellEdit executes the reviewed UPDATE.
+func (v *MainView) runCellEdit() tea.Cmd {
+	e := v.cellEdit
+	// It shares the connection with the statements of the results pane
+	// (the open transaction's, which can't run two at once), so it waits
+	// for them like a typed statement, and they queue behind it.
+	if v.loading {
+		e.err = errors.New("a statement is still running: press y again once it is done")
+		return nil
+	}
+	v.loading = true
+	e.step = cellEditRunning
+	applog.Event("SQL", "Cell edit confirmed: %s", e.sql)
+	run, sql, table := v.queryRunner(), e.sql, e.table
+	ctx := v.queryContext()
+	return func() tea.Msg {
+		result, err := run(ctx, sql)
+		return CellEditDoneMsg{Table: table, SQL: sql, Result: result, Err: err}
+	}
+}
+
+// splitCtids takes the leading ctid column of a page read by ctid off r
+// and returns its values, row by row.
+func splitCtids(r *db.QueryResult) []string {
+	if len(r.Columns) == 0 || r.Columns[0] != "ctid" {
+		return nil
+	}
+	r.Columns = r.Columns[1:]
+	if len(r.ColumnTypes) > 0 {
+		r.ColumnTypes = r.ColumnTypes[1:]
+	}
+	ctids := make([]string, len(r.Rows))
+	for i, row := range r.Rows {
+		if len(row) > 0 {
+			ctids[i], r.Rows[i] = row[0], row[1:]
+		}
+	}
+	return ctids
+}
+
+// focusedCtid returns the ctid of row i of the page, or "" when the page
+// wasn't read with ctids.
+func (v *MainView) focusedCtid(i int) string {
+	if i < 0 || i >= len(v.pagCtids) {
+		return ""
+	}
+	return v.pagCtids[i]
+}
+
+// updateCellEdit handles the cell editor's messages.
+func (v *MainView) updateCellEdit(msg tea.Msg) tea.Cmd {
+	e := v.cellEdit
+	switch msg := msg.(type) {
+	case CellEditKeyMsg:
+		if e == nil || e.step != cellEditLoading || e.table != msg.Table {
+			return nil
+		}
+		if msg.Err != nil {
+			v.cellEdit = nil
+			return func() tea.Msg { return StatusMsg("Edit cell: " + msg.Err.Error()) }
+		}
+		e.key, e.step = msg.Key, cellEditInput
+
+	case CellEditDoneMsg:
+		v.loading = false
+		v.noteTxError(msg.Err)
+		if msg.Err != nil {
+			applog.Event("SQL", "Cell edit failed: %s: %v", msg.SQL, msg.Err)
+			if e != nil && e.step == cellEditRunning && e.table == msg.Table {
+				e.err, e.step = msg.Err, cellEditReview
+			}
+			return nil
+		}
+		if e != nil && e.table == msg.Table {
+			v.cellEdit = nil
+		}
+		text := "Updated: " + msg.SQL
+		if msg.Result != nil && msg.Result.Status == "UPDATE 0" {
+			text = "No row was updated — it changed or was deleted since the page was read"
+		}
+		if v.pagTable != msg.Table {
+			return func() tea.Msg { return StatusMsg(text) }
+		}
+		return tea.Batch(func() tea.Msg { return StatusMsg(text) }, v.fetchPage())
+	}
+	return nil
+}
+
+// renderCellEdit renders the cell editor in place of the results.
+func (v *MainView) renderCellEdit() []string {
+	e := v.cellEdit
+	lines := []string{StyleBold.Render("✏️  Edit " + e.table + "." + e.column), ""}
+	if e.step == cellEditLoading {
+		return append(lines, StyleDimmed.Render("Finding the row's key…"))
+	}
+
+	key := strings.Join(e.key.KeyColumns(), ", ")
+	lines = append(lines,
+		StyleDimmed.Render(fmt.Sprintf("Row by %s (%s)", e.key.Kind, key)),
+		"Current: "+truncateRunes(e.old, max(v.width-12, 20)),
+		"")
+	switch e.step {
+	case cellEditInput:
+		value := e.input.View()
+		if e.null {
+			value = StyleDimmed.Render("NULL") + "█"
+		}
+		lines = append(lines, StylePrompt.Render("New value: ")+value, "")
+		if e.err != nil {
+			lines = append(lines, StyleError.Render("Error: "+e.err.Error()), "")
+		}
+		lines = append(lines, StyleDimmed.Render("Enter review · Ctrl+N NULL · Ctrl+U clear · Esc cancel"))
+
+	case cellEditReview, cellEditRunning:
+		lines = append(lines, "  "+highlightSQL(e.sql+";"), "")
+		if w := e.key.Warning(); w != "" {
+			for _, l := range wrapLines(w, max(v.width-4, 20)) {
+				lines = append(lines, StyleWarning.Render(l))
+			}
+			lines = append(lines, "")
+		}
+		if v.tx != nil {
+			lines = append(lines, StyleWarning.Render("Runs inside the open transaction; \\commit saves it"), "")
+		}
+		if e.err != nil {
+			lines = append(lines, StyleError.Render("Error: "+e.err.Error()), "")
+		}
+		if e.step == cellEditRunning {
+			lines = append(lines, StyleDimmed.Render("Running…"))
+		} else {
+			lines = append(lines, StyleDimmed.Render("y run · Esc back"))
+		}
+	}
+	return lines
+}
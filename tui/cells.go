@@ -0,0 +1,152 @@
+// cells.go converts raw result values into compact grid text based on
+// the column's PostgreSQL type. Special types (pgvector, PostGIS) would
+// otherwise blow out column widths and make the grid unreadable.
+package tui
+
+import (
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/DachengChen/paiSQL/config"
+	"github.com/DachengChen/paiSQL/db"
+)
+
+// vectorPreviewElems is the number of leading vector elements shown in the grid.
+const vectorPreviewElems = 3
+
+// displayTimeLayout is how timestamptz values are shown (psql style).
+const displayTimeLayout = "2006-01-02 15:04:05.999999-07"
+
+// displayCell returns the grid representation of a single value.
+func displayCell(colType, cell string, opts config.DisplayConfig) string {
+	switch {
+	case db.IsVectorType(colType):
+		return db.SummarizeVector(cell, vectorPreviewElems)
+	case db.IsGeometryType(colType):
+		return db.SummarizeGeometry(cell)
+	}
+	return formatScalar(colType, cell, opts)
+}
+
+// formatScalar applies the number and time display options. Unlike
+// displayCell it never abbreviates, so expanded display can use it.
+func formatScalar(colType, cell string, opts config.DisplayConfig) string {
+	switch {
+	case colType == "timestamptz":
+		return formatTimestamptz(cell, opts.LocalTime)
+	case isNumericType(colType):
+		return formatNumber(colType, cell, opts)
+	}
+	return cell
+}
+
+// isNumericType reports whether a pg_type name holds numbers.
+func isNumericType(t string) bool {
+	switch t {
+	case "int2", "int4", "int8", "float4", "float8", "numeric", "money":
+		return true
+	}
+	return false
+}
+
+// displayRows applies displayCell to every value of a result.
+func displayRows(r *db.QueryResult, opts config.DisplayConfig) [][]string {
+	if len(r.ColumnTypes) == 0 {
+		return r.Rows
+	}
+	rows := make([][]string, len(r.Rows))
+	for i, row := range r.Rows {
+		out := make([]string, len(row))
+		for j, cell := range row {
+			if j < len(r.ColumnTypes) {
+				out[j] = displayCell(r.ColumnTypes[j], cell, opts)
+			} else {
+				out[j] = cell
+			}
+		}
+		rows[i] = out
+	}
+	return rows
+}
+
+// formatTimestamptz renders a timestamptz in UTC or the local zone.
+func formatTimestamptz(cell string, local bool) string {
+	t, err := time.Parse(pgTimeLayout, cell)
+	if err != nil {
+		return cell
+	}
+	if local {
+		return t.Local().Format(displayTimeLayout)
+	}
+	return t.UTC().Format(displayTimeLayout)
+}
+
+// formatNumber applies fixed decimals and locale separators to a number.
+// Values that aren't plain decimals (NaN, Infinity, money) are unchanged.
+func formatNumber(colType, cell string, opts config.DisplayConfig) string {
+	if colType == "money" || (!opts.NumericLocale && opts.Decimals == nil) {
+		return cell
+	}
+	integer := colType == "int2" || colType == "int4" || colType == "int8"
+	if opts.Decimals != nil && !integer {
+		r, ok := new(big.Rat).SetString(cell)
+		if !ok {
+			return cell
+		}
+		cell = r.FloatString(*opts.Decimals)
+	}
+	if !opts.NumericLocale {
+		return cell
+	}
+
+	intPart, frac, hasFrac := strings.Cut(cell, ".")
+	sign := ""
+	if strings.HasPrefix(intPart, "-") {
+		sign, intPart = "-", intPart[1:]
+	}
+	for _, ch := range intPart {
+		if ch < '0' || ch > '9' {
+			return cell // e.g. 1e+06, NaN
+		}
+	}
+
+	thousands, decimal := localeSeparators()
+	var sb strings.Builder
+	for i, ch := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			sb.WriteString(thousands)
+		}
+		sb.WriteRune(ch)
+	}
+	out := sign + sb.String()
+	if hasFrac {
+		out += decimal + frac
+	}
+	return out
+}
+
+// commaDecimalLanguages use "." for thousands and "," for decimals.
+var commaDecimalLanguages = map[string]bool{
+	"de": true, "es": true, "fr": true, "it": true, "nl": true, "pt": true,
+	"ru": true, "pl": true, "tr": true, "da": true, "sv": true, "nb": true,
+	"fi": true, "cs": true, "id": true, "vi": true, "el": true, "uk": true,
+}
+
+// localeSeparators returns the thousands and decimal separators for the
+// user's numeric locale (LC_ALL, then LC_NUMERIC, then LANG).
+func localeSeparators() (thousands, decimal string) {
+	locale := ""
+	for _, env := range []string{"LC_ALL", "LC_NUMERIC", "LANG"} {
+		if v := os.Getenv(env); v != "" {
+			locale = v
+			break
+		}
+	}
+	lang, _, _ := strings.Cut(strings.ToLower(locale), "_")
+	if commaDecimalLanguages[lang] {
+		return ".", ","
+	}
+	return ",", "."
+}
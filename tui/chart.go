@@ -0,0 +1,317 @@
+// chart.go renders quick terminal charts of result sets for visual
+// sanity checks without leaving the TUI.
+//
+// Line charts use Braille characters: each terminal cell holds a 2×4
+// dot matrix, giving 2× horizontal and 4× vertical resolution.
+package tui
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DachengChen/paiSQL/db"
+)
+
+// pgTimeLayout matches how time.Time values are rendered by db.FormatValue.
+const pgTimeLayout = "2006-01-02 15:04:05.999999999 -0700 MST"
+
+// isTimeType reports whether a pg_type name holds timestamps or dates.
+func isTimeType(t string) bool {
+	switch t {
+	case "timestamp", "timestamptz", "date":
+		return true
+	}
+	return false
+}
+
+// timeSeriesColumns returns the first timestamp column and the first
+// numeric column of a result, or ok=false if either is missing.
+func timeSeriesColumns(r *db.QueryResult) (timeCol, valueCol int, ok bool) {
+	timeCol, valueCol = -1, -1
+	for i, t := range r.ColumnTypes {
+		if timeCol < 0 && isTimeType(t) {
+			timeCol = i
+		} else if valueCol < 0 && isNumericType(t) {
+			valueCol = i
+		}
+	}
+	return timeCol, valueCol, timeCol >= 0 && valueCol >= 0
+}
+
+type chartPoint struct {
+	t time.Time
+	y float64
+}
+
+// renderTimeSeriesChart plots a result's time/value columns as a Braille
+// line chart that fits in width×height cells (including axis labels).
+func renderTimeSeriesChart(r *db.QueryResult, timeCol, valueCol, width, height int) []string {
+	var pts []chartPoint
+	for _, row := range r.Rows {
+		if timeCol >= len(row) || valueCol >= len(row) {
+			continue
+		}
+		t, err := time.Parse(pgTimeLayout, row[timeCol])
+		if err != nil {
+			continue
+		}
+		y, err := strconv.ParseFloat(row[valueCol], 64)
+		if err != nil {
+			continue
+		}
+		pts = append(pts, chartPoint{t: t, y: y})
+	}
+
+	title := fmt.Sprintf("📈 %s over %s  (%d points)", r.Columns[valueCol], r.Columns[timeCol], len(pts))
+	if len(pts) < 2 {
+		return []string{title, "", StyleDimmed.Render("Not enough plottable points for a chart.")}
+	}
+	sort.Slice(pts, func(i, j int) bool { return pts[i].t.Before(pts[j].t) })
+
+	minY, maxY := pts[0].y, pts[0].y
+	for _, p := range pts {
+		minY = math.Min(minY, p.y)
+		maxY = math.Max(maxY, p.y)
+	}
+	if minY == maxY {
+		minY--
+		maxY++
+	}
+	minT := pts[0].t.Unix()
+	maxT := pts[len(pts)-1].t.Unix()
+	if minT == maxT {
+		maxT++
+	}
+
+	yLabelMax := formatChartNumber(maxY)
+	yLabelMin := formatChartNumber(minY)
+	labelW := len(yLabelMax)
+	if len(yLabelMin) > labelW {
+		labelW = len(yLabelMin)
+	}
+
+	// Plot area in cells: title(1) + blank(1) + x-axis(2) rows of chrome
+	plotW := width - labelW - 2
+	plotH := height - 4
+	if plotW < 10 || plotH < 3 {
+		return []string{title, "", StyleDimmed.Render("Viewport too small for a chart.")}
+	}
+
+	canvas := newBrailleCanvas(plotW, plotH)
+	dotsW, dotsH := plotW*2, plotH*4
+	toDot := func(p chartPoint) (int, int) {
+		x := int(float64(p.t.Unix()-minT) / float64(maxT-minT) * float64(dotsW-1))
+		y := int((maxY - p.y) / (maxY - minY) * float64(dotsH-1))
+		return x, y
+	}
+	px, py := toDot(pts[0])
+	for _, p := range pts[1:] {
+		x, y := toDot(p)
+		canvas.line(px, py, x, y)
+		px, py = x, y
+	}
+
+	lines := []string{title, ""}
+	for i, row := range canvas.rows() {
+		label := ""
+		switch i {
+		case 0:
+			label = yLabelMax
+		case plotH - 1:
+			label = yLabelMin
+		}
+		lines = append(lines, fmt.Sprintf("%*s ┤%s", labelW, label, row))
+	}
+	lines = append(lines, strings.Repeat(" ", labelW+1)+"└"+strings.Repeat("─", plotW))
+
+	layout := "2006-01-02 15:04"
+	if maxT-minT > 7*24*3600 {
+		layout = "2006-01-02"
+	}
+	start := pts[0].t.Format(layout)
+	end := pts[len(pts)-1].t.Format(layout)
+	gap := plotW - len(start) - len(end)
+	if gap < 1 {
+		gap = 1
+	}
+	lines = append(lines, strings.Repeat(" ", labelW+2)+start+strings.Repeat(" ", gap)+end)
+	return lines
+}
+
+// formatChartNumber renders an axis label compactly.
+func formatChartNumber(f float64) string {
+	if f == math.Trunc(f) && math.Abs(f) < 1e15 {
+		return strconv.FormatInt(int64(f), 10)
+	}
+	return strconv.FormatFloat(f, 'g', 6, 64)
+}
+
+// brailleCanvas is a dot matrix backed by Braille cells.
+type brailleCanvas struct {
+	w, h  int // in cells
+	cells [][]rune
+}
+
+func newBrailleCanvas(w, h int) *brailleCanvas {
+	c := &brailleCanvas{w: w, h: h, cells: make([][]rune, h)}
+	for i := range c.cells {
+		c.cells[i] = make([]rune, w)
+	}
+	return c
+}
+
+// brailleBits maps a dot position within a cell (x 0-1, y 0-3) to its bit.
+var brailleBits = [2][4]rune{
+	{0x01, 0x02, 0x04, 0x40},
+	{0x08, 0x10, 0x20, 0x80},
+}
+
+func (c *brailleCanvas) set(x, y int) {
+	cx, cy := x/2, y/4
+	if cx < 0 || cy < 0 || cx >= c.w || cy >= c.h {
+		return
+	}
+	c.cells[cy][cx] |= brailleBits[x%2][y%4]
+}
+
+// line draws a straight line between two dots (Bresenham).
+func (c *brailleCanvas) line(x0, y0, x1, y1 int) {
+	dx := abs(x1 - x0)
+	dy := -abs(y1 - y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	err := dx + dy
+	for {
+		c.set(x0, y0)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * err
+		if e2 >= dy {
+			err += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			err += dx
+			y0 += sy
+		}
+	}
+}
+
+func (c *brailleCanvas) rows() []string {
+	out := make([]string, c.h)
+	for i, row := range c.cells {
+		var sb strings.Builder
+		for _, bits := range row {
+			if bits == 0 {
+				sb.WriteRune(' ')
+			} else {
+				sb.WriteRune(0x2800 + bits)
+			}
+		}
+		out[i] = sb.String()
+	}
+	return out
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// Bar chart sort orders, cycled with s while a bar chart is shown.
+const (
+	barSortValueDesc = iota
+	barSortValueAsc
+	barSortLabel
+	barSortCount
+)
+
+var barSortNames = [barSortCount]string{"value ↓", "value ↑", "label"}
+
+// isBarChartable reports whether a result looks like (label, number),
+// e.g. SELECT status, count(*) ... GROUP BY status.
+func isBarChartable(r *db.QueryResult) bool {
+	return len(r.Columns) == 2 && len(r.ColumnTypes) == 2 && isNumericType(r.ColumnTypes[1])
+}
+
+type barItem struct {
+	label string
+	value float64
+	text  string
+}
+
+// renderBarChart plots a (label, number) result as horizontal bars with
+// value labels, fitted to width cells. Long charts scroll in the viewport.
+func renderBarChart(r *db.QueryResult, sortMode, width int) []string {
+	var items []barItem
+	for _, row := range r.Rows {
+		if len(row) < 2 {
+			continue
+		}
+		f, err := strconv.ParseFloat(row[1], 64)
+		if err != nil {
+			continue
+		}
+		items = append(items, barItem{label: row[0], value: f, text: row[1]})
+	}
+
+	title := fmt.Sprintf("📊 %s by %s  (%d bars, sorted by %s — s to change)",
+		r.Columns[1], r.Columns[0], len(items), barSortNames[sortMode])
+	if len(items) == 0 {
+		return []string{title, "", StyleDimmed.Render("No numeric values to chart.")}
+	}
+
+	switch sortMode {
+	case barSortValueDesc:
+		sort.SliceStable(items, func(i, j int) bool { return items[i].value > items[j].value })
+	case barSortValueAsc:
+		sort.SliceStable(items, func(i, j int) bool { return items[i].value < items[j].value })
+	case barSortLabel:
+		sort.SliceStable(items, func(i, j int) bool { return items[i].label < items[j].label })
+	}
+
+	labelW, textW := 0, 0
+	maxAbs := 0.0
+	for _, it := range items {
+		labelW = max(labelW, len([]rune(it.label)))
+		textW = max(textW, len(it.text))
+		maxAbs = math.Max(maxAbs, math.Abs(it.value))
+	}
+	if labelW > width/3 {
+		labelW = width / 3
+	}
+	barW := width - labelW - textW - 4
+	if barW < 5 {
+		return []string{title, "", StyleDimmed.Render("Viewport too small for a chart.")}
+	}
+
+	lines := []string{title, ""}
+	for _, it := range items {
+		label := []rune(it.label)
+		if len(label) > labelW {
+			label = append(label[:labelW-1], '…')
+		}
+		n := 0
+		if maxAbs > 0 {
+			n = int(math.Round(math.Abs(it.value) / maxAbs * float64(barW)))
+		}
+		bar := strings.Repeat("█", n)
+		if n == 0 && it.value != 0 {
+			bar = "▏"
+		}
+		lines = append(lines, fmt.Sprintf("%-*s │%s %s", labelW, string(label), bar, it.text))
+	}
+	return lines
+}
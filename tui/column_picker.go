@@ -0,0 +1,249 @@
+// column_picker.go implements the column picker (C in the table list or
+// while browsing a table): choose which columns the paginated SELECT
+// fetches and their order, so an 80-column table shows the few that
+// matter and pages faster. The choice is saved per table (see
+// config.TablePrefs) and used whenever the table is browsed again.
+package tui
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/DachengChen/paiSQL/applog"
+	"github.com/DachengChen/paiSQL/config"
+	"github.com/DachengChen/paiSQL/db"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// columnPicker is the column picker shown over the results pane.
+type columnPicker struct {
+	table   string // table list name
+	browse  bool   // browse the table once applied (opened from the table list)
+	loading bool
+	columns []db.ColumnInfo // in table order
+	items   []pickerColumn  // in display order
+	cursor  int
+	err     error
+}
+
+// pickerColumn is a row of the picker.
+type pickerColumn struct {
+	name     string
+	dataType string
+	on       bool
+}
+
+// openColumnPicker opens the picker for table and loads its columns.
+func (v *MainView) openColumnPicker(table string, browse bool) tea.Cmd {
+	v.picker = &columnPicker{table: table, browse: browse, loading: true}
+	schema, name := v.tableRef(table)
+	database := v.db
+	return func() tea.Msg {
+		ts, err := database.FetchTableSchema(context.Background(), schema, name)
+		if err != nil {
+			return PickerColumnsMsg{Table: table, Err: err}
+		}
+		return PickerColumnsMsg{Table: table, Columns: ts.Columns}
+	}
+}
+
+// loadTablePrefs loads the table preferences of a connection. Browsing
+// works without them; the picker then just doesn't remember its choice.
+func loadTablePrefs(connName string) *config.TablePrefs {
+	prefs, err := config.LoadTablePrefs(connName)
+	if err != nil {
+		applog.Error("Failed to load table preferences: %v", err)
+		return nil
+	}
+	return prefs
+}
+
+// tablePref returns the saved preference of table.
+func (v *MainView) tablePref(table string) config.TablePref {
+	if v.tablePrefs == nil {
+		return config.TablePref{}
+	}
+	return v.tablePrefs.Get(table)
+}
+
+// browseColumns returns the saved columns of table, or nil for all.
+func (v *MainView) browseColumns(table string) []string {
+	return v.tablePref(table).Columns
+}
+
+// setItems fills the picker from the saved columns: those first, in their
+// saved order, then the rest of the table's columns, unchecked. Saved
+// columns the table no longer has are dropped.
+func (p *columnPicker) setItems(saved []string) {
+	p.items = nil
+	if len(saved) == 0 {
+		for _, c := range p.columns {
+			p.items = append(p.items, pickerColumn{name: c.Name, dataType: c.DataType, on: true})
+		}
+		return
+	}
+	for _, name := range saved {
+		if i := slices.IndexFunc(p.columns, func(c db.ColumnInfo) bool { return c.Name == name }); i >= 0 {
+			p.items = append(p.items, pickerColumn{name: name, dataType: p.columns[i].DataType, on: true})
+		}
+	}
+	for _, c := range p.columns {
+		if !slices.Contains(saved, c.Name) {
+			p.items = append(p.items, pickerColumn{name: c.Name, dataType: c.DataType})
+		}
+	}
+}
+
+// chosen returns the checked columns in order, or nil when that is every
+// column in table order, which is what SELECT * returns.
+func (p *columnPicker) chosen() []string {
+	var cols []string
+	for _, it := range p.items {
+		if it.on {
+			cols = append(cols, it.name)
+		}
+	}
+	if len(cols) == len(p.columns) {
+		all := true
+		for i, c := range p.columns {
+			all = all && cols[i] == c.Name
+		}
+		if all {
+			return nil
+		}
+	}
+	return cols
+}
+
+func (v *MainView) handlePickerKey(msg tea.KeyMsg) (View, tea.Cmd) {
+	p := v.picker
+	if p.loading {
+		if msg.String() == "esc" {
+			v.picker = nil
+		}
+		return v, nil
+	}
+
+	switch msg.String() {
+	case "esc", "q":
+		v.picker = nil
+	case "up", "k":
+		if p.cursor > 0 {
+			p.cursor--
+		}
+	case "down", "j":
+		if p.cursor < len(p.items)-1 {
+			p.cursor++
+		}
+	case "K", "shift+up": // move the column up
+		if p.cursor > 0 {
+			p.items[p.cursor-1], p.items[p.cursor] = p.items[p.cursor], p.items[p.cursor-1]
+			p.cursor--
+		}
+	case "J", "shift+down": // move the column down
+		if p.cursor < len(p.items)-1 {
+			p.items[p.cursor+1], p.items[p.cursor] = p.items[p.cursor], p.items[p.cursor+1]
+			p.cursor++
+		}
+	case " ":
+		p.items[p.cursor].on = !p.items[p.cursor].on
+	case "a": // check all, or uncheck all when all are checked
+		all := !slices.ContainsFunc(p.items, func(it pickerColumn) bool { return !it.on })
+		for i := range p.items {
+			p.items[i].on = !all
+		}
+	case "r": // every column in table order
+		p.setItems(nil)
+	case "enter":
+		return v, v.applyColumnPicker()
+	}
+	p.err = nil
+	return v, nil
+}
+
+// applyColumnPicker saves the chosen columns and browses the table with
+// them.
+func (v *MainView) applyColumnPicker() tea.Cmd {
+	p := v.picker
+	cols := p.chosen()
+	if cols != nil && len(cols) == 0 {
+		p.err = fmt.Errorf("check at least one column with Space")
+		return nil
+	}
+	v.picker = nil
+	if v.tablePrefs == nil {
+		return func() tea.Msg { return StatusMsg("Columns: table preferences could not be loaded (see the app log)") }
+	}
+
+	var status tea.Cmd
+	pref := v.tablePref(p.table)
+	pref.Columns = cols
+	if err := v.tablePrefs.Set(p.table, pref); err != nil {
+		applog.Error("Failed to save table preferences: %v", err)
+		status = func() tea.Msg { return StatusMsg("Columns not saved: " + err.Error()) }
+	}
+
+	switch {
+	case p.browse:
+		return tea.Batch(status, v.browseTable(p.table))
+	case v.pagTable == p.table:
+		return tea.Batch(status, v.fetchPage())
+	}
+	return status
+}
+
+// updateColumnPicker handles the picker's columns arriving.
+func (v *MainView) updateColumnPicker(msg PickerColumnsMsg) tea.Cmd {
+	p := v.picker
+	if p == nil || !p.loading || p.table != msg.Table {
+		return nil
+	}
+	if msg.Err != nil || len(msg.Columns) == 0 {
+		v.picker = nil
+		text := "Columns: " + msg.Table + " has no columns"
+		if msg.Err != nil {
+			text = "Columns: " + msg.Err.Error()
+		}
+		return func() tea.Msg { return StatusMsg(text) }
+	}
+	p.loading, p.columns = false, msg.Columns
+	p.setItems(v.browseColumns(p.table))
+	return nil
+}
+
+// renderColumnPicker renders the picker in place of the results.
+func (v *MainView) renderColumnPicker() []string {
+	p := v.picker
+	lines := []string{StyleBold.Render("▦ Columns of " + p.table), ""}
+	if p.loading {
+		return append(lines, StyleDimmed.Render("Loading columns…"))
+	}
+
+	// Wide tables scroll: show the rows around the cursor that fit.
+	first, last := 0, len(p.items)
+	if rows := v.viewport.height - 6; rows > 0 && last > rows {
+		first = min(max(p.cursor-rows/2, 0), last-rows)
+		last = first + rows
+	}
+	shown := 0
+	for i, it := range p.items {
+		mark := "[ ]"
+		if it.on {
+			mark = "[x]"
+			shown++
+		}
+		if i < first || i >= last {
+			continue
+		}
+		line := fmt.Sprintf("%s %-24s %s", mark, it.name, StyleDimmed.Render(it.dataType))
+		lines = append(lines, pickLine(line, i == p.cursor))
+	}
+	lines = append(lines, "",
+		StyleDimmed.Render(fmt.Sprintf("%d of %d columns shown, top to bottom as they will appear.", shown, len(p.items))),
+		StyleDimmed.Render("Space show/hide · K/J move · a all/none · r reset · Enter apply and save · Esc close"))
+	if p.err != nil {
+		lines = append(lines, "", StyleError.Render("Error: "+p.err.Error()))
+	}
+	return lines
+}
@@ -0,0 +1,82 @@
+// column_scroll.go shows where the results pane is panned to in a grid
+// wider than the pane: the range of columns in view ("cols 5–9 of 23")
+// next to the row position, and a thin scrollbar under the grid.
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// gridSpan is where a column sits in the lines of the grid, in runes.
+type gridSpan struct{ start, end int }
+
+// gridSpans lays out columns of the given widths like gridLine does and
+// returns each column's span and the width of a whole line.
+func gridSpans(widths []int, border int) (spans []gridSpan, total int) {
+	prefix, sep, suffix := 1, 3, 1 // " a │ b "
+	switch border {
+	case 0:
+		prefix, sep, suffix = 0, 1, 0 // "a b"
+	case 2:
+		prefix, sep, suffix = 2, 3, 2 // "│ a │ b │"
+	}
+	x := prefix
+	for i, w := range widths {
+		if i > 0 {
+			x += sep
+		}
+		spans = append(spans, gridSpan{x, x + w})
+		x += w
+	}
+	return spans, x + suffix
+}
+
+// visibleColumns returns the first and last column (0-based) at least
+// partly in view when the grid is wider than width, or ok false.
+func (v *MainView) visibleColumns(width int) (first, last int, ok bool) {
+	vp := v.viewport
+	if v.result == nil || v.expandedMode || v.chartMode || v.rightMode != rightModeData ||
+		vp.wrapText || len(v.gridCols) == 0 || v.gridWidth <= width {
+		return 0, 0, false
+	}
+	x0, x1 := vp.scrollX, vp.scrollX+width
+	first, last = len(v.gridCols)-1, 0
+	for i, c := range v.gridCols {
+		if c.end > x0 {
+			first = min(first, i)
+		}
+		if c.start < x1 {
+			last = i
+		}
+	}
+	return first, max(first, last), true
+}
+
+// columnPosition describes the columns in view, e.g. "cols 5–9 of 23".
+func (v *MainView) columnPosition(width int) string {
+	first, last, ok := v.visibleColumns(width)
+	if !ok {
+		return ""
+	}
+	if first == last {
+		return fmt.Sprintf("col %d of %d", first+1, len(v.gridCols))
+	}
+	return fmt.Sprintf("cols %d–%d of %d", first+1, last+1, len(v.gridCols))
+}
+
+// columnScrollbar draws a horizontal scrollbar width runes wide, the thumb
+// covering the part of the grid in view, or "" when the grid fits.
+func (v *MainView) columnScrollbar(width int) string {
+	if _, _, ok := v.visibleColumns(width); !ok {
+		return ""
+	}
+	total := v.gridWidth
+	thumb := max(width*width/total, 1)
+	pos := min(v.viewport.scrollX*width/total, width-thumb)
+	return StyleDimmed.Render(strings.Repeat("─", pos)) +
+		lipgloss.NewStyle().Foreground(ColorAccent).Render(strings.Repeat("━", thumb)) +
+		StyleDimmed.Render(strings.Repeat("─", width-pos-thumb))
+}
@@ -0,0 +1,432 @@
+// completion.go implements schema-aware completion in the SQL input.
+// Tab (or Ctrl+Space) completes the word before the cursor against the
+// columns of the tables the statement names, the table list and SQL
+// keywords. A single match is inserted right away; otherwise a popup
+// opens above the input and narrows as you keep typing. Columns are read
+// with DescribeTable the first time a table is referenced and cached for
+// the session.
+package tui
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/DachengChen/paiSQL/db"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/jackc/pgx/v5"
+)
+
+// completionRows is the most candidates the popup shows at once.
+const completionRows = 8
+
+// sqlKeywords are completed after the columns and tables.
+var sqlKeywords = []string{
+	"SELECT", "FROM", "WHERE", "JOIN", "LEFT", "RIGHT", "INNER", "OUTER",
+	"INSERT", "UPDATE", "DELETE", "CREATE", "ALTER", "DROP", "INDEX",
+	"ORDER", "GROUP", "HAVING", "LIMIT", "OFFSET", "BEGIN", "COMMIT",
+	"ROLLBACK", "AND", "OR", "NOT", "NULL", "INTO", "VALUES", "SET",
+	"TABLE", "AS", "ON", "IN", "LIKE", "ILIKE", "BETWEEN", "EXISTS",
+	"DISTINCT", "COUNT", "SUM", "AVG", "MIN", "MAX", "EXPLAIN", "ANALYZE",
+	"RETURNING", "USING", "WITH", "CASE", "WHEN", "THEN", "ELSE", "END",
+}
+
+// tableKeywords are followed by a table name.
+var tableKeywords = []string{"FROM", "JOIN", "UPDATE", "INTO", "TABLE"}
+
+// completionItem is a candidate: the text to insert and what it is.
+type completionItem struct {
+	text string
+	kind string // "column", "table", "schema" or "keyword"
+	hint string // the table of a column
+}
+
+// completion is the open completion popup.
+type completion struct {
+	start     int    // byte offset in the input of the word being completed
+	qualifier string // "alias" of "alias.col", "" if unqualified
+	items     []completionItem
+	matches   []completionItem // items starting with the typed word
+	cursor    int
+	loading   bool // columns of a referenced table are being read
+}
+
+// tableColumns caches the column names of a table, keyed by tableColumnsKey.
+// A nil entry is being read.
+type tableColumns map[string][]string
+
+func tableColumnsKey(schema, table string) string {
+	return schema + "." + table
+}
+
+// openCompletion completes the word before the cursor (Tab, Ctrl+Space).
+func (v *MainView) openCompletion() tea.Cmd {
+	start, qualifier := completionWord(v.input.BeforeCursor())
+	c := &completion{start: start, qualifier: qualifier}
+	v.complete = c
+	cmd := v.completionItems()
+	c.filter(v.input.BeforeCursor()[start:])
+	switch {
+	case len(c.matches) == 1 && !c.loading:
+		v.acceptCompletion()
+	case len(c.matches) == 0 && !c.loading:
+		v.complete = nil
+		return func() tea.Msg { return StatusMsg("No completions") }
+	}
+	return cmd
+}
+
+// completionItems collects the candidates for the open popup, reading the
+// columns of referenced tables that are not cached yet.
+func (v *MainView) completionItems() tea.Cmd {
+	c := v.complete
+	before := v.input.BeforeCursor()[:c.start]
+	aliases := referencedTables(v.input.Value())
+	var items []completionItem
+	var load []string
+	columns := func(name string) {
+		schema, table := v.tableRef(name)
+		key := tableColumnsKey(schema, table)
+		cols, ok := v.columns[key]
+		if !ok {
+			load = append(load, name)
+			v.columns[key] = nil
+		} else if cols == nil {
+			c.loading = true
+		}
+		for _, col := range cols {
+			items = append(items, completionItem{text: completionText(col), kind: "column", hint: table})
+		}
+	}
+
+	if c.qualifier != "" {
+		q := strings.ToLower(strings.Trim(c.qualifier, `"`))
+		if name, ok := aliases[q]; ok {
+			columns(name)
+		} else if slices.Contains(v.tableSchemas, q) {
+			for i, t := range v.tables {
+				if v.tableSchemas[i] == q {
+					_, table := db.SplitTableName(t)
+					items = append(items, completionItem{text: completionText(table), kind: "table"})
+				}
+			}
+		}
+	} else {
+		if !afterTableKeyword(before) {
+			seen := map[string]bool{}
+			for _, name := range aliases {
+				if !seen[name] {
+					seen[name] = true
+					columns(name)
+				}
+			}
+		}
+		for _, t := range v.tables {
+			items = append(items, completionItem{text: t, kind: "table"})
+		}
+		if afterTableKeyword(before) {
+			for _, s := range slices.Compact(slices.Sorted(slices.Values(v.tableSchemas))) {
+				items = append(items, completionItem{text: s, kind: "schema"})
+			}
+		} else {
+			for _, kw := range sqlKeywords {
+				items = append(items, completionItem{text: kw, kind: "keyword"})
+			}
+		}
+	}
+	c.items = slices.CompactFunc(items, func(a, b completionItem) bool { return a.text == b.text && a.kind == b.kind })
+
+	if len(load) == 0 {
+		return nil
+	}
+	c.loading = true
+	var cmds []tea.Cmd
+	for _, name := range load {
+		cmds = append(cmds, v.loadTableColumns(name))
+	}
+	return tea.Batch(cmds...)
+}
+
+// loadTableColumns reads the column names of a table for completion.
+func (v *MainView) loadTableColumns(name string) tea.Cmd {
+	schema, table := v.tableRef(name)
+	database := v.db
+	return func() tea.Msg {
+		result, err := database.DescribeTable(context.Background(), schema, table)
+		var cols []string
+		if err == nil {
+			for _, row := range result.Rows {
+				cols = append(cols, row[0])
+			}
+		}
+		return TableColumnsMsg{Schema: schema, Table: table, Columns: cols, Err: err}
+	}
+}
+
+// updateTableColumns caches the columns that arrived and refreshes the
+// popup waiting for them.
+func (v *MainView) updateTableColumns(msg TableColumnsMsg) tea.Cmd {
+	cols := msg.Columns
+	if cols == nil {
+		cols = []string{} // unknown table or error: don't ask again
+	}
+	v.columns[tableColumnsKey(msg.Schema, msg.Table)] = cols
+	c := v.complete
+	if c == nil || !c.loading {
+		return nil
+	}
+	typed := v.input.BeforeCursor()
+	if len(typed) < c.start {
+		v.complete = nil
+		return nil
+	}
+	c.loading = false
+	cmd := v.completionItems()
+	c.filter(typed[c.start:])
+	return cmd
+}
+
+// filter keeps the items starting with word, case-insensitively.
+func (c *completion) filter(word string) {
+	lower := strings.ToLower(strings.Trim(word, `"`))
+	c.matches = c.matches[:0]
+	for _, it := range c.items {
+		if t := strings.ToLower(strings.Trim(it.text, `"`)); strings.HasPrefix(t, lower) && t != lower {
+			c.matches = append(c.matches, it)
+		}
+	}
+	c.cursor = min(c.cursor, max(len(c.matches)-1, 0))
+}
+
+// acceptCompletion replaces the typed word with the selected candidate.
+func (v *MainView) acceptCompletion() {
+	c := v.complete
+	v.complete = nil
+	typed := v.input.BeforeCursor()
+	if c.cursor >= len(c.matches) || len(typed) < c.start {
+		return
+	}
+	it := c.matches[c.cursor]
+	text := it.text
+	if it.kind == "keyword" && isLowerWord(typed[c.start:]) {
+		text = strings.ToLower(text)
+	}
+	v.input.ReplaceBeforeCursor(c.start, text)
+}
+
+func (v *MainView) handleCompletionKey(msg tea.KeyMsg) (View, tea.Cmd) {
+	c := v.complete
+	switch msg.String() {
+	case "esc":
+		v.complete = nil
+	case "up", "ctrl+p":
+		if c.cursor > 0 {
+			c.cursor--
+		}
+	case "down", "ctrl+n":
+		if c.cursor < len(c.matches)-1 {
+			c.cursor++
+		}
+	case "tab", "enter", "ctrl+@":
+		if len(c.matches) > 0 {
+			v.acceptCompletion()
+		} else {
+			v.complete = nil
+		}
+	case "backspace":
+		if len(v.input.BeforeCursor()) <= c.start {
+			v.complete = nil
+			return v.handleInputKey(msg)
+		}
+		v.input.Update(msg)
+		c.filter(v.input.BeforeCursor()[c.start:])
+	default:
+		if msg.Type != tea.KeyRunes || strings.ContainsAny(string(msg.Runes), wordBreaks) {
+			// Anything but more of the word closes the popup and goes on as usual
+			v.complete = nil
+			return v.handleInputKey(msg)
+		}
+		v.input.Insert(string(msg.Runes))
+		c.filter(v.input.BeforeCursor()[c.start:])
+		if len(c.matches) == 0 && !c.loading {
+			v.complete = nil
+		}
+	}
+	return v, nil
+}
+
+// wordBreaks end the word being completed.
+const wordBreaks = " \t\n,()=<>;:+-*/|'"
+
+// completionWord returns where the word at the end of input, the text
+// before the cursor, starts and, for "alias.col", the qualifier before the
+// dot.
+func completionWord(input string) (start int, qualifier string) {
+	start = strings.LastIndexAny(input, wordBreaks) + 1
+	if dot := strings.LastIndex(input[start:], "."); dot >= 0 {
+		qualifier = input[start : start+dot]
+		start += dot + 1
+	}
+	return start, qualifier
+}
+
+// afterTableKeyword reports whether the word being completed follows
+// FROM, JOIN or another keyword taking a table name.
+func afterTableKeyword(before string) bool {
+	words := strings.FieldsFunc(before, func(r rune) bool { return strings.ContainsRune(wordBreaks, r) })
+	if len(words) == 0 {
+		return false
+	}
+	last := strings.ToUpper(words[len(words)-1])
+	if slices.Contains(tableKeywords, last) {
+		return !strings.HasSuffix(strings.TrimRight(before, " \t\n"), ",")
+	}
+	// FROM a, <table>
+	trimmed := strings.TrimRight(before, " \t\n")
+	if !strings.HasSuffix(trimmed, ",") {
+		return false
+	}
+	for i := len(words) - 1; i >= 0; i-- {
+		switch strings.ToUpper(words[i]) {
+		case "FROM":
+			return true
+		case "SELECT", "WHERE", "SET", "BY", "ON", "VALUES", "RETURNING":
+			return false
+		}
+	}
+	return false
+}
+
+// referencedTables maps the tables named after FROM, JOIN, UPDATE and INTO
+// in sql, and their aliases, to the table name, all lower-cased.
+func referencedTables(sql string) map[string]string {
+	words := strings.FieldsFunc(sql, func(r rune) bool {
+		return r == ' ' || r == '\t' || r == '\n' || r == '(' || r == ')' || r == ';'
+	})
+	refs := map[string]string{}
+	for i := 0; i < len(words); i++ {
+		if !slices.Contains(tableKeywords, strings.ToUpper(words[i])) {
+			continue
+		}
+		// FROM a x, b AS y
+		for i+1 < len(words) {
+			i++
+			name := strings.TrimSuffix(words[i], ",")
+			if name == "" || !identPath.MatchString(name) {
+				break
+			}
+			name = strings.ToLower(name)
+			refs[name] = name
+			if _, table := db.SplitTableName(name); table != name {
+				refs[table] = name
+			}
+			more := strings.HasSuffix(words[i], ",")
+			if !more && i+1 < len(words) {
+				alias := words[i+1]
+				if strings.EqualFold(alias, "AS") && i+2 < len(words) {
+					i++
+					alias = words[i+1]
+				}
+				more = strings.HasSuffix(alias, ",")
+				alias = strings.ToLower(strings.TrimSuffix(alias, ","))
+				if identPath.MatchString(alias) && !strings.Contains(alias, ".") &&
+					!slices.Contains(sqlKeywords, strings.ToUpper(alias)) {
+					refs[alias] = name
+					i++
+				} else {
+					more = false
+				}
+			}
+			if !more {
+				break
+			}
+		}
+	}
+	return refs
+}
+
+// identPath matches a plain or schema-qualified identifier.
+var identPath = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_$]*(\.[A-Za-z_][A-Za-z0-9_$]*)?$`)
+
+// plainIdent matches identifiers that need no quotes.
+var plainIdent = regexp.MustCompile(`^[a-z_][a-z0-9_$]*$`)
+
+// completionText quotes a column or table name where SQL needs it.
+func completionText(name string) string {
+	if plainIdent.MatchString(name) {
+		return name
+	}
+	return pgx.Identifier{name}.Sanitize()
+}
+
+// isLowerWord reports whether s was typed in lower case.
+func isLowerWord(s string) bool {
+	return s != "" && s == strings.ToLower(s)
+}
+
+// renderCompletion draws the popup, at most height lines tall.
+func (v *MainView) renderCompletion(height int) []string {
+	c := v.complete
+	rows := min(completionRows, height-2)
+	if rows < 1 {
+		return nil
+	}
+	var body []string
+	switch {
+	case len(c.matches) == 0 && c.loading:
+		body = []string{StyleDimmed.Render(" reading columns… ")}
+	case len(c.matches) == 0:
+		body = []string{StyleDimmed.Render(" no matches ")}
+	default:
+		first, last := 0, len(c.matches)
+		if last > rows {
+			first = min(max(c.cursor-rows/2, 0), last-rows)
+			last = first + rows
+		}
+		textWidth := 0
+		for _, it := range c.matches[first:last] {
+			textWidth = max(textWidth, len([]rune(it.text)))
+		}
+		for i := first; i < last; i++ {
+			it := c.matches[i]
+			kind := it.kind
+			if it.hint != "" {
+				kind = it.hint
+			}
+			line := fmt.Sprintf("%-*s  %s", textWidth, it.text, StyleDimmed.Render(kind))
+			body = append(body, pickLine(line, i == c.cursor)+" ")
+		}
+		if len(c.matches) > rows {
+			body = append(body[:len(body)-1], StyleDimmed.Render(fmt.Sprintf("   … %d of %d", c.cursor+1, len(c.matches))))
+		}
+	}
+	box := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(ColorAccent).
+		Render(strings.Join(body, "\n"))
+	return strings.Split(box, "\n")
+}
+
+// overlayCompletion draws the popup over the bottom of the results pane,
+// under the word being completed.
+func (v *MainView) overlayCompletion(results string, width, height int) string {
+	popup := v.renderCompletion(height)
+	if len(popup) == 0 {
+		return results
+	}
+	lines := strings.Split(results, "\n")
+	for len(lines) < height {
+		lines = append(lines, "")
+	}
+	lines = lines[:height]
+	// focus marker(2) + prompt(5) + padding(1), less the popup's border
+	col := 7 + len([]rune(v.input.BeforeCursor()[:v.complete.start]))
+	col = max(min(col, width-lipgloss.Width(popup[0])), 0)
+	for i, p := range popup {
+		lines[height-len(popup)+i] = strings.Repeat(" ", col) + p
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,141 @@
+// explain_saved.go implements \save and \load in the Explain view: a plan
+// is saved with its query, options and timestamp, and a loaded plan
+// becomes the baseline that later results are compared with.
+package tui
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/DachengChen/paiSQL/config"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// planCommand runs \save [file] or \load [file].
+func (v *ExplainView) planCommand(args []string) tea.Cmd {
+	v.input.Reset()
+	name := ""
+	if len(args) > 1 {
+		name = strings.Join(args[1:], " ")
+	}
+
+	switch args[0] {
+	case "\\save":
+		if v.current == nil {
+			return func() tea.Msg { return StatusMsg("Nothing to save: run EXPLAIN first") }
+		}
+		path, err := config.SavePlan(name, v.current)
+		if err != nil {
+			return func() tea.Msg { return StatusMsg("Save failed: " + err.Error()) }
+		}
+		return func() tea.Msg { return StatusMsg("Plan saved to " + path) }
+
+	case "\\load":
+		if name == "" {
+			v.listPlans()
+			return nil
+		}
+		p, err := config.LoadPlan(name)
+		if err != nil {
+			v.viewport.SetContent(StyleError.Render("Load failed: " + err.Error()))
+			return nil
+		}
+		v.baseline = p
+		v.input.SetValue(p.Query)
+		header := []string{
+			StyleBold.Render("Loaded plan") + StyleDimmed.Render(" — saved "+p.SavedAt.Format("2006-01-02 15:04")),
+			StyleDimmed.Render("EXPLAIN " + p.Options),
+			p.Query,
+			"",
+			StyleDimmed.Render("The query is in the prompt: Enter or Ctrl+A runs it and compares with this plan."),
+			"",
+		}
+		v.viewport.SetContent(strings.Join(header, "\n") + "\n" + v.planContent(p))
+		return nil
+	}
+	v.viewport.SetContent(StyleError.Render("Unknown command: " + args[0] + " (\\save [file], \\load [file])"))
+	return nil
+}
+
+// listPlans shows the plans saved in ~/.paisql/plans.
+func (v *ExplainView) listPlans() {
+	names, err := config.ListPlans()
+	if err != nil {
+		v.viewport.SetContent(StyleError.Render("ERROR: " + err.Error()))
+		return
+	}
+	dir, _ := config.PlansDir()
+	lines := []string{StyleBold.Render("Saved plans") + StyleDimmed.Render(" in "+dir), ""}
+	if len(names) == 0 {
+		lines = append(lines, StyleDimmed.Render("  (none — \\save [file] saves the current plan)"))
+	}
+	for _, name := range names {
+		lines = append(lines, "  "+name)
+	}
+	lines = append(lines, "", StyleDimmed.Render("\\load <file> loads one; a path with a directory loads any file."))
+	v.viewport.SetContent(strings.Join(lines, "\n"))
+}
+
+// planContent renders a plan in its saved format.
+func (v *ExplainView) planContent(p *config.SavedPlan) string {
+	if len(p.Plan) == 0 {
+		return p.Text
+	}
+	return v.formatJSON(string(p.Plan))
+}
+
+// planTotals reads the root cost and timings of a FORMAT JSON plan.
+// Times are zero unless the plan was run with ANALYZE.
+type planTotals struct {
+	Plan struct {
+		TotalCost float64 `json:"Total Cost"`
+	} `json:"Plan"`
+	PlanningTime  float64 `json:"Planning Time"`
+	ExecutionTime float64 `json:"Execution Time"`
+}
+
+func readPlanTotals(p *config.SavedPlan) (planTotals, bool) {
+	var root []planTotals
+	if len(p.Plan) == 0 || json.Unmarshal(p.Plan, &root) != nil || len(root) == 0 {
+		return planTotals{}, false
+	}
+	return root[0], true
+}
+
+// comparison is a header comparing the current result with the loaded
+// baseline, or "" if there is none or either plan is in FORMAT TEXT.
+func (v *ExplainView) comparison() string {
+	if v.baseline == nil || v.current == nil {
+		return ""
+	}
+	before, ok1 := readPlanTotals(v.baseline)
+	after, ok2 := readPlanTotals(v.current)
+	if !ok1 || !ok2 {
+		return StyleDimmed.Render("Compared with the loaded plan only in FORMAT JSON.") + "\n\n"
+	}
+
+	lines := []string{StyleBold.Render("Compared with loaded plan") +
+		StyleDimmed.Render(" (saved "+v.baseline.SavedAt.Format("2006-01-02 15:04")+")")}
+	row := func(label string, was, now float64, unit string) {
+		line := fmt.Sprintf("  %-16s %12.2f%s → %12.2f%s", label, was, unit, now, unit)
+		if was > 0 {
+			change := (now - was) / was * 100
+			style := StyleSuccess
+			if change > 0 {
+				style = StyleError
+			}
+			line += style.Render(fmt.Sprintf("  %+.0f%%", change))
+		}
+		lines = append(lines, line)
+	}
+	row("Total cost", before.Plan.TotalCost, after.Plan.TotalCost, "")
+	if before.ExecutionTime > 0 && after.ExecutionTime > 0 {
+		row("Planning time", before.PlanningTime, after.PlanningTime, " ms")
+		row("Execution time", before.ExecutionTime, after.ExecutionTime, " ms")
+	}
+	if v.baseline.Query != v.current.Query {
+		lines = append(lines, StyleWarning.Render("  The query differs from the loaded plan's."))
+	}
+	return strings.Join(lines, "\n") + "\n\n"
+}
@@ -0,0 +1,263 @@
+// export_files.go writes the output of \export: gzip or zstd compressed
+// when the file ends in .gz or .zst, and split into numbered chunks of
+// about a given size for very large exports:
+//
+//	\export big.csv.zst split 1GB
+//
+// writes big-0001.csv.zst, big-0002.csv.zst, ... each starting with the
+// header row, and big.manifest.json listing the rows and bytes of each.
+package tui
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// minChunkSize keeps a split export from turning into thousands of files.
+const minChunkSize = 1 << 20
+
+// exportChunk is one file of an export, as listed in the manifest.
+type exportChunk struct {
+	File  string `json:"file"`
+	Rows  int64  `json:"rows"`
+	Bytes int64  `json:"bytes"`
+}
+
+// exportManifest describes a split export.
+type exportManifest struct {
+	Query       string        `json:"query"`
+	ExportedAt  time.Time     `json:"exported_at"`
+	Compression string        `json:"compression,omitempty"`
+	ChunkSize   int64         `json:"chunk_size"`
+	Rows        int64         `json:"rows"`
+	Chunks      []exportChunk `json:"chunks"`
+}
+
+// exportCompression returns the compression implied by the file name:
+// "gzip", "zstd" or "".
+func exportCompression(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".gz":
+		return "gzip"
+	case ".zst":
+		return "zstd"
+	}
+	return ""
+}
+
+// exportFiles is the io.Writer \export streams its CSV to. Each Write is
+// one row (see db.ExportCSV), so a chunk is closed between rows once its
+// file has reached chunkSize, and the next one starts with the header.
+type exportFiles struct {
+	path        string
+	compression string
+	chunkSize   int64 // 0 writes a single file
+
+	header []byte
+	chunks []exportChunk
+	file   *os.File
+	size   *countingWriter // bytes of the current file on disk
+	zw     io.WriteCloser  // compressor in front of size, if any
+	w      io.Writer
+}
+
+// countingWriter counts the bytes written to w.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(b []byte) (int, error) {
+	n, err := c.w.Write(b)
+	c.n += int64(n)
+	return n, err
+}
+
+func newExportFiles(path string, chunkSize int64) *exportFiles {
+	return &exportFiles{path: path, compression: exportCompression(path), chunkSize: chunkSize}
+}
+
+// splitPath splits the path into its base and extension, which covers
+// the compression suffix too: out.csv.gz → out, .csv.gz.
+func (e *exportFiles) splitPath() (base, ext string) {
+	ext = filepath.Ext(e.path)
+	if e.compression != "" {
+		ext = filepath.Ext(strings.TrimSuffix(e.path, ext)) + ext
+	}
+	return strings.TrimSuffix(e.path, ext), ext
+}
+
+// chunkName is the name of chunk n (1-based): out.csv.gz → out-0001.csv.gz.
+func (e *exportFiles) chunkName(n int) string {
+	if e.chunkSize == 0 {
+		return e.path
+	}
+	base, ext := e.splitPath()
+	return fmt.Sprintf("%s-%04d%s", base, n, ext)
+}
+
+// manifestPath is where the manifest of a split export is written:
+// out.csv.gz → out.manifest.json.
+func (e *exportFiles) manifestPath() string {
+	base, _ := e.splitPath()
+	return base + ".manifest.json"
+}
+
+func (e *exportFiles) Write(b []byte) (int, error) {
+	if e.header == nil {
+		e.header = append([]byte(nil), b...)
+		if err := e.open(); err != nil {
+			return 0, err
+		}
+		return len(b), nil
+	}
+	cur := &e.chunks[len(e.chunks)-1]
+	if e.chunkSize > 0 && cur.Rows > 0 && e.size.n >= e.chunkSize {
+		if err := e.closeFile(); err != nil {
+			return 0, err
+		}
+		if err := e.open(); err != nil {
+			return 0, err
+		}
+		cur = &e.chunks[len(e.chunks)-1]
+	}
+	n, err := e.w.Write(b)
+	if err == nil {
+		cur.Rows++
+	}
+	return n, err
+}
+
+// open starts the next file and writes the header to it.
+func (e *exportFiles) open() error {
+	name := e.chunkName(len(e.chunks) + 1)
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	e.file = f
+	e.size = &countingWriter{w: f}
+	e.zw, e.w = nil, e.size
+	switch e.compression {
+	case "gzip":
+		e.zw = gzip.NewWriter(e.size)
+	case "zstd":
+		if e.zw, err = zstd.NewWriter(e.size); err != nil {
+			return err
+		}
+	}
+	if e.zw != nil {
+		e.w = e.zw
+	}
+	e.chunks = append(e.chunks, exportChunk{File: filepath.Base(name)})
+	_, err = e.w.Write(e.header)
+	return err
+}
+
+// closeFile flushes the compressor and closes the current file.
+func (e *exportFiles) closeFile() error {
+	if e.file == nil {
+		return nil
+	}
+	var err error
+	if e.zw != nil {
+		err = e.zw.Close()
+	}
+	if cerr := e.file.Close(); err == nil {
+		err = cerr
+	}
+	e.chunks[len(e.chunks)-1].Bytes = e.size.n
+	e.file = nil
+	return err
+}
+
+// finish closes the last file and, for a split export, writes the manifest.
+// An export that ends without output still gets its (empty) file.
+func (e *exportFiles) finish(sql string) error {
+	if e.file == nil && len(e.chunks) == 0 {
+		if err := e.open(); err != nil {
+			return err
+		}
+	}
+	if err := e.closeFile(); err != nil {
+		return err
+	}
+	if e.chunkSize == 0 {
+		return nil
+	}
+	m := exportManifest{
+		Query:       sql,
+		ExportedAt:  time.Now().UTC(),
+		Compression: e.compression,
+		ChunkSize:   e.chunkSize,
+		Chunks:      e.chunks,
+	}
+	for _, c := range e.chunks {
+		m.Rows += c.Rows
+	}
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(e.manifestPath(), append(data, '\n'), 0o644)
+}
+
+// remove closes and deletes every file written, after a failed export.
+func (e *exportFiles) remove() {
+	if e.file != nil {
+		if e.zw != nil {
+			e.zw.Close()
+		}
+		e.file.Close()
+		e.file = nil
+	}
+	dir := filepath.Dir(e.path)
+	for _, c := range e.chunks {
+		os.Remove(filepath.Join(dir, c.File))
+	}
+	if e.chunkSize > 0 {
+		os.Remove(e.manifestPath())
+	}
+}
+
+// written is the total size of the files on disk.
+func (e *exportFiles) written() int64 {
+	var n int64
+	for _, c := range e.chunks {
+		n += c.Bytes
+	}
+	return n
+}
+
+// parseChunkSize parses a chunk size such as 500MB, 2G or 1048576 (bytes).
+func parseChunkSize(s string) (int64, error) {
+	upper := strings.ToUpper(strings.TrimSpace(s))
+	unit := int64(1)
+	for _, u := range []struct {
+		suffix string
+		size   int64
+	}{{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"G", 1 << 30}, {"M", 1 << 20}, {"K", 1 << 10}, {"B", 1}} {
+		if strings.HasSuffix(upper, u.suffix) {
+			upper, unit = strings.TrimSuffix(upper, u.suffix), u.size
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(strings.TrimSpace(upper), 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid size %q (use e.g. 500MB or 2GB)", s)
+	}
+	size := int64(n * float64(unit))
+	if size < minChunkSize {
+		return 0, fmt.Errorf("chunks must be at least %s", formatByteSize(minChunkSize))
+	}
+	return size, nil
+}
@@ -0,0 +1,245 @@
+// group_summary.go implements the value distribution of a column (p in
+// the results while browsing a table): it counts the rows per value of
+// the focused column, with the quick filters applied, and lists the
+// groups largest first over the results. Enter on a group drills into it
+// by adding it as a quick filter.
+//
+// \dupes finds duplicates the same way, over one or more columns:
+//
+//	\dupes                  duplicate values of the focused column
+//	\dupes email            ... of a column
+//	\dupes first, last      ... of a combination of columns
+//
+// listing the combinations that occur more than once; Enter browses the
+// rows of one.
+package tui
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/DachengChen/paiSQL/db"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	groupSummaryLimit = 200 // groups fetched
+	groupBarWidth     = 20
+)
+
+// groupSummary is the distribution shown over the results pane.
+type groupSummary struct {
+	table   string
+	columns []string
+	dupes   bool // only groups of more than one row
+	loading bool
+	result  *db.QueryResult // values of columns, then the count; largest first
+	total   int64           // rows counted across the fetched groups
+	cursor  int
+	err     error
+}
+
+// openGroupSummary counts the browsed table's rows per value of the
+// focused column.
+func (v *MainView) openGroupSummary() tea.Cmd {
+	status := func(text string) tea.Cmd { return func() tea.Msg { return StatusMsg(text) } }
+	if v.pagTable == "" {
+		return status("Group counts work while browsing a table (Enter in the table list)")
+	}
+	col, _, ok := v.focusedCell()
+	if !ok {
+		return status("Group counts need the result grid (x leaves expanded display, w wrapping)")
+	}
+	return v.countGroups([]string{v.result.Columns[col]}, false)
+}
+
+// findDuplicates implements \dupes on the browsed table.
+func (v *MainView) findDuplicates(spec string) tea.Cmd {
+	v.input.Reset()
+	status := func(text string) tea.Cmd { return func() tea.Msg { return StatusMsg(text) } }
+	if v.pagTable == "" || v.result == nil {
+		return status("\\dupes: browse a table first (Enter in the table list)")
+	}
+	var columns []string
+	for _, c := range strings.Split(spec, ",") {
+		if c = strings.TrimSpace(c); c != "" {
+			columns = append(columns, c)
+		}
+	}
+	if len(columns) == 0 {
+		col, _, ok := v.focusedCell()
+		if !ok {
+			return status("Usage: \\dupes <column>[, <column>...] (or focus a column in the grid)")
+		}
+		columns = []string{v.result.Columns[col]}
+	}
+	if i := slices.IndexFunc(columns, func(c string) bool { return !slices.Contains(v.result.Columns, c) }); i >= 0 {
+		return status(fmt.Sprintf("\\dupes: %s is not among the columns shown (C picks them)", columns[i]))
+	}
+	return v.countGroups(columns, true)
+}
+
+// countGroups counts the browsed table's rows per combination of values
+// of columns, with the quick filters applied; dupes keeps only those
+// occurring more than once.
+func (v *MainView) countGroups(columns []string, dupes bool) tea.Cmd {
+	table := v.pagTable
+	v.groups = &groupSummary{table: table, columns: columns, dupes: dupes, loading: true}
+
+	n := len(columns)
+	positions := make([]string, n)
+	for i := range positions {
+		positions[i] = strconv.Itoa(i + 1)
+	}
+	having := ""
+	if dupes {
+		having = " HAVING count(*) > 1"
+	}
+	sql := v.styleSQL(fmt.Sprintf("SELECT %s, count(*) FROM %s%s GROUP BY %s%s ORDER BY %d DESC, %s LIMIT %d",
+		db.SelectList(columns), table, v.browseWhere(), strings.Join(positions, ", "), having,
+		n+1, strings.Join(positions, ", "), groupSummaryLimit))
+	database := v.db
+	return func() tea.Msg {
+		result, err := database.Execute(context.Background(), sql)
+		return GroupSummaryMsg{Table: table, Columns: columns, Result: result, Err: err}
+	}
+}
+
+func (v *MainView) handleGroupSummaryKey(msg tea.KeyMsg) (View, tea.Cmd) {
+	g := v.groups
+	switch msg.String() {
+	case "esc", "q", "p":
+		v.groups = nil
+	case "up", "k":
+		if g.cursor > 0 {
+			g.cursor--
+		}
+	case "down", "j":
+		if g.result != nil && g.cursor < len(g.result.Rows)-1 {
+			g.cursor++
+		}
+	case "enter":
+		if g.result == nil || len(g.result.Rows) == 0 {
+			return v, nil
+		}
+		var filters []quickFilter
+		for i, column := range g.columns {
+			colType := ""
+			if i < len(g.result.ColumnTypes) {
+				colType = g.result.ColumnTypes[i]
+			}
+			f, err := newQuickFilter(column, colType, g.result.Rows[g.cursor][i], false)
+			if err != nil {
+				g.err = err
+				return v, nil
+			}
+			if !slices.Contains(v.quickFilters, f) {
+				filters = append(filters, f)
+			}
+		}
+		v.groups = nil
+		if v.pagTable != g.table {
+			return v, nil
+		}
+		v.quickFilters = append(v.quickFilters, filters...)
+		return v, v.refilter()
+	}
+	return v, nil
+}
+
+// updateGroupSummary handles the counts arriving.
+func (v *MainView) updateGroupSummary(msg GroupSummaryMsg) {
+	g := v.groups
+	if g == nil || !g.loading || g.table != msg.Table || !slices.Equal(g.columns, msg.Columns) {
+		return
+	}
+	g.loading, g.result, g.err = false, msg.Result, msg.Err
+	if msg.Result == nil {
+		return
+	}
+	for _, row := range msg.Result.Rows {
+		g.total += g.count(row)
+	}
+}
+
+// value renders the grouped values of a row.
+func (g *groupSummary) value(row []string) string {
+	values := make([]string, len(g.columns))
+	for i := range values {
+		values[i] = truncateRunes(row[i], quickFilterValueLength)
+	}
+	return strings.Join(values, " · ")
+}
+
+// count returns the row count of a group.
+func (g *groupSummary) count(row []string) int64 {
+	n, _ := strconv.ParseInt(row[len(g.columns)], 10, 64)
+	return n
+}
+
+// renderGroupSummary renders the distribution in place of the results.
+func (v *MainView) renderGroupSummary() []string {
+	g := v.groups
+	title := "Σ " + strings.Join(g.columns, ", ") + " in " + g.table
+	if g.dupes {
+		title = "👯 Duplicates of " + strings.Join(g.columns, ", ") + " in " + g.table
+	}
+	if len(v.quickFilters) > 0 {
+		title += fmt.Sprintf(" (%d quick filters)", len(v.quickFilters))
+	}
+	lines := []string{StyleBold.Render(title), ""}
+	switch {
+	case g.loading:
+		return append(lines, StyleDimmed.Render("Counting…"))
+	case g.err != nil && g.result == nil:
+		return append(lines, StyleError.Render("Error: "+g.err.Error()), "", StyleDimmed.Render("Esc close"))
+	}
+
+	rows := g.result.Rows
+	if len(rows) == 0 && g.dupes {
+		return append(lines, StyleSuccess.Render("✓ No duplicates"), "", StyleDimmed.Render("Esc close"))
+	}
+	var largest int64
+	if len(rows) > 0 {
+		largest = g.count(rows[0])
+	}
+	width := 0
+	for _, row := range rows {
+		width = max(width, len([]rune(g.value(row))))
+	}
+
+	first, last := 0, len(rows)
+	if n := v.viewport.height - 6; n > 0 && last > n {
+		first = min(max(g.cursor-n/2, 0), last-n)
+		last = first + n
+	}
+	for i := first; i < last; i++ {
+		value, count := g.value(rows[i]), g.count(rows[i])
+		bar, share := "", 0.0
+		if largest > 0 {
+			bar = strings.Repeat("█", max(int(count*groupBarWidth/largest), 1))
+		}
+		if g.total > 0 {
+			share = float64(count) * 100 / float64(g.total)
+		}
+		line := fmt.Sprintf("%-*s %10d %5.1f%% %s", width, value, count, share, StyleDimmed.Render(bar))
+		lines = append(lines, pickLine(line, i == g.cursor))
+	}
+
+	summary := fmt.Sprintf("%d groups, %d rows", len(rows), g.total)
+	if len(rows) == groupSummaryLimit {
+		summary = fmt.Sprintf("Largest %d groups, %d rows", groupSummaryLimit, g.total)
+	}
+	if g.dupes {
+		summary += fmt.Sprintf(", %d of them extra copies", g.total-int64(len(rows)))
+	}
+	lines = append(lines, "", StyleDimmed.Render(summary),
+		StyleDimmed.Render("Enter browse the rows of a group · Esc close"))
+	if g.err != nil {
+		lines = append(lines, "", StyleError.Render("Error: "+g.err.Error()))
+	}
+	return lines
+}
@@ -0,0 +1,146 @@
+// help.go renders the help overlay (?) from the views' own keymaps, so it
+// always reflects the actual bindings. Typing while it is open filters
+// the bindings of every view.
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// globalKeymap lists the bindings handled by the App itself.
+var globalKeymap = KeyGroup{Title: "Global", Bindings: []KeyBinding{
+	{Key: "F1", Desc: "go to the SQL view"},
+	{Key: "/", Desc: "jump to view by name; in the results, plan or log: search the text (Ctrl+R regex, Ctrl+T match case; n/N next/previous, Esc clears)"},
+	{Key: "F6", Desc: "go to the view of a finished background task"},
+	{Key: "?", Desc: "help"},
+	{Key: "Ctrl+C", Desc: "quit"},
+}}
+
+// viewKeymap returns a view's registered keymap. Bindings from ShortHelp
+// that the full keymap doesn't mention are added, so nothing shown in the
+// status bar is missing here.
+func viewKeymap(v View) []KeyGroup {
+	var groups []KeyGroup
+	if fh, ok := v.(FullHelper); ok {
+		groups = fh.FullHelp()
+	}
+	known := map[string]bool{}
+	for _, g := range groups {
+		for _, b := range g.Bindings {
+			known[b.Key] = true
+		}
+	}
+	var extra []KeyBinding
+	for _, b := range v.ShortHelp() {
+		if !known[b.Key] {
+			extra = append(extra, b)
+		}
+	}
+	if len(extra) > 0 {
+		groups = append(groups, KeyGroup{Title: v.Name(), Bindings: extra})
+	}
+	return groups
+}
+
+// helpGroups returns what the overlay shows: the active view's keymap and
+// the global keys, or with a search filter, matching bindings of every view.
+func (a *App) helpGroups() []KeyGroup {
+	filter := strings.ToLower(strings.TrimSpace(a.helpFilter.Value()))
+	if filter == "" {
+		var groups []KeyGroup
+		if a.activeTab < len(a.views) {
+			groups = viewKeymap(a.views[a.activeTab])
+		}
+		return append(groups, globalKeymap)
+	}
+
+	matches := func(b KeyBinding) bool {
+		return strings.Contains(strings.ToLower(b.Key), filter) ||
+			strings.Contains(strings.ToLower(b.Desc), filter)
+	}
+	var groups []KeyGroup
+	add := func(title string, g KeyGroup) {
+		var found []KeyBinding
+		for _, b := range g.Bindings {
+			if matches(b) {
+				found = append(found, b)
+			}
+		}
+		if len(found) > 0 {
+			groups = append(groups, KeyGroup{Title: title, Bindings: found})
+		}
+	}
+	for _, v := range a.views {
+		for _, g := range viewKeymap(v) {
+			title := v.Name()
+			if g.Title != title {
+				title += " › " + g.Title
+			}
+			add(title, g)
+		}
+	}
+	add(globalKeymap.Title, globalKeymap)
+	return groups
+}
+
+func (a *App) renderHelp() string {
+	title := "⌨ Keyboard Shortcuts"
+	if a.helpFilter.Value() == "" && a.activeTab < len(a.views) {
+		title += " — " + a.views[a.activeTab].Name()
+	}
+	groups := a.helpGroups()
+
+	keyWidth := 0
+	for _, g := range groups {
+		for _, b := range g.Bindings {
+			keyWidth = max(keyWidth, utf8.RuneCountInString(b.Key))
+		}
+	}
+
+	lines := []string{StyleTitle.Render(title), ""}
+	if len(groups) == 0 {
+		lines = append(lines, StyleDimmed.Render(fmt.Sprintf("No bindings match %q", a.helpFilter.Value())), "")
+	}
+	for _, g := range groups {
+		lines = append(lines, StyleTitle.Render(g.Title))
+		for _, b := range g.Bindings {
+			pad := strings.Repeat(" ", keyWidth-utf8.RuneCountInString(b.Key))
+			lines = append(lines, "  "+StyleHelpKey.Render(b.Key)+pad+"  "+b.Desc)
+		}
+		lines = append(lines, "")
+	}
+
+	// Keep the footer visible when the list is taller than the screen.
+	footer := StyleDimmed.Render("Type to search all views · Esc or ? to close")
+	contentHeight := a.height - 3
+	if room := contentHeight - 3; room > 0 && len(lines) > room {
+		hidden := len(lines) - room + 1
+		lines = append(lines[:room-1], StyleDimmed.Render(fmt.Sprintf("… %d more lines — type to filter", hidden)))
+	}
+	lines = append(lines, footer)
+
+	return lipgloss.NewStyle().
+		Width(a.width-4).
+		Height(contentHeight).
+		Padding(1, 2).
+		Render(strings.Join(lines, "\n"))
+}
+
+// handleHelpKey edits the search filter while the help overlay is open.
+func (a *App) handleHelpKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return a, a.quit()
+	case "esc", "?":
+		a.showHelp = false
+		a.helpFilter.Reset()
+	default:
+		a.helpFilter.Update(msg)
+	}
+	return a, nil
+}
@@ -0,0 +1,177 @@
+// history.go keeps the SQL input history across restarts: statements are
+// appended to the connection's history file with their outcome when the
+// result arrives, and loaded back for ↑/↓ when the view is created.
+// Ctrl+R searches the history backwards like readline's
+// reverse-i-search.
+package tui
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/DachengChen/paiSQL/applog"
+	"github.com/DachengChen/paiSQL/config"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// historyRun is the statement from the SQL input still running, whose
+// history entry is completed and saved when its result arrives.
+type historyRun struct {
+	id    int // result request of the statement
+	sql   string
+	start time.Time
+}
+
+// historySearch is the Ctrl+R search over the history.
+type historySearch struct {
+	query textInput // typed at its end: the cursor keys take the match
+	match int       // index in history of the statement shown, -1 for none
+	saved string    // input before the search, put back by Esc
+}
+
+// loadHistory reads the saved history of a connection, newest first as
+// ↑/↓ walks it. A history that cannot be read is logged and not saved to.
+func loadHistory(connName string) (*config.History, []config.HistoryEntry) {
+	file, err := config.LoadHistory(connName)
+	if err != nil {
+		applog.Error("Failed to load query history: %v", err)
+		return nil, nil
+	}
+	entries := make([]config.HistoryEntry, 0, len(file.Entries))
+	for i := len(file.Entries) - 1; i >= 0; i-- {
+		entries = append(entries, file.Entries[i])
+	}
+	return file, entries
+}
+
+// addHistory puts input at the top of the history, unless it is already
+// there.
+func (v *MainView) addHistory(input string) {
+	v.histIdx = -1
+	if len(v.history) > 0 && v.history[0].SQL == input {
+		return
+	}
+	v.history = append([]config.HistoryEntry{{SQL: input, At: time.Now()}}, v.history...)
+}
+
+// recordHistory completes the history entry of the statement that
+// produced result request id and saves it. Superseded statements are
+// saved too; their context was cancelled.
+func (v *MainView) recordHistory(id int, err error, cancelled bool) {
+	run := v.histRun
+	if run == nil || run.id != id {
+		return
+	}
+	v.histRun = nil
+	entry := config.HistoryEntry{SQL: run.sql, At: run.start, Status: "ok", Duration: time.Since(run.start).Milliseconds()}
+	switch {
+	case cancelled || errors.Is(err, context.Canceled):
+		entry.Status = "cancelled"
+	case err != nil:
+		entry.Status, entry.Error = "error", err.Error()
+	}
+	for i, e := range v.history {
+		if e.SQL == run.sql {
+			v.history[i] = entry
+			break
+		}
+	}
+	if v.histFile == nil {
+		return
+	}
+	if err := v.histFile.Append(entry); err != nil {
+		applog.Error("Failed to save query history: %v", err)
+	}
+}
+
+// startHistorySearch opens the Ctrl+R search.
+func (v *MainView) startHistorySearch() {
+	v.histSearch = &historySearch{match: -1, saved: v.input.Value()}
+	if len(v.history) > 0 {
+		v.histSearch.match = 0
+	}
+}
+
+// findHistory returns the first statement at or after index from that
+// contains query (ignoring case), or -1.
+func (v *MainView) findHistory(query string, from int) int {
+	query = strings.ToLower(query)
+	for i := max(from, 0); i < len(v.history); i++ {
+		if strings.Contains(strings.ToLower(v.history[i].SQL), query) {
+			return i
+		}
+	}
+	return -1
+}
+
+// handleHistorySearchKey handles a key while Ctrl+R searches: typing
+// narrows the search, Ctrl+R finds an older match, Enter runs the match,
+// Tab or → edits it, Esc puts the input back. Other keys take the match
+// and then act on it as usual.
+func (v *MainView) handleHistorySearchKey(msg tea.KeyMsg) (View, tea.Cmd) {
+	s := v.histSearch
+	switch msg.String() {
+	case "ctrl+r":
+		if s.match >= 0 {
+			if i := v.findHistory(s.query.Value(), s.match+1); i >= 0 {
+				s.match = i
+			}
+		}
+		return v, nil
+	case "esc", "ctrl+g":
+		v.input.SetValue(s.saved)
+		v.histSearch = nil
+		return v, nil
+	case "backspace", "ctrl+w", "alt+backspace":
+		// A shorter query may match a newer statement.
+		s.query.Update(msg)
+		s.match = v.findHistory(s.query.Value(), 0)
+		return v, nil
+	}
+	if msg.Type == tea.KeyRunes || msg.Type == tea.KeySpace {
+		s.query.Update(msg)
+		s.match = v.findHistory(s.query.Value(), s.match)
+		return v, nil
+	}
+
+	if s.match >= 0 {
+		v.input.SetValue(v.history[s.match].SQL)
+	} else {
+		v.input.SetValue(s.saved)
+	}
+	v.histSearch = nil
+	switch msg.String() {
+	case "tab", "right":
+		return v, nil
+	}
+	return v.handleInputKey(msg)
+}
+
+// historyPrompt is the input line while Ctrl+R searches: the query, the
+// matching statement and when it last ran.
+func (v *MainView) historyPrompt() (label, text string) {
+	s := v.histSearch
+	if s.match < 0 {
+		return StylePrompt.Render("(failing reverse-i-search)`" + s.query.Value() + "': "), ""
+	}
+	e := v.history[s.match]
+	text = strings.Join(strings.Fields(e.SQL), " ")
+	var note string
+	switch e.Status {
+	case "ok":
+		note = "✓ "
+	case "error":
+		note = "✗ "
+	case "cancelled":
+		note = "cancelled "
+	}
+	if !e.At.IsZero() {
+		note += e.At.Format("Jan 2 15:04")
+	}
+	if note != "" {
+		text += "  " + StyleDimmed.Render(note)
+	}
+	return StylePrompt.Render("(reverse-i-search)`" + s.query.Value() + "': "), text
+}
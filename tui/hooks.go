@@ -0,0 +1,101 @@
+// hooks.go fires the notification hooks of ~/.paisql/config.json: when
+// a query, export or maintenance action runs longer than the threshold,
+// its end is announced on the desktop and/or posted to a webhook, so the
+// user can tab away from the terminal while it runs.
+package tui
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/DachengChen/paiSQL/applog"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// webhookTimeout bounds a webhook POST.
+const webhookTimeout = 10 * time.Second
+
+// announce returns a command firing the hooks when msg ends work that
+// took at least the threshold, or nil.
+func (a *App) announce(msg routedMsg) tea.Cmd {
+	if a.appConfig == nil {
+		return nil
+	}
+	hooks := a.appConfig.Notify
+	if !hooks.Enabled() || msg.took < hooks.Threshold() {
+		return nil
+	}
+	text, failed, ok := completionNotice(msg.msg)
+	if status, isStatus := msg.msg.(StatusMsg); isStatus {
+		text, ok = string(status), true
+		failed = strings.Contains(strings.ToLower(text), "failed")
+	}
+	if !ok {
+		return nil
+	}
+
+	title := "paiSQL"
+	if a.connName != "" {
+		title += " · " + a.connName
+	}
+	body := fmt.Sprintf("%s (%s, took %s)", text, msg.view.Name(), msg.took.Round(time.Second))
+	return func() tea.Msg {
+		if hooks.Desktop {
+			if err := desktopNotify(title, body); err != nil {
+				applog.Event("NOTIFY", "Desktop notification failed: %v", err)
+			}
+		}
+		if hooks.Webhook != "" {
+			mark := "✅"
+			if failed {
+				mark = "❌"
+			}
+			if err := postWebhook(hooks.Webhook, mark+" *"+title+"*: "+body); err != nil {
+				applog.Event("NOTIFY", "Webhook failed: %v", err)
+			}
+		}
+		return nil
+	}
+}
+
+// desktopNotify shows a desktop notification.
+func desktopNotify(title, body string) error {
+	if runtime.GOOS == "darwin" {
+		quote := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+		script := fmt.Sprintf(`display notification "%s" with title "%s"`, quote.Replace(body), quote.Replace(title))
+		return exec.Command("osascript", "-e", script).Run()
+	}
+	if _, err := exec.LookPath("notify-send"); err != nil {
+		return fmt.Errorf("notify-send not found")
+	}
+	return exec.Command("notify-send", title, body).Run()
+}
+
+// postWebhook posts text as a Slack-compatible message. Errors leave out
+// the URL, which holds the webhook's secret.
+func postWebhook(webhook, text string) error {
+	payload, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return err
+	}
+	client := &http.Client{Timeout: webhookTimeout}
+	resp, err := client.Post(webhook, "application/json", bytes.NewReader(payload))
+	if urlErr, ok := err.(*url.Error); ok {
+		return urlErr.Err
+	}
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("the webhook returned %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,363 @@
+// index_wizard.go implements the create index form (I in the table list):
+// pick key columns with their ordering and operator class, INCLUDE
+// columns, a partial WHERE predicate and the UNIQUE / CONCURRENTLY
+// options, then review the statement and its estimated size.
+//
+// The build runs like a table action (maintenance.go), with progress
+// from pg_stat_progress_create_index and an audit log entry.
+package tui
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/DachengChen/paiSQL/db"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type indexStep int
+
+const (
+	indexLoading    indexStep = iota // fetching the table's columns
+	indexColumns                     // choosing key and INCLUDE columns
+	indexOptions                     // name, method, UNIQUE, CONCURRENTLY, WHERE
+	indexEditing                     // typing a name, predicate or opclass
+	indexEstimating                  // waiting for the size estimate
+	indexReview                      // statement, estimate and warnings; y runs it
+)
+
+// Rows of the options step.
+const (
+	indexOptName = iota
+	indexOptMethod
+	indexOptUnique
+	indexOptConcurrently
+	indexOptWhere
+	indexOptPreview
+	indexOptCount
+)
+
+// indexWizard is the create index form shown over the results pane.
+type indexWizard struct {
+	table    string // table list name
+	step     indexStep
+	columns  []db.ColumnInfo
+	cursor   int // row in the current step
+	spec     db.IndexSpec
+	named    bool   // the user set spec.Name; stop deriving it from the columns
+	editing  string // what indexEditing edits: "name", "where" or a column for its opclass
+	input    textInput
+	estimate *db.IndexEstimate
+	err      error
+}
+
+// openIndexWizard starts the create index form for table.
+func (v *MainView) openIndexWizard(table string) tea.Cmd {
+	schema, name := v.tableRef(table)
+	v.index = &indexWizard{
+		table: table,
+		spec:  db.IndexSpec{Schema: schema, Table: name, Method: "btree", Concurrently: true},
+	}
+	database := v.db
+	return func() tea.Msg {
+		ts, err := database.FetchTableSchema(context.Background(), schema, name)
+		if err != nil {
+			return IndexColumnsMsg{Table: table, Err: err}
+		}
+		return IndexColumnsMsg{Table: table, Columns: ts.Columns}
+	}
+}
+
+// keyPos returns the position of column in the index key, or -1.
+func (w *indexWizard) keyPos(column string) int {
+	return slices.IndexFunc(w.spec.Columns, func(c db.IndexColumn) bool { return c.Name == column })
+}
+
+// syncName keeps the default name in step with the key columns.
+func (w *indexWizard) syncName() {
+	if !w.named {
+		w.spec.Name = db.DefaultIndexName(w.spec.Table, w.spec.Columns)
+	}
+}
+
+func (v *MainView) handleIndexKey(msg tea.KeyMsg) (View, tea.Cmd) {
+	w := v.index
+	key := msg.String()
+
+	switch w.step {
+	case indexLoading, indexEstimating:
+		if key == "esc" {
+			v.index = nil
+		}
+
+	case indexColumns:
+		col := w.columns[w.cursor].Name
+		switch key {
+		case "esc":
+			v.index = nil
+		case "up", "k":
+			if w.cursor > 0 {
+				w.cursor--
+			}
+		case "down", "j":
+			if w.cursor < len(w.columns)-1 {
+				w.cursor++
+			}
+		case " ":
+			if i := w.keyPos(col); i >= 0 {
+				w.spec.Columns = slices.Delete(w.spec.Columns, i, i+1)
+			} else {
+				w.spec.Columns = append(w.spec.Columns, db.IndexColumn{Name: col})
+				w.spec.Include = slices.DeleteFunc(w.spec.Include, func(c string) bool { return c == col })
+			}
+			w.syncName()
+		case "o":
+			if i := w.keyPos(col); i >= 0 {
+				w.spec.Columns[i].Desc = !w.spec.Columns[i].Desc
+			}
+		case "p":
+			if i := w.keyPos(col); i >= 0 {
+				w.step, w.editing = indexEditing, col
+				w.input.SetValue(w.spec.Columns[i].Opclass)
+			}
+		case "i":
+			if i := slices.Index(w.spec.Include, col); i >= 0 {
+				w.spec.Include = slices.Delete(w.spec.Include, i, i+1)
+			} else if w.keyPos(col) < 0 {
+				w.spec.Include = append(w.spec.Include, col)
+			}
+		case "enter":
+			if len(w.spec.Columns) == 0 {
+				w.err = fmt.Errorf("pick at least one key column with Space")
+				return v, nil
+			}
+			w.err = nil
+			w.step, w.cursor = indexOptions, 0
+		}
+
+	case indexOptions:
+		switch key {
+		case "esc":
+			w.step, w.cursor = indexColumns, 0
+		case "up", "k":
+			if w.cursor > 0 {
+				w.cursor--
+			}
+		case "down", "j":
+			if w.cursor < indexOptCount-1 {
+				w.cursor++
+			}
+		case "enter", " ":
+			switch w.cursor {
+			case indexOptName:
+				w.step, w.editing = indexEditing, "name"
+				w.input.SetValue(w.spec.Name)
+			case indexOptWhere:
+				w.step, w.editing = indexEditing, "where"
+				w.input.SetValue(w.spec.Where)
+			case indexOptMethod:
+				i := slices.Index(db.IndexMethods, w.spec.Method)
+				w.spec.Method = db.IndexMethods[(i+1)%len(db.IndexMethods)]
+			case indexOptUnique:
+				w.spec.Unique = !w.spec.Unique
+			case indexOptConcurrently:
+				w.spec.Concurrently = !w.spec.Concurrently
+			case indexOptPreview:
+				return v, v.estimateIndex()
+			}
+		}
+
+	case indexEditing:
+		switch key {
+		case "esc":
+			w.step = w.returnStep()
+		case "enter":
+			v.applyIndexInput()
+		case "ctrl+u":
+			w.input.Reset()
+		default:
+			w.input.Update(msg)
+		}
+
+	case indexReview:
+		switch key {
+		case "esc":
+			w.step, w.err = indexOptions, nil
+		case "y":
+			if v.maintRun != nil {
+				w.err = fmt.Errorf("%s is still running; wait for it to finish", v.maintRun.plan.Action.Name)
+				return v, nil
+			}
+			plan := w.spec.Plan(w.estimate)
+			plan.SQL = v.styleSQL(plan.SQL)
+			v.index = nil
+			v.maint = &maintMenu{table: w.table, step: maintRunning}
+			return v, v.startMaintenance(plan)
+		}
+	}
+	return v, nil
+}
+
+// returnStep is the step an edit goes back to.
+func (w *indexWizard) returnStep() indexStep {
+	if w.editing == "name" || w.editing == "where" {
+		return indexOptions
+	}
+	return indexColumns
+}
+
+// applyIndexInput stores the text typed in indexEditing.
+func (v *MainView) applyIndexInput() {
+	w := v.index
+	text := strings.TrimSpace(w.input.Value())
+	switch w.editing {
+	case "name":
+		w.spec.Name = text
+		w.named = text != ""
+		w.syncName()
+	case "where":
+		w.spec.Where = text
+	default:
+		if i := w.keyPos(w.editing); i >= 0 {
+			w.spec.Columns[i].Opclass = text
+		}
+	}
+	w.step = w.returnStep()
+}
+
+// estimateIndex fetches the size estimate for the review step.
+func (v *MainView) estimateIndex() tea.Cmd {
+	w := v.index
+	w.step, w.err = indexEstimating, nil
+	spec := w.spec
+	database := v.db
+	return func() tea.Msg {
+		est, err := database.EstimateIndex(context.Background(), spec)
+		return IndexEstimateMsg{Table: w.table, Estimate: est, Err: err}
+	}
+}
+
+// updateIndexWizard handles the create index form's messages.
+func (v *MainView) updateIndexWizard(msg tea.Msg) tea.Cmd {
+	w := v.index
+	switch msg := msg.(type) {
+	case IndexColumnsMsg:
+		if w == nil || w.step != indexLoading || w.table != msg.Table {
+			return nil
+		}
+		if msg.Err != nil || len(msg.Columns) == 0 {
+			v.index = nil
+			text := "Create index: " + msg.Table + " has no columns"
+			if msg.Err != nil {
+				text = "Create index: " + msg.Err.Error()
+			}
+			return func() tea.Msg { return StatusMsg(text) }
+		}
+		w.columns, w.step = msg.Columns, indexColumns
+		w.syncName()
+
+	case IndexEstimateMsg:
+		if w == nil || w.step != indexEstimating || w.table != msg.Table {
+			return nil
+		}
+		if msg.Err != nil {
+			w.step, w.err = indexOptions, msg.Err
+			return nil
+		}
+		w.estimate, w.step = msg.Estimate, indexReview
+	}
+	return nil
+}
+
+// renderIndexWizard renders the create index form in place of the results.
+func (v *MainView) renderIndexWizard() []string {
+	w := v.index
+	lines := []string{StyleBold.Render("➕ Create index on " + w.table), ""}
+
+	switch w.step {
+	case indexLoading:
+		return append(lines, StyleDimmed.Render("Loading columns…"))
+
+	case indexColumns:
+		for i, col := range w.columns {
+			mark := "   "
+			if k := w.keyPos(col.Name); k >= 0 {
+				c := w.spec.Columns[k]
+				mark = fmt.Sprintf("%2d ", k+1)
+				extra := ""
+				if c.Desc {
+					extra += " DESC"
+				}
+				if c.Opclass != "" {
+					extra += " " + c.Opclass
+				}
+				col.DataType += StyleSuccess.Render(extra)
+			} else if slices.Contains(w.spec.Include, col.Name) {
+				mark = " + "
+			}
+			line := fmt.Sprintf("%s%-24s %s", mark, col.Name, StyleDimmed.Render(col.DataType))
+			lines = append(lines, pickLine(line, i == w.cursor))
+		}
+		lines = append(lines, "",
+			StyleDimmed.Render("Numbers are key column positions, + marks INCLUDE columns."),
+			StyleDimmed.Render("Space key column · o ASC/DESC · p opclass · i INCLUDE · Enter options · Esc close"))
+
+	case indexOptions, indexEstimating:
+		yesNo := map[bool]string{true: "yes", false: "no"}
+		where := w.spec.Where
+		if where == "" {
+			where = StyleDimmed.Render("(whole table)")
+		}
+		options := []string{
+			fmt.Sprintf("%-14s %s", "Name", w.spec.Name),
+			fmt.Sprintf("%-14s %s", "Method", w.spec.Method),
+			fmt.Sprintf("%-14s %s", "Unique", yesNo[w.spec.Unique]),
+			fmt.Sprintf("%-14s %s", "Concurrently", yesNo[w.spec.Concurrently]),
+			fmt.Sprintf("%-14s %s", "WHERE", where),
+			StyleBold.Render("Preview statement"),
+		}
+		for i, opt := range options {
+			lines = append(lines, pickLine(opt, i == w.cursor))
+		}
+		lines = append(lines, "", "  "+StyleDimmed.Render(v.styleSQL(w.spec.SQL())), "")
+		if w.step == indexEstimating {
+			lines = append(lines, StyleDimmed.Render("Estimating size…"))
+		} else {
+			lines = append(lines, StyleDimmed.Render("Enter edit/toggle · Esc columns"))
+		}
+
+	case indexEditing:
+		label := "Operator class for " + w.editing
+		switch w.editing {
+		case "name":
+			label = "Index name"
+		case "where":
+			label = "WHERE predicate"
+		}
+		lines = append(lines, StylePrompt.Render(label+": ")+w.input.View(), "",
+			StyleDimmed.Render("Enter save · Esc cancel · Ctrl+U clear"))
+
+	case indexReview:
+		est := w.estimate
+		lines = append(lines, "  "+highlightSQL(v.styleSQL(w.spec.SQL())+";"), "",
+			fmt.Sprintf("  Estimated size: ~%s for %s rows", est.Size, db.FormatRowCount(est.Rows)))
+		if w.spec.Method != "btree" {
+			lines = append(lines, StyleDimmed.Render("  (btree estimate; "+w.spec.Method+" indexes differ, BRIN is far smaller)"))
+		}
+		if len(est.MissingStats) > 0 {
+			lines = append(lines, StyleDimmed.Render("  No statistics for "+strings.Join(est.MissingStats, ", ")+"; ANALYZE the table for a better estimate"))
+		}
+		lines = append(lines, "")
+		for _, warning := range w.spec.Warnings() {
+			lines = append(lines, StyleWarning.Render("⚠ "+warning))
+		}
+		lines = append(lines, "", StyleDimmed.Render("y create · Esc back"))
+	}
+
+	if w.err != nil {
+		lines = append(lines, "", StyleError.Render("Error: "+w.err.Error()))
+	}
+	return lines
+}
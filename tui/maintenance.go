@@ -0,0 +1,313 @@
+// maintenance.go implements the table actions menu (a in the table list):
+// pick ANALYZE, VACUUM, REINDEX, CLUSTER, TRUNCATE or DROP, confirm the
+// generated statement, and follow its progress while it runs.
+//
+// Every confirmed action and its outcome is written to the application
+// log, so there is a record of what ran against which table and how it
+// ended.
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/DachengChen/paiSQL/applog"
+	"github.com/DachengChen/paiSQL/db"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const maintProgressInterval = time.Second
+
+type maintStep int
+
+const (
+	maintChoose   maintStep = iota // picking an action
+	maintPlanning                  // waiting for the statement and table size
+	maintConfirm                   // showing the statement, waiting for confirmation
+	maintRunning                   // following the running statement
+)
+
+// maintMenu is the table actions modal shown over the results pane.
+type maintMenu struct {
+	table string // table list name
+	step  maintStep
+	idx   int // selected action in db.MaintenanceActions
+	plan  *db.MaintenancePlan
+	typed textInput // table name typed to confirm a destructive action
+	err   error
+}
+
+// maintRun is a running table action. It outlives the menu, which can be
+// hidden and reopened while the statement runs.
+type maintRun struct {
+	gen      int
+	plan     *db.MaintenancePlan
+	run      *db.MaintenanceRun
+	cancel   context.CancelFunc
+	started  time.Time
+	progress string
+}
+
+// maintTickMsg triggers a progress poll of run gen.
+type maintTickMsg struct{ gen int }
+
+// openMaintenance opens the actions menu for table, or the progress of
+// the running action if there is one.
+func (v *MainView) openMaintenance(table string) {
+	if v.maintRun != nil {
+		v.maint = &maintMenu{table: table, step: maintRunning}
+		return
+	}
+	v.maint = &maintMenu{table: table}
+}
+
+// confirmName is what the user types to confirm a destructive action.
+func (m *maintMenu) confirmName() string {
+	_, name := db.SplitTableName(m.table)
+	return name
+}
+
+func (v *MainView) handleMaintKey(msg tea.KeyMsg) (View, tea.Cmd) {
+	m := v.maint
+	key := msg.String()
+	switch m.step {
+	case maintChoose:
+		switch key {
+		case "up", "k":
+			if m.idx > 0 {
+				m.idx--
+			}
+		case "down", "j":
+			if m.idx < len(db.MaintenanceActions)-1 {
+				m.idx++
+			}
+		case "enter":
+			m.step = maintPlanning
+			m.err = nil
+			return v, v.planMaintenance(m.table, db.MaintenanceActions[m.idx])
+		case "esc", "q", "a":
+			v.maint = nil
+		}
+
+	case maintPlanning:
+		if key == "esc" {
+			v.maint = nil
+		}
+
+	case maintConfirm:
+		if m.plan.Action.Destructive {
+			switch key {
+			case "esc":
+				m.step = maintChoose
+				m.typed.Reset()
+			case "enter":
+				if m.typed.Value() == m.confirmName() {
+					return v, v.startMaintenance(m.plan)
+				}
+			default:
+				m.typed.Update(msg)
+			}
+			return v, nil
+		}
+		switch key {
+		case "y", "enter":
+			return v, v.startMaintenance(m.plan)
+		case "n", "esc":
+			m.step = maintChoose
+		}
+
+	case maintRunning:
+		switch key {
+		case "x":
+			if v.maintRun != nil {
+				v.maintRun.cancel()
+			}
+		case "esc", "a":
+			v.maint = nil // keeps running; a reopens the progress
+		}
+	}
+	return v, nil
+}
+
+// planMaintenance resolves action against table for the confirmation step.
+func (v *MainView) planMaintenance(table string, action db.MaintenanceAction) tea.Cmd {
+	schema, name := v.tableRef(table)
+	database := v.db
+	return func() tea.Msg {
+		plan, err := database.PlanMaintenance(context.Background(), action, schema, name)
+		return MaintenancePlanMsg{Table: table, Plan: plan, Err: err}
+	}
+}
+
+// startMaintenance runs a confirmed plan and starts polling its progress.
+func (v *MainView) startMaintenance(plan *db.MaintenancePlan) tea.Cmd {
+	v.maintGen++
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &maintRun{
+		gen:      v.maintGen,
+		plan:     plan,
+		run:      &db.MaintenanceRun{},
+		cancel:   cancel,
+		started:  time.Now(),
+		progress: "starting",
+	}
+	v.maintRun = r
+	v.maint.step = maintRunning
+	applog.Event("MAINTENANCE", "Confirmed: %s (size %s, ~%d rows)", plan.SQL, plan.Size, plan.Rows)
+
+	database := v.db
+	return tea.Batch(func() tea.Msg {
+		elapsed, err := database.RunMaintenance(ctx, plan, r.run)
+		return MaintenanceDoneMsg{Gen: r.gen, Plan: plan, Elapsed: elapsed, Err: err}
+	}, maintTick(r.gen))
+}
+
+func maintTick(gen int) tea.Cmd {
+	return tea.Tick(maintProgressInterval, func(time.Time) tea.Msg {
+		return maintTickMsg{gen: gen}
+	})
+}
+
+// pollMaintenance reads the progress of the running action.
+func (v *MainView) pollMaintenance() tea.Cmd {
+	r := v.maintRun
+	database := v.db
+	return func() tea.Msg {
+		pid := r.run.PID()
+		if pid == 0 {
+			return MaintenanceProgressMsg{Gen: r.gen, Progress: "waiting for a connection"}
+		}
+		progress, err := database.MaintenanceProgress(context.Background(), r.plan, pid)
+		return MaintenanceProgressMsg{Gen: r.gen, Progress: progress, Err: err}
+	}
+}
+
+// updateMaintenance handles the messages of the actions menu and the
+// running action.
+func (v *MainView) updateMaintenance(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case MaintenancePlanMsg:
+		m := v.maint
+		if m == nil || m.step != maintPlanning || m.table != msg.Table {
+			return nil
+		}
+		if msg.Err != nil {
+			m.step, m.err = maintChoose, msg.Err
+			return nil
+		}
+		msg.Plan.SQL = v.styleSQL(msg.Plan.SQL)
+		m.step, m.plan = maintConfirm, msg.Plan
+		m.typed.Reset()
+
+	case maintTickMsg:
+		if v.maintRun != nil && v.maintRun.gen == msg.gen {
+			return v.pollMaintenance()
+		}
+
+	case MaintenanceProgressMsg:
+		if v.maintRun == nil || v.maintRun.gen != msg.Gen {
+			return nil
+		}
+		if msg.Err != nil {
+			v.maintRun.progress = "progress unavailable: " + msg.Err.Error()
+		} else {
+			v.maintRun.progress = msg.Progress
+		}
+		return maintTick(msg.Gen)
+
+	case MaintenanceDoneMsg:
+		if v.maintRun == nil || v.maintRun.gen != msg.Gen {
+			return nil
+		}
+		v.maintRun.cancel()
+		v.maintRun = nil
+		if v.maint != nil && v.maint.step == maintRunning {
+			v.maint = nil
+		}
+
+		var status string
+		switch {
+		case errors.Is(msg.Err, context.Canceled):
+			status = fmt.Sprintf("%s cancelled after %s", msg.Plan.SQL, msg.Elapsed.Round(time.Millisecond))
+			applog.Event("MAINTENANCE", "Cancelled: %s", msg.Plan.SQL)
+		case msg.Err != nil:
+			status = fmt.Sprintf("%s failed: %v", msg.Plan.SQL, msg.Err)
+			applog.Event("MAINTENANCE", "Failed: %s: %v", msg.Plan.SQL, msg.Err)
+		default:
+			status = fmt.Sprintf("%s finished in %s", msg.Plan.SQL, msg.Elapsed.Round(time.Millisecond))
+			applog.Event("MAINTENANCE", "Finished in %s: %s", msg.Elapsed.Round(time.Millisecond), msg.Plan.SQL)
+		}
+		// Row estimates change after ANALYZE, VACUUM and TRUNCATE; DROP
+		// removes the table from the list.
+		return tea.Batch(func() tea.Msg { return StatusMsg(status) }, v.fetchTables())
+	}
+	return nil
+}
+
+// renderMaintenance renders the actions menu in place of the results.
+func (v *MainView) renderMaintenance() []string {
+	m := v.maint
+	lines := []string{StyleBold.Render("🛠  Table actions: " + m.table), ""}
+
+	switch m.step {
+	case maintChoose, maintPlanning:
+		for i, a := range db.MaintenanceActions {
+			name := fmt.Sprintf("%-22s", a.Name)
+			if a.Destructive {
+				name = StyleError.Render(name)
+			}
+			line := "   " + name + StyleDimmed.Render(a.Description)
+			if i == m.idx {
+				line = StylePrompt.Render(" ▸ ") + name + a.Description
+			}
+			lines = append(lines, line)
+		}
+		lines = append(lines, "")
+		if m.err != nil {
+			lines = append(lines, StyleError.Render("Error: "+m.err.Error()), "")
+		}
+		if m.step == maintPlanning {
+			lines = append(lines, StyleDimmed.Render("Preparing "+db.MaintenanceActions[m.idx].Name+"…"))
+		} else {
+			lines = append(lines, StyleDimmed.Render("↑/↓ select · Enter choose · Esc close"))
+		}
+
+	case maintConfirm:
+		p := m.plan
+		lines = append(lines,
+			StyleBold.Render("Run this statement?"),
+			"",
+			"  "+highlightSQL(p.SQL+";"),
+			"",
+			fmt.Sprintf("  Table size: %s, ~%s rows", p.Size, db.FormatRowCount(p.Rows)),
+			"  Lock: "+p.Action.Lock,
+			"",
+		)
+		if p.Action.Destructive {
+			lines = append(lines,
+				StyleError.Render("⚠️  This cannot be undone."),
+				fmt.Sprintf("Type %s to confirm: %s", StyleBold.Render(m.confirmName()), m.typed.View()),
+				"",
+				StyleDimmed.Render("Enter run · Esc back"))
+		} else {
+			lines = append(lines, StyleDimmed.Render("y/Enter run · n/Esc back"))
+		}
+
+	case maintRunning:
+		r := v.maintRun
+		if r == nil {
+			lines = append(lines, StyleDimmed.Render("Nothing running."))
+			break
+		}
+		lines = append(lines,
+			"⏳ "+StyleBold.Render(r.plan.SQL),
+			"",
+			fmt.Sprintf("  Running for %s", time.Since(r.started).Round(time.Second)),
+			"  "+r.progress,
+			"",
+			StyleDimmed.Render("x cancel · Esc hide (keeps running; a shows it again)"))
+	}
+	return lines
+}
@@ -5,22 +5,38 @@
 package tui
 
 import (
+	"time"
+
 	"github.com/DachengChen/paiSQL/ai"
+	"github.com/DachengChen/paiSQL/config"
 	"github.com/DachengChen/paiSQL/db"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jackc/pgx/v5"
 )
 
 // QueryResultMsg is sent when a SQL query completes.
 type QueryResultMsg struct {
+	ID       int // request ID; results of superseded requests are dropped
 	Result   *db.QueryResult
 	Err      error
 	PagTotal int64  // total rows for pagination (0 = not paginated)
 	PagInfo  string // table info header (name, size, etc.)
+	Question string // natural-language question when the query came from an AI plan
+	Refresh  bool   // an auto-refresh of a browsed page, not worth a notice
+
+	// A browsed page's table and its row key, and the ctids of its rows
+	// when the key is ctid
+	RowKeyTable string
+	RowKey      *db.RowKey
+	Ctids       []string
 }
 
 // ExplainResultMsg is sent when an EXPLAIN query completes.
 type ExplainResultMsg struct {
-	Result *db.ExplainResult
-	Err    error
+	Query   string
+	Options db.ExplainOptions
+	Result  *db.ExplainResult
+	Err     error
 }
 
 // TablesListMsg is sent when \dt, \di, \dv completes.
@@ -29,22 +45,258 @@ type TablesListMsg struct {
 	Err    error
 }
 
+// ServerInfoMsg is sent when the server details for the connection banner
+// arrive.
+type ServerInfoMsg struct {
+	Info     *db.ServerInfo
+	Warnings []db.SanityWarning
+	Err      error
+}
+
+// SanityMsg is sent when \warnings has re-run the sanity checks.
+type SanityMsg struct {
+	ID       int // request ID, shared with QueryResultMsg
+	Warnings []db.SanityWarning
+	Err      error
+}
+
 // DescribeResultMsg is sent when a table describe completes.
 type DescribeResultMsg struct {
+	ID           int             // request ID, shared with QueryResultMsg
 	Result       *db.QueryResult // columns
 	Indexes      *db.QueryResult
 	ForeignKeys  *db.QueryResult
 	ReferencedBy *db.QueryResult
+	Constraints  *db.QueryResult       // CHECK / UNIQUE / EXCLUDE
+	Comments     *db.QueryResult       // table and column comments
+	Stats        *db.QueryResult       // operational statistics (statistic, value)
+	Hypertable   *db.HypertableDetails // nil unless a TimescaleDB hypertable
 	Header       string
 	Err          error
 }
 
+// DependenciesMsg is sent when a dependency lookup (D, \deps) completes.
+type DependenciesMsg struct {
+	ID     int // request ID, shared with QueryResultMsg
+	Report *db.DependencyReport
+	Err    error
+}
+
+// MaintenancePlanMsg is sent when a table action's statement is ready
+// to confirm.
+type MaintenancePlanMsg struct {
+	Table string // table list name the action was chosen for
+	Plan  *db.MaintenancePlan
+	Err   error
+}
+
+// MaintenanceProgressMsg carries a progress poll of a running table action.
+type MaintenanceProgressMsg struct {
+	Gen      int
+	Progress string
+	Err      error
+}
+
+// MaintenanceDoneMsg is sent when a table action finishes.
+type MaintenanceDoneMsg struct {
+	Gen     int
+	Plan    *db.MaintenancePlan
+	Elapsed time.Duration
+	Err     error
+}
+
+// ExportDoneMsg is sent when an \export finishes.
+type ExportDoneMsg struct {
+	Gen      int
+	Path     string
+	Rows     int64
+	Bytes    int64  // written to disk, after compression
+	Files    int    // more than one for a split export
+	Manifest string // manifest of a split export
+	Elapsed  time.Duration
+	Err      error
+}
+
+// AlterColumnsMsg is sent when the column wizard has loaded the table's columns.
+type AlterColumnsMsg struct {
+	Table   string // table list name
+	Columns []db.ColumnInfo
+	Err     error
+}
+
+// AlterPreviewMsg is sent when a column change has been checked.
+type AlterPreviewMsg struct {
+	Table   string
+	Preview *db.AlterColumnPreview
+	Err     error
+}
+
+// TxBeganMsg is sent when \begin has opened a transaction.
+type TxBeganMsg struct {
+	ID  int // result request of the \begin
+	Tx  pgx.Tx
+	Err error
+}
+
+// TxEndedMsg is sent when \commit or \rollback has ended the transaction.
+type TxEndedMsg struct {
+	ID     int
+	Commit bool
+	Err    error
+}
+
+// CellEditKeyMsg is sent when the cell editor has found how to address
+// the edited row.
+type CellEditKeyMsg struct {
+	Table string // table list name
+	Key   *db.RowKey
+	Err   error
+}
+
+// CellEditDoneMsg is sent when a cell's UPDATE finishes.
+type CellEditDoneMsg struct {
+	Table  string
+	SQL    string
+	Result *db.QueryResult
+	Err    error
+}
+
+// RowFormLoadedMsg is sent when the insert form has read the table's
+// columns, or the delete confirmation the table's row key.
+type RowFormLoadedMsg struct {
+	Table   string
+	Columns []db.ColumnInfo
+	Key     *db.RowKey
+	Err     error
+}
+
+// RowFormDoneMsg is sent when a row's INSERT or DELETE finishes.
+type RowFormDoneMsg struct {
+	Table  string
+	SQL    string
+	Result *db.QueryResult
+	Err    error
+}
+
+// AlterDoneMsg is sent when the column wizard's DDL finishes.
+type AlterDoneMsg struct {
+	Table string
+	DDL   string
+	Err   error
+}
+
+// IndexColumnsMsg is sent when the create index form has loaded the
+// table's columns.
+type IndexColumnsMsg struct {
+	Table   string // table list name
+	Columns []db.ColumnInfo
+	Err     error
+}
+
+// PickerColumnsMsg is sent when the column picker has loaded the table's
+// columns.
+type PickerColumnsMsg struct {
+	Table   string // table list name
+	Columns []db.ColumnInfo
+	Err     error
+}
+
+// TableColumnsMsg is sent when the column names of a table arrive for
+// completion in the SQL input.
+type TableColumnsMsg struct {
+	Schema  string // as resolved by tableRef, "" for the search path
+	Table   string
+	Columns []string
+	Err     error
+}
+
+// SequencesMsg is sent when the sequences of a schema arrive.
+type SequencesMsg struct {
+	Schema    string // as given to \sequences
+	Sequences []db.SequenceInfo
+	Err       error
+}
+
+// GroupSummaryMsg is sent when the row counts per value of a browsed
+// table's columns arrive.
+type GroupSummaryMsg struct {
+	Table   string // table list name
+	Columns []string
+	Result  *db.QueryResult
+	Err     error
+}
+
+// ReferencedByMsg is sent when the foreign keys referencing a browsed
+// table have loaded.
+type ReferencedByMsg struct {
+	Table  string // table list name
+	Result *db.QueryResult
+	Err    error
+}
+
+// IndexEstimateMsg is sent when an index size estimate completes.
+type IndexEstimateMsg struct {
+	Table    string
+	Estimate *db.IndexEstimate
+	Err      error
+}
+
 // AIResponseMsg is sent when an AI request completes.
 type AIResponseMsg struct {
+	ID       int // request ID; replies to superseded requests are dropped
 	Response string
 	Err      error
 }
 
+// AIChunkMsg carries the next part of a streamed chat reply. The
+// receiver must return next, which waits for the rest of the stream.
+type AIChunkMsg struct {
+	ID    int
+	Delta string
+	next  tea.Cmd
+}
+
+// SchemaRetrievalMsg carries the tables retrieved for a chat question.
+// Index is set when the schema index was fetched for the retrieval, so
+// the view can cache it.
+type SchemaRetrievalMsg struct {
+	ID       int
+	Question string
+	Tables   []string
+	Index    *db.SchemaIndex
+	Err      error
+}
+
+// MigrationReviewMsg is sent when an AI migration review (\review)
+// completes. File is empty for pasted SQL.
+type MigrationReviewMsg struct {
+	File   string
+	SQL    string
+	Review *ai.MigrationReview
+	Err    error
+}
+
+// OllamaModelsMsg lists the models installed in Ollama.
+type OllamaModelsMsg struct {
+	Models []ai.OllamaModel
+	Quiet  bool // startup check: only report a missing configured model
+	Err    error
+}
+
+// OllamaPullMsg reports progress of an Ollama model download. Like
+// AIChunkMsg, the receiver returns next to keep reading the stream.
+type OllamaPullMsg struct {
+	Model    string
+	Progress ai.PullProgress
+	next     tea.Cmd
+}
+
+// OllamaPullDoneMsg is sent when an Ollama model download ends.
+type OllamaPullDoneMsg struct {
+	Model string
+	Err   error
+}
+
 // IndexSuggestionMsg is sent when AI index analysis completes.
 type IndexSuggestionMsg struct {
 	Suggestion string
@@ -54,15 +306,73 @@ type IndexSuggestionMsg struct {
 // StatsMsg carries database statistics.
 type StatsMsg struct {
 	Lines []string
+	Spill *db.SpillReport
 	Err   error
 }
 
+// DatabaseStatsMsg carries a sample of pg_stat_database for the activity
+// view.
+type DatabaseStatsMsg struct {
+	Gen   int
+	Stats []db.DatabaseStats
+	At    time.Time
+	Err   error
+}
+
+// LockSessionsMsg carries the blocking chains for the locks view.
+type LockSessionsMsg struct {
+	Sessions []db.LockSession
+	At       time.Time
+	Err      error
+}
+
+// DeadlocksMsg carries the deadlocks read from the server log.
+type DeadlocksMsg struct {
+	Deadlocks []db.Deadlock
+	Err       error
+}
+
+// WorkMemAdviceMsg is sent when the AI's work_mem advice arrives.
+type WorkMemAdviceMsg struct {
+	ID     int
+	Advice string
+	Err    error
+}
+
+// IntegrityMsg carries the result of a data-integrity check.
+type IntegrityMsg struct {
+	Report []db.TableIntegrity
+	Err    error
+}
+
 // LogMsg carries a new log line from tail.
 type LogMsg struct {
 	Line string
 	Err  error
 }
 
+// ListenerReadyMsg is sent when the Listen view's connection is open.
+type ListenerReadyMsg struct {
+	Listener *db.Listener
+	Err      error
+}
+
+// NotificationMsg is sent when a NOTIFY arrives on a listened channel, or
+// when the wait for one fails. Gen identifies the wait.
+type NotificationMsg struct {
+	Gen          int
+	Notification *db.Notification
+	Err          error
+}
+
+// ListenOpMsg is sent when a LISTEN, UNLISTEN or NOTIFY of the Listen
+// view finishes.
+type ListenOpMsg struct {
+	Op      string // "listen", "unlisten" or "notify"
+	Channel string
+	Err     error
+}
+
 // StatusMsg is a transient status message for the status bar.
 type StatusMsg string
 
@@ -74,6 +384,82 @@ type QueryPlanMsg struct {
 	Err         error
 }
 
+// ModificationEstimateMsg is sent when EXPLAIN of an AI modification
+// plan completes.
+type ModificationEstimateMsg struct {
+	SQL      string // the pending statement that was explained
+	Estimate *db.ModificationEstimate
+	Err      error
+}
+
+// InterpretMsg is sent when the AI finishes interpreting a plan's result.
+type InterpretMsg struct {
+	ID     int // request ID of the interpreted result, shared with QueryResultMsg
+	Answer string
+	Err    error
+}
+
+// ColumnSearchMsg is sent when a semantic column search completes.
+type ColumnSearchMsg struct {
+	Description string
+	Matches     []ai.ColumnMatch
+	Index       *db.SchemaIndex // freshly fetched index to cache (nil if cached one was used)
+	Err         error
+}
+
+// FDWAppliedMsg is sent when a postgres_fdw setup plan has been executed.
+type FDWAppliedMsg struct {
+	Plan *db.FDWPlan
+	Err  error
+}
+
+// SeedPreviewMsg is sent when fake rows for \seed have been generated.
+type SeedPreviewMsg struct {
+	Data *db.SeedData
+	Note string // how the rows were generated
+	Err  error
+}
+
+// SeedInsertedMsg is sent when \seed apply finishes inserting rows.
+type SeedInsertedMsg struct {
+	Table string
+	Count int
+	Err   error
+}
+
+// UpsertPlanMsg is sent when \upsert has built the script that copies
+// the result into a table of another saved connection.
+type UpsertPlanMsg struct {
+	Conn config.Connection
+	Plan *db.UpsertPlan
+	Err  error
+}
+
+// UpsertAppliedMsg is sent when \upsert apply finishes on the target.
+type UpsertAppliedMsg struct {
+	Plan *db.UpsertPlan
+	Rows int64
+	Err  error
+}
+
+// RecipeStepMsg is sent when a step of a \recipe run finishes. Result
+// is set for SQL steps, Note describes other steps.
+type RecipeStepMsg struct {
+	run    *recipeRun
+	Step   int
+	Result *db.QueryResult
+	Note   string
+	Err    error
+}
+
+// WatchResultMsg is sent when a run of an \every watch finishes.
+type WatchResultMsg struct {
+	ID     int
+	Result *db.QueryResult
+	Err    error
+	At     time.Time
+}
+
 // AntigravityLoginMsg is sent when Google Antigravity OAuth login completes.
 type AntigravityLoginMsg struct {
 	Err error
@@ -0,0 +1,192 @@
+// migration_review.go implements \review and \i in the SQL tab.
+//
+// \review <file|sql> sends a migration, with the current state of the
+// tables it touches, to the AI, which flags locking, rewrites, foreign
+// keys without indexes and irreversible steps. \i then applies it: \i
+// with no argument runs the SQL just reviewed, \i <file> runs a file.
+package tui
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/DachengChen/paiSQL/ai"
+	"github.com/DachengChen/paiSQL/applog"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// reviewedMigration is the migration of the last \review.
+type reviewedMigration struct {
+	file string // empty for pasted SQL
+	sql  string
+}
+
+// readMigration reads arg as a file if one exists at that path, or
+// returns it as SQL.
+func readMigration(arg string) (file, sql string, err error) {
+	if !strings.ContainsAny(arg, "\n;") {
+		path := arg
+		if rest, ok := strings.CutPrefix(path, "~/"); ok {
+			if home, err := os.UserHomeDir(); err == nil {
+				path = filepath.Join(home, rest)
+			}
+		}
+		if info, statErr := os.Stat(path); statErr == nil && !info.IsDir() {
+			data, err := os.ReadFile(path)
+			return path, string(data), err
+		}
+	}
+	return "", arg, nil
+}
+
+// reviewMigration runs \review.
+func (v *MainView) reviewMigration(arg string) tea.Cmd {
+	v.input.Reset()
+	if arg == "" {
+		v.viewport.SetContent(StyleError.Render("Usage: \\review <file|sql> — paste the migration after \\review, or give its file"))
+		return nil
+	}
+	file, sql, err := readMigration(arg)
+	if err != nil {
+		v.viewport.SetContent(StyleError.Render("ERROR: " + err.Error()))
+		return nil
+	}
+	source := file
+	if source == "" {
+		source = "pasted SQL"
+	}
+	v.reviewed = nil
+	v.viewport.SetContentLines([]string{
+		StyleBold.Render("🔍 Reviewing " + source),
+		"",
+		StyleDimmed.Render("Reading the tables it touches and asking the AI…"),
+	})
+
+	database := v.db
+	provider := v.aiProvider
+	return func() tea.Msg {
+		ctx := context.Background()
+		schemaContext, err := database.MigrationContext(ctx, sql)
+		if err != nil {
+			return MigrationReviewMsg{File: file, SQL: sql, Err: fmt.Errorf("reading schema: %w", err)}
+		}
+		review, err := ai.ReviewMigration(ctx, provider, schemaContext, sql)
+		return MigrationReviewMsg{File: file, SQL: sql, Review: review, Err: err}
+	}
+}
+
+// showMigrationReview renders the result of \review.
+func (v *MainView) showMigrationReview(msg MigrationReviewMsg) {
+	source := msg.File
+	if source == "" {
+		source = "pasted SQL"
+	}
+	lines := []string{StyleBold.Render("🔍 Migration review: " + source), ""}
+	if msg.Err != nil {
+		lines = append(lines, StyleError.Render("Review failed: "+msg.Err.Error()))
+		v.viewport.SetContentLines(lines)
+		return
+	}
+	v.reviewed = &reviewedMigration{file: msg.File, sql: msg.SQL}
+
+	width := v.viewport.width - 4
+	if msg.Review.Summary != "" {
+		lines = append(lines, wrapLines(msg.Review.Summary, width)...)
+		lines = append(lines, "")
+	}
+	if len(msg.Review.Risks) == 0 {
+		lines = append(lines, StyleSuccess.Render("✔ No risks flagged"), "")
+	}
+	for _, r := range msg.Review.Risks {
+		style := StyleDimmed
+		switch r.Severity {
+		case "high":
+			style = StyleError
+		case "medium":
+			style = StyleWarning
+		}
+		lines = append(lines, style.Render(fmt.Sprintf("[%s]", strings.ToUpper(r.Severity)))+" "+StyleDimmed.Render(r.Statement))
+		for _, l := range wrapLines(r.Issue, width) {
+			lines = append(lines, "  "+l)
+		}
+		if r.Suggestion != "" {
+			for _, l := range wrapLines("→ "+r.Suggestion, width) {
+				lines = append(lines, "  "+l)
+			}
+		}
+		lines = append(lines, "")
+	}
+
+	apply := "\\i applies the reviewed SQL"
+	if msg.File != "" {
+		apply = "\\i (or \\i " + msg.File + ") applies it"
+	}
+	lines = append(lines, StyleDimmed.Render(apply+" inside the open transaction (\\begin first), to \\commit or \\rollback."))
+	v.viewport.SetContentLines(lines)
+}
+
+// includeFile runs \i [file]: the file, or the last reviewed migration.
+func (v *MainView) includeFile(arg string) tea.Cmd {
+	v.input.Reset()
+	var file, sql string
+	switch {
+	case arg != "":
+		var err error
+		file, sql, err = readMigration(arg)
+		if err == nil && file == "" {
+			err = fmt.Errorf("no such file: %s", arg)
+		}
+		if err != nil {
+			v.viewport.SetContent(StyleError.Render("ERROR: " + err.Error()))
+			return nil
+		}
+	case v.reviewed != nil:
+		file, sql = v.reviewed.file, v.reviewed.sql
+	default:
+		v.viewport.SetContent(StyleError.Render("Usage: \\i <file> (or \\i alone after \\review)"))
+		return nil
+	}
+
+	// A script changes the database like the modification commands, which
+	// need a transaction; it runs in the open one, to \commit or
+	// \rollback as a whole.
+	if v.tx == nil {
+		v.viewport.SetContentLines([]string{
+			"⚠️  Modification commands require a transaction",
+			"",
+			"  1. Type: \\begin  (or BEGIN;)",
+			"  2. Run \\i again: the script runs inside the transaction",
+			"  3. Type: \\commit  (to save)  or  \\rollback  (to undo)",
+		})
+		v.input.SetValue(strings.TrimSpace("\\i " + arg)) // keep the input, as for other commands
+		return nil
+	}
+	if v.loading {
+		return func() tea.Msg { return StatusMsg("Wait for the running statement before \\i") }
+	}
+
+	source := file
+	if source == "" {
+		source = "reviewed SQL"
+	}
+	v.reviewed = nil
+	v.loading = true
+	v.lastSQL = strings.TrimSpace(sql)
+	id := v.newResultRequest()
+	ctx := v.queryContext()
+	database, tx := v.db, v.tx
+	applog.Event("MIGRATION", "Running %s", source)
+	return func() tea.Msg {
+		result, err := database.ExecScriptTx(ctx, tx, sql)
+		if err != nil {
+			applog.Event("MIGRATION", "Failed: %s: %v", source, err)
+		} else {
+			applog.Event("MIGRATION", "Applied %s (%s)", source, result.Status)
+			result.Status = source + ": " + result.Status
+		}
+		return QueryResultMsg{ID: id, Result: result, Err: err}
+	}
+}
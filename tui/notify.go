@@ -0,0 +1,181 @@
+// notify.go turns completions in inactive views into a status bar badge.
+// Routed results (see tasks.go) reach their view even when the user is
+// elsewhere; without a notice they would finish unseen.
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// jumpToNoticeKey switches to the view of the latest notice.
+const jumpToNoticeKey = "f6"
+
+// maxNotices is how many notices are kept; older ones are dropped, as the
+// badge only shows the latest and a count.
+const maxNotices = 50
+
+// notice records a background completion in a view that wasn't active.
+type notice struct {
+	view View
+	text string
+	err  bool
+}
+
+// completionNotice describes messages that finish work the user started.
+// Periodic refreshes (stats, log, table list, browsed pages) are not worth
+// a badge.
+func completionNotice(msg tea.Msg) (text string, failed bool, ok bool) {
+	switch m := msg.(type) {
+	case QueryResultMsg:
+		if m.Refresh {
+			return "", false, false
+		}
+		if m.Err != nil {
+			return "Query failed: " + m.Err.Error(), true, true
+		}
+		if m.Result != nil && len(m.Result.Columns) > 0 {
+			return fmt.Sprintf("Query finished: %d rows", m.Result.RowCount), false, true
+		}
+		if m.Result != nil {
+			return "Query finished: " + m.Result.Status, false, true
+		}
+	case DescribeResultMsg:
+		if m.Err != nil {
+			return "Describe failed: " + m.Err.Error(), true, true
+		}
+		return "Describe finished", false, true
+	case DependenciesMsg:
+		if m.Err != nil {
+			return "Dependency lookup failed: " + m.Err.Error(), true, true
+		}
+		return "Dependencies ready", false, true
+	case MaintenanceDoneMsg:
+		if m.Err != nil {
+			return m.Plan.Action.Name + " failed: " + m.Err.Error(), true, true
+		}
+		return m.Plan.Action.Name + " finished", false, true
+	case ExportDoneMsg:
+		if errors.Is(m.Err, context.Canceled) {
+			return "Export to " + m.Path + " cancelled", false, true
+		}
+		if m.Err != nil {
+			return "Export to " + m.Path + " failed: " + m.Err.Error(), true, true
+		}
+		return fmt.Sprintf("Exported %d rows to %s", m.Rows, m.Path), false, true
+	case AlterDoneMsg:
+		if m.Err != nil {
+			return "ALTER TABLE failed: " + m.Err.Error(), true, true
+		}
+		return "ALTER TABLE finished", false, true
+	case ExplainResultMsg:
+		if m.Err != nil {
+			return "EXPLAIN failed: " + m.Err.Error(), true, true
+		}
+		return "EXPLAIN finished", false, true
+	case IndexSuggestionMsg:
+		if m.Err != nil {
+			return "Index analysis failed: " + m.Err.Error(), true, true
+		}
+		return "Index suggestions ready", false, true
+	case AIResponseMsg:
+		if m.Err != nil {
+			return "AI request failed: " + m.Err.Error(), true, true
+		}
+		return "AI reply ready", false, true
+	case MigrationReviewMsg:
+		if m.Err != nil {
+			return "Migration review failed: " + m.Err.Error(), true, true
+		}
+		return fmt.Sprintf("Migration review ready: %d risks", len(m.Review.Risks)), false, true
+	case OllamaPullDoneMsg:
+		if m.Err != nil {
+			return "Pull of " + m.Model + " failed: " + m.Err.Error(), true, true
+		}
+		return "Pulled " + m.Model, false, true
+	case IntegrityMsg:
+		if m.Err != nil {
+			return "Integrity check failed: " + m.Err.Error(), true, true
+		}
+		issues := 0
+		for _, t := range m.Report {
+			issues += len(t.Issues)
+		}
+		return fmt.Sprintf("Integrity check finished: %d issues", issues), false, true
+	case FDWAppliedMsg:
+		if m.Err != nil {
+			return "postgres_fdw setup failed: " + m.Err.Error(), true, true
+		}
+		return "postgres_fdw setup applied", false, true
+	case SeedInsertedMsg:
+		if m.Err != nil {
+			return "Seed failed: " + m.Err.Error(), true, true
+		}
+		return fmt.Sprintf("Seed finished: %d rows → %s", m.Count, m.Table), false, true
+	case UpsertAppliedMsg:
+		if m.Err != nil {
+			return "Copy to " + m.Plan.Connection + " failed: " + m.Err.Error(), true, true
+		}
+		return fmt.Sprintf("Copied %d rows → %s on %s", m.Rows, m.Plan.Table, m.Plan.Connection), false, true
+	}
+	return "", false, false
+}
+
+// notifyCompletion adds a notice when msg completes work in view i while
+// another view is active.
+func (a *App) notifyCompletion(i int, msg tea.Msg) {
+	if i == a.activeTab {
+		return
+	}
+	if text, failed, ok := completionNotice(msg); ok {
+		a.notices = append(a.notices, notice{view: a.views[i], text: text, err: failed})
+		if n := len(a.notices); n > maxNotices {
+			a.notices = append(a.notices[:0], a.notices[n-maxNotices:]...)
+		}
+	}
+}
+
+// clearNotices drops the notices of the now active view.
+func (a *App) clearNotices() {
+	if a.activeTab >= len(a.views) {
+		return
+	}
+	kept := a.notices[:0]
+	for _, n := range a.notices {
+		if n.view != a.views[a.activeTab] {
+			kept = append(kept, n)
+		}
+	}
+	a.notices = kept
+}
+
+// jumpToNotice switches to the view of the latest notice.
+func (a *App) jumpToNotice() (tea.Model, tea.Cmd) {
+	if len(a.notices) == 0 {
+		return a, nil
+	}
+	latest := a.notices[len(a.notices)-1]
+	for i, v := range a.views {
+		if v == latest.view {
+			return a.switchTab(i)
+		}
+	}
+	return a, nil
+}
+
+// renderNoticeBadge renders the latest notice for the status bar.
+func (a *App) renderNoticeBadge() string {
+	latest := a.notices[len(a.notices)-1]
+	style := StyleSuccess
+	if latest.err {
+		style = StyleError
+	}
+	badge := style.Render("● "+latest.text) + StyleDimmed.Render(" in "+latest.view.Name())
+	if more := len(a.notices) - 1; more > 0 {
+		badge += StyleDimmed.Render(fmt.Sprintf(" (+%d more)", more))
+	}
+	return badge + "  " + StyleHelpKey.Render("F6") + " " + StyleHelpDesc.Render("view")
+}
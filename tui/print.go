@@ -0,0 +1,116 @@
+// print.go holds the psql-compatible \pset options that change how the
+// result grid is drawn: border style, NULL display, output format and
+// tuples-only mode.
+package tui
+
+import (
+	"encoding/csv"
+	"strings"
+
+	"github.com/DachengChen/paiSQL/db"
+)
+
+// nullCell is how db.FormatValue renders a NULL value.
+const nullCell = "<nil>"
+
+// Output formats accepted by \pset format.
+const (
+	formatAligned   = "aligned"
+	formatUnaligned = "unaligned"
+	formatCSV       = "csv"
+)
+
+// printOptions are the psql \pset settings applied by the grid renderer.
+type printOptions struct {
+	border     int    // 0, 1 or 2, like psql
+	null       string // shown in place of NULL
+	format     string // formatAligned, formatUnaligned or formatCSV
+	tuplesOnly bool   // hide column headers and the row count footer
+}
+
+// defaultPrintOptions matches the grid as it looks without any \pset.
+func defaultPrintOptions() printOptions {
+	return printOptions{border: 1, null: nullCell, format: formatAligned}
+}
+
+// withNullDisplay returns rows with NULLs replaced by the \pset null
+// string. The input is never modified since it may be the raw result.
+func (o printOptions) withNullDisplay(rows [][]string) [][]string {
+	if o.null == nullCell {
+		return rows
+	}
+	out := make([][]string, len(rows))
+	for i, row := range rows {
+		out[i] = make([]string, len(row))
+		for j, cell := range row {
+			if cell == nullCell {
+				cell = o.null
+			}
+			out[i][j] = cell
+		}
+	}
+	return out
+}
+
+// gridLine joins padded cells with the separators for the border style.
+func (o printOptions) gridLine(cells []string) string {
+	switch o.border {
+	case 0:
+		return strings.Join(cells, " ")
+	case 2:
+		return "│ " + strings.Join(cells, " │ ") + " │"
+	}
+	return " " + strings.Join(cells, " │ ") + " "
+}
+
+// gridRule draws a horizontal rule for columns of the given widths.
+// left, mid and right are the junction characters; border 1 has no outer
+// edges, so left and right are ignored there.
+func (o printOptions) gridRule(widths []int, left, mid, right string) string {
+	segs := make([]string, len(widths))
+	for i, w := range widths {
+		if o.border == 0 {
+			segs[i] = strings.Repeat("-", w)
+		} else {
+			segs[i] = strings.Repeat("─", w+2)
+		}
+	}
+	switch o.border {
+	case 0:
+		return strings.Join(segs, " ")
+	case 2:
+		return left + strings.Join(segs, mid) + right
+	}
+	return strings.Join(segs, mid)
+}
+
+// formatResultCSV renders a result as RFC 4180 CSV, like psql's csv
+// format. There is no footer, so the output can be copied as-is.
+func (o printOptions) formatResultCSV(r *db.QueryResult, rows [][]string) []string {
+	var b strings.Builder
+	w := csv.NewWriter(&b)
+	if !o.tuplesOnly {
+		_ = w.Write(r.Columns)
+	}
+	for _, row := range rows {
+		_ = w.Write(row)
+	}
+	w.Flush()
+	return strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+}
+
+// formatResultUnaligned renders a result with "|" between fields and no
+// padding, like psql's unaligned format.
+func (o printOptions) formatResultUnaligned(r *db.QueryResult, rows [][]string) []string {
+	var lines []string
+	if !o.tuplesOnly {
+		lines = append(lines, strings.Join(r.Columns, "|"))
+	}
+	for _, row := range rows {
+		lines = append(lines, strings.Join(row, "|"))
+	}
+	if !o.tuplesOnly {
+		lines = append(lines, r.Status)
+	}
+	return lines
+}
@@ -0,0 +1,223 @@
+// quick_filter.go implements quick filters on a browsed table: < and >
+// in the results move the focused column, f keeps only the rows whose
+// focused column has the focused row's value and F excludes them. The
+// filters stack (ANDed) and are shown as chips in the page header:
+//
+//	u                   drop the last filter
+//	\filter             list the filters
+//	\filter drop <n>    drop filter n
+//	\filter clear       drop them all
+//
+// Filters last until another table is browsed; they are not saved.
+package tui
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/DachengChen/paiSQL/db"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// quickFilterValueLength caps how much of a value a chip shows.
+const quickFilterValueLength = 30
+
+// quickFilter is one WHERE condition of a browsed table.
+type quickFilter struct {
+	column  string
+	value   string // display value, for the chip
+	literal string // SQL literal of the value; "" for NULL
+	exclude bool
+}
+
+// sql renders the condition. Excluding a value keeps the rows where the
+// column is NULL.
+func (f quickFilter) sql() string {
+	col := db.SelectList([]string{f.column})
+	switch {
+	case f.literal == "" && f.exclude:
+		return col + " IS NOT NULL"
+	case f.literal == "":
+		return col + " IS NULL"
+	case f.exclude:
+		return col + " IS DISTINCT FROM " + f.literal
+	}
+	return col + " = " + f.literal
+}
+
+// chip renders the filter for the page header.
+func (f quickFilter) chip() string {
+	if f.literal == "" {
+		if f.exclude {
+			return f.column + " is not null"
+		}
+		return f.column + " is null"
+	}
+	op := " = "
+	if f.exclude {
+		op = " ≠ "
+	}
+	return f.column + op + truncateRunes(f.value, quickFilterValueLength)
+}
+
+// truncateRunes shortens s to n runes, ending it with … when cut.
+func truncateRunes(s string, n int) string {
+	s = strings.ReplaceAll(s, "\n", "↵")
+	if n < 1 {
+		return ""
+	}
+	if r := []rune(s); len(r) > n {
+		return string(r[:n-1]) + "…"
+	}
+	return s
+}
+
+// browseWhere is the WHERE clause of the quick filters, with a leading
+// space, or "".
+func (v *MainView) browseWhere() string {
+	if len(v.quickFilters) == 0 {
+		return ""
+	}
+	conds := make([]string, len(v.quickFilters))
+	for i, f := range v.quickFilters {
+		conds[i] = f.sql()
+	}
+	return " WHERE " + strings.Join(conds, " AND ")
+}
+
+// filterChips renders the quick filters for the page header, or "".
+func (v *MainView) filterChips() string {
+	if len(v.quickFilters) == 0 {
+		return ""
+	}
+	chips := make([]string, len(v.quickFilters))
+	for i, f := range v.quickFilters {
+		chips[i] = fmt.Sprintf("[%d: %s]", i+1, f.chip())
+	}
+	return "🔎 Filters: " + strings.Join(chips, " ") + "   (u drops the last, \\filter drop N one)"
+}
+
+// focusedCell returns the focused column's index and the focused row's
+// value in it, or ok false when the grid isn't showing.
+func (v *MainView) focusedCell() (col int, cell string, ok bool) {
+	row := v.focusedRow()
+	if row < 0 || v.expandedMode || len(v.result.Columns) == 0 {
+		return 0, "", false
+	}
+	col = min(v.focusCol, len(v.result.Columns)-1)
+	cells := v.result.Rows[row]
+	if col >= len(cells) {
+		return 0, "", false
+	}
+	return col, cells[col], true
+}
+
+// moveFocusCol moves the focused column by delta, panning the grid to
+// bring it into view.
+func (v *MainView) moveFocusCol(delta int) {
+	if v.result == nil || len(v.result.Columns) == 0 {
+		return
+	}
+	v.focusCol = min(max(v.focusCol+delta, 0), len(v.result.Columns)-1)
+	if v.focusCol >= len(v.gridCols) || v.expandedMode || v.viewport.wrapText {
+		return
+	}
+	vp, c := v.viewport, v.gridCols[v.focusCol]
+	switch {
+	case c.start < vp.scrollX:
+		vp.ScrollLeft(vp.scrollX - c.start)
+	case c.end > vp.scrollX+vp.width:
+		vp.ScrollRight(min(c.end-vp.width, c.start) - vp.scrollX)
+	}
+}
+
+// addQuickFilter filters the browsed table to (or, with exclude, away
+// from) the focused cell's value.
+func (v *MainView) addQuickFilter(exclude bool) tea.Cmd {
+	status := func(text string) tea.Cmd { return func() tea.Msg { return StatusMsg(text) } }
+	if v.pagTable == "" {
+		return status("Quick filters work while browsing a table (Enter in the table list)")
+	}
+	col, cell, ok := v.focusedCell()
+	if !ok {
+		return status("Quick filters need the result grid (x leaves expanded display, w wrapping)")
+	}
+	colType := ""
+	if col < len(v.result.ColumnTypes) {
+		colType = v.result.ColumnTypes[col]
+	}
+	f, err := newQuickFilter(v.result.Columns[col], colType, cell, exclude)
+	if err != nil {
+		return status("Quick filter: " + err.Error())
+	}
+	return v.applyQuickFilter(f)
+}
+
+// newQuickFilter builds the filter on column, of type colType, for a
+// value as the grid holds it.
+func newQuickFilter(column, colType, cell string, exclude bool) (quickFilter, error) {
+	f := quickFilter{column: column, value: cell, exclude: exclude}
+	if cell == nullCell {
+		return f, nil
+	}
+	literal, err := db.ValueLiteral(colType, cell)
+	if err != nil {
+		return f, err
+	}
+	f.literal = literal
+	return f, nil
+}
+
+// applyQuickFilter adds f to the filters of the browsed table.
+func (v *MainView) applyQuickFilter(f quickFilter) tea.Cmd {
+	if slices.Contains(v.quickFilters, f) {
+		return func() tea.Msg { return StatusMsg("Already filtered on " + f.chip()) }
+	}
+	v.quickFilters = append(v.quickFilters, f)
+	return v.refilter()
+}
+
+// refilter fetches the first page of the browsed table with the current
+// filters.
+func (v *MainView) refilter() tea.Cmd {
+	v.pagPage, v.pagTotal = 0, 0
+	return v.fetchPage()
+}
+
+// dropLastFilter implements u: it drops the last quick filter.
+func (v *MainView) dropLastFilter() tea.Cmd {
+	if v.pagTable == "" || len(v.quickFilters) == 0 {
+		return nil
+	}
+	v.quickFilters = v.quickFilters[:len(v.quickFilters)-1]
+	return v.refilter()
+}
+
+// filterCommand implements \filter.
+func (v *MainView) filterCommand(args []string) tea.Cmd {
+	v.input.Reset()
+	status := func(text string) tea.Cmd { return func() tea.Msg { return StatusMsg(text) } }
+	if v.pagTable == "" {
+		return status("\\filter: browse a table first (Enter in the table list)")
+	}
+	switch {
+	case len(args) == 0:
+		if len(v.quickFilters) == 0 {
+			return status("No quick filters: f in the results filters to the focused value, F excludes it")
+		}
+		return status(v.filterChips())
+	case len(args) == 1 && args[0] == "clear":
+		v.quickFilters = nil
+		return v.refilter()
+	case len(args) == 2 && args[0] == "drop":
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n < 1 || n > len(v.quickFilters) {
+			return status(fmt.Sprintf("\\filter drop: there are %d filters", len(v.quickFilters)))
+		}
+		v.quickFilters = slices.Delete(v.quickFilters, n-1, n)
+		return v.refilter()
+	}
+	return status("Usage: \\filter | \\filter drop <n> | \\filter clear")
+}
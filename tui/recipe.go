@@ -0,0 +1,323 @@
+// recipe.go runs recipes (config/recipes.go) with \recipe <name>: the
+// steps are shown in place of the results with their progress, a
+// confirmation step waits for y, and Esc stops the run, cancelling a
+// running statement. \recipe alone lists the saved recipes.
+package tui
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/DachengChen/paiSQL/applog"
+	"github.com/DachengChen/paiSQL/config"
+	"github.com/DachengChen/paiSQL/db"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// recipeRun is a recipe being run, shown over the results pane.
+type recipeRun struct {
+	recipe     *config.Recipe
+	step       int      // the running step; len(steps) once finished
+	outcome    []string // what each finished step did
+	err        error    // why the run stopped, if it failed
+	stopped    bool     // stopped by Esc or a declined confirmation
+	confirming bool     // the current step waits for y/n
+	waitUntil  time.Time
+	started    time.Time
+	elapsed    time.Duration
+	last       *db.QueryResult // result of the latest SQL step
+	cancel     context.CancelFunc
+}
+
+// finished reports whether the run has ended, successfully or not.
+func (r *recipeRun) finished() bool {
+	return r.err != nil || r.stopped || r.step >= len(r.recipe.Steps)
+}
+
+// recipeCommand runs \recipe [name].
+func (v *MainView) recipeCommand(args []string) tea.Cmd {
+	v.input.Reset()
+	if len(args) == 0 {
+		v.listRecipes()
+		return nil
+	}
+	if v.recipe != nil && !v.recipe.finished() {
+		v.viewport.SetContent(StyleError.Render("Recipe " + v.recipe.recipe.Name + " is still running"))
+		return nil
+	}
+	recipe, err := config.LoadRecipe(strings.Join(args, " "))
+	if err != nil {
+		v.viewport.SetContent(StyleError.Render("Load failed: " + err.Error()))
+		return nil
+	}
+	v.recipe = &recipeRun{
+		recipe:  recipe,
+		outcome: make([]string, len(recipe.Steps)),
+		started: time.Now(),
+	}
+	applog.Event("RECIPE", "Running %s (%d steps)", recipe.Name, len(recipe.Steps))
+	return v.runRecipeStep()
+}
+
+// listRecipes shows the recipes in ~/.paisql/recipes and the workspace's.
+func (v *MainView) listRecipes() {
+	dirs, err := config.RecipeDirs()
+	if err != nil {
+		v.viewport.SetContent(StyleError.Render("ERROR: " + err.Error()))
+		return
+	}
+	var lines []string
+	seen := map[string]bool{}
+	for i, dir := range dirs {
+		names, err := config.ListRecipes(dir)
+		if err != nil {
+			v.viewport.SetContent(StyleError.Render("ERROR: " + err.Error()))
+			return
+		}
+		if i > 0 && len(names) == 0 {
+			continue
+		}
+		if len(lines) > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, StyleBold.Render("Recipes")+StyleDimmed.Render(" in "+dir), "")
+		if len(names) == 0 {
+			lines = append(lines, StyleDimmed.Render("  (none — add a .json file with a list of steps)"))
+		}
+		for _, name := range names {
+			line := "  " + name
+			if seen[name] {
+				// \recipe <name> runs the one listed above.
+				lines = append(lines, StyleDimmed.Render(line+"  (hidden by yours of the same name)"))
+				continue
+			}
+			seen[name] = true
+			if r, err := config.LoadRecipe(name); err != nil {
+				line += StyleError.Render("  " + err.Error())
+			} else if r.Description != "" {
+				line += StyleDimmed.Render("  " + r.Description)
+			}
+			lines = append(lines, line)
+		}
+	}
+	lines = append(lines, "", StyleDimmed.Render("\\recipe <name> runs one; a path with a directory runs any file."))
+	v.viewport.SetContentLines(lines)
+}
+
+// runRecipeStep starts the current step.
+func (v *MainView) runRecipeStep() tea.Cmd {
+	run := v.recipe
+	if run.finished() {
+		v.finishRecipe()
+		return nil
+	}
+	i := run.step
+	step := run.recipe.Steps[i]
+	switch step.Kind() {
+	case config.StepSQL:
+		ctx, cancel := context.WithCancel(context.Background())
+		run.cancel = cancel
+		database := v.db
+		sql := step.SQL
+		applog.Event("RECIPE", "%s step %d: %s", run.recipe.Name, i+1, sql)
+		return func() tea.Msg {
+			defer cancel()
+			result, err := database.Execute(ctx, sql)
+			return RecipeStepMsg{run: run, Step: i, Result: result, Err: err}
+		}
+
+	case config.StepExport:
+		if run.last == nil {
+			return func() tea.Msg {
+				return RecipeStepMsg{run: run, Step: i, Err: fmt.Errorf("no SQL step before the export")}
+			}
+		}
+		path := step.Export
+		if rest, ok := strings.CutPrefix(path, "~/"); ok {
+			if home, err := os.UserHomeDir(); err == nil {
+				path = filepath.Join(home, rest)
+			}
+		}
+		r := run.last
+		return func() tea.Msg {
+			if err := writeResultFile(path, r); err != nil {
+				return RecipeStepMsg{run: run, Step: i, Err: err}
+			}
+			return RecipeStepMsg{run: run, Step: i, Note: fmt.Sprintf("%d rows → %s", len(r.Rows), path)}
+		}
+
+	case config.StepWait:
+		d := step.Duration()
+		run.waitUntil = time.Now().Add(d)
+		return tea.Tick(d, func(time.Time) tea.Msg {
+			return RecipeStepMsg{run: run, Step: i, Note: "waited " + d.String()}
+		})
+
+	case config.StepConfirm:
+		run.confirming = true
+	}
+	return nil
+}
+
+// finishRecipe logs the end of the run.
+func (v *MainView) finishRecipe() {
+	run := v.recipe
+	run.elapsed = time.Since(run.started)
+	switch {
+	case run.err != nil:
+		applog.Event("RECIPE", "%s failed at step %d: %v", run.recipe.Name, run.step+1, run.err)
+	case run.stopped:
+		applog.Event("RECIPE", "%s stopped at step %d", run.recipe.Name, run.step+1)
+	default:
+		applog.Event("RECIPE", "%s finished in %s", run.recipe.Name, run.elapsed.Round(time.Millisecond))
+	}
+}
+
+// updateRecipe handles a finished step and starts the next one.
+func (v *MainView) updateRecipe(msg RecipeStepMsg) tea.Cmd {
+	run := v.recipe
+	if run == nil || msg.run != run || msg.Step != run.step || run.finished() {
+		return nil // a stopped run or a closed overlay
+	}
+	if msg.Err != nil {
+		run.err = msg.Err
+		v.finishRecipe()
+		return nil
+	}
+	run.outcome[run.step] = msg.Note
+	if msg.Result != nil {
+		run.last = msg.Result
+		run.outcome[run.step] = msg.Result.Status
+	}
+	run.step++
+	return v.runRecipeStep()
+}
+
+func (v *MainView) handleRecipeKey(msg tea.KeyMsg) (View, tea.Cmd) {
+	run := v.recipe
+	key := msg.String()
+	if run.finished() {
+		if key == "esc" || key == "enter" {
+			v.closeRecipe()
+		}
+		return v, nil
+	}
+	if run.confirming {
+		switch key {
+		case "y":
+			run.confirming = false
+			run.outcome[run.step] = "confirmed"
+			run.step++
+			return v, v.runRecipeStep()
+		case "n", "esc":
+			run.confirming = false
+			run.stopped = true
+			v.finishRecipe()
+		}
+		return v, nil
+	}
+	if key == "esc" {
+		if run.cancel != nil {
+			run.cancel()
+		}
+		run.stopped = true
+		v.finishRecipe()
+	}
+	return v, nil
+}
+
+// closeRecipe hides the run and shows the result of its last SQL step.
+func (v *MainView) closeRecipe() {
+	last := v.recipe.last
+	v.recipe = nil
+	if last == nil {
+		return
+	}
+	v.result = last
+	v.chartMode, v.measureAll = false, false
+	v.pagTable, v.pagPlan = "", false
+	v.rightMode = rightModeData
+	v.redrawResult()
+}
+
+// recipeStepTitle describes a step in the progress list.
+func recipeStepTitle(s config.RecipeStep) string {
+	if s.Name != "" {
+		return s.Name
+	}
+	switch s.Kind() {
+	case config.StepSQL:
+		sql := []rune(strings.Join(strings.Fields(s.SQL), " "))
+		if len(sql) > 72 {
+			return string(sql[:71]) + "…"
+		}
+		return string(sql)
+	case config.StepExport:
+		return "Export to " + s.Export
+	case config.StepWait:
+		return "Wait " + s.Wait
+	}
+	return "Confirm: " + s.Confirm
+}
+
+// renderRecipe renders the run's progress in place of the results.
+func (v *MainView) renderRecipe() []string {
+	run := v.recipe
+	title := "📋 Recipe " + run.recipe.Name
+	lines := []string{StyleBold.Render(title)}
+	if run.recipe.Description != "" {
+		lines = append(lines, StyleDimmed.Render(run.recipe.Description))
+	}
+	lines = append(lines, "")
+
+	for i, s := range run.recipe.Steps {
+		text := fmt.Sprintf("%2d. %s", i+1, recipeStepTitle(s))
+		switch {
+		case i < run.step:
+			line := StyleSuccess.Render("✓") + " " + text
+			if run.outcome[i] != "" {
+				line += StyleDimmed.Render("  " + run.outcome[i])
+			}
+			lines = append(lines, line)
+		case i > run.step:
+			lines = append(lines, StyleDimmed.Render("· "+text))
+		case run.err != nil:
+			lines = append(lines, StyleError.Render("✗ "+text), StyleError.Render("     "+run.err.Error()))
+		case run.stopped:
+			lines = append(lines, StyleWarning.Render("■ "+text+"  (stopped)"))
+		case run.confirming:
+			lines = append(lines, StyleWarning.Render("? "+text))
+		default:
+			line := "⏳ " + StyleBold.Render(text)
+			if s.Kind() == config.StepWait {
+				line += StyleDimmed.Render("  until " + run.waitUntil.Format("15:04:05"))
+			}
+			lines = append(lines, line)
+		}
+	}
+
+	lines = append(lines, "")
+	switch {
+	case run.err != nil:
+		lines = append(lines, StyleError.Render(fmt.Sprintf("Failed at step %d.", run.step+1)))
+	case run.stopped:
+		lines = append(lines, StyleWarning.Render(fmt.Sprintf("Stopped at step %d.", run.step+1)))
+	case run.finished():
+		lines = append(lines, StyleSuccess.Render(fmt.Sprintf("✓ Finished in %s", run.elapsed.Round(time.Millisecond))))
+	}
+	switch {
+	case run.finished() && run.last != nil:
+		lines = append(lines, StyleDimmed.Render("Enter/Esc close and show the last result"))
+	case run.finished():
+		lines = append(lines, StyleDimmed.Render("Enter/Esc close"))
+	case run.confirming:
+		lines = append(lines, StyleDimmed.Render("y continue · n/Esc stop"))
+	default:
+		lines = append(lines, StyleDimmed.Render("Esc stop (cancels a running statement)"))
+	}
+	return lines
+}
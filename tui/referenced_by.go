@@ -0,0 +1,200 @@
+// referenced_by.go implements the reverse foreign key drill-down (r in
+// the results while browsing a table): it lists the foreign keys of other
+// tables that reference the browsed table and, on Enter, browses the
+// child rows that reference the focused row, filtered by the key's
+// columns. The key's conditions show as quick filter chips, so u or
+// \filter widen the view again.
+package tui
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/DachengChen/paiSQL/db"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// referencedBy is the list of referencing foreign keys shown over the
+// results pane.
+type referencedBy struct {
+	table   string // parent table list name
+	schema  string // parent (and child) schema
+	loading bool
+	keys    []childKey
+	cursor  int
+	err     error
+
+	// The focused row when r was pressed
+	columns []string
+	types   []string
+	row     []string
+}
+
+// childKey is a foreign key of a child table referencing the parent.
+type childKey struct {
+	table      string // child table name, unqualified
+	constraint string
+	columns    []string // child columns, in key order
+	refColumns []string // the parent columns they reference
+}
+
+// openReferencedBy lists the foreign keys referencing the browsed table.
+func (v *MainView) openReferencedBy() tea.Cmd {
+	status := func(text string) tea.Cmd { return func() tea.Msg { return StatusMsg(text) } }
+	if v.pagTable == "" {
+		return status("Referencing rows work while browsing a table (Enter in the table list)")
+	}
+	row := v.focusedRow()
+	if row < 0 || v.expandedMode {
+		return status("Referencing rows need the result grid (x leaves expanded display, w wrapping)")
+	}
+	table := v.pagTable
+	schema, name := v.tableRef(table)
+	v.refs = &referencedBy{
+		table: table, schema: schema, loading: true,
+		columns: slices.Clone(v.result.Columns),
+		types:   slices.Clone(v.result.ColumnTypes),
+		row:     slices.Clone(v.result.Rows[row]),
+	}
+	database := v.db
+	return func() tea.Msg {
+		result, err := database.TableReferencedBy(context.Background(), schema, name)
+		return ReferencedByMsg{Table: table, Result: result, Err: err}
+	}
+}
+
+// updateReferencedBy handles the referencing keys arriving. The rows come
+// one per column pair, grouped by table and constraint.
+func (v *MainView) updateReferencedBy(msg ReferencedByMsg) {
+	r := v.refs
+	if r == nil || !r.loading || r.table != msg.Table {
+		return
+	}
+	r.loading, r.err = false, msg.Err
+	if msg.Result == nil {
+		return
+	}
+	for _, row := range msg.Result.Rows {
+		if len(row) < 4 {
+			continue
+		}
+		n := len(r.keys)
+		if n == 0 || r.keys[n-1].table != row[0] || r.keys[n-1].constraint != row[2] {
+			r.keys = append(r.keys, childKey{table: row[0], constraint: row[2]})
+			n++
+		}
+		k := &r.keys[n-1]
+		k.columns = append(k.columns, row[1])
+		k.refColumns = append(k.refColumns, row[3])
+	}
+}
+
+// filters returns the quick filters on k's columns that select the child
+// rows referencing the focused row.
+func (r *referencedBy) filters(k childKey) ([]quickFilter, error) {
+	var filters []quickFilter
+	for i, ref := range k.refColumns {
+		col := slices.Index(r.columns, ref)
+		if col < 0 || col >= len(r.row) {
+			return nil, fmt.Errorf("%s is not among the columns shown (C picks them)", ref)
+		}
+		if r.row[col] == nullCell {
+			return nil, fmt.Errorf("the row's %s is NULL, which no row references", ref)
+		}
+		colType := ""
+		if col < len(r.types) {
+			colType = r.types[col]
+		}
+		f, err := newQuickFilter(k.columns[i], colType, r.row[col], false)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, f)
+	}
+	return filters, nil
+}
+
+// childTable returns the table list name of a child table in schema.
+func (v *MainView) childTable(schema, table string) string {
+	for i, t := range v.tables {
+		if i < len(v.tableSchemas) && v.tableSchemas[i] == schema {
+			if _, name := db.SplitTableName(t); name == table {
+				return t
+			}
+		}
+	}
+	if schema == "" {
+		return table
+	}
+	return schema + "." + table
+}
+
+func (v *MainView) handleReferencedByKey(msg tea.KeyMsg) (View, tea.Cmd) {
+	r := v.refs
+	switch msg.String() {
+	case "esc", "q", "r":
+		v.refs = nil
+	case "up", "k":
+		if r.cursor > 0 {
+			r.cursor--
+		}
+	case "down", "j":
+		if r.cursor < len(r.keys)-1 {
+			r.cursor++
+		}
+	case "enter":
+		if r.loading || len(r.keys) == 0 {
+			return v, nil
+		}
+		k := r.keys[r.cursor]
+		filters, err := r.filters(k)
+		if err != nil {
+			r.err = err
+			return v, nil
+		}
+		v.refs = nil
+		return v, v.browseFiltered(v.childTable(r.schema, k.table), filters)
+	}
+	return v, nil
+}
+
+// renderReferencedBy renders the referencing keys in place of the results.
+func (v *MainView) renderReferencedBy() []string {
+	r := v.refs
+	lines := []string{StyleBold.Render("↳ Tables referencing " + r.table), ""}
+	switch {
+	case r.loading:
+		return append(lines, StyleDimmed.Render("Loading foreign keys…"))
+	case r.err != nil && len(r.keys) == 0:
+		return append(lines, StyleError.Render("Error: "+r.err.Error()), "", StyleDimmed.Render("Esc close"))
+	case len(r.keys) == 0:
+		return append(lines, StyleDimmed.Render("No foreign keys in this schema reference "+r.table+"."),
+			"", StyleDimmed.Render("Esc close"))
+	}
+
+	first, last := 0, len(r.keys)
+	if n := v.viewport.height - 6; n > 0 && last > n {
+		first = min(max(r.cursor-n/2, 0), last-n)
+		last = first + n
+	}
+	for i := first; i < last; i++ {
+		k := r.keys[i]
+		conds := make([]string, len(k.columns))
+		for j, col := range k.columns {
+			value := "?"
+			if c := slices.Index(r.columns, k.refColumns[j]); c >= 0 && c < len(r.row) {
+				value = truncateRunes(r.row[c], quickFilterValueLength)
+			}
+			conds[j] = col + " = " + value
+		}
+		line := fmt.Sprintf("%s (%s)  %s", k.table, strings.Join(conds, ", "), StyleDimmed.Render(k.constraint))
+		lines = append(lines, pickLine(line, i == r.cursor))
+	}
+	lines = append(lines, "", StyleDimmed.Render("Enter browse the rows referencing the focused row · Esc close"))
+	if r.err != nil {
+		lines = append(lines, "", StyleError.Render("Error: "+r.err.Error()))
+	}
+	return lines
+}
@@ -0,0 +1,174 @@
+// result_export.go copies the current result to the clipboard as a
+// GitHub-flavored Markdown table (M in the results) or a plain HTML table
+// (H), for pasting into issues and docs. Cells are copied in full with
+// the display formatting and NULL string of the grid.
+//
+// \xlsx <file> writes the result as an Excel workbook with typed cells.
+package tui
+
+import (
+	"encoding/csv"
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/DachengChen/paiSQL/db"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// resultMarkdown renders r as a Markdown table. Numeric columns are
+// right-aligned; pipes and HTML are escaped and line breaks become <br>.
+func resultMarkdown(r *db.QueryResult, rows [][]string) string {
+	cell := func(s string) string {
+		s = strings.ReplaceAll(html.EscapeString(s), "|", `\|`)
+		return strings.ReplaceAll(strings.ReplaceAll(s, "\r\n", "<br>"), "\n", "<br>")
+	}
+	line := func(cells []string) string {
+		out := make([]string, len(r.Columns))
+		for i := range out {
+			if i < len(cells) {
+				out[i] = cell(cells[i])
+			}
+		}
+		return "| " + strings.Join(out, " | ") + " |"
+	}
+
+	var sb strings.Builder
+	sb.WriteString(line(r.Columns) + "\n")
+	rule := make([]string, len(r.Columns))
+	for i := range rule {
+		rule[i] = "---"
+		if i < len(r.ColumnTypes) && isNumericType(r.ColumnTypes[i]) {
+			rule[i] = "---:"
+		}
+	}
+	sb.WriteString("|" + strings.Join(rule, "|") + "|\n")
+	for _, row := range rows {
+		sb.WriteString(line(row) + "\n")
+	}
+	return sb.String()
+}
+
+// resultHTML renders r as an HTML table with escaped cells.
+func resultHTML(r *db.QueryResult, rows [][]string) string {
+	cell := func(tag string, i int, s string) string {
+		open := tag
+		if tag == "td" && i < len(r.ColumnTypes) && isNumericType(r.ColumnTypes[i]) {
+			open += ` align="right"`
+		}
+		s = strings.ReplaceAll(html.EscapeString(s), "\n", "<br>")
+		return "<" + open + ">" + s + "</" + tag + ">"
+	}
+
+	var sb strings.Builder
+	sb.WriteString("<table>\n  <thead>\n    <tr>")
+	for i, col := range r.Columns {
+		sb.WriteString(cell("th", i, col))
+	}
+	sb.WriteString("</tr>\n  </thead>\n  <tbody>\n")
+	for _, row := range rows {
+		sb.WriteString("    <tr>")
+		for i := range r.Columns {
+			text := ""
+			if i < len(row) {
+				text = row[i]
+			}
+			sb.WriteString(cell("td", i, text))
+		}
+		sb.WriteString("</tr>\n")
+	}
+	sb.WriteString("  </tbody>\n</table>\n")
+	return sb.String()
+}
+
+// copyResult copies the current result in the given format ("Markdown"
+// or "HTML") and reports the outcome on the status bar.
+func (v *MainView) copyResult(format string) tea.Cmd {
+	status := func(text string) tea.Cmd { return func() tea.Msg { return StatusMsg(text) } }
+	r := v.result
+	if r == nil || len(r.Columns) == 0 {
+		return status("Nothing to copy: run a query that returns rows")
+	}
+	if v.rowNumbers {
+		r = v.numberedResult(r)
+	}
+	rows := v.printOpts.withNullDisplay(displayRows(r, v.display))
+
+	text := resultMarkdown(r, rows)
+	if format == "HTML" {
+		text = resultHTML(r, rows)
+	}
+	if err := writeClipboard(text); err != nil {
+		return status("Copy failed: " + err.Error())
+	}
+	note := ""
+	if v.paged() {
+		note = " (this page)"
+	}
+	return status(fmt.Sprintf("Copied %d rows as a %s table%s", len(rows), format, note))
+}
+
+// exportXLSX implements \xlsx <file>: writes the current result, with its
+// raw values rather than the grid's display text, as an Excel workbook.
+func (v *MainView) exportXLSX(args []string) tea.Cmd {
+	v.input.Reset()
+	if len(args) < 1 {
+		v.viewport.SetContent(StyleError.Render("Usage: \\xlsx <file>"))
+		return nil
+	}
+	if v.result == nil || len(v.result.Columns) == 0 {
+		v.viewport.SetContent(StyleError.Render("Run a query that returns rows first"))
+		return nil
+	}
+	path := strings.Join(args, " ")
+	if filepath.Ext(path) == "" {
+		path += ".xlsx"
+	}
+	r := v.result
+	note := ""
+	if v.paged() {
+		note = " (this page)"
+	}
+	return func() tea.Msg {
+		if err := writeResultFile(path, r); err != nil {
+			return StatusMsg("XLSX export failed: " + err.Error())
+		}
+		return StatusMsg(fmt.Sprintf("Exported %d rows%s to %s", len(r.Rows), note, path))
+	}
+}
+
+// writeResultFile writes r with its raw values to path as an Excel
+// workbook, or as CSV with a header row if path ends in .csv. A file
+// that fails part way is removed.
+func writeResultFile(path string, r *db.QueryResult) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		w := csv.NewWriter(f)
+		_ = w.Write(r.Columns)
+		for _, row := range r.Rows {
+			out := make([]string, len(row))
+			for i, cell := range row {
+				if cell != nullCell {
+					out[i] = cell
+				}
+			}
+			_ = w.Write(out)
+		}
+		w.Flush()
+		err = w.Error()
+	} else {
+		err = db.WriteXLSX(f, r)
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(path)
+	}
+	return err
+}
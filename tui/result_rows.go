@@ -0,0 +1,170 @@
+// result_rows.go numbers the rows of the result grid (n in the results),
+// shows the absolute position of the focused row — the first row under
+// the pinned header — and its value in the focused column, and implements
+// \goto N, which fetches the right page of a browsed table or AI result
+// before scrolling to the row. In expanded display the position is the
+// record at the top of the pane and [ / ] jump between records, using the
+// lines each record actually takes (multi-line and wrapped values).
+package tui
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/DachengChen/paiSQL/db"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// paged reports whether the result is one page of a browsed table or AI plan.
+func (v *MainView) paged() bool {
+	return (v.pagTable != "" || v.pagPlan) && v.pagPageSize > 0
+}
+
+// rowOffset is the absolute index of the first row of the current page.
+func (v *MainView) rowOffset() int {
+	if !v.paged() {
+		return 0
+	}
+	return v.pagPage * v.pagPageSize
+}
+
+// totalRows is the row count of the whole result, across all pages.
+func (v *MainView) totalRows() int {
+	if v.paged() && v.pagTotal > 0 {
+		return int(v.pagTotal)
+	}
+	return len(v.result.Rows)
+}
+
+// numberedResult returns r with a leading # column holding each row's
+// absolute number.
+func (v *MainView) numberedResult(r *db.QueryResult) *db.QueryResult {
+	if r == nil || len(r.Columns) == 0 {
+		return r
+	}
+	types := make([]string, len(r.Columns)+1)
+	types[0] = "int8"
+	copy(types[1:], r.ColumnTypes)
+
+	offset := v.rowOffset()
+	rows := make([][]string, len(r.Rows))
+	for i, row := range r.Rows {
+		rows[i] = append([]string{strconv.Itoa(offset + i + 1)}, row...)
+	}
+	return &db.QueryResult{
+		Columns:     append([]string{"#"}, r.Columns...),
+		ColumnTypes: types,
+		Rows:        rows,
+		RowCount:    r.RowCount,
+		Status:      r.Status,
+	}
+}
+
+// focusedRow is the index in the current page of the first row under the
+// pinned header, or -1 when the results pane isn't showing the grid.
+func (v *MainView) focusedRow() int {
+	vp := v.viewport
+	if v.result == nil || len(v.result.Rows) == 0 || v.chartMode || vp.stickyLines == 0 || vp.wrapText {
+		return -1
+	}
+	return min(max(vp.scrollY-vp.stickyStart, 0), len(v.result.Rows)-1)
+}
+
+// focusedRecord is the index in the current page of the expanded record
+// at the top of the pane, or -1 when the pane isn't showing records.
+func (v *MainView) focusedRecord() int {
+	if v.result == nil || !v.expandedMode || v.chartMode || v.rightMode != rightModeData ||
+		len(v.records) == 0 || len(v.records) != len(v.result.Rows) {
+		return -1
+	}
+	top := v.viewport.ContentLine(v.viewport.scrollY) - v.resultOffset
+	i, found := slices.BinarySearch(v.records, top)
+	if !found {
+		i--
+	}
+	return max(i, 0)
+}
+
+// jumpRecord scrolls to the start of the next (dir 1) or previous (dir -1)
+// expanded record; going back from inside a record goes to its start.
+// It reports false when the pane isn't showing records.
+func (v *MainView) jumpRecord(dir int) bool {
+	i := v.focusedRecord()
+	if i < 0 {
+		return false
+	}
+	vp := v.viewport
+	start := vp.VisualLine(v.resultOffset + v.records[i])
+	switch {
+	case dir > 0 && i+1 < len(v.records):
+		i++
+	case dir < 0 && vp.scrollY <= start && i > 0:
+		i--
+	}
+	vp.ScrollTo(vp.VisualLine(v.resultOffset + v.records[i]))
+	return true
+}
+
+// rowPosition describes the focused row and cell, e.g.
+// "Row 41 of 1234 · status: active", or the record at the top of the
+// pane in expanded display.
+func (v *MainView) rowPosition() string {
+	if i := v.focusedRecord(); i >= 0 {
+		return fmt.Sprintf("Record %d of %d", v.rowOffset()+i+1, v.totalRows())
+	}
+	i := v.focusedRow()
+	if i < 0 {
+		return ""
+	}
+	position := fmt.Sprintf("Row %d of %d", v.rowOffset()+i+1, v.totalRows())
+	if col, cell, ok := v.focusedCell(); ok {
+		position += fmt.Sprintf(" · %s: %s", v.result.Columns[col], truncateRunes(cell, quickFilterValueLength))
+	}
+	return position
+}
+
+// scrollToRow makes row n (1-based, absolute) the focused row if it is
+// on the current page.
+func (v *MainView) scrollToRow(n int) bool {
+	i := n - 1 - v.rowOffset()
+	if v.focusedRow() < 0 || i < 0 || i >= len(v.result.Rows) {
+		return false
+	}
+	v.viewport.ScrollTo(v.viewport.stickyStart + i)
+	return true
+}
+
+// gotoRow runs \goto N. Rows on another page of a paged result are
+// fetched first; the scroll happens when the page arrives.
+func (v *MainView) gotoRow(args []string) tea.Cmd {
+	v.input.Reset()
+	status := func(text string) tea.Cmd { return func() tea.Msg { return StatusMsg(text) } }
+	if len(args) != 1 {
+		return status("Usage: \\goto <row>")
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n < 1 {
+		return status("\\goto: row must be a positive number")
+	}
+	if v.result == nil || len(v.result.Rows) == 0 {
+		return status("\\goto: run a query first")
+	}
+	if v.focusedRow() < 0 {
+		return status("\\goto: only in the result grid (x leaves expanded display, w wrapping)")
+	}
+	if total := v.totalRows(); n > total {
+		return status(fmt.Sprintf("\\goto: the result has %d rows", total))
+	}
+	if v.scrollToRow(n) {
+		return nil
+	}
+
+	page := (n - 1) / v.pagPageSize
+	v.gotoPending = n
+	if v.pagPlan && v.lastQueryPlan != nil {
+		return v.pageQueryPlan(page)
+	}
+	v.pagPage = page
+	return v.fetchPage()
+}
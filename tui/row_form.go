@@ -0,0 +1,356 @@
+// row_form.go implements adding and deleting rows of a browsed table from
+// the results: i opens a form with a field per column, its type and
+// default, and D looks up the focused row's key (as the cell editor
+// does, falling back to its ctid). Either way the generated INSERT or DELETE is shown for
+// confirmation before it runs — inside the open transaction, if there is
+// one — and the page is reloaded afterwards.
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/DachengChen/paiSQL/applog"
+	"github.com/DachengChen/paiSQL/db"
+	tea "github.com/charmbracelet/bubbletea"
+	pgx "github.com/jackc/pgx/v5"
+)
+
+type rowFormStep int
+
+const (
+	rowFormLoading rowFormStep = iota // reading the columns or the row key
+	rowFormInput                      // filling in the new row
+	rowFormReview                     // the statement; y runs it
+	rowFormRunning                    // waiting for the statement
+)
+
+// rowField is the value typed for one column of a new row. A field left
+// unset is omitted from the INSERT so the column gets its default.
+type rowField struct {
+	value textInput
+	set   bool
+	null  bool // set NULL instead of value
+}
+
+// rowForm is the insert form or delete confirmation shown over the
+// results pane.
+type rowForm struct {
+	table  string // table list name
+	delete bool
+	step   rowFormStep
+	sql    string
+	err    error
+
+	// Insert: the table's columns and a field for each
+	ref     string // schema-qualified, quoted
+	columns []db.ColumnInfo
+	fields  []rowField
+	cursor  int
+
+	// Delete: the row's key and the focused row when D was pressed
+	key         *db.RowKey
+	gridColumns []string
+	types       []string
+	row         []string
+	ctid        string
+}
+
+// openRowInsert opens the form for a new row of the browsed table.
+func (v *MainView) openRowInsert() tea.Cmd {
+	if v.pagTable == "" {
+		return func() tea.Msg { return StatusMsg("Adding rows works while browsing a table (Enter in the table list)") }
+	}
+	schema, name := v.tableRef(v.pagTable)
+	ref := pgx.Identifier{name}.Sanitize()
+	if schema != "" {
+		ref = pgx.Identifier{schema, name}.Sanitize()
+	}
+	f := &rowForm{table: v.pagTable, ref: ref}
+	v.rowForm = f
+	database := v.db
+	return func() tea.Msg {
+		columns, err := database.TableColumns(context.Background(), schema, name)
+		return RowFormLoadedMsg{Table: f.table, Columns: columns, Err: err}
+	}
+}
+
+// openRowDelete asks to delete the focused row of the browsed table.
+func (v *MainView) openRowDelete() tea.Cmd {
+	status := func(text string) tea.Cmd { return func() tea.Msg { return StatusMsg(text) } }
+	if v.pagTable == "" {
+		return status("Deleting rows works while browsing a table (Enter in the table list)")
+	}
+	row := v.focusedRow()
+	if v.expandedMode {
+		row = v.focusedRecord()
+	}
+	if row < 0 {
+		return status("No row to delete (w leaves wrapping)")
+	}
+	f := &rowForm{
+		table:       v.pagTable,
+		delete:      true,
+		gridColumns: slices.Clone(v.result.Columns),
+		types:       slices.Clone(v.result.ColumnTypes),
+		row:         slices.Clone(v.result.Rows[row]),
+		ctid:        v.focusedCtid(row),
+	}
+	v.rowForm = f
+	schema, name := v.tableRef(f.table)
+	database := v.db
+	return func() tea.Msg {
+		key, err := database.TableRowKey(context.Background(), schema, name)
+		return RowFormLoadedMsg{Table: f.table, Key: key, Err: err}
+	}
+}
+
+// buildInsert generates the INSERT of the fields that were filled in.
+func (f *rowForm) buildInsert() string {
+	var columns []string
+	var values []*string
+	for i, field := range f.fields {
+		if !field.set {
+			continue
+		}
+		columns = append(columns, f.columns[i].Name)
+		if field.null {
+			values = append(values, nil)
+		} else {
+			value := field.value.Value()
+			values = append(values, &value)
+		}
+	}
+	return db.InsertRow(f.ref, columns, values)
+}
+
+func (v *MainView) handleRowFormKey(msg tea.KeyMsg) (View, tea.Cmd) {
+	f := v.rowForm
+	key := msg.String()
+	switch f.step {
+	case rowFormLoading:
+		if key == "esc" {
+			v.rowForm = nil
+		}
+
+	case rowFormInput:
+		field := &f.fields[f.cursor]
+		switch key {
+		case "esc":
+			v.rowForm = nil
+		case "enter":
+			f.sql, f.err, f.step = v.styleSQL(f.buildInsert()), nil, rowFormReview
+		case "up", "shift+tab":
+			f.cursor = max(f.cursor-1, 0)
+		case "down", "tab":
+			f.cursor = min(f.cursor+1, len(f.fields)-1)
+		case "ctrl+n":
+			field.null = !field.null
+			field.set = field.null || field.value.Value() != ""
+		case "ctrl+u":
+			*field = rowField{}
+		default:
+			// Editing the text, or backspace on NULL, takes the typed value;
+			// an emptied field goes back to the default.
+			before := field.value.Value()
+			if field.value.Update(msg) && (field.value.Value() != before || key == "backspace") {
+				field.set, field.null = field.value.Value() != "", false
+			}
+		}
+
+	case rowFormReview:
+		switch key {
+		case "y":
+			return v, v.runRowForm()
+		case "esc", "n":
+			if f.delete {
+				v.rowForm = nil
+			} else {
+				f.step, f.err = rowFormInput, nil
+			}
+		}
+	}
+	return v, nil
+}
+
+// runRowForm executes the reviewed statement.
+func (v *MainView) runRowForm() tea.Cmd {
+	f := v.rowForm
+	// It shares the connection with the statements of the results pane
+	// (the open transaction's, which can't run two at once), so it waits
+	// for them like a typed statement, and they queue behind it.
+	if v.loading {
+		f.err = errors.New("a statement is still running: press y again once it is done")
+		return nil
+	}
+	v.loading = true
+	f.step = rowFormRunning
+	applog.Event("SQL", "Row change confirmed: %s", f.sql)
+	run, sql, table := v.queryRunner(), f.sql, f.table
+	ctx := v.queryContext()
+	return func() tea.Msg {
+		result, err := run(ctx, sql)
+		return RowFormDoneMsg{Table: table, SQL: sql, Result: result, Err: err}
+	}
+}
+
+// updateRowForm handles the insert form's and delete confirmation's
+// messages.
+func (v *MainView) updateRowForm(msg tea.Msg) tea.Cmd {
+	f := v.rowForm
+	status := func(text string) tea.Cmd { return func() tea.Msg { return StatusMsg(text) } }
+	switch msg := msg.(type) {
+	case RowFormLoadedMsg:
+		if f == nil || f.step != rowFormLoading || f.table != msg.Table {
+			return nil
+		}
+		if msg.Err != nil {
+			v.rowForm = nil
+			if f.delete {
+				return status("Delete row: " + msg.Err.Error())
+			}
+			return status("Add row: " + msg.Err.Error())
+		}
+		if f.delete {
+			f.key = msg.Key
+			literals, err := rowKeyLiterals(f.key, f.gridColumns, f.types, f.row, f.ctid)
+			if err == nil {
+				f.sql, err = f.key.DeleteRow(literals)
+			}
+			if err != nil {
+				v.rowForm = nil
+				return status("Delete row: " + err.Error())
+			}
+			f.sql, f.step = v.styleSQL(f.sql), rowFormReview
+			return nil
+		}
+		if len(msg.Columns) == 0 {
+			v.rowForm = nil
+			return status("Add row: " + f.table + " has no columns")
+		}
+		f.columns, f.fields, f.step = msg.Columns, make([]rowField, len(msg.Columns)), rowFormInput
+
+	case RowFormDoneMsg:
+		v.loading = false
+		v.noteTxError(msg.Err)
+		if msg.Err != nil {
+			applog.Event("SQL", "Row change failed: %s: %v", msg.SQL, msg.Err)
+			if f != nil && f.step == rowFormRunning && f.table == msg.Table {
+				f.err, f.step = msg.Err, rowFormReview
+			}
+			return nil
+		}
+		if f != nil && f.table == msg.Table {
+			v.rowForm = nil
+		}
+		text := "Done: " + msg.SQL
+		if msg.Result != nil {
+			switch msg.Result.Status {
+			case "INSERT 0 1":
+				text = "Inserted: " + msg.SQL
+			case "DELETE 1":
+				text = "Deleted: " + msg.SQL
+			case "DELETE 0":
+				text = "No row was deleted — it changed or was deleted since the page was read"
+			}
+		}
+		if v.pagTable != msg.Table {
+			return status(text)
+		}
+		return tea.Batch(status(text), v.fetchPage())
+	}
+	return nil
+}
+
+// renderRowForm renders the insert form or delete confirmation in place
+// of the results.
+func (v *MainView) renderRowForm() []string {
+	f := v.rowForm
+	title := "➕ New row in " + f.table
+	if f.delete {
+		title = "🗑  Delete row from " + f.table
+	}
+	lines := []string{StyleBold.Render(title), ""}
+	if f.step == rowFormLoading {
+		if f.delete {
+			return append(lines, StyleDimmed.Render("Finding the row's key…"))
+		}
+		return append(lines, StyleDimmed.Render("Loading columns…"))
+	}
+
+	if f.step == rowFormInput {
+		// Wide tables scroll: show the fields around the cursor that fit.
+		first, last := 0, len(f.fields)
+		if rows := v.viewport.height - 6; rows > 0 && last > rows {
+			first = min(max(f.cursor-rows/2, 0), last-rows)
+			last = first + rows
+		}
+		nameWidth := 0
+		for _, c := range f.columns {
+			nameWidth = max(nameWidth, len([]rune(c.Name)))
+		}
+		for i := first; i < last; i++ {
+			c, field := f.columns[i], f.fields[i]
+			colType := c.DataType
+			if !c.IsNullable {
+				colType += " not null"
+			}
+			var value string
+			switch {
+			case field.null:
+				value = StyleDimmed.Render("NULL")
+			case field.set && i == f.cursor:
+				value = field.value.View()
+			case field.set:
+				value = field.value.Value()
+			case c.Default != "":
+				value = StyleDimmed.Render("DEFAULT " + c.Default)
+			default:
+				value = StyleDimmed.Render("DEFAULT")
+			}
+			if i == f.cursor && (field.null || !field.set) {
+				value += "█"
+			}
+			line := fmt.Sprintf("%-*s %s  %s", nameWidth, c.Name, StyleDimmed.Render(fmt.Sprintf("%-20s", colType)), value)
+			lines = append(lines, pickLine(line, i == f.cursor))
+		}
+		return append(lines, "",
+			StyleDimmed.Render("↑/↓ field · Enter review · Ctrl+N NULL · Ctrl+U default · Esc cancel"))
+	}
+
+	if f.delete {
+		shown := min(len(f.gridColumns), len(f.row), max(v.viewport.height-10, 3))
+		for i, name := range f.gridColumns[:shown] {
+			lines = append(lines, StyleDimmed.Render(name+": ")+truncateRunes(f.row[i], max(v.width-len(name)-8, 20)))
+		}
+		if more := len(f.gridColumns) - shown; more > 0 {
+			lines = append(lines, StyleDimmed.Render(fmt.Sprintf("… %d more columns", more)))
+		}
+		lines = append(lines, "")
+	}
+	lines = append(lines, "  "+highlightSQL(f.sql+";"), "")
+	if f.key != nil {
+		if w := f.key.Warning(); w != "" {
+			for _, l := range wrapLines(w, max(v.width-4, 20)) {
+				lines = append(lines, StyleWarning.Render(l))
+			}
+			lines = append(lines, "")
+		}
+	}
+	if v.tx != nil {
+		lines = append(lines, StyleWarning.Render("Runs inside the open transaction; \\commit saves it"), "")
+	}
+	if f.err != nil {
+		lines = append(lines, StyleError.Render("Error: "+f.err.Error()), "")
+	}
+	if f.step == rowFormRunning {
+		lines = append(lines, StyleDimmed.Render("Running…"))
+	} else if f.delete {
+		lines = append(lines, StyleDimmed.Render("y delete · Esc cancel"))
+	} else {
+		lines = append(lines, StyleDimmed.Render("y run · Esc back"))
+	}
+	return lines
+}
@@ -0,0 +1,86 @@
+// schema_retrieval.go sends the tables most relevant to a chat question
+// as context (AIConfig.SchemaRetrieval). The schema index is embedded
+// with the provider's embeddings API, so in a large database the AI sees
+// the tables a question is about, not just the selected one.
+package tui
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/DachengChen/paiSQL/ai"
+	"github.com/DachengChen/paiSQL/db"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// schemaEmbedder returns the provider's embedder if schema retrieval is
+// enabled and the provider can embed.
+func (v *MainView) schemaEmbedder() ai.Embedder {
+	if v.db == nil || v.appConfig == nil || !v.appConfig.AI.SchemaRetrieval {
+		return nil
+	}
+	e, _ := v.aiProvider.(ai.Embedder)
+	return e
+}
+
+// retrieveSchema finds the tables relevant to question; the chat answer
+// follows when SchemaRetrievalMsg arrives.
+func (v *MainView) retrieveSchema(e ai.Embedder, question string) tea.Cmd {
+	database := v.db
+	cached := v.schemaIndex
+	k := v.appConfig.AI.RetrieveTables
+	v.chatReq++
+	id := v.chatReq
+	return func() tea.Msg {
+		ctx := context.Background()
+		idx := cached
+		var fresh *db.SchemaIndex
+		if idx == nil {
+			var err error
+			idx, err = database.FetchSchemaIndex(ctx, "")
+			if err != nil {
+				return SchemaRetrievalMsg{ID: id, Question: question, Err: fmt.Errorf("failed to index schema: %w", err)}
+			}
+			fresh = idx
+		}
+		tables, err := ai.RetrieveTables(ctx, e, idx.Docs(), question, k)
+		return SchemaRetrievalMsg{ID: id, Question: question, Tables: tables, Index: fresh, Err: err}
+	}
+}
+
+// updateSchemaRetrieval answers the question with the retrieved tables
+// as context. Retrieval errors are reported but don't stop the answer.
+func (v *MainView) updateSchemaRetrieval(msg SchemaRetrievalMsg) tea.Cmd {
+	if msg.Index != nil {
+		v.schemaIndex = msg.Index
+	}
+	if msg.ID != v.chatReq {
+		return nil // a newer question is pending
+	}
+	v.retrieved = msg.Tables
+	answer := v.answerChat(msg.Question)
+	if msg.Err != nil {
+		text := "Schema retrieval failed: " + msg.Err.Error()
+		return tea.Batch(answer, func() tea.Msg { return StatusMsg(text) })
+	}
+	if len(msg.Tables) > 0 {
+		text := "Context: " + strings.Join(msg.Tables, ", ")
+		return tea.Batch(answer, func() tea.Msg { return StatusMsg(text) })
+	}
+	return answer
+}
+
+// retrievedContext lists the retrieved tables other than exclude (the
+// selected table, already described) for the AI prompt, or returns "".
+func (v *MainView) retrievedContext(exclude string) string {
+	if v.schemaIndex == nil {
+		return ""
+	}
+	tables := slices.DeleteFunc(slices.Clone(v.retrieved), func(t string) bool { return t == exclude })
+	if len(tables) == 0 {
+		return ""
+	}
+	return "\n## Tables Relevant to the Question\n" + v.schemaIndex.FormatTables(tables)
+}
@@ -0,0 +1,119 @@
+// scratchpad.go autosaves the views' input buffers to disk every few
+// seconds and restores them on the next connection, so a long query
+// survives a crash, a dropped SSH session or an accidental Ctrl+C.
+package tui
+
+import (
+	"maps"
+	"time"
+
+	"github.com/DachengChen/paiSQL/applog"
+	"github.com/DachengChen/paiSQL/config"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const scratchAutosaveInterval = 3 * time.Second
+
+// scratchHolder is implemented by views with text buffers worth keeping.
+// Buffer names must be stable between runs.
+type scratchHolder interface {
+	scratchBuffers() map[string]string
+	restoreScratch(buffers map[string]string)
+}
+
+// scratchTickMsg triggers an autosave. gen ties it to the current
+// connection so ticks from a previous session stop the old loop.
+type scratchTickMsg struct{ gen int }
+
+// startScratchpad loads the connection's scratchpad into the views and
+// starts the autosave loop.
+func (a *App) startScratchpad() tea.Cmd {
+	a.scratchGen++
+	pad, err := config.LoadScratchpad(a.connName)
+	if err != nil {
+		applog.Error("Failed to load scratchpad: %v", err)
+		return nil
+	}
+	a.scratch = pad
+
+	if len(pad.Buffers) > 0 {
+		for _, v := range a.views {
+			if h, ok := v.(scratchHolder); ok {
+				h.restoreScratch(pad.Buffers)
+			}
+		}
+		a.statusMsg = "Restored unsent input from " + pad.Saved.Format("Jan 2 15:04")
+	}
+	return a.scratchTick()
+}
+
+func (a *App) scratchTick() tea.Cmd {
+	gen := a.scratchGen
+	return tea.Tick(scratchAutosaveInterval, func(time.Time) tea.Msg {
+		return scratchTickMsg{gen: gen}
+	})
+}
+
+// saveScratch writes the views' buffers if they changed since the last save.
+func (a *App) saveScratch() {
+	if a.scratch == nil {
+		return
+	}
+	buffers := map[string]string{}
+	for _, v := range a.views {
+		if h, ok := v.(scratchHolder); ok {
+			for name, text := range h.scratchBuffers() {
+				if text != "" {
+					buffers[name] = text
+				}
+			}
+		}
+	}
+	if maps.Equal(buffers, a.scratch.Buffers) {
+		return
+	}
+	a.scratch.Buffers = buffers
+	if err := a.scratch.Save(); err != nil {
+		applog.Error("Failed to save scratchpad: %v", err)
+	}
+}
+
+// stopScratchpad saves one last time and ends the autosave loop.
+func (a *App) stopScratchpad() {
+	a.saveScratch()
+	a.scratch = nil
+	a.scratchGen++
+}
+
+func (v *MainView) scratchBuffers() map[string]string {
+	return map[string]string{"sql": v.input.Value(), "chat": v.chatInput.Value()}
+}
+
+func (v *MainView) restoreScratch(buffers map[string]string) {
+	v.input.SetValue(buffers["sql"])
+	v.chatInput.SetValue(buffers["chat"])
+}
+
+func (v *ExplainView) scratchBuffers() map[string]string {
+	return map[string]string{"explain": v.input.Value()}
+}
+
+func (v *ExplainView) restoreScratch(buffers map[string]string) {
+	v.input.SetValue(buffers["explain"])
+}
+
+func (v *IndexView) scratchBuffers() map[string]string {
+	return map[string]string{"index": v.input.Value()}
+}
+
+func (v *IndexView) restoreScratch(buffers map[string]string) {
+	v.input.SetValue(buffers["index"])
+}
+
+func (v *AIView) scratchBuffers() map[string]string {
+	return map[string]string{"ai": v.input.Value()}
+}
+
+func (v *AIView) restoreScratch(buffers map[string]string) {
+	v.input.SetValue(buffers["ai"])
+}
@@ -0,0 +1,185 @@
+// sequences.go implements the sequence inspector (\sequences [schema]):
+// every sequence of the schema with the value it hands out next, the
+// column it feeds and how far that column has got. Sequences behind their
+// column's max (the next insert collides) or near the end of their range
+// are flagged and listed first. Enter on a sequence that is behind places
+// a setval statement in the input, to review and run with Enter.
+package tui
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/DachengChen/paiSQL/db"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// sequenceList is the sequence inspector shown over the results pane.
+type sequenceList struct {
+	schema  string // as given, "" for the default schema
+	loading bool
+	seqs    []db.SequenceInfo // flagged first
+	cursor  int
+	err     error
+}
+
+// openSequences implements \sequences.
+func (v *MainView) openSequences(args []string) tea.Cmd {
+	v.input.Reset()
+	schema := ""
+	if len(args) > 0 {
+		schema = args[0]
+	}
+	v.sequences = &sequenceList{schema: schema}
+	return v.fetchSequences()
+}
+
+// fetchSequences (re)reads the listed schema's sequences.
+func (v *MainView) fetchSequences() tea.Cmd {
+	s := v.sequences
+	s.loading, s.err = true, nil
+	schema, database := s.schema, v.db
+	return func() tea.Msg {
+		seqs, err := database.ListSequences(context.Background(), schema)
+		return SequencesMsg{Schema: schema, Sequences: seqs, Err: err}
+	}
+}
+
+// updateSequences handles the sequences arriving.
+func (v *MainView) updateSequences(msg SequencesMsg) {
+	s := v.sequences
+	if s == nil || !s.loading || s.schema != msg.Schema {
+		return
+	}
+	s.loading, s.err = false, msg.Err
+	s.seqs = msg.Sequences
+	slices.SortStableFunc(s.seqs, func(a, b db.SequenceInfo) int {
+		return sequenceRank(&a) - sequenceRank(&b)
+	})
+	s.cursor = min(s.cursor, max(len(s.seqs)-1, 0))
+}
+
+// sequenceRank orders the sequences that need attention first.
+func sequenceRank(s *db.SequenceInfo) int {
+	switch {
+	case s.Behind():
+		return 0
+	case s.NearOverflow():
+		return 1
+	}
+	return 2
+}
+
+func (v *MainView) handleSequencesKey(msg tea.KeyMsg) (View, tea.Cmd) {
+	s := v.sequences
+	switch msg.String() {
+	case "esc", "q":
+		v.sequences = nil
+	case "up", "k":
+		if s.cursor > 0 {
+			s.cursor--
+		}
+	case "down", "j":
+		if s.cursor < len(s.seqs)-1 {
+			s.cursor++
+		}
+	case "r":
+		if !s.loading {
+			return v, v.fetchSequences()
+		}
+	case "enter":
+		if s.loading || len(s.seqs) == 0 {
+			return v, nil
+		}
+		seq := s.seqs[s.cursor]
+		fix := seq.FixSQL()
+		if fix == "" {
+			return v, func() tea.Msg { return StatusMsg(seq.Name + " is ahead of its column; nothing to fix") }
+		}
+		v.sequences = nil
+		v.input.SetValue(fix)
+		v.viewport.SetContentLines([]string{
+			StyleBold.Render("Reset " + seq.Name), "",
+			fmt.Sprintf("%s.%s is at %d, but %s hands out %d next.", seq.Table, seq.Column, *seq.ColumnMax, seq.Name, seq.Next()),
+			"", fix, "",
+			StyleDimmed.Render("Placed in the input; Enter runs it, \\sequences checks again."),
+		})
+	}
+	return v, nil
+}
+
+// renderSequences renders the sequence inspector in place of the results.
+func (v *MainView) renderSequences() []string {
+	s := v.sequences
+	title := "🔢 Sequences"
+	if s.schema != "" {
+		title += " in " + s.schema
+	}
+	lines := []string{StyleBold.Render(title), ""}
+	switch {
+	case s.loading:
+		return append(lines, StyleDimmed.Render("Reading sequences and their columns…"))
+	case s.err != nil:
+		return append(lines, StyleError.Render("Error: "+s.err.Error()), "", StyleDimmed.Render("Esc close"))
+	case len(s.seqs) == 0:
+		return append(lines, StyleDimmed.Render("No sequences."), "", StyleDimmed.Render("Esc close"))
+	}
+
+	nameWidth, ownerWidth := len("sequence"), len("owned by")
+	for _, seq := range s.seqs {
+		nameWidth = max(nameWidth, len([]rune(seq.Name)))
+		ownerWidth = max(ownerWidth, len([]rune(sequenceOwner(&seq))))
+	}
+	lines = append(lines, StyleDimmed.Render(fmt.Sprintf("   %-*s %-*s %14s %14s %6s",
+		nameWidth, "sequence", ownerWidth, "owned by", "next", "column max", "used")))
+
+	first, last := 0, len(s.seqs)
+	if n := v.viewport.height - 7; n > 0 && last > n {
+		first = min(max(s.cursor-n/2, 0), last-n)
+		last = first + n
+	}
+	flagged := 0
+	for _, seq := range s.seqs {
+		if sequenceRank(&seq) < 2 {
+			flagged++
+		}
+	}
+	for i := first; i < last; i++ {
+		seq := &s.seqs[i]
+		columnMax := ""
+		switch {
+		case seq.Err != nil:
+			columnMax = "?"
+		case seq.ColumnMax != nil:
+			columnMax = strconv.FormatInt(*seq.ColumnMax, 10)
+		}
+		line := fmt.Sprintf("%-*s %-*s %14d %14s %5.1f%%",
+			nameWidth, seq.Name, ownerWidth, sequenceOwner(seq), seq.Next(), columnMax, seq.Used()*100)
+		switch {
+		case seq.Behind():
+			line += "  " + StyleError.Render("⚠ behind the column: the next insert collides")
+		case seq.NearOverflow():
+			line += "  " + StyleWarning.Render(fmt.Sprintf("⚠ near overflow (limit %d)", seq.Limit()))
+		case seq.Err != nil:
+			line += "  " + StyleDimmed.Render(seq.Err.Error())
+		}
+		lines = append(lines, pickLine(line, i == s.cursor))
+	}
+
+	summary := StyleSuccess.Render(fmt.Sprintf("✓ %d sequences, none behind or near overflow", len(s.seqs)))
+	if flagged > 0 {
+		summary = StyleWarning.Render(fmt.Sprintf("%d of %d sequences need attention", flagged, len(s.seqs)))
+	}
+	return append(lines, "", summary,
+		StyleDimmed.Render("Enter on a sequence behind its column: review a setval fix · r re-check · Esc close"))
+}
+
+// sequenceOwner renders the column owning seq, e.g. "orders.id (integer)".
+func sequenceOwner(seq *db.SequenceInfo) string {
+	if seq.Table == "" {
+		return "—"
+	}
+	return seq.Table + "." + seq.Column + " (" + seq.ColumnType + ")"
+}
@@ -0,0 +1,80 @@
+// sql_highlight.go colors SQL for display — keywords, string literals,
+// numbers and comments — using the db package's lexer, in the chat's sql
+// code fences and wherever a dialog shows the statement it will run.
+package tui
+
+import (
+	"strings"
+
+	"github.com/DachengChen/paiSQL/db"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// sqlFenceLangs are the code fence languages highlighted as SQL.
+var sqlFenceLangs = map[string]bool{
+	"sql": true, "postgresql": true, "postgres": true, "psql": true, "pgsql": true, "plpgsql": true,
+}
+
+// sqlClassStyles are the styles of the highlighted classes of SQL.
+var sqlClassStyles = map[db.SQLClass]lipgloss.Style{
+	db.SQLKeyword: StyleSQLKeyword,
+	db.SQLString:  StyleSQLString,
+	db.SQLNumber:  StyleSQLNumber,
+	db.SQLComment: StyleSQLComment,
+}
+
+// highlightSQL returns sql with its tokens styled. Line breaks are kept,
+// and each line is styled on its own so the result can be split into
+// lines.
+func highlightSQL(sql string) string {
+	var b strings.Builder
+	for _, span := range db.LexSQL(sql) {
+		style, ok := sqlClassStyles[span.Class]
+		if !ok {
+			b.WriteString(span.Text)
+			continue
+		}
+		for i, part := range strings.Split(span.Text, "\n") {
+			if i > 0 {
+				b.WriteByte('\n')
+			}
+			if part != "" {
+				b.WriteString(style.Render(part))
+			}
+		}
+	}
+	return b.String()
+}
+
+// highlightCodeFences splits chat text into lines, highlighting the SQL
+// of ```sql fences. A fence still open at the end, as in a reply being
+// streamed, is highlighted up to there.
+func highlightCodeFences(text string) []string {
+	var lines, block []string
+	inFence, isSQL := false, false
+	flush := func() {
+		if isSQL && len(block) > 0 {
+			block = strings.Split(highlightSQL(strings.Join(block, "\n")), "\n")
+		}
+		lines = append(lines, block...)
+		block = nil
+	}
+	for _, line := range strings.Split(text, "\n") {
+		fence, ok := strings.CutPrefix(strings.TrimSpace(line), "```")
+		switch {
+		case ok && !inFence:
+			inFence, isSQL = true, sqlFenceLangs[strings.ToLower(strings.TrimSpace(fence))]
+			lines = append(lines, StyleDimmed.Render(line))
+		case ok && inFence:
+			flush()
+			inFence = false
+			lines = append(lines, StyleDimmed.Render(line))
+		case inFence:
+			block = append(block, line)
+		default:
+			lines = append(lines, line)
+		}
+	}
+	flush()
+	return lines
+}
@@ -0,0 +1,192 @@
+// stream_export.go implements \export, which writes the whole result of
+// the current query or table, not just the page on screen, to a CSV file:
+//
+//	\export <file> [split <size>]  start the export (.csv is added if there is no extension)
+//	\export                        show the progress of the running export
+//	\export cancel                 stop it and remove the partial files
+//
+// Rows are streamed from COPY ... TO STDOUT straight to the file, so
+// tables of any size export without being loaded into the app. Progress
+// is shown on the status bar in bytes and rows. A .gz or .zst file is
+// compressed, and split writes numbered chunks (see export_files.go).
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/DachengChen/paiSQL/db"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const exportProgressInterval = time.Second
+
+// exportRun is the running \export.
+type exportRun struct {
+	gen      int
+	path     string
+	progress *db.ExportProgress
+	cancel   context.CancelFunc
+	started  time.Time
+}
+
+// exportTickMsg triggers a progress report of export gen.
+type exportTickMsg struct{ gen int }
+
+// exportSQL is the statement behind the current result without its
+// paging: the whole table, with its chosen columns, when browsing one, every row of an AI query
+// plan, or the last statement run.
+func (v *MainView) exportSQL() (string, error) {
+	switch {
+	case v.pagTable != "":
+		return v.styleSQL(v.browseSQL(v.pagTable)), nil
+	case v.pagPlan && v.lastQueryPlan != nil:
+		plan := *v.lastQueryPlan
+		plan.Limit, plan.Page = 0, 1
+		sql, err := plan.ToSQL()
+		if err != nil {
+			return "", err
+		}
+		return v.styleSQL(sql), nil
+	case v.lastSQL != "":
+		return v.lastSQL, nil
+	}
+	return "", fmt.Errorf("run a query that returns rows first")
+}
+
+// streamExport implements \export.
+func (v *MainView) streamExport(args []string) tea.Cmd {
+	v.input.Reset()
+	status := func(text string) tea.Cmd { return func() tea.Msg { return StatusMsg(text) } }
+	if len(args) == 0 {
+		if v.exportRun == nil {
+			v.viewport.SetContent(StyleError.Render("Usage: \\export <file.csv[.gz|.zst]> [split <size>] | \\export cancel"))
+			return nil
+		}
+		return status(v.exportRun.status())
+	}
+	if len(args) == 1 && args[0] == "cancel" {
+		if v.exportRun == nil {
+			return status("No export is running")
+		}
+		v.exportRun.cancel()
+		return nil
+	}
+	if v.exportRun != nil {
+		return status("An export to " + v.exportRun.path + " is running; \\export cancel stops it")
+	}
+
+	var chunkSize int64
+	if n := len(args); n >= 3 && strings.EqualFold(args[n-2], "split") {
+		size, err := parseChunkSize(args[n-1])
+		if err != nil {
+			return status("Export failed: " + err.Error())
+		}
+		chunkSize, args = size, args[:n-2]
+	}
+	path := strings.Join(args, " ")
+	csvPath := path // without the .gz or .zst
+	if exportCompression(path) != "" {
+		csvPath = strings.TrimSuffix(path, filepath.Ext(path))
+	}
+	switch strings.ToLower(filepath.Ext(csvPath)) {
+	case "":
+		if csvPath == path {
+			path += ".csv"
+		}
+	case ".csv":
+	default:
+		return status("\\export writes CSV; use \\xlsx for an Excel workbook of the result")
+	}
+	sql, err := v.exportSQL()
+	if err != nil {
+		return status("Export failed: " + err.Error())
+	}
+
+	v.exportGen++
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &exportRun{
+		gen:      v.exportGen,
+		path:     path,
+		progress: &db.ExportProgress{},
+		cancel:   cancel,
+		started:  time.Now(),
+	}
+	v.exportRun = r
+
+	database := v.db
+	return tea.Batch(func() tea.Msg {
+		files := newExportFiles(path, chunkSize)
+		rows, err := writeExport(ctx, database, files, sql, r.progress)
+		done := ExportDoneMsg{Gen: r.gen, Path: path, Rows: rows, Bytes: files.written(),
+			Files: len(files.chunks), Elapsed: time.Since(r.started), Err: err}
+		if chunkSize > 0 {
+			done.Manifest = files.manifestPath()
+		}
+		return done
+	}, status("Exporting to "+path+"..."), exportTick(r.gen))
+}
+
+// writeExport streams the rows of sql to files. Files written by an
+// export that fails part way are removed.
+func writeExport(ctx context.Context, database *db.DB, files *exportFiles, sql string, p *db.ExportProgress) (int64, error) {
+	rows, err := database.ExportCSV(ctx, files, sql, p)
+	if err == nil {
+		err = files.finish(sql)
+	}
+	if err != nil {
+		files.remove()
+	}
+	return rows, err
+}
+
+func exportTick(gen int) tea.Cmd {
+	return tea.Tick(exportProgressInterval, func(time.Time) tea.Msg {
+		return exportTickMsg{gen: gen}
+	})
+}
+
+// status describes the progress of the export.
+func (r *exportRun) status() string {
+	return fmt.Sprintf("Exporting to %s: %s, %d rows (%s) · \\export cancel stops it",
+		r.path, formatByteSize(int(r.progress.Bytes())), r.progress.Rows(), time.Since(r.started).Round(time.Second))
+}
+
+// updateExport handles the progress ticks and the end of the export.
+func (v *MainView) updateExport(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case exportTickMsg:
+		if v.exportRun == nil || v.exportRun.gen != msg.gen {
+			return nil
+		}
+		text := v.exportRun.status()
+		return tea.Batch(func() tea.Msg { return StatusMsg(text) }, exportTick(msg.gen))
+
+	case ExportDoneMsg:
+		if v.exportRun == nil || v.exportRun.gen != msg.Gen {
+			return nil
+		}
+		v.exportRun.cancel()
+		v.exportRun = nil
+
+		var text string
+		switch {
+		case errors.Is(msg.Err, context.Canceled):
+			text = "Export to " + msg.Path + " cancelled"
+		case msg.Err != nil:
+			text = "Export failed: " + msg.Err.Error()
+		case msg.Manifest != "":
+			text = fmt.Sprintf("Exported %d rows (%s) to %d files listed in %s in %s", msg.Rows,
+				formatByteSize(int(msg.Bytes)), msg.Files, msg.Manifest, msg.Elapsed.Round(time.Millisecond))
+		default:
+			text = fmt.Sprintf("Exported %d rows (%s) to %s in %s", msg.Rows,
+				formatByteSize(int(msg.Bytes)), msg.Path, msg.Elapsed.Round(time.Millisecond))
+		}
+		return func() tea.Msg { return StatusMsg(text) }
+	}
+	return nil
+}
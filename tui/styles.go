@@ -23,6 +23,35 @@ var (
 	ColorFgDim = ColorDim
 )
 
+// connectionColors are the named frame colors of a connection (see
+// config.Connection.Color), in the order the connection form cycles them.
+var connectionColors = []struct {
+	name  string
+	color lipgloss.Color
+}{
+	{"red", "196"},
+	{"orange", "214"},
+	{"yellow", "226"},
+	{"green", "42"},
+	{"blue", "39"},
+	{"purple", "135"},
+}
+
+// connectionColor resolves a connection's color: a name from
+// connectionColors, else an ANSI number or hex color as is. ok is false
+// for no color.
+func connectionColor(name string) (color lipgloss.Color, ok bool) {
+	if name == "" {
+		return "", false
+	}
+	for _, c := range connectionColors {
+		if c.name == name {
+			return c.color, true
+		}
+	}
+	return lipgloss.Color(name), true
+}
+
 // Shared styles - minimal and clean
 var (
 	// Standard Text
@@ -74,4 +103,26 @@ var (
 
 	StyleHelpDesc = lipgloss.NewStyle().
 			Foreground(ColorDim)
+
+	// SQL syntax highlighting (sql_highlight.go)
+	StyleSQLKeyword = lipgloss.NewStyle().Foreground(ColorAccent).Bold(true)
+	StyleSQLString  = lipgloss.NewStyle().Foreground(ColorSuccess)
+	StyleSQLNumber  = lipgloss.NewStyle().Foreground(ColorWarning)
+	StyleSQLComment = lipgloss.NewStyle().Foreground(ColorDim).Italic(true)
+
+	// Text inputs (textinput.go): the character under the cursor and
+	// selected text
+	StyleTextCursor    = lipgloss.NewStyle().Reverse(true)
+	StyleTextSelection = lipgloss.NewStyle().
+				Foreground(ColorPrimary).
+				Background(ColorSecondary)
+
+	// Search matches in a viewport, and the one n/N moved to
+	StyleSearchMatch = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("0")).
+				Background(ColorWarning)
+	StyleSearchCurrent = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("0")).
+				Background(ColorAccent).
+				Bold(true)
 )
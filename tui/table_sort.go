@@ -0,0 +1,71 @@
+// table_sort.go implements \sort, the sort order of a browsed table:
+//
+//	\sort created_at desc     order by created_at, newest first
+//	\sort status, id desc     several columns
+//	\sort                     show the sort order
+//	\sort off                 back to physical order
+//
+// The sort order is saved per table with the chosen columns (see
+// config.TablePrefs), so the table opens with its most relevant rows
+// first the next time it is browsed.
+package tui
+
+import (
+	"strings"
+
+	"github.com/DachengChen/paiSQL/applog"
+	"github.com/DachengChen/paiSQL/db"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// browseOrder is the ORDER BY clause of table's saved sort order, with a
+// leading space, or "" for none. A sort order edited by hand into
+// something invalid is logged and ignored.
+func (v *MainView) browseOrder(table string) string {
+	sort := v.tablePref(table).Sort
+	if sort == "" {
+		return ""
+	}
+	list, err := db.OrderByList(sort)
+	if err != nil {
+		applog.Error("Ignoring the sort order of %s: %v", table, err)
+		return ""
+	}
+	return " ORDER BY " + list
+}
+
+// sortTable implements \sort for the browsed table.
+func (v *MainView) sortTable(spec string) tea.Cmd {
+	v.input.Reset()
+	status := func(text string) tea.Cmd { return func() tea.Msg { return StatusMsg(text) } }
+	table := v.pagTable
+	if table == "" {
+		return status("\\sort: browse a table first (Enter in the table list)")
+	}
+	pref := v.tablePref(table)
+	switch {
+	case spec == "":
+		if pref.Sort == "" {
+			return status(table + " is not sorted; \\sort <column> [asc|desc] sets an order")
+		}
+		return status(table + " is sorted by " + pref.Sort + "; \\sort off clears it")
+	case strings.EqualFold(spec, "off"):
+		pref.Sort = ""
+	default:
+		if _, err := db.OrderByList(spec); err != nil {
+			return status("\\sort: " + err.Error())
+		}
+		pref.Sort = spec
+	}
+
+	if v.tablePrefs == nil {
+		return status("\\sort: table preferences could not be loaded (see the app log)")
+	}
+	var saved tea.Cmd
+	if err := v.tablePrefs.Set(table, pref); err != nil {
+		applog.Error("Failed to save table preferences: %v", err)
+		saved = status("Sort order not saved: " + err.Error())
+	}
+	v.pagPage = 0
+	return tea.Batch(saved, v.fetchPage())
+}
@@ -0,0 +1,89 @@
+// tasks.go ties async work to the view that started it.
+//
+// Commands returned by a view run in the background and their messages
+// used to be delivered to whichever view was active when they arrived.
+// The App now wraps them in a routedMsg naming the originating view, and
+// views hold a viewTasks so work can be cancelled when the user leaves.
+package tui
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// viewTasks owns the context of a view's in-flight async operations.
+type viewTasks struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// current returns the context for a new operation, shared with any
+// others still running.
+func (t *viewTasks) current() context.Context {
+	if t.ctx == nil {
+		t.ctx, t.cancel = context.WithCancel(context.Background())
+	}
+	return t.ctx
+}
+
+// restart cancels running operations and returns a fresh context, for
+// views that only care about the result of their latest request.
+func (t *viewTasks) restart() context.Context {
+	t.stop()
+	return t.current()
+}
+
+// stop cancels all running operations.
+func (t *viewTasks) stop() {
+	if t.cancel != nil {
+		t.cancel()
+		t.ctx, t.cancel = nil, nil
+	}
+}
+
+// routedMsg carries a message produced by a view's command back to that
+// view, even if the user has switched tabs since. took is how long the
+// command ran, for the notification hooks (hooks.go).
+type routedMsg struct {
+	view View
+	msg  tea.Msg
+	took time.Duration
+}
+
+// tuiPkgPath identifies message types declared in this package; only
+// those are routed, so Bubble Tea's own messages reach the runtime.
+var tuiPkgPath = reflect.TypeOf(routedMsg{}).PkgPath()
+
+// routeTo wraps cmd so the message it produces is delivered to view.
+// StatusMsg is routed too, only so the hooks see how long it took; the
+// App still shows it in the status bar whatever the view.
+func routeTo(view View, cmd tea.Cmd) tea.Cmd {
+	if cmd == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		start := time.Now()
+		msg := cmd()
+		switch m := msg.(type) {
+		case nil:
+			return msg
+		case tea.BatchMsg:
+			batch := make(tea.BatchMsg, len(m))
+			for i, c := range m {
+				batch[i] = routeTo(view, c)
+			}
+			return batch
+		}
+		t := reflect.TypeOf(msg)
+		if t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		if t.PkgPath() != tuiPkgPath {
+			return msg
+		}
+		return routedMsg{view: view, msg: msg, took: time.Since(start)}
+	}
+}
@@ -0,0 +1,286 @@
+// textinput.go is the text field the views type into: the SQL and chat
+// inputs of the main view, the prompts of the AI, Explain, Index and
+// Listen tabs, and the connection form's fields.
+//
+// Text is edited as runes, so multibyte characters are never split, with
+// a cursor that moves by character (←/→), word (Alt+←/→, Ctrl+←/→,
+// Alt+B/F) and line (Home/End, Ctrl+A/E). Shift with a movement selects;
+// typing or deleting replaces the selection. Ctrl+W and Alt+Backspace
+// delete the word before the cursor, Alt+D the word after, Ctrl+U and
+// Ctrl+K to the start and end of the line. Views handle their own keys
+// first, so a view binding one of these keeps it.
+package tui
+
+import (
+	"strings"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// textInput is an editable line, or lines, of text. The zero value is an
+// empty input.
+type textInput struct {
+	text      []rune
+	cursor    int // position in text, 0..len(text)
+	anchor    int // other end of the selection
+	selecting bool
+}
+
+// Value returns the text.
+func (t *textInput) Value() string {
+	return string(t.text)
+}
+
+// SetValue replaces the text, putting the cursor at its end.
+func (t *textInput) SetValue(s string) {
+	t.text = []rune(s)
+	t.cursor, t.selecting = len(t.text), false
+}
+
+// Reset empties the input.
+func (t *textInput) Reset() {
+	t.SetValue("")
+}
+
+// BeforeCursor returns the text before the cursor.
+func (t *textInput) BeforeCursor() string {
+	return string(t.text[:t.cursor])
+}
+
+// ReplaceBeforeCursor replaces the text from byte offset start of
+// BeforeCursor up to the cursor with s, leaving the cursor after it.
+func (t *textInput) ReplaceBeforeCursor(start int, s string) {
+	before := t.BeforeCursor()
+	n := len([]rune(before[min(max(start, 0), len(before)):]))
+	t.selecting = false
+	t.remove(t.cursor-n, t.cursor)
+	t.Insert(s)
+}
+
+// Insert types s at the cursor, replacing the selection.
+func (t *textInput) Insert(s string) {
+	t.deleteSelection()
+	r := []rune(s)
+	t.text = append(t.text[:t.cursor], append(r, t.text[t.cursor:]...)...)
+	t.cursor += len(r)
+}
+
+// Update applies an editing key and reports whether it was one. Keys that
+// don't edit text, like Enter, ↑/↓ or Esc, are left to the view.
+func (t *textInput) Update(msg tea.KeyMsg) bool {
+	switch msg.String() {
+	case "left":
+		t.move(t.cursor-1, false)
+	case "right":
+		t.move(t.cursor+1, false)
+	case "shift+left":
+		t.move(t.cursor-1, true)
+	case "shift+right":
+		t.move(t.cursor+1, true)
+	case "alt+left", "ctrl+left", "alt+b":
+		t.move(t.wordStart(), false)
+	case "alt+right", "ctrl+right", "alt+f":
+		t.move(t.wordEnd(), false)
+	case "ctrl+shift+left", "alt+shift+left":
+		t.move(t.wordStart(), true)
+	case "ctrl+shift+right", "alt+shift+right":
+		t.move(t.wordEnd(), true)
+	case "home", "ctrl+a":
+		t.move(t.lineStart(), false)
+	case "end", "ctrl+e":
+		t.move(t.lineEnd(), false)
+	case "shift+home":
+		t.move(t.lineStart(), true)
+	case "shift+end":
+		t.move(t.lineEnd(), true)
+
+	case "backspace":
+		if !t.deleteSelection() {
+			t.remove(t.cursor-1, t.cursor)
+		}
+	case "delete", "ctrl+d":
+		if !t.deleteSelection() {
+			t.remove(t.cursor, t.cursor+1)
+		}
+	case "ctrl+w", "alt+backspace":
+		if !t.deleteSelection() {
+			t.remove(t.wordStart(), t.cursor)
+		}
+	case "alt+d", "alt+delete":
+		if !t.deleteSelection() {
+			t.remove(t.cursor, t.wordEnd())
+		}
+	case "ctrl+u":
+		t.deleteSelection()
+		t.remove(t.lineStart(), t.cursor)
+	case "ctrl+k":
+		t.deleteSelection()
+		t.remove(t.cursor, t.lineEnd())
+
+	default:
+		// KeyRunes covers typing and bracketed paste.
+		switch msg.Type {
+		case tea.KeyRunes:
+			t.Insert(string(msg.Runes))
+		case tea.KeySpace:
+			t.Insert(" ")
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// move puts the cursor at pos, extending the selection when selecting and
+// dropping it otherwise.
+func (t *textInput) move(pos int, selecting bool) {
+	if selecting && !t.selecting {
+		t.anchor = t.cursor
+	}
+	t.selecting = selecting
+	t.cursor = min(max(pos, 0), len(t.text))
+}
+
+// selection returns the selected range, empty when nothing is selected.
+func (t *textInput) selection() (from, to int) {
+	if !t.selecting {
+		return t.cursor, t.cursor
+	}
+	return min(t.anchor, t.cursor), max(t.anchor, t.cursor)
+}
+
+// deleteSelection removes the selected text and reports whether there
+// was any.
+func (t *textInput) deleteSelection() bool {
+	from, to := t.selection()
+	t.selecting = false
+	if from == to {
+		return false
+	}
+	t.cursor = to
+	t.remove(from, to)
+	return true
+}
+
+// remove deletes text[from:to], clamped to the text, moving the cursor
+// with it.
+func (t *textInput) remove(from, to int) {
+	from, to = max(from, 0), min(to, len(t.text))
+	if from >= to {
+		return
+	}
+	t.text = append(t.text[:from], t.text[to:]...)
+	switch {
+	case t.cursor >= to:
+		t.cursor -= to - from
+	case t.cursor > from:
+		t.cursor = from
+	}
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// wordStart returns the start of the word before the cursor.
+func (t *textInput) wordStart() int {
+	i := t.cursor
+	for i > 0 && !isWordRune(t.text[i-1]) {
+		i--
+	}
+	for i > 0 && isWordRune(t.text[i-1]) {
+		i--
+	}
+	return i
+}
+
+// wordEnd returns the end of the word after the cursor.
+func (t *textInput) wordEnd() int {
+	i := t.cursor
+	for i < len(t.text) && !isWordRune(t.text[i]) {
+		i++
+	}
+	for i < len(t.text) && isWordRune(t.text[i]) {
+		i++
+	}
+	return i
+}
+
+// lineStart and lineEnd return the ends of the cursor's line.
+func (t *textInput) lineStart() int {
+	i := t.cursor
+	for i > 0 && t.text[i-1] != '\n' {
+		i--
+	}
+	return i
+}
+
+func (t *textInput) lineEnd() int {
+	i := t.cursor
+	for i < len(t.text) && t.text[i] != '\n' {
+		i++
+	}
+	return i
+}
+
+// View renders the text with the cursor, a block at the end of the text
+// or of a line, and the selection. Lines are styled separately so the
+// result can be split at newlines.
+func (t *textInput) View() string {
+	from, to := t.selection()
+	var b strings.Builder
+	region := func(i, j int, selected bool) {
+		if i >= j {
+			return
+		}
+		if selected {
+			b.WriteString(styleLines(StyleTextSelection, string(t.text[i:j])))
+		} else {
+			b.WriteString(string(t.text[i:j]))
+		}
+	}
+	// cursor draws the cursor and returns the runes it covers.
+	cursor := func() int {
+		if t.cursor == len(t.text) || t.text[t.cursor] == '\n' {
+			b.WriteString("█")
+			return 0
+		}
+		b.WriteString(StyleTextCursor.Render(string(t.text[t.cursor])))
+		return 1
+	}
+
+	// The cursor is at one end of the selection.
+	region(0, from, false)
+	i := from
+	if t.cursor == from {
+		i += cursor()
+	}
+	region(i, to, true)
+	i = max(i, to)
+	if t.cursor == to && to != from {
+		i += cursor()
+	}
+	region(i, len(t.text), false)
+	return b.String()
+}
+
+// MaskedView renders the input like View with every character shown as
+// a bullet, for passwords and API keys.
+func (t *textInput) MaskedView() string {
+	masked := *t
+	masked.text = []rune(strings.Repeat("•", len(t.text)))
+	return masked.View()
+}
+
+// styleLines renders each line of s with style.
+func styleLines(style lipgloss.Style, s string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = style.Render(line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
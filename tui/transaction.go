@@ -0,0 +1,154 @@
+// transaction.go is the SQL view's transaction mode: \begin (or a typed
+// BEGIN) opens a pgx.Tx on a connection taken from the pool, statements
+// and browsed pages then run inside it until \commit or \rollback, and
+// the header shows TX OPEN meanwhile so a transaction isn't left idle.
+// Without it, BEGIN and COMMIT would each run on whichever pooled
+// connection was free.
+package tui
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/DachengChen/paiSQL/applog"
+	"github.com/DachengChen/paiSQL/db"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// txEndTimeout bounds the rollback of a transaction left open on exit.
+const txEndTimeout = 5 * time.Second
+
+// queryRunner returns how a statement of the results pane runs: inside
+// the open transaction, or on the pool.
+func (v *MainView) queryRunner() func(ctx context.Context, sql string) (*db.QueryResult, error) {
+	database, tx := v.db, v.tx
+	if tx == nil {
+		return database.Execute
+	}
+	return func(ctx context.Context, sql string) (*db.QueryResult, error) {
+		return database.ExecuteTx(ctx, tx, sql)
+	}
+}
+
+// rowQuerier returns how the single-row lookups that go with a statement,
+// such as a browsed page's count, run: inside the open transaction, which
+// sees its own changes and isn't blocked by its own locks, or on the pool.
+func (v *MainView) rowQuerier() func(ctx context.Context, sql string, args ...any) pgx.Row {
+	if v.tx != nil {
+		return v.tx.QueryRow
+	}
+	return v.db.Pool.QueryRow
+}
+
+// beginTx implements \begin.
+func (v *MainView) beginTx() tea.Cmd {
+	v.input.Reset()
+	if v.tx != nil {
+		return func() tea.Msg { return StatusMsg("A transaction is already open (\\commit or \\rollback ends it)") }
+	}
+	v.loading = true
+	id := v.newResultRequest()
+	ctx := v.queryContext()
+	database := v.db
+	return func() tea.Msg {
+		tx, err := database.Begin(ctx)
+		return TxBeganMsg{ID: id, Tx: tx, Err: err}
+	}
+}
+
+// endTx implements \commit and \rollback. The transaction is over either
+// way; a failed COMMIT rolls it back.
+func (v *MainView) endTx(commit bool) tea.Cmd {
+	v.input.Reset()
+	if v.tx == nil {
+		return func() tea.Msg { return StatusMsg("No transaction is open") }
+	}
+	tx := v.tx
+	v.loading = true
+	id := v.newResultRequest()
+	return func() tea.Msg {
+		var err error
+		if commit {
+			err = tx.Commit(context.Background())
+		} else {
+			err = tx.Rollback(context.Background())
+		}
+		return TxEndedMsg{ID: id, Commit: commit, Err: err}
+	}
+}
+
+// abandonTx rolls back a transaction still open when the connection is
+// closed; pgxpool.Close waits for its connection otherwise.
+func (v *MainView) abandonTx() {
+	if v.tx == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), txEndTimeout)
+	defer cancel()
+	if err := v.tx.Rollback(ctx); err != nil {
+		applog.Error("Failed to roll back the open transaction: %v", err)
+	}
+	applog.Event("SQL", "Rolled back the transaction left open")
+	v.tx, v.txAborted = nil, false
+}
+
+// noteTxError marks the transaction failed after a statement in it got
+// an error from the server; until ROLLBACK every statement fails.
+func (v *MainView) noteTxError(err error) {
+	var pgErr *pgconn.PgError
+	if v.tx != nil && errors.As(err, &pgErr) {
+		v.txAborted = true
+	}
+}
+
+// updateTx handles the messages of \begin, \commit and \rollback.
+func (v *MainView) updateTx(msg tea.Msg) {
+	var lines []string
+	var id int
+	switch msg := msg.(type) {
+	case TxBeganMsg:
+		id = msg.ID
+		if msg.Err != nil {
+			lines = []string{StyleError.Render("ERROR: " + msg.Err.Error())}
+			break
+		}
+		// Kept even when superseded: the transaction holds a connection.
+		v.tx, v.txAborted, v.txStart = msg.Tx, false, time.Now()
+		applog.Event("SQL", "Transaction opened")
+		lines = []string{
+			StyleWarning.Render("BEGIN — transaction open"),
+			"",
+			"  Statements and browsed pages run inside it until \\commit (or COMMIT;) saves",
+			"  them or \\rollback (or ROLLBACK;) undoes them.",
+		}
+	case TxEndedMsg:
+		id = msg.ID
+		v.tx, v.txAborted = nil, false
+		applog.Event("SQL", "Transaction ended (commit %v): %v", msg.Commit, msg.Err)
+		switch {
+		case errors.Is(msg.Err, pgx.ErrTxCommitRollback):
+			lines = []string{StyleError.Render("ROLLBACK — the transaction had failed; nothing was committed")}
+		case msg.Err != nil:
+			lines = []string{StyleError.Render("ERROR: " + msg.Err.Error())}
+		case msg.Commit:
+			lines = []string{StyleSuccess.Render("COMMIT — changes saved")}
+		default:
+			lines = []string{StyleWarning.Render("ROLLBACK — changes undone")}
+		}
+	}
+	if id != v.resultReq {
+		return
+	}
+	v.loading = false
+	v.result, v.err = nil, nil
+	v.viewport.SetContentLines(lines)
+}
+
+// TxStatus reports the open transaction for the header: since when, and
+// whether a statement in it failed.
+func (v *MainView) TxStatus() (open bool, since time.Time, aborted bool) {
+	return v.tx != nil, v.txStart, v.txAborted
+}
@@ -1,6 +1,7 @@
 package tui
 
 import (
+	"errors"
 	"fmt"
 	"log"
 
@@ -10,8 +11,18 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 )
 
+// Options are the command line options of the TUI.
+type Options struct {
+	Connect string // saved connection to open, skipping the connection screen
+	View    string // view to show once connected, e.g. "stats"
+	Table   string // table to browse once connected
+	Monitor bool   // only the monitoring views (paisql top)
+}
+
 // Start initializes the connection store and launches the TUI.
-func Start() error {
+// If opts.Connect is set — or autoconnect_last is enabled in the config —
+// the connection screen is skipped and that saved connection is opened.
+func Start(opts Options) error {
 	applog.Event("APP", "paiSQL starting")
 
 	store, err := config.NewConnectionStore()
@@ -20,6 +31,12 @@ func Start() error {
 		return fmt.Errorf("failed to load connections: %w", err)
 	}
 	applog.Event("CONFIG", "Loaded %d saved connections", len(store.Connections))
+	if store.Workspace != "" {
+		applog.Event("CONFIG", "Workspace %s", store.Workspace)
+	}
+	for _, name := range store.StrippedSecrets {
+		applog.Error("Ignored the passwords, SSH key or insecure_host_key of shared connection %s: only ~/.paisql may set them", name)
+	}
 
 	appCfg, err := config.LoadAppConfig()
 	if err != nil {
@@ -38,9 +55,31 @@ func Start() error {
 	}
 
 	app := NewApp(store, provider, appCfg)
-	p := tea.NewProgram(app, tea.WithAltScreen())
+	app.startView, app.startTable, app.monitor = opts.View, opts.Table, opts.Monitor
+	if opts.Connect != "" {
+		if _, ok := store.Get(opts.Connect); !ok {
+			return fmt.Errorf("no saved connection named %q", opts.Connect)
+		}
+		app.autoconnect = opts.Connect
+	} else if appCfg.AutoconnectLast {
+		if recent, ok := store.MostRecent(); ok {
+			app.autoconnect = recent.Name
+		}
+	}
+	if app.autoconnect != "" {
+		applog.Event("CONNECT", "Autoconnecting to saved connection '%s'", app.autoconnect)
+	}
+	p := tea.NewProgram(app, tea.WithAltScreen(), tea.WithFilter(app.filterSignals))
 
 	_, err = p.Run()
+	if errors.Is(err, tea.ErrInterrupted) {
+		applog.Event("APP", "Interrupted")
+		err = nil
+	}
+	if app.db != nil {
+		app.abandonTx()
+		app.db.Close()
+	}
 	applog.Event("APP", "paiSQL stopped")
 	applog.Close()
 	return err
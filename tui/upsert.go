@@ -0,0 +1,216 @@
+// upsert.go implements \upsert, which copies the current result into a
+// table of another saved connection:
+//
+//	\upsert <connection> <table> [column,...]   build and show the script
+//	\upsert apply                               run it on the connection
+//	\upsert save <file>                         write it to a file
+//
+// The columns name the ON CONFLICT target; without them the target
+// table's primary key is used.
+package tui
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/DachengChen/paiSQL/applog"
+	"github.com/DachengChen/paiSQL/config"
+	"github.com/DachengChen/paiSQL/db"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// upsertPreviewStatements is the number of statements shown before \upsert apply.
+const upsertPreviewStatements = 3
+
+// pendingUpsert is the script of the last \upsert, waiting for apply.
+type pendingUpsert struct {
+	conn config.Connection
+	plan *db.UpsertPlan
+}
+
+// upsert runs \upsert.
+func (v *MainView) upsert(args []string) tea.Cmd {
+	v.input.Reset()
+	usage := "Usage: \\upsert <connection> <table> [column,...]  |  \\upsert apply  |  \\upsert save <file>"
+	if len(args) == 0 {
+		v.viewport.SetContent(StyleError.Render(usage))
+		return nil
+	}
+
+	switch args[0] {
+	case "apply", "save":
+		if v.upsertScript == nil {
+			v.viewport.SetContent(StyleError.Render("Nothing to " + args[0] + " — run \\upsert <connection> <table> first"))
+			return nil
+		}
+		if args[0] == "save" {
+			return v.saveUpsert(args[1:])
+		}
+		return v.applyUpsert()
+	}
+
+	if len(args) < 2 || len(args) > 3 {
+		v.viewport.SetContent(StyleError.Render(usage))
+		return nil
+	}
+	if v.result == nil || len(v.result.Columns) == 0 || v.lastSQL == "" {
+		v.viewport.SetContent(StyleError.Render("Run a query that returns rows first"))
+		return nil
+	}
+	if v.tx != nil {
+		// The query is run again on a pooled connection, which would not
+		// see the transaction's changes.
+		v.viewport.SetContent(StyleError.Render("\\upsert runs the query again outside the open transaction: \\commit or \\rollback first"))
+		return nil
+	}
+	store, err := config.NewConnectionStore()
+	if err != nil {
+		v.viewport.SetContent(StyleError.Render("Load connections: " + err.Error()))
+		return nil
+	}
+	conn, ok := store.Get(args[0])
+	if !ok {
+		var names []string
+		for _, c := range store.Connections {
+			names = append(names, c.Name)
+		}
+		v.viewport.SetContentLines([]string{
+			StyleError.Render("No saved connection named " + args[0]),
+			"",
+			StyleDimmed.Render("Saved connections: " + strings.Join(names, ", ")),
+		})
+		return nil
+	}
+
+	schema, table := db.SplitTableName(args[1])
+	var conflict []string
+	if len(args) == 3 {
+		for _, c := range strings.Split(args[2], ",") {
+			if c = strings.TrimSpace(c); c != "" {
+				conflict = append(conflict, c)
+			}
+		}
+	}
+
+	v.loading = true
+	v.upsertScript = nil
+	sql := v.lastSQL
+	source := v.db
+	return func() tea.Msg {
+		ctx := context.Background()
+		target, err := db.Connect(ctx, config.FromConnection(conn))
+		if err != nil {
+			return UpsertPlanMsg{Conn: conn, Err: fmt.Errorf("connect to %s: %w", conn.Name, err)}
+		}
+		defer target.Close()
+		plan, err := db.NewUpsertPlan(ctx, source, target, conn.Name, sql, schema, table, conflict)
+		return UpsertPlanMsg{Conn: conn, Plan: plan, Err: err}
+	}
+}
+
+// applyUpsert runs the pending script on its connection.
+func (v *MainView) applyUpsert() tea.Cmd {
+	pending := v.upsertScript
+	v.loading = true
+	plan := pending.plan
+	applog.Event("UPSERT", "Copying %d rows into %s on %s", plan.Rows, plan.Table, plan.Connection)
+	return func() tea.Msg {
+		ctx := context.Background()
+		target, err := db.Connect(ctx, config.FromConnection(pending.conn))
+		if err != nil {
+			return UpsertAppliedMsg{Plan: plan, Err: fmt.Errorf("connect to %s: %w", plan.Connection, err)}
+		}
+		defer target.Close()
+		n, err := target.ApplyUpsertPlan(ctx, plan)
+		if err != nil {
+			applog.Event("UPSERT", "Failed: %s on %s: %v", plan.Table, plan.Connection, err)
+		} else {
+			applog.Event("UPSERT", "Wrote %d rows into %s on %s", n, plan.Table, plan.Connection)
+		}
+		return UpsertAppliedMsg{Plan: plan, Rows: n, Err: err}
+	}
+}
+
+// saveUpsert writes the pending script to a file.
+func (v *MainView) saveUpsert(args []string) tea.Cmd {
+	if len(args) == 0 {
+		v.viewport.SetContent(StyleError.Render("Usage: \\upsert save <file>"))
+		return nil
+	}
+	path := strings.Join(args, " ")
+	plan := v.upsertScript.plan
+	if err := os.WriteFile(path, []byte(plan.Script()), 0644); err != nil {
+		return func() tea.Msg { return StatusMsg("Save failed: " + err.Error()) }
+	}
+	return func() tea.Msg {
+		return StatusMsg(fmt.Sprintf("Saved %d statements to %s", len(plan.Statements), path))
+	}
+}
+
+// updateUpsert handles the \upsert messages.
+func (v *MainView) updateUpsert(msg tea.Msg) {
+	v.loading = false
+	switch msg := msg.(type) {
+	case UpsertPlanMsg:
+		if msg.Err != nil {
+			v.viewport.SetContent(StyleError.Render("\\upsert: " + msg.Err.Error()))
+			return
+		}
+		if msg.Plan.Rows == 0 {
+			v.viewport.SetContent(StyleDimmed.Render("The query returned no rows: nothing to copy."))
+			return
+		}
+		for i, stmt := range msg.Plan.Statements {
+			msg.Plan.Statements[i] = v.styleSQL(stmt)
+		}
+		v.upsertScript = &pendingUpsert{conn: msg.Conn, plan: msg.Plan}
+		v.viewport.SetContentLines(v.renderUpsert(msg.Plan))
+		v.viewport.Home()
+
+	case UpsertAppliedMsg:
+		if msg.Err != nil {
+			v.viewport.SetContentLines([]string{
+				StyleError.Render(fmt.Sprintf("Copy into %s on %s failed (rolled back): %s",
+					msg.Plan.Table, msg.Plan.Connection, msg.Err.Error())),
+				"",
+				StyleDimmed.Render("Fix the problem and run \\upsert apply again."),
+			})
+			return
+		}
+		v.upsertScript = nil
+		v.viewport.SetContent(StyleSuccess.Render(fmt.Sprintf("✓ Wrote %d rows into %s on %s",
+			msg.Rows, msg.Plan.Table, msg.Plan.Connection)))
+	}
+}
+
+// renderUpsert shows the script for review.
+func (v *MainView) renderUpsert(p *db.UpsertPlan) []string {
+	target := "plain INSERT"
+	if len(p.Conflict) > 0 {
+		target = "ON CONFLICT (" + strings.Join(p.Conflict, ", ") + ")"
+	}
+	lines := []string{
+		StyleBold.Render(fmt.Sprintf("📤 Copy %d rows into %s on %q", p.Rows, p.Table, p.Connection)),
+		StyleDimmed.Render(fmt.Sprintf("%d statements · %s", len(p.Statements), target)),
+		"",
+	}
+	for i, stmt := range p.Statements {
+		if i == upsertPreviewStatements {
+			lines = append(lines, StyleDimmed.Render(fmt.Sprintf("… %d more statements", len(p.Statements)-i)), "")
+			break
+		}
+		lines = append(lines, strings.Split(stmt+";", "\n")...)
+		lines = append(lines, "")
+	}
+	for _, w := range p.Warnings {
+		lines = append(lines, StyleWarning.Render("⚠️  "+w))
+	}
+	if len(p.Warnings) > 0 {
+		lines = append(lines, "")
+	}
+	lines = append(lines, StyleDimmed.Render("Run \\upsert apply to execute them on "+p.Connection+
+		" in one transaction, or \\upsert save <file> to write the script."))
+	return lines
+}
@@ -24,6 +24,11 @@ type View interface {
 	// SetSize is called when the terminal is resized.
 	SetSize(width, height int)
 
+	// Leave is called when the user switches to another view. Views cancel
+	// in-flight operations whose results would be stale by the time the
+	// user comes back.
+	Leave()
+
 	// WantsTextInput returns true when the view is accepting freeform text
 	// input (e.g. chat mode, editing a field). When true, the App should
 	// NOT intercept single-character shortcuts like q, 1-6, etc.
@@ -35,3 +40,22 @@ type KeyBinding struct {
 	Key  string
 	Desc string
 }
+
+// KeyGroup is a titled set of bindings in the help overlay.
+type KeyGroup struct {
+	Title    string
+	Bindings []KeyBinding
+}
+
+// FullHelper is implemented by views that register their complete keymap
+// for the help overlay. ShortHelp only lists the most common keys.
+type FullHelper interface {
+	FullHelp() []KeyGroup
+}
+
+// Searcher is implemented by views whose content can be searched with
+// /pattern. SearchViewport returns the viewport to search, or nil while
+// the view needs / and n for something else (typing, an open dialog).
+type Searcher interface {
+	SearchViewport() *Viewport
+}
@@ -0,0 +1,465 @@
+// view_activity.go — Database activity view.
+//
+// Samples pg_stat_database on an interval while the view is shown and
+// lists every database's transaction rate, rollback share, deadlocks,
+// cache hit ratio and tuple rates over the last interval, with a trend
+// line of the transaction rate and hit ratio across the samples kept.
+// Where the StatsView is about what takes space, this is about what the
+// connections are doing.
+//
+// 'd' shows the deadlocks reported in the server log (see
+// db.ReadDeadlocks) as wait-for cycles; the log is read again whenever a
+// sample shows the deadlock counter rising.
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/DachengChen/paiSQL/db"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// maxActivitySamples is the number of samples kept for the trend lines.
+const maxActivitySamples = 60
+
+// Deadlocks kept in the history, and shown.
+const (
+	maxDeadlocks   = 100
+	shownDeadlocks = 10
+)
+
+// activityIntervals are the sampling intervals +/- step through.
+var activityIntervals = []time.Duration{2 * time.Second, 5 * time.Second, 10 * time.Second, 30 * time.Second, time.Minute}
+
+// activitySample is one reading of every database's counters.
+type activitySample struct {
+	at    time.Time
+	stats map[string]db.DatabaseStats
+}
+
+// activityTickMsg triggers the next sample of generation gen.
+type activityTickMsg struct{ gen int }
+
+type ActivityView struct {
+	db       *db.DB
+	viewport *Viewport
+	width    int
+	height   int
+	tasks    viewTasks
+
+	samples  []activitySample // oldest first
+	names    []string         // databases of the last sample, in order
+	interval int              // index into activityIntervals
+	gen      int              // bumped to stop the ticks of a previous Init
+	err      error
+
+	// Deadlocks collected from the server log, oldest first
+	showDeadlocks bool
+	readingLog    bool
+	deadlocks     []db.Deadlock
+	deadlockErr   error
+}
+
+func NewActivityView(database *db.DB) *ActivityView {
+	return &ActivityView{
+		db:       database,
+		viewport: NewViewport(80, 20),
+		interval: 1,
+	}
+}
+
+func (v *ActivityView) Name() string         { return "Activity" }
+func (v *ActivityView) WantsTextInput() bool { return false }
+
+func (v *ActivityView) SetSize(width, height int) {
+	v.width = width
+	v.height = height
+	v.viewport.SetSize(width-2, height-2)
+	v.render()
+}
+
+func (v *ActivityView) ShortHelp() []KeyBinding {
+	return []KeyBinding{
+		{Key: "r", Desc: "sample now"},
+		{Key: "+/-", Desc: "interval"},
+		{Key: "d", Desc: "deadlocks"},
+		{Key: "Ctrl+K/J", Desc: "scroll"},
+	}
+}
+
+func (v *ActivityView) FullHelp() []KeyGroup {
+	return []KeyGroup{{Title: "Activity", Bindings: []KeyBinding{
+		{Key: "r", Desc: "sample now"},
+		{Key: "+/-", Desc: "sample less / more often (2s to 1m)"},
+		{Key: "c", Desc: "clear the samples"},
+		{Key: "d", Desc: "show/hide the deadlocks from the server log"},
+		{Key: "Ctrl+K/J", Desc: "scroll"},
+		{Key: "PgUp/PgDn", Desc: "page"},
+	}}}
+}
+
+// Init samples now and then on the interval, until Leave.
+func (v *ActivityView) Init() tea.Cmd {
+	v.gen++
+	return v.sample()
+}
+
+// Leave stops sampling; the samples are kept for the next visit.
+func (v *ActivityView) Leave() {
+	v.gen++
+	v.tasks.stop()
+}
+
+func (v *ActivityView) Update(msg tea.Msg) (View, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		return v.handleKey(msg)
+	case activityTickMsg:
+		if msg.gen != v.gen {
+			return v, nil
+		}
+		return v, v.sample()
+	case DatabaseStatsMsg:
+		if errors.Is(msg.Err, context.Canceled) {
+			return v, nil
+		}
+		v.err = msg.Err
+		var cmds []tea.Cmd
+		if msg.Err == nil {
+			v.addSample(msg.At, msg.Stats)
+			if v.deadlocksRose() {
+				cmds = append(cmds, v.readDeadlocks())
+			}
+		}
+		v.render()
+		if msg.Gen == v.gen {
+			cmds = append(cmds, v.tick())
+		}
+		return v, tea.Batch(cmds...)
+	case DeadlocksMsg:
+		v.readingLog = false
+		v.deadlockErr = msg.Err
+		v.mergeDeadlocks(msg.Deadlocks)
+		v.render()
+		return v, nil
+	}
+	return v, nil
+}
+
+func (v *ActivityView) handleKey(msg tea.KeyMsg) (View, tea.Cmd) {
+	switch msg.String() {
+	case "r":
+		v.gen++ // the sample schedules the next tick
+		return v, v.sample()
+	case "+":
+		if v.interval < len(activityIntervals)-1 {
+			v.interval++
+			v.render()
+		}
+	case "-":
+		if v.interval > 0 {
+			v.interval--
+			v.render()
+		}
+	case "c":
+		v.samples, v.names = nil, nil
+		v.render()
+	case "d":
+		v.showDeadlocks = !v.showDeadlocks
+		v.render()
+		if v.showDeadlocks {
+			return v, v.readDeadlocks()
+		}
+	case "ctrl+k":
+		v.viewport.ScrollUp(1)
+	case "ctrl+j":
+		v.viewport.ScrollDown(1)
+	case "pgup":
+		v.viewport.PageUp()
+	case "pgdown":
+		v.viewport.PageDown()
+	}
+	return v, nil
+}
+
+// sample reads the counters.
+func (v *ActivityView) sample() tea.Cmd {
+	ctx := v.tasks.restart()
+	gen, database := v.gen, v.db
+	return func() tea.Msg {
+		stats, err := database.FetchDatabaseStats(ctx)
+		return DatabaseStatsMsg{Gen: gen, Stats: stats, At: time.Now(), Err: err}
+	}
+}
+
+// tick schedules the next sample.
+func (v *ActivityView) tick() tea.Cmd {
+	gen := v.gen
+	return tea.Tick(activityIntervals[v.interval], func(time.Time) tea.Msg { return activityTickMsg{gen: gen} })
+}
+
+// addSample keeps a reading, dropping the oldest beyond maxActivitySamples.
+func (v *ActivityView) addSample(at time.Time, stats []db.DatabaseStats) {
+	s := activitySample{at: at, stats: make(map[string]db.DatabaseStats, len(stats))}
+	v.names = v.names[:0]
+	for _, st := range stats {
+		s.stats[st.Name] = st
+		v.names = append(v.names, st.Name)
+	}
+	v.samples = append(v.samples, s)
+	if len(v.samples) > maxActivitySamples {
+		v.samples = v.samples[len(v.samples)-maxActivitySamples:]
+	}
+}
+
+// deadlocksRose reports whether a database's deadlock counter rose
+// between the last two samples.
+func (v *ActivityView) deadlocksRose() bool {
+	n := len(v.samples)
+	if n < 2 {
+		return false
+	}
+	for name, st := range v.samples[n-1].stats {
+		if prev, ok := v.samples[n-2].stats[name]; ok && st.Deadlocks > prev.Deadlocks {
+			return true
+		}
+	}
+	return false
+}
+
+// readDeadlocks reads the deadlocks from the server log, unless a read is
+// already running.
+func (v *ActivityView) readDeadlocks() tea.Cmd {
+	if v.readingLog {
+		return nil
+	}
+	v.readingLog = true
+	database := v.db
+	return func() tea.Msg {
+		deadlocks, err := database.ReadDeadlocks(context.Background())
+		return DeadlocksMsg{Deadlocks: deadlocks, Err: err}
+	}
+}
+
+// mergeDeadlocks adds the deadlocks not seen yet; the history outlives
+// the log file rotating.
+func (v *ActivityView) mergeDeadlocks(deadlocks []db.Deadlock) {
+	seen := make(map[string]bool, len(v.deadlocks))
+	key := func(dl db.Deadlock) string { return fmt.Sprintf("%s/%d", dl.At, dl.Processes[0].PID) }
+	for _, dl := range v.deadlocks {
+		seen[key(dl)] = true
+	}
+	for _, dl := range deadlocks {
+		if !seen[key(dl)] {
+			v.deadlocks = append(v.deadlocks, dl)
+		}
+	}
+	if len(v.deadlocks) > maxDeadlocks {
+		v.deadlocks = v.deadlocks[len(v.deadlocks)-maxDeadlocks:]
+	}
+}
+
+// activityRates are a database's rates between two samples.
+type activityRates struct {
+	tx          float64 // commits and rollbacks per second
+	rollbackPct float64
+	deadlocks   int64
+	hitPct      float64 // NaN without block accesses
+	read        float64 // blocks read per second
+	returned    float64
+	fetched     float64
+}
+
+// rates returns the rates of database name between samples a and b, or
+// false if it's missing from either or its statistics were reset.
+func rates(name string, a, b activitySample) (activityRates, bool) {
+	x, okA := a.stats[name]
+	y, okB := b.stats[name]
+	secs := b.at.Sub(a.at).Seconds()
+	if !okA || !okB || secs <= 0 || y.Commits < x.Commits || y.BlksHit < x.BlksHit {
+		return activityRates{}, false
+	}
+	commits, rollbacks := float64(y.Commits-x.Commits), float64(y.Rollbacks-x.Rollbacks)
+	hit, read := float64(y.BlksHit-x.BlksHit), float64(y.BlksRead-x.BlksRead)
+	r := activityRates{
+		tx:        (commits + rollbacks) / secs,
+		deadlocks: y.Deadlocks - x.Deadlocks,
+		hitPct:    math.NaN(),
+		read:      read / secs,
+		returned:  float64(y.TupReturned-x.TupReturned) / secs,
+		fetched:   float64(y.TupFetched-x.TupFetched) / secs,
+	}
+	if commits+rollbacks > 0 {
+		r.rollbackPct = rollbacks * 100 / (commits + rollbacks)
+	}
+	if hit+read > 0 {
+		r.hitPct = hit * 100 / (hit + read)
+	}
+	return r, true
+}
+
+// render lays out the samples.
+func (v *ActivityView) render() {
+	interval := activityIntervals[v.interval]
+	title := StyleTitle.Render("📈 Database Activity")
+	status := fmt.Sprintf("  Sampling every %s", interval)
+	if n := len(v.samples); n > 0 {
+		status += fmt.Sprintf(" · %d samples · last %s", n, v.samples[n-1].at.Format("15:04:05"))
+	}
+	lines := []string{title, "", StyleDimmed.Render(status), ""}
+	if v.err != nil {
+		lines = append(lines, StyleError.Render("  ERROR: "+v.err.Error()), "")
+	}
+	if len(v.samples) == 0 {
+		v.viewport.SetContentLines(append(lines, StyleDimmed.Render("  Sampling pg_stat_database...")))
+		return
+	}
+
+	last := v.samples[len(v.samples)-1]
+	lines = append(lines,
+		fmt.Sprintf("  %-24s │ %5s │ %9s │ %9s │ %9s │ %7s │ %9s │ %11s │ %11s",
+			"Database", "Conns", "Tx/s", "Rollback", "Deadlocks", "Hit", "Reads/s", "Returned/s", "Fetched/s"),
+		"  "+strings.Repeat("─", 120))
+	for _, name := range v.names {
+		st := last.stats[name]
+		var r activityRates
+		ok := len(v.samples) > 1
+		if ok {
+			r, ok = rates(name, v.samples[len(v.samples)-2], last)
+		}
+		if !ok {
+			// Only the cumulative counters until there are two samples
+			total := st.Commits + st.Rollbacks
+			rollback, hit := "—", "—"
+			if total > 0 {
+				rollback = fmt.Sprintf("%.1f%%", float64(st.Rollbacks)*100/float64(total))
+			}
+			if st.BlksHit+st.BlksRead > 0 {
+				hit = fmt.Sprintf("%.1f%%", float64(st.BlksHit)*100/float64(st.BlksHit+st.BlksRead))
+			}
+			lines = append(lines, fmt.Sprintf("  %-24s │ %5d │ %9s │ %9s │ %9d │ %7s │ %9s │ %11s │ %11s",
+				name, st.Backends, "—", rollback, st.Deadlocks, hit, "—", "—", "—"))
+			continue
+		}
+		hit := "—"
+		if !math.IsNaN(r.hitPct) {
+			hit = fmt.Sprintf("%.1f%%", r.hitPct)
+		}
+		deadlocks := fmt.Sprintf("%d", st.Deadlocks)
+		if r.deadlocks > 0 {
+			deadlocks = StyleError.Render(fmt.Sprintf("%9s", fmt.Sprintf("%d (+%d)", st.Deadlocks, r.deadlocks)))
+		}
+		lines = append(lines, fmt.Sprintf("  %-24s │ %5d │ %9.1f │ %8.1f%% │ %9s │ %7s │ %9.1f │ %11.0f │ %11.0f",
+			name, st.Backends, r.tx, r.rollbackPct, deadlocks, hit, r.read, r.returned, r.fetched))
+	}
+	lines = append(lines, "", StyleDimmed.Render("  Rates over the last interval; until the second sample, ratios since the statistics were reset"))
+
+	if len(v.samples) > 2 {
+		lines = append(lines, "", StyleTitle.Render("Trend"), "")
+		for _, name := range v.names {
+			var tx, hit []float64
+			for i := 1; i < len(v.samples); i++ {
+				r, ok := rates(name, v.samples[i-1], v.samples[i])
+				if !ok {
+					tx, hit = append(tx, math.NaN()), append(hit, math.NaN())
+					continue
+				}
+				tx, hit = append(tx, r.tx), append(hit, r.hitPct)
+			}
+			lines = append(lines,
+				fmt.Sprintf("  %-24s tx/s %s", name, sparkline(tx)),
+				fmt.Sprintf("  %-24s hit  %s", "", sparkline(hit)))
+		}
+	}
+	if v.showDeadlocks {
+		lines = append(lines, v.deadlockLines()...)
+	}
+	lines = append(lines, "", StyleDimmed.Render("  'r' samples now, +/- change the interval, 'c' clears the samples, 'd' shows the deadlocks"))
+	v.viewport.SetContentLines(lines)
+}
+
+// deadlockLines renders the collected deadlocks, newest first, each as
+// its wait-for cycle: every process waits for a lock held by the next,
+// and the last for one held by the first.
+func (v *ActivityView) deadlockLines() []string {
+	lines := []string{"", StyleTitle.Render("💀 Deadlocks (server log)"), ""}
+	if v.readingLog {
+		lines = append(lines, StyleDimmed.Render("  Reading the server log..."))
+	}
+	if v.deadlockErr != nil {
+		lines = append(lines, StyleError.Render("  Can't read the server log: "+v.deadlockErr.Error()))
+	}
+	if len(v.deadlocks) == 0 {
+		if !v.readingLog && v.deadlockErr == nil {
+			lines = append(lines, StyleDimmed.Render("  No deadlocks in the recent server log"))
+		}
+		return lines
+	}
+	width := max(v.width-30, 40)
+	for i := len(v.deadlocks) - 1; i >= max(len(v.deadlocks)-shownDeadlocks, 0); i-- {
+		dl := v.deadlocks[i]
+		at := dl.At
+		if at == "" {
+			at = "(no timestamp)"
+		}
+		lines = append(lines, StyleBold.Render(fmt.Sprintf("  %s  %d processes", at, len(dl.Processes))))
+		for j, p := range dl.Processes {
+			head, tail := "  │ ", "  │ "
+			if j == 0 {
+				head = "  ┌▶"
+			}
+			if j == len(dl.Processes)-1 {
+				tail = "  └─"
+			}
+			pid := fmt.Sprintf("%-7d", p.PID)
+			if j == 0 {
+				pid += StyleError.Render(" cancelled")
+			}
+			lines = append(lines,
+				fmt.Sprintf("%s %s %s", head, pid, truncateRunes(p.Query, width)),
+				StyleDimmed.Render(fmt.Sprintf("%s   waits for %s held by %d", tail, p.WaitsFor, p.BlockedBy)))
+		}
+		lines = append(lines, "")
+	}
+	if n := len(v.deadlocks) - shownDeadlocks; n > 0 {
+		lines = append(lines, StyleDimmed.Render(fmt.Sprintf("  … and %d older", n)))
+	}
+	return lines
+}
+
+// sparkline renders values as block characters scaled between their min
+// and max; NaN is a gap.
+func sparkline(values []float64) string {
+	const blocks = "▁▂▃▄▅▆▇█"
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, x := range values {
+		if !math.IsNaN(x) {
+			lo, hi = min(lo, x), max(hi, x)
+		}
+	}
+	levels := []rune(blocks)
+	var sb strings.Builder
+	for _, x := range values {
+		switch {
+		case math.IsNaN(x):
+			sb.WriteRune(' ')
+		case hi == lo:
+			sb.WriteRune(levels[0])
+		default:
+			sb.WriteRune(levels[int((x-lo)/(hi-lo)*float64(len(levels)-1)+0.5)])
+		}
+	}
+	if !math.IsInf(lo, 1) {
+		sb.WriteString(StyleDimmed.Render(fmt.Sprintf("  %.1f–%.1f", lo, hi)))
+	}
+	return sb.String()
+}
+
+func (v *ActivityView) View() string {
+	return v.viewport.Render()
+}
@@ -6,7 +6,6 @@
 package tui
 
 import (
-	"context"
 	"strings"
 
 	"github.com/DachengChen/paiSQL/ai"
@@ -17,12 +16,19 @@ import (
 type AIView struct {
 	provider ai.Provider
 	viewport *Viewport
-	input    string
+	input    textInput
 	messages []ai.Message
 	loading  bool
 	err      error
 	width    int
 	height   int
+	reqID    int    // ID of the latest chat request; older replies are dropped
+	partial  string // streamed part of the pending reply
+
+	// Ollama model management (ai_ollama.go).
+	info       []string // output of the last /models or /pull
+	pulling    string   // model being downloaded, if any
+	pullStatus ai.PullProgress
 }
 
 func NewAIView(provider ai.Provider) *AIView {
@@ -46,12 +52,32 @@ func (v *AIView) ShortHelp() []KeyBinding {
 	return []KeyBinding{
 		{Key: "Enter", Desc: "send"},
 		{Key: "Ctrl+L", Desc: "clear"},
-		{Key: "↑/↓", Desc: "scroll"},
+		{Key: "Ctrl+K/J", Desc: "scroll"},
 	}
 }
 
+func (v *AIView) FullHelp() []KeyGroup {
+	return []KeyGroup{{Title: "AI", Bindings: []KeyBinding{
+		{Key: "Enter", Desc: "send"},
+		{Key: "Ctrl+L", Desc: "clear conversation"},
+		{Key: "Ctrl+K/J", Desc: "scroll"},
+		{Key: "PgUp/PgDn", Desc: "page"},
+		{Key: "/models", Desc: "list Ollama models"},
+		{Key: "/pull [model]", Desc: "download an Ollama model"},
+	}}}
+}
+
 func (v *AIView) Init() tea.Cmd {
-	welcome := []string{
+	v.refresh()
+	if o, ok := v.provider.(*ai.Ollama); ok {
+		return listOllamaModels(o, true)
+	}
+	return nil
+}
+
+// welcome is shown until the first message is sent.
+func (v *AIView) welcome() []string {
+	lines := []string{
 		StyleTitle.Render("🤖 AI Assistant") + StyleDimmed.Render(" ("+v.provider.Name()+")"),
 		"",
 		"Ask me anything about your database:",
@@ -62,17 +88,41 @@ func (v *AIView) Init() tea.Cmd {
 		"",
 		StyleDimmed.Render("Type your question and press Enter."),
 	}
-	v.viewport.SetContentLines(welcome)
-	return nil
+	if _, ok := v.provider.(*ai.Ollama); ok {
+		lines = append(lines, StyleDimmed.Render("/models lists installed Ollama models; /pull [model] downloads one."))
+	}
+	return lines
+}
+
+// refresh redraws the conversation and scrolls to its end.
+func (v *AIView) refresh() {
+	v.viewport.SetContentLines(v.renderChat())
+	v.viewport.End()
 }
 
+// Leave keeps a pending reply; it is added to the chat when it arrives.
+func (v *AIView) Leave() {}
+
 func (v *AIView) Update(msg tea.Msg) (View, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		return v.handleKey(msg)
 
+	case AIChunkMsg:
+		if msg.ID == v.reqID {
+			v.partial += msg.Delta
+			v.refresh()
+		}
+		return v, msg.next
+
+	case OllamaModelsMsg, OllamaPullMsg, OllamaPullDoneMsg:
+		return v, v.updateOllama(msg)
+
 	case AIResponseMsg:
-		v.loading = false
+		if msg.ID != v.reqID {
+			return v, nil
+		}
+		v.loading, v.partial = false, ""
 		if msg.Err != nil {
 			v.err = msg.Err
 			v.messages = append(v.messages, ai.Message{
@@ -85,8 +135,7 @@ func (v *AIView) Update(msg tea.Msg) (View, tea.Cmd) {
 				Content: msg.Response,
 			})
 		}
-		v.viewport.SetContentLines(v.renderChat())
-		v.viewport.End()
+		v.refresh()
 		return v, nil
 	}
 
@@ -98,8 +147,7 @@ func (v *AIView) handleKey(msg tea.KeyMsg) (View, tea.Cmd) {
 	case "enter":
 		return v, v.sendMessage()
 	case "ctrl+l":
-		v.messages = nil
-		v.viewport.SetContent("")
+		v.messages, v.info = nil, nil
 		return v, v.Init()
 	case "ctrl+k":
 		v.viewport.ScrollUp(1)
@@ -109,62 +157,47 @@ func (v *AIView) handleKey(msg tea.KeyMsg) (View, tea.Cmd) {
 		v.viewport.PageUp()
 	case "pgdown":
 		v.viewport.PageDown()
-	case "backspace":
-		if len(v.input) > 0 {
-			v.input = v.input[:len(v.input)-1]
-		}
 	default:
-		if msg.Type == tea.KeyRunes {
-			v.input += string(msg.Runes)
-		} else if msg.Type == tea.KeySpace {
-			v.input += " "
-		}
+		v.input.Update(msg)
 	}
 	return v, nil
 }
 
 func (v *AIView) sendMessage() tea.Cmd {
-	text := strings.TrimSpace(v.input)
+	text := strings.TrimSpace(v.input.Value())
 	if text == "" {
 		return nil
 	}
+	if cmd, ok := v.ollamaCommand(text); ok {
+		return cmd
+	}
 
 	v.messages = append(v.messages, ai.Message{
 		Role:    "user",
 		Content: text,
 	})
-	v.input = ""
-	v.loading = true
-	v.viewport.SetContentLines(v.renderChat())
-	v.viewport.End()
+	v.input.Reset()
+	v.loading, v.partial = true, ""
+	v.refresh()
 
 	// Copy messages for the goroutine
 	msgs := make([]ai.Message, len(v.messages))
 	copy(msgs, v.messages)
 
-	providerName := v.provider.Name()
-	return func() tea.Msg {
-		// Build input summary for logging
-		var inputSummary string
-		for _, m := range msgs {
-			inputSummary += m.Role + ": " + m.Content + "\n"
-		}
-		ai.LogAIRequest("Chat", providerName, map[string]string{
-			"Messages": inputSummary,
-		})
-
-		resp, err := v.provider.Chat(context.Background(), msgs)
-		ai.LogAIResponse("Chat", resp, err)
-		return AIResponseMsg{Response: resp, Err: err}
-	}
+	v.reqID++
+	return chatCmd(v.provider, v.reqID, msgs)
 }
 
 func (v *AIView) renderChat() []string {
 	var lines []string
 
-	lines = append(lines, StyleTitle.Render("🤖 AI Assistant")+" "+
-		StyleDimmed.Render("("+v.provider.Name()+")"))
-	lines = append(lines, "")
+	if len(v.messages) == 0 {
+		lines = v.welcome()
+	} else {
+		lines = append(lines, StyleTitle.Render("🤖 AI Assistant")+" "+
+			StyleDimmed.Render("("+v.provider.Name()+")"))
+		lines = append(lines, "")
+	}
 
 	userStyle := lipgloss.NewStyle().
 		Foreground(ColorSecondary).
@@ -187,15 +220,28 @@ func (v *AIView) renderChat() []string {
 		}
 	}
 
-	if v.loading {
+	if v.loading && v.partial != "" {
+		lines = append(lines, assistantStyle.Render("AI: "))
+		for _, line := range strings.Split(v.partial, "\n") {
+			lines = append(lines, "  "+line)
+		}
+	} else if v.loading {
 		lines = append(lines, StyleDimmed.Render("  ⏳ Thinking..."))
 	}
 
+	if v.pulling != "" {
+		lines = append(lines, v.renderPull())
+	}
+	if len(v.info) > 0 {
+		lines = append(lines, "")
+		lines = append(lines, v.info...)
+	}
+
 	return lines
 }
 
 func (v *AIView) View() string {
-	prompt := StylePrompt.Render("Ask> ") + v.input + "█"
+	prompt := StylePrompt.Render("Ask> ") + v.input.View()
 	if v.loading {
 		prompt = StylePrompt.Render("Ask> ") + StyleDimmed.Render("waiting for response...")
 	}
@@ -1,13 +1,17 @@
 // view_explain.go — EXPLAIN / EXPLAIN ANALYZE view.
 //
 // Shows the JSON query plan with syntax highlighting and scrolling.
-// The user can paste a query and run EXPLAIN or EXPLAIN ANALYZE.
+// The user can paste a query and run EXPLAIN or EXPLAIN ANALYZE, with
+// BUFFERS, SETTINGS, WAL, VERBOSE and FORMAT TEXT toggled on or off. The
+// toggles last for the session and are shown in the prompt. \save and
+// \load (explain_saved.go) keep plans in files for later comparison.
 package tui
 
 import (
 	"context"
 	"strings"
 
+	"github.com/DachengChen/paiSQL/config"
 	"github.com/DachengChen/paiSQL/db"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -16,8 +20,10 @@ import (
 type ExplainView struct {
 	db       *db.DB
 	viewport *Viewport
-	input    string
-	analyze  bool
+	input    textInput
+	opts     db.ExplainOptions // Analyze is that of the last run
+	current  *config.SavedPlan // last result, for \save
+	baseline *config.SavedPlan // plan from \load that new results are compared with
 	loading  bool
 	err      error
 	width    int
@@ -34,6 +40,14 @@ func NewExplainView(database *db.DB) *ExplainView {
 func (v *ExplainView) Name() string         { return "Explain" }
 func (v *ExplainView) WantsTextInput() bool { return false }
 
+// SearchViewport lets / search the plan while no query is being typed.
+func (v *ExplainView) SearchViewport() *Viewport {
+	if v.input.Value() != "" {
+		return nil
+	}
+	return v.viewport
+}
+
 func (v *ExplainView) SetSize(width, height int) {
 	v.width = width
 	v.height = height
@@ -44,12 +58,34 @@ func (v *ExplainView) ShortHelp() []KeyBinding {
 	return []KeyBinding{
 		{Key: "Enter", Desc: "explain"},
 		{Key: "Ctrl+A", Desc: "analyze"},
-		{Key: "w", Desc: "wrap"},
+		{Key: "Ctrl+B/S/E/R", Desc: "buffers/settings/wal/verbose"},
+		{Key: "Ctrl+F", Desc: "text/json"},
 	}
 }
 
+func (v *ExplainView) FullHelp() []KeyGroup {
+	return []KeyGroup{{Title: "Explain", Bindings: []KeyBinding{
+		{Key: "Enter", Desc: "explain query"},
+		{Key: "Ctrl+A", Desc: "explain analyze (runs the query)"},
+		{Key: "Ctrl+B", Desc: "toggle BUFFERS"},
+		{Key: "Ctrl+S", Desc: "toggle SETTINGS"},
+		{Key: "Ctrl+E", Desc: "toggle WAL (with ANALYZE)"},
+		{Key: "Ctrl+R", Desc: "toggle VERBOSE"},
+		{Key: "Ctrl+F", Desc: "toggle FORMAT TEXT/JSON"},
+		{Key: "\\save [file]", Desc: "save the plan with its query"},
+		{Key: "\\load [file]", Desc: "load a saved plan to compare against (no file lists them)"},
+		{Key: "Ctrl+K/J", Desc: "scroll"},
+		{Key: "Ctrl+H/L", Desc: "pan"},
+		{Key: "PgUp/PgDn", Desc: "page"},
+		{Key: "Ctrl+W", Desc: "toggle wrapping"},
+	}}}
+}
+
 func (v *ExplainView) Init() tea.Cmd { return nil }
 
+// Leave lets a running EXPLAIN finish; the plan is waiting on return.
+func (v *ExplainView) Leave() {}
+
 func (v *ExplainView) Update(msg tea.Msg) (View, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
@@ -59,7 +95,16 @@ func (v *ExplainView) Update(msg tea.Msg) (View, tea.Cmd) {
 		v.loading = false
 		v.err = msg.Err
 		if msg.Result != nil {
-			v.viewport.SetContent(v.formatJSON(msg.Result.JSON))
+			v.current = &config.SavedPlan{
+				Query:   msg.Query,
+				Options: msg.Options.String(),
+				Plan:    []byte(msg.Result.JSON),
+				Text:    msg.Result.Text,
+			}
+			if msg.Result.JSON == "" {
+				v.current.Plan = nil
+			}
+			v.viewport.SetContent(v.comparison() + v.planContent(v.current))
 		} else if msg.Err != nil {
 			v.viewport.SetContent(StyleError.Render("ERROR: " + msg.Err.Error()))
 		}
@@ -72,11 +117,25 @@ func (v *ExplainView) Update(msg tea.Msg) (View, tea.Cmd) {
 func (v *ExplainView) handleKey(msg tea.KeyMsg) (View, tea.Cmd) {
 	switch msg.String() {
 	case "enter":
+		if strings.HasPrefix(v.input.Value(), "\\") {
+			return v, v.planCommand(strings.Fields(v.input.Value()))
+		}
 		return v, v.runExplain(false)
 
 	case "ctrl+a":
 		return v, v.runExplain(true)
 
+	case "ctrl+b":
+		v.opts.Buffers = !v.opts.Buffers
+	case "ctrl+s":
+		v.opts.Settings = !v.opts.Settings
+	case "ctrl+e":
+		v.opts.WAL = !v.opts.WAL
+	case "ctrl+r":
+		v.opts.Verbose = !v.opts.Verbose
+	case "ctrl+f":
+		v.opts.Text = !v.opts.Text
+
 	case "ctrl+k":
 		v.viewport.ScrollUp(1)
 	case "ctrl+j":
@@ -91,32 +150,25 @@ func (v *ExplainView) handleKey(msg tea.KeyMsg) (View, tea.Cmd) {
 		v.viewport.PageDown()
 	case "ctrl+w":
 		v.viewport.ToggleWrap()
-	case "backspace":
-		if len(v.input) > 0 {
-			v.input = v.input[:len(v.input)-1]
-		}
 	default:
-		if msg.Type == tea.KeyRunes {
-			v.input += string(msg.Runes)
-		} else if msg.Type == tea.KeySpace {
-			v.input += " "
-		}
+		v.input.Update(msg)
 	}
 	return v, nil
 }
 
 func (v *ExplainView) runExplain(analyze bool) tea.Cmd {
-	sql := strings.TrimSpace(v.input)
+	sql := strings.TrimSpace(v.input.Value())
 	if sql == "" {
 		return nil
 	}
 
 	v.loading = true
-	v.analyze = analyze
+	v.opts.Analyze = analyze
+	opts := v.opts
 
 	return func() tea.Msg {
-		result, err := v.db.Explain(context.Background(), sql, analyze)
-		return ExplainResultMsg{Result: result, Err: err}
+		result, err := v.db.ExplainWith(context.Background(), sql, opts)
+		return ExplainResultMsg{Query: sql, Options: opts, Result: result, Err: err}
 	}
 }
 
@@ -141,14 +193,15 @@ func (v *ExplainView) formatJSON(json string) string {
 }
 
 func (v *ExplainView) View() string {
-	mode := "EXPLAIN"
-	if v.analyze {
-		mode = "EXPLAIN ANALYZE"
+	mode := StylePrompt.Render("EXPLAIN " + v.opts.String())
+	if v.opts.WAL && !v.opts.Analyze {
+		mode += StyleDimmed.Render(" (+WAL with Ctrl+A)")
 	}
+	mode += StylePrompt.Render("> ")
 
-	prompt := StylePrompt.Render(mode+"> ") + v.input + "█"
+	prompt := mode + v.input.View()
 	if v.loading {
-		prompt = StylePrompt.Render(mode+"> ") + StyleDimmed.Render("analyzing...")
+		prompt = mode + StyleDimmed.Render("analyzing...")
 	}
 
 	content := v.viewport.Render()
@@ -19,7 +19,7 @@ type IndexView struct {
 	db         *db.DB
 	aiProvider ai.Provider
 	viewport   *Viewport
-	input      string
+	input      textInput
 	loading    bool
 	err        error
 	width      int
@@ -46,10 +46,19 @@ func (v *IndexView) SetSize(width, height int) {
 func (v *IndexView) ShortHelp() []KeyBinding {
 	return []KeyBinding{
 		{Key: "Enter", Desc: "analyze"},
-		{Key: "↑/↓", Desc: "scroll"},
+		{Key: "Ctrl+K/J", Desc: "scroll"},
 	}
 }
 
+func (v *IndexView) FullHelp() []KeyGroup {
+	return []KeyGroup{{Title: "Index", Bindings: []KeyBinding{
+		{Key: "Enter", Desc: "suggest indexes for the query"},
+		{Key: "Ctrl+K/J", Desc: "scroll"},
+		{Key: "PgUp/PgDn", Desc: "page"},
+		{Key: "Ctrl+W", Desc: "toggle wrapping"},
+	}}}
+}
+
 func (v *IndexView) Init() tea.Cmd {
 	v.viewport.SetContent(StyleDimmed.Render(
 		"Enter a SQL query and press Enter to get index suggestions.\n\n" +
@@ -58,6 +67,9 @@ func (v *IndexView) Init() tea.Cmd {
 	return nil
 }
 
+// Leave lets a running analysis finish in the background.
+func (v *IndexView) Leave() {}
+
 func (v *IndexView) Update(msg tea.Msg) (View, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
@@ -90,22 +102,14 @@ func (v *IndexView) handleKey(msg tea.KeyMsg) (View, tea.Cmd) {
 		v.viewport.PageDown()
 	case "ctrl+w":
 		v.viewport.ToggleWrap()
-	case "backspace":
-		if len(v.input) > 0 {
-			v.input = v.input[:len(v.input)-1]
-		}
 	default:
-		if msg.Type == tea.KeyRunes {
-			v.input += string(msg.Runes)
-		} else if msg.Type == tea.KeySpace {
-			v.input += " "
-		}
+		v.input.Update(msg)
 	}
 	return v, nil
 }
 
 func (v *IndexView) analyze() tea.Cmd {
-	sql := strings.TrimSpace(v.input)
+	sql := strings.TrimSpace(v.input.Value())
 	if sql == "" {
 		return nil
 	}
@@ -129,12 +133,12 @@ func (v *IndexView) analyze() tea.Cmd {
 		})
 		suggestion, err := v.aiProvider.SuggestIndexes(ctx, sql, explain.JSON)
 		ai.LogAIResponse("SuggestIndexes", suggestion, err)
-		return IndexSuggestionMsg{Suggestion: suggestion, Err: err}
+		return IndexSuggestionMsg{Suggestion: ai.SanitizeResponse(suggestion), Err: err}
 	}
 }
 
 func (v *IndexView) View() string {
-	prompt := StylePrompt.Render("Index> ") + v.input + "█"
+	prompt := StylePrompt.Render("Index> ") + v.input.View()
 	if v.loading {
 		prompt = StylePrompt.Render("Index> ") + StyleDimmed.Render("analyzing query plan...")
 	}
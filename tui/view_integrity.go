@@ -0,0 +1,157 @@
+// view_integrity.go — Data-integrity report view.
+//
+// Runs checks that constraints don't cover (orphaned rows behind implicit
+// FKs, duplicates in unique-looking columns, stray NULLs) and lists the
+// findings per table. The checks scan every table, so they run once when
+// the view is first opened and again only on 'r'.
+package tui
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/DachengChen/paiSQL/db"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type IntegrityView struct {
+	db       *db.DB
+	viewport *Viewport
+	loading  bool
+	loaded   bool
+	width    int
+	height   int
+}
+
+func NewIntegrityView(database *db.DB) *IntegrityView {
+	return &IntegrityView{
+		db:       database,
+		viewport: NewViewport(80, 20),
+	}
+}
+
+func (v *IntegrityView) Name() string         { return "Integrity" }
+func (v *IntegrityView) WantsTextInput() bool { return false }
+
+func (v *IntegrityView) SetSize(width, height int) {
+	v.width = width
+	v.height = height
+	v.viewport.SetSize(width-2, height-2)
+}
+
+func (v *IntegrityView) ShortHelp() []KeyBinding {
+	return []KeyBinding{
+		{Key: "r", Desc: "re-check"},
+		{Key: "↑/↓", Desc: "scroll"},
+	}
+}
+
+func (v *IntegrityView) FullHelp() []KeyGroup {
+	return []KeyGroup{{Title: "Integrity", Bindings: []KeyBinding{
+		{Key: "r", Desc: "re-run checks"},
+		{Key: "↑/↓", Desc: "scroll (also Ctrl+K/J)"},
+		{Key: "PgUp/PgDn", Desc: "page"},
+	}}}
+}
+
+func (v *IntegrityView) Init() tea.Cmd {
+	if v.loaded || v.loading {
+		return nil
+	}
+	return v.runChecks()
+}
+
+// Leave lets the checks run to completion since they only run once.
+func (v *IntegrityView) Leave() {}
+
+func (v *IntegrityView) Update(msg tea.Msg) (View, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		return v.handleKey(msg)
+	case IntegrityMsg:
+		v.loading = false
+		v.loaded = true
+		if msg.Err != nil {
+			v.viewport.SetContent(StyleError.Render("ERROR: " + msg.Err.Error()))
+		} else {
+			v.viewport.SetContentLines(formatIntegrityReport(msg.Report))
+		}
+		return v, nil
+	}
+	return v, nil
+}
+
+func (v *IntegrityView) handleKey(msg tea.KeyMsg) (View, tea.Cmd) {
+	switch msg.String() {
+	case "r":
+		if !v.loading {
+			return v, v.runChecks()
+		}
+	case "up", "ctrl+k":
+		v.viewport.ScrollUp(1)
+	case "down", "ctrl+j":
+		v.viewport.ScrollDown(1)
+	case "pgup":
+		v.viewport.PageUp()
+	case "pgdown":
+		v.viewport.PageDown()
+	}
+	return v, nil
+}
+
+func (v *IntegrityView) runChecks() tea.Cmd {
+	v.loading = true
+	database := v.db
+	return func() tea.Msg {
+		report, err := database.CheckIntegrity(context.Background(), "")
+		return IntegrityMsg{Report: report, Err: err}
+	}
+}
+
+// formatIntegrityReport renders findings grouped by table; clean tables
+// are summarized on one line at the end.
+func formatIntegrityReport(report []db.TableIntegrity) []string {
+	lines := []string{StyleTitle.Render("🩺 Data Integrity"), ""}
+
+	icons := map[string]string{
+		db.IssueOrphans:    "🔗",
+		db.IssueDuplicates: "👯",
+		db.IssueNulls:      "∅ ",
+	}
+
+	var clean []string
+	issues := 0
+	for _, t := range report {
+		if t.Err != nil {
+			lines = append(lines, StyleBold.Render(t.Table), StyleError.Render("  could not check: "+t.Err.Error()), "")
+			continue
+		}
+		if len(t.Issues) == 0 {
+			clean = append(clean, t.Table)
+			continue
+		}
+		issues += len(t.Issues)
+		lines = append(lines, StyleBold.Render(fmt.Sprintf("%s (%s rows)", t.Table, db.FormatRowCount(t.Rows))))
+		for _, is := range t.Issues {
+			lines = append(lines, fmt.Sprintf("  %s %-24s %s", icons[is.Kind], is.Column, is.Detail))
+		}
+		lines = append(lines, "")
+	}
+
+	if issues == 0 {
+		lines = append(lines, StyleSuccess.Render(fmt.Sprintf("✓ No issues found in %d tables", len(report))))
+	} else if len(clean) > 0 {
+		lines = append(lines, StyleDimmed.Render(fmt.Sprintf("✓ %d tables without issues", len(clean))))
+	}
+	lines = append(lines, "",
+		StyleDimmed.Render("🔗 orphaned rows (implicit *_id FKs)  👯 duplicates in unique-looking columns  ∅ NULLs in mostly-set columns"),
+		StyleDimmed.Render("Press 'r' to re-check"))
+	return lines
+}
+
+func (v *IntegrityView) View() string {
+	if v.loading {
+		return StyleDimmed.Render("  Checking tables...")
+	}
+	return v.viewport.Render()
+}
@@ -0,0 +1,306 @@
+// view_listen.go — LISTEN/NOTIFY view.
+//
+// Listens on channels and streams the notifications that arrive, with
+// their payload, sending backend and time, for debugging applications
+// that use Postgres pub/sub. Commands are typed at the prompt:
+//
+//	listen <channel>...          start listening
+//	unlisten <channel>|*         stop listening
+//	notify <channel> [payload]   send a notification
+//
+// Notifications keep streaming while another view is active.
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/DachengChen/paiSQL/db"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// maxListenLines is the number of lines kept in the stream.
+const maxListenLines = 1000
+
+type ListenView struct {
+	db       *db.DB
+	viewport *Viewport
+	input    textInput
+	lines    []string
+	received int
+	width    int
+	height   int
+
+	listener   *db.Listener
+	channels   []string
+	connecting bool
+	pending    []string // commands typed before the connection was ready
+
+	// A Wait holds the listener's connection, so it is cancelled before
+	// LISTEN/UNLISTEN and started again once none is running.
+	waitGen    int
+	waitCancel context.CancelFunc
+	ops        int
+}
+
+func NewListenView(database *db.DB) *ListenView {
+	return &ListenView{
+		db:       database,
+		viewport: NewViewport(80, 20),
+	}
+}
+
+func (v *ListenView) Name() string         { return "Listen" }
+func (v *ListenView) WantsTextInput() bool { return true }
+
+func (v *ListenView) SetSize(width, height int) {
+	v.width = width
+	v.height = height
+	v.viewport.SetSize(width-2, height-5)
+}
+
+func (v *ListenView) ShortHelp() []KeyBinding {
+	return []KeyBinding{
+		{Key: "Enter", Desc: "run"},
+		{Key: "Ctrl+L", Desc: "clear"},
+		{Key: "Ctrl+K/J", Desc: "scroll"},
+	}
+}
+
+func (v *ListenView) FullHelp() []KeyGroup {
+	return []KeyGroup{{Title: "Listen", Bindings: []KeyBinding{
+		{Key: "listen <channel>", Desc: "LISTEN on one or more channels"},
+		{Key: "unlisten <channel>|*", Desc: "stop listening"},
+		{Key: "notify <channel> [payload]", Desc: "send a notification"},
+		{Key: "Ctrl+L", Desc: "clear the stream"},
+		{Key: "Ctrl+K/J", Desc: "scroll"},
+		{Key: "PgUp/PgDn", Desc: "page"},
+	}}}
+}
+
+func (v *ListenView) Init() tea.Cmd {
+	v.refresh()
+	return nil
+}
+
+// Leave keeps listening; notifications are added while the view is hidden.
+func (v *ListenView) Leave() {}
+
+func (v *ListenView) Update(msg tea.Msg) (View, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		return v.handleKey(msg)
+
+	case ListenerReadyMsg:
+		v.connecting = false
+		if msg.Err != nil {
+			v.pending = nil
+			v.appendLine(StyleError.Render("Connect failed: " + msg.Err.Error()))
+			return v, nil
+		}
+		v.listener = msg.Listener
+		var cmds []tea.Cmd
+		for _, line := range v.pending {
+			cmds = append(cmds, v.run(line))
+		}
+		v.pending = nil
+		return v, tea.Batch(append(cmds, v.startWait())...)
+
+	case ListenOpMsg:
+		if msg.Op != "notify" {
+			v.ops--
+		}
+		switch {
+		case msg.Err != nil:
+			v.appendLine(StyleError.Render(msg.Op + " " + msg.Channel + ": " + msg.Err.Error()))
+		case msg.Op == "listen":
+			if !slices.Contains(v.channels, msg.Channel) {
+				v.channels = append(v.channels, msg.Channel)
+			}
+			v.appendLine(StyleSuccess.Render("✓ Listening on " + msg.Channel))
+		case msg.Op == "unlisten":
+			v.channels = slices.DeleteFunc(v.channels, func(c string) bool {
+				return msg.Channel == "*" || c == msg.Channel
+			})
+			v.appendLine(StyleDimmed.Render("Stopped listening on " + msg.Channel))
+		default:
+			v.appendLine(StyleDimmed.Render("Sent on " + msg.Channel))
+		}
+		return v, v.startWait()
+
+	case NotificationMsg:
+		if msg.Gen != v.waitGen {
+			return v, nil // cancelled for a LISTEN/UNLISTEN
+		}
+		v.waitCancel = nil
+		if msg.Err != nil {
+			if !errors.Is(msg.Err, context.Canceled) {
+				v.appendLine(StyleError.Render("Listening stopped: " + msg.Err.Error()))
+				v.listener.Close()
+				v.listener, v.channels = nil, nil
+			}
+			return v, nil
+		}
+		v.received++
+		v.appendLine(formatNotification(msg.Notification))
+		return v, v.startWait()
+	}
+	return v, nil
+}
+
+func (v *ListenView) handleKey(msg tea.KeyMsg) (View, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		line := strings.TrimSpace(v.input.Value())
+		v.input.Reset()
+		if line == "" {
+			return v, nil
+		}
+		return v, v.run(line)
+	case "ctrl+l":
+		v.lines, v.received = nil, 0
+		v.refresh()
+	case "ctrl+k":
+		v.viewport.ScrollUp(1)
+	case "ctrl+j":
+		v.viewport.ScrollDown(1)
+	case "pgup":
+		v.viewport.PageUp()
+	case "pgdown":
+		v.viewport.PageDown()
+	default:
+		v.input.Update(msg)
+	}
+	return v, nil
+}
+
+// run runs a typed command.
+func (v *ListenView) run(line string) tea.Cmd {
+	fields := strings.Fields(line)
+	op := strings.ToLower(fields[0])
+	args := fields[1:]
+	switch {
+	case op == "notify" && len(args) > 0:
+		channel := args[0]
+		_, payload, _ := strings.Cut(strings.TrimSpace(line[len(fields[0]):]), channel)
+		payload = strings.TrimSpace(payload)
+		database := v.db
+		return func() tea.Msg {
+			err := database.Notify(context.Background(), channel, payload)
+			return ListenOpMsg{Op: op, Channel: channel, Err: err}
+		}
+
+	case (op == "listen" || op == "unlisten") && len(args) > 0:
+		if v.listener == nil {
+			v.pending = append(v.pending, line)
+			if v.connecting {
+				return nil
+			}
+			v.connecting = true
+			database := v.db
+			return func() tea.Msg {
+				l, err := database.NewListener(context.Background())
+				return ListenerReadyMsg{Listener: l, Err: err}
+			}
+		}
+		v.stopWait()
+		l := v.listener
+		var cmds []tea.Cmd
+		for _, channel := range args {
+			v.ops++
+			cmds = append(cmds, func() tea.Msg {
+				var err error
+				if op == "listen" {
+					err = l.Listen(context.Background(), channel)
+				} else {
+					err = l.Unlisten(context.Background(), channel)
+				}
+				return ListenOpMsg{Op: op, Channel: channel, Err: err}
+			})
+		}
+		return tea.Batch(cmds...)
+	}
+
+	v.appendLine(StyleError.Render("Unknown command: " + line))
+	v.appendLine(StyleDimmed.Render("Use listen <channel>, unlisten <channel>|* or notify <channel> [payload]."))
+	return nil
+}
+
+// startWait waits for the next notification unless a wait or a statement
+// is already using the connection.
+func (v *ListenView) startWait() tea.Cmd {
+	if v.listener == nil || v.waitCancel != nil || v.ops > 0 {
+		return nil
+	}
+	v.waitGen++
+	gen := v.waitGen
+	ctx, cancel := context.WithCancel(context.Background())
+	v.waitCancel = cancel
+	l := v.listener
+	return func() tea.Msg {
+		n, err := l.Wait(ctx)
+		return NotificationMsg{Gen: gen, Notification: n, Err: err}
+	}
+}
+
+// stopWait cancels the running wait so a statement can use the connection.
+func (v *ListenView) stopWait() {
+	if v.waitCancel != nil {
+		v.waitCancel()
+		v.waitCancel = nil
+		v.waitGen++
+	}
+}
+
+// formatNotification renders a notification as one line of the stream.
+func formatNotification(n *db.Notification) string {
+	payload := strings.ReplaceAll(n.Payload, "\n", "↵")
+	if payload == "" {
+		payload = StyleDimmed.Render("(no payload)")
+	}
+	return StyleDimmed.Render(n.At.Format("15:04:05.000")) + "  " +
+		StyleBold.Render(n.Channel) + "  " +
+		StyleDimmed.Render(fmt.Sprintf("pid %d", n.PID)) + "  " + payload
+}
+
+// appendLine adds a line to the stream and scrolls to it.
+func (v *ListenView) appendLine(line string) {
+	v.lines = append(v.lines, line)
+	if n := len(v.lines) - maxListenLines; n > 0 {
+		v.lines = v.lines[n:]
+	}
+	v.refresh()
+}
+
+// refresh redraws the stream and scrolls to its end.
+func (v *ListenView) refresh() {
+	if len(v.lines) == 0 {
+		v.viewport.SetContentLines([]string{
+			StyleDimmed.Render("No notifications yet."),
+			"",
+			StyleDimmed.Render("Type listen <channel> to subscribe, notify <channel> [payload] to send."),
+		})
+		return
+	}
+	v.viewport.SetContentLines(v.lines)
+	v.viewport.End()
+}
+
+func (v *ListenView) View() string {
+	header := StyleTitle.UnsetMarginBottom().Render("📡 LISTEN/NOTIFY")
+	switch {
+	case len(v.channels) > 0:
+		header += StyleDimmed.Render(fmt.Sprintf("  on %s · %d received",
+			strings.Join(v.channels, ", "), v.received))
+	case v.connecting:
+		header += StyleDimmed.Render("  connecting...")
+	default:
+		header += StyleDimmed.Render("  not listening")
+	}
+	prompt := StylePrompt.Render("Listen> ") + v.input.View()
+	return lipgloss.JoinVertical(lipgloss.Left, header, prompt, "", v.viewport.Render())
+}
@@ -0,0 +1,229 @@
+// view_locks.go — Lock waits view.
+//
+// Shows the blocking chains of the server as trees: each session holding
+// others up, with the sessions waiting on it indented below, what lock
+// they wait for and for how long. Refreshes every few seconds like the
+// Log view; the root of a tree is usually the one to look at (an idle
+// transaction left open, a long migration).
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/DachengChen/paiSQL/db"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const locksRefreshInterval = 2 * time.Second
+
+// locksTickMsg triggers a refresh of generation gen.
+type locksTickMsg struct{ gen int }
+
+type LocksView struct {
+	db       *db.DB
+	viewport *Viewport
+	paused   bool
+	width    int
+	height   int
+	tasks    viewTasks
+	ticks    int // generation of the refresh loop; older ticks are dropped
+	sessions []db.LockSession
+	at       time.Time
+	err      error
+}
+
+func NewLocksView(database *db.DB) *LocksView {
+	return &LocksView{
+		db:       database,
+		viewport: NewViewport(80, 20),
+	}
+}
+
+func (v *LocksView) Name() string         { return "Locks" }
+func (v *LocksView) WantsTextInput() bool { return false }
+
+func (v *LocksView) SetSize(width, height int) {
+	v.width = width
+	v.height = height
+	v.viewport.SetSize(width-2, height-2)
+	v.render()
+}
+
+func (v *LocksView) ShortHelp() []KeyBinding {
+	pause := "pause"
+	if v.paused {
+		pause = "resume"
+	}
+	return []KeyBinding{
+		{Key: "p", Desc: pause},
+		{Key: "Ctrl+K/J", Desc: "scroll"},
+	}
+}
+
+func (v *LocksView) FullHelp() []KeyGroup {
+	return []KeyGroup{{Title: "Locks", Bindings: []KeyBinding{
+		{Key: "p", Desc: "pause/resume refreshing"},
+		{Key: "Ctrl+K/J", Desc: "scroll"},
+		{Key: "PgUp/PgDn", Desc: "page"},
+	}}}
+}
+
+func (v *LocksView) Init() tea.Cmd {
+	v.ticks++
+	return tea.Batch(v.fetch(), v.tick())
+}
+
+// Leave stops refreshing until the view is entered again.
+func (v *LocksView) Leave() {
+	v.ticks++
+	v.tasks.stop()
+}
+
+func (v *LocksView) tick() tea.Cmd {
+	gen := v.ticks
+	return tea.Tick(locksRefreshInterval, func(time.Time) tea.Msg { return locksTickMsg{gen: gen} })
+}
+
+func (v *LocksView) Update(msg tea.Msg) (View, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		return v.handleKey(msg)
+	case locksTickMsg:
+		if msg.gen != v.ticks {
+			return v, nil
+		}
+		if !v.paused {
+			return v, tea.Batch(v.fetch(), v.tick())
+		}
+		return v, v.tick()
+	case LockSessionsMsg:
+		if errors.Is(msg.Err, context.Canceled) {
+			return v, nil
+		}
+		v.err = msg.Err
+		if msg.Err == nil {
+			v.sessions, v.at = msg.Sessions, msg.At
+		}
+		v.render()
+		return v, nil
+	}
+	return v, nil
+}
+
+func (v *LocksView) handleKey(msg tea.KeyMsg) (View, tea.Cmd) {
+	switch msg.String() {
+	case "p":
+		v.paused = !v.paused
+		v.render()
+	case "ctrl+k":
+		v.viewport.ScrollUp(1)
+	case "ctrl+j":
+		v.viewport.ScrollDown(1)
+	case "pgup":
+		v.viewport.PageUp()
+	case "pgdown":
+		v.viewport.PageDown()
+	}
+	return v, nil
+}
+
+func (v *LocksView) fetch() tea.Cmd {
+	ctx := v.tasks.restart()
+	database := v.db
+	return func() tea.Msg {
+		sessions, err := database.FetchLockSessions(ctx)
+		return LockSessionsMsg{Sessions: sessions, At: time.Now(), Err: err}
+	}
+}
+
+// render draws the blocking trees.
+func (v *LocksView) render() {
+	status := "refreshing every " + locksRefreshInterval.String()
+	if v.paused {
+		status = "paused"
+	}
+	if !v.at.IsZero() {
+		status += " · " + v.at.Format("15:04:05")
+	}
+	lines := []string{StyleTitle.Render("🔒 Lock Waits"), "", StyleDimmed.Render("  " + status), ""}
+	if v.err != nil {
+		lines = append(lines, StyleError.Render("  ERROR: "+v.err.Error()), "")
+	}
+	if len(v.sessions) == 0 {
+		if v.err == nil && !v.at.IsZero() {
+			lines = append(lines, StyleSuccess.Render("  ✓ No session is waiting for a lock"))
+		}
+		v.viewport.SetContentLines(lines)
+		return
+	}
+
+	waiting := 0
+	for _, s := range v.sessions {
+		if len(s.BlockedBy) > 0 {
+			waiting++
+		}
+	}
+	lines = append(lines, StyleWarning.Render(fmt.Sprintf("  %d sessions waiting", waiting)), "")
+
+	// Roots block others without waiting themselves; sessions left over
+	// after them wait in a cycle (a deadlock about to be detected).
+	shown := map[int]bool{}
+	for _, s := range v.sessions {
+		if len(s.BlockedBy) == 0 {
+			lines = v.appendLockTree(lines, s, 0, shown)
+		}
+	}
+	for _, s := range v.sessions {
+		if !shown[s.PID] {
+			lines = v.appendLockTree(lines, s, 0, shown)
+		}
+	}
+	lines = append(lines, StyleDimmed.Render("  Each session blocks those indented below it; pg_terminate_backend(pid) ends one"))
+	v.viewport.SetContentLines(lines)
+}
+
+// appendLockTree appends s and, indented, the sessions it blocks.
+func (v *LocksView) appendLockTree(lines []string, s db.LockSession, depth int, shown map[int]bool) []string {
+	shown[s.PID] = true
+	indent := strings.Repeat("   ", depth)
+	marker := StyleError.Render("●")
+	if depth > 0 {
+		marker = "└─"
+	}
+	line := fmt.Sprintf("  %s%s %d %s", indent, marker, s.PID, s.User)
+	if s.App != "" {
+		line += " (" + s.App + ")"
+	}
+	line += "  " + s.State
+	if s.XactAge > 0 {
+		line += StyleDimmed.Render("  xact " + s.XactAge.Round(time.Second).String())
+	}
+	if s.WaitsFor != "" {
+		line += StyleWarning.Render(fmt.Sprintf("  waiting %s for %s", s.QueryAge.Round(time.Second), s.WaitsFor))
+	}
+	if len(s.BlockedBy) > 1 {
+		line += StyleDimmed.Render(fmt.Sprintf("  (blocked by %d sessions)", len(s.BlockedBy)))
+	}
+	lines = append(lines, line)
+	if s.Query != "" {
+		lines = append(lines, "  "+indent+"     "+StyleDimmed.Render(truncateRunes(s.Query, max(v.width-12-len(indent), 20))))
+	}
+	for _, w := range v.sessions {
+		if !shown[w.PID] && slices.Contains(w.BlockedBy, int32(s.PID)) {
+			lines = v.appendLockTree(lines, w, depth+1, shown)
+		}
+	}
+	if depth == 0 {
+		lines = append(lines, "")
+	}
+	return lines
+}
+
+func (v *LocksView) View() string {
+	return v.viewport.Render()
+}
@@ -6,6 +6,7 @@ package tui
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -25,6 +26,8 @@ type LogView struct {
 	loading  bool
 	width    int
 	height   int
+	tasks    viewTasks
+	ticks    int // generation of the refresh loop; older ticks are dropped
 }
 
 func NewLogView(database *db.DB) *LogView {
@@ -37,6 +40,9 @@ func NewLogView(database *db.DB) *LogView {
 func (v *LogView) Name() string         { return "Log" }
 func (v *LogView) WantsTextInput() bool { return false }
 
+// SearchViewport lets / search the log.
+func (v *LogView) SearchViewport() *Viewport { return v.viewport }
+
 func (v *LogView) SetSize(width, height int) {
 	v.width = width
 	v.height = height
@@ -51,20 +57,39 @@ func (v *LogView) ShortHelp() []KeyBinding {
 	return []KeyBinding{
 		{Key: "p", Desc: pause},
 		{Key: "c", Desc: "clear"},
-		{Key: "↑/↓", Desc: "scroll"},
+		{Key: "Ctrl+K/J", Desc: "scroll"},
 	}
 }
 
-// tickMsg triggers periodic refresh.
-type tickMsg time.Time
+func (v *LogView) FullHelp() []KeyGroup {
+	return []KeyGroup{{Title: "Log", Bindings: []KeyBinding{
+		{Key: "p", Desc: "pause/resume streaming"},
+		{Key: "c", Desc: "clear"},
+		{Key: "Ctrl+K/J", Desc: "scroll"},
+		{Key: "PgUp/PgDn", Desc: "page"},
+		{Key: "Home/End", Desc: "top/bottom"},
+	}}}
+}
+
+// tickMsg triggers periodic refresh. gen ties it to the refresh loop
+// started by the latest Init, so re-entering the view doesn't add loops.
+type tickMsg struct{ gen int }
 
 func (v *LogView) Init() tea.Cmd {
+	v.ticks++
 	return tea.Batch(v.fetchLog(), v.tick())
 }
 
+// Leave stops polling until the view is entered again.
+func (v *LogView) Leave() {
+	v.ticks++
+	v.tasks.stop()
+}
+
 func (v *LogView) tick() tea.Cmd {
-	return tea.Tick(logRefreshInterval, func(t time.Time) tea.Msg {
-		return tickMsg(t)
+	gen := v.ticks
+	return tea.Tick(logRefreshInterval, func(time.Time) tea.Msg {
+		return tickMsg{gen: gen}
 	})
 }
 
@@ -74,12 +99,18 @@ func (v *LogView) Update(msg tea.Msg) (View, tea.Cmd) {
 		return v.handleKey(msg)
 
 	case tickMsg:
+		if msg.gen != v.ticks {
+			return v, nil
+		}
 		if !v.paused {
 			return v, tea.Batch(v.fetchLog(), v.tick())
 		}
 		return v, v.tick()
 
 	case LogMsg:
+		if errors.Is(msg.Err, context.Canceled) {
+			return v, nil
+		}
 		v.loading = false
 		if msg.Err != nil {
 			v.lines = append(v.lines, StyleError.Render("ERROR: "+msg.Err.Error()))
@@ -124,8 +155,8 @@ func (v *LogView) handleKey(msg tea.KeyMsg) (View, tea.Cmd) {
 
 func (v *LogView) fetchLog() tea.Cmd {
 	v.loading = true
+	ctx := v.tasks.restart()
 	return func() tea.Msg {
-		ctx := context.Background()
 
 		rows, err := v.db.Pool.Query(ctx, `
 			SELECT pid, usename, state,
@@ -4,23 +4,39 @@
 //   - Text input for SQL queries
 //   - Async query execution (never blocks UI)
 //   - Results rendered as a table with scrolling
-//   - Meta-commands: \dt \di \dv \d <table> \set
+//   - Meta-commands: \dt \di \dv \d <table> \set \pset \x \t \knn \geojson \xlsx
+//     \export
 //   - Variable substitution via db.Variables
 package tui
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"math"
+	"os"
 	"os/exec"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 	"unicode/utf8"
 
 	"github.com/DachengChen/paiSQL/ai"
+	"github.com/DachengChen/paiSQL/config"
 	"github.com/DachengChen/paiSQL/db"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/jackc/pgx/v5"
 )
 
+// maxQueuedQueries caps how many submissions wait behind a running one.
+const maxQueuedQueries = 5
+
+// widthSampleRows is how many rows are measured to size grid columns.
+const widthSampleRows = 500
+
 const (
 	focusSidebar = iota
 	focusResults
@@ -43,9 +59,10 @@ type MainView struct {
 	db       *db.DB
 	vars     *db.Variables
 	viewport *Viewport
-	input    string
-	history  []string
+	input    textInput
+	history  []config.HistoryEntry // newest first
 	histIdx  int
+	draft    string // what was typed before ↑ recalled a statement
 	result   *db.QueryResult
 	err      error
 	loading  bool
@@ -53,37 +70,173 @@ type MainView struct {
 	height   int
 
 	// Split view state
-	tables    []string
-	tableRows []int64 // estimated row counts per table
-	tableIdx  int
-	focus     int
-	tableErr  error
+	tables       []string // display names, schema-qualified when ambiguous
+	tableSchemas []string // schema of each table
+	tableRows    []int64  // estimated row counts per table
+	tableTypes   []string // "table" or "hypertable" per table
+	tableIdx     int
+	focus        int
+	tableErr     error
+
+	tableTasks viewTasks // in-flight table list refresh
+	queryTasks viewTasks // the running statement or result page; Ctrl+X cancels it
+	cancelled  bool      // Ctrl+X was pressed for the running statement
+
+	// Table actions menu (a) and the action it started, if still running
+	maint    *maintMenu
+	maintRun *maintRun
+	maintGen int
+
+	// Whole-result CSV export started by \export, if still running
+	exportRun *exportRun
+	exportGen int
+
+	// Column wizard (A), create index form (I), column picker (C), group
+	// counts (p), referencing tables (r) and the sequence inspector
+	// (\sequences)
+	alter     *alterWizard
+	index     *indexWizard
+	picker    *columnPicker
+	groups    *groupSummary
+	refs      *referencedBy
+	sequences *sequenceList
+
+	// Cell editor (e in the results while browsing a table), and the
+	// new row form and delete confirmation (i and D)
+	cellEdit *cellEdit
+	rowForm  *rowForm
+
+	// Saved statement history, the statement whose outcome it waits for
+	// and the Ctrl+R search over it
+	histFile   *config.History
+	histRun    *historyRun
+	histSearch *historySearch
+
+	// Completion popup over the SQL input and the columns it has read
+	complete *completion
+	columns  tableColumns
+
+	// Saved browsing preferences of this connection's tables
+	tablePrefs *config.TablePrefs
+
+	// Saved connection name ("" when unnamed), for the connection banner,
+	// which is fetched on the first Init only
+	connName    string
+	bannerShown bool
+	warnings    []db.SanityWarning // found after connecting, or by \warnings
+
+	// Table to browse on the first Init (--table), in place of the banner
+	startTable string
 
 	// Pagination state
 	pagTable    string // current paginated table name
 	pagPage     int    // current page (0-based)
 	pagPageSize int    // rows per page
 	pagTotal    int64  // total rows in table
+	pagPlan     bool   // the result is lastQueryPlan's; pages regenerate its SQL
+	gotoPending int    // row \goto scrolls to once its page arrives (1-based, 0 = none)
+
+	// Row key of pagKeyTable, looked up with its first page (nil for a
+	// view), and the ctids of the page's rows when the key is ctid, which
+	// pages then fetch as a hidden first column
+	pagKey      *db.RowKey
+	pagKeyTable string
+	pagCtids    []string
+
+	// Quick filters of the browsed table (f/F), and the grid column they
+	// take their value from (< and >)
+	quickFilters []quickFilter
+	focusCol     int
+
+	// Auto-refresh of the browsed table (R, \refresh)
+	refresh    *browseRefresh
+	refreshGen int
 
 	// Right pane mode
 	rightMode    int  // rightModeData or rightModeDescribe
 	expandedMode bool // vertical display like \x in psql
+	chartMode    bool // quick chart of the current result instead of the grid
+	chartSort    int  // bar chart sort order (barSortValueDesc, ...)
+	showTypes    bool // show each column's type under its header
+	measureAll   bool // size columns from every row, not just widthSampleRows
+	rowNumbers   bool // number the grid's rows, counting from the first page
+
+	// Expanded display: the line of each record within the rendered
+	// result, and the line the result starts at in the viewport
+	records      []int
+	resultOffset int
+
+	// Grid display: where each column sits in a line and the line width
+	gridCols  []gridSpan
+	gridWidth int
+
+	// Result formatting, initialized from config and changed by \pset
+	display   config.DisplayConfig
+	printOpts printOptions // psql-style border, null, format, tuples_only
+
+	// Keyword case and identifier quoting of generated SQL
+	sqlStyle config.SQLStyleConfig
 
 	// Chat mode state
 	inputMode    int // inputModeChat or inputModeSQL
 	aiProvider   ai.Provider
-	chatInput    string
+	appConfig    *config.AppConfig
+	chatInput    textInput
+	chatHistory  []string // questions sent, newest first
+	chatHistIdx  int      // question shown by ↑/↓, -1 for the draft
+	chatDraft    string   // what was typed before ↑ recalled a question
 	chatMessages []ai.Message
 	chatLoading  bool
+	chatPartial  string // streamed part of the pending reply
 
 	// Query plan state — tracks the last AI-generated plan for pagination
 	lastQueryPlan *ai.QueryPlan
 	planSortCol   string // saved sort column from last plan
 	planSortOrder string // saved sort order from last plan
+	lastQuestion  string // natural-language question behind the last plan
+
+	// Cached whole-schema column index for semantic column search and
+	// schema retrieval
+	schemaIndex *db.SchemaIndex
+	retrieved   []string // tables retrieved for the current question (schema_retrieval.go)
 
 	// Modification query workflow
-	pendingSQL    string // SQL from a modification plan, waiting to be pasted
-	inTransaction bool   // true after BEGIN is executed
+	pendingSQL string // SQL from a modification plan, waiting to be pasted
+
+	// Transaction opened by \begin (transaction.go), when it was opened
+	// and whether a statement in it failed
+	tx        pgx.Tx
+	txStart   time.Time
+	txAborted bool
+
+	// postgres_fdw setup generated by \fdw, waiting for \fdw apply
+	pendingFDW *db.FDWPlan
+
+	// Fake rows generated by \seed, waiting for \seed apply
+	pendingSeed *db.SeedData
+
+	// Script generated by \upsert, waiting for \upsert apply
+	upsertScript *pendingUpsert
+
+	// Recipe started by \recipe, shown over the results until closed
+	recipe *recipeRun
+
+	// Statements run on an interval by \every, and the pane of their runs
+	watches  []*watch
+	watchSeq int
+	watchLog []string
+
+	// Migration checked by \review, waiting for \i
+	reviewed *reviewedMigration
+
+	// Submissions made while a statement was running, oldest first
+	queue []string
+	// Browsed page to fetch once the statement running in the transaction ends
+	pageQueued bool
+
+	// Latest request IDs; responses carrying an older ID are stale
+	resultReq int // QueryResultMsg and DescribeResultMsg
+	chatReq   int // AIResponseMsg
 
 	// Last executed SQL for copy feature
 	lastSQL string
@@ -92,23 +245,61 @@ type MainView struct {
 	fullscreen bool
 }
 
-func NewMainView(database *db.DB, provider ai.Provider) *MainView {
-	return &MainView{
-		db:         database,
-		vars:       db.NewVariables(),
-		viewport:   NewViewport(80, 20),
-		histIdx:    -1,
-		focus:      focusSidebar,
-		aiProvider: provider,
-	}
+func NewMainView(database *db.DB, provider ai.Provider, appCfg *config.AppConfig, connName string) *MainView {
+	v := &MainView{
+		db:          database,
+		vars:        db.NewVariables(),
+		viewport:    NewViewport(80, 20),
+		histIdx:     -1,
+		chatHistIdx: -1,
+		focus:       focusSidebar,
+		aiProvider:  provider,
+		appConfig:   appCfg,
+		printOpts:   defaultPrintOptions(),
+		columns:     tableColumns{},
+	}
+	if appCfg != nil {
+		v.display = appCfg.Display
+		v.sqlStyle = appCfg.SQLStyle
+	}
+	v.connName = connName
+	v.tablePrefs = loadTablePrefs(connName)
+	v.histFile, v.history = loadHistory(connName)
+	return v
 }
 
 func (v *MainView) Name() string { return "Main" }
 
 func (v *MainView) WantsTextInput() bool {
+	if m := v.maint; m != nil {
+		return m.step == maintConfirm && m.plan.Action.Destructive
+	}
+	if v.alter != nil {
+		return v.alter.step == alterInput
+	}
+	if v.index != nil {
+		return v.index.step == indexEditing
+	}
+	if v.cellEdit != nil {
+		return v.cellEdit.step == cellEditInput
+	}
+	if v.rowForm != nil {
+		return v.rowForm.step == rowFormInput
+	}
 	return v.inputMode == inputModeChat || v.focus == focusInput
 }
 
+// SearchViewport lets / search the results pane while it has the focus
+// and no dialog is open over it.
+func (v *MainView) SearchViewport() *Viewport {
+	if v.focus != focusResults || v.maint != nil || v.alter != nil || v.index != nil ||
+		v.picker != nil || v.groups != nil || v.refs != nil || v.sequences != nil || v.recipe != nil ||
+		v.cellEdit != nil || v.rowForm != nil {
+		return nil
+	}
+	return v.viewport
+}
+
 func (v *MainView) SetSize(width, height int) {
 	v.width = width
 	v.height = height
@@ -122,6 +313,92 @@ func (v *MainView) ShortHelp() []KeyBinding {
 	toggle := KeyBinding{Key: "F2", Desc: modeLabel}
 	fs := KeyBinding{Key: "F5", Desc: "fullscreen"}
 
+	if v.recipe != nil {
+		return []KeyBinding{
+			{Key: "y/n", Desc: "confirm step"},
+			{Key: "Esc", Desc: "stop/close"},
+		}
+	}
+	if v.cellEdit != nil {
+		return []KeyBinding{
+			{Key: "Enter", Desc: "review"},
+			{Key: "Ctrl+N", Desc: "NULL"},
+			{Key: "y", Desc: "run"},
+			{Key: "Esc", Desc: "back"},
+		}
+	}
+	if f := v.rowForm; f != nil {
+		if f.step == rowFormInput {
+			return []KeyBinding{
+				{Key: "↑/↓", Desc: "field"},
+				{Key: "Enter", Desc: "review"},
+				{Key: "Ctrl+N", Desc: "NULL"},
+				{Key: "Ctrl+U", Desc: "default"},
+				{Key: "Esc", Desc: "cancel"},
+			}
+		}
+		return []KeyBinding{
+			{Key: "y", Desc: "run"},
+			{Key: "Esc", Desc: "back"},
+		}
+	}
+	if v.sequences != nil {
+		return []KeyBinding{
+			{Key: "↑/↓", Desc: "sequence"},
+			{Key: "Enter", Desc: "review fix"},
+			{Key: "r", Desc: "re-check"},
+			{Key: "Esc", Desc: "close"},
+		}
+	}
+	if v.refs != nil {
+		return []KeyBinding{
+			{Key: "↑/↓", Desc: "foreign key"},
+			{Key: "Enter", Desc: "browse child rows"},
+			{Key: "Esc", Desc: "close"},
+		}
+	}
+	if v.groups != nil {
+		return []KeyBinding{
+			{Key: "↑/↓", Desc: "group"},
+			{Key: "Enter", Desc: "browse group"},
+			{Key: "Esc", Desc: "close"},
+		}
+	}
+	if v.picker != nil {
+		return []KeyBinding{
+			{Key: "Space", Desc: "show/hide"},
+			{Key: "K/J", Desc: "move"},
+			{Key: "a", Desc: "all/none"},
+			{Key: "Enter", Desc: "apply"},
+			{Key: "Esc", Desc: "close"},
+		}
+	}
+	if v.index != nil {
+		return []KeyBinding{
+			{Key: "Space", Desc: "key column"},
+			{Key: "o/p/i", Desc: "order/opclass/include"},
+			{Key: "Enter", Desc: "next"},
+			{Key: "y", Desc: "create"},
+			{Key: "Esc", Desc: "back"},
+		}
+	}
+	if v.alter != nil {
+		return []KeyBinding{
+			{Key: "↑/↓", Desc: "select"},
+			{Key: "Enter", Desc: "next"},
+			{Key: "y", Desc: "run"},
+			{Key: "Esc", Desc: "back"},
+		}
+	}
+	if v.maint != nil {
+		return []KeyBinding{
+			{Key: "↑/↓", Desc: "select"},
+			{Key: "Enter", Desc: "choose/run"},
+			{Key: "x", Desc: "cancel running"},
+			{Key: "Esc", Desc: "back/close"},
+		}
+	}
+
 	if v.focus == focusSidebar {
 		return []KeyBinding{
 			toggle,
@@ -129,9 +406,23 @@ func (v *MainView) ShortHelp() []KeyBinding {
 			{Key: "↑/↓", Desc: "navigate"},
 			{Key: "Enter", Desc: "data"},
 			{Key: "d", Desc: "describe"},
+			{Key: "a", Desc: "actions"},
+			{Key: "A", Desc: "alter column"},
+			{Key: "I", Desc: "create index"},
+			{Key: "C", Desc: "columns"},
 			{Key: "F3/F4", Desc: "prev/next pane"},
 		}
 	} else if v.focus == focusResults {
+		if v.chartMode {
+			return []KeyBinding{
+				toggle,
+				fs,
+				{Key: "↑/↓", Desc: "scroll"},
+				{Key: "g", Desc: "table"},
+				{Key: "s", Desc: "sort bars"},
+				{Key: "F3/F4", Desc: "prev/next pane"},
+			}
+		}
 		return []KeyBinding{
 			toggle,
 			fs,
@@ -139,6 +430,9 @@ func (v *MainView) ShortHelp() []KeyBinding {
 			{Key: "←/→", Desc: "pan"},
 			{Key: "[/]", Desc: "record"},
 			{Key: "x", Desc: "expand"},
+			{Key: "t", Desc: "types"},
+			{Key: "m", Desc: "re-measure"},
+			{Key: "g", Desc: "chart"},
 			{Key: "c", Desc: "copy SQL"},
 			{Key: "F3/F4", Desc: "prev/next pane"},
 		}
@@ -148,64 +442,352 @@ func (v *MainView) ShortHelp() []KeyBinding {
 		return []KeyBinding{
 			toggle,
 			{Key: "Enter", Desc: "send"},
+			{Key: "Alt+Enter", Desc: "new line"},
+			{Key: "↑/↓", Desc: "history"},
 			{Key: "Ctrl+L", Desc: "clear chat"},
 		}
 	}
+	if v.loading {
+		return []KeyBinding{
+			toggle,
+			{Key: "Ctrl+X", Desc: "cancel query"},
+			{Key: "Enter", Desc: "queue"},
+			{Key: "F3/F4", Desc: "prev/next pane"},
+		}
+	}
 	return []KeyBinding{
 		toggle,
 		{Key: "Enter", Desc: "execute"},
 		{Key: "Tab", Desc: "autocomplete"},
 		{Key: "F3/F4", Desc: "prev/next pane"},
 		{Key: "↑/↓", Desc: "history"},
+		{Key: "Ctrl+R", Desc: "search history"},
+	}
+}
+
+func (v *MainView) FullHelp() []KeyGroup {
+	return []KeyGroup{
+		{Title: "Panes", Bindings: []KeyBinding{
+			{Key: "F2", Desc: "toggle input between SQL and Chat"},
+			{Key: "F3/F4", Desc: "previous/next pane (also Shift+Tab/Tab outside SQL input)"},
+			{Key: "F5", Desc: "fullscreen"},
+			{Key: "c", Desc: "copy last SQL (outside the input)"},
+		}},
+		{Title: "Tables", Bindings: []KeyBinding{
+			{Key: "↑/↓", Desc: "select table (also k/j)"},
+			{Key: "PgUp/PgDn", Desc: "page through tables"},
+			{Key: "Home/End", Desc: "first/last table"},
+			{Key: "Enter", Desc: "browse data"},
+			{Key: "d", Desc: "describe table"},
+			{Key: "D", Desc: "dependent views and DROP … CASCADE preview"},
+			{Key: "a", Desc: "actions: ANALYZE, VACUUM, REINDEX, CLUSTER, TRUNCATE, DROP"},
+			{Key: "A", Desc: "alter a column: rename, type, NOT NULL, default"},
+			{Key: "I", Desc: "create an index"},
+			{Key: "C", Desc: "choose and order the columns to browse (also in the results)"},
+		}},
+		{Title: "Columns", Bindings: []KeyBinding{
+			{Key: "Space", Desc: "show/hide the column"},
+			{Key: "K/J", Desc: "move the column up/down (also Shift+↑/↓)"},
+			{Key: "a", Desc: "show all / none"},
+			{Key: "r", Desc: "reset to every column in table order"},
+			{Key: "Enter", Desc: "apply; saved per table for the next time it is browsed"},
+		}},
+		{Title: "Create index", Bindings: []KeyBinding{
+			{Key: "Space", Desc: "add/remove key column (in the order picked)"},
+			{Key: "o", Desc: "toggle ASC/DESC of a key column"},
+			{Key: "p", Desc: "set a key column's operator class"},
+			{Key: "i", Desc: "add/remove INCLUDE column"},
+			{Key: "Enter", Desc: "options: name, method, UNIQUE, CONCURRENTLY, WHERE; then preview"},
+			{Key: "y", Desc: "create (progress as for table actions)"},
+		}},
+		{Title: "Table actions", Bindings: []KeyBinding{
+			{Key: "↑/↓", Desc: "select action"},
+			{Key: "Enter", Desc: "choose, then run (destructive actions: type the table name)"},
+			{Key: "x", Desc: "cancel the running action"},
+			{Key: "Esc", Desc: "back, or hide progress (the action keeps running)"},
+		}},
+		{Title: "Results", Bindings: []KeyBinding{
+			{Key: "↑/↓", Desc: "scroll (also k/j)"},
+			{Key: "←/→", Desc: "pan (also h/l)"},
+			{Key: "Ctrl+H/L", Desc: "pan faster"},
+			{Key: "[/]", Desc: "previous/next record"},
+			{Key: "PgUp/PgDn", Desc: "page (previous/next data page when browsing a table or AI result)"},
+			{Key: "Home/End", Desc: "top/bottom"},
+			{Key: "w", Desc: "toggle wrapping"},
+			{Key: "x", Desc: "toggle expanded display"},
+			{Key: "t", Desc: "toggle column types"},
+			{Key: "n", Desc: "toggle row numbers (\\goto N jumps to a row)"},
+			{Key: "M/H", Desc: "copy the result as a Markdown/HTML table"},
+			{Key: "m", Desc: "re-measure column widths"},
+			{Key: "g", Desc: "toggle chart"},
+			{Key: "s", Desc: "cycle bar chart sort"},
+			{Key: "</>", Desc: "focus the previous/next column, panning it into view (shown under the grid)"},
+			{Key: "f/F", Desc: "browsing a table: only rows with / without the focused value"},
+			{Key: "u", Desc: "drop the last quick filter (\\filter lists, \\filter drop N, \\filter clear)"},
+			{Key: "p", Desc: "browsing a table: row counts per value of the focused column"},
+			{Key: "\\dupes", Desc: "browsing a table: duplicate values of the focused column (\\dupes a, b for a combination)"},
+			{Key: "r", Desc: "browsing a table: rows of other tables referencing the focused row"},
+			{Key: "e", Desc: "browsing a table: edit the focused cell (UPDATE by primary key, shown before it runs)"},
+			{Key: "i", Desc: "browsing a table: add a row (a field per column; empty fields get their defaults)"},
+			{Key: "D", Desc: "browsing a table: delete the focused row (DELETE by primary key, shown before it runs)"},
+			{Key: "R", Desc: "browsing a table: refresh the page every 5s (\\refresh 10s sets the interval)"},
+		}},
+		{Title: "SQL input", Bindings: []KeyBinding{
+			{Key: "Enter", Desc: "execute (queued if a statement is running)"},
+			{Key: "Ctrl+X", Desc: "cancel the running statement (and drop the queued ones)"},
+			{Key: "Tab", Desc: "complete a column, table or keyword (Ctrl+Space too; ↑/↓ and Tab pick)"},
+			{Key: "Ctrl+F", Desc: "format the SQL (\\fmt formats the last statement)"},
+			{Key: "↑/↓", Desc: "history (kept per connection in ~/.paisql/history/)"},
+			{Key: "Ctrl+R", Desc: "search the history; Ctrl+R again for older, Enter runs, Tab edits, Esc cancels"},
+			{Key: "\\dt \\d", Desc: "list tables"},
+			{Key: "\\pset \\x \\t", Desc: "display options"},
+			{Key: "\\set", Desc: "set a variable"},
+			{Key: "\\goto", Desc: "scroll the result to row N (fetching its page)"},
+			{Key: "\\sort", Desc: "sort the browsed table, e.g. \\sort created_at desc (saved; off clears)"},
+			{Key: "\\sequences [schema]", Desc: "sequences behind their column or near overflow, with a setval fix"},
+			{Key: "\\warnings [n]", Desc: "re-run the connection sanity checks, or explain warning n"},
+			{Key: "\\begin", Desc: "open a transaction; statements run in it until \\commit or \\rollback (header shows TX OPEN)"},
+			{Key: "\\search_path", Desc: "show the schemas searched"},
+			{Key: "\\deallocate all", Desc: "drop cached prepared statements (after schema changes)"},
+			{Key: "\\deps", Desc: "dependencies of a table or view"},
+			{Key: "\\knn \\geojson", Desc: "vector search / GeoJSON export"},
+			{Key: "\\xlsx", Desc: "export the result as an Excel workbook"},
+			{Key: "\\export <file>", Desc: "stream every row of the result to CSV"},
+			{Key: "\\export <file> split <size>", Desc: "export in chunks (.gz/.zst compress)"},
+			{Key: "\\fdw \\seed", Desc: "postgres_fdw setup / fake data"},
+			{Key: "\\upsert", Desc: "copy the result into a table of another connection"},
+			{Key: "\\recipe", Desc: "run a saved multi-step recipe (alone: list them)"},
+			{Key: "\\every", Desc: "run SQL on an interval, e.g. \\every 5m <sql> (stop: \\every stop)"},
+			{Key: "\\review", Desc: "AI review of a migration (file or pasted SQL)"},
+			{Key: "\\i", Desc: "run a SQL file, or the reviewed migration"},
+		}},
+		{Title: "Chat input", Bindings: []KeyBinding{
+			{Key: "Enter", Desc: "send"},
+			{Key: "Alt+Enter", Desc: "new line (Ctrl+J too; Shift+Enter where the terminal sends one of them)"},
+			{Key: "↑/↓", Desc: "questions sent before; ↓ past the newest brings the draft back"},
+			{Key: "Ctrl+L", Desc: "clear conversation (the draft is kept)"},
+		}},
+		{Title: "Editing", Bindings: []KeyBinding{
+			{Key: "←/→", Desc: "move the cursor (Alt or Ctrl: by word; Home/End, Ctrl+A/E: line)"},
+			{Key: "Shift+←/→", Desc: "select (with Home/End too); typing replaces the selection"},
+			{Key: "Ctrl+W", Desc: "delete the word before the cursor (Alt+D: after)"},
+			{Key: "Ctrl+U/K", Desc: "delete to the start / end of the line"},
+		}},
 	}
 }
 
 func (v *MainView) Init() tea.Cmd {
+	if v.startTable != "" {
+		table := v.startTable
+		v.startTable, v.bannerShown = "", true
+		return tea.Batch(v.fetchTables(), v.browseTable(table))
+	}
+	if !v.bannerShown {
+		v.bannerShown = true
+		return tea.Batch(v.fetchTables(), v.fetchBanner())
+	}
 	return v.fetchTables()
 }
 
+// Leave cancels a running table list refresh, which Init repeats on
+// return. Statements the user ran keep going: cancelling one inside a
+// transaction would abort the transaction.
+func (v *MainView) Leave() {
+	v.tableTasks.stop()
+	v.refresh = nil // a pending tick is ignored
+}
+
 func (v *MainView) fetchTables() tea.Cmd {
+	v.schemaIndex = nil // tables may have changed; rebuild on next search
+	ctx := v.tableTasks.restart()
 	return func() tea.Msg {
-		tables, err := v.db.ListTables(context.Background(), "public")
+		tables, err := v.db.ListTables(ctx, "") // every schema on the search path
 		return TablesListMsg{Tables: tables, Err: err}
 	}
 }
 
+// Update handles a message and, when it finishes the running operation,
+// starts the next queued submission.
 func (v *MainView) Update(msg tea.Msg) (View, tea.Cmd) {
+	wasLoading := v.loading
+	view, cmd := v.update(msg)
+	if wasLoading && !v.loading && v.pageQueued {
+		v.pageQueued = false
+		if v.pagTable != "" {
+			cmd = tea.Batch(cmd, v.fetchPage())
+		}
+	}
+	if wasLoading && !v.loading && len(v.queue) > 0 {
+		return view, tea.Batch(cmd, v.runQueued())
+	}
+	return view, cmd
+}
+
+func (v *MainView) update(msg tea.Msg) (View, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if v.maint != nil {
+			return v.handleMaintKey(msg)
+		}
+		if v.alter != nil {
+			return v.handleAlterKey(msg)
+		}
+		if v.index != nil {
+			return v.handleIndexKey(msg)
+		}
+		if v.picker != nil {
+			return v.handlePickerKey(msg)
+		}
+		if v.groups != nil {
+			return v.handleGroupSummaryKey(msg)
+		}
+		if v.refs != nil {
+			return v.handleReferencedByKey(msg)
+		}
+		if v.sequences != nil {
+			return v.handleSequencesKey(msg)
+		}
+		if v.recipe != nil {
+			return v.handleRecipeKey(msg)
+		}
+		if v.cellEdit != nil {
+			return v.handleCellEditKey(msg)
+		}
+		if v.rowForm != nil {
+			return v.handleRowFormKey(msg)
+		}
 		return v.handleKey(msg)
 
+	case RecipeStepMsg:
+		return v, v.updateRecipe(msg)
+
+	case watchTickMsg, WatchResultMsg:
+		return v, v.updateWatch(msg)
+
+	case MaintenancePlanMsg, MaintenanceProgressMsg, MaintenanceDoneMsg, maintTickMsg:
+		return v, v.updateMaintenance(msg)
+
+	case exportTickMsg, ExportDoneMsg:
+		return v, v.updateExport(msg)
+
+	case AlterColumnsMsg, AlterPreviewMsg, AlterDoneMsg:
+		return v, v.updateAlter(msg)
+
+	case CellEditKeyMsg, CellEditDoneMsg:
+		return v, v.updateCellEdit(msg)
+
+	case RowFormLoadedMsg, RowFormDoneMsg:
+		return v, v.updateRowForm(msg)
+
+	case IndexColumnsMsg, IndexEstimateMsg:
+		return v, v.updateIndexWizard(msg)
+
+	case PickerColumnsMsg:
+		return v, v.updateColumnPicker(msg)
+
+	case GroupSummaryMsg:
+		v.updateGroupSummary(msg)
+		return v, nil
+
+	case ReferencedByMsg:
+		v.updateReferencedBy(msg)
+		return v, nil
+
+	case TableColumnsMsg:
+		return v, v.updateTableColumns(msg)
+
+	case SequencesMsg:
+		v.updateSequences(msg)
+		return v, nil
+
+	case ServerInfoMsg:
+		v.updateBanner(msg)
+		return v, nil
+
+	case SanityMsg:
+		v.updateSanity(msg)
+		return v, nil
+
+	case browseRefreshTickMsg:
+		return v, v.updateRefresh(msg)
+
+	case TxBeganMsg, TxEndedMsg:
+		v.updateTx(msg)
+		return v, nil
+
 	case QueryResultMsg:
+		v.recordHistory(msg.ID, msg.Err, v.cancelled && msg.ID == v.resultReq)
+		v.noteTxError(msg.Err)
+		if msg.ID != v.resultReq {
+			return v, nil // a newer request owns the result pane
+		}
 		v.loading = false
+		cancelled := v.cancelled
+		v.cancelled = false
 		v.err = msg.Err
 		v.result = msg.Result
+		v.pagCtids = msg.Ctids
+		if msg.RowKeyTable != "" {
+			v.pagKey, v.pagKeyTable = msg.RowKey, msg.RowKeyTable
+		}
+		v.chartMode = false
+		v.measureAll = false
 		if msg.PagTotal > 0 {
 			v.pagTotal = msg.PagTotal
 		}
 		if msg.Result != nil {
-			lines := v.formatResult(msg.Result)
+			start := time.Now()
+			lines := v.renderResult(msg.Result)
+			var footprint tea.Cmd
+			if !msg.Refresh {
+				footprint = v.footprintStatus(msg.Result, time.Since(start))
+			}
+			offset := 0
 			if msg.PagInfo != "" {
-				lines = append([]string{msg.PagInfo, ""}, lines...)
+				info := append(strings.Split(msg.PagInfo, "\n"), "")
+				lines = append(info, lines...)
+				offset = len(info)
 			}
 			// Show transaction reminder after modification queries
-			if v.inTransaction {
+			if v.tx != nil {
 				lines = append(lines, "", "─────────────────────────────────────",
-					"⚠️  IN TRANSACTION — type COMMIT; to save or ROLLBACK; to undo")
+					"⚠️  IN TRANSACTION — \\commit to save or \\rollback to undo")
 			}
 			v.viewport.SetContentLines(lines)
+			v.pinHeader(offset)
+			if v.gotoPending > 0 {
+				v.scrollToRow(v.gotoPending)
+				v.gotoPending = 0
+			}
 			v.rightMode = rightModeData
+			if msg.Question != "" && msg.Result.RowCount > 0 && v.interpretEnabled() {
+				lines = append(lines, "", StyleDimmed.Render("💡 Interpreting result..."))
+				v.viewport.SetContentLines(lines)
+				v.pinHeader(offset)
+				return v, tea.Batch(footprint, v.interpretResult(msg.ID, msg.Question, msg.Result, msg.PagTotal))
+			}
+			return v, footprint
 		} else if msg.Err != nil {
+			v.gotoPending = 0
 			errLines := []string{"ERROR: " + msg.Err.Error()}
-			if v.inTransaction {
+			if cancelled {
+				errLines = []string{StyleWarning.Render("Query cancelled")}
+			}
+			if v.txAborted {
 				errLines = append(errLines, "", "─────────────────────────────────────",
-					"⚠️  IN TRANSACTION — type ROLLBACK; to undo or fix and retry")
+					"⚠️  IN TRANSACTION — it failed; \\rollback to end it")
+			} else if v.tx != nil {
+				errLines = append(errLines, "", "─────────────────────────────────────",
+					"⚠️  IN TRANSACTION — \\commit to save or \\rollback to undo")
 			}
 			v.viewport.SetContentLines(errLines)
 		}
 		return v, nil
 
 	case DescribeResultMsg:
+		if msg.ID != v.resultReq {
+			return v, nil
+		}
 		v.loading = false
 		if msg.Err != nil {
 			v.viewport.SetContent("ERROR: " + msg.Err.Error())
@@ -229,29 +811,88 @@ func (v *MainView) Update(msg tea.Msg) (View, tea.Cmd) {
 				lines = append(lines, "", "── Referenced By ──")
 				lines = append(lines, v.formatResult(msg.ReferencedBy)...)
 			}
+			// Constraints
+			if msg.Constraints != nil && msg.Constraints.RowCount > 0 {
+				lines = append(lines, "", "── Constraints ──")
+				lines = append(lines, v.formatResult(msg.Constraints)...)
+			}
+			// Comments
+			if msg.Comments != nil && msg.Comments.RowCount > 0 {
+				lines = append(lines, "", "── Comments ──")
+				lines = append(lines, v.formatResult(msg.Comments)...)
+			}
+			// Statistics
+			if msg.Stats != nil && msg.Stats.RowCount > 0 {
+				lines = append(lines, "", "── Statistics ──")
+				lines = append(lines, v.formatResult(msg.Stats)...)
+			}
+			// TimescaleDB: the rows live in chunks
+			if h := msg.Hypertable; h != nil {
+				lines = append(lines, "", "── Hypertable ──")
+				lines = append(lines, v.formatResult(h.Dimensions)...)
+				lines = append(lines, "", "── Chunks ──")
+				lines = append(lines, v.formatResult(h.Chunks)...)
+				lines = append(lines, "", "── Policies ──")
+				if h.Policies.RowCount > 0 {
+					lines = append(lines, v.formatResult(h.Policies)...)
+				} else {
+					lines = append(lines, "(no retention or compression policies)")
+				}
+			}
 			v.viewport.SetContentLines(lines)
 			v.rightMode = rightModeDescribe
 		}
 		return v, nil
 
+	case DependenciesMsg:
+		if msg.ID != v.resultReq {
+			return v, nil
+		}
+		v.loading = false
+		if msg.Err != nil {
+			v.viewport.SetContent("ERROR: " + msg.Err.Error())
+		} else {
+			v.viewport.SetContentLines(formatDependencies(msg.Report, v.sqlStyle))
+			v.rightMode = rightModeDescribe
+		}
+		return v, nil
+
 	case TablesListMsg:
+		if errors.Is(msg.Err, context.Canceled) {
+			return v, nil // superseded by a newer refresh
+		}
 		if msg.Err == nil {
-			var names []string
+			var schemas, types []string
 			var rowCounts []int64
 			for _, t := range msg.Tables {
-				names = append(names, t.Name)
+				schemas = append(schemas, t.Schema)
+				types = append(types, t.Type)
 				rowCounts = append(rowCounts, t.RowCount)
 			}
-			v.tables = names
+			v.tables = db.DisplayNames(msg.Tables)
+			v.tableSchemas = schemas
 			v.tableRows = rowCounts
+			v.tableTypes = types
 			v.tableErr = nil
+			v.columns = tableColumns{} // the tables may have changed
 		} else {
 			v.tableErr = msg.Err
 		}
 		return v, nil
 
+	case AIChunkMsg:
+		if msg.ID == v.chatReq {
+			v.chatPartial += msg.Delta
+			v.viewport.SetContentLines(v.renderChatHistory())
+			v.viewport.End()
+		}
+		return v, msg.next
+
 	case AIResponseMsg:
-		v.chatLoading = false
+		if msg.ID != v.chatReq {
+			return v, nil // reply to an earlier message; a newer one is pending
+		}
+		v.chatLoading, v.chatPartial = false, ""
 		if msg.Err != nil {
 			v.chatMessages = append(v.chatMessages, ai.Message{
 				Role:    "assistant",
@@ -267,6 +908,116 @@ func (v *MainView) Update(msg tea.Msg) (View, tea.Cmd) {
 		v.viewport.End()
 		return v, nil
 
+	case InterpretMsg:
+		if msg.ID != v.resultReq {
+			return v, nil // a newer request owns the result pane
+		}
+		lines := v.viewport.content
+		stickyStart, stickyLines := v.viewport.stickyStart, v.viewport.stickyLines
+		if n := len(lines); n > 0 && strings.Contains(lines[n-1], "Interpreting result") {
+			lines = lines[:n-1]
+		}
+		if msg.Err != nil {
+			lines = append(lines, StyleError.Render("💡 Interpretation failed: "+msg.Err.Error()))
+		} else {
+			lines = append(lines, wrapLines("💡 "+msg.Answer, v.viewport.width)...)
+			v.chatMessages = append(v.chatMessages, ai.Message{
+				Role:    "assistant",
+				Content: "💡 " + msg.Answer,
+			})
+		}
+		v.viewport.SetContentLines(lines)
+		v.viewport.SetSticky(stickyStart, stickyLines)
+		return v, nil
+
+	case FDWAppliedMsg:
+		v.loading = false
+		if msg.Err != nil {
+			v.viewport.SetContentLines([]string{
+				StyleError.Render("postgres_fdw setup failed (rolled back): " + msg.Err.Error()),
+				"",
+				StyleDimmed.Render("Fix the problem and run \\fdw apply again."),
+			})
+			return v, nil
+		}
+		v.pendingFDW = nil
+		v.viewport.SetContentLines([]string{
+			StyleSuccess.Render(fmt.Sprintf("✓ Foreign server %s created; %s imported into schema %s",
+				msg.Plan.Server, msg.Plan.RemoteSchema, msg.Plan.LocalSchema)),
+			"",
+			"Remote tables can now be joined with local ones, e.g.:",
+			fmt.Sprintf("  SELECT * FROM %s.<table> r JOIN <local_table> l ON ...;", msg.Plan.LocalSchema),
+		})
+		return v, nil
+
+	case SeedPreviewMsg:
+		v.loading = false
+		if msg.Err != nil {
+			v.viewport.SetContent(StyleError.Render("Seed failed: " + msg.Err.Error()))
+			return v, nil
+		}
+		v.pendingSeed = msg.Data
+		preview := &db.QueryResult{Columns: msg.Data.Columns}
+		for i, row := range msg.Data.Rows {
+			if i == 10 {
+				break
+			}
+			cells := make([]string, len(row))
+			for j, val := range row {
+				if val == nil {
+					cells[j] = "NULL"
+				} else {
+					cells[j] = fmt.Sprint(val)
+				}
+			}
+			preview.Rows = append(preview.Rows, cells)
+		}
+		preview.Status = fmt.Sprintf("Preview: %d of %d rows", len(preview.Rows), len(msg.Data.Rows))
+		lines := []string{
+			StyleBold.Render(fmt.Sprintf("🌱 %d rows for %s (%s)", len(msg.Data.Rows), msg.Data.Table, msg.Note)),
+			"",
+		}
+		lines = append(lines, v.formatResult(preview)...)
+		lines = append(lines, "", StyleDimmed.Render("Run \\seed apply to insert them in one transaction."))
+		v.viewport.SetContentLines(lines)
+		v.viewport.Home()
+		return v, nil
+
+	case SeedInsertedMsg:
+		v.loading = false
+		if msg.Err != nil {
+			v.viewport.SetContent(StyleError.Render("Seed insert failed (rolled back): " + msg.Err.Error()))
+			return v, nil
+		}
+		v.pendingSeed = nil
+		v.viewport.SetContent(StyleSuccess.Render(fmt.Sprintf("✓ Inserted %d rows into %s", msg.Count, msg.Table)))
+		return v, v.fetchTables()
+
+	case UpsertPlanMsg, UpsertAppliedMsg:
+		v.updateUpsert(msg)
+		return v, nil
+
+	case MigrationReviewMsg:
+		v.showMigrationReview(msg)
+		v.rightMode = rightModeData
+		return v, nil
+
+	case SchemaRetrievalMsg:
+		return v, v.updateSchemaRetrieval(msg)
+
+	case ColumnSearchMsg:
+		v.chatLoading = false
+		if msg.Index != nil {
+			v.schemaIndex = msg.Index
+		}
+		v.chatMessages = append(v.chatMessages, ai.Message{
+			Role:    "assistant",
+			Content: v.formatColumnMatches(msg),
+		})
+		v.viewport.SetContentLines(v.renderChatHistory())
+		v.viewport.End()
+		return v, nil
+
 	case QueryPlanMsg:
 		v.chatLoading = false
 		if msg.Err != nil {
@@ -306,6 +1057,7 @@ func (v *MainView) Update(msg tea.Msg) (View, tea.Cmd) {
 		}
 		v.pagPage = plan.Page - 1 // pagPage is 0-based
 		v.pagPageSize = plan.Limit
+		v.pagPlan = plan.IsReadOnly()
 
 		if plan.IsReadOnly() {
 			// SELECT: auto-execute with rich info (same as table browse)
@@ -342,6 +1094,20 @@ func (v *MainView) Update(msg tea.Msg) (View, tea.Cmd) {
 		})
 		v.viewport.SetContentLines(v.renderChatHistory())
 		v.viewport.End()
+		return v, v.estimateModification(oneLine)
+
+	case ModificationEstimateMsg:
+		if msg.SQL != v.pendingSQL {
+			return v, nil // the plan was already used or replaced
+		}
+		v.chatMessages = append(v.chatMessages, ai.Message{
+			Role:    "assistant",
+			Content: formatModificationEstimate(msg.Estimate, msg.Err),
+		})
+		if v.inputMode == inputModeChat {
+			v.viewport.SetContentLines(v.renderChatHistory())
+			v.viewport.End()
+		}
 		return v, nil
 	}
 
@@ -357,22 +1123,27 @@ func (v *MainView) handleKey(msg tea.KeyMsg) (View, tea.Cmd) {
 			v.inputMode = inputModeSQL
 			// If there's a pending modification SQL, auto-start a transaction
 			if v.pendingSQL != "" {
-				v.input = v.pendingSQL
+				sql := v.pendingSQL
 				v.pendingSQL = ""
-				// Auto-execute BEGIN
-				v.inTransaction = true
-				v.loading = true
 				v.focus = focusInput
-				return v, func() tea.Msg {
-					result, err := v.db.Execute(context.Background(), "BEGIN")
-					return QueryResultMsg{Result: result, Err: err}
+				if v.tx != nil {
+					v.input.SetValue(sql)
+					return v, nil
 				}
+				// Auto-start a transaction
+				cmd := v.beginTx()
+				v.input.SetValue(sql)
+				return v, cmd
 			}
 		}
 		v.focus = focusInput
 		return v, nil
 	}
 
+	if msg.String() == "ctrl+x" {
+		return v, v.cancelQuery()
+	}
+
 	// F5 toggles fullscreen for the currently focused panel
 	if msg.String() == "f5" {
 		v.fullscreen = !v.fullscreen
@@ -448,23 +1219,36 @@ func (v *MainView) handleSidebarKey(msg tea.KeyMsg) (View, tea.Cmd) {
 		}
 	case "enter":
 		if len(v.tables) > 0 {
-			selected := v.tables[v.tableIdx]
-			v.pagTable = selected
-			v.pagPage = 0
-			v.pagPageSize = 20
-			if v.tableIdx < len(v.tableRows) {
-				v.pagTotal = v.tableRows[v.tableIdx]
-			} else {
-				v.pagTotal = 0
-			}
-			return v, v.fetchPage()
+			return v, v.browseTable(v.tables[v.tableIdx])
+		}
+	case "C":
+		if len(v.tables) > 0 {
+			return v, v.openColumnPicker(v.tables[v.tableIdx], true)
 		}
 	case "d":
 		if len(v.tables) > 0 {
 			selected := v.tables[v.tableIdx]
-			v.pagTable = ""
+			v.pagTable, v.pagPlan = "", false
 			return v, v.fetchDescribe(selected)
 		}
+	case "a":
+		if len(v.tables) > 0 {
+			v.openMaintenance(v.tables[v.tableIdx])
+		}
+	case "A":
+		if len(v.tables) > 0 {
+			return v, v.openAlterWizard(v.tables[v.tableIdx])
+		}
+	case "I":
+		if len(v.tables) > 0 {
+			return v, v.openIndexWizard(v.tables[v.tableIdx])
+		}
+	case "D":
+		if len(v.tables) > 0 {
+			selected := v.tables[v.tableIdx]
+			v.pagTable, v.pagPlan = "", false
+			return v, v.fetchDependencies(selected)
+		}
 	}
 	return v, nil
 }
@@ -481,22 +1265,20 @@ func (v *MainView) handleResultsKey(msg tea.KeyMsg) (View, tea.Cmd) {
 		v.viewport.ScrollRight(4)
 	case "[":
 		// In expanded mode: jump to previous record
-		if v.expandedMode && v.result != nil {
-			recordHeight := len(v.result.Columns) + 1 // columns + header
-			v.viewport.ScrollUp(recordHeight)
-		} else {
+		if !v.jumpRecord(-1) {
 			v.viewport.ScrollUp(5)
 		}
 	case "]":
 		// In expanded mode: jump to next record
-		if v.expandedMode && v.result != nil {
-			recordHeight := len(v.result.Columns) + 1
-			v.viewport.ScrollDown(recordHeight)
-		} else {
+		if !v.jumpRecord(1) {
 			v.viewport.ScrollDown(5)
 		}
 	case "pgup":
-		if v.pagTable != "" {
+		if v.pagPlan && v.lastQueryPlan != nil {
+			if v.pagPage > 0 {
+				return v, v.pageQueryPlan(v.pagPage - 1)
+			}
+		} else if v.pagTable != "" {
 			if v.pagPage > 0 {
 				v.pagPage--
 				return v, v.fetchPage()
@@ -505,7 +1287,11 @@ func (v *MainView) handleResultsKey(msg tea.KeyMsg) (View, tea.Cmd) {
 			v.viewport.PageUp()
 		}
 	case "pgdown":
-		if v.pagTable != "" {
+		if v.pagPlan && v.lastQueryPlan != nil {
+			if v.pagPage < v.maxPage() {
+				return v, v.pageQueryPlan(v.pagPage + 1)
+			}
+		} else if v.pagTable != "" {
 			maxPage := v.maxPage()
 			if v.pagPage < maxPage {
 				v.pagPage++
@@ -526,293 +1312,1044 @@ func (v *MainView) handleResultsKey(msg tea.KeyMsg) (View, tea.Cmd) {
 		v.viewport.ToggleWrap()
 	case "x": // expanded/vertical display toggle
 		v.expandedMode = !v.expandedMode
-		if v.result != nil {
-			var lines []string
-			if v.expandedMode {
-				lines = v.formatResultExpanded(v.result)
-			} else {
-				lines = v.formatResult(v.result)
-			}
-			if v.pagTable != "" {
-				lines = append([]string{v.result.Status, ""}, lines...)
-			}
-			v.viewport.SetContentLines(lines)
+		v.redrawResult()
+	case "m": // re-measure column widths over all rows
+		if v.result != nil && !v.chartMode && !v.measureAll && len(v.result.Rows) > widthSampleRows {
+			v.measureAll = true
+			start := time.Now()
+			v.redrawResult()
+			return v, v.footprintStatus(v.result, time.Since(start))
+		}
+	case "n": // row number column toggle
+		v.rowNumbers = !v.rowNumbers
+		if !v.expandedMode {
+			v.redrawResult()
+		}
+	case "t": // column type row toggle
+		v.showTypes = !v.showTypes
+		if !v.expandedMode {
+			v.redrawResult()
+		}
+	case "g": // quick chart toggle
+		if v.result == nil {
+			break
+		}
+		v.chartMode = !v.chartMode
+		if v.chartMode {
+			v.viewport.SetContentLines(v.chartLines(v.result))
+			v.viewport.Home()
+			break
+		}
+		v.redrawResult()
+	case "s": // bar chart sort order
+		if v.chartMode && v.result != nil && isBarChartable(v.result) {
+			v.chartSort = (v.chartSort + 1) % barSortCount
+			v.viewport.SetContentLines(v.chartLines(v.result))
 		}
 	case "c":
 		if v.lastSQL != "" {
 			v.copyToClipboard(v.lastSQL)
 		}
+	case "M": // copy the result as a Markdown table
+		return v, v.copyResult("Markdown")
+	case "H": // copy the result as an HTML table
+		return v, v.copyResult("HTML")
+	case "C": // choose the browsed table's columns
+		if v.pagTable != "" {
+			return v, v.openColumnPicker(v.pagTable, false)
+		}
+	case "<": // focus the previous column
+		v.moveFocusCol(-1)
+	case ">": // focus the next column
+		v.moveFocusCol(1)
+	case "f": // keep only the focused value
+		return v, v.addQuickFilter(false)
+	case "F": // exclude the focused value
+		return v, v.addQuickFilter(true)
+	case "u": // drop the last quick filter
+		return v, v.dropLastFilter()
+	case "p": // row counts per value of the focused column
+		return v, v.openGroupSummary()
+	case "r": // rows referencing the focused row
+		return v, v.openReferencedBy()
+	case "e": // edit the focused cell
+		return v, v.openCellEdit()
+	case "i": // add a row
+		return v, v.openRowInsert()
+	case "D": // delete the focused row
+		return v, v.openRowDelete()
+	case "R": // auto-refresh the browsed table
+		return v, v.toggleRefresh()
 	}
 	return v, nil
 }
 
+// redrawResult re-renders the current result after a display toggle.
+func (v *MainView) redrawResult() {
+	if v.result == nil {
+		return
+	}
+	lines := v.renderResult(v.result)
+	offset := 0
+	if v.pagTable != "" {
+		lines = append([]string{v.result.Status, ""}, lines...)
+		offset = 2
+	}
+	v.viewport.SetContentLines(lines)
+	v.pinHeader(offset)
+}
+
+// chartLines renders the current result as a chart sized to the viewport,
+// or an explanation when the result has no chartable columns.
+func (v *MainView) chartLines(r *db.QueryResult) []string {
+	if timeCol, valueCol, ok := timeSeriesColumns(r); ok {
+		return renderTimeSeriesChart(r, timeCol, valueCol, v.viewport.width, v.viewport.height)
+	}
+	if isBarChartable(r) {
+		return renderBarChart(r, v.chartSort, v.viewport.width)
+	}
+	return []string{
+		StyleError.Render("Nothing to chart."),
+		"",
+		StyleDimmed.Render("A chart needs a timestamp/date column and a numeric column,"),
+		StyleDimmed.Render("or exactly two columns: (label, number)."),
+		StyleDimmed.Render("Press g to return to the table."),
+	}
+}
+
 func (v *MainView) handleInputKey(msg tea.KeyMsg) (View, tea.Cmd) {
+	if v.complete != nil {
+		return v.handleCompletionKey(msg)
+	}
+	if v.histSearch != nil {
+		return v.handleHistorySearchKey(msg)
+	}
 	switch msg.String() {
 	case "enter":
 		return v, v.execute()
-	case "tab":
-		// Simple table name autocomplete: find the word being typed and match against table names
-		v.input = v.autocompleteTable(v.input)
+	case "tab", "ctrl+@":
+		return v, v.openCompletion()
+	case "ctrl+r":
+		v.startHistorySearch()
+	case "ctrl+f":
+		if v.input.Value() != "" && !strings.HasPrefix(v.input.Value(), "\\") {
+			v.formatSQL(v.input.Value())
+		}
 	case "up":
 		if len(v.history) > 0 {
+			if v.histIdx < 0 {
+				v.draft = v.input.Value()
+			}
 			if v.histIdx < len(v.history)-1 {
 				v.histIdx++
 			}
-			v.input = v.history[v.histIdx]
+			v.input.SetValue(v.history[v.histIdx].SQL)
 		}
 	case "down":
 		if v.histIdx > 0 {
 			v.histIdx--
-			v.input = v.history[v.histIdx]
-		} else {
+			v.input.SetValue(v.history[v.histIdx].SQL)
+		} else if v.histIdx == 0 {
 			v.histIdx = -1
-			v.input = ""
-		}
-	case "backspace":
-		if len(v.input) > 0 {
-			v.input = v.input[:len(v.input)-1]
+			v.input.SetValue(v.draft)
+			v.draft = ""
 		}
 	default:
-		// Accept typed characters AND pasted text (bracketed paste).
-		// Pasted text arrives as KeyRunes with Paste=true; String() wraps
-		// it in "[...]" so the old len()==1 check rejected it.
-		if msg.Type == tea.KeyRunes {
-			v.input += string(msg.Runes)
-		} else if msg.Type == tea.KeySpace {
-			v.input += " "
-		}
+		v.input.Update(msg)
 	}
 	return v, nil
 }
 
-// autocompleteTable completes the last word in the input against known table names
-// and common SQL keywords.
-func (v *MainView) autocompleteTable(input string) string {
-	if input == "" {
-		return input
-	}
-
-	// Find the last word being typed
-	lastSpace := strings.LastIndexAny(input, " \t,.()")
-	var prefix, partial string
-	if lastSpace == -1 {
-		prefix = ""
-		partial = input
-	} else {
-		prefix = input[:lastSpace+1]
-		partial = input[lastSpace+1:]
-	}
-
-	if partial == "" {
-		return input
-	}
-
-	lower := strings.ToLower(partial)
-
-	// Try table names first
-	for _, t := range v.tables {
-		if strings.HasPrefix(strings.ToLower(t), lower) && strings.ToLower(t) != lower {
-			return prefix + t
-		}
-	}
-
-	// Try SQL keywords
-	keywords := []string{
-		"SELECT", "FROM", "WHERE", "JOIN", "LEFT", "RIGHT", "INNER", "OUTER",
-		"INSERT", "UPDATE", "DELETE", "CREATE", "ALTER", "DROP", "INDEX",
-		"ORDER", "GROUP", "HAVING", "LIMIT", "OFFSET", "BEGIN", "COMMIT",
-		"ROLLBACK", "AND", "OR", "NOT", "NULL", "INTO", "VALUES", "SET",
-		"TABLE", "AS", "ON", "IN", "LIKE", "ILIKE", "BETWEEN", "EXISTS",
-		"DISTINCT", "COUNT", "SUM", "AVG", "MIN", "MAX", "EXPLAIN", "ANALYZE",
-	}
-	for _, kw := range keywords {
-		if strings.HasPrefix(strings.ToLower(kw), lower) && strings.ToLower(kw) != lower {
-			return prefix + kw
-		}
-	}
-
-	return input
+// writeClipboard copies text to the system clipboard using pbcopy (macOS).
+func writeClipboard(text string) error {
+	cmd := exec.Command("pbcopy")
+	cmd.Stdin = strings.NewReader(text)
+	return cmd.Run()
 }
 
-// copyToClipboard copies text to the system clipboard using pbcopy (macOS).
+// copyToClipboard copies the SQL text to the clipboard.
 func (v *MainView) copyToClipboard(text string) {
-	cmd := exec.Command("pbcopy")
-	cmd.Stdin = strings.NewReader(text)
-	if err := cmd.Run(); err != nil {
+	if err := writeClipboard(text); err != nil {
 		return
 	}
 	// Show brief confirmation in the result status area
 	if v.result != nil {
 		v.result.Status += "  ✅ SQL copied!"
-		var lines []string
-		if v.expandedMode {
-			lines = v.formatResultExpanded(v.result)
-		} else {
-			lines = v.formatResult(v.result)
-		}
-		v.viewport.SetContentLines(lines)
+		v.viewport.SetContentLines(v.renderResult(v.result))
+		v.pinHeader(0)
 	}
 }
 
 func (v *MainView) execute() tea.Cmd {
-	input := strings.TrimSpace(v.input)
+	input := strings.TrimSpace(v.input.Value())
 	if input == "" {
 		return nil
 	}
+	if v.loading {
+		return v.enqueue(input)
+	}
 	// Strip trailing semicolons for command matching
 	cleanInput := strings.TrimRight(input, "; ")
 
-	v.history = append([]string{input}, v.history...)
-	v.histIdx = -1
-	v.pagTable = "" // clear pagination for manual queries
+	v.addHistory(input)
+	v.pagTable, v.pagPlan = "", false // clear pagination for manual queries
 
-	// Track transaction state
-	upper := strings.ToUpper(cleanInput)
-	if upper == "BEGIN" || upper == "START TRANSACTION" {
-		v.inTransaction = true
-	} else if upper == "COMMIT" || upper == "ROLLBACK" {
-		v.inTransaction = false
+	// Transaction control runs on the transaction's own connection
+	switch strings.ToUpper(cleanInput) {
+	case "BEGIN", "START TRANSACTION":
+		return v.beginTx()
+	case "COMMIT", "END":
+		return v.endTx(true)
+	case "ROLLBACK", "ABORT":
+		return v.endTx(false)
 	}
 
 	// Block modification commands outside a transaction
-	if !v.inTransaction && !strings.HasPrefix(input, "\\") {
+	if v.tx == nil && !strings.HasPrefix(input, "\\") {
 		firstWord := strings.ToUpper(strings.Fields(cleanInput)[0])
 		switch firstWord {
 		case "INSERT", "UPDATE", "DELETE", "ALTER", "DROP", "CREATE", "TRUNCATE":
 			v.viewport.SetContentLines([]string{
 				"⚠️  Modification commands require a transaction",
 				"",
-				"  1. Type: BEGIN;",
+				"  1. Type: \\begin  (or BEGIN;)",
 				"  2. Run your command",
-				"  3. Type: COMMIT;  (to save)  or  ROLLBACK;  (to undo)",
+				"  3. Type: \\commit  (to save)  or  \\rollback  (to undo)",
 			})
-			v.input = input // keep the input so the user doesn't lose it
+			v.input.SetValue(input) // keep the input so the user doesn't lose it
+			return nil
+		}
+	}
+
+	if strings.HasPrefix(input, "\\") {
+		return v.handleMetaCommand(input)
+	}
+	sql := v.vars.Expand(input)
+	v.loading = true
+	v.input.Reset()
+	v.lastSQL = strings.Join(strings.Fields(sql), " ") + ";"
+	id := v.newResultRequest()
+	ctx := v.queryContext()
+	v.histRun = &historyRun{id: id, sql: input, start: time.Now()}
+	run := v.queryRunner()
+	return func() tea.Msg {
+		result, err := run(ctx, sql)
+		return QueryResultMsg{ID: id, Result: result, Err: err}
+	}
+}
+
+// estimateModification runs a plain EXPLAIN on a pending modification
+// plan as an extra safety signal before the user executes it.
+func (v *MainView) estimateModification(sql string) tea.Cmd {
+	database := v.db
+	return func() tea.Msg {
+		est, err := database.EstimateModification(context.Background(), sql)
+		return ModificationEstimateMsg{SQL: sql, Estimate: est, Err: err}
+	}
+}
+
+// formatModificationEstimate renders the EXPLAIN estimate as a chat note.
+func formatModificationEstimate(est *db.ModificationEstimate, err error) string {
+	if err != nil {
+		return "🔎 EXPLAIN failed — check the statement carefully: " + err.Error()
+	}
+	verb, ok := map[string]string{"Update": "updated", "Delete": "deleted", "Insert": "inserted", "Merge": "merged"}[est.Operation]
+	if !ok {
+		verb = "affected"
+	}
+	lines := []string{fmt.Sprintf("🔎 EXPLAIN estimate: ~%d rows would be %s in %s", est.Rows, verb, est.Table)}
+	if len(est.Indexes) > 0 {
+		lines = append(lines, "✅ Rows are located with index "+strings.Join(est.Indexes, ", "))
+	}
+	if len(est.SeqScans) > 0 {
+		lines = append(lines, "⚠️  Sequential scan on "+strings.Join(est.SeqScans, ", ")+" — no index narrows the rows")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// newResultRequest issues the ID for a request that fills the result
+// pane, superseding any query or describe still in flight.
+func (v *MainView) newResultRequest() int {
+	v.resultReq++
+	return v.resultReq
+}
+
+// queryContext returns the context for a statement filling the results
+// pane, cancelling the previous one's.
+func (v *MainView) queryContext() context.Context {
+	v.cancelled = false
+	return v.queryTasks.restart()
+}
+
+// cancelQuery stops the running statement (Ctrl+X): its context is
+// cancelled, which sends the server a cancel request. Queued statements
+// are dropped too; inside a transaction they would only fail.
+func (v *MainView) cancelQuery() tea.Cmd {
+	if !v.loading {
+		return func() tea.Msg { return StatusMsg("No query is running") }
+	}
+	v.cancelled = true
+	v.queryTasks.stop()
+	v.pageQueued = false
+	text := "Cancelling the query…"
+	if n := len(v.queue); n > 0 {
+		text = fmt.Sprintf("Cancelling the query and %d queued", n)
+		v.queue = nil
+	}
+	return func() tea.Msg { return StatusMsg(text) }
+}
+
+// enqueue holds a submission made while another statement is running,
+// so it runs after it instead of racing it on the same connection.
+func (v *MainView) enqueue(input string) tea.Cmd {
+	if len(v.queue) >= maxQueuedQueries {
+		return func() tea.Msg {
+			return StatusMsg(fmt.Sprintf("Busy: %d statements already queued", len(v.queue)))
+		}
+	}
+	v.queue = append(v.queue, input)
+	v.input.Reset()
+	n := len(v.queue)
+	return func() tea.Msg {
+		return StatusMsg(fmt.Sprintf("Queued — runs after the current statement (%d pending)", n))
+	}
+}
+
+// runQueued executes queued submissions in order until one starts an
+// async operation. Whatever the user is typing meanwhile is left in the
+// input line.
+func (v *MainView) runQueued() tea.Cmd {
+	typed := v.input
+	var cmds []tea.Cmd
+	for len(v.queue) > 0 && !v.loading {
+		v.input.SetValue(v.queue[0])
+		v.queue = v.queue[1:]
+		cmds = append(cmds, v.execute())
+	}
+	v.input = typed
+	return tea.Batch(cmds...)
+}
+
+// tableRef resolves a table list name to its schema and bare name. Names
+// not in the list may be written "schema.table"; an unqualified one gets
+// an empty schema, which the db package resolves to the search path.
+func (v *MainView) tableRef(name string) (schema, table string) {
+	for i, t := range v.tables {
+		if t == name && i < len(v.tableSchemas) {
+			_, table = db.SplitTableName(name)
+			return v.tableSchemas[i], table
+		}
+	}
+	return db.SplitTableName(name)
+}
+
+// browseTable starts browsing table from its first page. Browsing another
+// table drops the quick filters.
+func (v *MainView) browseTable(table string) tea.Cmd {
+	filters := v.quickFilters
+	if table != v.pagTable {
+		filters = nil
+	}
+	return v.browseFiltered(table, filters)
+}
+
+// browseFiltered starts browsing table from its first page with the given
+// quick filters.
+func (v *MainView) browseFiltered(table string, filters []quickFilter) tea.Cmd {
+	if table != v.pagTable {
+		v.focusCol = 0
+		v.pagKeyTable = "" // looked up again: the table may have changed since
+	}
+	v.quickFilters = filters
+	v.pagTable, v.pagPlan = table, false
+	v.pagPage = 0
+	v.pagPageSize = 20
+	if i := slices.Index(v.tables, table); i >= 0 && i < len(v.tableRows) {
+		v.pagTotal = v.tableRows[i]
+	} else {
+		v.pagTotal = 0
+	}
+	return v.fetchPage()
+}
+
+// browseSQL is the SELECT of the browsed table's rows with its saved
+// columns and sort order and the quick filters, without paging.
+func (v *MainView) browseSQL(table string) string {
+	return fmt.Sprintf("SELECT %s FROM %s%s%s", db.SelectList(v.browseColumns(table)), table, v.browseWhere(), v.browseOrder(table))
+}
+
+// fetchPage runs a paginated SELECT for the current table. While a
+// statement runs in the transaction the fetch waits for it: starting it
+// would cancel that statement and abort the transaction.
+func (v *MainView) fetchPage() tea.Cmd {
+	if v.tx != nil && v.loading {
+		v.pageQueued = true
+		return func() tea.Msg { return StatusMsg("Queued — the page loads after the current statement") }
+	}
+	table := v.pagTable
+	page := v.pagPage
+	pageSize := v.pagPageSize
+	v.loading = true
+	offset := page * pageSize
+	selectSQL := v.browseSQL(table)
+	ctidSQL := "SELECT ctid::text AS ctid, " + strings.TrimPrefix(selectSQL, "SELECT ")
+	where, chips := v.browseWhere(), v.filterChips()
+	every := v.refreshInterval(table)
+	pageSQL := v.styleSQL(fmt.Sprintf("%s LIMIT %d OFFSET %d", selectSQL, pageSize, offset))
+	ctidPageSQL := v.styleSQL(fmt.Sprintf("%s LIMIT %d OFFSET %d", ctidSQL, pageSize, offset))
+	v.lastSQL = pageSQL + ";"
+	id := v.newResultRequest()
+	ctx := v.queryContext()
+	run, queryRow := v.queryRunner(), v.rowQuerier()
+	database := v.db
+	key, keyKnown := v.pagKey, v.pagKeyTable == table
+	schema, name := v.tableRef(table)
+	return func() tea.Msg {
+		// Tables without a key are edited by ctid, so their pages fetch it.
+		// Views have no row key and fail the lookup.
+		if !keyKnown {
+			key, _ = database.TableRowKey(ctx, schema, name)
+		}
+		byCtid := key != nil && key.Kind == db.RowKeyCtid
+		sql := pageSQL
+		if byCtid {
+			sql = ctidPageSQL
+		}
+
+		// Get real row count
+		var total int64
+		countSQL := fmt.Sprintf("SELECT count(*) FROM %s%s", table, where)
+		_ = queryRow(ctx, countSQL).Scan(&total)
+
+		// Get table size info
+		var totalSize, tableSize, indexSize string
+		sizeSQL := `SELECT pg_size_pretty(pg_total_relation_size($1)),
+		                   pg_size_pretty(pg_relation_size($1)),
+		                   pg_size_pretty(pg_indexes_size($1))`
+		_ = queryRow(ctx, sizeSQL, table).Scan(&totalSize, &tableSize, &indexSize)
+
+		info := fmt.Sprintf("🔍 %s;\n📊 %s  |  Total: %-8s  |  Table: %-8s  |  Indexes: %-8s  |  %d rows",
+			sql, table, totalSize, tableSize, indexSize, total)
+		if chips != "" {
+			info += "\n" + chips
+		}
+		if every > 0 {
+			info += fmt.Sprintf("\n⟳ Refreshing every %s · last refresh %s   (R stops)", every, time.Now().Format("15:04:05"))
+		}
+
+		result, err := run(ctx, sql)
+		var ctids []string
+		if byCtid && result != nil {
+			ctids = splitCtids(result)
+		}
+		if result != nil {
+			lastRow := offset + result.RowCount
+			totalPages := maxPageCalc(total, int64(pageSize)) + 1
+			result.Status = fmt.Sprintf("Page %d/%d  |  Rows %d–%d of %d",
+				page+1, totalPages,
+				offset+1, lastRow,
+				total)
+		}
+		return QueryResultMsg{ID: id, Result: result, Err: err, PagTotal: total, PagInfo: info,
+			RowKey: key, RowKeyTable: table, Ctids: ctids}
+	}
+}
+
+func (v *MainView) maxPage() int {
+	if v.pagTotal <= 0 || v.pagPageSize <= 0 {
+		return 0
+	}
+	return int((v.pagTotal - 1) / int64(v.pagPageSize))
+}
+
+func maxPageCalc(total int64, pageSize int64) int64 {
+	if total <= 0 || pageSize <= 0 {
+		return 0
+	}
+	return (total - 1) / pageSize
+}
+
+// fetchDescribe queries the table schema and returns a DescribeResultMsg.
+func (v *MainView) fetchDescribe(table string) tea.Cmd {
+	v.loading = true
+	id := v.newResultRequest()
+	schema, name := v.tableRef(table)
+	return func() tea.Msg {
+		ctx := context.Background()
+
+		// Get table size info
+		var totalSize, tableSize, indexSize string
+		var rowCount int64
+		sizeSQL := `SELECT pg_size_pretty(pg_total_relation_size($1)),
+		                   pg_size_pretty(pg_relation_size($1)),
+		                   pg_size_pretty(pg_indexes_size($1))`
+		_ = v.db.Pool.QueryRow(ctx, sizeSQL, table).Scan(&totalSize, &tableSize, &indexSize)
+		_ = v.db.Pool.QueryRow(ctx, fmt.Sprintf("SELECT count(*) FROM %s", table)).Scan(&rowCount)
+
+		header := fmt.Sprintf("📋 %s  |  Total: %s  |  Table: %s  |  Indexes: %s  |  %d rows",
+			table, totalSize, tableSize, indexSize, rowCount)
+
+		result, err := v.db.DescribeTable(ctx, schema, name)
+		if err != nil {
+			return DescribeResultMsg{ID: id, Err: err, Header: header}
+		}
+		indexes, _ := v.db.TableIndexes(ctx, schema, name)
+		fks, _ := v.db.TableForeignKeys(ctx, schema, name)
+		refs, _ := v.db.TableReferencedBy(ctx, schema, name)
+		constraints, _ := v.db.TableConstraints(ctx, schema, name)
+		comments, _ := v.db.TableComments(ctx, schema, name)
+		stats, _ := v.db.TableStatistics(ctx, schema, name)
+		hypertable, _ := v.db.DescribeHypertable(ctx, schema, name)
+
+		return DescribeResultMsg{
+			ID:     id,
+			Result: result, Indexes: indexes,
+			ForeignKeys: fks, ReferencedBy: refs,
+			Constraints: constraints, Comments: comments,
+			Stats:      stats,
+			Hypertable: hypertable,
+			Header:     header,
+		}
+	}
+}
+
+// fetchDependencies looks up what depends on a table or view.
+func (v *MainView) fetchDependencies(table string) tea.Cmd {
+	v.loading = true
+	id := v.newResultRequest()
+	schema, name := v.tableRef(table)
+	database := v.db
+	return func() tea.Msg {
+		report, err := database.Dependencies(context.Background(), schema, name)
+		return DependenciesMsg{ID: id, Report: report, Err: err}
+	}
+}
+
+// formatDependencies renders a dependency report as a tree of dependent
+// views, the foreign keys referencing the relation, and what DROP …
+// CASCADE would take with it.
+func formatDependencies(r *db.DependencyReport, style config.SQLStyleConfig) []string {
+	lines := []string{
+		StyleBold.Render(fmt.Sprintf("🔗 Dependencies of %s %s", r.Kind, r.QualifiedName())) +
+			StyleDimmed.Render("  ("+r.Summary()+")"),
+		"",
+	}
+
+	lines = append(lines, "── Dependent Views ──")
+	if len(r.Views) == 0 {
+		lines = append(lines, StyleDimmed.Render("  (none)"))
+	}
+	for _, dv := range r.Views {
+		line := strings.Repeat("  ", dv.Depth) + dv.Kind + " " + dv.Schema + "." + dv.Name
+		if dv.Depth > 1 {
+			line += StyleDimmed.Render("  via " + dv.Via)
+		}
+		lines = append(lines, line)
+	}
+
+	if len(r.ForeignKeys) > 0 {
+		lines = append(lines, "", "── Referenced By ──")
+		for _, k := range r.ForeignKeys {
+			lines = append(lines, fmt.Sprintf("  %s.%s  %s", k.Schema, k.Table, StyleDimmed.Render(k.Constraint)))
+		}
+	}
+
+	lines = append(lines, "", "── DROP … CASCADE Preview ──", "  "+db.ApplySQLStyle(r.DropCascadeSQL(), style))
+	effects := r.DropCascadeEffects()
+	if len(effects) == 0 {
+		lines = append(lines, StyleDimmed.Render("  drops only "+r.QualifiedName()))
+	} else {
+		lines = append(lines, StyleError.Render(fmt.Sprintf("  would also drop %d object(s):", len(effects))))
+		for _, e := range effects {
+			lines = append(lines, "    - "+e)
+		}
+	}
+	lines = append(lines, "", StyleDimmed.Render("Preview only — nothing has been executed."))
+	return lines
+}
+
+func (v *MainView) handleMetaCommand(cmd string) tea.Cmd {
+	// Simple meta commands
+	parts := strings.Fields(cmd)
+	switch parts[0] {
+	case "\\dt", "\\di", "\\dv", "\\d":
+		return v.fetchTables()
+	case "\\knn":
+		return v.nearestNeighbors(parts[1:])
+	case "\\recipe":
+		return v.recipeCommand(parts[1:])
+	case "\\every":
+		return v.every(strings.TrimSpace(strings.TrimPrefix(cmd, parts[0])))
+	case "\\upsert":
+		return v.upsert(parts[1:])
+	case "\\xlsx":
+		return v.exportXLSX(parts[1:])
+	case "\\export":
+		return v.streamExport(parts[1:])
+	case "\\geojson":
+		return v.exportGeoJSON(parts[1:])
+	case "\\fdw":
+		return v.foreignDataWrapper(parts[1:])
+	case "\\seed":
+		return v.seedTable(parts[1:])
+	case "\\pset":
+		return v.pset(parts[1:])
+	case "\\x":
+		return v.pset(append([]string{"expanded"}, parts[1:]...))
+	case "\\t":
+		return v.pset(append([]string{"tuples_only"}, parts[1:]...))
+	case "\\deps":
+		v.input.Reset()
+		if len(parts) < 2 {
+			v.viewport.SetContent(StyleError.Render("Usage: \\deps <table|view>"))
+			return nil
+		}
+		v.pagTable, v.pagPlan = "", false
+		return v.fetchDependencies(parts[1])
+	case "\\begin":
+		return v.beginTx()
+	case "\\commit":
+		return v.endTx(true)
+	case "\\rollback":
+		return v.endTx(false)
+	case "\\search_path":
+		v.showSearchPath()
+		return nil
+	case "\\deallocate":
+		return v.deallocate(parts[1:])
+	case "\\fmt":
+		v.formatSQL(strings.TrimSpace(strings.TrimPrefix(cmd, "\\fmt")))
+		return nil
+	case "\\review":
+		return v.reviewMigration(strings.TrimSpace(strings.TrimPrefix(cmd, "\\review")))
+	case "\\i":
+		return v.includeFile(strings.TrimSpace(strings.TrimPrefix(cmd, "\\i")))
+	case "\\goto":
+		return v.gotoRow(parts[1:])
+	case "\\filter":
+		return v.filterCommand(parts[1:])
+	case "\\warnings":
+		return v.warningsCommand(parts[1:])
+	case "\\refresh":
+		return v.refreshCommand(parts[1:])
+	case "\\sequences":
+		return v.openSequences(parts[1:])
+	case "\\dupes":
+		return v.findDuplicates(strings.TrimPrefix(cmd, parts[0]))
+	case "\\sort":
+		return v.sortTable(strings.TrimSpace(strings.TrimPrefix(cmd, parts[0])))
+	case "\\set":
+		if len(parts) >= 3 {
+			v.vars.Set(parts[1], strings.Join(parts[2:], " "))
+			v.viewport.SetContent(StyleSuccess.Render(fmt.Sprintf("SET %s = ...", parts[1])))
+		} else {
+			v.viewport.SetContentLines(v.vars.List())
+		}
+		v.input.Reset()
+		return nil
+	}
+	v.viewport.SetContent(StyleError.Render("Unknown command: " + cmd))
+	v.input.Reset()
+	return nil
+}
+
+// styleSQL rewrites generated SQL in the configured SQL style.
+func (v *MainView) styleSQL(sql string) string {
+	return db.ApplySQLStyle(sql, v.sqlStyle)
+}
+
+// formatSQL implements \fmt [sql]: it reformats sql, or the last statement
+// run when sql is empty, into the input buffer and shows the result in
+// full, since the input block only has room for a few lines.
+func (v *MainView) formatSQL(sql string) {
+	if sql == "" {
+		sql = v.lastSQL
+	}
+	if sql == "" {
+		v.input.Reset()
+		v.viewport.SetContent(StyleError.Render("Usage: \\fmt <sql> (or run a statement first)"))
+		return
+	}
+	// Quoting is the user's; only the keyword case follows the SQL style.
+	v.input.SetValue(db.ApplySQLStyle(db.FormatSQL(sql), config.SQLStyleConfig{
+		KeywordCase:      v.sqlStyle.KeywordCase,
+		QuoteIdentifiers: config.QuoteKeep,
+	}))
+	lines := append([]string{StyleBold.Render("Formatted SQL"), ""}, strings.Split(highlightSQL(v.input.Value()), "\n")...)
+	lines = append(lines, "", StyleDimmed.Render("Placed in the input; Enter runs it."))
+	v.viewport.SetContentLines(lines)
+}
+
+// showSearchPath implements \search_path: the schemas unqualified names
+// resolve to, which the table list and AI context cover.
+func (v *MainView) showSearchPath() {
+	v.input.Reset()
+	lines := []string{StyleBold.Render("search_path"), ""}
+	if len(v.db.SearchPath) == 0 {
+		lines = append(lines, StyleDimmed.Render("  (no existing schema on the search path)"))
+	}
+	for i, schema := range v.db.SearchPath {
+		lines = append(lines, fmt.Sprintf("  %d. %s", i+1, schema))
+	}
+	lines = append(lines, "", StyleDimmed.Render("Set a per-connection search_path under Settings → Search Path."))
+	v.viewport.SetContentLines(lines)
+}
+
+// deallocate implements \deallocate all: prepared statements cached
+// before a schema change are dropped and prepared again on next use.
+// Unlike a typed DEALLOCATE ALL, it also clears pgx's statement cache.
+func (v *MainView) deallocate(args []string) tea.Cmd {
+	v.input.Reset()
+	if len(args) != 1 || !strings.EqualFold(args[0], "all") {
+		v.viewport.SetContent(StyleError.Render("Usage: \\deallocate all"))
+		return nil
+	}
+	database := v.db
+	return func() tea.Msg {
+		n, err := database.DeallocateAll(context.Background())
+		if err != nil {
+			return StatusMsg("DEALLOCATE ALL failed: " + err.Error())
+		}
+		return StatusMsg(fmt.Sprintf("Dropped the prepared statements of %d idle connections", n))
+	}
+}
+
+// nearestNeighbors implements \knn <table> <column> <vector|record#> [limit].
+// The query vector is either a literal like [0.1,0.2,...] or the 1-based
+// record number of a row in the current result that has that column.
+func (v *MainView) nearestNeighbors(args []string) tea.Cmd {
+	v.input.Reset()
+	if len(args) < 3 {
+		v.viewport.SetContent(StyleError.Render("Usage: \\knn <table> <column> <vector|record#> [limit]"))
+		return nil
+	}
+	table, column, vec := args[0], args[1], args[2]
+	limit := 10
+	if len(args) >= 4 {
+		if n, err := strconv.Atoi(args[3]); err == nil && n > 0 {
+			limit = n
+		}
+	}
+
+	// A record number refers to a row of the current result
+	if n, err := strconv.Atoi(vec); err == nil {
+		if v.result == nil || n < 1 || n > len(v.result.Rows) {
+			v.viewport.SetContent(StyleError.Render(fmt.Sprintf("No record %d in the current result", n)))
+			return nil
+		}
+		colIdx := -1
+		for i, c := range v.result.Columns {
+			if c == column {
+				colIdx = i
+				break
+			}
+		}
+		if colIdx < 0 {
+			v.viewport.SetContent(StyleError.Render("Column not in current result: " + column))
+			return nil
+		}
+		vec = v.result.Rows[n-1][colIdx]
+	}
+
+	v.loading = true
+	v.pagTable, v.pagPlan = "", false
+	schema, name := v.tableRef(table)
+	v.lastSQL = v.styleSQL(db.NearestNeighborsSQL(schema, name, column, vec, limit))
+	database := v.db
+	id := v.newResultRequest()
+	ctx := v.queryContext()
+	return func() tea.Msg {
+		result, err := database.NearestNeighbors(ctx, schema, name, column, vec, limit)
+		return QueryResultMsg{ID: id, Result: result, Err: err}
+	}
+}
+
+// exportGeoJSON implements \geojson <file>: writes the loaded rows of the
+// result as a GeoJSON FeatureCollection.
+func (v *MainView) exportGeoJSON(args []string) tea.Cmd {
+	v.input.Reset()
+	if len(args) < 1 {
+		v.viewport.SetContent(StyleError.Render("Usage: \\geojson <file>"))
+		return nil
+	}
+	if v.result == nil || len(v.result.Columns) == 0 {
+		v.viewport.SetContent(StyleError.Render("Run a query with a geometry column first"))
+		return nil
+	}
+	path := strings.Join(args, " ")
+	r := v.result
+	note := ""
+	if v.paged() {
+		note = " (this page)"
+	}
+	return func() tea.Msg {
+		var buf bytes.Buffer
+		n, err := db.WriteGeoJSON(&buf, r)
+		if err == nil {
+			err = os.WriteFile(path, buf.Bytes(), 0644)
+		}
+		if err != nil {
+			return StatusMsg("GeoJSON export failed: " + err.Error())
+		}
+		return StatusMsg(fmt.Sprintf("Exported %d features%s to %s", n, note, path))
+	}
+}
+
+// foreignDataWrapper implements \fdw <connection> [remote_schema] [local_schema],
+// which shows the postgres_fdw statements linking a saved connection's
+// database into this one, and \fdw apply, which executes them.
+func (v *MainView) foreignDataWrapper(args []string) tea.Cmd {
+	v.input.Reset()
+	if len(args) < 1 {
+		v.viewport.SetContent(StyleError.Render("Usage: \\fdw <connection> [remote_schema] [local_schema]  |  \\fdw apply"))
+		return nil
+	}
+
+	if args[0] == "apply" {
+		if v.pendingFDW == nil {
+			v.viewport.SetContent(StyleError.Render("Nothing to apply — run \\fdw <connection> first"))
 			return nil
 		}
+		v.loading = true
+		plan := v.pendingFDW
+		database := v.db
+		return func() tea.Msg {
+			err := database.ApplyFDWPlan(context.Background(), plan)
+			return FDWAppliedMsg{Plan: plan, Err: err}
+		}
 	}
 
-	if strings.HasPrefix(input, "\\") {
-		return v.handleMetaCommand(input)
+	store, err := config.NewConnectionStore()
+	if err != nil {
+		v.viewport.SetContent(StyleError.Render("Load connections: " + err.Error()))
+		return nil
 	}
-	sql := v.vars.Expand(input)
-	v.loading = true
-	v.input = ""
-	v.lastSQL = strings.Join(strings.Fields(sql), " ") + ";"
-	return func() tea.Msg {
-		result, err := v.db.Execute(context.Background(), sql)
-		return QueryResultMsg{Result: result, Err: err}
+	conn, ok := store.Get(args[0])
+	if !ok {
+		var names []string
+		for _, c := range store.Connections {
+			names = append(names, c.Name)
+		}
+		v.viewport.SetContentLines([]string{
+			StyleError.Render("No saved connection named " + args[0]),
+			"",
+			StyleDimmed.Render("Saved connections: " + strings.Join(names, ", ")),
+		})
+		return nil
 	}
-}
 
-// fetchPage runs a paginated SELECT for the current table.
-func (v *MainView) fetchPage() tea.Cmd {
-	table := v.pagTable
-	page := v.pagPage
-	pageSize := v.pagPageSize
-	v.loading = true
-	offset := page * pageSize
-	v.lastSQL = fmt.Sprintf("SELECT * FROM %s LIMIT %d OFFSET %d;", table, pageSize, offset)
-	return func() tea.Msg {
-		ctx := context.Background()
+	var remoteSchema, localSchema string
+	if len(args) >= 2 {
+		remoteSchema = args[1]
+	}
+	if len(args) >= 3 {
+		localSchema = args[2]
+	}
+	plan := db.NewFDWPlan(conn, remoteSchema, localSchema)
+	v.pendingFDW = plan
 
-		// Get real row count
-		var total int64
-		countSQL := fmt.Sprintf("SELECT count(*) FROM %s", table)
-		_ = v.db.Pool.QueryRow(ctx, countSQL).Scan(&total)
+	lines := []string{
+		StyleBold.Render(fmt.Sprintf("🔗 postgres_fdw setup for %q → schema %s", conn.Name, plan.LocalSchema)),
+		"",
+		"Review the statements below:",
+		"",
+	}
+	lines = append(lines, plan.DisplayStatements(conn.Password)...)
+	if len(plan.Warnings) > 0 {
+		lines = append(lines, "")
+		for _, w := range plan.Warnings {
+			lines = append(lines, "⚠️  "+w)
+		}
+	}
+	lines = append(lines, "",
+		StyleDimmed.Render("Run \\fdw apply to execute them in one transaction."))
+	v.viewport.SetContentLines(lines)
+	v.viewport.Home()
+	return nil
+}
 
-		// Get table size info
-		var totalSize, tableSize, indexSize string
-		sizeSQL := `SELECT pg_size_pretty(pg_total_relation_size($1)),
-		                   pg_size_pretty(pg_relation_size($1)),
-		                   pg_size_pretty(pg_indexes_size($1))`
-		_ = v.db.Pool.QueryRow(ctx, sizeSQL, table).Scan(&totalSize, &tableSize, &indexSize)
+// pset implements \pset [option [value]] for the session's display
+// options. Without a value, boolean options toggle like in psql.
+func (v *MainView) pset(args []string) tea.Cmd {
+	v.input.Reset()
+	if len(args) == 0 {
+		v.viewport.SetContentLines(v.psetList())
+		return nil
+	}
 
-		offset := page * pageSize
-		sql := fmt.Sprintf("SELECT * FROM %s LIMIT %d OFFSET %d", table, pageSize, offset)
+	value := ""
+	if len(args) >= 2 {
+		value = strings.ToLower(args[1])
+	}
+	toggle := func(cur bool) (bool, error) {
+		switch value {
+		case "":
+			return !cur, nil
+		case "on", "true", "yes", "1":
+			return true, nil
+		case "off", "false", "no", "0":
+			return false, nil
+		}
+		return cur, fmt.Errorf("unrecognized value %q: expected on or off", args[1])
+	}
+
+	var err error
+	var status string
+	switch strings.ToLower(args[0]) {
+	case "numericlocale":
+		v.display.NumericLocale, err = toggle(v.display.NumericLocale)
+		status = "Locale-adjusted numeric output is " + onOff(v.display.NumericLocale)
+	case "decimals":
+		switch value {
+		case "", "off":
+			v.display.Decimals = nil
+			status = "Decimal places are shown as returned"
+		default:
+			n, convErr := strconv.Atoi(value)
+			if convErr != nil || n < 0 || n > 20 {
+				err = fmt.Errorf("decimals must be a number from 0 to 20, or off")
+				break
+			}
+			v.display.Decimals = &n
+			status = fmt.Sprintf("Numbers are shown with %d decimal places", n)
+		}
+	case "localtime":
+		v.display.LocalTime, err = toggle(v.display.LocalTime)
+		if v.display.LocalTime {
+			status = "timestamptz values are shown in local time"
+		} else {
+			status = "timestamptz values are shown in UTC"
+		}
+	case "border":
+		n, convErr := strconv.Atoi(value)
+		if convErr != nil || n < 0 || n > 2 {
+			err = fmt.Errorf("border must be 0, 1 or 2")
+			break
+		}
+		v.printOpts.border = n
+		status = fmt.Sprintf("Border style is %d", n)
+	case "null":
+		if len(args) >= 2 {
+			v.printOpts.null = strings.Trim(strings.Join(args[1:], " "), "'")
+		}
+		status = fmt.Sprintf("Null display is %q", v.printOpts.null)
+	case "format":
+		switch value {
+		case formatAligned, formatUnaligned, formatCSV:
+			v.printOpts.format = value
+			v.expandedMode = false
+		case "expanded":
+			v.printOpts.format = formatAligned
+			v.expandedMode = true
+		default:
+			err = fmt.Errorf("format must be aligned, unaligned, csv or expanded")
+		}
+		status = "Output format is " + v.outputFormat()
+	case "expanded", "x":
+		v.expandedMode, err = toggle(v.expandedMode)
+		status = "Expanded display is " + onOff(v.expandedMode)
+	case "tuples_only", "t":
+		v.printOpts.tuplesOnly, err = toggle(v.printOpts.tuplesOnly)
+		status = "Tuples only is " + onOff(v.printOpts.tuplesOnly)
+	default:
+		err = fmt.Errorf("unknown \\pset option %q", args[0])
+	}
+	if err != nil {
+		v.viewport.SetContent(StyleError.Render(err.Error()))
+		return nil
+	}
 
-		info := fmt.Sprintf("🔍 %s;\n📊 %s  |  Total: %-8s  |  Table: %-8s  |  Indexes: %-8s  |  %d rows",
-			sql, table, totalSize, tableSize, indexSize, total)
+	v.redrawResult()
+	return func() tea.Msg { return StatusMsg(status) }
+}
 
-		result, err := v.db.Execute(ctx, sql)
-		if result != nil {
-			lastRow := offset + result.RowCount
-			totalPages := maxPageCalc(total, int64(pageSize)) + 1
-			result.Status = fmt.Sprintf("Page %d/%d  |  Rows %d–%d of %d",
-				page+1, totalPages,
-				offset+1, lastRow,
-				total)
-		}
-		return QueryResultMsg{Result: result, Err: err, PagTotal: total, PagInfo: info}
+// psetList renders the current display options, like \pset without arguments.
+func (v *MainView) psetList() []string {
+	decimals := "off"
+	if v.display.Decimals != nil {
+		decimals = strconv.Itoa(*v.display.Decimals)
+	}
+	return []string{
+		fmt.Sprintf("%-16s %d", "border", v.printOpts.border),
+		fmt.Sprintf("%-16s %s", "expanded", onOff(v.expandedMode)),
+		fmt.Sprintf("%-16s %s", "format", v.outputFormat()),
+		fmt.Sprintf("%-16s %q", "null", v.printOpts.null),
+		fmt.Sprintf("%-16s %s", "tuples_only", onOff(v.printOpts.tuplesOnly)),
+		fmt.Sprintf("%-16s %s", "numericlocale", onOff(v.display.NumericLocale)),
+		fmt.Sprintf("%-16s %s", "decimals", decimals),
+		fmt.Sprintf("%-16s %s", "localtime", onOff(v.display.LocalTime)),
 	}
 }
 
-func (v *MainView) maxPage() int {
-	if v.pagTotal <= 0 || v.pagPageSize <= 0 {
-		return 0
+// outputFormat names the current \pset format; expanded display counts
+// as a format of its own.
+func (v *MainView) outputFormat() string {
+	if v.expandedMode {
+		return "expanded"
 	}
-	return int((v.pagTotal - 1) / int64(v.pagPageSize))
+	return v.printOpts.format
 }
 
-func maxPageCalc(total int64, pageSize int64) int64 {
-	if total <= 0 || pageSize <= 0 {
-		return 0
+func onOff(b bool) string {
+	if b {
+		return "on"
 	}
-	return (total - 1) / pageSize
+	return "off"
 }
 
-// fetchDescribe queries the table schema and returns a DescribeResultMsg.
-func (v *MainView) fetchDescribe(table string) tea.Cmd {
+// seedTable implements \seed <table> <rows> [ai], which generates fake
+// rows for preview, and \seed apply, which inserts them.
+func (v *MainView) seedTable(args []string) tea.Cmd {
+	v.input.Reset()
+	if len(args) == 1 && args[0] == "apply" {
+		if v.pendingSeed == nil {
+			v.viewport.SetContent(StyleError.Render("Nothing to insert — run \\seed <table> <rows> first"))
+			return nil
+		}
+		v.loading = true
+		data := v.pendingSeed
+		database := v.db
+		return func() tea.Msg {
+			n, err := database.InsertSeed(context.Background(), data)
+			return SeedInsertedMsg{Table: data.Table, Count: n, Err: err}
+		}
+	}
+
+	n := 0
+	if len(args) >= 2 {
+		n, _ = strconv.Atoi(args[1])
+	}
+	if n <= 0 {
+		v.viewport.SetContent(StyleError.Render("Usage: \\seed <table> <rows> [ai]  |  \\seed apply"))
+		return nil
+	}
+	table := args[0]
+	schema, name := v.tableRef(table)
+	useAI := len(args) >= 3 && args[2] == "ai"
+
 	v.loading = true
+	database := v.db
+	provider := v.aiProvider
 	return func() tea.Msg {
 		ctx := context.Background()
-
-		// Get table size info
-		var totalSize, tableSize, indexSize string
-		var rowCount int64
-		sizeSQL := `SELECT pg_size_pretty(pg_total_relation_size($1)),
-		                   pg_size_pretty(pg_relation_size($1)),
-		                   pg_size_pretty(pg_indexes_size($1))`
-		_ = v.db.Pool.QueryRow(ctx, sizeSQL, table).Scan(&totalSize, &tableSize, &indexSize)
-		_ = v.db.Pool.QueryRow(ctx, fmt.Sprintf("SELECT count(*) FROM %s", table)).Scan(&rowCount)
-
-		header := fmt.Sprintf("📋 %s  |  Total: %s  |  Table: %s  |  Indexes: %s  |  %d rows",
-			table, totalSize, tableSize, indexSize, rowCount)
-
-		result, err := v.db.DescribeTable(ctx, "public", table)
+		seeder, err := database.NewSeeder(ctx, schema, name)
 		if err != nil {
-			return DescribeResultMsg{Err: err, Header: header}
+			return SeedPreviewMsg{Err: err}
 		}
-		indexes, _ := v.db.TableIndexes(ctx, "public", table)
-		fks, _ := v.db.TableForeignKeys(ctx, "public", table)
-		refs, _ := v.db.TableReferencedBy(ctx, "public", table)
-
-		return DescribeResultMsg{
-			Result: result, Indexes: indexes,
-			ForeignKeys: fks, ReferencedBy: refs,
-			Header: header,
+		if !useAI {
+			return SeedPreviewMsg{Data: seeder.Generate(n), Note: "generated"}
 		}
-	}
-}
 
-func (v *MainView) handleMetaCommand(cmd string) tea.Cmd {
-	// Simple meta commands
-	parts := strings.Fields(cmd)
-	switch parts[0] {
-	case "\\dt", "\\di", "\\dv", "\\d":
-		return v.fetchTables()
-	case "\\set":
-		if len(parts) >= 3 {
-			v.vars.Set(parts[1], strings.Join(parts[2:], " "))
-			v.viewport.SetContent(StyleSuccess.Render(fmt.Sprintf("SET %s = ...", parts[1])))
-		} else {
-			v.viewport.SetContentLines(v.vars.List())
+		records, err := ai.GenerateSeedRows(ctx, provider, seeder.Describe(), n)
+		if err != nil {
+			return SeedPreviewMsg{Err: fmt.Errorf("AI: %w", err)}
 		}
-		v.input = ""
-		return nil
+		note := fmt.Sprintf("%d from %s", min(len(records), n), provider.Name())
+		if len(records) < n {
+			note += fmt.Sprintf(", %d generated", n-len(records))
+		}
+		return SeedPreviewMsg{Data: seeder.FromRecords(records, n), Note: note}
 	}
-	v.viewport.SetContent(StyleError.Render("Unknown command: " + cmd))
-	v.input = ""
-	return nil
 }
 
 // ══════════════════════════════════════════
@@ -823,28 +2360,48 @@ func (v *MainView) handleChatInputKey(msg tea.KeyMsg) (View, tea.Cmd) {
 	switch msg.String() {
 	case "enter":
 		return v, v.sendChatMessage()
+	case "alt+enter", "shift+enter", "ctrl+j":
+		v.chatInput.Insert("\n")
 	case "ctrl+l":
 		v.chatMessages = nil
-		v.chatInput = ""
 		v.viewport.SetContentLines(v.renderChatHistory())
 		return v, nil
-	case "backspace":
-		if len(v.chatInput) > 0 {
-			v.chatInput = v.chatInput[:len(v.chatInput)-1]
+	case "up":
+		if v.chatHistIdx < len(v.chatHistory)-1 {
+			if v.chatHistIdx < 0 {
+				v.chatDraft = v.chatInput.Value()
+			}
+			v.chatHistIdx++
+			v.chatInput.SetValue(v.chatHistory[v.chatHistIdx])
 		}
-	default:
-		// Accept typed characters AND pasted text (bracketed paste).
-		if msg.Type == tea.KeyRunes {
-			v.chatInput += string(msg.Runes)
-		} else if msg.Type == tea.KeySpace {
-			v.chatInput += " "
+	case "down":
+		if v.chatHistIdx > 0 {
+			v.chatHistIdx--
+			v.chatInput.SetValue(v.chatHistory[v.chatHistIdx])
+		} else if v.chatHistIdx == 0 {
+			v.chatHistIdx = -1
+			v.chatInput.SetValue(v.chatDraft)
+			v.chatDraft = ""
 		}
+	default:
+		v.chatInput.Update(msg)
 	}
 	return v, nil
 }
 
+// multilineInput lays out a multi-line input after its prompt: later
+// lines are indented by the prompt's width, and only the last maxLines
+// are kept.
+func multilineInput(text string, indent, maxLines int) string {
+	lines := strings.Split(text, "\n")
+	if len(lines) > maxLines {
+		lines = lines[len(lines)-maxLines:]
+	}
+	return strings.Join(lines, "\n"+strings.Repeat(" ", indent))
+}
+
 func (v *MainView) sendChatMessage() tea.Cmd {
-	text := strings.TrimSpace(v.chatInput)
+	text := strings.TrimSpace(v.chatInput.Value())
 	if text == "" {
 		return nil
 	}
@@ -853,13 +2410,25 @@ func (v *MainView) sendChatMessage() tea.Cmd {
 		Role:    "user",
 		Content: text,
 	})
-	v.chatInput = ""
-	v.chatLoading = true
+	v.chatInput.Reset()
+	v.chatDraft, v.chatHistIdx = "", -1
+	if len(v.chatHistory) == 0 || v.chatHistory[0] != text {
+		v.chatHistory = append([]string{text}, v.chatHistory...)
+	}
+	v.chatLoading, v.chatPartial = true, ""
 	v.viewport.SetContentLines(v.renderChatHistory())
 	v.viewport.End()
 
-	// Check for pagination commands using the saved query plan
+	// Semantic column search: "/find customer emails" or "which table has ...?"
 	lowerText := strings.ToLower(text)
+	if strings.HasPrefix(lowerText, "/find ") {
+		return v.searchColumns(strings.TrimSpace(text[len("/find "):]))
+	}
+	if strings.HasPrefix(lowerText, "which table") || strings.HasPrefix(lowerText, "which column") {
+		return v.searchColumns(text)
+	}
+
+	// Check for pagination commands using the saved query plan
 	if v.lastQueryPlan != nil {
 		if strings.Contains(lowerText, "next page") {
 			v.lastQueryPlan.Page++
@@ -873,8 +2442,20 @@ func (v *MainView) sendChatMessage() tea.Cmd {
 		}
 	}
 
+	// Find the tables relevant to the question first, if enabled
+	v.retrieved = nil
+	if e := v.schemaEmbedder(); e != nil {
+		return v.retrieveSchema(e, text)
+	}
+	return v.answerChat(text)
+}
+
+// answerChat answers a question with a query plan for the selected table,
+// or with a regular chat reply.
+func (v *MainView) answerChat(text string) tea.Cmd {
 	// Check if a table is selected — if yes, use query plan generation
 	if v.tableIdx >= 0 && v.tableIdx < len(v.tables) && v.db != nil {
+		v.lastQuestion = text
 		return v.generateQueryPlan(text)
 	}
 
@@ -888,21 +2469,8 @@ func (v *MainView) sendChatMessage() tea.Cmd {
 		msgs = append([]ai.Message{{Role: "system", Content: ctxMsg}}, msgs...)
 	}
 
-	provider := v.aiProvider
-	providerName := provider.Name()
-	return func() tea.Msg {
-		var inputSummary string
-		for _, m := range msgs {
-			inputSummary += m.Role + ": " + m.Content + "\n"
-		}
-		ai.LogAIRequest("Chat", providerName, map[string]string{
-			"Messages": inputSummary,
-		})
-
-		resp, err := provider.Chat(context.Background(), msgs)
-		ai.LogAIResponse("Chat", resp, err)
-		return AIResponseMsg{Response: resp, Err: err}
-	}
+	v.chatReq++
+	return chatCmd(v.aiProvider, v.chatReq, msgs)
 }
 
 // generateQueryPlan sends the user's question to the AI with full schema context
@@ -910,7 +2478,18 @@ func (v *MainView) sendChatMessage() tea.Cmd {
 func (v *MainView) generateQueryPlan(question string) tea.Cmd {
 	provider := v.aiProvider
 	database := v.db
+	style := v.sqlStyle
 	table := v.tables[v.tableIdx]
+	schema, name := v.tableRef(table)
+	retrieved := v.retrievedContext(table)
+	formatSchema := v.schemaFormatter()
+	var guard *db.PlanGuard
+	if v.appConfig == nil || !v.appConfig.AI.DisablePlanGuard {
+		guard = &db.PlanGuard{
+			Tables:  append([]string{table}, v.retrieved...),
+			Schemas: append([]string{schema}, v.db.SearchPath...),
+		}
+	}
 
 	// Build data view state string
 	var dataViewState string
@@ -928,20 +2507,21 @@ func (v *MainView) generateQueryPlan(question string) tea.Cmd {
 		ctx := context.Background()
 
 		// Fetch schema for the current table
-		mainSchema, err := database.FetchTableSchema(ctx, "public", table)
+		mainSchema, err := database.FetchTableSchema(ctx, schema, name)
 		if err != nil {
 			return QueryPlanMsg{Err: fmt.Errorf("failed to fetch schema for %s: %w", table, err)}
 		}
+		mainSchema.Name = table // keep the qualifier of same-named tables
 
 		// Fetch schemas for FK-related tables
-		relatedSchemas, err := database.FetchRelatedSchemas(ctx, "public", mainSchema)
+		relatedSchemas, err := database.FetchRelatedSchemas(ctx, schema, mainSchema)
 		if err != nil {
 			// Non-fatal — we can still generate a plan without related schemas
 			relatedSchemas = make(map[string]*db.TableSchema)
 		}
 
 		// Build the schema context text
-		schemaContext := db.FormatSchemaContext(mainSchema, relatedSchemas)
+		schemaContext := formatSchema(mainSchema, relatedSchemas) + retrieved
 
 		// Log the request
 		providerName := fmt.Sprintf("%T", provider)
@@ -967,6 +2547,16 @@ func (v *MainView) generateQueryPlan(question string) tea.Cmd {
 			ai.LogQueryPlanResponse(rawResponse, plan, "", err)
 			return QueryPlanMsg{Plan: plan, Err: fmt.Errorf("SQL generation error: %w", err), RawResponse: rawResponse}
 		}
+		if guard != nil {
+			for related := range relatedSchemas {
+				guard.Tables = append(guard.Tables, related)
+			}
+			if err := guard.Check(sql); err != nil {
+				ai.LogQueryPlanResponse(rawResponse, plan, sql, err)
+				return QueryPlanMsg{Err: fmt.Errorf("plan refused: %w", err), RawResponse: rawResponse}
+			}
+		}
+		sql = db.ApplySQLStyle(sql, style)
 
 		// Log the successful response
 		ai.LogQueryPlanResponse(rawResponse, plan, sql, nil)
@@ -975,6 +2565,66 @@ func (v *MainView) generateQueryPlan(question string) tea.Cmd {
 	}
 }
 
+// searchColumns asks the AI which columns across the whole schema match
+// description. The schema index is fetched on first use and cached.
+func (v *MainView) searchColumns(description string) tea.Cmd {
+	provider := v.aiProvider
+	database := v.db
+	cached := v.schemaIndex
+	return func() tea.Msg {
+		ctx := context.Background()
+		idx := cached
+		var fresh *db.SchemaIndex
+		if idx == nil {
+			var err error
+			idx, err = database.FetchSchemaIndex(ctx, "")
+			if err != nil {
+				return ColumnSearchMsg{Description: description, Err: fmt.Errorf("failed to index schema: %w", err)}
+			}
+			fresh = idx
+		}
+		matches, err := ai.SearchColumns(ctx, provider, idx.Format(), description)
+		if err != nil {
+			return ColumnSearchMsg{Description: description, Index: fresh, Err: err}
+		}
+
+		// Drop hallucinated columns that aren't in the index
+		var valid []ai.ColumnMatch
+		for _, m := range matches {
+			if _, ok := idx.Column(m.Table, m.Column); ok {
+				valid = append(valid, m)
+			}
+		}
+		return ColumnSearchMsg{Description: description, Matches: valid, Index: fresh}
+	}
+}
+
+// formatColumnMatches renders column search results as a chat message.
+func (v *MainView) formatColumnMatches(msg ColumnSearchMsg) string {
+	if msg.Err != nil {
+		return "❌ Column search error: " + msg.Err.Error()
+	}
+	if len(msg.Matches) == 0 {
+		return fmt.Sprintf("🔎 No columns found matching %q.", msg.Description)
+	}
+
+	lines := []string{fmt.Sprintf("🔎 Columns matching %q:", msg.Description), ""}
+	for _, m := range msg.Matches {
+		dataType := ""
+		if v.schemaIndex != nil {
+			if col, ok := v.schemaIndex.Column(m.Table, m.Column); ok {
+				dataType = " (" + col.DataType + ")"
+			}
+		}
+		line := fmt.Sprintf("%3.0f%%  %s.%s%s", m.Confidence*100, m.Table, m.Column, dataType)
+		if m.Reason != "" {
+			line += " — " + m.Reason
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
 // executeQueryPlan is used for pagination — it takes an existing plan and
 // re-generates the SQL with updated page/sort.
 func (v *MainView) executeQueryPlan(plan *ai.QueryPlan) tea.Cmd {
@@ -987,10 +2637,12 @@ func (v *MainView) executeQueryPlan(plan *ai.QueryPlan) tea.Cmd {
 		v.viewport.SetContentLines(v.renderChatHistory())
 		return nil
 	}
+	sql = v.styleSQL(sql)
 
 	// Sync pagination state
 	v.pagPage = plan.Page - 1
 	v.pagPageSize = plan.Limit
+	v.pagPlan = true
 
 	v.chatMessages = append(v.chatMessages, ai.Message{
 		Role:    "assistant",
@@ -1004,6 +2656,22 @@ func (v *MainView) executeQueryPlan(plan *ai.QueryPlan) tea.Cmd {
 	return v.fetchQueryPlanPage(plan, sql)
 }
 
+// pageQueryPlan shows page (0-based) of the last AI plan's result, as
+// PgUp/PgDn do for a browsed table.
+func (v *MainView) pageQueryPlan(page int) tea.Cmd {
+	plan := v.lastQueryPlan
+	plan.Page = page + 1
+	sql, err := plan.ToSQL()
+	if err != nil {
+		return func() tea.Msg { return StatusMsg("SQL generation error: " + err.Error()) }
+	}
+	sql = v.styleSQL(sql)
+	v.pagPage = page
+	v.lastSQL = strings.Join(strings.Fields(sql), " ") + ";"
+	v.loading = true
+	return v.fetchQueryPlanPage(plan, sql)
+}
+
 // fetchQueryPlanPage executes a query plan's SQL and builds the same rich info
 // header as fetchPage() — table name, sizes, pagination, and sort info.
 func (v *MainView) fetchQueryPlanPage(plan *ai.QueryPlan, sql string) tea.Cmd {
@@ -1020,10 +2688,17 @@ func (v *MainView) fetchQueryPlanPage(plan *ai.QueryPlan, sql string) tea.Cmd {
 
 	// Build the count SQL that matches the same JOINs and filters
 	countSQL := plan.ToCountSQL()
+	// Only the first page answers the question; paging through the rest
+	// doesn't ask the AI to interpret each one again.
+	question := ""
+	if page <= 1 {
+		question = v.lastQuestion
+	}
 
 	database := v.db
+	id := v.newResultRequest()
+	ctx := v.queryContext()
 	return func() tea.Msg {
-		ctx := context.Background()
 
 		// Get filtered row count (using same JOINs + WHERE as the main query)
 		var total int64
@@ -1062,10 +2737,39 @@ func (v *MainView) fetchQueryPlanPage(plan *ai.QueryPlan, sql string) tea.Cmd {
 				total)
 		}
 
-		return QueryResultMsg{Result: result, Err: err, PagTotal: total, PagInfo: info}
+		return QueryResultMsg{ID: id, Result: result, Err: err, PagTotal: total, PagInfo: info, Question: question}
 	}
 }
 
+// interpretEnabled reports whether AI plan results should be interpreted.
+func (v *MainView) interpretEnabled() bool {
+	return v.appConfig != nil && v.appConfig.AI.InterpretResults
+}
+
+// interpretResult sends the question and the first rows of the result
+// of request id back to the AI for a one-paragraph natural-language answer.
+func (v *MainView) interpretResult(id int, question string, result *db.QueryResult, total int64) tea.Cmd {
+	provider := v.aiProvider
+	maxRows := v.appConfig.AI.InterpretRows
+	columns := result.Columns
+	rows := result.Rows
+	if total == 0 {
+		total = int64(result.RowCount)
+	}
+	return func() tea.Msg {
+		answer, err := ai.InterpretResult(context.Background(), provider, question, columns, rows, total, maxRows)
+		return InterpretMsg{ID: id, Answer: answer, Err: err}
+	}
+}
+
+// schemaFormatter returns the configured schema context format.
+func (v *MainView) schemaFormatter() func(*db.TableSchema, map[string]*db.TableSchema) string {
+	if v.appConfig != nil && v.appConfig.AI.CompactSchema {
+		return db.FormatSchemaContextCompact
+	}
+	return db.FormatSchemaContext
+}
+
 // buildDBContext returns a system message with the current database context.
 // Used as fallback for regular chat when no table is selected.
 func (v *MainView) buildDBContext() string {
@@ -1088,18 +2792,25 @@ func (v *MainView) buildDBContext() string {
 
 		// Fetch full schema (columns + FKs)
 		ctx := context.Background()
-		mainSchema, err := v.db.FetchTableSchema(ctx, "public", table)
+		schema, name := v.tableRef(table)
+		mainSchema, err := v.db.FetchTableSchema(ctx, schema, name)
 		if err == nil && mainSchema != nil {
-			relatedSchemas, _ := v.db.FetchRelatedSchemas(ctx, "public", mainSchema)
+			mainSchema.Name = table
+			relatedSchemas, _ := v.db.FetchRelatedSchemas(ctx, schema, mainSchema)
 			if relatedSchemas == nil {
 				relatedSchemas = make(map[string]*db.TableSchema)
 			}
 			sb.WriteString("\n")
-			sb.WriteString(db.FormatSchemaContext(mainSchema, relatedSchemas))
+			sb.WriteString(v.schemaFormatter()(mainSchema, relatedSchemas))
 		}
+		sb.WriteString(v.retrievedContext(table))
+		sb.WriteString("\nWhen the user asks about 'this table' or gives a natural language query, generate SQL for the selected table above.")
+	} else if retrieved := v.retrievedContext(""); retrieved != "" {
+		sb.WriteString(retrieved)
+		sb.WriteString("\nWhen the user gives a natural language query, generate SQL using the relevant tables above.")
+	} else {
+		sb.WriteString("\nWhen the user asks about 'this table' or gives a natural language query, generate SQL for the selected table above.")
 	}
-
-	sb.WriteString("\nWhen the user asks about 'this table' or gives a natural language query, generate SQL for the selected table above.")
 	sb.WriteString("\nAlways output executable PostgreSQL queries the user can copy-paste.")
 	return sb.String()
 }
@@ -1113,6 +2824,7 @@ func (v *MainView) renderChatHistory() []string {
 
 	if len(v.chatMessages) == 0 {
 		lines = append(lines, StyleDimmed.Render("Ask anything about your database..."))
+		lines = append(lines, StyleDimmed.Render("Type /find <description> to search columns across all tables."))
 		lines = append(lines, StyleDimmed.Render("Press F2 to switch back to SQL."))
 		return lines
 	}
@@ -1126,18 +2838,23 @@ func (v *MainView) renderChatHistory() []string {
 	for _, msg := range v.chatMessages {
 		switch msg.Role {
 		case "user":
-			lines = append(lines, userStyle.Render("You: ")+msg.Content)
+			lines = append(lines, strings.Split(userStyle.Render("You: ")+multilineInput(msg.Content, 5, math.MaxInt), "\n")...)
 			lines = append(lines, "")
 		case "assistant":
 			lines = append(lines, assistantStyle.Render("AI: "))
-			for _, line := range strings.Split(msg.Content, "\n") {
+			for _, line := range highlightCodeFences(msg.Content) {
 				lines = append(lines, "  "+line)
 			}
 			lines = append(lines, "")
 		}
 	}
 
-	if v.chatLoading {
+	if v.chatLoading && v.chatPartial != "" {
+		lines = append(lines, assistantStyle.Render("AI: "))
+		for _, line := range highlightCodeFences(v.chatPartial) {
+			lines = append(lines, "  "+line)
+		}
+	} else if v.chatLoading {
 		lines = append(lines, StyleDimmed.Render("  ⏳ Thinking..."))
 	}
 
@@ -1145,17 +2862,40 @@ func (v *MainView) renderChatHistory() []string {
 }
 
 func (v *MainView) formatResult(r *db.QueryResult) []string {
+	v.gridCols, v.gridWidth = nil, 0
 	if r == nil || len(r.Columns) == 0 {
 		return []string{StyleDimmed.Render(r.Status)}
 	}
 
 	runeLen := utf8.RuneCountInString
+	rows := v.printOpts.withNullDisplay(displayRows(r, v.display))
+	switch v.printOpts.format {
+	case formatCSV:
+		return v.printOpts.formatResultCSV(r, rows)
+	case formatUnaligned:
+		return v.printOpts.formatResultUnaligned(r, rows)
+	}
+
+	types := r.ColumnTypes
+	if len(types) != len(r.Columns) {
+		types = make([]string, len(r.Columns))
+	}
 
 	widths := make([]int, len(r.Columns))
 	for i, col := range r.Columns {
 		widths[i] = runeLen(col)
+		if v.showTypes && runeLen(types[i]) > widths[i] {
+			widths[i] = runeLen(types[i])
+		}
 	}
-	for _, row := range r.Rows {
+	// Measuring every cell of a huge result takes seconds, so widths come
+	// from the first rows unless a re-measure was requested; wider cells
+	// further down are truncated like any other over-long value.
+	measured := rows
+	if !v.measureAll && len(measured) > widthSampleRows {
+		measured = measured[:widthSampleRows]
+	}
+	for _, row := range measured {
 		for i, cell := range row {
 			if i < len(widths) && runeLen(cell) > widths[i] {
 				widths[i] = runeLen(cell)
@@ -1167,42 +2907,137 @@ func (v *MainView) formatResult(r *db.QueryResult) []string {
 			widths[i] = 50
 		}
 	}
+
+	opts := v.printOpts
+	v.gridCols, v.gridWidth = gridSpans(widths, opts.border)
+	pad := func(cells []string) []string {
+		padded := make([]string, len(widths))
+		for i := range widths {
+			cell := ""
+			if i < len(cells) {
+				cell = cells[i]
+			}
+			if runeLen(cell) > widths[i] {
+				runes := []rune(cell)
+				cell = string(runes[:widths[i]-1]) + "…"
+			}
+			if isNumericType(types[i]) {
+				padded[i] = fmt.Sprintf("%*s", widths[i], cell)
+			} else {
+				padded[i] = fmt.Sprintf("%-*s", widths[i], cell)
+			}
+		}
+		return padded
+	}
+
 	var lines []string
-	header := ""
-	for i, col := range r.Columns {
-		header += fmt.Sprintf(" %-*s │", widths[i], col)
+	if opts.border == 2 {
+		lines = append(lines, opts.gridRule(widths, "┌", "┬", "┐"))
+	}
+	if !opts.tuplesOnly {
+		header := make([]string, len(r.Columns))
+		for i, col := range r.Columns {
+			header[i] = fmt.Sprintf("%-*s", widths[i], col)
+		}
+		lines = append(lines, opts.gridLine(header))
+		if v.showTypes {
+			typeRow := make([]string, len(types))
+			for i, t := range types {
+				typeRow[i] = fmt.Sprintf("%-*s", widths[i], t)
+			}
+			lines = append(lines, StyleDimmed.Render(opts.gridLine(typeRow)))
+		}
+		lines = append(lines, opts.gridRule(widths, "├", "┼", "┤"))
 	}
-	// Build separator from header: replace every char with ─, except │ → ┼
-	var sepBuilder strings.Builder
-	for _, ch := range header {
-		if ch == '│' {
-			sepBuilder.WriteRune('┼')
-		} else {
-			sepBuilder.WriteRune('─')
+	for _, row := range rows {
+		lines = append(lines, opts.gridLine(pad(row)))
+	}
+	if opts.border == 2 {
+		lines = append(lines, opts.gridRule(widths, "└", "┴", "┘"))
+	}
+	if !opts.tuplesOnly {
+		lines = append(lines, "", r.Status)
+	}
+	return lines
+}
+
+// renderResult formats a query result in the current display mode,
+// followed by the notices the statement raised.
+func (v *MainView) renderResult(r *db.QueryResult) []string {
+	var lines []string
+	if v.expandedMode {
+		lines = v.formatResultExpanded(r)
+	} else if v.rowNumbers {
+		lines = v.formatResult(v.numberedResult(r))
+		if len(v.gridCols) > 0 {
+			v.gridCols = v.gridCols[1:] // the # column isn't one of the result's
 		}
+	} else {
+		lines = v.formatResult(r)
 	}
-	separator := sepBuilder.String()
-	lines = append(lines, strings.TrimRight(header, "│"))
-	lines = append(lines, strings.TrimRight(separator, "┼"))
-	for _, row := range r.Rows {
-		line := ""
-		for i, cell := range row {
-			if i < len(widths) {
-				if runeLen(cell) > widths[i] {
-					runes := []rune(cell)
-					cell = string(runes[:widths[i]-1]) + "…"
-				}
-				line += fmt.Sprintf(" %-*s │", widths[i], cell)
+	if r != nil && len(r.Notices) > 0 {
+		lines = append(lines, "")
+		for _, n := range r.Notices {
+			for _, line := range strings.Split(n, "\n") {
+				lines = append(lines, StyleDimmed.Render(line))
 			}
 		}
-		lines = append(lines, strings.TrimRight(line, "│"))
 	}
-	lines = append(lines, "", r.Status)
 	return lines
 }
 
+// footprintStatus reports on the status bar the memory footprint of a
+// row result and how long rendering it took.
+func (v *MainView) footprintStatus(r *db.QueryResult, elapsed time.Duration) tea.Cmd {
+	if r == nil || len(r.Rows) == 0 || v.printOpts.tuplesOnly || v.printOpts.format == formatCSV {
+		return nil
+	}
+	info := fmt.Sprintf("≈ %s in memory  ·  formatted in %s",
+		formatByteSize(r.Footprint()), elapsed.Round(time.Millisecond))
+	if !v.expandedMode && !v.measureAll && len(r.Rows) > widthSampleRows {
+		info += fmt.Sprintf("  ·  widths from first %d rows (m to re-measure)", widthSampleRows)
+	}
+	return func() tea.Msg { return StatusMsg(info) }
+}
+
+// pinHeader pins the column header of the displayed grid result, which
+// starts offset lines into the viewport content, while its rows scroll.
+func (v *MainView) pinHeader(offset int) {
+	v.resultOffset = offset
+	r := v.result
+	if r == nil || len(r.Columns) == 0 || len(r.Rows) == 0 || v.expandedMode ||
+		v.printOpts.tuplesOnly || v.printOpts.format != formatAligned {
+		return
+	}
+	n := 2 // header and separator
+	if v.printOpts.border == 2 {
+		n++
+	}
+	if v.showTypes {
+		n++
+	}
+	v.viewport.SetSticky(offset, n)
+}
+
+// formatByteSize renders a byte count as B, KB, MB or GB.
+func formatByteSize(n int) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	size, suffix := float64(n)/unit, "KB"
+	for _, s := range []string{"MB", "GB"} {
+		if size < unit {
+			break
+		}
+		size, suffix = size/unit, s
+	}
+	return fmt.Sprintf("%.1f %s", size, suffix)
+}
+
 // formatResultExpanded renders rows vertically like \x in psql.
 func (v *MainView) formatResultExpanded(r *db.QueryResult) []string {
+	v.records = v.records[:0]
 	if r == nil || len(r.Columns) == 0 {
 		return []string{r.Status}
 	}
@@ -1219,16 +3054,36 @@ func (v *MainView) formatResultExpanded(r *db.QueryResult) []string {
 
 	var lines []string
 	for rowIdx, row := range r.Rows {
-		// Record separator
-		sep := fmt.Sprintf("─[ RECORD %d ]─", rowIdx+1)
-		lines = append(lines, sep)
+		v.records = append(v.records, len(lines))
+		// Record separator; tuples-only mode uses a blank line like psql
+		if v.printOpts.tuplesOnly {
+			if rowIdx > 0 {
+				lines = append(lines, "")
+			}
+		} else {
+			lines = append(lines, fmt.Sprintf("─[ RECORD %d ]─", rowIdx+1))
+		}
 		for i, cell := range row {
 			if i < len(r.Columns) {
-				lines = append(lines, fmt.Sprintf(" %-*s │ %s", maxCol, r.Columns[i], cell))
+				if cell == nullCell {
+					cell = v.printOpts.null
+				} else if i < len(r.ColumnTypes) {
+					cell = formatScalar(r.ColumnTypes[i], cell, v.display)
+				}
+				// Multi-line values continue under the value column
+				for j, part := range strings.Split(cell, "\n") {
+					name := r.Columns[i]
+					if j > 0 {
+						name = ""
+					}
+					lines = append(lines, fmt.Sprintf(" %-*s │ %s", maxCol, name, part))
+				}
 			}
 		}
 	}
-	lines = append(lines, "", r.Status)
+	if !v.printOpts.tuplesOnly {
+		lines = append(lines, "", r.Status)
+	}
 	return lines
 }
 
@@ -1255,8 +3110,11 @@ func (v *MainView) renderTableList(maxWidth, visibleRows int) []string {
 	for i := start; i < end; i++ {
 		name := v.tables[i]
 		suffix := ""
+		if i < len(v.tableTypes) && v.tableTypes[i] == "hypertable" {
+			suffix = " ⏱"
+		}
 		if i < len(v.tableRows) {
-			suffix = " (" + db.FormatRowCount(v.tableRows[i]) + ")"
+			suffix += " (" + db.FormatRowCount(v.tableRows[i]) + ")"
 		}
 		display := name + suffix
 		if maxWidth > 4 && len(display) > maxWidth {
@@ -1297,22 +3155,32 @@ func (v *MainView) View() string {
 
 		case focusResults:
 			v.viewport.SetSize(v.width, v.height-1)
+			if position := v.rowPosition(); position != "" {
+				hint += StyleDimmed.Render("  " + position)
+			}
+			if cols := v.columnPosition(v.width); cols != "" {
+				hint += StyleDimmed.Render("  " + cols)
+			}
 			return hint + "\n" + v.viewport.Render()
 
 		case focusInput:
 			var label, txt string
 			if v.inputMode == inputModeChat {
 				label = "Ask> "
-				txt = v.chatInput
+				txt = multilineInput(v.chatInput.View(), len(label), max(v.height-3, 1))
 			} else {
-				if v.inTransaction {
+				if v.tx != nil {
 					label = "TXN> "
 				} else {
 					label = "SQL> "
 				}
-				txt = v.input
+				txt = v.input.View()
+			}
+			content := StylePrompt.Render(label) + txt
+			if v.inputMode == inputModeSQL && v.histSearch != nil {
+				label, txt = v.historyPrompt()
+				content = label + txt
 			}
-			content := StylePrompt.Render(label) + txt + "█"
 			lines := []string{hint, "", content}
 			for len(lines) < v.height {
 				lines = append(lines, "")
@@ -1329,8 +3197,18 @@ func (v *MainView) View() string {
 	}
 	inputHeight := 5
 
-	contentWidth := v.width - sidebarWidth - 1
-	resultsHeight := v.height - inputHeight - 1
+	// App lays views out for at least the minimum terminal size; keep the
+	// results pane at a bordered line or more on the smallest of those.
+	contentWidth := max(v.width-sidebarWidth-1, 10)
+	resultsHeight := max(v.height-inputHeight-1, 4)
+
+	// Running \every watches take a pane between the results and the input.
+	var watchPane string
+	if len(v.watches) > 0 {
+		watchHeight := min(10, resultsHeight/3)
+		watchPane = v.renderWatchPane(contentWidth, watchHeight)
+		resultsHeight -= watchHeight
+	}
 
 	// 1. Sidebar (same for both modes)
 	var tableList []string
@@ -1339,6 +3217,9 @@ func (v *MainView) View() string {
 	if v.focus == focusSidebar {
 		sidebarTitle = lipgloss.NewStyle().Foreground(ColorAccent).Render(" ●") + " Tables"
 	}
+	if v.maintRun != nil {
+		sidebarTitle += StyleDimmed.Render(" ⏳ " + v.maintRun.plan.Action.Name)
+	}
 	tableList = append(tableList, headerStyle.Render(sidebarTitle))
 	tableList = append(tableList, v.renderTableList(sidebarWidth-4, v.height)...)
 
@@ -1359,7 +3240,24 @@ func (v *MainView) View() string {
 		Render(strings.Join(tableList, "\n"))
 
 	// 2. Results Block (Top Right) — single viewport for both SQL and Chat
-	v.viewport.SetSize(contentWidth-2, resultsHeight-2)
+	// The focused row's position and the columns in view take a line
+	// under the grid, and a scrollbar one more when it is wider than the pane.
+	position := v.rowPosition()
+	if cols := v.columnPosition(contentWidth - 2); cols != "" {
+		if position != "" {
+			position += " · "
+		}
+		position += cols
+	}
+	scrollbar := v.columnScrollbar(contentWidth - 2)
+	footer := 0
+	if position != "" {
+		footer++
+	}
+	if scrollbar != "" {
+		footer++
+	}
+	v.viewport.SetSize(contentWidth-2, resultsHeight-2-footer)
 	resultsBorderColor := ColorDim
 	resultsFocus := "  "
 	if v.focus == focusResults {
@@ -1367,12 +3265,43 @@ func (v *MainView) View() string {
 		resultsFocus = lipgloss.NewStyle().Foreground(ColorAccent).Render(" ●")
 	}
 
+	results := v.viewport.Render()
+	if scrollbar != "" {
+		results += "\n" + scrollbar
+	}
+	if position != "" {
+		results += "\n" + StyleDimmed.Render(position)
+	}
+	if v.maint != nil {
+		results = strings.Join(v.renderMaintenance(), "\n")
+	} else if v.alter != nil {
+		results = strings.Join(v.renderAlterWizard(), "\n")
+	} else if v.index != nil {
+		results = strings.Join(v.renderIndexWizard(), "\n")
+	} else if v.picker != nil {
+		results = strings.Join(v.renderColumnPicker(), "\n")
+	} else if v.groups != nil {
+		results = strings.Join(v.renderGroupSummary(), "\n")
+	} else if v.refs != nil {
+		results = strings.Join(v.renderReferencedBy(), "\n")
+	} else if v.sequences != nil {
+		results = strings.Join(v.renderSequences(), "\n")
+	} else if v.recipe != nil {
+		results = strings.Join(v.renderRecipe(), "\n")
+	} else if v.cellEdit != nil {
+		results = strings.Join(v.renderCellEdit(), "\n")
+	} else if v.rowForm != nil {
+		results = strings.Join(v.renderRowForm(), "\n")
+	}
+	if v.complete != nil && v.focus == focusInput {
+		results = v.overlayCompletion(results, contentWidth, resultsHeight-1)
+	}
 	resultBlock := lipgloss.NewStyle().
 		Width(contentWidth).
 		Height(resultsHeight).
 		Border(lipgloss.NormalBorder(), false, false, true, false).
 		BorderForeground(resultsBorderColor).
-		Render(resultsFocus + v.viewport.Render())
+		Render(resultsFocus + results)
 
 	// 3. Input Block (Bottom Right)
 	inputFocus := "  "
@@ -1383,33 +3312,39 @@ func (v *MainView) View() string {
 	var promptLabel, promptTxt string
 	if v.inputMode == inputModeChat {
 		promptLabel = StylePrompt.Render("Ask> ")
-		promptTxt = v.chatInput
+		indent := lipgloss.Width(inputFocus + promptLabel)
 		if v.focus == focusInput {
-			promptTxt += "█"
-		} else if v.chatInput == "" {
+			promptTxt = multilineInput(v.chatInput.View(), indent, inputHeight)
+		} else if v.chatInput.Value() == "" {
 			promptTxt = StyleDimmed.Render("(press tab to focus input)")
 		} else {
-			promptTxt = StyleDimmed.Render(promptTxt)
+			promptTxt = StyleDimmed.Render(multilineInput(v.chatInput.Value(), indent, inputHeight))
 		}
 		if v.chatLoading {
 			promptTxt = StyleDimmed.Render("waiting for response...")
 		}
 	} else {
-		if v.inTransaction {
+		if v.tx != nil {
 			promptLabel = StylePrompt.Render("TXN> ")
 		} else {
 			promptLabel = StylePrompt.Render("SQL> ")
 		}
-		promptTxt = v.input
-		if v.focus == focusInput {
-			promptTxt += "█"
-		} else if v.input == "" {
+		promptTxt = v.input.Value()
+		if v.histSearch != nil {
+			promptLabel, promptTxt = v.historyPrompt()
+		} else if v.focus == focusInput {
+			promptTxt = v.input.View()
+		} else if v.input.Value() == "" {
 			promptTxt = StyleDimmed.Render("(press tab to focus input)")
 		} else {
 			promptTxt = StyleDimmed.Render(promptTxt)
 		}
 		if v.loading {
-			promptTxt = StyleDimmed.Render("Executing...")
+			busy := "Executing..."
+			if len(v.queue) > 0 {
+				busy = fmt.Sprintf("Executing... (%d queued)", len(v.queue))
+			}
+			promptTxt = StyleDimmed.Render(busy) + " " + promptTxt
 		}
 	}
 
@@ -1421,6 +3356,9 @@ func (v *MainView) View() string {
 
 	// Combine Right Side
 	rightPane := lipgloss.JoinVertical(lipgloss.Left, resultBlock, inputBlock)
+	if watchPane != "" {
+		rightPane = lipgloss.JoinVertical(lipgloss.Left, resultBlock, watchPane, inputBlock)
+	}
 
 	return lipgloss.JoinHorizontal(lipgloss.Top, sidebar, rightPane)
 }
@@ -12,15 +12,20 @@ package tui
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/DachengChen/paiSQL/ai"
 	"github.com/DachengChen/paiSQL/applog"
 	"github.com/DachengChen/paiSQL/config"
 	"github.com/DachengChen/paiSQL/db"
+	"github.com/DachengChen/paiSQL/ssh"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
@@ -35,13 +40,25 @@ const (
 	fieldPassword
 	fieldDatabase
 	fieldSSLMode
+	fieldSearchPath
+	fieldStatementCache
+	fieldColor
+	fieldConnAIProvider // AI provider override; "" uses the AI settings
+	fieldConnAIModel    // only with a provider override that has models
 	fieldSSHEnabled
 	fieldSSHHost
 	fieldSSHPort
 	fieldSSHUser
-	fieldSSHKey
+	fieldSSHAuth
+	fieldSSHKey           // only with key file auth
+	fieldSSHKeyPassphrase // only with key file auth
+	fieldSSHPassword      // only with password auth
+	fieldSSHHostKey       // "" checks known_hosts, "insecure" doesn't
 	fieldConnect
+	fieldTest
 	fieldSave
+	fieldClone
+	fieldRename
 	fieldDelete
 	// ─── AI block fields ────────────────────────────────────
 	fieldAIProvider
@@ -51,6 +68,7 @@ const (
 	fieldAILogin    // only for Antigravity — "Login with Google" button
 	fieldAILogout   // only for Antigravity — "Logout" button
 	fieldAIAuthCode // only for Antigravity — paste callback URL from browser (SSH/remote)
+	fieldAIInterpret
 	fieldAISave
 	fieldCount // sentinel
 )
@@ -67,35 +85,56 @@ const (
 
 // fieldLabel maps field IDs to display labels.
 var fieldLabels = map[int]string{
-	fieldSaved:      "Saved",
-	fieldName:       "Name",
-	fieldHost:       "Host",
-	fieldPort:       "Port",
-	fieldUser:       "User",
-	fieldPassword:   "Password",
-	fieldDatabase:   "Database",
-	fieldSSLMode:    "SSL Mode",
-	fieldSSHEnabled: "SSH Tunnel",
-	fieldSSHHost:    "SSH Host",
-	fieldSSHPort:    "SSH Port",
-	fieldSSHUser:    "SSH User",
-	fieldSSHKey:     "SSH Key",
-	fieldConnect:    "Connect",
-	fieldSave:       "Save",
-	fieldDelete:     "Delete",
-	fieldAIProvider: "Provider",
-	fieldAIAPIKey:   "API Key",
-	fieldAIModel:    "Model",
-	fieldAIHost:     "Host",
-	fieldAILogin:    "Login with Google",
-	fieldAILogout:   "Logout",
-	fieldAIAuthCode: "Paste URL/Code",
-	fieldAISave:     "Save AI",
+	fieldSaved:            "Saved",
+	fieldName:             "Name",
+	fieldHost:             "Host",
+	fieldPort:             "Port",
+	fieldUser:             "User",
+	fieldPassword:         "Password",
+	fieldDatabase:         "Database",
+	fieldSSLMode:          "SSL Mode",
+	fieldSearchPath:       "Search Path",
+	fieldStatementCache:   "Stmt Cache",
+	fieldColor:            "Frame Color",
+	fieldConnAIProvider:   "AI Provider",
+	fieldConnAIModel:      "AI Model",
+	fieldSSHEnabled:       "SSH Tunnel",
+	fieldSSHHost:          "SSH Host",
+	fieldSSHPort:          "SSH Port",
+	fieldSSHUser:          "SSH User",
+	fieldSSHAuth:          "SSH Auth",
+	fieldSSHKey:           "SSH Key",
+	fieldSSHKeyPassphrase: "Key Passphrase",
+	fieldSSHPassword:      "SSH Password",
+	fieldSSHHostKey:       "Host Key",
+	fieldConnect:          "Connect",
+	fieldTest:             "Test",
+	fieldSave:             "Save",
+	fieldClone:            "Clone",
+	fieldRename:           "Rename",
+	fieldDelete:           "Delete",
+	fieldAIProvider:       "Provider",
+	fieldAIAPIKey:         "API Key",
+	fieldAIModel:          "Model",
+	fieldAIHost:           "Host",
+	fieldAILogin:          "Login with Google",
+	fieldAILogout:         "Logout",
+	fieldAIAuthCode:       "Paste URL/Code",
+	fieldAIInterpret:      "Interpret",
+	fieldAISave:           "Save AI",
 }
 
 // SSL mode options for cycling.
 var sslModes = []string{"disable", "require", "verify-ca", "verify-full", "prefer"}
 
+// statementCacheLabels describes the statement cache modes.
+var statementCacheLabels = map[string]string{
+	"":                            "prepared (default)",
+	config.StatementCacheDescribe: "describe",
+	config.StatementCacheExec:     "exec (no cache)",
+	config.StatementCacheDisabled: "disabled (pgbouncer)",
+}
+
 // AI provider options for cycling.
 var aiProviders = []string{"openai", "anthropic", "gemini", "groq", "ollama", "antigravity", "placeholder"}
 
@@ -126,8 +165,9 @@ type ConnectView struct {
 	appCfg     *config.AppConfig
 	fields     []string // field values indexed by field ID
 	focusField int
-	savedIdx   int  // selected index in saved connections list
-	editing    bool // true when typing in a field
+	savedIdx   int       // selected index in saved connections list
+	editing    bool      // true when typing in a field
+	edit       textInput // the field being typed in
 	err        error
 	statusMsg  string
 	connecting bool
@@ -142,8 +182,38 @@ type ConnectView struct {
 	oauthAuthURL     string          // the Google auth URL for the user to copy
 	oauthRedirectURI string          // redirect URI needed for code exchange
 	oauthProvider    *ai.Antigravity // the provider instance for this login attempt
+
+	// Connection test results, shown under the buttons
+	testing   bool
+	testSteps []db.ConnectionStep
+
+	// The Delete confirmation and Rename prompt, shown under the buttons,
+	// and the last deleted connection while U can restore it
+	confirmDelete string // connection asked about
+	renaming      string // connection being renamed; the new name is typed in edit
+	deleted       *deletedConnection
+	deletedGen    int
+
+	// An SSH host key not in known_hosts, asked about after a connect or
+	// test found it; trusting it repeats that
+	trustHost *ssh.UnknownHostError
+	trustTest bool
 }
 
+// deletedConnection is a deleted connection kept for undo.
+type deletedConnection struct {
+	conn   config.Connection
+	index  int    // where it was in the list
+	status string // the status line offering U
+	gen    int
+}
+
+// connUndoWindow is how long U restores a deleted connection.
+const connUndoWindow = 10 * time.Second
+
+// connUndoExpiredMsg ends the undo window of a deleted connection.
+type connUndoExpiredMsg struct{ gen int }
+
 // ConnectedMsg is sent when a DB connection is successfully established.
 type ConnectedMsg struct {
 	DB   *db.DB
@@ -156,6 +226,14 @@ type ConnectErrorMsg struct {
 	Err error
 }
 
+// ConnectionTestMsg carries the per-step results of the Test action.
+type ConnectionTestMsg struct {
+	Steps []db.ConnectionStep
+}
+
+// connectionTestTimeout bounds the whole Test action.
+const connectionTestTimeout = 5 * time.Second
+
 func NewConnectView(store *config.ConnectionStore, appCfg *config.AppConfig) *ConnectView {
 	v := &ConnectView{
 		store:      store,
@@ -186,6 +264,10 @@ func NewConnectView(store *config.ConnectionStore, appCfg *config.AppConfig) *Co
 		v.fields[fieldAIProvider] = "placeholder"
 	}
 	v.loadAIFieldsFromConfig()
+	v.fields[fieldAIInterpret] = "no"
+	if appCfg.AI.InterpretResults {
+		v.fields[fieldAIInterpret] = "yes"
+	}
 
 	// Discover SSH keys
 	v.sshKeys = discoverSSHKeys()
@@ -239,8 +321,11 @@ func (v *ConnectView) loadAIFieldsFromConfig() {
 	}
 }
 
-func (v *ConnectView) Name() string         { return "Settings" }
-func (v *ConnectView) WantsTextInput() bool { return v.editing }
+func (v *ConnectView) Name() string { return "Settings" }
+
+func (v *ConnectView) WantsTextInput() bool {
+	return v.editing || v.renaming != "" || v.confirmDelete != "" || v.trustHost != nil
+}
 
 func (v *ConnectView) SetSize(width, height int) {
 	v.width = width
@@ -248,6 +333,24 @@ func (v *ConnectView) SetSize(width, height int) {
 }
 
 func (v *ConnectView) ShortHelp() []KeyBinding {
+	if v.trustHost != nil {
+		return []KeyBinding{
+			{Key: "y", Desc: "trust host"},
+			{Key: "n/Esc", Desc: "cancel"},
+		}
+	}
+	if v.confirmDelete != "" {
+		return []KeyBinding{
+			{Key: "y", Desc: "delete"},
+			{Key: "n/Esc", Desc: "keep"},
+		}
+	}
+	if v.renaming != "" {
+		return []KeyBinding{
+			{Key: "Enter", Desc: "rename"},
+			{Key: "Esc", Desc: "cancel"},
+		}
+	}
 	if v.editing {
 		return []KeyBinding{
 			{Key: "Enter", Desc: "confirm"},
@@ -255,24 +358,48 @@ func (v *ConnectView) ShortHelp() []KeyBinding {
 			{Key: "Ctrl+U", Desc: "clear"},
 		}
 	}
-	return []KeyBinding{
+	keys := []KeyBinding{
 		{Key: "↑/↓", Desc: "navigate"},
 		{Key: "Tab", Desc: "switch block"},
 		{Key: "Enter", Desc: "edit/action"},
-		{Key: "Ctrl+C", Desc: "quit"},
 	}
+	if v.deleted != nil {
+		keys = append(keys, KeyBinding{Key: "U", Desc: "restore " + v.deleted.conn.Name})
+	}
+	return append(keys, KeyBinding{Key: "Ctrl+C", Desc: "quit"})
 }
 
 func (v *ConnectView) Init() tea.Cmd { return nil }
 
+// Leave is a no-op: the connection screen is not a tab.
+func (v *ConnectView) Leave() {}
+
 func (v *ConnectView) Update(msg tea.Msg) (View, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if v.trustHost != nil {
+			return v.handleTrustHost(msg)
+		}
+		if v.confirmDelete != "" {
+			return v.handleConfirmDelete(msg)
+		}
+		if v.renaming != "" {
+			return v.handleRenaming(msg)
+		}
 		if v.editing {
 			return v.handleEditing(msg)
 		}
 		return v.handleNavigation(msg)
 
+	case connUndoExpiredMsg:
+		if d := v.deleted; d != nil && d.gen == msg.gen {
+			v.deleted = nil
+			if v.statusMsg == d.status {
+				v.statusMsg = ""
+			}
+		}
+		return v, nil
+
 	case ConnectedMsg:
 		return v, nil
 
@@ -280,6 +407,17 @@ func (v *ConnectView) Update(msg tea.Msg) (View, tea.Cmd) {
 		v.connecting = false
 		v.err = msg.Err
 		v.statusMsg = ""
+		if errors.As(msg.Err, &v.trustHost) {
+			v.err, v.trustTest = nil, false
+		}
+		return v, nil
+
+	case ConnectionTestMsg:
+		v.testing = false
+		v.testSteps = msg.Steps
+		if n := len(msg.Steps); n > 0 && errors.As(msg.Steps[n-1].Err, &v.trustHost) {
+			v.trustTest = true
+		}
 		return v, nil
 
 	case AntigravityLoginMsg:
@@ -322,6 +460,9 @@ func (v *ConnectView) handleNavigation(msg tea.KeyMsg) (View, tea.Cmd) {
 	case "right", "l":
 		return v.handleRight()
 
+	case "U":
+		v.restoreDeleted()
+
 	case "q", "ctrl+c":
 		return v, tea.Quit
 	}
@@ -369,18 +510,12 @@ func (v *ConnectView) blockRange() (int, int) {
 func (v *ConnectView) skipHiddenFields(dir int) {
 	first, last := v.blockRange()
 
-	// Connection block: skip saved if empty, skip SSH fields if disabled
+	// Connection block: skip saved if empty, SSH fields if disabled and
+	// fields the chosen options don't use
 	if v.block == blockConn {
-		if v.focusField == fieldSaved && len(v.store.Connections) == 0 {
+		for v.focusField >= first && v.focusField <= last && v.connFieldHidden(v.focusField) {
 			v.focusField += dir
 		}
-		if !v.sshEnabled() && v.isSSHField(v.focusField) {
-			if dir > 0 {
-				v.focusField = fieldConnect
-			} else {
-				v.focusField = fieldSSHEnabled - 1
-			}
-		}
 	}
 
 	// AI block: skip fields based on provider
@@ -413,8 +548,8 @@ func (v *ConnectView) skipHiddenFields(dir int) {
 		if (!v.oauthPending || provider != "antigravity") && v.focusField == fieldAIAuthCode {
 			v.focusField += dir
 		}
-		// Skip model/host/login/logout/authcode for placeholder
-		if provider == "placeholder" && (v.focusField == fieldAIModel || v.focusField == fieldAIHost || v.focusField == fieldAILogin || v.focusField == fieldAILogout || v.focusField == fieldAIAuthCode) {
+		// Skip model/host/login/logout/authcode/interpret for placeholder
+		if provider == "placeholder" && (v.focusField == fieldAIModel || v.focusField == fieldAIHost || v.focusField == fieldAILogin || v.focusField == fieldAILogout || v.focusField == fieldAIAuthCode || v.focusField == fieldAIInterpret) {
 			v.focusField += dir
 		}
 	}
@@ -440,6 +575,16 @@ func (v *ConnectView) handleLeft() (View, tea.Cmd) {
 		}
 	case fieldSSLMode:
 		v.cycleSSLMode(-1)
+	case fieldStatementCache:
+		v.cycleStatementCache(-1)
+	case fieldColor:
+		v.cycleColor(-1)
+	case fieldConnAIProvider:
+		v.cycleConnAIProvider(-1)
+	case fieldSSHAuth:
+		v.cycleSSHAuth(-1)
+	case fieldSSHHostKey:
+		v.toggleSSHHostKey()
 	case fieldSSHKey:
 		v.cycleSSHKey(-1)
 	case fieldAIProvider:
@@ -462,6 +607,16 @@ func (v *ConnectView) handleRight() (View, tea.Cmd) {
 		}
 	case fieldSSLMode:
 		v.cycleSSLMode(1)
+	case fieldStatementCache:
+		v.cycleStatementCache(1)
+	case fieldColor:
+		v.cycleColor(1)
+	case fieldConnAIProvider:
+		v.cycleConnAIProvider(1)
+	case fieldSSHAuth:
+		v.cycleSSHAuth(1)
+	case fieldSSHHostKey:
+		v.toggleSSHHostKey()
 	case fieldSSHKey:
 		v.cycleSSHKey(1)
 	case fieldAIProvider:
@@ -472,32 +627,21 @@ func (v *ConnectView) handleRight() (View, tea.Cmd) {
 	return v, nil
 }
 
-func (v *ConnectView) handleEditing(msg tea.KeyMsg) (View, tea.Cmd) {
-	field := v.focusField
+// startEditing starts typing in the focused field.
+func (v *ConnectView) startEditing() {
+	v.editing = true
+	v.edit.SetValue(v.fields[v.focusField])
+}
 
+func (v *ConnectView) handleEditing(msg tea.KeyMsg) (View, tea.Cmd) {
 	switch msg.String() {
-	case "enter", "escape":
+	case "enter", "esc":
 		v.editing = false
 		return v, nil
-
-	case "backspace":
-		if len(v.fields[field]) > 0 {
-			v.fields[field] = v.fields[field][:len(v.fields[field])-1]
-		}
-
-	case "ctrl+u":
-		v.fields[field] = ""
-
-	default:
-		// Accept typed characters and pasted text.
-		// msg.Type == tea.KeyRunes covers normal typing and paste.
-		if msg.Type == tea.KeyRunes {
-			v.fields[field] += msg.String()
-		} else if ch := msg.String(); len(ch) == 1 {
-			v.fields[field] += ch
-		}
 	}
-
+	if v.edit.Update(msg) {
+		v.fields[v.focusField] = v.edit.Value()
+	}
 	return v, nil
 }
 
@@ -518,25 +662,72 @@ func (v *ConnectView) handleAction() (View, tea.Cmd) {
 		v.cycleSSLMode(1)
 		return v, nil
 
+	case fieldStatementCache:
+		v.cycleStatementCache(1)
+		return v, nil
+
+	case fieldColor:
+		v.cycleColor(1)
+		return v, nil
+
+	case fieldConnAIProvider:
+		v.cycleConnAIProvider(1)
+		return v, nil
+
+	case fieldAIInterpret:
+		if v.fields[fieldAIInterpret] == "yes" {
+			v.fields[fieldAIInterpret] = "no"
+		} else {
+			v.fields[fieldAIInterpret] = "yes"
+		}
+		return v, nil
+
 	case fieldConnect:
 		return v, v.connect()
 
+	case fieldTest:
+		return v, v.testConnection()
+
 	case fieldSave:
 		return v, v.saveConnection()
 
+	case fieldClone:
+		v.cloneConnection()
+		return v, nil
+
+	case fieldRename:
+		v.startRename()
+		return v, nil
+
 	case fieldDelete:
-		return v, v.deleteConnection()
+		if len(v.store.Connections) > 0 {
+			c := v.store.Connections[v.savedIdx]
+			if c.Shared {
+				v.err = fmt.Errorf("'%s' is shared in the workspace %s: remove it there", c.Name, v.store.Workspace)
+				return v, nil
+			}
+			v.confirmDelete = c.Name
+		}
+		return v, nil
 
 	case fieldAIProvider:
 		v.cycleAIProvider(1)
 		return v, nil
 
+	case fieldSSHAuth:
+		v.cycleSSHAuth(1)
+		return v, nil
+
+	case fieldSSHHostKey:
+		v.toggleSSHHostKey()
+		return v, nil
+
 	case fieldSSHKey:
 		// If we have discovered keys, cycle; otherwise allow manual edit
 		if len(v.sshKeys) > 0 {
 			v.cycleSSHKey(1)
 		} else {
-			v.editing = true
+			v.startEditing()
 		}
 		return v, nil
 
@@ -554,7 +745,7 @@ func (v *ConnectView) handleAction() (View, tea.Cmd) {
 
 	default:
 		// Editable text fields
-		v.editing = true
+		v.startEditing()
 		return v, nil
 	}
 }
@@ -588,6 +779,56 @@ func (v *ConnectView) connect() tea.Cmd {
 	}
 }
 
+// connectTo loads the named saved connection into the form and connects,
+// for --connect and autoconnect on startup. Failures stay on the form.
+func (v *ConnectView) connectTo(name string) tea.Cmd {
+	if !v.selectSaved(name) {
+		v.err = fmt.Errorf("no saved connection named %q", name)
+		return nil
+	}
+	v.loadSavedConnection(v.savedIdx)
+	return v.connect()
+}
+
+// selectSaved points the saved-connection selector at name, e.g. after
+// the list was reordered by recency.
+func (v *ConnectView) selectSaved(name string) bool {
+	for i, c := range v.store.Connections {
+		if c.Name == name {
+			v.savedIdx = i
+			v.focusField = fieldSaved
+			return true
+		}
+	}
+	return false
+}
+
+// testConnection runs db.TestConnection against the form values without
+// leaving the form; results are rendered under the buttons.
+func (v *ConnectView) testConnection() tea.Cmd {
+	if v.testing {
+		return nil
+	}
+	conn := v.buildConnection()
+	cfg := config.FromConnection(conn)
+
+	v.testing = true
+	v.testSteps = nil
+	v.err = nil
+
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), connectionTestTimeout)
+		defer cancel()
+		steps := db.TestConnection(ctx, cfg)
+		for _, st := range steps {
+			if st.Err != nil {
+				applog.Error("Connection test %s failed: %v", st.Name, st.Err)
+			}
+		}
+		return ConnectionTestMsg{Steps: steps}
+	}
+}
+
 func (v *ConnectView) saveConnection() tea.Cmd {
 	name := strings.TrimSpace(v.fields[fieldName])
 	if name == "" {
@@ -620,33 +861,189 @@ func (v *ConnectView) saveConnection() tea.Cmd {
 	return nil
 }
 
-func (v *ConnectView) deleteConnection() tea.Cmd {
+// cloneConnection copies the selected saved connection into the form under
+// a new name and starts editing the name. Nothing is stored until Save.
+func (v *ConnectView) cloneConnection() {
 	if len(v.store.Connections) == 0 {
-		return nil
+		v.err = fmt.Errorf("no saved connection to clone")
+		return
 	}
 
-	name := v.store.Connections[v.savedIdx].Name
+	src := v.store.Connections[v.savedIdx].Name
+	v.loadSavedConnection(v.savedIdx)
+
+	name := src + "-copy"
+	for n := 2; ; n++ {
+		if _, exists := v.store.Get(name); !exists {
+			break
+		}
+		name = fmt.Sprintf("%s-copy%d", src, n)
+	}
+	v.fields[fieldName] = name
+
+	v.focusField = fieldName
+	v.startEditing()
+	v.err = nil
+	v.statusMsg = fmt.Sprintf("Cloned '%s' — rename, adjust, then Save", src)
+}
+
+// handleConfirmDelete answers the Delete confirmation.
+func (v *ConnectView) handleConfirmDelete(msg tea.KeyMsg) (View, tea.Cmd) {
+	switch msg.String() {
+	case "y", "Y":
+		name := v.confirmDelete
+		v.confirmDelete = ""
+		return v, v.deleteConnection(name)
+	case "n", "N", "esc":
+		v.confirmDelete = ""
+	}
+	return v, nil
+}
+
+// handleTrustHost answers the prompt for an unknown SSH host key: y
+// records it in known_hosts and repeats the connect or test that found
+// it.
+func (v *ConnectView) handleTrustHost(msg tea.KeyMsg) (View, tea.Cmd) {
+	host := v.trustHost
+	switch msg.String() {
+	case "y", "Y":
+		v.trustHost = nil
+		if err := ssh.AddKnownHost(host); err != nil {
+			applog.Error("Failed to add %s to known_hosts: %v", host.Host, err)
+			v.err = err
+			return v, nil
+		}
+		applog.Event("CONNECT", "Trusted SSH host %s (%s)", host.Host, host.Fingerprint())
+		if v.trustTest {
+			return v, v.testConnection()
+		}
+		return v, v.connect()
+	case "n", "N", "esc":
+		v.trustHost = nil
+		if !v.trustTest {
+			v.err = host
+		}
+	}
+	return v, nil
+}
+
+// deleteConnection deletes a saved connection, keeping it for U to restore
+// for connUndoWindow.
+func (v *ConnectView) deleteConnection(name string) tea.Cmd {
+	index := slices.IndexFunc(v.store.Connections, func(c config.Connection) bool { return c.Name == name })
+	if index < 0 {
+		return nil
+	}
+	conn := v.store.Connections[index]
 	v.store.Delete(name)
 
 	if err := v.store.Save(); err != nil {
 		applog.Error("Failed to delete connection '%s': %v", name, err)
+		v.store.Insert(index, conn)
 		v.err = err
 		return nil
 	}
 
 	applog.Event("CONFIG", "Connection deleted: %s", name)
-	v.statusMsg = fmt.Sprintf("Connection '%s' deleted.", name)
+	v.deletedGen++
+	v.deleted = &deletedConnection{
+		conn:   conn,
+		index:  index,
+		status: fmt.Sprintf("Connection '%s' deleted — press U to restore", name),
+		gen:    v.deletedGen,
+	}
+	v.statusMsg = v.deleted.status
 	v.err = nil
 
 	if v.savedIdx >= len(v.store.Connections) {
 		v.savedIdx = 0
 	}
 
-	return nil
+	gen := v.deletedGen
+	return tea.Tick(connUndoWindow, func(time.Time) tea.Msg { return connUndoExpiredMsg{gen: gen} })
+}
+
+// restoreDeleted puts the last deleted connection back where it was.
+func (v *ConnectView) restoreDeleted() {
+	d := v.deleted
+	if d == nil {
+		return
+	}
+	v.deleted = nil
+	if _, exists := v.store.Get(d.conn.Name); exists {
+		v.err = fmt.Errorf("cannot restore '%s': a connection with that name was saved since", d.conn.Name)
+		return
+	}
+	v.store.Insert(d.index, d.conn)
+	if err := v.store.Save(); err != nil {
+		applog.Error("Failed to restore connection '%s': %v", d.conn.Name, err)
+		v.store.Delete(d.conn.Name)
+		v.err = err
+		return
+	}
+	applog.Event("CONFIG", "Connection restored: %s", d.conn.Name)
+	v.selectSaved(d.conn.Name)
+	v.loadSavedConnection(v.savedIdx)
+	v.statusMsg = fmt.Sprintf("Connection '%s' restored.", d.conn.Name)
+	v.err = nil
+}
+
+// startRename opens the Rename prompt for the selected saved connection.
+func (v *ConnectView) startRename() {
+	if len(v.store.Connections) == 0 {
+		v.err = fmt.Errorf("no saved connection to rename")
+		return
+	}
+	if c := v.store.Connections[v.savedIdx]; c.Shared {
+		v.err = fmt.Errorf("'%s' is shared in the workspace %s: rename it there", c.Name, v.store.Workspace)
+		return
+	}
+	v.renaming = v.store.Connections[v.savedIdx].Name
+	v.edit.SetValue(v.renaming)
+	v.err = nil
+}
+
+// handleRenaming handles a key while the new name is typed.
+func (v *ConnectView) handleRenaming(msg tea.KeyMsg) (View, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		v.renaming = ""
+	case "enter":
+		v.renameConnection()
+	default:
+		v.edit.Update(msg)
+	}
+	return v, nil
+}
+
+// renameConnection renames the connection to the typed name. An error
+// keeps the prompt open.
+func (v *ConnectView) renameConnection() {
+	oldName, newName := v.renaming, strings.TrimSpace(v.edit.Value())
+	if newName == oldName {
+		v.renaming = ""
+		return
+	}
+	if err := v.store.Rename(oldName, newName); err != nil {
+		v.err = err
+		return
+	}
+	if err := v.store.Save(); err != nil {
+		applog.Error("Failed to rename connection '%s': %v", oldName, err)
+		v.err = err
+		return
+	}
+	applog.Event("CONFIG", "Connection renamed: %s → %s", oldName, newName)
+	if v.fields[fieldName] == oldName {
+		v.fields[fieldName] = newName
+	}
+	v.renaming = ""
+	v.statusMsg = fmt.Sprintf("Connection '%s' renamed to '%s'.", oldName, newName)
+	v.err = nil
 }
 
 func (v *ConnectView) buildConnection() config.Connection {
-	return config.Connection{
+	conn := config.Connection{
 		Name:     strings.TrimSpace(v.fields[fieldName]),
 		Host:     v.fields[fieldHost],
 		Port:     v.fields[fieldPort],
@@ -659,9 +1056,25 @@ func (v *ConnectView) buildConnection() config.Connection {
 			Host:    v.fields[fieldSSHHost],
 			Port:    v.fields[fieldSSHPort],
 			User:    v.fields[fieldSSHUser],
-			KeyPath: v.fields[fieldSSHKey],
+			Auth:    v.fields[fieldSSHAuth],
+
+			InsecureHostKey: v.fields[fieldSSHHostKey] == "insecure",
 		},
-	}
+		SearchPath:     strings.TrimSpace(v.fields[fieldSearchPath]),
+		StatementCache: v.fields[fieldStatementCache],
+		Color:          v.fields[fieldColor],
+		AIProvider:     v.fields[fieldConnAIProvider],
+		AIModel:        strings.TrimSpace(v.fields[fieldConnAIModel]),
+	}
+	// Only the chosen method's secrets are kept
+	switch conn.SSH.Auth {
+	case config.SSHAuthKey:
+		conn.SSH.KeyPath = v.fields[fieldSSHKey]
+		conn.SSH.KeyPassphrase = v.fields[fieldSSHKeyPassphrase]
+	case config.SSHAuthPassword:
+		conn.SSH.Password = v.fields[fieldSSHPassword]
+	}
+	return conn
 }
 
 func (v *ConnectView) loadSavedConnection(idx int) {
@@ -676,6 +1089,11 @@ func (v *ConnectView) loadSavedConnection(idx int) {
 	v.fields[fieldPassword] = c.Password
 	v.fields[fieldDatabase] = c.Database
 	v.fields[fieldSSLMode] = c.SSLMode
+	v.fields[fieldSearchPath] = c.SearchPath
+	v.fields[fieldStatementCache] = c.StatementCache
+	v.fields[fieldColor] = c.Color
+	v.fields[fieldConnAIProvider] = c.AIProvider
+	v.fields[fieldConnAIModel] = c.AIModel
 	if c.SSH.Enabled {
 		v.fields[fieldSSHEnabled] = "yes"
 	} else {
@@ -684,8 +1102,16 @@ func (v *ConnectView) loadSavedConnection(idx int) {
 	v.fields[fieldSSHHost] = c.SSH.Host
 	v.fields[fieldSSHPort] = c.SSH.Port
 	v.fields[fieldSSHUser] = c.SSH.User
+	v.fields[fieldSSHAuth] = c.SSH.Auth
 	v.fields[fieldSSHKey] = c.SSH.KeyPath
+	v.fields[fieldSSHKeyPassphrase] = c.SSH.KeyPassphrase
+	v.fields[fieldSSHPassword] = c.SSH.Password
+	v.fields[fieldSSHHostKey] = ""
+	if c.SSH.InsecureHostKey {
+		v.fields[fieldSSHHostKey] = "insecure"
+	}
 	v.savedIdx = idx
+	v.testSteps = nil
 
 	// Sync SSH key index
 	for i, k := range v.sshKeys {
@@ -701,7 +1127,25 @@ func (v *ConnectView) sshEnabled() bool {
 }
 
 func (v *ConnectView) isSSHField(f int) bool {
-	return f >= fieldSSHHost && f <= fieldSSHKey
+	return f >= fieldSSHHost && f <= fieldSSHHostKey
+}
+
+// connFieldHidden reports whether a field of the connection block is
+// left out of the form.
+func (v *ConnectView) connFieldHidden(f int) bool {
+	switch {
+	case f == fieldSaved:
+		return len(v.store.Connections) == 0
+	case f == fieldConnAIModel:
+		return !v.connAIModelShown()
+	case v.isSSHField(f) && !v.sshEnabled():
+		return true
+	case f == fieldSSHKey || f == fieldSSHKeyPassphrase:
+		return v.fields[fieldSSHAuth] != config.SSHAuthKey
+	case f == fieldSSHPassword:
+		return v.fields[fieldSSHAuth] != config.SSHAuthPassword
+	}
+	return false
 }
 
 func (v *ConnectView) cycleSSLMode(dir int) {
@@ -717,6 +1161,71 @@ func (v *ConnectView) cycleSSLMode(dir int) {
 	v.fields[fieldSSLMode] = sslModes[idx]
 }
 
+// cycleStatementCache cycles through the statement cache modes.
+func (v *ConnectView) cycleStatementCache(dir int) {
+	modes := config.StatementCacheModes
+	idx := slices.Index(modes, v.fields[fieldStatementCache])
+	idx = (max(idx, 0) + dir + len(modes)) % len(modes)
+	v.fields[fieldStatementCache] = modes[idx]
+}
+
+// cycleColor cycles the frame color through none and the named colors. A
+// custom color from the connections file cycles on from none.
+func (v *ConnectView) cycleColor(dir int) {
+	names := []string{""}
+	for _, c := range connectionColors {
+		names = append(names, c.name)
+	}
+	idx := slices.Index(names, v.fields[fieldColor])
+	idx = (max(idx, 0) + dir + len(names)) % len(names)
+	v.fields[fieldColor] = names[idx]
+}
+
+// cycleConnAIProvider cycles the connection's AI provider override
+// through none and the providers. The model is the new provider's default
+// until one is typed.
+func (v *ConnectView) cycleConnAIProvider(dir int) {
+	names := append([]string{""}, ai.SupportedProviders...)
+	idx := slices.Index(names, v.fields[fieldConnAIProvider])
+	idx = (max(idx, 0) + dir + len(names)) % len(names)
+	v.fields[fieldConnAIProvider] = names[idx]
+	v.fields[fieldConnAIModel] = ""
+}
+
+// connAIModelShown reports whether the connection's AI provider override
+// takes a model.
+func (v *ConnectView) connAIModelShown() bool {
+	p := v.fields[fieldConnAIProvider]
+	return p != "" && p != "placeholder"
+}
+
+// sshAuthModes are the SSH authentication methods in cycling order, with
+// their labels.
+var sshAuthModes = []struct{ mode, label string }{
+	{config.SSHAuthKey, "key file"},
+	{config.SSHAuthAgent, "ssh-agent"},
+	{config.SSHAuthPassword, "password"},
+}
+
+// cycleSSHAuth cycles through the SSH authentication methods.
+func (v *ConnectView) cycleSSHAuth(dir int) {
+	idx := slices.IndexFunc(sshAuthModes, func(m struct{ mode, label string }) bool {
+		return m.mode == v.fields[fieldSSHAuth]
+	})
+	idx = (max(idx, 0) + dir + len(sshAuthModes)) % len(sshAuthModes)
+	v.fields[fieldSSHAuth] = sshAuthModes[idx].mode
+}
+
+// toggleSSHHostKey switches between checking the SSH host key against
+// known_hosts and not checking it.
+func (v *ConnectView) toggleSSHHostKey() {
+	if v.fields[fieldSSHHostKey] == "" {
+		v.fields[fieldSSHHostKey] = "insecure"
+	} else {
+		v.fields[fieldSSHHostKey] = ""
+	}
+}
+
 // cycleSSHKey cycles through discovered SSH key files.
 func (v *ConnectView) cycleSSHKey(dir int) {
 	if len(v.sshKeys) == 0 {
@@ -818,6 +1327,7 @@ func (v *ConnectView) loadAIKeyFromConfig() {
 // applyAIConfig writes the current AI form values back to appConfig.
 func (v *ConnectView) applyAIConfig() {
 	v.appCfg.AI.Provider = v.fields[fieldAIProvider]
+	v.appCfg.AI.InterpretResults = v.fields[fieldAIInterpret] == "yes"
 	// Strip brackets that may come from terminal bracketed paste
 	apiKey := strings.Trim(v.fields[fieldAIAPIKey], "[]")
 	switch v.fields[fieldAIProvider] {
@@ -953,6 +1463,9 @@ func (v *ConnectView) View() string {
 		savedLine := ""
 		for i, c := range v.store.Connections {
 			label := c.Name
+			if c.Shared {
+				label += " (shared)"
+			}
 			if i == v.savedIdx {
 				if v.focusField == fieldSaved {
 					savedLine += StyleListItemActive.Render(" ► " + label + " ")
@@ -978,6 +1491,13 @@ func (v *ConnectView) View() string {
 	leftLines = append(leftLines, v.renderPasswordField(leftInputW))
 	leftLines = append(leftLines, v.renderField(fieldDatabase, leftInputW))
 	leftLines = append(leftLines, v.renderSelectField(fieldSSLMode, leftInputW))
+	leftLines = append(leftLines, v.renderField(fieldSearchPath, leftInputW))
+	leftLines = append(leftLines, v.renderSelectField(fieldStatementCache, leftInputW))
+	leftLines = append(leftLines, v.renderSelectField(fieldColor, leftInputW))
+	leftLines = append(leftLines, v.renderSelectField(fieldConnAIProvider, leftInputW))
+	if v.connAIModelShown() {
+		leftLines = append(leftLines, v.renderField(fieldConnAIModel, leftInputW))
+	}
 	leftLines = append(leftLines, "")
 
 	// SSH Tunnel
@@ -988,16 +1508,25 @@ func (v *ConnectView) View() string {
 		leftLines = append(leftLines, v.renderField(fieldSSHHost, leftInputW))
 		leftLines = append(leftLines, v.renderField(fieldSSHPort, leftInputW))
 		leftLines = append(leftLines, v.renderField(fieldSSHUser, leftInputW))
-		leftLines = append(leftLines, v.renderSSHKeyField(leftInputW))
+		leftLines = append(leftLines, v.renderSelectField(fieldSSHAuth, leftInputW))
+		switch v.fields[fieldSSHAuth] {
+		case config.SSHAuthKey:
+			leftLines = append(leftLines, v.renderSSHKeyField(leftInputW))
+			leftLines = append(leftLines, v.renderMaskedField(fieldSSHKeyPassphrase, leftInputW))
+		case config.SSHAuthPassword:
+			leftLines = append(leftLines, v.renderMaskedField(fieldSSHPassword, leftInputW))
+		}
+		leftLines = append(leftLines, v.renderSelectField(fieldSSHHostKey, leftInputW))
 	}
 
 	leftLines = append(leftLines, "")
 
 	// Connection action buttons
-	btnLine := v.renderButton(fieldConnect) + "  " +
-		v.renderButton(fieldSave) + "  " +
-		v.renderButton(fieldDelete)
-	leftLines = append(leftLines, btnLine)
+	leftLines = append(leftLines,
+		v.renderButton(fieldConnect)+"  "+v.renderButton(fieldTest)+"  "+v.renderButton(fieldSave),
+		v.renderButton(fieldClone)+"  "+v.renderButton(fieldRename)+"  "+v.renderButton(fieldDelete))
+	leftLines = append(leftLines, v.renderSavedPrompt()...)
+	leftLines = append(leftLines, v.renderTestSteps()...)
 
 	leftContent := strings.Join(leftLines, "\n")
 
@@ -1084,6 +1613,9 @@ func (v *ConnectView) View() string {
 				rightLines = append(rightLines, v.renderField(fieldAIAuthCode, rightInputW))
 			}
 		}
+		rightLines = append(rightLines, "")
+		rightLines = append(rightLines, v.renderToggleField(fieldAIInterpret))
+		rightLines = append(rightLines, StyleDimmed.Render("  Explain AI query results in plain language"))
 	}
 
 	rightLines = append(rightLines, "")
@@ -1132,6 +1664,47 @@ func (v *ConnectView) View() string {
 	return centered
 }
 
+// renderTestSteps renders the outcome of the last connection test.
+// renderSavedPrompt renders the Delete confirmation or Rename prompt.
+func (v *ConnectView) renderSavedPrompt() []string {
+	switch {
+	case v.trustHost != nil:
+		return []string{"",
+			StyleWarning.Render(fmt.Sprintf("  SSH host %s isn't in ~/.ssh/known_hosts.", v.trustHost.Host)),
+			StyleDimmed.Render("  " + v.trustHost.Key.Type() + " key fingerprint:"),
+			"  " + v.trustHost.Fingerprint(),
+			StyleWarning.Render("  Trust it and add it to known_hosts?") + StyleDimmed.Render("  y trust · n cancel")}
+	case v.confirmDelete != "":
+		return []string{"",
+			StyleWarning.Render(fmt.Sprintf("  Delete connection '%s'?", v.confirmDelete)) +
+				StyleDimmed.Render("  y delete · n keep")}
+	case v.renaming != "":
+		return []string{"",
+			StylePrompt.Render(fmt.Sprintf("  Rename '%s' to: ", v.renaming)) + v.edit.View(),
+			StyleDimmed.Render("  Enter rename · Esc cancel")}
+	}
+	return nil
+}
+
+func (v *ConnectView) renderTestSteps() []string {
+	if v.testing {
+		return []string{"", StyleDimmed.Render("  ⏳ Testing connection...")}
+	}
+	if len(v.testSteps) == 0 {
+		return nil
+	}
+	lines := []string{""}
+	for _, st := range v.testSteps {
+		elapsed := st.Elapsed.Round(time.Millisecond)
+		if st.Err != nil {
+			lines = append(lines, StyleError.Render(fmt.Sprintf("  ✗ %s (%s): %v", st.Name, elapsed, st.Err)))
+		} else {
+			lines = append(lines, StyleSuccess.Render(fmt.Sprintf("  ✓ %s (%s)", st.Name, elapsed)))
+		}
+	}
+	return lines
+}
+
 // blockHeader renders a section header, highlighted if the block is active.
 func (v *ConnectView) blockHeader(label string, width int, blk int) string {
 	active := v.block == blk
@@ -1168,6 +1741,12 @@ func (v *ConnectView) blockHeader(label string, width int, blk int) string {
 func (v *ConnectView) renderField(id, inputWidth int) string {
 	label := fieldLabels[id]
 	value := v.fields[id]
+	if id == fieldStatementCache {
+		value = statementCacheLabels[value]
+	}
+	if id == fieldConnAIModel && value == "" && !(v.editing && v.focusField == id) {
+		value = "default (" + aiDefaultModels[v.fields[fieldConnAIProvider]] + ")"
+	}
 	focused := v.focusField == id
 
 	labelStr := lipgloss.NewStyle().
@@ -1184,14 +1763,13 @@ func (v *ConnectView) renderField(id, inputWidth int) string {
 	}
 
 	if focused {
-		cursor := "█"
-		if !v.editing {
-			cursor = ""
+		if v.editing {
+			value = v.edit.View()
 		}
 		inputBox := lipgloss.NewStyle().
 			Width(inputWidth).
 			Foreground(ColorPrimary).
-			Render(value + cursor)
+			Render(value)
 		return labelStr + " " + inputBox
 	}
 
@@ -1201,7 +1779,7 @@ func (v *ConnectView) renderField(id, inputWidth int) string {
 func (v *ConnectView) renderPasswordField(inputWidth int) string {
 	value := v.fields[fieldPassword]
 	focused := v.focusField == fieldPassword
-	masked := strings.Repeat("•", len(value))
+	masked := strings.Repeat("•", utf8.RuneCountInString(value))
 
 	labelStr := lipgloss.NewStyle().
 		Width(16).
@@ -1217,14 +1795,13 @@ func (v *ConnectView) renderPasswordField(inputWidth int) string {
 	}
 
 	if focused {
-		cursor := "█"
-		if !v.editing {
-			cursor = ""
+		if v.editing {
+			masked = v.edit.MaskedView()
 		}
 		inputBox := lipgloss.NewStyle().
 			Width(inputWidth).
 			Foreground(ColorPrimary).
-			Render(masked + cursor)
+			Render(masked)
 		return labelStr + " " + inputBox
 	}
 
@@ -1235,7 +1812,7 @@ func (v *ConnectView) renderMaskedField(id, inputWidth int) string {
 	label := fieldLabels[id]
 	value := v.fields[id]
 	focused := v.focusField == id
-	masked := strings.Repeat("•", len(value))
+	masked := strings.Repeat("•", utf8.RuneCountInString(value))
 
 	labelStr := lipgloss.NewStyle().
 		Width(16).
@@ -1251,14 +1828,13 @@ func (v *ConnectView) renderMaskedField(id, inputWidth int) string {
 	}
 
 	if focused {
-		cursor := "█"
-		if !v.editing {
-			cursor = ""
+		if v.editing {
+			masked = v.edit.MaskedView()
 		}
 		inputBox := lipgloss.NewStyle().
 			Width(inputWidth).
 			Foreground(ColorPrimary).
-			Render(masked + cursor)
+			Render(masked)
 		return labelStr + " " + inputBox
 	}
 
@@ -1269,6 +1845,32 @@ func (v *ConnectView) renderSelectField(id, inputWidth int) string {
 	label := fieldLabels[id]
 	value := v.fields[id]
 	focused := v.focusField == id
+	valueStyle := StyleDimmed
+	if id == fieldColor {
+		if color, ok := connectionColor(value); ok {
+			value = "■ " + value
+			valueStyle = lipgloss.NewStyle().Foreground(color)
+		} else {
+			value = "none"
+		}
+	}
+	if id == fieldSSHHostKey {
+		if value == "" {
+			value = "check known_hosts"
+		} else {
+			value, valueStyle = "don't check (insecure)", StyleWarning
+		}
+	}
+	if id == fieldSSHAuth {
+		for _, m := range sshAuthModes {
+			if m.mode == value {
+				value = m.label
+			}
+		}
+	}
+	if id == fieldConnAIProvider && value == "" {
+		value = "AI settings (" + v.fields[fieldAIProvider] + ")"
+	}
 
 	labelStr := lipgloss.NewStyle().
 		Width(16).
@@ -1288,7 +1890,7 @@ func (v *ConnectView) renderSelectField(id, inputWidth int) string {
 		return labelStr + " " + selectBox
 	}
 
-	return labelStr + " " + StyleDimmed.Render(value)
+	return labelStr + " " + valueStyle.Render(value)
 }
 
 func (v *ConnectView) renderToggleField(id int) string {
@@ -1369,14 +1971,13 @@ func (v *ConnectView) renderSSHKeyField(inputWidth int) string {
 	// No keys discovered — fall back to editable text field
 	value := v.fields[fieldSSHKey]
 	if focused {
-		cursor := "█"
-		if !v.editing {
-			cursor = ""
+		if v.editing {
+			value = v.edit.View()
 		}
 		inputBox := lipgloss.NewStyle().
 			Width(inputWidth).
 			Foreground(ColorPrimary).
-			Render(value + cursor)
+			Render(value)
 		return labelStr + " " + inputBox
 	}
 	return labelStr + " " + StyleDimmed.Render(value)
@@ -1,31 +1,49 @@
 // view_stats.go — Database statistics view.
 //
 // Shows live stats: database size, active connections, table sizes,
-// cache hit ratio, etc. Data is fetched asynchronously.
+// cache hit ratio, etc. Data is fetched asynchronously. Partitions count
+// toward their partitioned table; TimescaleDB hypertables and Citus
+// distributed tables get their own sections instead of listing their
+// chunks and near-empty parents.
+//
+// The temp files section shows what spills to disk for lack of work_mem;
+// 'a' sends it to the AI provider for work_mem advice.
 package tui
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
 	"strings"
 
+	"github.com/DachengChen/paiSQL/ai"
+	"github.com/DachengChen/paiSQL/applog"
 	"github.com/DachengChen/paiSQL/db"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
 type StatsView struct {
-	db       *db.DB
-	viewport *Viewport
-	loading  bool
-	err      error
-	width    int
-	height   int
+	db         *db.DB
+	aiProvider ai.Provider
+	viewport   *Viewport
+	loading    bool
+	err        error
+	width      int
+	height     int
+	tasks      viewTasks
+
+	lines     []string        // the stats shown, without the advice
+	spill     *db.SpillReport // nil if it couldn't be read
+	advising  bool
+	adviceReq int
 }
 
-func NewStatsView(database *db.DB) *StatsView {
+func NewStatsView(database *db.DB, provider ai.Provider) *StatsView {
 	return &StatsView{
-		db:       database,
-		viewport: NewViewport(80, 20),
+		db:         database,
+		aiProvider: provider,
+		viewport:   NewViewport(80, 20),
 	}
 }
 
@@ -41,27 +59,60 @@ func (v *StatsView) SetSize(width, height int) {
 func (v *StatsView) ShortHelp() []KeyBinding {
 	return []KeyBinding{
 		{Key: "r", Desc: "refresh"},
-		{Key: "↑/↓", Desc: "scroll"},
+		{Key: "a", Desc: "work_mem advice"},
+		{Key: "Ctrl+K/J", Desc: "scroll"},
 	}
 }
 
+func (v *StatsView) FullHelp() []KeyGroup {
+	return []KeyGroup{{Title: "Stats", Bindings: []KeyBinding{
+		{Key: "r", Desc: "refresh"},
+		{Key: "a", Desc: "ask the AI provider how to tune work_mem for the temp file usage"},
+		{Key: "Ctrl+K/J", Desc: "scroll"},
+		{Key: "PgUp/PgDn", Desc: "page"},
+	}}}
+}
+
 func (v *StatsView) Init() tea.Cmd {
 	return v.fetchStats()
 }
 
+// Leave cancels a running fetch; Init fetches fresh stats on return.
+func (v *StatsView) Leave() { v.tasks.stop() }
+
 func (v *StatsView) Update(msg tea.Msg) (View, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		return v.handleKey(msg)
 	case StatsMsg:
+		if errors.Is(msg.Err, context.Canceled) {
+			return v, nil // superseded by a newer fetch
+		}
 		v.loading = false
 		v.err = msg.Err
 		if msg.Err != nil {
 			v.viewport.SetContent(StyleError.Render("ERROR: " + msg.Err.Error()))
 		} else {
+			v.lines, v.spill = msg.Lines, msg.Spill
 			v.viewport.SetContentLines(msg.Lines)
 		}
 		return v, nil
+	case WorkMemAdviceMsg:
+		if msg.ID != v.adviceReq {
+			return v, nil
+		}
+		v.advising = false
+		lines := append(slices.Clone(v.lines), "", StyleTitle.Render("🤖 work_mem advice ("+v.aiProvider.Name()+")"), "")
+		if msg.Err != nil {
+			lines = append(lines, StyleError.Render("  "+msg.Err.Error()))
+		} else {
+			for _, line := range strings.Split(msg.Advice, "\n") {
+				lines = append(lines, "  "+line)
+			}
+		}
+		v.viewport.SetContentLines(lines)
+		v.viewport.ScrollTo(len(v.lines))
+		return v, nil
 	}
 	return v, nil
 }
@@ -70,6 +121,8 @@ func (v *StatsView) handleKey(msg tea.KeyMsg) (View, tea.Cmd) {
 	switch msg.String() {
 	case "r":
 		return v, v.fetchStats()
+	case "a":
+		return v, v.adviseWorkMem()
 	case "ctrl+k":
 		v.viewport.ScrollUp(1)
 	case "ctrl+j":
@@ -84,8 +137,8 @@ func (v *StatsView) handleKey(msg tea.KeyMsg) (View, tea.Cmd) {
 
 func (v *StatsView) fetchStats() tea.Cmd {
 	v.loading = true
+	ctx := v.tasks.restart()
 	return func() tea.Msg {
-		ctx := context.Background()
 		var lines []string
 
 		lines = append(lines, StyleTitle.Render("📊 Database Statistics"))
@@ -139,19 +192,57 @@ func (v *StatsView) fetchStats() tea.Cmd {
 			lines = append(lines, "  Cache hit ratio:      N/A")
 		}
 
+		// Citus and TimescaleDB; stats without them beat no stats
+		ext, err := v.db.FetchExtensionStats(ctx)
+		if err != nil {
+			if errors.Is(err, context.Canceled) {
+				return StatsMsg{Err: err}
+			}
+			applog.Error("Failed to read extension statistics: %v", err)
+			lines = append(lines, "", StyleError.Render("  Extension statistics: "+err.Error()))
+			ext = &db.ExtensionStats{}
+		}
+		lines = append(lines, extensionStatsLines(ext)...)
+
+		// Temp files; an error reading the statements still leaves the
+		// per-database usage
+		spill, err := v.db.FetchSpillReport(ctx, ext)
+		if err != nil {
+			if errors.Is(err, context.Canceled) {
+				return StatsMsg{Err: err}
+			}
+			applog.Error("Failed to read temp file statistics: %v", err)
+		}
+		if spill != nil {
+			lines = append(lines, spillLines(spill)...)
+		}
+		if err != nil {
+			lines = append(lines, StyleError.Render("  Temp file statistics: "+err.Error()))
+		}
+
 		lines = append(lines, "")
 		lines = append(lines, StyleTitle.Render("📋 Table Sizes (Top 20)"))
 		lines = append(lines, "")
 		lines = append(lines, fmt.Sprintf("  %-40s │ %-12s │ %s", "Table", "Size", "Rows (est.)"))
 		lines = append(lines, "  "+strings.Repeat("─", 70))
 
-		// Table sizes
+		// Table sizes, partitions summed into their partitioned table
 		rows, err := v.db.Pool.Query(ctx, `
-			SELECT schemaname || '.' || relname,
-			       pg_size_pretty(pg_total_relation_size(relid)),
-			       n_live_tup
-			FROM pg_stat_user_tables
-			ORDER BY pg_total_relation_size(relid) DESC
+			WITH t AS (
+			  SELECT COALESCE(pg_partition_root(relid), relid) AS root, relid,
+			         pg_total_relation_size(relid) AS size, n_live_tup
+			  FROM pg_stat_user_tables
+			  WHERE `+ext.OrdinaryTables()+`
+			)
+			SELECT n.nspname || '.' || c.relname,
+			       pg_size_pretty(sum(t.size)),
+			       sum(t.n_live_tup)::bigint,
+			       count(*) FILTER (WHERE t.relid <> t.root)
+			FROM t
+			JOIN pg_class c ON c.oid = t.root
+			JOIN pg_namespace n ON n.oid = c.relnamespace
+			GROUP BY n.nspname, c.relname
+			ORDER BY sum(t.size) DESC
 			LIMIT 20`)
 		if err != nil {
 			return StatsMsg{Err: err}
@@ -160,18 +251,130 @@ func (v *StatsView) fetchStats() tea.Cmd {
 
 		for rows.Next() {
 			var name, size string
-			var rowCount int64
-			if err := rows.Scan(&name, &size, &rowCount); err != nil {
+			var rowCount, partitions int64
+			if err := rows.Scan(&name, &size, &rowCount, &partitions); err != nil {
 				return StatsMsg{Err: err}
 			}
+			if partitions > 0 {
+				name += fmt.Sprintf(" (%d partitions)", partitions)
+			}
 			lines = append(lines, fmt.Sprintf("  %-40s │ %-12s │ %d", name, size, rowCount))
 		}
 
 		lines = append(lines, "")
-		lines = append(lines, StyleDimmed.Render("  Press 'r' to refresh"))
+		lines = append(lines, StyleDimmed.Render("  Press 'r' to refresh, 'a' for work_mem advice"))
 
-		return StatsMsg{Lines: lines}
+		return StatsMsg{Lines: lines, Spill: spill}
+	}
+}
+
+// extensionStatsLines renders the hypertables and distributed tables.
+func extensionStatsLines(ext *db.ExtensionStats) []string {
+	var lines []string
+	if e, ok := ext.Extensions["timescaledb"]; ok {
+		lines = append(lines, "", StyleTitle.Render("⏱ TimescaleDB "+e.Version+" Hypertables"), "")
+		if len(ext.Hypertables) == 0 {
+			lines = append(lines, StyleDimmed.Render("  No hypertables"))
+		} else {
+			lines = append(lines, fmt.Sprintf("  %-40s │ %-12s │ %-8s │ %s", "Hypertable", "Size", "Chunks", "Compression"))
+			lines = append(lines, "  "+strings.Repeat("─", 90))
+		}
+		for _, h := range ext.Hypertables {
+			compression := "off"
+			if h.CompressedChunks > 0 {
+				compression = fmt.Sprintf("%d/%d chunks, %.1fx (%s → %s)", h.CompressedChunks, h.Chunks,
+					h.CompressionRatio(), formatByteSize(int(h.BeforeCompress)), formatByteSize(int(h.AfterCompress)))
+			}
+			lines = append(lines, fmt.Sprintf("  %-40s │ %-12s │ %-8d │ %s",
+				h.Schema+"."+h.Name, formatByteSize(int(h.Size)), h.Chunks, compression))
+		}
+	}
+	if e, ok := ext.Extensions["citus"]; ok {
+		lines = append(lines, "", StyleTitle.Render(fmt.Sprintf("🌐 Citus %s Distributed Tables (%d workers)", e.Version, ext.Workers)), "")
+		if len(ext.Distributed) == 0 {
+			lines = append(lines, StyleDimmed.Render("  No distributed tables"))
+		} else {
+			lines = append(lines, fmt.Sprintf("  %-40s │ %-12s │ %-8s │ %-12s │ %s", "Table", "Size", "Shards", "Type", "Distributed by"))
+			lines = append(lines, "  "+strings.Repeat("─", 90))
+		}
+		for _, t := range ext.Distributed {
+			lines = append(lines, fmt.Sprintf("  %-40s │ %-12s │ %-8d │ %-12s │ %s",
+				t.Name, formatByteSize(int(t.Size)), t.Shards, t.Type, t.Column))
+		}
+	}
+	return lines
+}
+
+// spillLines renders the temp file usage per database and the statements
+// spilling most.
+func spillLines(r *db.SpillReport) []string {
+	lines := []string{"", StyleTitle.Render("💾 Temp Files"), "",
+		fmt.Sprintf("  work_mem:             %s", r.WorkMem),
+		fmt.Sprintf("  log_temp_files:       %s", r.LogTempFiles), ""}
+	if len(r.Databases) == 0 {
+		lines = append(lines, StyleDimmed.Render("  No temp files written since the statistics were reset"))
+	} else {
+		lines = append(lines, fmt.Sprintf("  %-30s │ %-10s │ %-12s │ %s", "Database", "Files", "Written", "Since"))
+		lines = append(lines, "  "+strings.Repeat("─", 70))
+	}
+	for _, u := range r.Databases {
+		since := "never reset"
+		if u.StatsReset != nil {
+			since = u.StatsReset.Format("2006-01-02 15:04")
+		}
+		lines = append(lines, fmt.Sprintf("  %-30s │ %-10d │ %-12s │ %s",
+			u.Database, u.TempFiles, formatByteSize(int(u.TempBytes)), since))
+	}
+
+	lines = append(lines, "")
+	switch {
+	case !r.HasStatements:
+		lines = append(lines, StyleDimmed.Render("  Install pg_stat_statements to see which statements spill"))
+	case len(r.Statements) == 0:
+		lines = append(lines, StyleDimmed.Render("  No statement in this database spilled to disk"))
+	default:
+		lines = append(lines, StyleBold.Render("  Statements spilling most"))
+		lines = append(lines, fmt.Sprintf("  %-12s │ %-10s │ %-10s │ %s", "Written", "Calls", "Mean", "Query"))
+		lines = append(lines, "  "+strings.Repeat("─", 90))
+	}
+	for _, s := range r.Statements {
+		lines = append(lines, fmt.Sprintf("  %-12s │ %-10d │ %-10s │ %s",
+			formatByteSize(int(s.TempBytes)), s.Calls, fmt.Sprintf("%.1f ms", s.MeanTimeMS), s.Query))
+	}
+	return lines
+}
+
+// adviseWorkMem sends the temp file usage to the AI provider.
+func (v *StatsView) adviseWorkMem() tea.Cmd {
+	if v.spill == nil || v.advising {
+		return nil
+	}
+	v.advising = true
+	v.adviceReq++
+	id, provider, report := v.adviceReq, v.aiProvider, spillReportText(v.spill)
+	v.viewport.SetContentLines(append(slices.Clone(v.lines), "", StyleDimmed.Render("  🤖 Asking "+provider.Name()+" about work_mem...")))
+	v.viewport.ScrollTo(len(v.lines))
+	return func() tea.Msg {
+		advice, err := ai.SuggestWorkMem(context.Background(), provider, report)
+		return WorkMemAdviceMsg{ID: id, Advice: advice, Err: err}
+	}
+}
+
+// spillReportText renders r as plain text for the AI provider.
+func spillReportText(r *db.SpillReport) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "work_mem = %s\nmax_connections = %s\nlog_temp_files = %s\n\n", r.WorkMem, r.MaxConnections, r.LogTempFiles)
+	sb.WriteString("Temp files per database (since stats reset):\n")
+	for _, u := range r.Databases {
+		fmt.Fprintf(&sb, "%s: %d files, %s\n", u.Database, u.TempFiles, formatByteSize(int(u.TempBytes)))
+	}
+	if len(r.Statements) > 0 {
+		sb.WriteString("\nStatements writing the most temp data (written | calls | mean time | query):\n")
+		for _, s := range r.Statements {
+			fmt.Fprintf(&sb, "%s | %d | %.1f ms | %s\n", formatByteSize(int(s.TempBytes)), s.Calls, s.MeanTimeMS, s.Query)
+		}
 	}
+	return sb.String()
 }
 
 func (v *StatsView) View() string {
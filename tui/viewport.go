@@ -5,6 +5,7 @@
 package tui
 
 import (
+	"regexp"
 	"strings"
 	"unicode/utf8"
 )
@@ -17,6 +18,18 @@ type Viewport struct {
 	scrollY  int      // vertical scroll offset (line index)
 	scrollX  int      // horizontal scroll offset (column index)
 	wrapText bool     // whether to wrap text instead of horizontal scroll
+
+	// stickyStart and stickyLines mark header lines (a result's column
+	// header and separator) that stay pinned at the top once scrolled past.
+	stickyStart int
+	stickyLines int
+
+	// search is the active /pattern search, nil for none; matches are
+	// its occurrences, match the current one.
+	search     *regexp.Regexp
+	searchText string // the pattern as typed
+	matches    []searchMatch
+	match      int
 }
 
 // NewViewport creates a viewport with the given dimensions.
@@ -30,19 +43,35 @@ func NewViewport(width, height int) *Viewport {
 // SetContent replaces the viewport content.
 func (v *Viewport) SetContent(content string) {
 	v.content = strings.Split(content, "\n")
+	v.stickyLines = 0
+	v.findMatches()
 	v.clampScroll()
 }
 
 // SetContentLines replaces the viewport content with pre-split lines.
 func (v *Viewport) SetContentLines(lines []string) {
 	v.content = lines
+	v.stickyLines = 0
+	v.findMatches()
 	v.clampScroll()
 }
 
+// SetSticky pins n content lines from start at the top of the viewport
+// while the content is scrolled below them. Replacing the content unpins
+// them; n of 0 pins nothing. Wrapped text is never pinned.
+func (v *Viewport) SetSticky(start, n int) {
+	if start < 0 || start+n > len(v.content) {
+		n = 0
+	}
+	v.stickyStart, v.stickyLines = start, n
+}
+
 // SetSize updates viewport dimensions.
+// Sizes are clamped to at least 1, as callers subtract their chrome
+// from whatever the terminal gives them.
 func (v *Viewport) SetSize(width, height int) {
-	v.width = width
-	v.height = height
+	v.width = max(width, 1)
+	v.height = max(height, 1)
 	v.clampScroll()
 }
 
@@ -82,6 +111,12 @@ func (v *Viewport) ScrollRight(n int) {
 	}
 }
 
+// ScrollTo scrolls so that line y is at the top, as far as the content allows.
+func (v *Viewport) ScrollTo(y int) {
+	v.scrollY = y
+	v.clampScroll()
+}
+
 // PageUp scrolls up by one page.
 func (v *Viewport) PageUp() {
 	v.ScrollUp(v.height)
@@ -132,32 +167,62 @@ func (v *Viewport) renderScrolled() []string {
 		end = len(v.content)
 	}
 
+	// Pinned header lines take the place of the first lines of the window,
+	// so the rows under them keep scrolling one line at a time.
 	var lines []string
-	for i := v.scrollY; i < end; i++ {
-		line := v.content[i]
-		runes := []rune(line)
-		// Apply horizontal scroll
-		if v.scrollX > 0 && v.scrollX < len(runes) {
-			runes = runes[v.scrollX:]
-		} else if v.scrollX >= len(runes) {
-			runes = nil
-		}
-		// Truncate to width
-		if len(runes) > v.width {
-			runes = runes[:v.width]
+	start := v.scrollY
+	if v.stickyLines > 0 && v.scrollY > v.stickyStart && v.stickyLines < v.height {
+		for i := v.stickyStart; i < v.stickyStart+v.stickyLines; i++ {
+			lines = append(lines, v.scrolledLine(i))
 		}
-		lines = append(lines, string(runes))
+		start += v.stickyLines
+	}
+	for i := start; i < end; i++ {
+		lines = append(lines, v.scrolledLine(i))
 	}
 	return lines
 }
 
+// scrolledLine returns the visible part of content line i, with the
+// matches of a search highlighted.
+func (v *Viewport) scrolledLine(i int) string {
+	if j, ok := v.lineMatches(i); ok {
+		return v.markLine(i, j, v.scrollX, v.scrollX+v.width)
+	}
+	return v.cropLine(v.content[i])
+}
+
+// cropLine applies the horizontal scroll and truncates line to the width.
+func (v *Viewport) cropLine(line string) string {
+	runes := []rune(line)
+	// Apply horizontal scroll
+	if v.scrollX > 0 && v.scrollX < len(runes) {
+		runes = runes[v.scrollX:]
+	} else if v.scrollX >= len(runes) {
+		runes = nil
+	}
+	// Truncate to width
+	if len(runes) > v.width {
+		runes = runes[:v.width]
+	}
+	return string(runes)
+}
+
 // renderWrapped returns word-wrapped lines.
 func (v *Viewport) renderWrapped() []string {
 	// First, wrap all content lines
 	var wrapped []string
-	for _, line := range v.content {
+	for i, line := range v.content {
 		rl := utf8.RuneCountInString(line)
-		if rl <= v.width || v.width <= 0 {
+		if j, ok := v.lineMatches(i); ok {
+			if v.width <= 0 {
+				wrapped = append(wrapped, v.markLine(i, j, 0, rl))
+				continue
+			}
+			for from := 0; from == 0 || from < rl; from += v.width {
+				wrapped = append(wrapped, v.markLine(i, j, from, from+v.width))
+			}
+		} else if rl <= v.width || v.width <= 0 {
 			wrapped = append(wrapped, line)
 		} else {
 			runes := []rune(line)
@@ -191,21 +256,75 @@ func (v *Viewport) clampScroll() {
 }
 
 func (v *Viewport) maxScrollY() int {
-	total := len(v.content)
-	if v.wrapText && v.width > 0 {
-		total = 0
-		for _, line := range v.content {
-			rl := utf8.RuneCountInString(line)
-			if rl <= v.width {
-				total++
-			} else {
-				total += (rl + v.width - 1) / v.width
-			}
-		}
-	}
+	total := v.VisualLine(len(v.content))
 	max := total - v.height
 	if max < 0 {
 		return 0
 	}
 	return max
 }
+
+// lineSpan is the number of screen lines content line i takes: more
+// than one when wrapping a long line.
+func (v *Viewport) lineSpan(i int) int {
+	if !v.wrapText || v.width <= 0 {
+		return 1
+	}
+	if rl := utf8.RuneCountInString(v.content[i]); rl > v.width {
+		return (rl + v.width - 1) / v.width
+	}
+	return 1
+}
+
+// VisualLine is the scroll offset at which content line i starts; the
+// same as i unless wrapping.
+func (v *Viewport) VisualLine(i int) int {
+	if !v.wrapText {
+		return min(i, len(v.content))
+	}
+	y := 0
+	for j := 0; j < i && j < len(v.content); j++ {
+		y += v.lineSpan(j)
+	}
+	return y
+}
+
+// ContentLine is the content line shown at scroll offset y, the inverse
+// of VisualLine.
+func (v *Viewport) ContentLine(y int) int {
+	if !v.wrapText {
+		return y
+	}
+	for i := range v.content {
+		if y -= v.lineSpan(i); y < 0 {
+			return i
+		}
+	}
+	return len(v.content)
+}
+
+// wrapLines splits text into lines no wider than width runes,
+// breaking on spaces where possible.
+func wrapLines(text string, width int) []string {
+	if width <= 0 {
+		return strings.Split(text, "\n")
+	}
+	var lines []string
+	for _, para := range strings.Split(text, "\n") {
+		line := ""
+		for _, word := range strings.Fields(para) {
+			if line == "" {
+				line = word
+				continue
+			}
+			if utf8.RuneCountInString(line)+1+utf8.RuneCountInString(word) > width {
+				lines = append(lines, line)
+				line = word
+				continue
+			}
+			line += " " + word
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -0,0 +1,214 @@
+// viewport_search.go implements /pattern search in a viewport: the matches
+// of the pattern are found again whenever the content changes, n/N step
+// through them, scrolling down and across to the current one, and every
+// occurrence on screen is highlighted. The
+// pattern is plain text or, with SearchOptions.Regex, a Go regexp, and
+// case-insensitive unless SearchOptions.CaseSensitive. The App drives it
+// for views implementing Searcher.
+package tui
+
+import (
+	"errors"
+	"regexp"
+	"regexp/syntax"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// searchMatch is an occurrence of the pattern: its content line, and its
+// start column and width in runes of the line without styling.
+type searchMatch struct {
+	line, col, width int
+}
+
+// SearchOptions are the toggles of a viewport search.
+type SearchOptions struct {
+	Regex         bool
+	CaseSensitive bool
+}
+
+// flags shows the options in effect, e.g. "[regex, case]".
+func (o SearchOptions) flags() string {
+	var on []string
+	if o.Regex {
+		on = append(on, "regex")
+	}
+	if o.CaseSensitive {
+		on = append(on, "case")
+	}
+	if len(on) == 0 {
+		return ""
+	}
+	return "[" + strings.Join(on, ", ") + "]"
+}
+
+// compileSearch turns a pattern into the regexp lines are matched with.
+func compileSearch(pattern string, opts SearchOptions) (*regexp.Regexp, error) {
+	expr := pattern
+	if !opts.Regex {
+		expr = regexp.QuoteMeta(pattern)
+	}
+	if !opts.CaseSensitive {
+		expr = "(?i)" + expr
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		// The expression quoted in the error has our (?i) prefix.
+		var serr *syntax.Error
+		if errors.As(err, &serr) {
+			return nil, errors.New(serr.Code.String())
+		}
+		return nil, err
+	}
+	if re.MatchString("") {
+		return nil, errors.New("the pattern matches empty text")
+	}
+	return re, nil
+}
+
+// CountMatches returns how many times pattern occurs without starting a
+// search, for the count shown while the pattern is typed.
+func (v *Viewport) CountMatches(pattern string, opts SearchOptions) (int, error) {
+	re, err := compileSearch(pattern, opts)
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, line := range v.content {
+		n += len(re.FindAllStringIndex(ansi.Strip(line), -1))
+	}
+	return n, nil
+}
+
+// Search starts a search for pattern and shows the first match at or
+// below the top of the viewport. It returns the number of matches.
+func (v *Viewport) Search(pattern string, opts SearchOptions) (int, error) {
+	re, err := compileSearch(pattern, opts)
+	if err != nil {
+		return 0, err
+	}
+	v.search, v.searchText = re, pattern
+	v.findMatches()
+	if len(v.matches) == 0 {
+		return 0, nil
+	}
+	top := v.ContentLine(v.scrollY)
+	v.match = 0
+	for i, m := range v.matches {
+		if m.line >= top {
+			v.match = i
+			break
+		}
+	}
+	v.showMatch()
+	return len(v.matches), nil
+}
+
+// Searching reports whether a search is active.
+func (v *Viewport) Searching() bool {
+	return v.search != nil
+}
+
+// ClearSearch ends the search and its highlighting.
+func (v *Viewport) ClearSearch() {
+	v.search, v.searchText, v.matches, v.match = nil, "", nil, 0
+}
+
+// NextMatch moves to the next (dir 1) or previous (dir -1) match, wrapping
+// around, and returns its number and the number of matches.
+func (v *Viewport) NextMatch(dir int) (n, total int) {
+	if len(v.matches) == 0 {
+		return 0, 0
+	}
+	v.match = (v.match + dir + len(v.matches)) % len(v.matches)
+	v.showMatch()
+	return v.match + 1, len(v.matches)
+}
+
+// findMatches collects the occurrences of the search, in content order.
+func (v *Viewport) findMatches() {
+	v.matches = v.matches[:0]
+	if v.search == nil {
+		return
+	}
+	for i, line := range v.content {
+		plain := ansi.Strip(line)
+		for _, m := range v.search.FindAllStringIndex(plain, -1) {
+			col := utf8.RuneCountInString(plain[:m[0]])
+			v.matches = append(v.matches, searchMatch{
+				line:  i,
+				col:   col,
+				width: utf8.RuneCountInString(plain[m[0]:m[1]]),
+			})
+		}
+	}
+	v.match = min(v.match, max(len(v.matches)-1, 0))
+}
+
+// showMatch scrolls the line of the current match to the top, just under
+// a pinned header, so in a result grid it becomes the focused row, and
+// scrolls across to the match when it is off to the side.
+func (v *Viewport) showMatch() {
+	m := v.matches[v.match]
+	line := m.line
+	if !v.wrapText && v.stickyLines > 0 && line >= v.stickyStart+v.stickyLines {
+		line -= v.stickyLines
+	}
+	y := v.VisualLine(line)
+	if v.wrapText && v.width > 0 {
+		y += m.col / v.width // the wrapped piece the match starts in
+	}
+	v.ScrollTo(y)
+
+	if v.wrapText || (m.col >= v.scrollX && m.col+m.width <= v.scrollX+v.width) {
+		return
+	}
+	// Keep some of what precedes the match in view, such as the start of
+	// its cell, unless the match is too wide for that.
+	v.scrollX = max(m.col-v.width/4, 0)
+	if m.col+m.width > v.scrollX+v.width {
+		v.scrollX = m.col
+	}
+}
+
+// lineMatches returns the index in v.matches of the first match on
+// content line i, and whether there is one.
+func (v *Viewport) lineMatches(i int) (int, bool) {
+	if v.search == nil {
+		return 0, false
+	}
+	j := sort.Search(len(v.matches), func(k int) bool { return v.matches[k].line >= i })
+	return j, j < len(v.matches) && v.matches[j].line == i
+}
+
+// markLine renders runes from to to of content line i, whose matches
+// start at v.matches[j], with the matches highlighted and the current one
+// set apart. The line loses its own styling.
+func (v *Viewport) markLine(i, j, from, to int) string {
+	plain := []rune(ansi.Strip(v.content[i]))
+	to = min(to, len(plain))
+	if from >= to {
+		return ""
+	}
+	var b strings.Builder
+	pos := from
+	for ; j < len(v.matches) && v.matches[j].line == i; j++ {
+		m := v.matches[j]
+		start, end := max(m.col, pos), min(m.col+m.width, to)
+		if start >= end {
+			continue
+		}
+		b.WriteString(string(plain[pos:start]))
+		style := StyleSearchMatch
+		if j == v.match {
+			style = StyleSearchCurrent
+		}
+		b.WriteString(style.Render(string(plain[start:end])))
+		pos = end
+	}
+	b.WriteString(string(plain[pos:to]))
+	return b.String()
+}
@@ -0,0 +1,299 @@
+// watch.go implements \every, which runs a statement on an interval while
+// the app is open, for watching the effect of a deploy or a backfill:
+//
+//	\every 5m <sql>      start a watch (30s, 2m, 1h; a bare number is seconds)
+//	\every               list the watches
+//	\every stop [n|all]  stop watch n, or all of them
+//
+// Each run is appended to a pane under the results. The first run shows
+// the rows; later runs show what changed: the new value and difference of
+// each changed column of a one-row result, or the rows added and removed.
+package tui
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DachengChen/paiSQL/db"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	minWatchInterval = time.Second
+	watchShownRows   = 5   // rows shown per run, and per added/removed list
+	watchLogLines    = 500 // lines kept in the pane
+)
+
+// watch is a statement run by \every.
+type watch struct {
+	id       int
+	interval time.Duration
+	sql      string
+	runs     int
+	prev     *db.QueryResult // result of the last successful run
+}
+
+// watchTickMsg starts the next run of a watch.
+type watchTickMsg struct{ id int }
+
+// parseWatchInterval reads "30s", "5m" or a bare number of seconds.
+func parseWatchInterval(s string) (time.Duration, error) {
+	d, err := time.ParseDuration(s)
+	if n, convErr := strconv.Atoi(s); convErr == nil {
+		d, err = time.Duration(n)*time.Second, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("%q is not an interval such as 30s or 5m", s)
+	}
+	if d < minWatchInterval {
+		return 0, fmt.Errorf("the interval must be at least %s", minWatchInterval)
+	}
+	return d, nil
+}
+
+// every runs \every.
+func (v *MainView) every(cmd string) tea.Cmd {
+	v.input.Reset()
+	args := strings.Fields(cmd)
+	switch {
+	case len(args) == 0:
+		v.listWatches()
+		return nil
+	case args[0] == "stop":
+		return v.stopWatches(args[1:])
+	case len(args) < 2:
+		v.viewport.SetContent(StyleError.Render("Usage: \\every <interval> <sql>  |  \\every stop [n|all]"))
+		return nil
+	}
+
+	interval, err := parseWatchInterval(args[0])
+	if err != nil {
+		v.viewport.SetContent(StyleError.Render("\\every: " + err.Error()))
+		return nil
+	}
+	sql := strings.TrimSpace(strings.TrimPrefix(cmd, args[0]))
+	v.watchSeq++
+	w := &watch{id: v.watchSeq, interval: interval, sql: sql}
+	v.watches = append(v.watches, w)
+	v.appendWatchLog(StyleBold.Render(fmt.Sprintf("#%d every %s: %s", w.id, interval, oneLine(sql))))
+	return v.runWatch(w)
+}
+
+// oneLine collapses whitespace so a statement fits on one line.
+func oneLine(sql string) string {
+	return strings.Join(strings.Fields(sql), " ")
+}
+
+// listWatches shows the running watches in the results pane.
+func (v *MainView) listWatches() {
+	lines := []string{StyleBold.Render("Watches"), ""}
+	if len(v.watches) == 0 {
+		lines = append(lines, StyleDimmed.Render("  (none — \\every <interval> <sql> starts one)"))
+	}
+	for _, w := range v.watches {
+		lines = append(lines, fmt.Sprintf("  #%-3d every %-6s %3d runs  %s", w.id, w.interval, w.runs, oneLine(w.sql)))
+	}
+	lines = append(lines, "", StyleDimmed.Render("\\every stop <n> stops one, \\every stop stops all."))
+	v.viewport.SetContentLines(lines)
+}
+
+// stopWatches runs \every stop [n|all].
+func (v *MainView) stopWatches(args []string) tea.Cmd {
+	if len(args) == 0 || args[0] == "all" {
+		n := len(v.watches)
+		v.watches = nil
+		return func() tea.Msg { return StatusMsg(fmt.Sprintf("Stopped %d watches", n)) }
+	}
+	id, err := strconv.Atoi(strings.TrimPrefix(args[0], "#"))
+	i := slices.IndexFunc(v.watches, func(w *watch) bool { return w.id == id })
+	if err != nil || i < 0 {
+		return func() tea.Msg { return StatusMsg("No watch " + args[0] + " (\\every lists them)") }
+	}
+	v.watches = slices.Delete(v.watches, i, i+1)
+	v.appendWatchLog(StyleDimmed.Render(fmt.Sprintf("#%d stopped", id)))
+	return func() tea.Msg { return StatusMsg(fmt.Sprintf("Stopped watch #%d", id)) }
+}
+
+// findWatch returns the running watch with id, or nil once it's stopped.
+func (v *MainView) findWatch(id int) *watch {
+	for _, w := range v.watches {
+		if w.id == id {
+			return w
+		}
+	}
+	return nil
+}
+
+// runWatch runs w's statement once.
+func (v *MainView) runWatch(w *watch) tea.Cmd {
+	database := v.db
+	id, sql := w.id, w.sql
+	return func() tea.Msg {
+		result, err := database.Execute(context.Background(), sql)
+		return WatchResultMsg{ID: id, Result: result, Err: err, At: time.Now()}
+	}
+}
+
+// updateWatch handles ticks and results of the watches.
+func (v *MainView) updateWatch(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case watchTickMsg:
+		if w := v.findWatch(msg.id); w != nil {
+			return v.runWatch(w)
+		}
+
+	case WatchResultMsg:
+		w := v.findWatch(msg.ID)
+		if w == nil {
+			return nil
+		}
+		w.runs++
+		stamp := StyleDimmed.Render(msg.At.Format("15:04:05") + fmt.Sprintf(" #%d ", w.id))
+		if msg.Err != nil {
+			v.appendWatchLog(stamp + StyleError.Render(msg.Err.Error()))
+		} else {
+			lines := watchLines(w.prev, msg.Result)
+			v.appendWatchLog(stamp + lines[0])
+			for _, line := range lines[1:] {
+				v.appendWatchLog("           " + line)
+			}
+			w.prev = msg.Result
+		}
+		id := w.id
+		return tea.Tick(w.interval, func(time.Time) tea.Msg { return watchTickMsg{id: id} })
+	}
+	return nil
+}
+
+// appendWatchLog adds a line to the pane, dropping the oldest ones.
+func (v *MainView) appendWatchLog(line string) {
+	v.watchLog = append(v.watchLog, line)
+	if n := len(v.watchLog) - watchLogLines; n > 0 {
+		v.watchLog = slices.Delete(v.watchLog, 0, n)
+	}
+}
+
+// watchLines describes a run: the result itself on the first run or when
+// the columns changed, otherwise what changed since prev.
+func watchLines(prev, cur *db.QueryResult) []string {
+	if len(cur.Columns) == 0 {
+		return []string{cur.Status}
+	}
+	if prev == nil || !slices.Equal(prev.Columns, cur.Columns) {
+		lines := []string{cur.Status}
+		for i, row := range cur.Rows {
+			if i == watchShownRows {
+				lines = append(lines, StyleDimmed.Render(fmt.Sprintf("… %d more", len(cur.Rows)-i)))
+				break
+			}
+			lines = append(lines, watchRow(cur.Columns, row))
+		}
+		return lines
+	}
+
+	if len(prev.Rows) == 1 && len(cur.Rows) == 1 {
+		var changes []string
+		for i, col := range cur.Columns {
+			was, now := prev.Rows[0][i], cur.Rows[0][i]
+			if was == now {
+				continue
+			}
+			change := fmt.Sprintf("%s=%s", col, now)
+			a, errA := strconv.ParseFloat(was, 64)
+			b, errB := strconv.ParseFloat(now, 64)
+			if errA == nil && errB == nil {
+				delta := strconv.FormatFloat(b-a, 'f', -1, 64)
+				if b > a {
+					delta = "+" + delta
+				}
+				change += StyleWarning.Render(" (" + delta + ")")
+			} else {
+				change += StyleDimmed.Render(" (was " + was + ")")
+			}
+			changes = append(changes, change)
+		}
+		if len(changes) == 0 {
+			return []string{StyleDimmed.Render("no change")}
+		}
+		return []string{strings.Join(changes, ", ")}
+	}
+
+	key := func(row []string) string { return strings.Join(row, "\x00") }
+	before := make(map[string]int, len(prev.Rows))
+	for _, row := range prev.Rows {
+		before[key(row)]++
+	}
+	var added [][]string
+	for _, row := range cur.Rows {
+		if k := key(row); before[k] > 0 {
+			before[k]--
+		} else {
+			added = append(added, row)
+		}
+	}
+	var removed [][]string
+	for _, row := range prev.Rows {
+		if k := key(row); before[k] > 0 {
+			before[k]--
+			removed = append(removed, row)
+		}
+	}
+	if len(added) == 0 && len(removed) == 0 {
+		return []string{StyleDimmed.Render(cur.Status + ", no change")}
+	}
+
+	lines := []string{fmt.Sprintf("%s: +%d −%d", cur.Status, len(added), len(removed))}
+	list := func(rows [][]string, mark string, style lipgloss.Style) {
+		for i, row := range rows {
+			if i == watchShownRows {
+				lines = append(lines, StyleDimmed.Render(fmt.Sprintf("  … %d more", len(rows)-i)))
+				return
+			}
+			lines = append(lines, style.Render(mark+" "+watchRow(cur.Columns, row)))
+		}
+	}
+	list(added, "+", StyleSuccess)
+	list(removed, "−", StyleError)
+	return lines
+}
+
+// watchRow renders a row as col=value pairs.
+func watchRow(columns, row []string) string {
+	pairs := make([]string, len(row))
+	for i, cell := range row {
+		if cell == nullCell {
+			cell = "NULL"
+		}
+		pairs[i] = columns[i] + "=" + cell
+	}
+	return strings.Join(pairs, ", ")
+}
+
+// renderWatchPane renders the last lines of the watch log in a pane of
+// the given size, or "" when no watch is running.
+func (v *MainView) renderWatchPane(width, height int) string {
+	if len(v.watches) == 0 {
+		return ""
+	}
+	title := StyleBold.Render(fmt.Sprintf("  ⏱ Watches (%d)", len(v.watches))) +
+		StyleDimmed.Render("  \\every stop ends them")
+	lines := v.watchLog
+	if n := height - 2; len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	body := make([]string, len(lines))
+	for i, line := range lines {
+		body[i] = "  " + lipgloss.NewStyle().MaxWidth(width-2).Render(line)
+	}
+	return lipgloss.NewStyle().
+		Width(width).
+		Height(height-1).
+		Border(lipgloss.NormalBorder(), false, false, true, false).
+		BorderForeground(ColorDim).
+		Render(title + "\n" + strings.Join(body, "\n"))
+}